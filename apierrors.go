@@ -0,0 +1,19 @@
+package main
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// maxErrorBody is how much of a failed response's body statusError reads:
+// enough for GitHub's error message.
+const maxErrorBody = 4 << 10
+
+// statusError builds the error for a response with an unexpected status,
+// typed by kind where there is one; see core.StatusError.
+func statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return core.StatusError(resp.StatusCode, resp.Header.Get, body)
+}
@@ -0,0 +1,730 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// Config file JSON types — these never leave this file.
+
+type fileConfig struct {
+	Rules          []fileRule     `json:"rules"`
+	RulesURL       string         `json:"rules_url"`
+	RulesRepo      *fileRulesRepo `json:"rules_repo"`
+	RulesPublicKey string         `json:"rules_public_key"`
+	KeepAuthors    []string       `json:"keep_authors"`
+	KeepMentions   bool           `json:"keep_mentions"`
+	KeepAssigned   bool           `json:"keep_assigned"`
+	MuteApproved   bool           `json:"mute_approved"`
+
+	MuteTeammateReviewing bool `json:"mute_teammate_reviewing"`
+	KeepFailedChecks      bool `json:"keep_failed_checks"`
+	PinParticipated       bool `json:"pin_participated"`
+	KeepDeadlineDays      int  `json:"keep_deadline_days"`
+
+	Pinned []string `json:"pinned"`
+
+	Hosts []fileHost `json:"hosts"`
+	Users []fileUser `json:"users"`
+
+	UserAgent  string `json:"user_agent"`
+	APIVersion string `json:"api_version"`
+
+	OnCall        *fileOnCall        `json:"oncall"`
+	BusinessHours *fileBusinessHours `json:"business_hours"`
+	Scoring       *fileScoring       `json:"scoring"`
+	State         *fileState         `json:"state"`
+}
+
+type fileState struct {
+	Backend string `json:"backend"`
+	Dir     string `json:"dir"`
+	Encrypt bool   `json:"encrypt"`
+}
+
+type fileScoring struct {
+	Direct    int            `json:"direct"`
+	Team      int            `json:"team"`
+	Authors   map[string]int `json:"authors"`
+	Labels    map[string]int `json:"labels"`
+	Repos     map[string]int `json:"repos"`
+	PerDayOld int            `json:"per_day_old"`
+	KeepAt    int            `json:"keep_at"`
+	MuteBelow int            `json:"mute_below"`
+	SnoozeFor string         `json:"snooze_for"`
+}
+
+type fileBusinessHours struct {
+	Timezone string   `json:"timezone"`
+	Start    string   `json:"start"`
+	End      string   `json:"end"`
+	Days     []string `json:"days"`
+}
+
+type fileOnCall struct {
+	ICalURL           string   `json:"ical_url"`
+	PagerDutySchedule string   `json:"pagerduty_schedule"`
+	PagerDutyEmail    string   `json:"pagerduty_email"`
+	Teams             []string `json:"teams"`
+}
+
+type fileHost struct {
+	Host     string `json:"host"`
+	TokenEnv string `json:"token_env"`
+}
+
+type fileUser struct {
+	Name     string `json:"name"`
+	Host     string `json:"host"`
+	TokenEnv string `json:"token_env"`
+	Config   string `json:"config"`
+}
+
+type fileRule struct {
+	Name   string   `json:"name"`
+	When   string   `json:"when,omitempty"`
+	Teams  []string `json:"teams,omitempty"`
+	Action string   `json:"action"`
+}
+
+type fileRulesRepo struct {
+	Repo string `json:"repo"`
+	Path string `json:"path"`
+	Ref  string `json:"ref"`
+}
+
+// configKeys lists every key path a config file may contain, with array
+// indexes elided and "*" for free-form object keys. Keep in sync with fileConfig.
+var configKeys = []string{
+	"rules",
+	"rules[]",
+	"rules[].name",
+	"rules[].when",
+	"rules[].teams",
+	"rules[].teams[]",
+	"rules[].action",
+	"rules_url",
+	"rules_repo",
+	"rules_repo.repo",
+	"rules_repo.path",
+	"rules_repo.ref",
+	"rules_public_key",
+	"keep_authors",
+	"keep_authors[]",
+	"keep_mentions",
+	"keep_assigned",
+	"mute_approved",
+	"mute_teammate_reviewing",
+	"keep_failed_checks",
+	"pin_participated",
+	"keep_deadline_days",
+	"pinned",
+	"pinned[]",
+	"hosts",
+	"hosts[]",
+	"hosts[].host",
+	"hosts[].token_env",
+	"users",
+	"users[]",
+	"users[].name",
+	"users[].host",
+	"users[].token_env",
+	"users[].config",
+	"user_agent",
+	"api_version",
+	"oncall",
+	"oncall.ical_url",
+	"oncall.pagerduty_schedule",
+	"oncall.pagerduty_email",
+	"oncall.teams",
+	"oncall.teams[]",
+	"business_hours",
+	"business_hours.timezone",
+	"business_hours.start",
+	"business_hours.end",
+	"business_hours.days",
+	"business_hours.days[]",
+	"scoring",
+	"scoring.direct",
+	"scoring.team",
+	"scoring.authors",
+	"scoring.authors.*",
+	"scoring.labels",
+	"scoring.labels.*",
+	"scoring.repos",
+	"scoring.repos.*",
+	"scoring.per_day_old",
+	"scoring.keep_at",
+	"scoring.mute_below",
+	"scoring.snooze_for",
+	"state",
+	"state.backend",
+	"state.dir",
+	"state.encrypt",
+}
+
+// localConfig is what a checked config file yields.
+type localConfig struct {
+	rules                 []core.Rule
+	keepAuthors           []string
+	keepMentions          bool
+	keepAssigned          bool
+	muteApproved          bool
+	muteTeammateReviewing bool
+	keepFailedChecks      bool
+	pinParticipated       bool
+	deadlineWithin        time.Duration       // keep team-only requests due this soon; 0 for none
+	pins                  []core.ThreadTarget // threads never to touch
+	hosts                 []core.HostSpec     // empty to use GH_HOST and GH_TOKEN
+	users                 []core.UserSpec     // inboxes a multi-user daemon manages; empty for the token's own
+	policy                core.PolicySource   // shared policy to extend; zero if none
+	userAgent             string              // User-Agent override; empty for the default
+	apiVersion            string              // X-GitHub-Api-Version override; empty for the default
+	onCall                *core.OnCallSpec    // reviewer-on-call rotation; nil if none
+	businessHours         *core.BusinessHours
+	scoring               *core.Scoring  // nil for the built-in keep-or-mute
+	state                 core.StateSpec // where to keep state; zero for files in the state dir
+}
+
+// defaultConfigPath returns the config file location used when --config isn't
+// given, e.g. ~/.config/mutemath/config.json on Linux.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "mutemath", "config.json")
+}
+
+// resolveConfigPath returns the config file to load for a --config value, and
+// whether it must exist: an explicit path must, the default location needn't.
+func resolveConfigPath(flagValue string) (path string, required bool) {
+	if flagValue != "" {
+		return flagValue, true
+	}
+	return defaultConfigPath(), false
+}
+
+// loadConfig reads and checks a config file. found is false when the file
+// doesn't exist, which is only an error if required is set.
+func loadConfig(path string, required bool) (cfg localConfig, found bool, err error) {
+	if path == "" {
+		return localConfig{}, false, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return localConfig{}, false, nil
+	}
+	if err != nil {
+		return localConfig{}, false, fmt.Errorf("read config: %w", err)
+	}
+
+	cfg, diags := checkConfig(data)
+	for _, d := range diags {
+		if !d.Warning {
+			return localConfig{}, true, errors.New(core.FormatConfigDiagnostic(path, d))
+		}
+	}
+	return cfg, true, nil
+}
+
+// checkConfig parses a config file, reporting every problem with its position.
+// The result is only usable if there are no error diagnostics.
+func checkConfig(data []byte) (localConfig, []core.ConfigDiagnostic) {
+	text := string(data)
+	at := func(offset int, warning bool, msg string) core.ConfigDiagnostic {
+		line, col := core.LineCol(text, offset)
+		return core.ConfigDiagnostic{Line: line, Col: col, Warning: warning, Msg: msg}
+	}
+
+	entries, err := scanJSON(data)
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return localConfig{}, []core.ConfigDiagnostic{at(int(syntaxErr.Offset), false, syntaxErr.Error())}
+		}
+		return localConfig{}, []core.ConfigDiagnostic{{Msg: err.Error()}}
+	}
+
+	var diags []core.ConfigDiagnostic
+	byPath := make(map[string]jsonEntry, len(entries))
+	for _, e := range entries {
+		byPath[e.path] = e
+		if e.path != "" && !knownConfigKey(elideIndexes(e.path)) {
+			diags = append(diags, at(e.key, false, unknownKeyMessage(e.path)))
+		}
+	}
+
+	var fc fileConfig
+	if err := json.Unmarshal(data, &fc); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			return localConfig{}, append(diags, core.ConfigDiagnostic{Msg: err.Error()})
+		}
+		// Offset is just past the bad value; report where the value starts.
+		start := 0
+		for _, e := range entries {
+			if e.value < int(typeErr.Offset) {
+				start = e.value
+			}
+		}
+		msg := fmt.Sprintf("%s: expected %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+		return localConfig{}, append(diags, at(start, false, msg))
+	}
+
+	specs := make([]core.RuleSpec, len(fc.Rules))
+	for i, r := range fc.Rules {
+		specs[i] = core.RuleSpec{Name: r.Name, When: r.When, Teams: r.Teams, Action: r.Action}
+	}
+	rules, ruleErrs := core.CheckRules(specs)
+	for _, e := range ruleErrs {
+		diags = append(diags, ruleDiagnostic(text, byPath, e, false, at))
+	}
+	for _, e := range core.RuleConflicts(rules) {
+		diags = append(diags, ruleDiagnostic(text, byPath, e, true, at))
+	}
+
+	for i, a := range fc.KeepAuthors {
+		if strings.TrimSpace(a) == "" {
+			diags = append(diags, at(byPath[fmt.Sprintf("keep_authors[%d]", i)].value, false, "keep_authors: empty login"))
+		}
+	}
+
+	if fc.KeepDeadlineDays < 0 {
+		diags = append(diags, at(byPath["keep_deadline_days"].value, false, "keep_deadline_days: must not be negative"))
+	}
+
+	var pins []core.ThreadTarget
+	for i, p := range fc.Pinned {
+		target, err := core.ParseThreadTarget(p)
+		if err != nil {
+			diags = append(diags, at(byPath[fmt.Sprintf("pinned[%d]", i)].value, false, "pinned: "+err.Error()))
+			continue
+		}
+		pins = append(pins, target)
+	}
+
+	hosts := make([]core.HostSpec, len(fc.Hosts))
+	for i, h := range fc.Hosts {
+		hosts[i] = core.HostSpec{Host: h.Host, TokenEnv: h.TokenEnv}
+	}
+	for _, e := range core.CheckHosts(hosts) {
+		entry, ok := byPath[fmt.Sprintf("hosts[%d].%s", e.Index, e.Field)]
+		if !ok {
+			entry = byPath[fmt.Sprintf("hosts[%d]", e.Index)]
+		}
+		diags = append(diags, at(entry.value, false, e.Error()))
+	}
+
+	users := make([]core.UserSpec, len(fc.Users))
+	for i, u := range fc.Users {
+		users[i] = core.UserSpec{Name: u.Name, Host: u.Host, TokenEnv: u.TokenEnv, Config: u.Config}
+	}
+	for _, e := range core.CheckUsers(users) {
+		entry, ok := byPath[fmt.Sprintf("users[%d].%s", e.Index, e.Field)]
+		if !ok {
+			entry = byPath[fmt.Sprintf("users[%d]", e.Index)]
+		}
+		diags = append(diags, at(entry.value, false, e.Error()))
+	}
+	if len(users) > 0 && len(hosts) > 0 {
+		diags = append(diags, at(byPath["users"].value, false, "users and hosts can't be used together; give each user a host instead"))
+	}
+
+	policy := core.PolicySource{URL: fc.RulesURL, PublicKey: fc.RulesPublicKey}
+	if fc.RulesRepo != nil {
+		policy.Repo, policy.Path, policy.Ref = fc.RulesRepo.Repo, fc.RulesRepo.Path, fc.RulesRepo.Ref
+	}
+	if err := policy.Validate(); err != nil {
+		offset := 0
+		for _, key := range []string{"rules_public_key", "rules_repo", "rules_url"} {
+			if e, ok := byPath[key]; ok {
+				offset = e.value
+			}
+		}
+		diags = append(diags, at(offset, false, err.Error()))
+	}
+
+	if e, ok := byPath["user_agent"]; ok {
+		if err := core.CheckUserAgent(fc.UserAgent); err != nil {
+			diags = append(diags, at(e.value, false, "user_agent: "+err.Error()))
+		}
+	}
+	if e, ok := byPath["api_version"]; ok {
+		if err := core.CheckAPIVersion(fc.APIVersion); err != nil {
+			diags = append(diags, at(e.value, false, "api_version: "+err.Error()))
+		}
+	}
+
+	var onCall *core.OnCallSpec
+	if fc.OnCall != nil {
+		onCall = &core.OnCallSpec{
+			ICalURL:           fc.OnCall.ICalURL,
+			PagerDutySchedule: fc.OnCall.PagerDutySchedule,
+			PagerDutyEmail:    fc.OnCall.PagerDutyEmail,
+			Teams:             fc.OnCall.Teams,
+		}
+		if err := onCall.Validate(); err != nil {
+			diags = append(diags, at(byPath["oncall"].value, false, err.Error()))
+		}
+	}
+
+	var hours *core.BusinessHours
+	if fc.BusinessHours != nil {
+		spec := core.BusinessHoursSpec{Timezone: fc.BusinessHours.Timezone, Start: fc.BusinessHours.Start, End: fc.BusinessHours.End, Days: fc.BusinessHours.Days}
+		b, err := core.ParseBusinessHours(spec, time.LoadLocation)
+		if err != nil {
+			diags = append(diags, at(byPath["business_hours"].value, false, err.Error()))
+		}
+		hours = &b
+	}
+	var scoring *core.Scoring
+	if fc.Scoring != nil {
+		sc := fc.Scoring
+		scoring, err = core.CheckScoring(core.ScoringSpec{
+			Direct: sc.Direct, Team: sc.Team,
+			Authors: sc.Authors, Labels: sc.Labels, Repos: sc.Repos,
+			PerDayOld: sc.PerDayOld,
+			KeepAt:    sc.KeepAt, MuteBelow: sc.MuteBelow, SnoozeFor: sc.SnoozeFor,
+		})
+		if err != nil {
+			diags = append(diags, at(byPath["scoring"].value, false, err.Error()))
+		}
+	}
+	var state core.StateSpec
+	if fc.State != nil {
+		state, err = core.CheckState(core.StateSpec{Backend: fc.State.Backend, Dir: fc.State.Dir, Encrypt: fc.State.Encrypt})
+		if err != nil {
+			offset := byPath["state"].value
+			if e, ok := byPath["state.backend"]; ok {
+				offset = e.value
+			}
+			diags = append(diags, at(offset, false, err.Error()))
+		}
+	}
+	for i, r := range rules {
+		if hours == nil && r.NeedsBusinessHours() {
+			diags = append(diags, at(byPath[fmt.Sprintf("rules[%d]", i)].value, false, fmt.Sprintf("rule %s: defer and time.business_hours need business_hours", r.Name)))
+		}
+	}
+
+	if core.HasErrors(diags) {
+		return localConfig{}, diags
+	}
+	return localConfig{
+		rules:                 rules,
+		keepAuthors:           fc.KeepAuthors,
+		keepMentions:          fc.KeepMentions,
+		keepAssigned:          fc.KeepAssigned,
+		muteApproved:          fc.MuteApproved,
+		muteTeammateReviewing: fc.MuteTeammateReviewing,
+		keepFailedChecks:      fc.KeepFailedChecks,
+		pinParticipated:       fc.PinParticipated,
+		deadlineWithin:        time.Duration(fc.KeepDeadlineDays) * 24 * time.Hour,
+		pins:                  pins,
+		hosts:                 hosts,
+		users:                 users,
+		policy:                policy,
+		userAgent:             fc.UserAgent,
+		apiVersion:            fc.APIVersion,
+		onCall:                onCall,
+		businessHours:         hours,
+		scoring:               scoring,
+		state:                 state,
+	}, diags
+}
+
+// ruleDiagnostic positions a rule problem at the offending key's value, or
+// inside the "when" string for expression errors.
+func ruleDiagnostic(text string, byPath map[string]jsonEntry, e *core.RuleError, warning bool, at func(int, bool, string) core.ConfigDiagnostic) core.ConfigDiagnostic {
+	rulePath := fmt.Sprintf("rules[%d]", e.Index)
+	offset := byPath[rulePath].value
+	if entry, ok := byPath[rulePath+"."+e.Field]; ok && e.Field != "" {
+		offset = entry.value
+	}
+
+	var exprErr *core.ExprError
+	if e.Field == "when" && errors.As(e.Err, &exprErr) {
+		offset = core.JSONStringOffset(text, offset, exprErr.Col-1)
+		return at(offset, warning, fmt.Sprintf("rule %s: when: %s", e.Name, exprErr.Msg))
+	}
+	return at(offset, warning, e.Error())
+}
+
+var indexPattern = regexp.MustCompile(`\[\d+\]`)
+
+// knownConfigKey reports whether an elided key path is in configKeys, where
+// "parent.*" stands for any key of an object with free-form keys.
+func knownConfigKey(path string) bool {
+	if slices.Contains(configKeys, path) {
+		return true
+	}
+	i := strings.LastIndex(path, ".")
+	return i >= 0 && slices.Contains(configKeys, path[:i]+".*")
+}
+
+func elideIndexes(path string) string {
+	return indexPattern.ReplaceAllString(path, "[]")
+}
+
+// unknownKeyMessage names the unknown key and the valid keys at its level.
+func unknownKeyMessage(path string) string {
+	parent, key := "", path
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		parent, key = elideIndexes(path[:i])+".", path[i+1:]
+	}
+	var valid []string
+	for _, k := range configKeys {
+		rest, ok := strings.CutPrefix(k, parent)
+		if ok && rest != "" && !strings.ContainsAny(rest, ".[") {
+			valid = append(valid, rest)
+		}
+	}
+	return fmt.Sprintf("unknown key %q (valid keys: %s)", key, strings.Join(valid, ", "))
+}
+
+// jsonEntry records where a value in a JSON document starts. key is the
+// offset of its object key, or the value offset for array elements.
+type jsonEntry struct {
+	path  string // e.g. "rules[1].when"; "" for the root
+	key   int
+	value int
+}
+
+// scanJSON walks a JSON document and returns an entry for every value, in document order.
+func scanJSON(data []byte) ([]jsonEntry, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var entries []jsonEntry
+
+	// skip advances past whitespace and separators to the start of the next token.
+	skip := func(offset int) int {
+		for offset < len(data) && strings.IndexByte(" \t\r\n:,", data[offset]) >= 0 {
+			offset++
+		}
+		return offset
+	}
+
+	var walk func(path string, key int) error
+	walk = func(path string, key int) error {
+		value := skip(int(dec.InputOffset()))
+		if key < 0 {
+			key = value
+		}
+		entries = append(entries, jsonEntry{path: path, key: key, value: value})
+
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				keyOffset := skip(int(dec.InputOffset()))
+				name, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				child := name.(string)
+				if path != "" {
+					child = path + "." + child
+				}
+				if err := walk(child, keyOffset); err != nil {
+					return err
+				}
+			}
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				if err := walk(fmt.Sprintf("%s[%d]", path, i), -1); err != nil {
+					return err
+				}
+			}
+		default:
+			return nil
+		}
+		_, err = dec.Token() // closing delimiter
+		return err
+	}
+
+	if err := walk("", -1); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err == nil {
+		return nil, fmt.Errorf("unexpected data after the top-level value")
+	}
+	return entries, nil
+}
+
+// runConfig implements `mutemath config validate`.
+func runConfig(args []string) int {
+	if len(args) > 0 && args[0] == "state-key" {
+		return runStateKey(args[1:])
+	}
+	if len(args) == 0 || args[0] != "validate" {
+		fmt.Fprintf(os.Stderr, "usage: %s config validate [flags]\n       %[1]s config state-key [--print]\n", progName)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("config validate", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to the JSON config file (default "+defaultConfigPath()+")")
+	reason := fs.String("reason", "review_requested", "sample notification: reason")
+	repo := fs.String("repo", "", "sample notification: repository (owner/repo); setting any sample flag evaluates the sample")
+	title := fs.String("title", "", "sample notification: title")
+	subjectType := fs.String("type", "PullRequest", "sample notification: subject type")
+	teams := fs.String("teams", "", "sample notification: comma-separated requested team slugs")
+	users := fs.String("users", "", "sample notification: comma-separated requested user logins")
+	author := fs.String("author", "", "sample notification: PR author")
+	draft := fs.Bool("draft", false, "sample notification: PR is a draft")
+	labels := fs.String("labels", "", "sample notification: comma-separated PR labels")
+	body := fs.String("body", "", "sample notification: PR description")
+	assignees := fs.String("assignees", "", "sample notification: comma-separated PR assignee logins")
+	head := fs.String("head", "", "sample notification: PR head branch")
+	base := fs.String("base", "main", "sample notification: PR base branch")
+	files := fs.String("files", "", "sample notification: comma-separated paths the PR changes")
+	reviewDecision := fs.String("review-decision", "", "sample notification: PR review decision (APPROVED, CHANGES_REQUESTED, REVIEW_REQUIRED)")
+	login := fs.String("login", "", "your username, for rules that compare against login")
+	at := fs.String("at", "", "when the sample arrives, RFC 3339, for time.* fields and defer (default now)")
+	fs.Parse(args[1:])
+	now := time.Now()
+	if *at != "" {
+		t, err := time.Parse(time.RFC3339, *at)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --at: %s\n", err)
+			return 1
+		}
+		now = t
+	}
+
+	path, _ := resolveConfigPath(*configPath)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: read config: %s\n", err)
+		return 1
+	}
+
+	cfg, diags := checkConfig(data)
+	for _, d := range diags {
+		fmt.Println(core.FormatConfigDiagnostic(path, d))
+	}
+	if core.HasErrors(diags) {
+		return 1
+	}
+	fmt.Printf("%s: %d rules OK\n", path, len(cfg.rules))
+	if len(cfg.keepAuthors) > 0 {
+		fmt.Printf("always keeping review requests from %s\n", strings.Join(cfg.keepAuthors, ", "))
+	}
+	if cfg.keepMentions {
+		fmt.Println("keeping team-only review requests that @-mention you")
+	}
+	if cfg.keepAssigned {
+		fmt.Println("keeping review requests on PRs assigned to you")
+	}
+	if cfg.muteApproved {
+		fmt.Println("muting team-only review requests on approved PRs")
+	}
+	if cfg.muteTeammateReviewing {
+		fmt.Println("muting team-only review requests a teammate is already reviewing")
+	}
+	if cfg.keepFailedChecks {
+		fmt.Println("keeping failed CI runs and muting successful ones")
+	}
+	if cfg.deadlineWithin > 0 {
+		fmt.Printf("keeping team-only review requests due within %d days\n", int(cfg.deadlineWithin.Hours()/24))
+	}
+	if cfg.pinParticipated {
+		fmt.Println("keeping and pinning PRs you've reviewed or commented on")
+	}
+	if len(cfg.pins) > 0 {
+		fmt.Printf("never touching %d pinned threads\n", len(cfg.pins))
+	}
+	if cfg.onCall != nil {
+		fmt.Println("keeping team-only review requests while you're on call")
+	}
+	if cfg.businessHours != nil {
+		fmt.Printf("business hours %s to %s, %s\n", fmtClock(cfg.businessHours.Start), fmtClock(cfg.businessHours.End), cfg.businessHours.Location)
+	}
+	if cfg.scoring != nil {
+		fmt.Printf("scoring review requests: keep at %d, mute below %d, snooze %s in between\n", cfg.scoring.KeepAt, cfg.scoring.MuteBelow, cfg.scoring.SnoozeFor)
+	}
+	for _, h := range cfg.hosts {
+		fmt.Printf("host %s (token from $%s)\n", h.Host, h.TokenEnv)
+	}
+	for _, u := range cfg.users {
+		fmt.Printf("user %s (token from $%s)\n", u.Name, u.TokenEnv)
+	}
+	if !cfg.policy.IsZero() {
+		fmt.Printf("extends shared policy %s (not fetched; run %s doctor to check it)\n", cfg.policy, progName)
+	}
+
+	evaluate := false
+	fs.Visit(func(f *flag.Flag) { evaluate = evaluate || f.Name != "config" })
+	if !evaluate {
+		return 0
+	}
+
+	sample := core.Sample{
+		Reason:         *reason,
+		Repo:           *repo,
+		Title:          *title,
+		Type:           *subjectType,
+		Teams:          splitList(*teams),
+		Users:          splitList(*users),
+		Author:         *author,
+		Draft:          *draft,
+		Labels:         splitList(*labels),
+		Body:           *body,
+		Assignees:      splitList(*assignees),
+		Head:           *head,
+		Base:           *base,
+		Files:          splitList(*files),
+		ReviewDecision: *reviewDecision,
+	}
+	d := core.Decide(sample.Notification(), sample.Facts(now), *login, sampleConfig(cfg))
+	fmt.Printf("sample: %s (%s)\n", d.Action, d.Reason)
+	return 0
+}
+
+// sampleConfig is the config samples are decided under: the file's
+// classification settings, without those needing lookups a sample can't
+// describe, like team members.
+func sampleConfig(cfg localConfig) core.Config {
+	return core.Config{
+		Rules:         cfg.rules,
+		KeepAuthors:   cfg.keepAuthors,
+		KeepMentions:  cfg.keepMentions,
+		KeepAssigned:  cfg.keepAssigned,
+		MuteApproved:  cfg.muteApproved,
+		BusinessHours: cfg.businessHours,
+		Scoring:       cfg.scoring,
+
+		KeepFailedChecks: cfg.keepFailedChecks,
+		DeadlineWithin:   cfg.deadlineWithin,
+	}
+}
+
+// fmtClock renders a time since midnight as HH:MM.
+func fmtClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// apiTokenEnvVar holds the bearer token the control API requires.
+const apiTokenEnvVar = "MUTEMATH_API_TOKEN"
+
+// daemonControl lets the control API drive running daemons: wake them for a
+// cycle, or pause them. A nil *daemonControl never wakes or pauses.
+type daemonControl struct {
+	token   string                   // the bearer token every request needs
+	clients map[string]*GitHubClient // by host or user, for undo
+
+	mu     sync.Mutex
+	wake   chan struct{} // closed to wake every daemon, then replaced
+	paused bool
+}
+
+func newDaemonControl(token string, clients []*GitHubClient) *daemonControl {
+	c := &daemonControl{token: token, clients: make(map[string]*GitHubClient, len(clients)), wake: make(chan struct{})}
+	for _, client := range clients {
+		c.clients[client.name()] = client
+	}
+	return c
+}
+
+// Woken returns a channel closed when a cycle is triggered; nil, which never
+// receives, for a nil control.
+func (c *daemonControl) Woken() <-chan struct{} {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.wake
+}
+
+// Trigger wakes every daemon waiting for its next poll.
+func (c *daemonControl) Trigger() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	close(c.wake)
+	c.wake = make(chan struct{})
+}
+
+// Paused reports whether cycles are paused.
+func (c *daemonControl) Paused() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
+
+// SetPaused pauses or resumes cycles. A change wakes the daemons, so they
+// don't sit out the rest of a poll interval first.
+func (c *daemonControl) SetPaused(paused bool) {
+	c.mu.Lock()
+	was := c.paused
+	c.paused = paused
+	c.mu.Unlock()
+	if was != paused {
+		c.Trigger()
+	}
+}
+
+// Control API JSON types — these never leave this file.
+
+type apiDecision struct {
+	Host      string    `json:"host,omitempty"`
+	ThreadID  string    `json:"thread_id"`
+	Label     string    `json:"label"`
+	Title     string    `json:"title"`
+	Reason    string    `json:"reason"` // the notification's reason, e.g. "review_requested"
+	UpdatedAt time.Time `json:"updated_at"`
+	Action    string    `json:"action"`
+	Why       string    `json:"why"`
+	Rule      string    `json:"rule,omitempty"`
+	Until     time.Time `json:"until,omitzero"`
+}
+
+type apiPause struct {
+	Paused *bool `json:"paused"`
+}
+
+type apiUndo struct {
+	Host     string `json:"host"`
+	User     string `json:"user"`
+	ThreadID string `json:"thread_id"`
+}
+
+type apiUndone struct {
+	Host     string `json:"host,omitempty"`
+	User     string `json:"user,omitempty"`
+	ThreadID string `json:"thread_id"`
+	Label    string `json:"label"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// handleAPI registers the control API on mux, behind the bearer token.
+func (c *daemonControl) handleAPI(mux *http.ServeMux) {
+	auth := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(c.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="mutemath"`)
+				writeAPI(w, http.StatusUnauthorized, apiError{"missing or wrong bearer token"})
+				return
+			}
+			h(w, r)
+		}
+	}
+	mux.HandleFunc("GET /api/decisions", auth(c.listDecisions))
+	mux.HandleFunc("POST /api/cycle", auth(func(w http.ResponseWriter, r *http.Request) {
+		if c.Paused() {
+			writeAPI(w, http.StatusConflict, apiError{"cycles are paused"})
+			return
+		}
+		c.Trigger()
+		writeAPI(w, http.StatusAccepted, struct {
+			Triggered bool `json:"triggered"`
+		}{true})
+	}))
+	mux.HandleFunc("GET /api/pause", auth(func(w http.ResponseWriter, r *http.Request) {
+		paused := c.Paused()
+		writeAPI(w, http.StatusOK, apiPause{&paused})
+	}))
+	mux.HandleFunc("POST /api/pause", auth(c.setPause))
+	mux.HandleFunc("POST /api/undo", auth(c.undo))
+}
+
+// listDecisions serves the latest decision on each thread the daemons have
+// seen, newest first, as the dashboard shows them.
+func (c *daemonControl) listDecisions(w http.ResponseWriter, r *http.Request) {
+	dashboard.mu.Lock()
+	threads := dashboard.d.Threads
+	dashboard.mu.Unlock()
+
+	out := make([]apiDecision, 0, len(threads))
+	for _, d := range threads {
+		n := d.Notification
+		out = append(out, apiDecision{
+			Host:      n.Host,
+			ThreadID:  n.ID,
+			Label:     core.NotificationLabel(n),
+			Title:     n.Subject.Title,
+			Reason:    n.Reason,
+			UpdatedAt: n.UpdatedAt,
+			Action:    d.Action.String(),
+			Why:       d.Reason,
+			Rule:      d.Rule,
+			Until:     d.Until,
+		})
+	}
+	writeAPI(w, http.StatusOK, out)
+}
+
+// setPause sets whether cycles are paused from {"paused": true}, or toggles
+// it without a body.
+func (c *daemonControl) setPause(w http.ResponseWriter, r *http.Request) {
+	var req apiPause
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeAPI(w, http.StatusBadRequest, apiError{err.Error()})
+		return
+	}
+	paused := !c.Paused()
+	if req.Paused != nil {
+		paused = *req.Paused
+	}
+	c.SetPaused(paused)
+	writeAPI(w, http.StatusOK, apiPause{&paused})
+}
+
+// undo reverses a thread's latest mute, like mutemath undo --apply.
+func (c *daemonControl) undo(w http.ResponseWriter, r *http.Request) {
+	var req apiUndo
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ThreadID == "" {
+		writeAPI(w, http.StatusBadRequest, apiError{`want {"thread_id": "…"}, plus "host" with several hosts or "user" with several users`})
+		return
+	}
+	name := req.Host
+	if req.User != "" {
+		name = req.User
+	}
+	client, ok := c.clients[name]
+	if !ok {
+		writeAPI(w, http.StatusNotFound, apiError{fmt.Sprintf("%q isn't a host or user this daemon runs for", name)})
+		return
+	}
+	var records []core.MutationRecord
+	if !journalOff {
+		st, err := client.store()
+		if err == nil {
+			records, err = readJournalFrom(st)
+		}
+		if err != nil {
+			writeAPI(w, http.StatusInternalServerError, apiError{err.Error()})
+			return
+		}
+	}
+	m, err := core.UndoCandidate(records, client.host, req.ThreadID)
+	if err != nil {
+		writeAPI(w, http.StatusNotFound, apiError{err.Error()})
+		return
+	}
+	// A thread that was already ignored before the mute stays ignored.
+	if m.Ignored {
+		if err := client.UnignoreThread(m.ThreadID); err != nil {
+			writeAPI(w, http.StatusBadGateway, apiError{err.Error()})
+			return
+		}
+	}
+	recordMutation(client, core.RecordUndo(m, time.Now()))
+	log.Printf("control API: undid the mute of %s", m.Label)
+	writeAPI(w, http.StatusOK, apiUndone{Host: m.Host, User: client.user, ThreadID: m.ThreadID, Label: m.Label})
+}
+
+func writeAPI(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("warning: control API: %s", err)
+	}
+}
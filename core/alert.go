@@ -0,0 +1,144 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Alert is a message about a kept notification, delivered by a notifier sink.
+type Alert struct {
+	Title string
+	Body  string
+	URL   string // browser URL of the subject, empty if unknown
+}
+
+// NotifySinks lists the valid --notify sink names.
+var NotifySinks = []string{"desktop", "ntfy", "pushover", "slack", "discord", "matrix"}
+
+// NotifierSettings holds the per-sink settings, read by the shell from the environment.
+type NotifierSettings struct {
+	NtfyTopic     string // topic name on ntfy.sh, or a full URL for a self-hosted server
+	NtfyToken     string // optional access token
+	PushoverToken string // application API token
+	PushoverUser  string // user or group key
+
+	SlackWebhookURL   string // incoming webhook URL
+	DiscordWebhookURL string // channel webhook URL
+	MatrixHomeserver  string // e.g. https://matrix.org
+	MatrixRoomID      string // e.g. !abcdef:matrix.org
+	MatrixAccessToken string // access token of the posting account
+}
+
+// Validate checks that every selected sink has the settings it needs.
+func (s NotifierSettings) Validate(sinks []string) error {
+	for _, sink := range sinks {
+		switch sink {
+		case "ntfy":
+			if s.NtfyTopic == "" {
+				return fmt.Errorf("--notify ntfy requires NTFY_TOPIC")
+			}
+		case "pushover":
+			if s.PushoverToken == "" || s.PushoverUser == "" {
+				return fmt.Errorf("--notify pushover requires PUSHOVER_TOKEN and PUSHOVER_USER")
+			}
+		case "slack":
+			if s.SlackWebhookURL == "" {
+				return fmt.Errorf("--notify slack requires SLACK_WEBHOOK_URL")
+			}
+		case "discord":
+			if s.DiscordWebhookURL == "" {
+				return fmt.Errorf("--notify discord requires DISCORD_WEBHOOK_URL")
+			}
+		case "matrix":
+			if s.MatrixHomeserver == "" || s.MatrixRoomID == "" || s.MatrixAccessToken == "" {
+				return fmt.Errorf("--notify matrix requires MATRIX_HOMESERVER, MATRIX_ROOM_ID, and MATRIX_ACCESS_TOKEN")
+			}
+		}
+	}
+	return nil
+}
+
+// NtfyURL returns the publish URL for an ntfy topic. Bare topic names publish to ntfy.sh.
+func NtfyURL(topic string) string {
+	if strings.HasPrefix(topic, "https://") || strings.HasPrefix(topic, "http://") {
+		return topic
+	}
+	return "https://ntfy.sh/" + topic
+}
+
+// ParseNotifySinks parses a comma-separated --notify value into sink names,
+// dropping duplicates. An empty string means no sinks.
+func ParseNotifySinks(s string) ([]string, error) {
+	var sinks []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || slices.Contains(sinks, name) {
+			continue
+		}
+		if !slices.Contains(NotifySinks, name) {
+			return nil, fmt.Errorf("unknown notifier %q (valid values: %s)", name, strings.Join(NotifySinks, ", "))
+		}
+		sinks = append(sinks, name)
+	}
+	return sinks, nil
+}
+
+// HTMLURL converts a subject API URL into the browser URL for the same PR,
+// e.g. https://api.github.com/repos/org/repo/pulls/42 → https://github.com/org/repo/pull/42.
+// Returns "" for URLs that aren't PRs.
+func HTMLURL(apiURL string) string {
+	ref, err := ParseSubjectURL(apiURL)
+	if err != nil {
+		return ""
+	}
+	web := "https://github.com"
+	if !strings.HasPrefix(apiURL, "https://api.github.com/") {
+		// GHES: https://host/api/v3/repos/... → https://host
+		i := strings.Index(apiURL, "/api/v3/")
+		if i < 0 {
+			return ""
+		}
+		web = apiURL[:i]
+	}
+	return fmt.Sprintf("%s/%s/%s/pull/%d", web, ref.Owner, ref.Repo, ref.Number)
+}
+
+// AlertForDecision builds the alert for a kept notification.
+func AlertForDecision(d Decision) Alert {
+	title := "Review requested: %s"
+	if d.Notification.Subject.Type == "CheckSuite" {
+		title = "CI failed: %s"
+	}
+	return Alert{
+		Title: fmt.Sprintf(title, formatLabel(d)),
+		Body:  d.Notification.Subject.Title,
+		URL:   HTMLURL(d.Notification.Subject.URL),
+	}
+}
+
+// AlertKey identifies one version of a thread, so a thread that is updated
+// (e.g. a re-requested review) alerts again.
+func AlertKey(n Notification) string {
+	return n.ID + "@" + n.UpdatedAt.UTC().Format(time.RFC3339)
+}
+
+// PendingAlerts returns the KEEP decisions that haven't been alerted yet, and
+// the alerted set to carry into the next cycle. The returned set only holds
+// threads still kept in this cycle, so it doesn't grow without bound.
+func PendingAlerts(decisions []Decision, alerted map[string]bool) ([]Decision, map[string]bool) {
+	var pending []Decision
+	next := make(map[string]bool)
+	for _, d := range decisions {
+		if d.Action != ActionKeep {
+			continue
+		}
+		key := AlertKey(d.Notification)
+		if !alerted[key] {
+			pending = append(pending, d)
+		}
+		next[key] = true
+	}
+	return pending, next
+}
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseNotifySinks(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{input: "", want: nil},
+		{input: "desktop", want: []string{"desktop"}},
+		{input: " Desktop , desktop", want: []string{"desktop"}},
+		{input: "desktop,,", want: []string{"desktop"}},
+		{input: "ntfy,pushover", want: []string{"ntfy", "pushover"}},
+		{input: "slack,discord,matrix", want: []string{"slack", "discord", "matrix"}},
+		{input: "carrier-pigeon", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseNotifySinks(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseNotifySinks(%q) = %v, want error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseNotifySinks(%q) error = %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseNotifySinks(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestNotifierSettingsValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings NotifierSettings
+		sinks    []string
+		wantErr  bool
+	}{
+		{name: "no sinks", sinks: nil},
+		{name: "desktop needs nothing", sinks: []string{"desktop"}},
+		{name: "ntfy with topic", settings: NotifierSettings{NtfyTopic: "reviews"}, sinks: []string{"ntfy"}},
+		{name: "ntfy without topic", sinks: []string{"ntfy"}, wantErr: true},
+		{
+			name:     "pushover complete",
+			settings: NotifierSettings{PushoverToken: "t", PushoverUser: "u"},
+			sinks:    []string{"pushover"},
+		},
+		{
+			name:     "pushover missing user",
+			settings: NotifierSettings{PushoverToken: "t"},
+			sinks:    []string{"pushover"},
+			wantErr:  true,
+		},
+		{name: "slack without webhook", sinks: []string{"slack"}, wantErr: true},
+		{name: "discord without webhook", sinks: []string{"discord"}, wantErr: true},
+		{
+			name:     "matrix complete",
+			settings: NotifierSettings{MatrixHomeserver: "https://matrix.org", MatrixRoomID: "!r:matrix.org", MatrixAccessToken: "t"},
+			sinks:    []string{"matrix"},
+		},
+		{
+			name:     "matrix missing token",
+			settings: NotifierSettings{MatrixHomeserver: "https://matrix.org", MatrixRoomID: "!r:matrix.org"},
+			sinks:    []string{"matrix"},
+			wantErr:  true,
+		},
+		{
+			name:     "unused sink settings are not required",
+			settings: NotifierSettings{NtfyTopic: "reviews"},
+			sinks:    []string{"ntfy", "desktop"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.settings.Validate(tt.sinks)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNtfyURL(t *testing.T) {
+	tests := []struct {
+		topic string
+		want  string
+	}{
+		{topic: "my-reviews", want: "https://ntfy.sh/my-reviews"},
+		{topic: "https://ntfy.example.com/reviews", want: "https://ntfy.example.com/reviews"},
+		{topic: "http://localhost:8080/reviews", want: "http://localhost:8080/reviews"},
+	}
+	for _, tt := range tests {
+		if got := NtfyURL(tt.topic); got != tt.want {
+			t.Errorf("NtfyURL(%q) = %q, want %q", tt.topic, got, tt.want)
+		}
+	}
+}
+
+func TestHTMLURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://api.github.com/repos/org/repo/pulls/42", want: "https://github.com/org/repo/pull/42"},
+		{url: "https://ghes.example.com/api/v3/repos/org/repo/pulls/7", want: "https://ghes.example.com/org/repo/pull/7"},
+		{url: "https://api.github.com/repos/org/repo/issues/42", want: ""},
+		{url: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := HTMLURL(tt.url); got != tt.want {
+			t.Errorf("HTMLURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestAlertForDecision(t *testing.T) {
+	d := Decision{
+		Notification: Notification{
+			Subject:    Subject{Title: "Fix bug", URL: "https://api.github.com/repos/org/repo/pulls/42", Type: "PullRequest"},
+			Repository: Repository{FullName: "org/repo"},
+		},
+		Action: ActionKeep,
+	}
+	want := Alert{
+		Title: "Review requested: org/repo#42",
+		Body:  "Fix bug",
+		URL:   "https://github.com/org/repo/pull/42",
+	}
+	if got := AlertForDecision(d); got != want {
+		t.Errorf("AlertForDecision() = %+v, want %+v", got, want)
+	}
+
+	d.Notification.Subject = Subject{Title: "CI workflow run failed for main branch", Type: "CheckSuite"}
+	want = Alert{Title: "CI failed: org/repo", Body: "CI workflow run failed for main branch"}
+	if got := AlertForDecision(d); got != want {
+		t.Errorf("AlertForDecision() for a CheckSuite = %+v, want %+v", got, want)
+	}
+}
+
+func TestPendingAlerts(t *testing.T) {
+	t0 := time.Date(2026, 2, 27, 10, 0, 0, 0, time.UTC)
+	keep := func(id string, updated time.Time) Decision {
+		return Decision{Notification: Notification{ID: id, UpdatedAt: updated}, Action: ActionKeep}
+	}
+	mute := Decision{Notification: Notification{ID: "9", UpdatedAt: t0}, Action: ActionMute}
+
+	// First cycle: everything kept is new.
+	pending, alerted := PendingAlerts([]Decision{keep("1", t0), keep("2", t0), mute}, map[string]bool{})
+	if len(pending) != 2 {
+		t.Fatalf("first cycle: got %d pending, want 2", len(pending))
+	}
+
+	// Second cycle: thread 1 unchanged, thread 2 updated, thread 3 new.
+	pending, alerted = PendingAlerts([]Decision{keep("1", t0), keep("2", t0.Add(time.Hour)), keep("3", t0)}, alerted)
+	var ids []string
+	for _, d := range pending {
+		ids = append(ids, d.Notification.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"2", "3"}) {
+		t.Errorf("second cycle: pending = %v, want [2 3]", ids)
+	}
+
+	// Threads that drop out of the inbox are forgotten.
+	_, alerted = PendingAlerts([]Decision{keep("3", t0)}, alerted)
+	if len(alerted) != 1 {
+		t.Errorf("alerted set = %v, want only thread 3", alerted)
+	}
+}
+
+func TestPendingAlertsDoesNotMutateInput(t *testing.T) {
+	alerted := map[string]bool{"old@2026-01-01T00:00:00Z": true}
+	PendingAlerts([]Decision{{Notification: Notification{ID: "1"}, Action: ActionKeep}}, alerted)
+	if len(alerted) != 1 || !alerted["old@2026-01-01T00:00:00Z"] {
+		t.Errorf("input map was modified: %v", alerted)
+	}
+}
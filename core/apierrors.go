@@ -0,0 +1,166 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The GitHub client's failed responses are typed by kind, so callers branch
+// with errors.As rather than on the message. Each message still starts with
+// "unexpected status N", like any other failed response.
+
+// RateLimitError is a response refused by GitHub's primary rate limit, or by
+// a secondary limit asking to wait Retry-After.
+type RateLimitError struct {
+	Status     int
+	Reset      time.Time     // when the primary limit resets; zero if not sent
+	RetryAfter time.Duration // the secondary limit's wait; zero if not sent
+}
+
+func (e *RateLimitError) Error() string {
+	switch {
+	case !e.Reset.IsZero():
+		return fmt.Sprintf("unexpected status %d (rate limited until %s)", e.Status, e.Reset.UTC().Format("15:04:05 UTC"))
+	case e.RetryAfter > 0:
+		return fmt.Sprintf("unexpected status %d (rate limited for %s)", e.Status, e.RetryAfter)
+	}
+	return fmt.Sprintf("unexpected status %d (rate limited)", e.Status)
+}
+
+// Until is when the request may be made again: the reset, or RetryAfter
+// from now. Zero if neither was sent.
+func (e *RateLimitError) Until(now time.Time) time.Time {
+	if e.Reset.IsZero() && e.RetryAfter > 0 {
+		return now.Add(e.RetryAfter)
+	}
+	return e.Reset
+}
+
+// AuthError is a response refusing the token: 401 for a bad or expired one,
+// or 403 for one lacking a scope or SSO authorization.
+type AuthError struct {
+	Status   int
+	Scopes   []string // the token's scopes, from X-OAuth-Scopes; nil for a fine-grained token
+	Accepted []string // the scopes the endpoint accepts, from X-Accepted-OAuth-Scopes
+	SSO      bool     // the org requires SAML SSO authorization for the token
+}
+
+func (e *AuthError) Error() string {
+	switch {
+	case e.Status == 401:
+		return fmt.Sprintf("unexpected status %d (bad or expired token)", e.Status)
+	case e.SSO:
+		return fmt.Sprintf("unexpected status %d (token isn't authorized for the org's SAML SSO)", e.Status)
+	case len(e.Accepted) > 0 && len(e.Missing()) > 0:
+		has := strings.Join(e.Scopes, ", ")
+		if has == "" {
+			has = "none"
+		}
+		return fmt.Sprintf("unexpected status %d (token lacks scope %s; it has %s)", e.Status, strings.Join(e.Missing(), " or "), has)
+	}
+	return fmt.Sprintf("unexpected status %d (forbidden)", e.Status)
+}
+
+// Missing lists the accepted scopes the token lacks: all of them if it has
+// none of them, since any one would do.
+func (e *AuthError) Missing() []string {
+	for _, a := range e.Accepted {
+		for _, s := range e.Scopes {
+			if s == a {
+				return nil
+			}
+		}
+	}
+	return e.Accepted
+}
+
+// NotFoundError is a 404. GitHub answers it for private resources the token
+// can't see, too.
+type NotFoundError struct{}
+
+func (e *NotFoundError) Error() string {
+	return "unexpected status 404"
+}
+
+// ServerError is a 5xx response: GitHub failed, and a retry may succeed.
+type ServerError struct {
+	Status int
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("unexpected status %d", e.Status)
+}
+
+// defaultRetryAfter is how long to wait on a rate limit that doesn't say.
+const defaultRetryAfter = 60 * time.Second
+
+// RetryAfter parses a Retry-After header in seconds, or defaultRetryAfter if
+// it's missing or malformed.
+func RetryAfter(h string) time.Duration {
+	secs, err := strconv.Atoi(h)
+	if err != nil {
+		return defaultRetryAfter
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// StatusError builds the error for a response with an unexpected status,
+// typed by kind where there is one. header looks up a response header, and
+// body is the start of the response body. A 403 is a rate limit, not a
+// refused token, when it asks to wait, the primary limit is spent, or the
+// body names a secondary limit, which GitHub doesn't always send
+// Retry-After for.
+func StatusError(status int, header func(string) string, body []byte) error {
+	spent := header("X-RateLimit-Remaining") == "0"
+	switch {
+	case status == 429 || (status == 403 && (header("Retry-After") != "" || spent || secondaryRateLimit(body))):
+		e := &RateLimitError{Status: status}
+		if secs, err := strconv.ParseInt(header("X-RateLimit-Reset"), 10, 64); err == nil && spent {
+			e.Reset = time.Unix(secs, 0).UTC()
+		}
+		if h := header("Retry-After"); h != "" {
+			e.RetryAfter = RetryAfter(h)
+		}
+		return e
+	case status == 401 || status == 403:
+		return &AuthError{
+			Status:   status,
+			Scopes:   splitScopes(header("X-OAuth-Scopes")),
+			Accepted: splitScopes(header("X-Accepted-OAuth-Scopes")),
+			SSO:      header("X-GitHub-SSO") != "",
+		}
+	case status == 404:
+		return &NotFoundError{}
+	case status >= 500:
+		return &ServerError{Status: status}
+	}
+	return fmt.Errorf("unexpected status %d", status)
+}
+
+// secondaryRateLimit reports whether a response body is GitHub's secondary
+// rate limit message, "You have exceeded a secondary rate limit...".
+func secondaryRateLimit(body []byte) bool {
+	return strings.Contains(strings.ToLower(string(body)), "secondary rate limit")
+}
+
+// splitScopes splits a comma-separated scopes header.
+func splitScopes(h string) []string {
+	var scopes []string
+	for _, s := range strings.Split(h, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+// Retryable reports whether a failed request may succeed if made again: not
+// when the token was refused or the thing isn't there.
+func Retryable(err error) bool {
+	var authErr *AuthError
+	var notFound *NotFoundError
+	return !errors.As(err, &authErr) && !errors.As(err, &notFound)
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStatusError(t *testing.T) {
+	reset := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		status    int
+		header    map[string]string
+		body      string
+		want      error
+		retryable bool
+	}{
+		{
+			name:      "primary rate limit",
+			status:    403,
+			header:    map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": fmt.Sprint(reset.Unix())},
+			body:      `{"message":"API rate limit exceeded for user ID 1."}`,
+			want:      &RateLimitError{Status: 403, Reset: reset},
+			retryable: true,
+		},
+		{
+			name:      "secondary rate limit with Retry-After",
+			status:    403,
+			header:    map[string]string{"Retry-After": "30", "X-RateLimit-Remaining": "4000"},
+			want:      &RateLimitError{Status: 403, RetryAfter: 30 * time.Second},
+			retryable: true,
+		},
+		{
+			name:      "secondary rate limit named only in the body",
+			status:    403,
+			header:    map[string]string{"X-RateLimit-Remaining": "4000", "X-OAuth-Scopes": "notifications, repo"},
+			body:      `{"message":"You have exceeded a secondary rate limit. Please wait a few minutes before you try again."}`,
+			want:      &RateLimitError{Status: 403},
+			retryable: true,
+		},
+		{
+			name:      "too many requests",
+			status:    429,
+			header:    map[string]string{"Retry-After": "oops"},
+			want:      &RateLimitError{Status: 429, RetryAfter: defaultRetryAfter},
+			retryable: true,
+		},
+		{
+			name:   "token lacks a scope",
+			status: 403,
+			header: map[string]string{"X-RateLimit-Remaining": "4000", "X-OAuth-Scopes": "notifications", "X-Accepted-OAuth-Scopes": "repo"},
+			body:   `{"message":"Resource not accessible by personal access token"}`,
+			want:   &AuthError{Status: 403, Scopes: []string{"notifications"}, Accepted: []string{"repo"}},
+		},
+		{
+			name:   "SSO",
+			status: 403,
+			header: map[string]string{"X-GitHub-SSO": "required; url=https://github.com/orgs/acme/sso"},
+			want:   &AuthError{Status: 403, SSO: true},
+		},
+		{
+			name:   "bad token",
+			status: 401,
+			body:   `{"message":"Bad credentials"}`,
+			want:   &AuthError{Status: 401},
+		},
+		{
+			name:   "not found",
+			status: 404,
+			want:   &NotFoundError{},
+		},
+		{
+			name:      "server error",
+			status:    502,
+			want:      &ServerError{Status: 502},
+			retryable: true,
+		},
+		{
+			name:      "other",
+			status:    422,
+			want:      errors.New("unexpected status 422"),
+			retryable: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := func(k string) string { return tt.header[k] }
+			err := StatusError(tt.status, header, []byte(tt.body))
+			if !reflect.DeepEqual(err, tt.want) {
+				t.Errorf("StatusError() = %T %+v, want %T %+v", err, err, tt.want, tt.want)
+			}
+			if got := Retryable(fmt.Errorf("mark thread 1: %w", err)); got != tt.retryable {
+				t.Errorf("Retryable(%v) = %v, want %v", err, got, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestAuthErrorMessage(t *testing.T) {
+	tests := []struct {
+		err  *AuthError
+		want string
+	}{
+		{&AuthError{Status: 401}, "unexpected status 401 (bad or expired token)"},
+		{&AuthError{Status: 403, SSO: true}, "unexpected status 403 (token isn't authorized for the org's SAML SSO)"},
+		{&AuthError{Status: 403, Accepted: []string{"repo"}}, "unexpected status 403 (token lacks scope repo; it has none)"},
+		{&AuthError{Status: 403, Scopes: []string{"repo"}, Accepted: []string{"repo"}}, "unexpected status 403 (forbidden)"},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestRateLimitErrorUntil(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	if got := (&RateLimitError{RetryAfter: time.Minute}).Until(now); !got.Equal(now.Add(time.Minute)) {
+		t.Errorf("Until() with Retry-After = %s, want a minute from now", got)
+	}
+	if got := (&RateLimitError{Reset: now.Add(time.Hour), RetryAfter: time.Minute}).Until(now); !got.Equal(now.Add(time.Hour)) {
+		t.Errorf("Until() with a reset = %s, want the reset", got)
+	}
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"container/list"
+	"fmt"
+)
+
+// LRU is a map of at most Size entries that evicts the least recently used
+// one to make room. It isn't safe for concurrent use.
+type LRU[K comparable, V any] struct {
+	Size  int
+	order *list.List // front is the most recently used
+	items map[K]*list.Element
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// Get returns the value for key, marking it used.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	if e, ok := c.items[key]; ok {
+		c.order.MoveToFront(e)
+		return e.Value.(*lruEntry[K, V]).value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Put sets the value for key, evicting the least recently used entry if the
+// LRU is full.
+func (c *LRU[K, V]) Put(key K, value V) {
+	if c.items == nil {
+		c.order, c.items = list.New(), make(map[K]*list.Element)
+	}
+	if e, ok := c.items[key]; ok {
+		e.Value.(*lruEntry[K, V]).value = value
+		c.order.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruEntry[K, V]{key, value})
+	for c.order.Len() > max(c.Size, 1) {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry[K, V]).key)
+	}
+}
+
+// Len is the number of entries.
+func (c *LRU[K, V]) Len() int {
+	return len(c.items)
+}
+
+// CacheStats counts how the HTTP cache served a run's or daemon cycle's GETs:
+// Hits were revalidated by a 304 and served from the cache, which doesn't
+// count against the rate limit, and Misses were fetched in full.
+type CacheStats struct {
+	Hits, Misses int
+}
+
+// FormatCacheStats renders the cache stats for --verbose, or "" if no GET
+// could use the cache.
+func FormatCacheStats(s CacheStats, entries int) string {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("HTTP cache: %d of %d GETs not modified (%d%%), %d responses cached", s.Hits, total, s.Hits*100/total, entries)
+}
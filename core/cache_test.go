@@ -0,0 +1,39 @@
+package core
+
+import "testing"
+
+func TestLRU(t *testing.T) {
+	c := LRU[string, int]{Size: 2}
+	c.Put("a", 1)
+	c.Put("b", 2)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("Get(a) = %d, %v, want 1, true", v, ok)
+	}
+	c.Put("c", 3) // evicts b, used less recently than a
+	if _, ok := c.Get("b"); ok {
+		t.Error("Get(b) found an evicted entry")
+	}
+	c.Put("a", 10)
+	if v, _ := c.Get("a"); v != 10 || c.Len() != 2 {
+		t.Errorf("after replacing a: Get(a) = %d, Len() = %d, want 10, 2", v, c.Len())
+	}
+	if v, ok := c.Get("c"); !ok || v != 3 {
+		t.Errorf("Get(c) = %d, %v, want 3, true", v, ok)
+	}
+}
+
+func TestFormatCacheStats(t *testing.T) {
+	tests := []struct {
+		s       CacheStats
+		entries int
+		want    string
+	}{
+		{CacheStats{}, 0, ""},
+		{CacheStats{Hits: 3, Misses: 9}, 9, "HTTP cache: 3 of 12 GETs not modified (25%), 9 responses cached"},
+	}
+	for _, tt := range tests {
+		if got := FormatCacheStats(tt.s, tt.entries); got != tt.want {
+			t.Errorf("FormatCacheStats(%+v, %d) = %q, want %q", tt.s, tt.entries, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Markup is a chat message dialect. Chat sinks share RenderChat so a new sink
+// only needs to pick a dialect and wrap the result in its payload.
+type Markup int
+
+const (
+	MarkupPlain    Markup = iota // no markup; the URL on its own line
+	MarkupSlack                  // Slack mrkdwn
+	MarkupMarkdown               // CommonMark-ish, as rendered by Discord
+	MarkupHTML                   // HTML subset, as used by Matrix formatted_body
+)
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "[", `\[`, "]", `\]`,
+)
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// RenderChat renders an alert as a chat message in the given dialect: the title
+// emphasized on the first line, then the body linked to the alert URL.
+func RenderChat(a Alert, m Markup) string {
+	switch m {
+	case MarkupSlack:
+		title, body := slackEscaper.Replace(a.Title), slackEscaper.Replace(a.Body)
+		if a.URL == "" {
+			return fmt.Sprintf("*%s*\n%s", title, body)
+		}
+		return fmt.Sprintf("*%s*\n<%s|%s>", title, a.URL, body)
+	case MarkupMarkdown:
+		title, body := markdownEscaper.Replace(a.Title), markdownEscaper.Replace(a.Body)
+		if a.URL == "" {
+			return fmt.Sprintf("**%s**\n%s", title, body)
+		}
+		return fmt.Sprintf("**%s**\n[%s](%s)", title, body, a.URL)
+	case MarkupHTML:
+		title, body := html.EscapeString(a.Title), html.EscapeString(a.Body)
+		if a.URL == "" {
+			return fmt.Sprintf("<b>%s</b><br>%s", title, body)
+		}
+		return fmt.Sprintf(`<b>%s</b><br><a href="%s">%s</a>`, title, html.EscapeString(a.URL), body)
+	default:
+		if a.URL == "" {
+			return a.Title + "\n" + a.Body
+		}
+		return a.Title + "\n" + a.Body + "\n" + a.URL
+	}
+}
@@ -0,0 +1,74 @@
+package core
+
+import "testing"
+
+func TestRenderChat(t *testing.T) {
+	a := Alert{
+		Title: "Review requested: org/repo#42",
+		Body:  "Fix <b>bug</b> & [refactor] *now*",
+		URL:   "https://github.com/org/repo/pull/42",
+	}
+
+	tests := []struct {
+		name   string
+		markup Markup
+		alert  Alert
+		want   string
+	}{
+		{
+			name:   "plain",
+			markup: MarkupPlain,
+			alert:  a,
+			want:   "Review requested: org/repo#42\nFix <b>bug</b> & [refactor] *now*\nhttps://github.com/org/repo/pull/42",
+		},
+		{
+			name:   "slack",
+			markup: MarkupSlack,
+			alert:  a,
+			want:   "*Review requested: org/repo#42*\n<https://github.com/org/repo/pull/42|Fix &lt;b&gt;bug&lt;/b&gt; &amp; [refactor] *now*>",
+		},
+		{
+			name:   "markdown",
+			markup: MarkupMarkdown,
+			alert:  a,
+			want:   "**Review requested: org/repo#42**\n[Fix <b>bug</b> & \\[refactor\\] \\*now\\*](https://github.com/org/repo/pull/42)",
+		},
+		{
+			name:   "html",
+			markup: MarkupHTML,
+			alert:  a,
+			want:   "<b>Review requested: org/repo#42</b><br><a href=\"https://github.com/org/repo/pull/42\">Fix &lt;b&gt;bug&lt;/b&gt; &amp; [refactor] *now*</a>",
+		},
+		{
+			name:   "slack without URL",
+			markup: MarkupSlack,
+			alert:  Alert{Title: "T", Body: "B"},
+			want:   "*T*\nB",
+		},
+		{
+			name:   "markdown without URL",
+			markup: MarkupMarkdown,
+			alert:  Alert{Title: "T", Body: "B"},
+			want:   "**T**\nB",
+		},
+		{
+			name:   "html without URL",
+			markup: MarkupHTML,
+			alert:  Alert{Title: "T", Body: "B"},
+			want:   "<b>T</b><br>B",
+		},
+		{
+			name:   "plain without URL",
+			markup: MarkupPlain,
+			alert:  Alert{Title: "T", Body: "B"},
+			want:   "T\nB",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderChat(tt.alert, tt.markup); got != tt.want {
+				t.Errorf("RenderChat() = %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
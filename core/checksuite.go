@@ -0,0 +1,35 @@
+package core
+
+import "strings"
+
+// CheckSuiteConclusion reads how a workflow run ended from its CheckSuite
+// notification's title, like "CI workflow run failed for main branch":
+// "failure", "success", or "" if the title doesn't say.
+func CheckSuiteConclusion(title string) string {
+	t := strings.ToLower(title)
+	switch {
+	case strings.Contains(t, " failed"), strings.Contains(t, " cancelled"), strings.Contains(t, " timed out"):
+		return "failure"
+	case strings.Contains(t, " succeeded"):
+		return "success"
+	default:
+		return ""
+	}
+}
+
+// checkSuiteDecision decides a CheckSuite notification under
+// KeepFailedChecks: keep failed runs, mute successful ones. ok is false for
+// other notifications and runs whose outcome the title doesn't give.
+func checkSuiteDecision(n Notification) (d Decision, ok bool) {
+	if n.Subject.Type != "CheckSuite" {
+		return Decision{}, false
+	}
+	switch CheckSuiteConclusion(n.Subject.Title) {
+	case "failure":
+		return Decision{Notification: n, Action: ActionKeep, Reason: "failed CI run"}, true
+	case "success":
+		return Decision{Notification: n, Action: ActionMute, Reason: "successful CI run"}, true
+	default:
+		return Decision{}, false
+	}
+}
@@ -0,0 +1,48 @@
+package core
+
+import "testing"
+
+func TestCheckSuiteConclusion(t *testing.T) {
+	tests := []struct {
+		title, want string
+	}{
+		{"CI workflow run failed for main branch", "failure"},
+		{"Deploy workflow run cancelled for release branch", "failure"},
+		{"Nightly workflow run timed out for main branch", "failure"},
+		{"CI workflow run succeeded for fix-login branch", "success"},
+		{"CI workflow run is waiting for approval", ""},
+	}
+	for _, tt := range tests {
+		if got := CheckSuiteConclusion(tt.title); got != tt.want {
+			t.Errorf("CheckSuiteConclusion(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestDecideKeepFailedChecks(t *testing.T) {
+	suite := func(title string) Notification {
+		return Notification{ID: "9", Reason: "ci_activity", Subject: Subject{Type: "CheckSuite", Title: title}, Repository: Repository{Owner: "org", FullName: "org/api"}}
+	}
+	muteNightly := mustParseRules(t, RuleSpec{Name: "nightly", When: `notification.title.contains("Nightly")`, Action: "mute"})
+
+	tests := []struct {
+		name       string
+		n          Notification
+		cfg        Config
+		wantAction Action
+		wantReason string
+	}{
+		{name: "failed", n: suite("CI workflow run failed for main branch"), cfg: Config{KeepFailedChecks: true}, wantAction: ActionKeep, wantReason: "failed CI run"},
+		{name: "succeeded", n: suite("CI workflow run succeeded for main branch"), cfg: Config{KeepFailedChecks: true}, wantAction: ActionMute, wantReason: "successful CI run"},
+		{name: "unknown outcome", n: suite("CI workflow run is waiting for approval"), cfg: Config{KeepFailedChecks: true}, wantAction: ActionSkip, wantReason: "not a review-requested PR"},
+		{name: "rule first", n: suite("Nightly workflow run failed for main branch"), cfg: Config{KeepFailedChecks: true, Rules: muteNightly}, wantAction: ActionMute, wantReason: "rule nightly"},
+		{name: "off", n: suite("CI workflow run failed for main branch"), cfg: Config{}, wantAction: ActionSkip, wantReason: "not a review-requested PR"},
+		{name: "filtered org", n: suite("CI workflow run failed for main branch"), cfg: Config{KeepFailedChecks: true, ExcludeOrg: "org"}, wantAction: ActionSkip, wantReason: "filtered by org"},
+	}
+	for _, tt := range tests {
+		d := Decide(tt.n, Facts{}, "me", tt.cfg)
+		if d.Action != tt.wantAction || d.Reason != tt.wantReason {
+			t.Errorf("%s: Decide() = %s (%s), want %s (%s)", tt.name, d.Action, d.Reason, tt.wantAction, tt.wantReason)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -14,6 +15,27 @@ type Notification struct {
 	Reason     string
 	Subject    Subject
 	Repository Repository
+	UpdatedAt  time.Time
+	Host       string     // set when processing several hosts, to qualify labels
+	State      InboxState // unread, or read in a listing of read threads too
+}
+
+// InboxState is where a listed thread stands in GitHub's inbox. The web UI
+// also has done, out of the inbox, but the REST listing only reports whether
+// a thread is unread: a read thread may be done as well, so there's no state
+// for it.
+type InboxState int
+
+const (
+	StateUnread InboxState = iota
+	StateRead
+)
+
+func (s InboxState) String() string {
+	if s == StateRead {
+		return "read"
+	}
+	return "unread"
 }
 
 type Subject struct {
@@ -25,6 +47,7 @@ type Subject struct {
 type Repository struct {
 	FullName string // "org/repo"
 	Owner    string // "org"
+	Private  bool
 }
 
 type Reviewers struct {
@@ -35,9 +58,12 @@ type Reviewers struct {
 type Action int
 
 const (
-	ActionSkip Action = iota // not a review-requested PR
-	ActionKeep               // direct review request — leave alone
-	ActionMute               // team-only spam — ignore + mark read
+	ActionSkip    Action = iota // not a review-requested PR
+	ActionKeep                  // direct review request — leave alone
+	ActionMute                  // team-only spam — ignore + mark read
+	ActionDefer                 // leave alone until business hours, then decide again
+	ActionDim                   // de-emphasize — mark read but stay subscribed
+	ActionArchive               // de-emphasize — mark done but stay subscribed
 )
 
 func (a Action) String() string {
@@ -48,15 +74,45 @@ func (a Action) String() string {
 		return "KEEP"
 	case ActionMute:
 		return "MUTE"
+	case ActionDefer:
+		return "DEFER"
+	case ActionDim:
+		return "DIM"
+	case ActionArchive:
+		return "ARCHIVE"
 	default:
 		return "UNKNOWN"
 	}
 }
 
+// Marks reports whether applying the action marks the thread: a mute, which
+// also ignores it, or a dim or archive, which leave it subscribed.
+func (a Action) Marks() bool {
+	return a == ActionMute || a == ActionDim || a == ActionArchive
+}
+
+// MarkMode is how applying the action marks the thread: a dim marks it read
+// and an archive done, whatever MODE says; a mute follows mode.
+func (a Action) MarkMode(mode Mode) Mode {
+	switch a {
+	case ActionDim:
+		return ModeRead
+	case ActionArchive:
+		return ModeDone
+	}
+	return mode
+}
+
 type Decision struct {
 	Notification Notification
 	Action       Action
 	Reason       string
+	Rule         string    // name of the rule that decided; empty if none did
+	Teams        []string  // requested team slugs, when reviewer data was available
+	Claim        bool      // claimed in an --edit plan: request login's review personally, and keep
+	Until        time.Time // with ActionDefer, when to decide again
+	Pin          bool      // kept because login took part: pin the thread from now on
+	Author       string    // PR author, when PR details were looked up
 }
 
 type PRRef struct {
@@ -66,8 +122,57 @@ type PRRef struct {
 }
 
 type Config struct {
-	IncludeOrg string
-	ExcludeOrg string
+	IncludeOrg    string
+	ExcludeOrg    string
+	IncludeTopics []string        // only process repos with any of these topics
+	ExcludeTopics []string        // skip repos with any of these topics
+	Visibility    string          // "private" or "public" to only process those repos; empty for both
+	Rules         []Rule          // checked in order before the built-in classification
+	Pinned        map[string]bool // thread IDs to always keep, e.g. starred in Octobox
+	Pins          []ThreadTarget  // threads and PRs to always keep, from the config file and mutemath pin
+	KeepAuthors   []string        // PR authors whose review requests are always kept
+	KeepMentions  bool            // keep team-only requests whose PR description @-mentions you
+	KeepAssigned  bool            // keep review requests on PRs assigned to you
+	MuteApproved  bool            // mute team-only requests on PRs already approved, even if a rule keeps them
+
+	// OnCall keeps team-only requests through OnCallTeams, or any team when
+	// it's empty, while the user is reviewer-on-call; see OnCallSpec.
+	OnCall      bool
+	OnCallTeams []string
+
+	// BusinessHours is when the user works, for time.business_hours in rules
+	// and the defer action; nil if not configured.
+	BusinessHours *BusinessHours
+
+	// Scoring decides review requests by priority score instead of the
+	// built-in keep-or-mute; nil for the built-in.
+	Scoring *Scoring
+
+	// MuteTeammateReviewing mutes team-only requests once another member of
+	// the requested team has reviewed or been requested, even if a rule keeps them.
+	MuteTeammateReviewing bool
+
+	// TeamSizeThreshold keeps team-only requests the built-in classification
+	// would mute when the requested team has at most this many members: in a
+	// small team, nobody else may take it. 0 mutes them whatever the size.
+	TeamSizeThreshold int
+
+	// KeepFailedChecks keeps CheckSuite notifications about failed workflow
+	// runs and mutes those about successful ones, unless a rule decides.
+	KeepFailedChecks bool
+
+	// PinParticipated keeps PRs login has reviewed or commented on, whatever
+	// the rules say, and pins them so they stay kept.
+	PinParticipated bool
+
+	// DeadlineWithin keeps team-only requests the built-in classification
+	// would mute when the PR's review deadline (see ReviewDeadline) is at most
+	// this far off, or past. 0 ignores deadlines.
+	DeadlineWithin time.Duration
+
+	// Waves looks up every PR's author, so near-identical PRs can be grouped
+	// into waves (see FindWaves).
+	Waves bool
 }
 
 type Mode int
@@ -95,6 +200,13 @@ func (m Mode) ActionLabel() string {
 	return "READ"
 }
 
+// Marks reports whether marking a thread in state s in mode moves it: a read
+// thread needs no marking read, but may not be done yet, so it's always
+// marked done.
+func (m Mode) Marks(s InboxState) bool {
+	return m == ModeDone || s == StateUnread
+}
+
 func (m Mode) ActionLabelLower() string {
 	if m == ModeDone {
 		return "done"
@@ -103,13 +215,15 @@ func (m Mode) ActionLabelLower() string {
 }
 
 // ParseSubjectURL extracts owner, repo, and PR number from a GitHub API URL
-// like "https://api.github.com/repos/org/repo/pulls/42".
+// like "https://api.github.com/repos/org/repo/pulls/42". GHES URLs such as
+// "https://ghes.example.com/api/v3/repos/org/repo/pulls/42" are accepted too.
 func ParseSubjectURL(url string) (PRRef, error) {
-	const prefix = "https://api.github.com/repos/"
-	if !strings.HasPrefix(url, prefix) {
+	const marker = "/repos/"
+	i := strings.Index(url, marker)
+	if !strings.HasPrefix(url, "https://") || i < 0 {
 		return PRRef{}, fmt.Errorf("unexpected URL prefix: %s", url)
 	}
-	rest := strings.TrimPrefix(url, prefix)
+	rest := url[i+len(marker):]
 	parts := strings.Split(rest, "/")
 	if len(parts) != 4 || parts[2] != "pulls" {
 		return PRRef{}, fmt.Errorf("unexpected URL structure: %s", url)
@@ -133,16 +247,64 @@ func MatchesOrgFilter(n Notification, cfg Config) bool {
 	return true
 }
 
+// MatchesVisibilityFilter checks if a notification's repository has the
+// visibility the filter asks for.
+func MatchesVisibilityFilter(n Notification, cfg Config) bool {
+	switch cfg.Visibility {
+	case "private":
+		return n.Repository.Private
+	case "public":
+		return !n.Repository.Private
+	}
+	return true
+}
+
+// MatchesRepoFilter checks if a notification passes both the org and the
+// visibility filter. Notifications that don't are skipped without lookups.
+func MatchesRepoFilter(n Notification, cfg Config) bool {
+	return MatchesOrgFilter(n, cfg) && MatchesVisibilityFilter(n, cfg)
+}
+
+// FiltersTopics reports whether a repository topic filter is set.
+func FiltersTopics(cfg Config) bool {
+	return len(cfg.IncludeTopics) > 0 || len(cfg.ExcludeTopics) > 0
+}
+
+// MatchesTopicFilter checks if a repository's topics pass the topic
+// include/exclude filter: any include topic is required, and any exclude
+// topic rules the repo out.
+func MatchesTopicFilter(topics []string, cfg Config) bool {
+	if len(cfg.IncludeTopics) > 0 && !slices.ContainsFunc(topics, func(t string) bool { return containsFold(cfg.IncludeTopics, t) }) {
+		return false
+	}
+	return !slices.ContainsFunc(topics, func(t string) bool { return containsFold(cfg.ExcludeTopics, t) })
+}
+
+// NeedsTopicsLookup decides if a notification requires fetching its
+// repository's topics: when a topic filter is set or a rule reads repo.topics,
+// and only for notifications passing the repo filter.
+func NeedsTopicsLookup(n Notification, cfg Config) bool {
+	return MatchesRepoFilter(n, cfg) && (FiltersTopics(cfg) || rulesUseField(cfg.Rules, "repo.topics"))
+}
+
+// NeedsLanguageLookup decides if a notification requires fetching its repo's
+// primary language: only if it passes the repo filter and some rule reads
+// repo.language.
+func NeedsLanguageLookup(n Notification, cfg Config) bool {
+	return MatchesRepoFilter(n, cfg) && rulesUseField(cfg.Rules, "repo.language")
+}
+
 // NeedsReviewerLookup decides if a notification requires a reviewer API call.
-// True when reason is "review_requested", type is "PullRequest", and it passes the org filter.
+// True when type is "PullRequest", it passes the repo filter, and either the
+// reason is "review_requested" or a rule reads reviewers.* fields.
 func NeedsReviewerLookup(n Notification, cfg Config) bool {
-	if n.Reason != "review_requested" {
+	if n.Reason != "review_requested" && !rulesUseField(cfg.Rules, "reviewers.") {
 		return false
 	}
 	if n.Subject.Type != "PullRequest" {
 		return false
 	}
-	return MatchesOrgFilter(n, cfg)
+	return MatchesRepoFilter(n, cfg)
 }
 
 // Classify determines the action for a single notification.
@@ -151,6 +313,9 @@ func Classify(n Notification, reviewers *Reviewers, login string, cfg Config) De
 	if !MatchesOrgFilter(n, cfg) {
 		return Decision{Notification: n, Action: ActionSkip, Reason: "filtered by org"}
 	}
+	if !MatchesVisibilityFilter(n, cfg) {
+		return Decision{Notification: n, Action: ActionSkip, Reason: "filtered by visibility"}
+	}
 	if n.Reason != "review_requested" || n.Subject.Type != "PullRequest" {
 		return Decision{Notification: n, Action: ActionSkip, Reason: "not a review-requested PR"}
 	}
@@ -159,10 +324,20 @@ func Classify(n Notification, reviewers *Reviewers, login string, cfg Config) De
 	}
 	for _, user := range reviewers.Users {
 		if strings.EqualFold(user, login) {
-			return Decision{Notification: n, Action: ActionKeep, Reason: "direct review request"}
+			return Decision{Notification: n, Action: ActionKeep, Reason: "direct review request", Teams: reviewers.Teams}
 		}
 	}
-	return Decision{Notification: n, Action: ActionMute, Reason: "team-only review request"}
+	return Decision{Notification: n, Action: ActionMute, Reason: "team-only review request", Teams: reviewers.Teams}
+}
+
+// AnnotateDryRun returns d as a dry run shows it. A review request skipped
+// because its reviewer lookup failed says so: with the lookup, apply could
+// mute or keep it, so the dry run's SKIP isn't a prediction.
+func AnnotateDryRun(d Decision) Decision {
+	if d.Action == ActionSkip && d.Reason == "no reviewer data" {
+		d.Reason += ": reviewer lookup failed, apply may decide otherwise"
+	}
+	return d
 }
 
 // ClassifyAll processes a batch of notifications.
@@ -177,12 +352,14 @@ func ClassifyAll(notifications []Notification, reviewersByURL map[string]*Review
 }
 
 // CountByAction returns counts of each action type in a set of decisions.
+// Deferred notifications are left alone for now, so they count as kept.
+// Dimmed and archived ones are counted by CountMarks instead.
 func CountByAction(decisions []Decision) (skip, keep, mute int) {
 	for _, d := range decisions {
 		switch d.Action {
 		case ActionSkip:
 			skip++
-		case ActionKeep:
+		case ActionKeep, ActionDefer:
 			keep++
 		case ActionMute:
 			mute++
@@ -191,47 +368,152 @@ func CountByAction(decisions []Decision) (skip, keep, mute int) {
 	return
 }
 
+// MarkCounts counts the threads an apply run marks, by action: mutes, dims
+// (marked read), and archives (marked done).
+type MarkCounts struct {
+	Mute, Dim, Archive int
+}
+
+// Add counts a decision's action, if it marks the thread.
+func (c *MarkCounts) Add(a Action) {
+	switch a {
+	case ActionMute:
+		c.Mute++
+	case ActionDim:
+		c.Dim++
+	case ActionArchive:
+		c.Archive++
+	}
+}
+
+// Plus returns the sum of c and o.
+func (c MarkCounts) Plus(o MarkCounts) MarkCounts {
+	return MarkCounts{Mute: c.Mute + o.Mute, Dim: c.Dim + o.Dim, Archive: c.Archive + o.Archive}
+}
+
+// Minus returns c less o, as in the marks attempted less those that failed.
+func (c MarkCounts) Minus(o MarkCounts) MarkCounts {
+	return MarkCounts{Mute: c.Mute - o.Mute, Dim: c.Dim - o.Dim, Archive: c.Archive - o.Archive}
+}
+
+// Total is the number of threads counted, whatever the action.
+func (c MarkCounts) Total() int {
+	return c.Mute + c.Dim + c.Archive
+}
+
+// CountMarks counts the decisions that mark their thread, by action.
+func CountMarks(decisions []Decision) MarkCounts {
+	var c MarkCounts
+	for _, d := range decisions {
+		c.Add(d.Action)
+	}
+	return c
+}
+
+// formatMarks renders marked as summary terms: the mutes under mode's label,
+// then any dims and archives, which are marked read and done whatever mode
+// says.
+func formatMarks(marked MarkCounts, mode Mode) string {
+	s := fmt.Sprintf("%d %s", marked.Mute, mode.ActionLabelLower())
+	if marked.Dim > 0 {
+		s += fmt.Sprintf(", %d dimmed", marked.Dim)
+	}
+	if marked.Archive > 0 {
+		s += fmt.Sprintf(", %d archived", marked.Archive)
+	}
+	return s
+}
+
 // FormatDecisionRow formats a single decision as a line for dry-run output.
 func FormatDecisionRow(d Decision) string {
 	label := formatLabel(d)
 	action := fmt.Sprintf("%s (%s)", d.Action, d.Reason)
+	if d.Action == ActionDefer {
+		action = fmt.Sprintf("%s (%s, until %s)", d.Action, d.Reason, d.Until.Format("Mon 15:04 MST"))
+	}
 	return fmt.Sprintf("%-40s  %-90s  %s", label, d.Notification.Subject.Title, action)
 }
 
-// FormatMutationRow formats a single mutation result as a line for apply output.
-func FormatMutationRow(d Decision, mode Mode, err error) string {
+// FormatMutationRow formats a single mutation result as a line for apply
+// output, starting with when it was made so long runs can be matched up with
+// GitHub's audit log.
+func FormatMutationRow(d Decision, mode Mode, err error, at time.Time) string {
 	label := formatLabel(d)
+	ts := at.UTC().Format(time.RFC3339)
 	if err != nil {
-		return fmt.Sprintf("ERROR  %s  %q  %s", label, d.Notification.Subject.Title, err)
+		return fmt.Sprintf("%s  ERROR  %s  %q  %s", ts, label, d.Notification.Subject.Title, err)
 	}
-	return fmt.Sprintf("%-5s  %s  %q", mode.ActionLabel(), label, d.Notification.Subject.Title)
+	return fmt.Sprintf("%s  %-5s  %s  %q", ts, d.Action.MarkMode(mode).ActionLabel(), label, d.Notification.Subject.Title)
+}
+
+// FormatDecisionPlain formats a decision for --plain output: one labeled
+// field per line, unpadded and untruncated, ending with a blank line.
+func FormatDecisionPlain(d Decision) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "thread: %s\n", formatLabel(d))
+	fmt.Fprintf(&b, "title: %s\n", d.Notification.Subject.Title)
+	fmt.Fprintf(&b, "action: %s\n", d.Action)
+	fmt.Fprintf(&b, "reason: %s\n", d.Reason)
+	if d.Notification.State != StateUnread {
+		fmt.Fprintf(&b, "state: %s\n", d.Notification.State)
+	}
+	if d.Rule != "" {
+		fmt.Fprintf(&b, "rule: %s\n", d.Rule)
+	}
+	if d.Action == ActionDefer {
+		fmt.Fprintf(&b, "until: %s\n", d.Until.Format("Mon 15:04 MST"))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// FormatMutationPlain formats a mutation result for --plain output, like
+// FormatDecisionPlain.
+func FormatMutationPlain(d Decision, mode Mode, err error, at time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "time: %s\n", at.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "thread: %s\n", formatLabel(d))
+	fmt.Fprintf(&b, "title: %s\n", d.Notification.Subject.Title)
+	if err != nil {
+		fmt.Fprintf(&b, "result: error\nerror: %s\n\n", err)
+		return b.String()
+	}
+	if d.Action == ActionDim || d.Action == ActionArchive {
+		fmt.Fprintf(&b, "result: marked %s\n\n", d.Action.MarkMode(mode).ActionLabelLower())
+		return b.String()
+	}
+	fmt.Fprintf(&b, "result: marked %s and muted\n\n", mode.ActionLabelLower())
+	return b.String()
 }
 
 // FormatSummary renders a final summary line from counts.
-func FormatSummary(scanned, actioned, kept, skipped, errors int, mode Mode) string {
-	label := mode.ActionLabelLower()
+func FormatSummary(scanned int, marked MarkCounts, kept, skipped, errors int, mode Mode) string {
 	if errors > 0 {
-		return fmt.Sprintf("\nDone: %d scanned, %d %s, %d errors", scanned, actioned, label, errors)
+		return fmt.Sprintf("\nDone: %d scanned, %s, %d errors", scanned, formatMarks(marked, mode), errors)
 	}
-	if actioned > 0 {
-		return fmt.Sprintf("\nDone: %d scanned, %d %s", scanned, actioned, label)
+	if marked.Total() > 0 {
+		return fmt.Sprintf("\nDone: %d scanned, %s", scanned, formatMarks(marked, mode))
 	}
-	return fmt.Sprintf("\nSummary: %d scanned, %d spam, %d kept, %d skipped", scanned, actioned, kept, skipped)
+	return fmt.Sprintf("\nSummary: %d scanned, %d spam, %d kept, %d skipped", scanned, marked.Mute, kept, skipped)
 }
 
 func formatLabel(d Decision) string {
+	repo := d.Notification.Repository.FullName
+	if d.Notification.Host != "" {
+		repo = d.Notification.Host + "/" + repo
+	}
 	ref, err := ParseSubjectURL(d.Notification.Subject.URL)
 	if err != nil {
-		return d.Notification.Repository.FullName
+		return repo
 	}
-	return fmt.Sprintf("%s#%d", d.Notification.Repository.FullName, ref.Number)
+	return fmt.Sprintf("%s#%d", repo, ref.Number)
 }
 
 // FormatDaemonCycleSummary renders a one-line timestamped cycle summary.
-func FormatDaemonCycleSummary(now time.Time, scanned, actioned, errCount int, notModified bool, mode Mode) string {
+func FormatDaemonCycleSummary(now time.Time, scanned int, marked MarkCounts, errCount int, notModified bool, mode Mode) string {
 	ts := now.UTC().Format(time.RFC3339)
 	if notModified {
 		return fmt.Sprintf("%s  cycle: not modified\n", ts)
 	}
-	return fmt.Sprintf("%s  cycle: %d scanned, %d %s, %d errors\n", ts, scanned, actioned, mode.ActionLabelLower(), errCount)
+	return fmt.Sprintf("%s  cycle: %d scanned, %s, %d errors\n", ts, scanned, formatMarks(marked, mode), errCount)
 }
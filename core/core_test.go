@@ -29,6 +29,11 @@ func TestParseSubjectURL(t *testing.T) {
 			url:  "https://api.github.com/repos/org/project/pulls/99999",
 			want: PRRef{Owner: "org", Repo: "project", Number: 99999},
 		},
+		{
+			name: "GHES PR URL",
+			url:  "https://ghes.example.com/api/v3/repos/org/repo/pulls/7",
+			want: PRRef{Owner: "org", Repo: "repo", Number: 7},
+		},
 		{
 			name:    "issue URL not a PR",
 			url:     "https://api.github.com/repos/org/repo/issues/42",
@@ -86,6 +91,36 @@ func TestParseSubjectURL(t *testing.T) {
 	}
 }
 
+func TestMatchesVisibilityFilter(t *testing.T) {
+	private := Notification{Repository: Repository{Owner: "org", Private: true}}
+	public := Notification{Repository: Repository{Owner: "org"}}
+
+	tests := []struct {
+		name string
+		n    Notification
+		cfg  Config
+		want bool
+	}{
+		{name: "no filter, private", n: private, cfg: Config{}, want: true},
+		{name: "no filter, public", n: public, cfg: Config{}, want: true},
+		{name: "only private, private", n: private, cfg: Config{Visibility: "private"}, want: true},
+		{name: "only private, public", n: public, cfg: Config{Visibility: "private"}, want: false},
+		{name: "only public, private", n: private, cfg: Config{Visibility: "public"}, want: false},
+		{name: "only public, public", n: public, cfg: Config{Visibility: "public"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchesVisibilityFilter(tt.n, tt.cfg); got != tt.want {
+				t.Errorf("MatchesVisibilityFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if NeedsReviewerLookup(Notification{Reason: "review_requested", Subject: Subject{Type: "PullRequest"}, Repository: public.Repository}, Config{Visibility: "private"}) {
+		t.Error("NeedsReviewerLookup() = true for a repo the visibility filter skips, want false")
+	}
+}
+
 func TestMatchesOrgFilter(t *testing.T) {
 	n := func(owner string) Notification {
 		return Notification{Repository: Repository{Owner: owner}}
@@ -318,6 +353,22 @@ func TestClassify(t *testing.T) {
 			cfg:        Config{ExcludeOrg: "org"},
 			wantAction: ActionSkip,
 		},
+		{
+			name:       "public repo skipped with private visibility",
+			n:          prNotif,
+			reviewers:  &Reviewers{Teams: []string{"backend"}},
+			login:      "me",
+			cfg:        Config{Visibility: "private"},
+			wantAction: ActionSkip,
+		},
+		{
+			name:       "public repo muted with public visibility",
+			n:          prNotif,
+			reviewers:  &Reviewers{Teams: []string{"backend"}},
+			login:      "me",
+			cfg:        Config{Visibility: "public"},
+			wantAction: ActionMute,
+		},
 	}
 
 	for _, tt := range tests {
@@ -330,6 +381,34 @@ func TestClassify(t *testing.T) {
 	}
 }
 
+func TestClassifyRecordsTeams(t *testing.T) {
+	n := Notification{
+		Reason:     "review_requested",
+		Subject:    Subject{URL: "https://api.github.com/repos/org/repo/pulls/42", Type: "PullRequest"},
+		Repository: Repository{Owner: "org"},
+	}
+	d := Classify(n, &Reviewers{Teams: []string{"backend", "infra"}}, "me", Config{})
+	if len(d.Teams) != 2 || d.Teams[0] != "backend" || d.Teams[1] != "infra" {
+		t.Errorf("Classify() teams = %v, want [backend infra]", d.Teams)
+	}
+}
+
+func TestAnnotateDryRun(t *testing.T) {
+	tests := []struct {
+		in         Decision
+		wantReason string
+	}{
+		{Decision{Action: ActionSkip, Reason: "no reviewer data"}, "no reviewer data: reviewer lookup failed, apply may decide otherwise"},
+		{Decision{Action: ActionSkip, Reason: "not a review-requested PR"}, "not a review-requested PR"},
+		{Decision{Action: ActionMute, Reason: "team-only review request"}, "team-only review request"},
+	}
+	for _, tt := range tests {
+		if got := AnnotateDryRun(tt.in); got.Reason != tt.wantReason || got.Action != tt.in.Action {
+			t.Errorf("AnnotateDryRun(%s, %q) = %s, %q, want %s, %q", tt.in.Action, tt.in.Reason, got.Action, got.Reason, tt.in.Action, tt.wantReason)
+		}
+	}
+}
+
 func TestClassifyAll(t *testing.T) {
 	notifications := []Notification{
 		{
@@ -380,6 +459,8 @@ func TestCountByAction(t *testing.T) {
 		{Action: ActionMute},
 		{Action: ActionMute},
 		{Action: ActionSkip},
+		{Action: ActionDim},
+		{Action: ActionArchive},
 	}
 	skip, keep, mute := CountByAction(decisions)
 	if skip != 2 || keep != 1 || mute != 2 {
@@ -387,6 +468,55 @@ func TestCountByAction(t *testing.T) {
 	}
 }
 
+func TestCountMarks(t *testing.T) {
+	decisions := []Decision{
+		{Action: ActionSkip},
+		{Action: ActionMute},
+		{Action: ActionMute},
+		{Action: ActionDim},
+		{Action: ActionArchive},
+		{Action: ActionDefer},
+	}
+	got := CountMarks(decisions)
+	if want := (MarkCounts{Mute: 2, Dim: 1, Archive: 1}); got != want {
+		t.Errorf("CountMarks() = %+v, want %+v", got, want)
+	}
+	if got.Total() != 4 {
+		t.Errorf("Total() = %d, want 4", got.Total())
+	}
+	failed := MarkCounts{Mute: 1, Archive: 1}
+	if want := (MarkCounts{Mute: 1, Dim: 1}); got.Minus(failed) != want {
+		t.Errorf("Minus() = %+v, want %+v", got.Minus(failed), want)
+	}
+	if want := (MarkCounts{Mute: 3, Dim: 1, Archive: 2}); got.Plus(failed) != want {
+		t.Errorf("Plus() = %+v, want %+v", got.Plus(failed), want)
+	}
+}
+
+func TestActionMarkMode(t *testing.T) {
+	tests := []struct {
+		action Action
+		mode   Mode
+		marks  bool
+		want   Mode
+	}{
+		{ActionMute, ModeRead, true, ModeRead},
+		{ActionMute, ModeDone, true, ModeDone},
+		{ActionDim, ModeDone, true, ModeRead},
+		{ActionArchive, ModeRead, true, ModeDone},
+		{ActionKeep, ModeRead, false, ModeRead},
+		{ActionDefer, ModeDone, false, ModeDone},
+	}
+	for _, tt := range tests {
+		if got := tt.action.Marks(); got != tt.marks {
+			t.Errorf("%s.Marks() = %v, want %v", tt.action, got, tt.marks)
+		}
+		if got := tt.action.MarkMode(tt.mode); got != tt.want {
+			t.Errorf("%s.MarkMode(%s) = %s, want %s", tt.action, tt.mode.ActionLabel(), got.ActionLabel(), tt.want.ActionLabel())
+		}
+	}
+}
+
 func TestParseMode(t *testing.T) {
 	tests := []struct {
 		input   string
@@ -448,46 +578,116 @@ func TestFormatMutationRow(t *testing.T) {
 		},
 		Action: ActionMute,
 	}
+	at := time.Date(2026, 3, 2, 9, 30, 0, 0, time.FixedZone("EST", -5*3600))
 
 	t.Run("read mode success", func(t *testing.T) {
-		output := FormatMutationRow(d, ModeRead, nil)
-		if !strings.Contains(output, "READ") || !strings.Contains(output, "org/repo#42") {
+		output := FormatMutationRow(d, ModeRead, nil, at)
+		if !strings.HasPrefix(output, "2026-03-02T14:30:00Z  READ ") || !strings.Contains(output, "org/repo#42") {
 			t.Errorf("unexpected output: %s", output)
 		}
 	})
 
 	t.Run("done mode success", func(t *testing.T) {
-		output := FormatMutationRow(d, ModeDone, nil)
+		output := FormatMutationRow(d, ModeDone, nil, at)
 		if !strings.Contains(output, "DONE") || !strings.Contains(output, "org/repo#42") {
 			t.Errorf("unexpected output: %s", output)
 		}
 	})
 
 	t.Run("error", func(t *testing.T) {
-		output := FormatMutationRow(d, ModeRead, fmt.Errorf("mark-read failed: 500"))
+		output := FormatMutationRow(d, ModeRead, fmt.Errorf("mark-read failed: 500"), at)
 		if !strings.Contains(output, "ERROR") || !strings.Contains(output, "mark-read failed") {
 			t.Errorf("unexpected output: %s", output)
 		}
 	})
 }
 
+func TestModeMarks(t *testing.T) {
+	tests := []struct {
+		mode  Mode
+		state InboxState
+		want  bool
+	}{
+		{ModeRead, StateUnread, true},
+		{ModeRead, StateRead, false},
+		{ModeDone, StateUnread, true},
+		{ModeDone, StateRead, true},
+	}
+	for _, tt := range tests {
+		if got := tt.mode.Marks(tt.state); got != tt.want {
+			t.Errorf("%s.Marks(%s) = %v, want %v", tt.mode.ActionLabelLower(), tt.state, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDecisionPlain(t *testing.T) {
+	d := Decision{
+		Notification: Notification{
+			Subject:    Subject{Title: "Fix bug", URL: "https://api.github.com/repos/org/repo/pulls/42", Type: "PullRequest"},
+			Repository: Repository{FullName: "org/repo"},
+		},
+		Action: ActionMute,
+		Reason: "rule drafts",
+		Rule:   "drafts",
+	}
+	want := "thread: org/repo#42\ntitle: Fix bug\naction: MUTE\nreason: rule drafts\nrule: drafts\n\n"
+	if got := FormatDecisionPlain(d); got != want {
+		t.Errorf("FormatDecisionPlain() = %q, want %q", got, want)
+	}
+
+	d.Notification.State = StateRead
+	want = "thread: org/repo#42\ntitle: Fix bug\naction: MUTE\nreason: rule drafts\nstate: read\nrule: drafts\n\n"
+	if got := FormatDecisionPlain(d); got != want {
+		t.Errorf("FormatDecisionPlain() read = %q, want %q", got, want)
+	}
+
+	d.Notification.State = StateUnread
+	d.Action, d.Reason, d.Rule = ActionDefer, "outside business hours", ""
+	d.Until = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	want = "thread: org/repo#42\ntitle: Fix bug\naction: DEFER\nreason: outside business hours\nuntil: Mon 09:00 UTC\n\n"
+	if got := FormatDecisionPlain(d); got != want {
+		t.Errorf("FormatDecisionPlain() deferred = %q, want %q", got, want)
+	}
+}
+
+func TestFormatMutationPlain(t *testing.T) {
+	d := Decision{Notification: Notification{
+		Subject:    Subject{Title: "Fix bug", URL: "https://api.github.com/repos/org/repo/pulls/42"},
+		Repository: Repository{FullName: "org/repo"},
+	}}
+	at := time.Date(2026, 3, 2, 14, 30, 0, 0, time.UTC)
+	want := "time: 2026-03-02T14:30:00Z\nthread: org/repo#42\ntitle: Fix bug\nresult: marked done and muted\n\n"
+	if got := FormatMutationPlain(d, ModeDone, nil, at); got != want {
+		t.Errorf("FormatMutationPlain() = %q, want %q", got, want)
+	}
+	want = "time: 2026-03-02T14:30:00Z\nthread: org/repo#42\ntitle: Fix bug\nresult: error\nerror: mark-read failed: 500\n\n"
+	if got := FormatMutationPlain(d, ModeRead, fmt.Errorf("mark-read failed: 500"), at); got != want {
+		t.Errorf("FormatMutationPlain() error = %q, want %q", got, want)
+	}
+	d.Action = ActionDim
+	want = "time: 2026-03-02T14:30:00Z\nthread: org/repo#42\ntitle: Fix bug\nresult: marked read\n\n"
+	if got := FormatMutationPlain(d, ModeDone, nil, at); got != want {
+		t.Errorf("FormatMutationPlain() dimmed = %q, want %q", got, want)
+	}
+}
+
 func TestFormatSummary(t *testing.T) {
 	t.Run("dry run", func(t *testing.T) {
-		output := FormatSummary(10, 0, 3, 7, 0, ModeRead)
+		output := FormatSummary(10, MarkCounts{}, 3, 7, 0, ModeRead)
 		if !strings.Contains(output, "10 scanned") || !strings.Contains(output, "3 kept") || !strings.Contains(output, "7 skipped") {
 			t.Errorf("unexpected output: %s", output)
 		}
 	})
 
 	t.Run("read mode with errors", func(t *testing.T) {
-		output := FormatSummary(10, 8, 2, 0, 1, ModeRead)
+		output := FormatSummary(10, MarkCounts{Mute: 8}, 2, 0, 1, ModeRead)
 		if !strings.Contains(output, "10 scanned") || !strings.Contains(output, "8 read") || !strings.Contains(output, "1 errors") {
 			t.Errorf("unexpected output: %s", output)
 		}
 	})
 
 	t.Run("read mode no errors", func(t *testing.T) {
-		output := FormatSummary(10, 5, 3, 2, 0, ModeRead)
+		output := FormatSummary(10, MarkCounts{Mute: 5}, 3, 2, 0, ModeRead)
 		if !strings.Contains(output, "10 scanned") || !strings.Contains(output, "5 read") {
 			t.Errorf("unexpected output: %s", output)
 		}
@@ -497,18 +697,32 @@ func TestFormatSummary(t *testing.T) {
 	})
 
 	t.Run("done mode", func(t *testing.T) {
-		output := FormatSummary(10, 5, 3, 2, 0, ModeDone)
+		output := FormatSummary(10, MarkCounts{Mute: 5}, 3, 2, 0, ModeDone)
 		if !strings.Contains(output, "5 done") {
 			t.Errorf("unexpected output: %s", output)
 		}
 	})
+
+	t.Run("dims and archives", func(t *testing.T) {
+		output := FormatSummary(10, MarkCounts{Mute: 5, Dim: 2, Archive: 1}, 2, 0, 0, ModeRead)
+		if want := "\nDone: 10 scanned, 5 read, 2 dimmed, 1 archived"; output != want {
+			t.Errorf("got %q, want %q", output, want)
+		}
+	})
+
+	t.Run("archives only", func(t *testing.T) {
+		output := FormatSummary(10, MarkCounts{Archive: 3}, 7, 0, 0, ModeDone)
+		if want := "\nDone: 10 scanned, 0 done, 3 archived"; output != want {
+			t.Errorf("got %q, want %q", output, want)
+		}
+	})
 }
 
 func TestFormatDaemonCycleSummary(t *testing.T) {
 	now := time.Date(2026, 2, 27, 10, 0, 0, 0, time.UTC)
 
 	t.Run("not modified", func(t *testing.T) {
-		output := FormatDaemonCycleSummary(now, 0, 0, 0, true, ModeRead)
+		output := FormatDaemonCycleSummary(now, 0, MarkCounts{}, 0, true, ModeRead)
 		want := "2026-02-27T10:00:00Z  cycle: not modified\n"
 		if output != want {
 			t.Errorf("got %q, want %q", output, want)
@@ -516,7 +730,7 @@ func TestFormatDaemonCycleSummary(t *testing.T) {
 	})
 
 	t.Run("read mode", func(t *testing.T) {
-		output := FormatDaemonCycleSummary(now, 3, 2, 0, false, ModeRead)
+		output := FormatDaemonCycleSummary(now, 3, MarkCounts{Mute: 2}, 0, false, ModeRead)
 		want := "2026-02-27T10:00:00Z  cycle: 3 scanned, 2 read, 0 errors\n"
 		if output != want {
 			t.Errorf("got %q, want %q", output, want)
@@ -524,11 +738,18 @@ func TestFormatDaemonCycleSummary(t *testing.T) {
 	})
 
 	t.Run("done mode", func(t *testing.T) {
-		output := FormatDaemonCycleSummary(now, 3, 2, 0, false, ModeDone)
+		output := FormatDaemonCycleSummary(now, 3, MarkCounts{Mute: 2}, 0, false, ModeDone)
 		want := "2026-02-27T10:00:00Z  cycle: 3 scanned, 2 done, 0 errors\n"
 		if output != want {
 			t.Errorf("got %q, want %q", output, want)
 		}
 	})
-}
 
+	t.Run("dims", func(t *testing.T) {
+		output := FormatDaemonCycleSummary(now, 4, MarkCounts{Mute: 2, Dim: 1}, 1, false, ModeDone)
+		want := "2026-02-27T10:00:00Z  cycle: 4 scanned, 2 done, 1 dimmed, 1 errors\n"
+		if output != want {
+			t.Errorf("got %q, want %q", output, want)
+		}
+	})
+}
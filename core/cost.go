@@ -0,0 +1,129 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RateLimit is a snapshot of the REST rate-limit headers from the last response.
+// A zero Limit means no rate-limit headers have been seen yet.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// ParseRateLimit builds a RateLimit from the X-RateLimit-Limit, X-RateLimit-Remaining,
+// and X-RateLimit-Reset header values. Returns false if any value is missing or malformed.
+func ParseRateLimit(limit, remaining, reset string) (RateLimit, bool) {
+	l, err := strconv.Atoi(limit)
+	if err != nil {
+		return RateLimit{}, false
+	}
+	r, err := strconv.Atoi(remaining)
+	if err != nil {
+		return RateLimit{}, false
+	}
+	epoch, err := strconv.ParseInt(reset, 10, 64)
+	if err != nil {
+		return RateLimit{}, false
+	}
+	return RateLimit{Limit: l, Remaining: r, Reset: time.Unix(epoch, 0).UTC()}, true
+}
+
+// callsPerMute is the number of API calls needed to action one thread:
+// mark read (or done), then ignore the subscription. GitHub has no endpoint
+// that marks a selected set of threads at once, so nothing can be batched.
+const callsPerMute = 2
+
+// CallsPerMute returns the API calls needed to action one thread. Checking
+// the subscription first (--check-subscription) adds a GET, which saves the
+// ignore write only for threads that are already ignored.
+func CallsPerMute(checkSubscription bool) int {
+	if checkSubscription {
+		return callsPerMute + 1
+	}
+	return callsPerMute
+}
+
+// callsPerDecline is the most API calls --decline makes per muted review
+// request: a GET of the PR's requested reviewers, then a DELETE if login was
+// requested personally.
+const callsPerDecline = 2
+
+// EstimateApplyCalls returns how many API calls an apply run would make
+// for the given decisions in mode, at most perMute calls per muted thread
+// and one per dimmed or archived thread, which is only marked. A thread
+// already where the mark would leave it isn't marked, saving a call. With
+// decline, each muted review request adds up to callsPerDecline more.
+func EstimateApplyCalls(decisions []Decision, mode Mode, perMute int, decline bool) int {
+	calls := 0
+	for _, d := range decisions {
+		if !d.Action.Marks() {
+			continue
+		}
+		if d.Action == ActionMute {
+			calls += perMute - 1
+		}
+		if decline && NeedsDecline(d) {
+			calls += callsPerDecline
+		}
+		if d.Action.MarkMode(mode).Marks(d.Notification.State) {
+			calls++
+		}
+	}
+	return calls
+}
+
+// FormatCostEstimate renders the projected API cost of an apply run and how it
+// compares to the remaining rate limit.
+func FormatCostEstimate(calls, perMute int, rl RateLimit) string {
+	estimate := fmt.Sprintf("Apply would make %d API calls (%d per muted thread)", calls, perMute)
+	if rl.Limit == 0 {
+		return estimate + "; rate limit unknown"
+	}
+	reset := rl.Reset.UTC().Format("15:04 UTC")
+	if calls > rl.Remaining {
+		return fmt.Sprintf("%s; exceeds remaining rate limit (%d of %d, resets %s) by %d",
+			estimate, rl.Remaining, rl.Limit, reset, calls-rl.Remaining)
+	}
+	return fmt.Sprintf("%s; %d of %d remaining (resets %s)", estimate, rl.Remaining, rl.Limit, reset)
+}
+
+// GraphQLCost totals the GraphQL rate-limit cost of a run or daemon cycle,
+// from the rateLimit field each query asks for. GraphQL is metered in points
+// per query from its own budget, not in requests against the REST limit.
+type GraphQLCost struct {
+	Queries   int
+	Points    int // total cost of the queries
+	Limit     int // the last query's budget; zero if none reported it
+	Remaining int
+	Reset     time.Time
+}
+
+// Add records a query's cost and the budget it left.
+func (g *GraphQLCost) Add(cost, limit, remaining int, reset time.Time) {
+	g.Queries++
+	g.Points += cost
+	if limit > 0 {
+		g.Limit, g.Remaining, g.Reset = limit, remaining, reset
+	}
+}
+
+// FormatGraphQLCost renders a GraphQL cost as a line for the end of a run or
+// cycle, or "" if no queries were made.
+func FormatGraphQLCost(g GraphQLCost) string {
+	if g.Queries == 0 {
+		return ""
+	}
+	queries := "queries"
+	if g.Queries == 1 {
+		queries = "query"
+	}
+	line := fmt.Sprintf("GraphQL: %d %s cost %d points", g.Queries, queries, g.Points)
+	if g.Limit == 0 {
+		return line + "; budget unknown"
+	}
+	return fmt.Sprintf("%s; %d of %d remaining (resets %s)", line, g.Remaining, g.Limit, g.Reset.UTC().Format("15:04 UTC"))
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		name                    string
+		limit, remaining, reset string
+		want                    RateLimit
+		wantOK                  bool
+	}{
+		{
+			name:      "valid headers",
+			limit:     "5000",
+			remaining: "4990",
+			reset:     "1772186400",
+			want:      RateLimit{Limit: 5000, Remaining: 4990, Reset: time.Date(2026, 2, 27, 10, 0, 0, 0, time.UTC)},
+			wantOK:    true,
+		},
+		{
+			name:      "missing headers",
+			limit:     "",
+			remaining: "",
+			reset:     "",
+		},
+		{
+			name:      "non-numeric remaining",
+			limit:     "5000",
+			remaining: "lots",
+			reset:     "1772186400",
+		},
+		{
+			name:      "non-numeric reset",
+			limit:     "5000",
+			remaining: "4990",
+			reset:     "soon",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseRateLimit(tt.limit, tt.remaining, tt.reset)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseRateLimit() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRateLimit() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateApplyCalls(t *testing.T) {
+	decisions := []Decision{
+		{Action: ActionSkip},
+		{Action: ActionKeep},
+		{Action: ActionMute},
+		{Action: ActionMute},
+		{Action: ActionMute},
+		{Action: ActionDim},
+		{Action: ActionArchive},
+	}
+	if got := EstimateApplyCalls(decisions, ModeRead, CallsPerMute(false), false); got != 8 {
+		t.Errorf("EstimateApplyCalls() = %d, want 8", got)
+	}
+	if got := EstimateApplyCalls(decisions, ModeRead, CallsPerMute(true), false); got != 11 {
+		t.Errorf("EstimateApplyCalls(check subscription) = %d, want 11", got)
+	}
+	if got := EstimateApplyCalls(nil, ModeRead, CallsPerMute(false), false); got != 0 {
+		t.Errorf("EstimateApplyCalls(nil) = %d, want 0", got)
+	}
+
+	// Read threads, listed with the read ones too, need no marking read.
+	read := Notification{State: StateRead}
+	decisions = []Decision{
+		{Action: ActionMute, Notification: read},
+		{Action: ActionDim, Notification: read},
+		{Action: ActionArchive, Notification: read},
+	}
+	if got := EstimateApplyCalls(decisions, ModeRead, CallsPerMute(false), false); got != 2 {
+		t.Errorf("EstimateApplyCalls(read threads) = %d, want 2", got)
+	}
+	if got := EstimateApplyCalls(decisions, ModeDone, CallsPerMute(false), false); got != 3 {
+		t.Errorf("EstimateApplyCalls(read threads, done) = %d, want 3", got)
+	}
+
+	// --decline re-reads a muted review request's reviewers, then may remove
+	// the request.
+	request := Notification{Reason: "review_requested", Subject: Subject{Type: "PullRequest"}}
+	decisions = []Decision{
+		{Action: ActionMute, Notification: request},
+		{Action: ActionMute, Notification: Notification{Reason: "subscribed", Subject: Subject{Type: "PullRequest"}}},
+		{Action: ActionKeep, Notification: request},
+		{Action: ActionDim, Notification: request},
+	}
+	if got := EstimateApplyCalls(decisions, ModeRead, CallsPerMute(false), false); got != 5 {
+		t.Errorf("EstimateApplyCalls(no decline) = %d, want 5", got)
+	}
+	if got := EstimateApplyCalls(decisions, ModeRead, CallsPerMute(false), true); got != 7 {
+		t.Errorf("EstimateApplyCalls(decline) = %d, want 7", got)
+	}
+}
+
+func TestFormatCostEstimate(t *testing.T) {
+	reset := time.Date(2026, 2, 27, 10, 30, 0, 0, time.UTC)
+
+	t.Run("within budget", func(t *testing.T) {
+		output := FormatCostEstimate(24, 2, RateLimit{Limit: 5000, Remaining: 4812, Reset: reset})
+		for _, want := range []string{"24 API calls", "(2 per muted thread)", "4812 of 5000 remaining", "10:30 UTC"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("FormatCostEstimate missing %q\nGot: %s", want, output)
+			}
+		}
+	})
+
+	t.Run("exceeds budget", func(t *testing.T) {
+		output := FormatCostEstimate(100, 2, RateLimit{Limit: 5000, Remaining: 40, Reset: reset})
+		if !strings.Contains(output, "exceeds remaining rate limit") || !strings.Contains(output, "by 60") {
+			t.Errorf("unexpected output: %s", output)
+		}
+	})
+
+	t.Run("unknown rate limit", func(t *testing.T) {
+		output := FormatCostEstimate(4, 2, RateLimit{})
+		if !strings.Contains(output, "4 API calls") || !strings.Contains(output, "rate limit unknown") {
+			t.Errorf("unexpected output: %s", output)
+		}
+	})
+}
+
+func TestGraphQLCost(t *testing.T) {
+	var g GraphQLCost
+	if got := FormatGraphQLCost(g); got != "" {
+		t.Errorf("FormatGraphQLCost() without queries = %q, want empty", got)
+	}
+
+	g.Add(1, 0, 0, time.Time{})
+	if got, want := FormatGraphQLCost(g), "GraphQL: 1 query cost 1 points; budget unknown"; got != want {
+		t.Errorf("FormatGraphQLCost() = %q, want %q", got, want)
+	}
+
+	reset := time.Date(2026, 2, 27, 10, 30, 0, 0, time.UTC)
+	g.Add(1, 5000, 4990, reset.Add(-time.Minute))
+	g.Add(3, 5000, 4987, reset)
+	g.Add(1, 0, 0, time.Time{}) // a query without rateLimit keeps the last budget
+	want := GraphQLCost{Queries: 4, Points: 6, Limit: 5000, Remaining: 4987, Reset: reset}
+	if g != want {
+		t.Errorf("GraphQLCost = %+v, want %+v", g, want)
+	}
+	if got, want := FormatGraphQLCost(g), "GraphQL: 4 queries cost 6 points; 4987 of 5000 remaining (resets 10:30 UTC)"; got != want {
+		t.Errorf("FormatGraphQLCost() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CountGroup is how --count breaks down its counts.
+type CountGroup int
+
+const (
+	GroupNone CountGroup = iota // one line per action
+	GroupOrg                    // a row per org
+	GroupRepo                   // a row per repository
+)
+
+// ParseCountGroup parses the --group-by flag.
+func ParseCountGroup(s string) (CountGroup, error) {
+	switch strings.ToLower(s) {
+	case "":
+		return GroupNone, nil
+	case "org":
+		return GroupOrg, nil
+	case "repo":
+		return GroupRepo, nil
+	default:
+		return 0, fmt.Errorf("invalid --group-by %q (valid values: org, repo)", s)
+	}
+}
+
+// ActionCounts is how many decisions of a group came to each action.
+type ActionCounts struct {
+	Group                                 string // the org or repo; empty for a run's totals
+	Keep, Mute, Defer, Skip, Dim, Archive int
+}
+
+// Total is the number of decisions counted.
+func (c ActionCounts) Total() int {
+	return c.Keep + c.Mute + c.Defer + c.Skip + c.Dim + c.Archive
+}
+
+func (c *ActionCounts) add(a Action) {
+	switch a {
+	case ActionKeep:
+		c.Keep++
+	case ActionMute:
+		c.Mute++
+	case ActionDefer:
+		c.Defer++
+	case ActionSkip:
+		c.Skip++
+	case ActionDim:
+		c.Dim++
+	case ActionArchive:
+		c.Archive++
+	}
+}
+
+// CountDecisions counts decisions by action: the run's totals, then with a
+// group one entry per org or repo, sorted by name.
+func CountDecisions(decisions []Decision, by CountGroup) (total ActionCounts, groups []ActionCounts) {
+	index := make(map[string]int)
+	for _, d := range decisions {
+		total.add(d.Action)
+		var name string
+		switch by {
+		case GroupOrg:
+			name = d.Notification.Repository.Owner
+		case GroupRepo:
+			name = d.Notification.Repository.FullName
+		default:
+			continue
+		}
+		i, ok := index[strings.ToLower(name)]
+		if !ok {
+			i = len(groups)
+			index[strings.ToLower(name)] = i
+			groups = append(groups, ActionCounts{Group: name})
+		}
+		groups[i].add(d.Action)
+	}
+	slices.SortFunc(groups, func(a, b ActionCounts) int {
+		return cmp.Compare(strings.ToLower(a.Group), strings.ToLower(b.Group))
+	})
+	return total, groups
+}
+
+// FormatCounts renders --count output: a line per action, or with groups a
+// table with a row per group and a final TOTAL row.
+func FormatCounts(total ActionCounts, groups []ActionCounts, by CountGroup) string {
+	var b strings.Builder
+	if by == GroupNone {
+		fmt.Fprintf(&b, "KEEP     %d\nMUTE     %d\nDEFER    %d\nSKIP     %d\nDIM      %d\nARCHIVE  %d\nTOTAL    %d\n", total.Keep, total.Mute, total.Defer, total.Skip, total.Dim, total.Archive, total.Total())
+		return b.String()
+	}
+	header := "ORG"
+	if by == GroupRepo {
+		header = "REPO"
+	}
+	width := len("TOTAL")
+	for _, g := range groups {
+		width = max(width, len(g.Group))
+	}
+	row := func(name string, c ActionCounts) {
+		fmt.Fprintf(&b, "%-*s  %4d  %4d  %5d  %4d  %3d  %7d  %5d\n", width, name, c.Keep, c.Mute, c.Defer, c.Skip, c.Dim, c.Archive, c.Total())
+	}
+	fmt.Fprintf(&b, "%-*s  %4s  %4s  %5s  %4s  %3s  %7s  %5s\n", width, header, "KEEP", "MUTE", "DEFER", "SKIP", "DIM", "ARCHIVE", "TOTAL")
+	for _, g := range groups {
+		row(g.Group, g)
+	}
+	row("TOTAL", total)
+	return b.String()
+}
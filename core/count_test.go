@@ -0,0 +1,86 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseCountGroup(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    CountGroup
+		wantErr bool
+	}{
+		{"", GroupNone, false},
+		{"org", GroupOrg, false},
+		{"Repo", GroupRepo, false},
+		{"team", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCountGroup(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseCountGroup(%q) = %v, %v, want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func countFixture() []Decision {
+	repo := func(owner, name string) Notification {
+		return Notification{Repository: Repository{Owner: owner, FullName: owner + "/" + name}}
+	}
+	return []Decision{
+		{Notification: repo("org", "web"), Action: ActionMute},
+		{Notification: repo("org", "api"), Action: ActionKeep},
+		{Notification: repo("oss", "lib"), Action: ActionSkip},
+		{Notification: repo("Org", "api"), Action: ActionMute},
+		{Notification: repo("org", "web"), Action: ActionDefer},
+		{Notification: repo("oss", "lib"), Action: ActionDim},
+		{Notification: repo("org", "api"), Action: ActionArchive},
+	}
+}
+
+func TestCountDecisions(t *testing.T) {
+	tests := []struct {
+		name string
+		by   CountGroup
+		want []ActionCounts
+	}{
+		{"none", GroupNone, nil},
+		{"org", GroupOrg, []ActionCounts{
+			{Group: "org", Keep: 1, Mute: 2, Defer: 1, Archive: 1},
+			{Group: "oss", Skip: 1, Dim: 1},
+		}},
+		{"repo", GroupRepo, []ActionCounts{
+			{Group: "org/api", Keep: 1, Mute: 1, Archive: 1},
+			{Group: "org/web", Mute: 1, Defer: 1},
+			{Group: "oss/lib", Skip: 1, Dim: 1},
+		}},
+	}
+	for _, tt := range tests {
+		total, groups := CountDecisions(countFixture(), tt.by)
+		if want := (ActionCounts{Keep: 1, Mute: 2, Defer: 1, Skip: 1, Dim: 1, Archive: 1}); total != want {
+			t.Errorf("%s: total = %+v, want %+v", tt.name, total, want)
+		}
+		if !slices.Equal(groups, tt.want) {
+			t.Errorf("%s: groups = %+v, want %+v", tt.name, groups, tt.want)
+		}
+	}
+}
+
+func TestFormatCounts(t *testing.T) {
+	total, _ := CountDecisions(countFixture(), GroupNone)
+	want := "KEEP     1\nMUTE     2\nDEFER    1\nSKIP     1\nDIM      1\nARCHIVE  1\nTOTAL    7\n"
+	if got := FormatCounts(total, nil, GroupNone); got != want {
+		t.Errorf("FormatCounts() =\n%s\nwant\n%s", got, want)
+	}
+
+	total, groups := CountDecisions(countFixture(), GroupRepo)
+	want = "REPO     KEEP  MUTE  DEFER  SKIP  DIM  ARCHIVE  TOTAL\n" +
+		"org/api     1     1      0     0    0        1      3\n" +
+		"org/web     0     1      1     0    0        0      2\n" +
+		"oss/lib     0     0      0     1    1        0      2\n" +
+		"TOTAL       1     2      1     1    1        1      7\n"
+	if got := FormatCounts(total, groups, GroupRepo); got != want {
+		t.Errorf("FormatCounts() by repo =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DSN is a parsed Sentry DSN: https://<public key>@<host>/<project id>.
+type DSN struct {
+	Scheme    string
+	PublicKey string
+	Host      string
+	Path      string // prefix before the project ID, for self-hosted instances under a subpath
+	ProjectID string
+}
+
+// ParseDSN parses a Sentry-compatible DSN.
+func ParseDSN(s string) (DSN, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return DSN{}, fmt.Errorf("invalid SENTRY_DSN: %w", err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return DSN{}, fmt.Errorf("invalid SENTRY_DSN: scheme must be https or http")
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return DSN{}, fmt.Errorf("invalid SENTRY_DSN: missing public key")
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	project := path[i+1:]
+	if project == "" {
+		return DSN{}, fmt.Errorf("invalid SENTRY_DSN: missing project ID")
+	}
+	return DSN{
+		Scheme:    u.Scheme,
+		PublicKey: u.User.Username(),
+		Host:      u.Host,
+		Path:      path[:i],
+		ProjectID: project,
+	}, nil
+}
+
+// EnvelopeURL returns the endpoint events are posted to.
+func (d DSN) EnvelopeURL() string {
+	return fmt.Sprintf("%s://%s%s/api/%s/envelope/", d.Scheme, d.Host, d.Path, d.ProjectID)
+}
+
+// AuthHeader returns the X-Sentry-Auth header value.
+func (d DSN) AuthHeader(client string) string {
+	return fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", client, d.PublicKey)
+}
+
+// String renders the DSN, as sent in envelope headers.
+func (d DSN) String() string {
+	return fmt.Sprintf("%s://%s@%s%s/%s", d.Scheme, d.PublicKey, d.Host, d.Path, d.ProjectID)
+}
+
+// Filtered replaces scrubbed secrets in reports.
+const Filtered = "[Filtered]"
+
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{20,}`),
+	regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{20,}`),
+	regexp.MustCompile(`(?i)\b(bearer|token)(\s+)[A-Za-z0-9._~+/=-]{8,}`),
+	regexp.MustCompile(`(?i)([?&](?:access_token|token|key)=)[^&\s]+`),
+}
+
+// ScrubSecrets removes tokens from text bound for an error report: the given
+// secrets (the configured tokens) and anything shaped like a GitHub token,
+// bearer credential, or token query parameter.
+func ScrubSecrets(s string, secrets []string) string {
+	for _, secret := range secrets {
+		// Very short values would scrub innocent text and can't be real tokens.
+		if len(secret) >= 8 {
+			s = strings.ReplaceAll(s, secret, Filtered)
+		}
+	}
+	s = secretPatterns[0].ReplaceAllString(s, Filtered)
+	s = secretPatterns[1].ReplaceAllString(s, Filtered)
+	s = secretPatterns[2].ReplaceAllString(s, "${1}${2}"+Filtered)
+	s = secretPatterns[3].ReplaceAllString(s, "${1}"+Filtered)
+	return s
+}
+
+// StackFrame is one frame of a goroutine stack trace.
+type StackFrame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+// ParseGoStack parses the first goroutine of a runtime/debug.Stack() trace
+// into frames, innermost first.
+func ParseGoStack(stack string) []StackFrame {
+	lines := strings.Split(stack, "\n")
+	var frames []StackFrame
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "goroutine ") {
+			if frames != nil {
+				break
+			}
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "\t") || i+1 >= len(lines) || !strings.HasPrefix(lines[i+1], "\t") {
+			continue
+		}
+		fn := line
+		if created, ok := strings.CutPrefix(fn, "created by "); ok {
+			fn, _, _ = strings.Cut(created, " in goroutine ")
+		} else if p := strings.LastIndex(fn, "("); p > 0 {
+			fn = fn[:p]
+		}
+		// "\t/path/file.go:123 +0x1d"
+		loc := strings.TrimSpace(lines[i+1])
+		if sp := strings.IndexByte(loc, ' '); sp >= 0 {
+			loc = loc[:sp]
+		}
+		f := StackFrame{Function: fn, File: loc}
+		if c := strings.LastIndexByte(loc, ':'); c >= 0 {
+			if n, err := strconv.Atoi(loc[c+1:]); err == nil {
+				f.File, f.Line = loc[:c], n
+			}
+		}
+		frames = append(frames, f)
+		i++
+	}
+	return frames
+}
+
+// CycleErrorReportThreshold is how many consecutive daemon cycles must fail
+// before the failure is reported; a single failed poll is usually a blip.
+const CycleErrorReportThreshold = 3
+
+// ErrorStreak counts consecutive cycle errors.
+type ErrorStreak struct {
+	Count int
+}
+
+// Record notes a cycle's outcome and reports whether this error should be
+// reported: once per streak, when it reaches CycleErrorReportThreshold.
+func (s *ErrorStreak) Record(err error) bool {
+	if err == nil {
+		s.Count = 0
+		return false
+	}
+	s.Count++
+	return s.Count == CycleErrorReportThreshold
+}
@@ -0,0 +1,44 @@
+package core
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PersonalRequests is the set of open PRs that request the user's review
+// personally, from a search, keyed by lowercase "owner/repo#number". A nil
+// set means the search failed.
+type PersonalRequests map[string]bool
+
+// NewPersonalRequests builds the set from search results' API URLs,
+// ignoring any that don't parse.
+func NewPersonalRequests(prURLs []string) PersonalRequests {
+	set := make(PersonalRequests, len(prURLs))
+	for _, u := range prURLs {
+		if ref, err := ParseSubjectURL(u); err == nil {
+			set[prKey(ref)] = true
+		}
+	}
+	return set
+}
+
+func prKey(ref PRRef) string {
+	return strings.ToLower(ref.Owner + "/" + ref.Repo + "#" + strconv.Itoa(ref.Number))
+}
+
+// GuardPersonalRequests double-checks a mute of a review request against the
+// search: if the search shows the PR still requesting the user personally,
+// or the search failed, the request is kept instead. It guards against stale
+// or incomplete reviewer data; other decisions pass through.
+func GuardPersonalRequests(d Decision, requested PersonalRequests) Decision {
+	if d.Action != ActionMute || d.Notification.Reason != "review_requested" || d.Notification.Subject.Type != "PullRequest" {
+		return d
+	}
+	switch ref, err := ParseSubjectURL(d.Notification.Subject.URL); {
+	case requested == nil:
+		d.Action, d.Reason = ActionKeep, "search cross-check unavailable"
+	case err == nil && requested[prKey(ref)]:
+		d.Action, d.Reason = ActionKeep, "search shows you requested personally"
+	}
+	return d
+}
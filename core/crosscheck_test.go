@@ -0,0 +1,45 @@
+package core
+
+import "testing"
+
+func TestGuardPersonalRequests(t *testing.T) {
+	requested := NewPersonalRequests([]string{
+		"https://api.github.com/repos/Org/Repo/pulls/1",
+		"https://ghes.example.com/api/v3/repos/org/other/pulls/7",
+		"not a url",
+	})
+	review := func(url string) Decision {
+		return Decision{
+			Notification: Notification{Reason: "review_requested", Subject: Subject{Type: "PullRequest", URL: url}},
+			Action:       ActionMute,
+			Reason:       "team-only review request",
+		}
+	}
+	mention := review("https://api.github.com/repos/org/repo/pulls/1")
+	mention.Notification.Reason = "mention"
+	kept := review("https://api.github.com/repos/org/repo/pulls/1")
+	kept.Action = ActionKeep
+
+	tests := []struct {
+		name       string
+		d          Decision
+		requested  PersonalRequests
+		wantAction Action
+		wantReason string
+	}{
+		{"still requested", review("https://api.github.com/repos/org/repo/pulls/1"), requested, ActionKeep, "search shows you requested personally"},
+		{"other host", review("https://ghes.example.com/api/v3/repos/org/other/pulls/7"), requested, ActionKeep, "search shows you requested personally"},
+		{"not requested", review("https://api.github.com/repos/org/repo/pulls/2"), requested, ActionMute, "team-only review request"},
+		{"search failed", review("https://api.github.com/repos/org/repo/pulls/2"), nil, ActionKeep, "search cross-check unavailable"},
+		{"not a review request", mention, nil, ActionMute, "team-only review request"},
+		{"already kept", kept, requested, ActionKeep, "team-only review request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GuardPersonalRequests(tt.d, tt.requested)
+			if got.Action != tt.wantAction || got.Reason != tt.wantReason {
+				t.Errorf("GuardPersonalRequests() = %s (%s), want %s (%s)", got.Action, got.Reason, tt.wantAction, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// How much the daemon's dashboard keeps: it lives in memory for as long as
+// the daemon runs.
+const (
+	DashboardCycles  = 20  // recent cycles shown
+	DashboardThreads = 200 // threads whose latest decision is shown
+	DashboardMutes   = 50  // journal records shown
+)
+
+// CycleRecord is one daemon cycle, as the dashboard shows it.
+type CycleRecord struct {
+	Host        string // empty unless processing several hosts
+	At          time.Time
+	Duration    time.Duration
+	Scanned     int
+	Actioned    int
+	Errors      int
+	NotModified bool
+	Err         string    // the cycle's error, if it failed
+	RateLimit   RateLimit // from the cycle's last response; zero if none carried one
+}
+
+// Dashboard collects what the daemon's dashboard shows between cycles: recent
+// cycles, the latest decision on each thread seen, and each host's rate limit.
+// The daemon only lists threads that changed, so the decisions add up over
+// cycles into a picture of the inbox.
+type Dashboard struct {
+	Cycles  []CycleRecord        // newest first
+	Threads []Decision           // newest first, one per thread
+	Rates   map[string]RateLimit // by host
+}
+
+// Record adds a cycle and its decisions, dropping the oldest cycles and
+// threads past DashboardCycles and DashboardThreads.
+func (d *Dashboard) Record(c CycleRecord, decisions []Decision) {
+	d.Cycles = append([]CycleRecord{c}, d.Cycles...)
+	if len(d.Cycles) > DashboardCycles {
+		d.Cycles = d.Cycles[:DashboardCycles]
+	}
+	if c.RateLimit.Limit > 0 {
+		if d.Rates == nil {
+			d.Rates = make(map[string]RateLimit)
+		}
+		d.Rates[c.Host] = c.RateLimit
+	}
+
+	seen := make(map[string]bool, len(decisions))
+	for _, dec := range decisions {
+		seen[threadKey(dec.Notification)] = true
+	}
+	d.Threads = slices.DeleteFunc(d.Threads, func(dec Decision) bool {
+		return seen[threadKey(dec.Notification)]
+	})
+	d.Threads = append(slices.Clone(decisions), d.Threads...)
+	if len(d.Threads) > DashboardThreads {
+		d.Threads = d.Threads[:DashboardThreads]
+	}
+}
+
+func threadKey(n Notification) string {
+	return n.Host + "/" + n.ID
+}
+
+// Table is a titled table of text cells, for the dashboard to render.
+type Table struct {
+	Title  string
+	Header []string
+	Rows   [][]string
+	Empty  string // shown instead of an empty table
+}
+
+// DashboardTables lays out the dashboard as tables: rate limits, recent
+// cycles, the inbox as last classified, and mute history from the journal,
+// newest first.
+func DashboardTables(d Dashboard, journal []MutationRecord, now time.Time) []Table {
+	rates := Table{
+		Title:  "Rate limit",
+		Header: []string{"Host", "Remaining", "Limit", "Resets"},
+		Empty:  "No responses with rate-limit headers yet.",
+	}
+	hosts := make([]string, 0, len(d.Rates))
+	for h := range d.Rates {
+		hosts = append(hosts, h)
+	}
+	sort.Strings(hosts)
+	for _, h := range hosts {
+		rl := d.Rates[h]
+		resets := "now"
+		if wait := rl.Reset.Sub(now); wait >= time.Minute {
+			resets = "in " + FormatAge(wait)
+		}
+		rates.Rows = append(rates.Rows, []string{hostName(h), fmt.Sprint(rl.Remaining), fmt.Sprint(rl.Limit), resets})
+	}
+
+	cycles := Table{
+		Title:  "Recent cycles",
+		Header: []string{"When", "Host", "Took", "Result"},
+		Empty:  "No cycles yet.",
+	}
+	for _, c := range d.Cycles {
+		result := fmt.Sprintf("%d scanned, %d actioned, %d errors", c.Scanned, c.Actioned, c.Errors)
+		switch {
+		case c.Err != "":
+			result = "error: " + c.Err
+		case c.NotModified:
+			result = "not modified"
+		}
+		cycles.Rows = append(cycles.Rows, []string{formatAgo(now.Sub(c.At)), hostName(c.Host), c.Duration.Round(10 * time.Millisecond).String(), result})
+	}
+
+	inbox := Table{
+		Title:  "Inbox, as last classified",
+		Header: []string{"Updated", "Thread", "Title", "Decision"},
+		Empty:  "No notifications seen yet.",
+	}
+	for _, dec := range d.Threads {
+		decision := fmt.Sprintf("%s (%s)", dec.Action, dec.Reason)
+		if dec.Action == ActionDefer {
+			decision = fmt.Sprintf("%s (%s, until %s)", dec.Action, dec.Reason, dec.Until.Format("Mon 15:04 MST"))
+		}
+		inbox.Rows = append(inbox.Rows, []string{formatAgo(now.Sub(dec.Notification.UpdatedAt)), formatLabel(dec), dec.Notification.Subject.Title, decision})
+	}
+
+	mutes := Table{
+		Title:  "Mute history",
+		Header: []string{"When", "Thread", "Title", "Change"},
+		Empty:  "Nothing muted yet.",
+	}
+	for i := len(journal) - 1; i >= 0 && len(mutes.Rows) < DashboardMutes; i-- {
+		r := journal[i]
+		if r.Kept {
+			continue
+		}
+		change := "muted, marked " + r.Mode.ActionLabelLower()
+		switch {
+		case r.Escalate:
+			change = "resurfaced, review SLA passed"
+		case r.Undo:
+			change = "undone"
+		}
+		mutes.Rows = append(mutes.Rows, []string{formatAgo(now.Sub(r.Time)), r.Label, r.Title, change})
+	}
+
+	return []Table{rates, cycles, inbox, mutes}
+}
+
+// hostName names a host for the dashboard; empty is the only host, from
+// GH_HOST or github.com.
+func hostName(host string) string {
+	if host == "" {
+		return "default"
+	}
+	return host
+}
+
+// formatAgo renders an age as "12m ago", or "now".
+func formatAgo(d time.Duration) string {
+	if age := FormatAge(d); age != "now" {
+		return age + " ago"
+	}
+	return "now"
+}
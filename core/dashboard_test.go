@@ -0,0 +1,93 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDashboardRecord(t *testing.T) {
+	t0 := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	thread := func(id string, a Action) Decision {
+		return Decision{Notification: Notification{ID: id}, Action: a}
+	}
+
+	var d Dashboard
+	d.Record(CycleRecord{At: t0, RateLimit: RateLimit{Limit: 5000, Remaining: 4990}}, []Decision{thread("1", ActionKeep), thread("2", ActionKeep)})
+	d.Record(CycleRecord{At: t0.Add(time.Minute)}, []Decision{thread("2", ActionMute)})
+
+	var ids []string
+	for _, dec := range d.Threads {
+		ids = append(ids, dec.Notification.ID+" "+dec.Action.String())
+	}
+	if want := []string{"2 MUTE", "1 KEEP"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Threads = %v, want %v", ids, want)
+	}
+	if len(d.Cycles) != 2 || !d.Cycles[0].At.Equal(t0.Add(time.Minute)) {
+		t.Errorf("Cycles = %v, want the newest first", d.Cycles)
+	}
+	if rl := d.Rates[""]; rl.Remaining != 4990 {
+		t.Errorf("Rates[\"\"] = %v, want the last cycle carrying a rate limit", rl)
+	}
+
+	for i := range DashboardCycles + 5 {
+		d.Record(CycleRecord{At: t0.Add(time.Duration(i) * time.Hour)}, nil)
+	}
+	if len(d.Cycles) != DashboardCycles {
+		t.Errorf("len(Cycles) = %d, want %d", len(d.Cycles), DashboardCycles)
+	}
+}
+
+func TestDashboardTables(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	d := Dashboard{
+		Cycles: []CycleRecord{
+			{At: now.Add(-2 * time.Minute), Duration: 1234 * time.Millisecond, Scanned: 3, Actioned: 1},
+			{At: now.Add(-7 * time.Minute), Err: "list notifications: 502"},
+		},
+		Threads: []Decision{{
+			Notification: Notification{
+				ID:         "9",
+				Subject:    Subject{Title: "Bump deps", URL: "https://api.github.com/repos/org/repo/pulls/42"},
+				Repository: Repository{FullName: "org/repo"},
+				UpdatedAt:  now.Add(-3 * time.Hour),
+			},
+			Action: ActionMute,
+			Reason: "team-only",
+		}},
+		Rates: map[string]RateLimit{"": {Limit: 5000, Remaining: 4100, Reset: now.Add(30 * time.Minute)}},
+	}
+	journal := []MutationRecord{
+		{Time: now.Add(-time.Hour), Label: "org/repo#42", Title: "Bump deps"},
+		{Time: now.Add(-time.Hour), Label: "org/repo#7", Kept: true},
+		{Time: now.Add(-10 * time.Second), Label: "org/repo#42", Title: "Bump deps", Undo: true},
+	}
+
+	got := DashboardTables(d, journal, now)
+	want := [][][]string{
+		{{"default", "4100", "5000", "in 30m"}},
+		{
+			{"2m ago", "default", "1.23s", "3 scanned, 1 actioned, 0 errors"},
+			{"7m ago", "default", "0s", "error: list notifications: 502"},
+		},
+		{{"3h ago", "org/repo#42", "Bump deps", "MUTE (team-only)"}},
+		{
+			{"now", "org/repo#42", "Bump deps", "undone"},
+			{"1h ago", "org/repo#42", "Bump deps", "muted, marked read"},
+		},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("DashboardTables() = %d tables, want %d", len(got), len(want))
+	}
+	for i, table := range got {
+		if !reflect.DeepEqual(table.Rows, want[i]) {
+			t.Errorf("%s rows = %q, want %q", table.Title, table.Rows, want[i])
+		}
+	}
+
+	for _, table := range DashboardTables(Dashboard{}, nil, now) {
+		if len(table.Rows) != 0 || table.Empty == "" {
+			t.Errorf("%s: empty dashboard = %q rows, empty text %q", table.Title, table.Rows, table.Empty)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"strings"
+	"time"
+)
+
+// ReviewDeadline finds a PR's review deadline: a "review-by:" line in its
+// description, like "review-by: 2026-10-20", or a "deadline/2026-10-20"
+// label. A date is due at the end of that day in loc; an RFC 3339 time is due
+// then. With several, the earliest wins. ok is false if there's none.
+func ReviewDeadline(pr *PullRequest, loc *time.Location) (due time.Time, ok bool) {
+	consider := func(s string) {
+		if t, valid := parseDeadline(strings.TrimSpace(s), loc); valid && (!ok || t.Before(due)) {
+			due, ok = t, true
+		}
+	}
+	for _, line := range strings.Split(pr.Body, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) > len("review-by:") && strings.EqualFold(line[:len("review-by:")], "review-by:") {
+			consider(line[len("review-by:"):])
+		}
+	}
+	for _, l := range pr.Labels {
+		if len(l) > len("deadline/") && strings.EqualFold(l[:len("deadline/")], "deadline/") {
+			consider(l[len("deadline/"):])
+		}
+	}
+	return due, ok
+}
+
+func parseDeadline(s string, loc *time.Location) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+	if d, err := time.ParseInLocation(time.DateOnly, s, loc); err == nil {
+		return d.AddDate(0, 0, 1), true
+	}
+	return time.Time{}, false
+}
+
+// deadlineReason is the reason a request due at due is kept, at now: "review
+// due in 3d" or "review overdue by 5h".
+func deadlineReason(due, now time.Time) string {
+	if due.After(now) {
+		return "review due in " + FormatAge(due.Sub(now))
+	}
+	return "review overdue by " + FormatAge(now.Sub(due))
+}
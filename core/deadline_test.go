@@ -0,0 +1,61 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReviewDeadline(t *testing.T) {
+	berlin := time.FixedZone("CEST", 2*60*60)
+	tests := []struct {
+		name   string
+		pr     PullRequest
+		loc    *time.Location
+		want   time.Time
+		wantOK bool
+	}{
+		{"none", PullRequest{Body: "Fixes the build."}, time.UTC, time.Time{}, false},
+		{"body date", PullRequest{Body: "Fixes the build.\n\nReview-By: 2026-10-20\n"}, time.UTC, time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC), true},
+		{"body date in zone", PullRequest{Body: "review-by: 2026-10-20"}, berlin, time.Date(2026, 10, 21, 0, 0, 0, 0, berlin), true},
+		{"body time", PullRequest{Body: "  review-by: 2026-10-20T15:00:00Z"}, time.UTC, time.Date(2026, 10, 20, 15, 0, 0, 0, time.UTC), true},
+		{"label", PullRequest{Labels: []string{"bug", "deadline/2026-10-18"}}, time.UTC, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), true},
+		{"earliest wins", PullRequest{Body: "review-by: 2026-10-20", Labels: []string{"deadline/2026-10-18"}}, time.UTC, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), true},
+		{"unparseable", PullRequest{Body: "review-by: next Friday", Labels: []string{"deadline/soon"}}, time.UTC, time.Time{}, false},
+		{"mid-line", PullRequest{Body: "no review-by: 2026-10-20 here"}, time.UTC, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ReviewDeadline(&tt.pr, tt.loc)
+		if ok != tt.wantOK || !got.Equal(tt.want) {
+			t.Errorf("%s: ReviewDeadline() = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestDecideDeadline(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	n := Notification{ID: "1", Reason: "review_requested", Subject: Subject{Type: "PullRequest", URL: "https://api.github.com/repos/acme/api/pulls/42"}, Repository: Repository{Owner: "acme"}}
+	team := &Reviewers{Teams: []string{"backend"}}
+	due := func(date string) *PullRequest { return &PullRequest{Body: "review-by: " + date} }
+	cfg := Config{DeadlineWithin: 3 * 24 * time.Hour}
+	tests := []struct {
+		name       string
+		facts      Facts
+		cfg        Config
+		wantAction Action
+		wantReason string
+	}{
+		{"due soon", Facts{Reviewers: team, PR: due("2026-10-17"), Now: now}, cfg, ActionKeep, "review due in 36h"},
+		{"overdue", Facts{Reviewers: team, PR: due("2026-10-14"), Now: now}, cfg, ActionKeep, "review overdue by 36h"},
+		{"slack", Facts{Reviewers: team, PR: due("2026-10-30"), Now: now}, cfg, ActionMute, "team-only review request"},
+		{"no deadline", Facts{Reviewers: team, PR: &PullRequest{}, Now: now}, cfg, ActionMute, "team-only review request"},
+		{"no PR data", Facts{Reviewers: team, Now: now}, cfg, ActionSkip, "no PR data"},
+		{"off", Facts{Reviewers: team, PR: due("2026-10-17"), Now: now}, Config{}, ActionMute, "team-only review request"},
+		{"direct request", Facts{Reviewers: &Reviewers{Users: []string{"me"}}, PR: due("2026-10-30"), Now: now}, cfg, ActionKeep, "direct review request"},
+	}
+	for _, tt := range tests {
+		d := Decide(n, tt.facts, "me", tt.cfg)
+		if d.Action != tt.wantAction || d.Reason != tt.wantReason {
+			t.Errorf("%s: Decide() = %s (%s), want %s (%s)", tt.name, d.Action, d.Reason, tt.wantAction, tt.wantReason)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NeedsDecline reports whether --decline applies to a decision: a muted
+// review request on a pull request.
+func NeedsDecline(d Decision) bool {
+	n := d.Notification
+	return d.Action == ActionMute && n.Reason == "review_requested" && n.Subject.Type == "PullRequest"
+}
+
+// DeclineRequest reports whether login's review request can be removed from a
+// PR, given its current requested reviewers, and if not, why. Only a personal
+// request can: GitHub can't take one member off a team's request, and
+// removing the team would decline for all its members.
+func DeclineRequest(reviewers *Reviewers, login string) (bool, string) {
+	if containsFold(reviewers.Users, login) {
+		return true, ""
+	}
+	if len(reviewers.Teams) > 0 {
+		return false, fmt.Sprintf("requested only through %s, which can't be declined for one member", strings.Join(reviewers.Teams, ", "))
+	}
+	return false, "no longer requested"
+}
+
+// FormatDeclineRow formats the outcome of declining a review request:
+// "DECLINE" when removed, "NOTE" when it couldn't be, and "ERROR" on failure.
+func FormatDeclineRow(d Decision, note string, err error) string {
+	switch {
+	case err != nil:
+		return fmt.Sprintf("ERROR  %s  decline review request: %s", formatLabel(d), err)
+	case note != "":
+		return fmt.Sprintf("NOTE   %s  not declined: %s", formatLabel(d), note)
+	default:
+		return fmt.Sprintf("DECLINE  %s  removed your review request", formatLabel(d))
+	}
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNeedsDecline(t *testing.T) {
+	review := Notification{Reason: "review_requested", Subject: Subject{Type: "PullRequest"}}
+	tests := []struct {
+		name string
+		d    Decision
+		want bool
+	}{
+		{name: "muted review request", d: Decision{Notification: review, Action: ActionMute}, want: true},
+		{name: "kept review request", d: Decision{Notification: review, Action: ActionKeep}},
+		{name: "muted mention", d: Decision{Notification: Notification{Reason: "mention", Subject: Subject{Type: "PullRequest"}}, Action: ActionMute}},
+		{name: "muted issue", d: Decision{Notification: Notification{Reason: "review_requested", Subject: Subject{Type: "Issue"}}, Action: ActionMute}},
+	}
+	for _, tt := range tests {
+		if got := NeedsDecline(tt.d); got != tt.want {
+			t.Errorf("%s: NeedsDecline() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDeclineRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		reviewers Reviewers
+		wantOK    bool
+		wantNote  string
+	}{
+		{name: "personal", reviewers: Reviewers{Users: []string{"Octocat"}, Teams: []string{"platform"}}, wantOK: true},
+		{name: "team only", reviewers: Reviewers{Users: []string{"hubot"}, Teams: []string{"platform", "infra"}}, wantNote: "requested only through platform, infra, which can't be declined for one member"},
+		{name: "gone", reviewers: Reviewers{}, wantNote: "no longer requested"},
+	}
+	for _, tt := range tests {
+		ok, note := DeclineRequest(&tt.reviewers, "octocat")
+		if ok != tt.wantOK || note != tt.wantNote {
+			t.Errorf("%s: DeclineRequest() = %v, %q; want %v, %q", tt.name, ok, note, tt.wantOK, tt.wantNote)
+		}
+	}
+}
+
+func TestFormatDeclineRow(t *testing.T) {
+	d := Decision{Notification: Notification{
+		Subject:    Subject{URL: "https://api.github.com/repos/org/repo/pulls/7"},
+		Repository: Repository{FullName: "org/repo"},
+	}}
+	tests := []struct {
+		note string
+		err  error
+		want string
+	}{
+		{want: "DECLINE  org/repo#7  removed your review request"},
+		{note: "no longer requested", want: "NOTE   org/repo#7  not declined: no longer requested"},
+		{err: errors.New("unexpected status 422"), want: "ERROR  org/repo#7  decline review request: unexpected status 422"},
+	}
+	for _, tt := range tests {
+		if got := FormatDeclineRow(d, tt.note, tt.err); got != tt.want {
+			t.Errorf("FormatDeclineRow() = %q, want %q", got, tt.want)
+		}
+	}
+}
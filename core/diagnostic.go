@@ -0,0 +1,78 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ConfigDiagnostic is a problem found in a config file, at a 1-based line and
+// column (0 when the position is unknown).
+type ConfigDiagnostic struct {
+	Line    int
+	Col     int
+	Warning bool
+	Msg     string
+}
+
+// LineCol converts a byte offset in text into a 1-based line and column,
+// counting columns in characters.
+func LineCol(text string, offset int) (line, col int) {
+	offset = min(max(offset, 0), len(text))
+	line, col = 1, 1
+	for _, r := range text[:offset] {
+		if r == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+// JSONStringOffset maps a byte index into a decoded JSON string back to an
+// offset in the raw document, where start is the offset of the string's
+// opening quote. Escape sequences are what make the two differ.
+func JSONStringOffset(raw string, start, decodedIndex int) int {
+	i := start + 1
+	for n := 0; n < decodedIndex && i < len(raw) && raw[i] != '"'; {
+		if raw[i] != '\\' || i+1 >= len(raw) {
+			i++
+			n++
+			continue
+		}
+		if raw[i+1] == 'u' && i+6 <= len(raw) {
+			r, _ := strconv.ParseUint(raw[i+2:i+6], 16, 32)
+			i += 6
+			n += max(utf8.RuneLen(rune(r)), 1)
+			continue
+		}
+		i += 2
+		n++
+	}
+	return i
+}
+
+// FormatConfigDiagnostic renders a diagnostic as "path:line:col: message",
+// the form editors and CI annotations understand.
+func FormatConfigDiagnostic(path string, d ConfigDiagnostic) string {
+	severity := "error"
+	if d.Warning {
+		severity = "warning"
+	}
+	if d.Line == 0 {
+		return fmt.Sprintf("%s: %s: %s", path, severity, d.Msg)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %s", path, d.Line, d.Col, severity, d.Msg)
+}
+
+// HasErrors reports whether any diagnostic is an error rather than a warning.
+func HasErrors(diags []ConfigDiagnostic) bool {
+	for _, d := range diags {
+		if !d.Warning {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package core
+
+import "testing"
+
+func TestLineCol(t *testing.T) {
+	text := "{\n  \"rules\": [\n    {\"when\": \"é\"}\n  ]\n}"
+	tests := []struct {
+		offset   int
+		wantLine int
+		wantCol  int
+	}{
+		{offset: 0, wantLine: 1, wantCol: 1},
+		{offset: 4, wantLine: 2, wantCol: 3},
+		{offset: 31, wantLine: 3, wantCol: 16}, // after the two-byte é
+		{offset: -5, wantLine: 1, wantCol: 1},
+		{offset: 1000, wantLine: 5, wantCol: 2},
+	}
+	for _, tt := range tests {
+		line, col := LineCol(text, tt.offset)
+		if line != tt.wantLine || col != tt.wantCol {
+			t.Errorf("LineCol(%d) = %d:%d, want %d:%d", tt.offset, line, col, tt.wantLine, tt.wantCol)
+		}
+	}
+}
+
+func TestJSONStringOffset(t *testing.T) {
+	raw := `{"when": "a \"b\" é && x"}`
+	start := 9 // opening quote
+	tests := []struct {
+		decoded int
+		want    int
+	}{
+		{decoded: 0, want: 10},  // a
+		{decoded: 2, want: 12},  // \"
+		{decoded: 3, want: 14},  // b
+		{decoded: 6, want: 18},  // é
+		{decoded: 8, want: 20},  // space after é, which is two bytes
+		{decoded: 99, want: 25}, // clamped at the closing quote
+	}
+	for _, tt := range tests {
+		if got := JSONStringOffset(raw, start, tt.decoded); got != tt.want {
+			t.Errorf("JSONStringOffset(%d) = %d, want %d", tt.decoded, got, tt.want)
+		}
+	}
+}
+
+func TestFormatConfigDiagnostic(t *testing.T) {
+	tests := []struct {
+		d    ConfigDiagnostic
+		want string
+	}{
+		{ConfigDiagnostic{Line: 3, Col: 14, Msg: "unknown key"}, "c.json:3:14: error: unknown key"},
+		{ConfigDiagnostic{Line: 1, Col: 1, Warning: true, Msg: "shadowed"}, "c.json:1:1: warning: shadowed"},
+		{ConfigDiagnostic{Msg: "unreadable"}, "c.json: error: unreadable"},
+	}
+	for _, tt := range tests {
+		if got := FormatConfigDiagnostic("c.json", tt.d); got != tt.want {
+			t.Errorf("FormatConfigDiagnostic() = %q, want %q", got, tt.want)
+		}
+	}
+	if HasErrors([]ConfigDiagnostic{{Warning: true}}) {
+		t.Error("HasErrors(warnings only) = true")
+	}
+	if !HasErrors([]ConfigDiagnostic{{Warning: true}, {}}) {
+		t.Error("HasErrors(with error) = false")
+	}
+}
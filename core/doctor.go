@@ -0,0 +1,251 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+)
+
+// APIBaseURL returns the REST API root for a GitHub host. An empty host or
+// "github.com" means github.com; anything else is treated as a GHES appliance.
+func APIBaseURL(host string) string {
+	if host == "" || strings.EqualFold(host, "github.com") {
+		return "https://api.github.com"
+	}
+	return "https://" + host + "/api/v3"
+}
+
+// APIHost is the inverse of APIBaseURL: the host a REST API root belongs
+// to, github.com for https://api.github.com.
+func APIHost(apiBase string) string {
+	host := strings.TrimPrefix(apiBase, "https://")
+	if host == "api.github.com" {
+		return "github.com"
+	}
+	return strings.TrimSuffix(host, "/api/v3")
+}
+
+// GraphQLURL returns the GraphQL endpoint for a REST API root from APIBaseURL:
+// https://api.github.com/graphql, or https://<host>/api/graphql on Enterprise
+// Server.
+func GraphQLURL(apiBase string) string {
+	if root, ok := strings.CutSuffix(apiBase, "/api/v3"); ok {
+		return root + "/api/graphql"
+	}
+	return apiBase + "/graphql"
+}
+
+// WebBaseURL returns the browser root for a GitHub host.
+func WebBaseURL(host string) string {
+	if host == "" {
+		host = "github.com"
+	}
+	return "https://" + host
+}
+
+type CheckStatus int
+
+const (
+	CheckOK CheckStatus = iota
+	CheckWarn
+	CheckFail
+)
+
+func (s CheckStatus) String() string {
+	switch s {
+	case CheckOK:
+		return "OK"
+	case CheckWarn:
+		return "WARN"
+	case CheckFail:
+		return "FAIL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// CheckResult is the outcome of one doctor check. Fix is empty when nothing needs doing.
+type CheckResult struct {
+	Name   string
+	Status CheckStatus
+	Detail string
+	Fix    string
+}
+
+// RequiredScopes are the classic PAT scopes mutemath needs.
+var RequiredScopes = []string{"notifications", "repo"}
+
+// maxClockSkew is how far the local clock may drift from GitHub's before we warn.
+const maxClockSkew = time.Minute
+
+// CheckReachability evaluates the result of requesting the API root.
+func CheckReachability(baseURL string, err error) CheckResult {
+	if err != nil {
+		return CheckResult{
+			Name:   "API reachable",
+			Status: CheckFail,
+			Detail: fmt.Sprintf("%s: %s", baseURL, err),
+			Fix:    "check network/proxy settings, and GH_HOST if you use GitHub Enterprise Server",
+		}
+	}
+	return CheckResult{Name: "API reachable", Status: CheckOK, Detail: baseURL}
+}
+
+func tokenFix(webBase string) string {
+	return fmt.Sprintf("generate a Classic PAT at %s/settings/tokens and export GH_TOKEN", webBase)
+}
+
+// CheckTokenMissing is the result when no token is configured at all.
+func CheckTokenMissing(webBase string) CheckResult {
+	return CheckResult{
+		Name:   "Token",
+		Status: CheckFail,
+		Detail: "GH_TOKEN is not set, and gh is not logged in",
+		Fix:    tokenFix(webBase) + ", or run gh auth login --scopes notifications,repo",
+	}
+}
+
+// CheckToken evaluates the HTTP status of GET /user made with the configured token.
+func CheckToken(status int, login, webBase string) CheckResult {
+	fix := tokenFix(webBase)
+	switch {
+	case status == 200:
+		return CheckResult{Name: "Token", Status: CheckOK, Detail: "authenticated as " + login}
+	case status == 401:
+		return CheckResult{Name: "Token", Status: CheckFail, Detail: "GitHub rejected the token (401)", Fix: fix}
+	case status == 403:
+		return CheckResult{
+			Name:   "Token",
+			Status: CheckFail,
+			Detail: "access forbidden (403)",
+			Fix:    "if your org uses SSO, authorize the token for it under Configure SSO",
+		}
+	default:
+		return CheckResult{Name: "Token", Status: CheckFail, Detail: fmt.Sprintf("unexpected status %d", status), Fix: fix}
+	}
+}
+
+// CheckScopes evaluates an X-OAuth-Scopes header against RequiredScopes.
+// Fine-grained tokens send no scopes header at all.
+func CheckScopes(header string, webBase string) CheckResult {
+	if strings.TrimSpace(header) == "" {
+		return CheckResult{
+			Name:   "Token scopes",
+			Status: CheckFail,
+			Detail: "no scopes reported (fine-grained tokens are not supported by the notifications API)",
+			Fix:    fmt.Sprintf("use a Classic PAT from %s/settings/tokens", webBase),
+		}
+	}
+	have := make(map[string]bool)
+	for _, s := range strings.Split(header, ",") {
+		have[strings.TrimSpace(s)] = true
+	}
+	var missing []string
+	for _, s := range RequiredScopes {
+		if !have[s] {
+			missing = append(missing, s)
+		}
+	}
+	if len(missing) > 0 {
+		return CheckResult{
+			Name:   "Token scopes",
+			Status: CheckFail,
+			Detail: "missing " + strings.Join(missing, ", "),
+			Fix:    fmt.Sprintf("edit the token at %s/settings/tokens and add the missing scopes", webBase),
+		}
+	}
+	return CheckResult{Name: "Token scopes", Status: CheckOK, Detail: strings.TrimSpace(header)}
+}
+
+// orgScopes are the classic PAT scopes that can list an org's team members.
+var orgScopes = []string{"read:org", "write:org", "admin:org"}
+
+// CheckOrgScope evaluates an X-OAuth-Scopes header for listing team members,
+// which --team-size-threshold and mute_teammate_reviewing need. Without it,
+// they can't tell who's on a team, so they leave requests for small teams
+// unread instead of keeping them.
+func CheckOrgScope(header string, webBase string) CheckResult {
+	for _, s := range strings.Split(header, ",") {
+		if slices.Contains(orgScopes, strings.TrimSpace(s)) {
+			return CheckResult{Name: "Team members", Status: CheckOK, Detail: "token can list team members"}
+		}
+	}
+	return CheckResult{
+		Name:   "Team members",
+		Status: CheckWarn,
+		Detail: "token lacks read:org, so --team-size-threshold and mute_teammate_reviewing can't list team members",
+		Fix:    fmt.Sprintf("if you use either, edit the token at %s/settings/tokens and add read:org", webBase),
+	}
+}
+
+// CheckClockSkew compares the server's Date header with the local clock.
+func CheckClockSkew(serverDate, now time.Time) CheckResult {
+	if serverDate.IsZero() {
+		return CheckResult{Name: "Clock skew", Status: CheckWarn, Detail: "server sent no Date header"}
+	}
+	skew := now.Sub(serverDate)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > maxClockSkew {
+		return CheckResult{
+			Name:   "Clock skew",
+			Status: CheckWarn,
+			Detail: fmt.Sprintf("local clock is %s off from GitHub", skew.Round(time.Second)),
+			Fix:    "enable NTP time synchronization on this machine",
+		}
+	}
+	return CheckResult{Name: "Clock skew", Status: CheckOK, Detail: skew.Round(time.Second).String()}
+}
+
+// CheckConfig reports on the config file: absent (fine, built-in rules only),
+// unparseable, or loaded with some number of rules.
+func CheckConfig(path string, found bool, rules int, err error) CheckResult {
+	switch {
+	case err != nil:
+		return CheckResult{Name: "Config", Status: CheckFail, Detail: err.Error(), Fix: "correct the config file, or move it aside to use the built-in rules"}
+	case !found:
+		return CheckResult{Name: "Config", Status: CheckOK, Detail: fmt.Sprintf("no config file at %s (built-in rules only)", path)}
+	default:
+		return CheckResult{Name: "Config", Status: CheckOK, Detail: fmt.Sprintf("%s (%d rules)", path, rules)}
+	}
+}
+
+// CheckStateDir evaluates whether the state dir, dir, takes a new file: err
+// is from creating it if need be and writing and removing a file in it.
+func CheckStateDir(dir string, err error) CheckResult {
+	fix := fmt.Sprintf("run mkdir -p %s, or set \"dir\" under \"state\" in the config file to a writable directory", dir)
+	if errors.Is(err, fs.ErrPermission) {
+		fix = fmt.Sprintf("run chmod u+rwx %s, or set \"dir\" under \"state\" in the config file to a writable directory", dir)
+	}
+	switch {
+	case dir == "":
+		return CheckResult{Name: "State dir", Status: CheckFail, Detail: err.Error(), Fix: "set \"dir\" under \"state\" in the config file"}
+	case err != nil:
+		return CheckResult{Name: "State dir", Status: CheckFail, Detail: fmt.Sprintf("%s isn't writable: %s", dir, err), Fix: fix}
+	default:
+		return CheckResult{Name: "State dir", Status: CheckOK, Detail: dir + " is writable"}
+	}
+}
+
+// FormatCheckResult renders a check as a status line plus an indented fix line.
+func FormatCheckResult(r CheckResult) string {
+	line := fmt.Sprintf("[%-4s] %s: %s", r.Status, r.Name, r.Detail)
+	if r.Fix != "" {
+		line += "\n       fix: " + r.Fix
+	}
+	return line
+}
+
+// AnyFailed reports whether any check failed outright.
+func AnyFailed(results []CheckResult) bool {
+	for _, r := range results {
+		if r.Status == CheckFail {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,224 @@
+package core
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIBaseURL(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "", want: "https://api.github.com"},
+		{host: "github.com", want: "https://api.github.com"},
+		{host: "GitHub.com", want: "https://api.github.com"},
+		{host: "ghes.example.com", want: "https://ghes.example.com/api/v3"},
+	}
+	for _, tt := range tests {
+		if got := APIBaseURL(tt.host); got != tt.want {
+			t.Errorf("APIBaseURL(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestAPIHost(t *testing.T) {
+	for _, host := range []string{"github.com", "ghes.example.com"} {
+		if got := APIHost(APIBaseURL(host)); got != host {
+			t.Errorf("APIHost(APIBaseURL(%q)) = %q", host, got)
+		}
+	}
+}
+
+func TestGraphQLURL(t *testing.T) {
+	if got := GraphQLURL("https://api.github.com"); got != "https://api.github.com/graphql" {
+		t.Errorf("GraphQLURL(github.com) = %q", got)
+	}
+	if got := GraphQLURL("https://ghes.example.com/api/v3"); got != "https://ghes.example.com/api/graphql" {
+		t.Errorf("GraphQLURL(ghes) = %q", got)
+	}
+}
+
+func TestWebBaseURL(t *testing.T) {
+	if got := WebBaseURL(""); got != "https://github.com" {
+		t.Errorf("WebBaseURL(\"\") = %q", got)
+	}
+	if got := WebBaseURL("ghes.example.com"); got != "https://ghes.example.com" {
+		t.Errorf("WebBaseURL(ghes) = %q", got)
+	}
+}
+
+func TestCheckReachability(t *testing.T) {
+	if r := CheckReachability("https://api.github.com", nil); r.Status != CheckOK {
+		t.Errorf("reachable: status = %v, want OK", r.Status)
+	}
+	r := CheckReachability("https://ghes.example.com/api/v3", errors.New("no such host"))
+	if r.Status != CheckFail || r.Fix == "" || !strings.Contains(r.Detail, "no such host") {
+		t.Errorf("unreachable: got %+v", r)
+	}
+}
+
+func TestCheckToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		want    CheckStatus
+		wantFix bool
+	}{
+		{name: "ok", status: 200, want: CheckOK},
+		{name: "unauthorized", status: 401, want: CheckFail, wantFix: true},
+		{name: "forbidden", status: 403, want: CheckFail, wantFix: true},
+		{name: "server error", status: 502, want: CheckFail, wantFix: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := CheckToken(tt.status, "me", "https://github.com")
+			if r.Status != tt.want {
+				t.Errorf("CheckToken(%d) status = %v, want %v", tt.status, r.Status, tt.want)
+			}
+			if (r.Fix != "") != tt.wantFix {
+				t.Errorf("CheckToken(%d) fix = %q, wantFix %v", tt.status, r.Fix, tt.wantFix)
+			}
+		})
+	}
+
+	if r := CheckTokenMissing("https://github.com"); r.Status != CheckFail || !strings.Contains(r.Fix, "GH_TOKEN") {
+		t.Errorf("CheckTokenMissing() = %+v", r)
+	}
+}
+
+func TestCheckScopes(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		want       CheckStatus
+		wantDetail string
+	}{
+		{name: "all scopes", header: "notifications, repo", want: CheckOK},
+		{name: "extra scopes", header: "gist, notifications, read:org, repo", want: CheckOK},
+		{name: "missing repo", header: "notifications", want: CheckFail, wantDetail: "missing repo"},
+		{name: "missing both", header: "gist", want: CheckFail, wantDetail: "missing notifications, repo"},
+		{name: "fine-grained token", header: "", want: CheckFail, wantDetail: "fine-grained"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := CheckScopes(tt.header, "https://github.com")
+			if r.Status != tt.want {
+				t.Errorf("CheckScopes(%q) status = %v, want %v", tt.header, r.Status, tt.want)
+			}
+			if !strings.Contains(r.Detail, tt.wantDetail) {
+				t.Errorf("CheckScopes(%q) detail = %q, want it to contain %q", tt.header, r.Detail, tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestCheckOrgScope(t *testing.T) {
+	tests := []struct {
+		header string
+		want   CheckStatus
+	}{
+		{"notifications, read:org, repo", CheckOK},
+		{"admin:org, notifications, repo", CheckOK},
+		{"notifications, repo", CheckWarn},
+	}
+	for _, tt := range tests {
+		r := CheckOrgScope(tt.header, "https://github.com")
+		if r.Status != tt.want || (r.Status == CheckWarn) != strings.Contains(r.Fix, "read:org") {
+			t.Errorf("CheckOrgScope(%q) = %+v, want status %s", tt.header, r, tt.want)
+		}
+	}
+}
+
+func TestCheckClockSkew(t *testing.T) {
+	server := time.Date(2026, 2, 27, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want CheckStatus
+	}{
+		{name: "in sync", now: server.Add(2 * time.Second), want: CheckOK},
+		{name: "local clock ahead", now: server.Add(5 * time.Minute), want: CheckWarn},
+		{name: "local clock behind", now: server.Add(-5 * time.Minute), want: CheckWarn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if r := CheckClockSkew(server, tt.now); r.Status != tt.want {
+				t.Errorf("CheckClockSkew() status = %v, want %v (%s)", r.Status, tt.want, r.Detail)
+			}
+		})
+	}
+
+	if r := CheckClockSkew(time.Time{}, server); r.Status != CheckWarn {
+		t.Errorf("missing Date header: status = %v, want WARN", r.Status)
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		found  bool
+		rules  int
+		err    error
+		want   CheckStatus
+		detail string
+	}{
+		{name: "missing", want: CheckOK, detail: "no config file at /c.json (built-in rules only)"},
+		{name: "loaded", found: true, rules: 3, want: CheckOK, detail: "/c.json (3 rules)"},
+		{name: "invalid", found: true, err: errors.New("rule x: when: col 1: unknown field"), want: CheckFail, detail: "rule x: when: col 1: unknown field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := CheckConfig("/c.json", tt.found, tt.rules, tt.err)
+			if r.Status != tt.want || r.Detail != tt.detail {
+				t.Errorf("CheckConfig() = %v %q, want %v %q", r.Status, r.Detail, tt.want, tt.detail)
+			}
+		})
+	}
+}
+
+func TestCheckStateDir(t *testing.T) {
+	tests := []struct {
+		name       string
+		dir        string
+		err        error
+		wantStatus CheckStatus
+		wantFix    string
+	}{
+		{"writable", "/home/me/.cache/mutemath", nil, CheckOK, ""},
+		{"permission denied", "/data/mutemath", &fs.PathError{Op: "open", Path: "/data/mutemath/x", Err: fs.ErrPermission}, CheckFail, "chmod u+rwx /data/mutemath"},
+		{"read-only file system", "/data/mutemath", errors.New("read-only file system"), CheckFail, "mkdir -p /data/mutemath"},
+		{"no cache dir", "", errors.New("neither $XDG_CACHE_HOME nor $HOME are defined"), CheckFail, `set "dir" under "state"`},
+	}
+	for _, tt := range tests {
+		r := CheckStateDir(tt.dir, tt.err)
+		if r.Status != tt.wantStatus || !strings.Contains(r.Fix, tt.wantFix) || (tt.wantFix == "") != (r.Fix == "") {
+			t.Errorf("%s: CheckStateDir() = %+v, want status %s and a fix with %q", tt.name, r, tt.wantStatus, tt.wantFix)
+		}
+	}
+}
+
+func TestFormatCheckResult(t *testing.T) {
+	ok := FormatCheckResult(CheckResult{Name: "Token", Status: CheckOK, Detail: "authenticated as me"})
+	if ok != "[OK  ] Token: authenticated as me" {
+		t.Errorf("unexpected output: %q", ok)
+	}
+
+	fail := FormatCheckResult(CheckResult{Name: "Token", Status: CheckFail, Detail: "rejected", Fix: "make a new one"})
+	if !strings.Contains(fail, "[FAIL]") || !strings.Contains(fail, "\n       fix: make a new one") {
+		t.Errorf("unexpected output: %q", fail)
+	}
+}
+
+func TestAnyFailed(t *testing.T) {
+	if AnyFailed([]CheckResult{{Status: CheckOK}, {Status: CheckWarn}}) {
+		t.Error("AnyFailed() = true for OK/WARN results")
+	}
+	if !AnyFailed([]CheckResult{{Status: CheckOK}, {Status: CheckFail}}) {
+		t.Error("AnyFailed() = false with a FAIL result")
+	}
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// Drift compares a run's decisions with the previous run's, so a daily dry
+// run only shows what changed.
+
+// PriorDecision is a decision as stored from the previous run.
+type PriorDecision struct {
+	Time     time.Time
+	Host     string // empty unless processing several hosts
+	ThreadID string
+	Action   Action
+	Reason   string
+}
+
+// Prior reduces decisions to what the next run compares against.
+func Prior(decisions []Decision, now time.Time) []PriorDecision {
+	out := make([]PriorDecision, 0, len(decisions))
+	for _, d := range decisions {
+		out = append(out, PriorDecision{Time: now, Host: d.Notification.Host, ThreadID: d.Notification.ID, Action: d.Action, Reason: d.Reason})
+	}
+	return out
+}
+
+// DriftKind is how a decision changed since the previous run.
+type DriftKind int
+
+const (
+	DriftNew       DriftKind = iota // not in the previous run
+	DriftMuted                      // newly muted
+	DriftEscalated                  // newly kept
+	DriftChanged                    // any other change of action
+)
+
+func (k DriftKind) String() string {
+	switch k {
+	case DriftNew:
+		return "NEW"
+	case DriftMuted:
+		return "MUTED"
+	case DriftEscalated:
+		return "ESCALATED"
+	default:
+		return "CHANGED"
+	}
+}
+
+// Drift is a decision whose action differs from the previous run's.
+type Drift struct {
+	Kind     DriftKind
+	Decision Decision
+	Was      Action // the previous action; meaningless for DriftNew
+}
+
+// DiffDecisions returns the decisions that are new or whose action changed
+// since prior, in run order. A changed reason alone isn't drift.
+func DiffDecisions(prior []PriorDecision, decisions []Decision) []Drift {
+	was := make(map[string]Action, len(prior))
+	for _, p := range prior {
+		was[p.Host+"\x00"+p.ThreadID] = p.Action
+	}
+	var out []Drift
+	for _, d := range decisions {
+		a, ok := was[d.Notification.Host+"\x00"+d.Notification.ID]
+		switch {
+		case !ok:
+			out = append(out, Drift{Kind: DriftNew, Decision: d})
+		case a == d.Action:
+		case d.Action == ActionMute:
+			out = append(out, Drift{Kind: DriftMuted, Decision: d, Was: a})
+		case d.Action == ActionKeep:
+			out = append(out, Drift{Kind: DriftEscalated, Decision: d, Was: a})
+		default:
+			out = append(out, Drift{Kind: DriftChanged, Decision: d, Was: a})
+		}
+	}
+	return out
+}
+
+// FormatDriftRow formats a changed decision as a line of --diff output.
+func FormatDriftRow(d Drift) string {
+	action := fmt.Sprintf("%s (%s)", d.Decision.Action, d.Decision.Reason)
+	if d.Kind != DriftNew {
+		action = fmt.Sprintf("%s → %s", d.Was, action)
+	}
+	return fmt.Sprintf("%-9s  %-40s  %-90s  %s", d.Kind, formatLabel(d.Decision), d.Decision.Notification.Subject.Title, action)
+}
+
+// FormatDriftSummary renders the line after --diff output: how many
+// decisions changed since the previous run, and when it was.
+func FormatDriftSummary(drifts, total int, previous, now time.Time) string {
+	if previous.IsZero() {
+		return fmt.Sprintf("No previous run to compare with: all %d decisions are new", total)
+	}
+	when := FormatAge(now.Sub(previous))
+	if when != "now" {
+		when += " ago"
+	}
+	return fmt.Sprintf("%d of %d decisions changed since the previous run (%s)", drifts, total, when)
+}
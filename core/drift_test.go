@@ -0,0 +1,67 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffDecisions(t *testing.T) {
+	dec := func(id string, a Action) Decision {
+		return Decision{Notification: Notification{ID: id}, Action: a, Reason: "r"}
+	}
+	prior := []PriorDecision{
+		{ThreadID: "same", Action: ActionKeep, Reason: "old reason"},
+		{ThreadID: "muted", Action: ActionKeep},
+		{ThreadID: "escalated", Action: ActionMute},
+		{ThreadID: "changed", Action: ActionKeep},
+		{ThreadID: "gone", Action: ActionMute},
+		{Host: "ghes.example.com", ThreadID: "other host", Action: ActionMute},
+	}
+	decisions := []Decision{
+		dec("new", ActionSkip),
+		dec("same", ActionKeep),
+		dec("muted", ActionMute),
+		dec("escalated", ActionKeep),
+		dec("changed", ActionDefer),
+		dec("other host", ActionMute),
+	}
+	got := DiffDecisions(prior, decisions)
+	want := []Drift{
+		{Kind: DriftNew, Decision: decisions[0]},
+		{Kind: DriftMuted, Decision: decisions[2], Was: ActionKeep},
+		{Kind: DriftEscalated, Decision: decisions[3], Was: ActionMute},
+		{Kind: DriftChanged, Decision: decisions[4], Was: ActionKeep},
+		{Kind: DriftNew, Decision: decisions[5]},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffDecisions() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestFormatDrift(t *testing.T) {
+	d := Decision{
+		Notification: Notification{Subject: Subject{Title: "Fix", URL: "https://api.github.com/repos/org/api/pulls/42"}, Repository: Repository{FullName: "org/api"}},
+		Action:       ActionMute,
+		Reason:       "team-only review request",
+	}
+	if got := FormatDriftRow(Drift{Kind: DriftMuted, Decision: d, Was: ActionKeep}); !strings.HasPrefix(got, "MUTED      org/api#42 ") || !strings.HasSuffix(got, "  KEEP → MUTE (team-only review request)") {
+		t.Errorf("FormatDriftRow() = %q", got)
+	}
+
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		previous time.Time
+		want     string
+	}{
+		{time.Time{}, "No previous run to compare with: all 9 decisions are new"},
+		{now.Add(-30 * time.Second), "2 of 9 decisions changed since the previous run (now)"},
+		{now.Add(-26 * time.Hour), "2 of 9 decisions changed since the previous run (26h ago)"},
+	}
+	for _, tt := range tests {
+		if got := FormatDriftSummary(2, 9, tt.previous, now); got != tt.want {
+			t.Errorf("FormatDriftSummary(%v) = %q, want %q", tt.previous, got, tt.want)
+		}
+	}
+}
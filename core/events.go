@@ -0,0 +1,107 @@
+package core
+
+import (
+	"strings"
+	"time"
+)
+
+// The event log records every classification and mutation as it happens,
+// one event per line, for analysis with jq or DuckDB.
+
+// EventSchemaVersion is written with every event. It goes up when a field
+// changes meaning or is removed; adding fields doesn't change it.
+const EventSchemaVersion = 1
+
+// Event kinds.
+const (
+	EventDecision = "decision" // a notification was classified
+	EventMute     = "mute"     // a mute was attempted; Err is set if it failed
+	EventUndo     = "undo"     // a mute was undone
+)
+
+// Event is one line of the event log.
+type Event struct {
+	Time     time.Time
+	Kind     string
+	User     string // empty unless serving several users
+	Host     string // empty unless processing several hosts
+	ThreadID string
+	Label    string // e.g. "org/repo#42"
+	Title    string
+	URL      string // the subject's API URL
+	Reason   string // the notification's reason, e.g. "review_requested"
+	State    string // the thread's inbox state when listed, e.g. "unread"
+
+	// Decisions.
+	Action string // lowercase, e.g. "mute"
+	Why    string // the decision's reason, e.g. "team-only review request"
+	Rule   string
+	Teams  []string
+	Apply  bool // the run applies its decisions, rather than previewing them
+
+	// Mutations.
+	Mode     string // "read" or "done"
+	Step     string // with Err, the step that failed: "mark" or "ignore"
+	Err      string
+	Escalate bool // an undo because the review SLA passed
+}
+
+// DecisionEvent builds the event for a classification.
+func DecisionEvent(d Decision, apply bool, now time.Time) Event {
+	e := notificationEvent(EventDecision, d.Notification, now)
+	e.Label = formatLabel(d)
+	e.Action = strings.ToLower(d.Action.String())
+	e.Why = d.Reason
+	e.Rule = d.Rule
+	e.Teams = d.Teams
+	e.Apply = apply
+	return e
+}
+
+// MuteEvent builds the event for a mute attempt, or a dim or archive; err is
+// nil if it succeeded, and otherwise failed at step.
+func MuteEvent(d Decision, mode Mode, step MutationStep, err error, now time.Time) Event {
+	e := notificationEvent(EventMute, d.Notification, now)
+	e.Label = formatLabel(d)
+	e.Teams = d.Teams
+	e.Action = strings.ToLower(d.Action.String())
+	e.Mode = d.Action.MarkMode(mode).ActionLabelLower()
+	if err != nil {
+		e.Err = err.Error()
+		e.Step = "mark"
+		if step == StepIgnore {
+			e.Step = "ignore"
+		}
+	}
+	return e
+}
+
+// UndoEvent builds the event for a journaled undo.
+func UndoEvent(r MutationRecord) Event {
+	return Event{
+		Time:     r.Time,
+		Kind:     EventUndo,
+		Host:     r.Host,
+		ThreadID: r.ThreadID,
+		Label:    r.Label,
+		Title:    r.Title,
+		URL:      r.URL,
+		Reason:   r.Reason,
+		Teams:    r.Teams,
+		Mode:     r.Mode.ActionLabelLower(),
+		Escalate: r.Escalate,
+	}
+}
+
+func notificationEvent(kind string, n Notification, now time.Time) Event {
+	return Event{
+		Time:     now,
+		Kind:     kind,
+		Host:     n.Host,
+		ThreadID: n.ID,
+		Title:    n.Subject.Title,
+		URL:      n.Subject.URL,
+		Reason:   n.Reason,
+		State:    n.State.String(),
+	}
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecisionEvent(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	d := Decision{
+		Notification: Notification{
+			ID:         "77",
+			Host:       "ghes.example.com",
+			Reason:     "review_requested",
+			Subject:    Subject{Title: "Bump deps", URL: "https://ghes.example.com/api/v3/repos/org/repo/pulls/9", Type: "PullRequest"},
+			Repository: Repository{FullName: "org/repo"},
+			State:      StateRead,
+		},
+		Action: ActionMute,
+		Reason: "rule bots",
+		Rule:   "bots",
+		Teams:  []string{"backend"},
+	}
+	want := Event{
+		Time:     now,
+		Kind:     EventDecision,
+		Host:     "ghes.example.com",
+		ThreadID: "77",
+		Label:    "ghes.example.com/org/repo#9",
+		Title:    "Bump deps",
+		URL:      "https://ghes.example.com/api/v3/repos/org/repo/pulls/9",
+		Reason:   "review_requested",
+		State:    "read",
+		Action:   "mute",
+		Why:      "rule bots",
+		Rule:     "bots",
+		Teams:    []string{"backend"},
+		Apply:    true,
+	}
+	if got := DecisionEvent(d, true, now); !reflect.DeepEqual(got, want) {
+		t.Errorf("DecisionEvent() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMuteEvent(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	d := Decision{Notification: Notification{ID: "5", Repository: Repository{FullName: "org/repo"}}, Action: ActionMute}
+
+	ok := MuteEvent(d, ModeDone, StepIgnore, nil, now)
+	if ok.Kind != EventMute || ok.Mode != "done" || ok.Err != "" || ok.Step != "" {
+		t.Errorf("MuteEvent() of a success = %+v", ok)
+	}
+	failed := MuteEvent(d, ModeRead, StepIgnore, errors.New("unexpected status 502"), now)
+	if failed.Err != "unexpected status 502" || failed.Step != "ignore" || failed.Mode != "read" {
+		t.Errorf("MuteEvent() of a failure = %+v", failed)
+	}
+	d.Action = ActionArchive
+	if archived := MuteEvent(d, ModeRead, StepMark, nil, now); archived.Action != "archive" || archived.Mode != "done" {
+		t.Errorf("MuteEvent() of an archive = %+v", archived)
+	}
+}
+
+func TestUndoEvent(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	r := RecordEscalation(MutationRecord{ThreadID: "5", Label: "org/repo#9", Mode: ModeDone}, now)
+	e := UndoEvent(r)
+	if e.Kind != EventUndo || !e.Escalate || e.ThreadID != "5" || e.Mode != "done" || !e.Time.Equal(now) {
+		t.Errorf("UndoEvent() = %+v", e)
+	}
+}
@@ -0,0 +1,770 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// A small, CEL-flavoured expression language for rule conditions, e.g.
+//
+//	notification.reason == "review_requested" && "platform-team" in reviewers.teams && pr.draft
+//
+// Expressions are type-checked when parsed, so evaluation cannot fail: no
+// loops, no I/O, no way to reach anything but the fields listed in exprFields.
+//
+// Grammar, loosest binding first:
+//
+//	or      = and { "||" and }
+//	and     = rel { "&&" rel }
+//	rel     = unary [ ("==" | "!=" | "<" | "<=" | ">" | ">=" | "in") unary ]
+//	unary   = ( "!" | "-" ) unary | postfix
+//	postfix = primary { "." ident [ "(" args ")" ] }
+//	primary = string | int | "true" | "false" | "[" args "]" | "(" or ")"
+//	        | ident "(" args ")" | ident
+
+// ExprEnv is the data an expression is evaluated against.
+type ExprEnv struct {
+	Notification Notification
+	Facts        Facts
+	Login        string
+	Hours        *BusinessHours // for time.* fields; nil if not configured
+}
+
+type exprType int
+
+const (
+	typeBool exprType = iota
+	typeInt
+	typeString
+	typeStringList
+	typeIntList
+	typeEmptyList // [] — compatible with any list type
+)
+
+func (t exprType) String() string {
+	switch t {
+	case typeBool:
+		return "bool"
+	case typeInt:
+		return "int"
+	case typeString:
+		return "string"
+	case typeStringList:
+		return "list(string)"
+	case typeIntList:
+		return "list(int)"
+	default:
+		return "list"
+	}
+}
+
+func (t exprType) isList() bool {
+	return t == typeStringList || t == typeIntList || t == typeEmptyList
+}
+
+// elem returns the element type of a list type.
+func (t exprType) elem() exprType {
+	if t == typeIntList {
+		return typeInt
+	}
+	return typeString
+}
+
+type exprField struct {
+	typ exprType
+	get func(*ExprEnv) any
+}
+
+// exprFields are the variables an expression can reference.
+var exprFields = map[string]exprField{
+	"login":               {typeString, func(e *ExprEnv) any { return e.Login }},
+	"notification.id":     {typeString, func(e *ExprEnv) any { return e.Notification.ID }},
+	"notification.reason": {typeString, func(e *ExprEnv) any { return e.Notification.Reason }},
+	"notification.type":   {typeString, func(e *ExprEnv) any { return e.Notification.Subject.Type }},
+	"notification.title":  {typeString, func(e *ExprEnv) any { return e.Notification.Subject.Title }},
+	"notification.repo":   {typeString, func(e *ExprEnv) any { return e.Notification.Repository.FullName }},
+	"notification.org":    {typeString, func(e *ExprEnv) any { return e.Notification.Repository.Owner }},
+	"repo.topics":         {typeStringList, func(e *ExprEnv) any { return e.Facts.Topics }},
+	"repo.language":       {typeString, func(e *ExprEnv) any { return e.Facts.Language }},
+	"reviewers.users": {typeStringList, func(e *ExprEnv) any {
+		if e.Facts.Reviewers == nil {
+			return []string(nil)
+		}
+		return e.Facts.Reviewers.Users
+	}},
+	"reviewers.teams": {typeStringList, func(e *ExprEnv) any {
+		if e.Facts.Reviewers == nil {
+			return []string(nil)
+		}
+		return e.Facts.Reviewers.Teams
+	}},
+	"request.source":      {typeString, func(e *ExprEnv) any { return RequestSource(e.Facts.ReviewRequests, e.Login) }},
+	"pr.draft":            {typeBool, func(e *ExprEnv) any { return e.pr().Draft }},
+	"pr.author":           {typeString, func(e *ExprEnv) any { return e.pr().Author }},
+	"pr.state":            {typeString, func(e *ExprEnv) any { return e.pr().State }},
+	"pr.labels":           {typeStringList, func(e *ExprEnv) any { return e.pr().Labels }},
+	"pr.body":             {typeString, func(e *ExprEnv) any { return e.pr().Body }},
+	"pr.assignees":        {typeStringList, func(e *ExprEnv) any { return e.pr().Assignees }},
+	"pr.head":             {typeString, func(e *ExprEnv) any { return e.pr().Head }},
+	"pr.base":             {typeString, func(e *ExprEnv) any { return e.pr().Base }},
+	"pr.files":            {typeStringList, func(e *ExprEnv) any { return e.Facts.Files }},
+	"pr.review_decision":  {typeString, func(e *ExprEnv) any { return e.Facts.ReviewDecision }},
+	"time.hour":           {typeInt, func(e *ExprEnv) any { return int64(e.localNow().Hour()) }},
+	"time.weekday":        {typeString, func(e *ExprEnv) any { return weekdayNames[e.localNow().Weekday()] }},
+	"time.business_hours": {typeBool, func(e *ExprEnv) any { return e.Hours != nil && e.Hours.Contains(e.Facts.Now) }},
+}
+
+// localNow returns the deciding time in the business-hours timezone, or UTC.
+func (e *ExprEnv) localNow() time.Time {
+	if e.Hours == nil {
+		return e.Facts.Now.UTC()
+	}
+	return e.Facts.Now.In(e.Hours.Location)
+}
+
+// pr returns the PR details, or a zero PullRequest if none were fetched.
+func (e *ExprEnv) pr() PullRequest {
+	if e.Facts.PR == nil {
+		return PullRequest{}
+	}
+	return *e.Facts.PR
+}
+
+// ExprFieldNames lists the fields available to expressions, sorted.
+func ExprFieldNames() []string {
+	names := make([]string, 0, len(exprFields))
+	for name := range exprFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Expr is a parsed, type-checked boolean expression.
+type Expr struct {
+	src    string
+	root   exprNode
+	fields []string // fields referenced, in order of first use
+}
+
+type exprNode struct {
+	typ  exprType
+	eval func(*ExprEnv) any
+}
+
+// ParseExpr parses and type-checks a boolean expression.
+func ParseExpr(src string) (*Expr, error) {
+	toks, err := lexExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{toks: toks}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, exprErrorf(t.pos, "unexpected %s", t)
+	}
+	if root.typ != typeBool {
+		return nil, exprErrorf(0, "expression must be bool, got %s", root.typ)
+	}
+	return &Expr{src: src, root: root, fields: p.fields}, nil
+}
+
+// Eval evaluates the expression.
+func (e *Expr) Eval(env ExprEnv) bool {
+	return e.root.eval(&env).(bool)
+}
+
+func (e *Expr) String() string {
+	return e.src
+}
+
+// UsesField reports whether the expression references a field, or any field
+// under a prefix ending in "." (e.g. "pr.").
+func (e *Expr) UsesField(name string) bool {
+	for _, f := range e.fields {
+		if f == name || (strings.HasSuffix(name, ".") && strings.HasPrefix(f, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExprError is a parse or type error at a 1-based column of the expression.
+type ExprError struct {
+	Col int
+	Msg string
+}
+
+func (e *ExprError) Error() string {
+	return fmt.Sprintf("col %d: %s", e.Col, e.Msg)
+}
+
+func exprErrorf(pos int, format string, args ...any) error {
+	return &ExprError{Col: pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Lexer.
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokIdent
+	tokString
+	tokInt
+	tokOp
+)
+
+type token struct {
+	kind tokKind
+	text string // identifier, operator, or decoded string literal
+	num  int64
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return strconv.Quote(t.text)
+	case tokInt:
+		return strconv.FormatInt(t.num, 10)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+var exprOps = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "-", "(", ")", "[", "]", ",", "."}
+
+func lexExpr(src string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(src) {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			s, n, err := lexString(src[i:])
+			if err != nil {
+				return nil, exprErrorf(i, "%s", err)
+			}
+			toks = append(toks, token{kind: tokString, text: s, pos: i})
+			i += n
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(src) && src[j] >= '0' && src[j] <= '9' {
+				j++
+			}
+			n, err := strconv.ParseInt(src[i:j], 10, 64)
+			if err != nil {
+				return nil, exprErrorf(i, "invalid number %s", src[i:j])
+			}
+			toks = append(toks, token{kind: tokInt, num: n, pos: i})
+			i = j
+		case c == '_' || unicode.IsLetter(c):
+			j := i
+			for j < len(src) && (src[j] == '_' || unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j]))) {
+				j++
+			}
+			toks = append(toks, token{kind: tokIdent, text: src[i:j], pos: i})
+			i = j
+		default:
+			op := ""
+			for _, candidate := range exprOps {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, exprErrorf(i, "unexpected character %q", c)
+			}
+			toks = append(toks, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(toks, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// lexString decodes a quoted literal at the start of s, returning its value
+// and length in s. Supports \\, \", \', \n, and \t escapes.
+func lexString(s string) (string, int, error) {
+	quote := s[0]
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case quote:
+			return sb.String(), i + 1, nil
+		case '\\':
+			i++
+			if i == len(s) {
+				return "", 0, fmt.Errorf("unterminated string")
+			}
+			switch s[i] {
+			case '\\', '"', '\'':
+				sb.WriteByte(s[i])
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				return "", 0, fmt.Errorf("unknown escape \\%c", s[i])
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// Parser and type checker.
+
+type exprParser struct {
+	toks   []token
+	i      int
+	fields []string
+}
+
+func (p *exprParser) peek() token {
+	return p.toks[p.i]
+}
+
+func (p *exprParser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *exprParser) isOp(op string) bool {
+	t := p.peek()
+	return t.kind == tokOp && t.text == op
+}
+
+func (p *exprParser) expectOp(op string) error {
+	if t := p.next(); t.kind != tokOp || t.text != op {
+		return exprErrorf(t.pos, "expected %q, got %s", op, t)
+	}
+	return nil
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return exprNode{}, err
+	}
+	for p.isOp("||") {
+		op := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return exprNode{}, err
+		}
+		if left.typ != typeBool || right.typ != typeBool {
+			return exprNode{}, exprErrorf(op.pos, "|| needs bool operands, got %s and %s", left.typ, right.typ)
+		}
+		l, r := left.eval, right.eval
+		left = exprNode{typeBool, func(e *ExprEnv) any { return l(e).(bool) || r(e).(bool) }}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseRel()
+	if err != nil {
+		return exprNode{}, err
+	}
+	for p.isOp("&&") {
+		op := p.next()
+		right, err := p.parseRel()
+		if err != nil {
+			return exprNode{}, err
+		}
+		if left.typ != typeBool || right.typ != typeBool {
+			return exprNode{}, exprErrorf(op.pos, "&& needs bool operands, got %s and %s", left.typ, right.typ)
+		}
+		l, r := left.eval, right.eval
+		left = exprNode{typeBool, func(e *ExprEnv) any { return l(e).(bool) && r(e).(bool) }}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseRel() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return exprNode{}, err
+	}
+	t := p.peek()
+	isRel := (t.kind == tokOp && slices.Contains([]string{"==", "!=", "<", "<=", ">", ">="}, t.text)) ||
+		(t.kind == tokIdent && t.text == "in")
+	if !isRel {
+		return left, nil
+	}
+	p.next()
+	right, err := p.parseUnary()
+	if err != nil {
+		return exprNode{}, err
+	}
+	l, r := left.eval, right.eval
+
+	switch t.text {
+	case "in":
+		if !right.typ.isList() || left.typ.isList() || left.typ == typeBool ||
+			(right.typ != typeEmptyList && right.typ.elem() != left.typ) {
+			return exprNode{}, exprErrorf(t.pos, "cannot test %s in %s", left.typ, right.typ)
+		}
+		if left.typ == typeInt {
+			return exprNode{typeBool, func(e *ExprEnv) any {
+				list, _ := r(e).([]int64)
+				return slices.Contains(list, l(e).(int64))
+			}}, nil
+		}
+		return exprNode{typeBool, func(e *ExprEnv) any {
+			list, _ := r(e).([]string)
+			return slices.Contains(list, l(e).(string))
+		}}, nil
+	case "==", "!=":
+		if left.typ != right.typ || left.typ.isList() {
+			return exprNode{}, exprErrorf(t.pos, "cannot compare %s %s %s", left.typ, t.text, right.typ)
+		}
+		want := t.text == "=="
+		return exprNode{typeBool, func(e *ExprEnv) any { return (l(e) == r(e)) == want }}, nil
+	default:
+		if left.typ != right.typ || (left.typ != typeInt && left.typ != typeString) {
+			return exprNode{}, exprErrorf(t.pos, "cannot compare %s %s %s", left.typ, t.text, right.typ)
+		}
+		op := t.text
+		return exprNode{typeBool, func(e *ExprEnv) any {
+			var c int
+			if left.typ == typeInt {
+				c = compareInts(l(e).(int64), r(e).(int64))
+			} else {
+				c = strings.Compare(l(e).(string), r(e).(string))
+			}
+			switch op {
+			case "<":
+				return c < 0
+			case "<=":
+				return c <= 0
+			case ">":
+				return c > 0
+			default:
+				return c >= 0
+			}
+		}}, nil
+	}
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.isOp("!") {
+		op := p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return exprNode{}, err
+		}
+		if operand.typ != typeBool {
+			return exprNode{}, exprErrorf(op.pos, "! needs a bool operand, got %s", operand.typ)
+		}
+		f := operand.eval
+		return exprNode{typeBool, func(e *ExprEnv) any { return !f(e).(bool) }}, nil
+	}
+	if p.isOp("-") {
+		op := p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return exprNode{}, err
+		}
+		if operand.typ != typeInt {
+			return exprNode{}, exprErrorf(op.pos, "- needs an int operand, got %s", operand.typ)
+		}
+		f := operand.eval
+		return exprNode{typeInt, func(e *ExprEnv) any { return -f(e).(int64) }}, nil
+	}
+	return p.parsePostfix()
+}
+
+// parsePostfix handles field paths (a.b.c) and method calls (x.contains("y")).
+func (p *exprParser) parsePostfix() (exprNode, error) {
+	start := p.peek()
+	if start.kind == tokIdent && !isExprKeyword(start.text) {
+		p.next()
+		if p.isOp("(") {
+			return p.parseFunc(start)
+		}
+		path := start.text
+		for p.isOp(".") && p.toks[p.i+1].kind == tokIdent && p.toks[p.i+2].text != "(" {
+			p.next()
+			path += "." + p.next().text
+		}
+		node, err := p.resolveField(path, start.pos)
+		if err != nil {
+			return exprNode{}, err
+		}
+		return p.parseMethods(node)
+	}
+
+	node, err := p.parsePrimary()
+	if err != nil {
+		return exprNode{}, err
+	}
+	return p.parseMethods(node)
+}
+
+func isExprKeyword(s string) bool {
+	return s == "true" || s == "false" || s == "in"
+}
+
+func (p *exprParser) resolveField(path string, pos int) (exprNode, error) {
+	f, ok := exprFields[path]
+	if !ok {
+		return exprNode{}, exprErrorf(pos, "unknown field %q (available: %s)", path, strings.Join(ExprFieldNames(), ", "))
+	}
+	if !slices.Contains(p.fields, path) {
+		p.fields = append(p.fields, path)
+	}
+	return exprNode{f.typ, f.get}, nil
+}
+
+func (p *exprParser) parseMethods(recv exprNode) (exprNode, error) {
+	for p.isOp(".") {
+		p.next()
+		name := p.next()
+		if name.kind != tokIdent {
+			return exprNode{}, exprErrorf(name.pos, "expected method name, got %s", name)
+		}
+		if err := p.expectOp("("); err != nil {
+			return exprNode{}, err
+		}
+		args, err := p.parseArgs(")")
+		if err != nil {
+			return exprNode{}, err
+		}
+		recv, err = p.method(recv, name, args)
+		if err != nil {
+			return exprNode{}, err
+		}
+	}
+	return recv, nil
+}
+
+var exprMethods = []string{"contains", "startsWith", "endsWith", "matches", "glob", "anyGlob", "allGlob"}
+
+// method type-checks a method call. matches() and the glob methods require a
+// literal pattern so it can be compiled (and rejected) at parse time.
+func (p *exprParser) method(recv exprNode, name token, args []argNode) (exprNode, error) {
+	if !slices.Contains(exprMethods, name.text) {
+		return exprNode{}, exprErrorf(name.pos, "unknown method %s() (available: %s)", name.text, strings.Join(exprMethods, ", "))
+	}
+	if name.text == "anyGlob" || name.text == "allGlob" {
+		return p.listGlobMethod(recv, name, args)
+	}
+	if recv.typ != typeString {
+		return exprNode{}, exprErrorf(name.pos, "%s() is only defined on strings, not %s", name.text, recv.typ)
+	}
+	if len(args) != 1 || args[0].typ != typeString {
+		return exprNode{}, exprErrorf(name.pos, "%s() takes one string argument", name.text)
+	}
+	s, arg := recv.eval, args[0].eval
+
+	switch name.text {
+	case "contains":
+		return exprNode{typeBool, func(e *ExprEnv) any { return strings.Contains(s(e).(string), arg(e).(string)) }}, nil
+	case "startsWith":
+		return exprNode{typeBool, func(e *ExprEnv) any { return strings.HasPrefix(s(e).(string), arg(e).(string)) }}, nil
+	case "endsWith":
+		return exprNode{typeBool, func(e *ExprEnv) any { return strings.HasSuffix(s(e).(string), arg(e).(string)) }}, nil
+	case "glob":
+		if !args[0].literal {
+			return exprNode{}, exprErrorf(name.pos, "glob() needs a string literal pattern")
+		}
+		pattern := args[0].text
+		if err := CheckGlob(pattern); err != nil {
+			return exprNode{}, exprErrorf(args[0].pos, "invalid glob: %s", err)
+		}
+		return exprNode{typeBool, func(e *ExprEnv) any { return MatchGlob(pattern, s(e).(string)) }}, nil
+	default: // matches
+		if !args[0].literal {
+			return exprNode{}, exprErrorf(name.pos, "matches() needs a string literal pattern")
+		}
+		re, err := regexp.Compile(args[0].text)
+		if err != nil {
+			return exprNode{}, exprErrorf(args[0].pos, "invalid regex: %s", err)
+		}
+		return exprNode{typeBool, func(e *ExprEnv) any { return re.MatchString(s(e).(string)) }}, nil
+	}
+}
+
+// listGlobMethod type-checks anyGlob() and allGlob() on a list of strings:
+// whether any, or every, element matches the pattern. allGlob() is false for
+// an empty list, so a rule never matches on missing data.
+func (p *exprParser) listGlobMethod(recv exprNode, name token, args []argNode) (exprNode, error) {
+	if recv.typ != typeStringList {
+		return exprNode{}, exprErrorf(name.pos, "%s() is only defined on list(string), not %s", name.text, recv.typ)
+	}
+	if len(args) != 1 || args[0].typ != typeString || !args[0].literal {
+		return exprNode{}, exprErrorf(name.pos, "%s() needs a string literal pattern", name.text)
+	}
+	pattern := args[0].text
+	if err := CheckGlob(pattern); err != nil {
+		return exprNode{}, exprErrorf(args[0].pos, "invalid glob: %s", err)
+	}
+	list := recv.eval
+	if name.text == "anyGlob" {
+		return exprNode{typeBool, func(e *ExprEnv) any {
+			return slices.ContainsFunc(list(e).([]string), func(s string) bool { return MatchGlob(pattern, s) })
+		}}, nil
+	}
+	return exprNode{typeBool, func(e *ExprEnv) any {
+		items := list(e).([]string)
+		return len(items) > 0 && !slices.ContainsFunc(items, func(s string) bool { return !MatchGlob(pattern, s) })
+	}}, nil
+}
+
+func (p *exprParser) parseFunc(name token) (exprNode, error) {
+	p.next() // (
+	args, err := p.parseArgs(")")
+	if err != nil {
+		return exprNode{}, err
+	}
+	switch name.text {
+	case "size":
+		if len(args) != 1 || !(args[0].typ.isList() || args[0].typ == typeString) {
+			return exprNode{}, exprErrorf(name.pos, "size() takes one list or string argument")
+		}
+		f := args[0].eval
+		node := exprNode{typeInt, func(e *ExprEnv) any {
+			switch v := f(e).(type) {
+			case string:
+				return int64(len(v))
+			case []string:
+				return int64(len(v))
+			case []int64:
+				return int64(len(v))
+			}
+			return int64(0)
+		}}
+		return p.parseMethods(node)
+	default:
+		return exprNode{}, exprErrorf(name.pos, "unknown function %s() (available: size)", name.text)
+	}
+}
+
+// argNode is a call argument; literal strings keep their text for matches().
+type argNode struct {
+	exprNode
+	literal bool
+	text    string
+	pos     int
+}
+
+func (p *exprParser) parseArgs(closer string) ([]argNode, error) {
+	var args []argNode
+	if p.isOp(closer) {
+		p.next()
+		return args, nil
+	}
+	for {
+		t := p.peek()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		literal := t.kind == tokString && (p.isOp(",") || p.isOp(closer))
+		args = append(args, argNode{exprNode: node, literal: literal, text: t.text, pos: t.pos})
+		if p.isOp(",") {
+			p.next()
+			continue
+		}
+		if err := p.expectOp(closer); err != nil {
+			return nil, err
+		}
+		return args, nil
+	}
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokString:
+		v := t.text
+		return exprNode{typeString, func(*ExprEnv) any { return v }}, nil
+	case t.kind == tokInt:
+		v := t.num
+		return exprNode{typeInt, func(*ExprEnv) any { return v }}, nil
+	case t.kind == tokIdent && t.text == "true":
+		return exprNode{typeBool, func(*ExprEnv) any { return true }}, nil
+	case t.kind == tokIdent && t.text == "false":
+		return exprNode{typeBool, func(*ExprEnv) any { return false }}, nil
+	case t.kind == tokOp && t.text == "(":
+		node, err := p.parseOr()
+		if err != nil {
+			return exprNode{}, err
+		}
+		if err := p.expectOp(")"); err != nil {
+			return exprNode{}, err
+		}
+		return node, nil
+	case t.kind == tokOp && t.text == "[":
+		return p.parseList(t)
+	default:
+		return exprNode{}, exprErrorf(t.pos, "unexpected %s", t)
+	}
+}
+
+// parseList parses a list literal. Elements must all be strings or all ints.
+func (p *exprParser) parseList(open token) (exprNode, error) {
+	args, err := p.parseArgs("]")
+	if err != nil {
+		return exprNode{}, err
+	}
+	if len(args) == 0 {
+		return exprNode{typeEmptyList, func(*ExprEnv) any { return nil }}, nil
+	}
+	elem := args[0].typ
+	if elem != typeString && elem != typeInt {
+		return exprNode{}, exprErrorf(open.pos, "list elements must be strings or ints, got %s", elem)
+	}
+	for _, a := range args[1:] {
+		if a.typ != elem {
+			return exprNode{}, exprErrorf(a.pos, "list mixes %s and %s", elem, a.typ)
+		}
+	}
+	if elem == typeInt {
+		return exprNode{typeIntList, func(e *ExprEnv) any {
+			list := make([]int64, len(args))
+			for i, a := range args {
+				list[i] = a.eval(e).(int64)
+			}
+			return list
+		}}, nil
+	}
+	return exprNode{typeStringList, func(e *ExprEnv) any {
+		list := make([]string, len(args))
+		for i, a := range args {
+			list[i] = a.eval(e).(string)
+		}
+		return list
+	}}, nil
+}
@@ -0,0 +1,182 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func exprTestEnv() ExprEnv {
+	return ExprEnv{
+		Notification: Notification{
+			ID:         "1",
+			Reason:     "review_requested",
+			Subject:    Subject{Title: "chore: bump deps", URL: "https://api.github.com/repos/org/repo/pulls/42", Type: "PullRequest"},
+			Repository: Repository{FullName: "org/repo", Owner: "org"},
+		},
+		Facts: Facts{
+			Reviewers: &Reviewers{Users: []string{"alice"}, Teams: []string{"platform-team", "infra"}},
+			Files:     []string{"docs/setup.md", "docs/img/flow.png"},
+			PR:        &PullRequest{Author: "dependabot[bot]", Draft: true, State: "open", Labels: []string{"dependencies"}, Body: "Bumps deps. cc @me", Assignees: []string{"me"}, Head: "renovate/npm/react", Base: "release/2.1"},
+		},
+		Login: "me",
+	}
+}
+
+func TestExprEval(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`notification.reason == "review_requested" && "platform-team" in reviewers.teams && pr.draft`, true},
+		{`notification.reason == "mention"`, false},
+		{`notification.reason != "mention"`, true},
+		{`'infra' in reviewers.teams`, true},
+		{`"frontend" in reviewers.teams`, false},
+		{`login in reviewers.users`, false},
+		{`!(login in reviewers.users)`, true},
+		{`notification.org in ["org", "other"]`, true},
+		{`notification.org in []`, false},
+		{`notification.repo == "org/repo" || false`, true},
+		{`false || true && false`, false},
+		{`(false || true) && true`, true},
+		{`notification.title.startsWith("chore")`, true},
+		{`notification.title.endsWith("deps")`, true},
+		{`notification.title.contains("bump")`, true},
+		{`notification.title.matches("^(chore|deps):")`, true},
+		{`pr.author.matches("\\[bot\\]$")`, true},
+		{`"dependencies" in pr.labels`, true},
+		{`pr.body.contains("@me")`, true},
+		{`login in pr.assignees`, true},
+		{`pr.head.glob("renovate/**")`, true},
+		{`pr.base.glob("release/*")`, true},
+		{`pr.head.glob("renovate/*")`, false},
+		{`pr.base == "main"`, false},
+		{`pr.files.allGlob("docs/**")`, true},
+		{`pr.files.anyGlob("**/*.png")`, true},
+		{`pr.files.anyGlob("services/auth/**")`, false},
+		{`pr.files.allGlob("**/*.md")`, false},
+		{`pr.state == "open" && !pr.draft`, false},
+		{`size(reviewers.teams) > 1`, true},
+		{`size(reviewers.teams) >= 3`, false},
+		{`size(notification.title) < 100`, true},
+		{`size(reviewers.users) == 1`, true},
+		{`-1 < 0`, true},
+		{`2 in [1, 2, 3]`, true},
+		{`"b" <= "a"`, false},
+		{`true == !false`, true},
+	}
+	env := exprTestEnv()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := ParseExpr(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseExpr() error = %v", err)
+			}
+			if got := e.Eval(env); got != tt.want {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExprEvalMissingFacts(t *testing.T) {
+	env := exprTestEnv()
+	env.Facts = Facts{}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`pr.draft`, false},
+		{`pr.author == ""`, true},
+		{`size(pr.labels) == 0`, true},
+		{`pr.files.allGlob("docs/**")`, false},
+		{`"infra" in reviewers.teams`, false},
+		{`size(reviewers.users) == 0`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := ParseExpr(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseExpr() error = %v", err)
+			}
+			if got := e.Eval(env); got != tt.want {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExprErrors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantCol int
+		wantMsg string
+	}{
+		{`pr.drafty`, 1, `unknown field "pr.drafty"`},
+		{`notification.reason`, 1, "must be bool"},
+		{`pr.draft == "yes"`, 10, "cannot compare bool == string"},
+		{`pr.draft && "x"`, 10, "&& needs bool operands"},
+		{`1 in reviewers.teams`, 3, "cannot test int in list(string)"},
+		{`"a" in "abc"`, 5, "cannot test string in string"},
+		{`reviewers.teams == []`, 17, "cannot compare"},
+		{`!notification.title`, 1, "! needs a bool operand"},
+		{`notification.title.matches(login)`, 20, "needs a string literal pattern"},
+		{`notification.title.matches("(")`, 28, "invalid regex"},
+		{`pr.head.glob(login)`, 9, "needs a string literal pattern"},
+		{`pr.head.glob("[a")`, 14, "invalid glob"},
+		{`pr.head.anyGlob("x")`, 9, "only defined on list(string)"},
+		{`pr.files.allGlob(login)`, 10, "needs a string literal pattern"},
+		{`pr.files.glob("x")`, 10, "only defined on strings"},
+		{`notification.title.shout()`, 20, "unknown method shout()"},
+		{`pr.draft.contains("x")`, 10, "only defined on strings"},
+		{`len(reviewers.teams) > 0`, 1, "unknown function len()"},
+		{`["a", 1] == []`, 7, "list mixes string and int"},
+		{`"unterminated`, 1, "unterminated string"},
+		{`pr.draft &`, 10, "unexpected character"},
+		{`pr.draft pr.draft`, 10, "unexpected"},
+		{`(pr.draft`, 10, `expected ")"`},
+		{``, 1, "unexpected end of expression"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseExpr(tt.expr)
+			if err == nil {
+				t.Fatal("ParseExpr() want error")
+			}
+			var exprErr *ExprError
+			if !errors.As(err, &exprErr) {
+				t.Fatalf("error %v is not an *ExprError", err)
+			}
+			if exprErr.Col != tt.wantCol {
+				t.Errorf("Col = %d, want %d (%v)", exprErr.Col, tt.wantCol, err)
+			}
+			if !strings.Contains(exprErr.Msg, tt.wantMsg) {
+				t.Errorf("Msg = %q, want substring %q", exprErr.Msg, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestExprUsesField(t *testing.T) {
+	e, err := ParseExpr(`"infra" in reviewers.teams && pr.draft`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"pr.", true},
+		{"pr.draft", true},
+		{"pr.author", false},
+		{"reviewers.", true},
+		{"notification.", false},
+		{"login", false},
+	}
+	for _, tt := range tests {
+		if got := e.UsesField(tt.name); got != tt.want {
+			t.Errorf("UsesField(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package core
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Feedback records the decisions a user corrects, so that recurring mistakes
+// can be turned into suggested rules.
+
+// MinSuggestionCorrections is how many corrections must share a team or repo
+// before a rule is suggested for it.
+const MinSuggestionCorrections = 2
+
+// Correction is a decision the user says was wrong: mutemath did Was, and
+// should have done ShouldBe.
+type Correction struct {
+	Time     time.Time
+	Host     string // empty unless processing several hosts
+	ThreadID string
+	Label    string // e.g. "org/repo#42"
+	Title    string
+	Repo     string   // "org/repo"
+	Teams    []string // requested team slugs, when reviewer data was available
+	Was      Action
+	ShouldBe Action
+}
+
+// NewCorrection builds a correction from the journal's latest record of the
+// thread. Only keep and mute can be corrected, and only to the other one.
+func NewCorrection(r MutationRecord, shouldBe Action, now time.Time) (Correction, error) {
+	if shouldBe != ActionKeep && shouldBe != ActionMute {
+		return Correction{}, fmt.Errorf("can only correct to keep or mute, not %s", strings.ToLower(shouldBe.String()))
+	}
+	was := ActionMute
+	if r.Kept || r.Undo {
+		was = ActionKeep
+	}
+	if was == shouldBe {
+		verb := "kept"
+		if was == ActionMute {
+			verb = "muted"
+		}
+		return Correction{}, fmt.Errorf("%s was already %s", r.Label, verb)
+	}
+	return Correction{
+		Time:     now,
+		Host:     r.Host,
+		ThreadID: r.ThreadID,
+		Label:    r.Label,
+		Title:    r.Title,
+		Repo:     labelRepo(r.Label, r.Host),
+		Teams:    r.Teams,
+		Was:      was,
+		ShouldBe: shouldBe,
+	}, nil
+}
+
+// labelRepo extracts "org/repo" from a label like "host/org/repo#42".
+func labelRepo(label, host string) string {
+	repo, _, _ := strings.Cut(label, "#")
+	if host != "" {
+		repo = strings.TrimPrefix(repo, host+"/")
+	}
+	return repo
+}
+
+// LatestRecord finds the journal's latest record of a thread, on host when
+// it's set, or on any host.
+func LatestRecord(records []MutationRecord, host, threadID string) (MutationRecord, error) {
+	for i := len(records) - 1; i >= 0; i-- {
+		r := records[i]
+		if r.ThreadID == threadID && (host == "" || strings.EqualFold(r.Host, host)) {
+			return r, nil
+		}
+	}
+	return MutationRecord{}, errors.New("thread " + threadID + " isn't in the journal; only muted and kept review requests are recorded")
+}
+
+// Suggestion is a rule that would have avoided Count corrections.
+type Suggestion struct {
+	Action  Action // what the rule does
+	Team    string // the rule's team, or empty for a repo rule
+	Repo    string
+	Count   int
+	Against int // corrections the other way that the rule would break
+}
+
+// Rule renders the suggestion as a config file rule.
+func (s Suggestion) Rule() string {
+	action := strings.ToLower(s.Action.String())
+	if s.Team != "" {
+		return fmt.Sprintf(`{ "name": "%s %s", "teams": [%q], "action": %q }`, action, s.Team, s.Team, action)
+	}
+	return fmt.Sprintf(`{ "name": "%s %s", "when": "notification.repo == \"%s\"", "action": %q }`, action, s.Repo, s.Repo, action)
+}
+
+// SuggestRules groups corrections by requested team and by repo, and
+// suggests a rule for every group of at least MinSuggestionCorrections that
+// wants the same action, most corrections first. Only each thread's latest
+// correction counts.
+func SuggestRules(corrections []Correction) []Suggestion {
+	latest := make(map[string]Correction)
+	for _, c := range corrections {
+		latest[c.Host+"\x00"+c.ThreadID] = c
+	}
+	type key struct {
+		action     Action
+		team, repo string
+	}
+	counts := make(map[key]int)
+	for _, c := range latest {
+		for _, t := range c.Teams {
+			counts[key{c.ShouldBe, strings.ToLower(t), ""}]++
+		}
+		counts[key{c.ShouldBe, "", strings.ToLower(c.Repo)}]++
+	}
+	var out []Suggestion
+	for k, n := range counts {
+		if n < MinSuggestionCorrections {
+			continue
+		}
+		other := ActionKeep
+		if k.action == ActionKeep {
+			other = ActionMute
+		}
+		out = append(out, Suggestion{Action: k.action, Team: k.team, Repo: k.repo, Count: n, Against: counts[key{other, k.team, k.repo}]})
+	}
+	slices.SortFunc(out, func(a, b Suggestion) int {
+		return cmp.Or(b.Count-a.Count, cmp.Compare(a.Action, b.Action), cmp.Compare(a.Team, b.Team), cmp.Compare(a.Repo, b.Repo))
+	})
+	return out
+}
+
+// FormatSuggestions renders suggested rules, one with its reasoning per
+// paragraph.
+func FormatSuggestions(suggestions []Suggestion, corrections int) string {
+	if len(suggestions) == 0 {
+		return fmt.Sprintf("No suggestions yet from %d corrections; a rule is suggested once %d corrections share a team or repo.\n", corrections, MinSuggestionCorrections)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Suggested rules from %d corrections:\n", corrections)
+	for _, s := range suggestions {
+		what := "repo " + s.Repo
+		if s.Team != "" {
+			what = "team " + s.Team
+		}
+		fmt.Fprintf(&b, "\n%s %s — %d corrections involved it", strings.ToLower(s.Action.String()), what, s.Count)
+		if s.Against > 0 {
+			fmt.Fprintf(&b, ", though %d went the other way", s.Against)
+		}
+		fmt.Fprintf(&b, "\n  %s\n", s.Rule())
+	}
+	return b.String()
+}
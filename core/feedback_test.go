@@ -0,0 +1,114 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewCorrection(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	muted := MutationRecord{ThreadID: "1", Host: "ghes.example.com", Label: "ghes.example.com/org/api#42", Title: "Fix", Teams: []string{"backend"}}
+	tests := []struct {
+		name     string
+		r        MutationRecord
+		shouldBe Action
+		want     Correction
+		wantErr  string
+	}{
+		{
+			name:     "muted, should have been kept",
+			r:        muted,
+			shouldBe: ActionKeep,
+			want:     Correction{Time: now, Host: "ghes.example.com", ThreadID: "1", Label: "ghes.example.com/org/api#42", Title: "Fix", Repo: "org/api", Teams: []string{"backend"}, Was: ActionMute, ShouldBe: ActionKeep},
+		},
+		{
+			name:     "kept, should have been muted",
+			r:        MutationRecord{ThreadID: "2", Label: "org/web#7", Kept: true},
+			shouldBe: ActionMute,
+			want:     Correction{Time: now, ThreadID: "2", Label: "org/web#7", Repo: "org/web", Was: ActionKeep, ShouldBe: ActionMute},
+		},
+		{name: "already muted", r: muted, shouldBe: ActionMute, wantErr: "ghes.example.com/org/api#42 was already muted"},
+		{name: "undone mute counts as kept", r: MutationRecord{Label: "org/web#7", Undo: true}, shouldBe: ActionKeep, wantErr: "org/web#7 was already kept"},
+		{name: "defer", r: muted, shouldBe: ActionDefer, wantErr: "can only correct to keep or mute, not defer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewCorrection(tt.r, tt.shouldBe, now)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("NewCorrection() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewCorrection() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatestRecord(t *testing.T) {
+	records := []MutationRecord{
+		{ThreadID: "1", Title: "first"},
+		{ThreadID: "1", Host: "ghes.example.com", Title: "other host"},
+		{ThreadID: "2"},
+		{ThreadID: "1", Title: "latest", Undo: true},
+	}
+	if r, err := LatestRecord(records, "", "1"); err != nil || r.Title != "latest" {
+		t.Errorf("LatestRecord(any host) = %+v, %v", r, err)
+	}
+	if r, err := LatestRecord(records, "GHES.example.com", "1"); err != nil || r.Title != "other host" {
+		t.Errorf("LatestRecord(host) = %+v, %v", r, err)
+	}
+	if _, err := LatestRecord(records, "", "3"); err == nil {
+		t.Error("LatestRecord(missing) succeeded")
+	}
+}
+
+func TestSuggestRules(t *testing.T) {
+	keep := func(id, repo string, teams ...string) Correction {
+		return Correction{ThreadID: id, Repo: repo, Teams: teams, Was: ActionMute, ShouldBe: ActionKeep}
+	}
+	mute := func(id, repo string, teams ...string) Correction {
+		return Correction{ThreadID: id, Repo: repo, Teams: teams, Was: ActionKeep, ShouldBe: ActionMute}
+	}
+	corrections := []Correction{
+		keep("1", "org/api", "Backend"),
+		keep("2", "org/api", "backend", "infra"),
+		keep("3", "org/web", "backend"),
+		mute("4", "org/web", "backend"),
+		mute("5", "org/docs"),
+		keep("5", "org/docs"), // corrected again: only the latest counts
+		mute("6", "org/docs"),
+	}
+	got := SuggestRules(corrections)
+	want := []Suggestion{
+		{Action: ActionKeep, Team: "backend", Count: 3, Against: 1},
+		{Action: ActionKeep, Repo: "org/api", Count: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SuggestRules() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestFormatSuggestions(t *testing.T) {
+	got := FormatSuggestions([]Suggestion{
+		{Action: ActionKeep, Team: "backend", Count: 4, Against: 1},
+		{Action: ActionMute, Repo: "org/docs", Count: 2},
+	}, 7)
+	want := "Suggested rules from 7 corrections:\n" +
+		"\nkeep team backend — 4 corrections involved it, though 1 went the other way\n" +
+		`  { "name": "keep backend", "teams": ["backend"], "action": "keep" }` + "\n" +
+		"\nmute repo org/docs — 2 corrections involved it\n" +
+		`  { "name": "mute org/docs", "when": "notification.repo == \"org/docs\"", "action": "mute" }` + "\n"
+	if got != want {
+		t.Errorf("FormatSuggestions() =\n%s\nwant\n%s", got, want)
+	}
+	if got := FormatSuggestions(nil, 1); got != "No suggestions yet from 1 corrections; a rule is suggested once 2 corrections share a team or repo.\n" {
+		t.Errorf("FormatSuggestions(nil) = %q", got)
+	}
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// mutemath can run as a gh CLI extension: gh runs an executable named
+// gh-<name> for `gh <name>`, passing its own environment through.
+
+// ExtensionName is the executable name gh runs for `gh mutemath`.
+const ExtensionName = "gh-mutemath"
+
+// IsExtension reports whether the executable at path is installed as a gh
+// extension.
+func IsExtension(path string) bool {
+	name := strings.TrimSuffix(filepath.Base(path), ".exe")
+	return strings.EqualFold(name, ExtensionName)
+}
+
+// ProgramName returns how the user invokes mutemath, for usage and hint text:
+// "gh mutemath" when running as a gh extension, "mutemath" otherwise.
+func ProgramName(path string) string {
+	if IsExtension(path) {
+		return "gh mutemath"
+	}
+	return "mutemath"
+}
+
+// TokenEnvVars returns the environment variables a host's token is read from,
+// in order. GH_TOKEN comes first for every host; after it, the variables gh
+// itself uses: GITHUB_TOKEN for github.com, and GH_ENTERPRISE_TOKEN or
+// GITHUB_ENTERPRISE_TOKEN for GitHub Enterprise Server.
+func TokenEnvVars(host string) []string {
+	if host == "" || strings.EqualFold(host, "github.com") {
+		return []string{"GH_TOKEN", "GITHUB_TOKEN"}
+	}
+	return []string{"GH_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+}
+
+// GHHostname returns the hostname to ask gh for a token: GH_HOST's value, or
+// github.com when it's unset.
+func GHHostname(host string) string {
+	if host == "" {
+		return "github.com"
+	}
+	return host
+}
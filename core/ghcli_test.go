@@ -0,0 +1,49 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestProgramName(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/usr/local/bin/mutemath", "mutemath"},
+		{"mutemath", "mutemath"},
+		{"/home/me/.local/share/gh/extensions/gh-mutemath/gh-mutemath", "gh mutemath"},
+		{"gh-mutemath.exe", "gh mutemath"},
+		{"gh-other", "mutemath"},
+	}
+	for _, tt := range tests {
+		if got := ProgramName(tt.path); got != tt.want {
+			t.Errorf("ProgramName(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestTokenEnvVars(t *testing.T) {
+	tests := []struct {
+		host string
+		want []string
+	}{
+		{"", []string{"GH_TOKEN", "GITHUB_TOKEN"}},
+		{"GitHub.com", []string{"GH_TOKEN", "GITHUB_TOKEN"}},
+		{"ghes.example.com", []string{"GH_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}},
+	}
+	for _, tt := range tests {
+		if got := TokenEnvVars(tt.host); !slices.Equal(got, tt.want) {
+			t.Errorf("TokenEnvVars(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestGHHostname(t *testing.T) {
+	if got := GHHostname(""); got != "github.com" {
+		t.Errorf(`GHHostname("") = %q, want github.com`, got)
+	}
+	if got := GHHostname("ghes.example.com"); got != "ghes.example.com" {
+		t.Errorf("GHHostname(ghes.example.com) = %q", got)
+	}
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchGlob reports whether a slash-separated name, such as a branch or file
+// path, matches a glob pattern. Each segment is matched with path.Match, so *
+// doesn't cross a slash; a ** segment matches any number of segments,
+// including none: "docs/**" matches everything under docs/.
+func MatchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := len(name); i >= 0; i-- {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// CheckGlob reports whether a glob pattern is well-formed.
+func CheckGlob(pattern string) error {
+	_, err := path.Match(pattern, "")
+	return err
+}
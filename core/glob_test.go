@@ -0,0 +1,42 @@
+package core
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"release/*", "release/1.2", true},
+		{"release/*", "release/1.2/rc1", false},
+		{"release/*", "release", false},
+		{"renovate/**", "renovate/npm/react", true},
+		{"renovate/**", "renovate", true},
+		{"hotfix-*", "hotfix-login", true},
+		{"main", "main", true},
+		{"main", "maintenance", false},
+		{"services/auth/**", "services/auth/token.go", true},
+		{"services/auth/**", "services/authz/token.go", false},
+		{"**/*.md", "README.md", true},
+		{"**/*.md", "docs/guide/setup.md", true},
+		{"**/*.md", "docs/guide/setup.go", false},
+		{"docs/**/*.png", "docs/img/a.png", true},
+		{"docs/**/*.png", "docs/a.png", true},
+		{"v[0-9]*", "v2", true},
+	}
+	for _, tt := range tests {
+		if got := MatchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckGlob(t *testing.T) {
+	if err := CheckGlob("release/*"); err != nil {
+		t.Errorf("CheckGlob(release/*) = %v, want nil", err)
+	}
+	if err := CheckGlob("release/[a"); err == nil {
+		t.Error("CheckGlob(release/[a) = nil, want error")
+	}
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DefaultAPIVersion is the X-GitHub-Api-Version sent unless overridden.
+const DefaultAPIVersion = "2022-11-28"
+
+// DefaultUserAgent is the User-Agent sent unless overridden, e.g. "mutemath/v1.2.3".
+func DefaultUserAgent(version string) string {
+	return "mutemath/" + version
+}
+
+// CheckUserAgent validates a User-Agent override: non-empty, and with no
+// control characters, which could split the header.
+func CheckUserAgent(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return errors.New("empty")
+	}
+	if strings.ContainsFunc(s, unicode.IsControl) {
+		return fmt.Errorf("%q contains control characters", s)
+	}
+	return nil
+}
+
+// CheckAPIVersion validates an X-GitHub-Api-Version override, a date like
+// 2022-11-28. Whether the server supports it is only known once it answers.
+func CheckAPIVersion(s string) error {
+	if _, err := time.Parse(time.DateOnly, s); err != nil {
+		return fmt.Errorf("%q isn't a date like %s", s, DefaultAPIVersion)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package core
+
+import "testing"
+
+func TestCheckUserAgent(t *testing.T) {
+	tests := []struct {
+		ua      string
+		wantErr bool
+	}{
+		{ua: "mutemath/v1.2.3"},
+		{ua: "acme-audit mutemath (team platform)"},
+		{ua: "", wantErr: true},
+		{ua: "  ", wantErr: true},
+		{ua: "mutemath\r\nX-Injected: 1", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := CheckUserAgent(tt.ua); (err != nil) != tt.wantErr {
+			t.Errorf("CheckUserAgent(%q) error = %v, wantErr %v", tt.ua, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckAPIVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		wantErr bool
+	}{
+		{version: DefaultAPIVersion},
+		{version: "2026-03-10"},
+		{version: "", wantErr: true},
+		{version: "v3", wantErr: true},
+		{version: "2022-13-01", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := CheckAPIVersion(tt.version); (err != nil) != tt.wantErr {
+			t.Errorf("CheckAPIVersion(%q) error = %v, wantErr %v", tt.version, err, tt.wantErr)
+		}
+	}
+}
+
+func TestDefaultUserAgent(t *testing.T) {
+	if got := DefaultUserAgent("v1.2.3"); got != "mutemath/v1.2.3" {
+		t.Errorf("DefaultUserAgent() = %q", got)
+	}
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HeatmapWeekdays names a Heatmap's rows, Monday first.
+var HeatmapWeekdays = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// Heatmap counts when a group's notifications arrived, by day of the week
+// (Monday first) and hour of the day.
+type Heatmap struct {
+	Group  string // the org or repo; "all" without a group
+	Counts [7][24]int
+}
+
+// Total is the number of notifications counted.
+func (h Heatmap) Total() int {
+	n := 0
+	for _, day := range h.Counts {
+		for _, c := range day {
+			n += c
+		}
+	}
+	return n
+}
+
+// BuildHeatmaps buckets the journal's mutes and kept review requests since
+// since by when they were handled, in loc, as a stand-in for when they
+// arrived. With a group, there's one heatmap per org or repo, sorted by
+// name. Undos aren't counted.
+func BuildHeatmaps(records []MutationRecord, by CountGroup, loc *time.Location, since time.Time) []Heatmap {
+	index := make(map[string]int)
+	var maps []Heatmap
+	for _, r := range records {
+		if r.Undo || r.Time.Before(since) {
+			continue
+		}
+		name := "all"
+		switch by {
+		case GroupOrg:
+			name, _, _ = strings.Cut(recordRepo(r), "/")
+		case GroupRepo:
+			name = recordRepo(r)
+		}
+		i, ok := index[strings.ToLower(name)]
+		if !ok {
+			i = len(maps)
+			index[strings.ToLower(name)] = i
+			maps = append(maps, Heatmap{Group: name})
+		}
+		t := r.Time.In(loc)
+		maps[i].Counts[(t.Weekday()+6)%7][t.Hour()]++
+	}
+	slices.SortFunc(maps, func(a, b Heatmap) int {
+		return cmp.Compare(strings.ToLower(a.Group), strings.ToLower(b.Group))
+	})
+	return maps
+}
+
+// recordRepo is the "org/repo" a journal record is about, from its label.
+func recordRepo(r MutationRecord) string {
+	label := r.Label
+	if r.Host != "" {
+		label = strings.TrimPrefix(label, r.Host+"/")
+	}
+	repo, _, _ := strings.Cut(label, "#")
+	return repo
+}
+
+// HeatmapCSV renders heatmaps as CSV records, a header and then one row per
+// group, weekday, and hour, zeros included, for plotting.
+func HeatmapCSV(maps []Heatmap) [][]string {
+	rows := [][]string{{"group", "weekday", "hour", "count"}}
+	for _, m := range maps {
+		for d, day := range m.Counts {
+			for h, c := range day {
+				rows = append(rows, []string{m.Group, HeatmapWeekdays[d], strconv.Itoa(h), strconv.Itoa(c)})
+			}
+		}
+	}
+	return rows
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildHeatmaps(t *testing.T) {
+	// 2026-03-16 is a Monday.
+	at := func(day, hour int) time.Time { return time.Date(2026, 3, day, hour, 30, 0, 0, time.UTC) }
+	records := []MutationRecord{
+		{Time: at(16, 9), Label: "acme/api#1"},
+		{Time: at(16, 9), Label: "Acme/web#2", Kept: true},
+		{Time: at(22, 23), Label: "oss/lib#3"},
+		{Time: at(17, 10), Label: "ghes.example.com/acme/api#4", Host: "ghes.example.com"},
+		{Time: at(17, 11), Label: "acme/api#1", Undo: true}, // undos aren't counted
+		{Time: at(2, 9), Label: "acme/api#5"},               // before since
+	}
+	since := at(9, 0)
+
+	tests := []struct {
+		name  string
+		by    CountGroup
+		loc   *time.Location
+		check func(t *testing.T, maps []Heatmap)
+	}{
+		{"all", GroupNone, time.UTC, func(t *testing.T, maps []Heatmap) {
+			if len(maps) != 1 || maps[0].Group != "all" || maps[0].Total() != 4 {
+				t.Fatalf("got %+v, want one \"all\" heatmap of 4", maps)
+			}
+			if c := maps[0].Counts[0][9]; c != 2 {
+				t.Errorf("mon 9h = %d, want 2", c)
+			}
+			if c := maps[0].Counts[6][23]; c != 1 {
+				t.Errorf("sun 23h = %d, want 1", c)
+			}
+		}},
+		{"org", GroupOrg, time.UTC, func(t *testing.T, maps []Heatmap) {
+			if len(maps) != 2 || maps[0].Group != "acme" || maps[1].Group != "oss" {
+				t.Fatalf("got %+v, want acme and oss", maps)
+			}
+			if maps[0].Total() != 3 || maps[0].Counts[1][10] != 1 {
+				t.Errorf("acme = %+v, want 3 with one on tue 10h", maps[0])
+			}
+		}},
+		{"repo", GroupRepo, time.UTC, func(t *testing.T, maps []Heatmap) {
+			var groups []string
+			for _, m := range maps {
+				groups = append(groups, m.Group)
+			}
+			if len(groups) != 3 || groups[0] != "acme/api" || groups[1] != "Acme/web" || groups[2] != "oss/lib" {
+				t.Fatalf("groups = %v, want acme/api, Acme/web, oss/lib", groups)
+			}
+			if maps[0].Total() != 2 {
+				t.Errorf("acme/api total = %d, want 2", maps[0].Total())
+			}
+		}},
+		{"time zone", GroupNone, time.FixedZone("UTC+2", 2*60*60), func(t *testing.T, maps []Heatmap) {
+			// Sunday 23:30 UTC is Monday 01:30 two hours east.
+			if c := maps[0].Counts[0][1]; c != 1 {
+				t.Errorf("mon 1h = %d, want 1", c)
+			}
+			if c := maps[0].Counts[0][11]; c != 2 {
+				t.Errorf("mon 11h = %d, want 2", c)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, BuildHeatmaps(records, tt.by, tt.loc, since))
+		})
+	}
+}
+
+func TestHeatmapCSV(t *testing.T) {
+	var m Heatmap
+	m.Group = "acme"
+	m.Counts[2][14] = 3
+	rows := HeatmapCSV([]Heatmap{m, {Group: "oss"}})
+	if len(rows) != 1+2*7*24 {
+		t.Fatalf("got %d rows, want a header and 168 per group", len(rows))
+	}
+	if got := rows[0]; len(got) != 4 || got[0] != "group" || got[3] != "count" {
+		t.Errorf("header = %v", got)
+	}
+	if got := rows[1+2*24+14]; got[0] != "acme" || got[1] != "wed" || got[2] != "14" || got[3] != "3" {
+		t.Errorf("wed 14h row = %v, want [acme wed 14 3]", got)
+	}
+	if got := rows[1+7*24]; got[0] != "oss" || got[1] != "mon" || got[2] != "0" || got[3] != "0" {
+		t.Errorf("first oss row = %v, want [oss mon 0 0]", got)
+	}
+}
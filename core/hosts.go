@@ -0,0 +1,50 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HostSpec is a GitHub host to process, from the config file's hosts list.
+// The token is read from the environment variable TokenEnv, so it never has
+// to live in the config file.
+type HostSpec struct {
+	Host     string // e.g. "github.com" or "ghes.example.com"
+	TokenEnv string // e.g. "GHES_TOKEN"
+}
+
+// HostError is a problem with one entry in the hosts list.
+type HostError struct {
+	Index int // 0-based position in the hosts list
+	Field string
+	Err   error
+}
+
+func (e *HostError) Error() string {
+	return fmt.Sprintf("hosts[%d]: %s: %s", e.Index, e.Field, e.Err)
+}
+
+// CheckHosts validates a hosts list, returning every problem found: each host
+// needs a name and a token variable, and no host may be listed twice.
+func CheckHosts(hosts []HostSpec) []*HostError {
+	var errs []*HostError
+	seen := make(map[string]int)
+	for i, h := range hosts {
+		name := strings.ToLower(strings.TrimSpace(h.Host))
+		switch {
+		case name == "":
+			errs = append(errs, &HostError{Index: i, Field: "host", Err: fmt.Errorf("empty host")})
+		case strings.Contains(name, "/"):
+			errs = append(errs, &HostError{Index: i, Field: "host", Err: fmt.Errorf("%q should be a bare hostname, e.g. ghes.example.com", h.Host)})
+		default:
+			if prev, dup := seen[name]; dup {
+				errs = append(errs, &HostError{Index: i, Field: "host", Err: fmt.Errorf("%s is already listed at hosts[%d]", name, prev)})
+			}
+			seen[name] = i
+		}
+		if strings.TrimSpace(h.TokenEnv) == "" {
+			errs = append(errs, &HostError{Index: i, Field: "token_env", Err: fmt.Errorf("needs the name of the environment variable holding the host's token")})
+		}
+	}
+	return errs
+}
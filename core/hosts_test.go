@@ -0,0 +1,45 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCheckHosts(t *testing.T) {
+	tests := []struct {
+		name  string
+		hosts []HostSpec
+		want  []string
+	}{
+		{name: "valid", hosts: []HostSpec{{Host: "github.com", TokenEnv: "GH_TOKEN"}, {Host: "ghes.example.com", TokenEnv: "GHES_TOKEN"}}},
+		{name: "empty host", hosts: []HostSpec{{TokenEnv: "GH_TOKEN"}}, want: []string{"hosts[0]: host: empty host"}},
+		{name: "url", hosts: []HostSpec{{Host: "https://ghes.example.com", TokenEnv: "T"}}, want: []string{`hosts[0]: host: "https://ghes.example.com" should be a bare hostname, e.g. ghes.example.com`}},
+		{name: "duplicate", hosts: []HostSpec{{Host: "github.com", TokenEnv: "A"}, {Host: "GitHub.com", TokenEnv: "B"}}, want: []string{"hosts[1]: host: github.com is already listed at hosts[0]"}},
+		{name: "no token env", hosts: []HostSpec{{Host: "github.com"}}, want: []string{"hosts[0]: token_env: needs the name of the environment variable holding the host's token"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range CheckHosts(tt.hosts) {
+				got = append(got, e.Error())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("CheckHosts() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatLabelQualifiesHost(t *testing.T) {
+	d := Decision{Notification: Notification{
+		Subject:    Subject{URL: "https://ghes.example.com/api/v3/repos/org/repo/pulls/7"},
+		Repository: Repository{FullName: "org/repo"},
+	}}
+	if got := formatLabel(d); got != "org/repo#7" {
+		t.Errorf("formatLabel() = %q, want org/repo#7", got)
+	}
+	d.Notification.Host = "ghes.example.com"
+	if got := formatLabel(d); got != "ghes.example.com/org/repo#7" {
+		t.Errorf("formatLabel() = %q, want ghes.example.com/org/repo#7", got)
+	}
+}
@@ -0,0 +1,169 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BusinessHoursSpec is business_hours as written in the config file.
+type BusinessHoursSpec struct {
+	Timezone   string   // IANA name, e.g. "Europe/Berlin"; empty for UTC
+	Start, End string   // "HH:MM", local time
+	Days       []string // e.g. ["mon", "tue"]; empty for Monday to Friday
+}
+
+// BusinessHours is a parsed BusinessHoursSpec: a daily window on some weekdays
+// in a timezone. Rules can check it with time.business_hours, and the defer
+// action holds a notification until the window next opens.
+type BusinessHours struct {
+	Location   *time.Location
+	Start, End time.Duration // since local midnight; End > Start
+	Days       [7]bool       // by time.Weekday
+}
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// ParseBusinessHours checks a spec. zone resolves its timezone, e.g.
+// time.LoadLocation.
+func ParseBusinessHours(spec BusinessHoursSpec, zone func(string) (*time.Location, error)) (BusinessHours, error) {
+	b := BusinessHours{Location: time.UTC}
+	if spec.Timezone != "" {
+		loc, err := zone(spec.Timezone)
+		if err != nil {
+			return BusinessHours{}, fmt.Errorf("business_hours: unknown timezone %q", spec.Timezone)
+		}
+		b.Location = loc
+	}
+	var err error
+	if b.Start, err = parseClock(spec.Start); err != nil {
+		return BusinessHours{}, fmt.Errorf("business_hours: start: %w", err)
+	}
+	if b.End, err = parseClock(spec.End); err != nil {
+		return BusinessHours{}, fmt.Errorf("business_hours: end: %w", err)
+	}
+	if b.End <= b.Start {
+		return BusinessHours{}, errors.New("business_hours: end must be after start")
+	}
+	days := spec.Days
+	if len(days) == 0 {
+		days = weekdayNames[1:6]
+	}
+	for _, d := range days {
+		i := indexFold(weekdayNames, strings.TrimSpace(d))
+		if i < 0 {
+			return BusinessHours{}, fmt.Errorf("business_hours: invalid day %q (valid values: %s)", d, strings.Join(weekdayNames, ", "))
+		}
+		b.Days[i] = true
+	}
+	return b, nil
+}
+
+func indexFold(list []string, s string) int {
+	for i, v := range list {
+		if strings.EqualFold(v, s) {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseClock parses "HH:MM" as a duration since midnight; "24:00" is the end
+// of the day.
+func parseClock(s string) (time.Duration, error) {
+	if s == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (want HH:MM)", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Contains reports whether t is within business hours.
+func (b BusinessHours) Contains(t time.Time) bool {
+	t = t.In(b.Location)
+	if !b.Days[t.Weekday()] {
+		return false
+	}
+	since := clockOf(t)
+	return since >= b.Start && since < b.End
+}
+
+// NextOpen returns when business hours next begin after t, or t itself if
+// they're open. With no days set, it's the zero time.
+func (b BusinessHours) NextOpen(t time.Time) time.Time {
+	if b.Contains(t) {
+		return t
+	}
+	local := t.In(b.Location)
+	day := midnight(local)
+	for range 8 {
+		if b.Days[day.Weekday()] {
+			// By wall clock, so it holds across DST changes.
+			open := time.Date(day.Year(), day.Month(), day.Day(), int(b.Start/time.Hour), int(b.Start%time.Hour/time.Minute), 0, 0, b.Location)
+			if open.After(local) {
+				return open
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
+
+// clockOf returns t's local wall-clock time since midnight.
+func clockOf(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// DeferredThread is a notification a defer rule held, to classify again once
+// business hours open.
+type DeferredThread struct {
+	Host     string
+	ThreadID string
+	Until    time.Time
+}
+
+// DeferQueue holds deferred notifications across daemon cycles.
+type DeferQueue struct {
+	Items []DeferredThread
+}
+
+// Add defers a thread until the given time, replacing any earlier deferral.
+func (q *DeferQueue) Add(t DeferredThread) {
+	q.Remove(t.Host, t.ThreadID)
+	q.Items = append(q.Items, t)
+}
+
+// Remove drops a thread, e.g. once it has been classified again.
+func (q *DeferQueue) Remove(host, threadID string) {
+	kept := q.Items[:0]
+	for _, t := range q.Items {
+		if t.Host != host || t.ThreadID != threadID {
+			kept = append(kept, t)
+		}
+	}
+	q.Items = kept
+}
+
+// TakeDue removes and returns the thread IDs on host deferred until now or
+// earlier, in the order they were deferred.
+func (q *DeferQueue) TakeDue(host string, now time.Time) []string {
+	var due []string
+	kept := q.Items[:0]
+	for _, t := range q.Items {
+		if t.Host == host && !t.Until.After(now) {
+			due = append(due, t.ThreadID)
+		} else {
+			kept = append(kept, t)
+		}
+	}
+	q.Items = kept
+	return due
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseBusinessHours(t *testing.T) {
+	berlin := time.FixedZone("CET", 3600)
+	zone := func(name string) (*time.Location, error) {
+		if name == "Europe/Berlin" {
+			return berlin, nil
+		}
+		return time.LoadLocation("Nowhere/Invalid")
+	}
+	b, err := ParseBusinessHours(BusinessHoursSpec{Timezone: "Europe/Berlin", Start: "09:00", End: "18:00"}, zone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := BusinessHours{Location: berlin, Start: 9 * time.Hour, End: 18 * time.Hour, Days: [7]bool{false, true, true, true, true, true, false}}
+	if b != want {
+		t.Errorf("ParseBusinessHours() = %+v, want %+v", b, want)
+	}
+
+	for _, spec := range []BusinessHoursSpec{
+		{Timezone: "Mars/Olympus", Start: "09:00", End: "18:00"},
+		{Start: "9am", End: "18:00"},
+		{Start: "18:00", End: "09:00"},
+		{Start: "09:00", End: "18:00", Days: []string{"monday"}},
+	} {
+		if _, err := ParseBusinessHours(spec, zone); err == nil {
+			t.Errorf("ParseBusinessHours(%+v) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestBusinessHours(t *testing.T) {
+	berlin := time.FixedZone("CET", 3600)
+	b := BusinessHours{Location: berlin, Start: 9 * time.Hour, End: 18 * time.Hour, Days: [7]bool{false, true, true, true, true, true, false}}
+	at := func(day, hour, min int) time.Time { return time.Date(2026, 3, day, hour, min, 0, 0, berlin) } // March 2 is a Monday
+
+	tests := []struct {
+		name     string
+		t        time.Time
+		open     bool
+		nextOpen time.Time
+	}{
+		{"monday morning", at(2, 9, 0), true, at(2, 9, 0)},
+		{"before opening", at(2, 8, 59), false, at(2, 9, 0)},
+		{"at closing", at(2, 18, 0), false, at(3, 9, 0)},
+		{"friday evening", at(6, 19, 30), false, at(9, 9, 0)},
+		{"sunday", at(8, 12, 0), false, at(9, 9, 0)},
+		{"utc input", time.Date(2026, 3, 2, 16, 30, 0, 0, time.UTC), true, time.Date(2026, 3, 2, 16, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.Contains(tt.t); got != tt.open {
+				t.Errorf("Contains() = %v, want %v", got, tt.open)
+			}
+			if got := b.NextOpen(tt.t); !got.Equal(tt.nextOpen) {
+				t.Errorf("NextOpen() = %v, want %v", got, tt.nextOpen)
+			}
+		})
+	}
+	if got := (BusinessHours{Location: berlin, Start: 9 * time.Hour, End: 18 * time.Hour}).NextOpen(at(2, 8, 0)); !got.IsZero() {
+		t.Errorf("NextOpen() with no days = %v, want zero", got)
+	}
+}
+
+func TestDecideDefer(t *testing.T) {
+	berlin := time.FixedZone("CET", 3600)
+	hours := &BusinessHours{Location: berlin, Start: 9 * time.Hour, End: 18 * time.Hour, Days: [7]bool{false, true, true, true, true, true, false}}
+	n := Notification{ID: "1", Reason: "review_requested", Subject: Subject{Type: "PullRequest"}, Repository: Repository{Owner: "org"}}
+	cfg := Config{
+		BusinessHours: hours,
+		Rules: mustParseRules(t,
+			RuleSpec{Name: "after hours", When: "!time.business_hours", Action: "defer"},
+			RuleSpec{Name: "late", When: `time.hour >= 20 || time.weekday in ["sat", "sun"]`, Action: "mute"},
+		),
+	}
+	reviewers := &Reviewers{Users: []string{"me"}}
+
+	evening := time.Date(2026, 3, 2, 19, 0, 0, 0, berlin)
+	d := Decide(n, Facts{Reviewers: reviewers, Now: evening}, "me", cfg)
+	if d.Action != ActionDefer || !d.Until.Equal(time.Date(2026, 3, 3, 9, 0, 0, 0, berlin)) {
+		t.Errorf("Decide() in the evening = %s until %v, want DEFER until Tuesday 09:00", d.Action, d.Until)
+	}
+
+	// In business hours the defer rule passes; later rules and the built-in
+	// classification decide.
+	d = Decide(n, Facts{Reviewers: reviewers, Now: time.Date(2026, 3, 3, 9, 0, 0, 0, berlin)}, "me", cfg)
+	if d.Action != ActionKeep || d.Reason != "direct review request" {
+		t.Errorf("Decide() in business hours = %s (%s), want KEEP (direct review request)", d.Action, d.Reason)
+	}
+
+	// Without business hours, defer rules never hold anything.
+	cfg.BusinessHours = nil
+	if d := Decide(n, Facts{Reviewers: reviewers, Now: time.Date(2026, 3, 7, 21, 0, 0, 0, berlin)}, "me", cfg); d.Action != ActionMute {
+		t.Errorf("Decide() without business hours = %s (%s), want MUTE from rule late", d.Action, d.Reason)
+	}
+}
+
+func TestDeferQueue(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	var q DeferQueue
+	q.Add(DeferredThread{ThreadID: "1", Until: now.Add(-time.Minute)})
+	q.Add(DeferredThread{ThreadID: "2", Until: now.Add(time.Hour)})
+	q.Add(DeferredThread{Host: "ghes.example.com", ThreadID: "3", Until: now})
+	q.Add(DeferredThread{ThreadID: "4", Until: now.Add(time.Hour)})
+	q.Add(DeferredThread{ThreadID: "4", Until: now}) // deferred again, replacing the first
+	q.Remove("", "2")
+
+	if got := q.TakeDue("", now); !slices.Equal(got, []string{"1", "4"}) {
+		t.Errorf("TakeDue() = %v, want [1 4]", got)
+	}
+	if got := q.TakeDue("", now); len(got) != 0 {
+		t.Errorf("TakeDue() again = %v, want none", got)
+	}
+	if got := q.TakeDue("ghes.example.com", now); !slices.Equal(got, []string{"3"}) {
+		t.Errorf("TakeDue(ghes) = %v, want [3]", got)
+	}
+}
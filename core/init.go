@@ -0,0 +1,86 @@
+package core
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// InitTeam is a team you're on, as mutemath init finds it.
+type InitTeam struct {
+	Org     string
+	Slug    string
+	Members int
+}
+
+// DefaultInitTeamSize is the largest team mutemath init proposes keeping: a
+// request through a team this small is usually meant for you.
+const DefaultInitTeamSize = 8
+
+// ProposeKeepTeams lists the teams in orgs, or in any org without orgs, for
+// mutemath init to offer, sorted by org then slug, and picks those it
+// proposes keeping: teams of at most maxSize members. A team whose size is
+// unknown (0) isn't proposed.
+func ProposeKeepTeams(teams []InitTeam, orgs []string, maxSize int) (offered []InitTeam, proposed []int) {
+	for _, t := range teams {
+		if len(orgs) == 0 || slices.ContainsFunc(orgs, func(o string) bool { return strings.EqualFold(o, t.Org) }) {
+			offered = append(offered, t)
+		}
+	}
+	slices.SortFunc(offered, func(a, b InitTeam) int {
+		return cmp.Or(cmp.Compare(strings.ToLower(a.Org), strings.ToLower(b.Org)), cmp.Compare(a.Slug, b.Slug))
+	})
+	for i, t := range offered {
+		if t.Members > 0 && t.Members <= maxSize {
+			proposed = append(proposed, i)
+		}
+	}
+	return offered, proposed
+}
+
+// ParseSelection reads an answer picking from n numbered choices: "all",
+// "none", or comma-separated numbers from 1. An empty answer picks def. The
+// result is the picked indexes from 0, in order.
+func ParseSelection(answer string, n int, def []int) ([]int, error) {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	switch answer {
+	case "":
+		return def, nil
+	case "none":
+		return []int{}, nil
+	case "all":
+		all := make([]int, n)
+		for i := range all {
+			all[i] = i
+		}
+		return all, nil
+	}
+	var picked []int
+	for _, f := range strings.Split(answer, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		i, err := strconv.Atoi(f)
+		if err != nil || i < 1 || i > n {
+			return nil, fmt.Errorf("%q isn't a number from 1 to %d", f, n)
+		}
+		if !slices.Contains(picked, i-1) {
+			picked = append(picked, i-1)
+		}
+	}
+	slices.Sort(picked)
+	return picked, nil
+}
+
+// OnlyOrgsWhen is a rule condition matching notifications from outside orgs,
+// for a rule that skips them like --include-org does.
+func OnlyOrgsWhen(orgs []string) string {
+	quoted := make([]string, len(orgs))
+	for i, o := range orgs {
+		quoted[i] = strconv.Quote(o)
+	}
+	return fmt.Sprintf("!(notification.org in [%s])", strings.Join(quoted, ", "))
+}
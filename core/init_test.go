@@ -0,0 +1,68 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProposeKeepTeams(t *testing.T) {
+	teams := []InitTeam{
+		{Org: "acme", Slug: "platform", Members: 40},
+		{Org: "oss", Slug: "maintainers", Members: 3},
+		{Org: "Acme", Slug: "backend", Members: 6},
+		{Org: "acme", Slug: "secret", Members: 0}, // size unknown
+	}
+	offered, proposed := ProposeKeepTeams(teams, []string{"acme"}, 8)
+	wantOffered := []InitTeam{teams[2], teams[0], teams[3]}
+	if !reflect.DeepEqual(offered, wantOffered) || !reflect.DeepEqual(proposed, []int{0}) {
+		t.Errorf("ProposeKeepTeams() = %+v, %v; want %+v, [0]", offered, proposed, wantOffered)
+	}
+
+	offered, proposed = ProposeKeepTeams(teams, nil, 8)
+	if len(offered) != 4 || !reflect.DeepEqual(proposed, []int{0, 3}) {
+		t.Errorf("with no orgs, ProposeKeepTeams() = %+v, %v; want all four, [0 3]", offered, proposed)
+	}
+}
+
+func TestParseSelection(t *testing.T) {
+	tests := []struct {
+		answer  string
+		want    []int
+		wantErr bool
+	}{
+		{answer: "", want: []int{1}},
+		{answer: "all", want: []int{0, 1, 2}},
+		{answer: " None ", want: []int{}},
+		{answer: "3, 1,3", want: []int{0, 2}},
+		{answer: "4", wantErr: true},
+		{answer: "0", wantErr: true},
+		{answer: "backend", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSelection(tt.answer, 3, []int{1})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSelection(%q) err = %v, wantErr %v", tt.answer, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseSelection(%q) = %v, want %v", tt.answer, got, tt.want)
+		}
+	}
+}
+
+func TestOnlyOrgsWhen(t *testing.T) {
+	when := OnlyOrgsWhen([]string{"acme", "oss"})
+	if want := `!(notification.org in ["acme", "oss"])`; when != want {
+		t.Fatalf("OnlyOrgsWhen() = %s, want %s", when, want)
+	}
+	e, err := ParseExpr(when)
+	if err != nil {
+		t.Fatalf("ParseExpr(%s): %v", when, err)
+	}
+	for org, want := range map[string]bool{"acme": false, "oss": false, "other": true} {
+		n := Notification{Repository: Repository{FullName: org + "/repo", Owner: org}}
+		if got := e.Eval(ExprEnv{Notification: n}); got != want {
+			t.Errorf("%s on %s = %v, want %v", when, org, got, want)
+		}
+	}
+}
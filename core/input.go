@@ -0,0 +1,34 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckInput validates notifications read with --input instead of listed:
+// each needs a thread ID, a repository, and a subject type. A missing
+// repository owner is taken from the repository's full name. Duplicate
+// threads, as overlapping exports produce, are dropped.
+func CheckInput(notifications []Notification) ([]Notification, error) {
+	seen := make(map[string]bool, len(notifications))
+	var out []Notification
+	for i, n := range notifications {
+		switch {
+		case n.ID == "":
+			return nil, fmt.Errorf("notification %d: missing id", i+1)
+		case n.Repository.FullName == "":
+			return nil, fmt.Errorf("notification %d (thread %s): missing repository.full_name", i+1, n.ID)
+		case n.Subject.Type == "":
+			return nil, fmt.Errorf("notification %d (thread %s): missing subject.type", i+1, n.ID)
+		}
+		if seen[n.ID] {
+			continue
+		}
+		seen[n.ID] = true
+		if n.Repository.Owner == "" {
+			n.Repository.Owner, _, _ = strings.Cut(n.Repository.FullName, "/")
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
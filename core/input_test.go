@@ -0,0 +1,54 @@
+package core
+
+import "testing"
+
+func TestCheckInput(t *testing.T) {
+	pr := func(id, repo string) Notification {
+		return Notification{ID: id, Subject: Subject{Type: "PullRequest"}, Repository: Repository{FullName: repo}}
+	}
+	tests := []struct {
+		name    string
+		in      []Notification
+		wantIDs []string
+		wantErr string
+	}{
+		{name: "empty"},
+		{name: "valid", in: []Notification{pr("1", "org/a"), pr("2", "org/b")}, wantIDs: []string{"1", "2"}},
+		{name: "duplicates dropped", in: []Notification{pr("1", "org/a"), pr("2", "org/b"), pr("1", "org/a")}, wantIDs: []string{"1", "2"}},
+		{name: "missing id", in: []Notification{pr("1", "org/a"), pr("", "org/b")}, wantErr: "notification 2: missing id"},
+		{name: "missing repo", in: []Notification{pr("7", "")}, wantErr: "notification 1 (thread 7): missing repository.full_name"},
+		{name: "missing type", in: []Notification{{ID: "7", Repository: Repository{FullName: "org/a"}}}, wantErr: "notification 1 (thread 7): missing subject.type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CheckInput(tt.in)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("CheckInput() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CheckInput() error = %v", err)
+			}
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("CheckInput() = %d notifications, want %d", len(got), len(tt.wantIDs))
+			}
+			for i, n := range got {
+				if n.ID != tt.wantIDs[i] {
+					t.Errorf("notification %d ID = %s, want %s", i, n.ID, tt.wantIDs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCheckInputOwner(t *testing.T) {
+	got, err := CheckInput([]Notification{{ID: "1", Subject: Subject{Type: "Issue"}, Repository: Repository{FullName: "acme/api"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Repository.Owner != "acme" {
+		t.Errorf("Owner = %q, want acme", got[0].Repository.Owner)
+	}
+}
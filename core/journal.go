@@ -0,0 +1,147 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The mutation journal records every mute mutemath makes, so recent mutes can
+// be found and undone, and the review requests it keeps, for review-load
+// reports.
+
+// JournalRetention is how long mutes stay in the journal once it's compacted.
+const JournalRetention = 30 * 24 * time.Hour
+
+// MutationRecord is a journal line: a thread that was muted, the undoing of
+// its latest mute, or a review request that was kept.
+type MutationRecord struct {
+	Time     time.Time
+	ThreadID string
+	Host     string // empty unless processing several hosts
+	Label    string // e.g. "org/repo#42", qualified by the host when set
+	Title    string
+	URL      string   // the subject's API URL; empty in records from before it was kept
+	Reason   string   // the notification's reason, e.g. "review_requested"
+	Teams    []string // requested team slugs, when reviewer data was available
+	Mode     Mode
+	Ignored  bool // the mute ignored the subscription, rather than finding it already ignored
+	Undo     bool
+	Escalate bool // with Undo, the mute was undone because the review SLA passed
+
+	Kept   bool // a kept review request, recorded for reports; not a mutation
+	Direct bool // with Kept, login was requested personally
+}
+
+// RecordMute builds the journal record for a completed mute.
+func RecordMute(d Decision, mode Mode, ignored bool, now time.Time) MutationRecord {
+	return MutationRecord{
+		Time:     now,
+		ThreadID: d.Notification.ID,
+		Host:     d.Notification.Host,
+		Label:    formatLabel(d),
+		Title:    d.Notification.Subject.Title,
+		URL:      d.Notification.Subject.URL,
+		Reason:   d.Notification.Reason,
+		Teams:    d.Teams,
+		Mode:     mode,
+		Ignored:  ignored,
+	}
+}
+
+// RecordKeep builds the journal record for a kept review request; direct
+// is whether login was requested personally.
+func RecordKeep(d Decision, direct bool, now time.Time) MutationRecord {
+	return MutationRecord{
+		Time:     now,
+		ThreadID: d.Notification.ID,
+		Host:     d.Notification.Host,
+		Label:    formatLabel(d),
+		Title:    d.Notification.Subject.Title,
+		Reason:   d.Notification.Reason,
+		Teams:    d.Teams,
+		Kept:     true,
+		Direct:   direct,
+	}
+}
+
+// RecordUndo builds the journal record for undoing a mute.
+func RecordUndo(m MutationRecord, now time.Time) MutationRecord {
+	m.Time, m.Undo = now, true
+	return m
+}
+
+// UndoCandidates returns the mutes an undo would reverse, newest first: each
+// thread's latest mute, unless it has been undone since. With since > 0, only
+// mutes made within since of now count; with last > 0, at most the last ones.
+// Records must be in the order they were written; kept records are ignored.
+func UndoCandidates(records []MutationRecord, now time.Time, since time.Duration, last int) []MutationRecord {
+	latest := make(map[string]int) // by host and thread, index into records
+	for i, r := range records {
+		if !r.Kept {
+			latest[r.Host+"\x00"+r.ThreadID] = i
+		}
+	}
+	var out []MutationRecord
+	for i := len(records) - 1; i >= 0; i-- {
+		r := records[i]
+		if r.Kept || latest[r.Host+"\x00"+r.ThreadID] != i || r.Undo {
+			continue
+		}
+		if since > 0 && now.Sub(r.Time) > since {
+			break
+		}
+		out = append(out, r)
+		if last > 0 && len(out) == last {
+			break
+		}
+	}
+	return out
+}
+
+// UndoCandidate finds a thread's latest mute to undo, on host when it's set,
+// or on any host. It fails if the thread has no mute in the journal, or its
+// latest mute was undone already.
+func UndoCandidate(records []MutationRecord, host, threadID string) (MutationRecord, error) {
+	for _, r := range UndoCandidates(records, time.Time{}, 0, 0) {
+		if r.ThreadID == threadID && (host == "" || strings.EqualFold(r.Host, host)) {
+			return r, nil
+		}
+	}
+	return MutationRecord{}, fmt.Errorf("thread %s has no mute in the journal to undo", threadID)
+}
+
+// PruneRecords drops records older than keep, for compacting the journal.
+func PruneRecords(records []MutationRecord, now time.Time, keep time.Duration) []MutationRecord {
+	var kept []MutationRecord
+	for _, r := range records {
+		if now.Sub(r.Time) <= keep {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// FormatUndoRow formats a mute being undone as a line of undo output.
+func FormatUndoRow(r MutationRecord, now time.Time, err error) string {
+	if err != nil {
+		return fmt.Sprintf("ERROR  %s  %q  %s", r.Label, r.Title, err)
+	}
+	when := FormatAge(now.Sub(r.Time))
+	if when != "now" {
+		when += " ago"
+	}
+	return fmt.Sprintf("UNDO   %s  %q  (muted %s)", r.Label, r.Title, when)
+}
+
+// FormatUndoSummary renders the final line of undo output.
+func FormatUndoSummary(undone, errors int, apply bool) string {
+	switch {
+	case !apply:
+		return fmt.Sprintf("\nWould undo %d mutes", undone)
+	case errors > 0:
+		return fmt.Sprintf("\nUndid %d mutes, %d errors", undone, errors)
+	default:
+		return fmt.Sprintf("\nUndid %d mutes", undone)
+	}
+}
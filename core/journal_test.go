@@ -0,0 +1,141 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestUndoCandidates(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	mute := func(id string, ago time.Duration) MutationRecord {
+		return MutationRecord{ThreadID: id, Time: now.Add(-ago), Ignored: true}
+	}
+	records := []MutationRecord{
+		mute("1", 5*time.Hour),
+		mute("2", 3*time.Hour),
+		mute("3", 90*time.Minute),
+		RecordUndo(mute("3", 0), now.Add(-time.Hour)),
+		mute("4", 50*time.Minute),
+		mute("2", 30*time.Minute), // muted again after being marked unread by hand
+		{ThreadID: "4", Host: "ghes.example.com", Time: now.Add(-10 * time.Minute)},
+		{ThreadID: "1", Time: now.Add(-5 * time.Minute), Kept: true}, // kept records aren't mutes
+		{ThreadID: "9", Time: now.Add(-time.Minute), Kept: true, Direct: true},
+	}
+
+	threads := func(rs []MutationRecord) []string {
+		var out []string
+		for _, r := range rs {
+			out = append(out, r.Host+r.ThreadID)
+		}
+		return out
+	}
+	tests := []struct {
+		name  string
+		since time.Duration
+		last  int
+		want  []string
+	}{
+		{"everything", 0, 0, []string{"ghes.example.com4", "2", "4", "1"}},
+		{"since", 2 * time.Hour, 0, []string{"ghes.example.com4", "2", "4"}},
+		{"last", 0, 2, []string{"ghes.example.com4", "2"}},
+		{"both", time.Hour, 10, []string{"ghes.example.com4", "2", "4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := threads(UndoCandidates(records, now, tt.since, tt.last)); !slices.Equal(got, tt.want) {
+				t.Errorf("UndoCandidates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Undoing them all leaves nothing to undo.
+	for _, r := range UndoCandidates(records, now, 0, 0) {
+		records = append(records, RecordUndo(r, now))
+	}
+	if got := UndoCandidates(records, now, 0, 0); len(got) != 0 {
+		t.Errorf("UndoCandidates() after undoing everything = %v, want none", threads(got))
+	}
+}
+
+func TestUndoCandidate(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	muted := MutationRecord{ThreadID: "1", Time: now.Add(-time.Hour)}
+	records := []MutationRecord{
+		muted,
+		{ThreadID: "2", Time: now.Add(-time.Hour)},
+		RecordUndo(MutationRecord{ThreadID: "2"}, now),
+		{ThreadID: "3", Host: "ghes.example.com", Time: now},
+		{ThreadID: "4", Time: now, Kept: true},
+	}
+	tests := []struct {
+		host, thread string
+		wantErr      bool
+	}{
+		{"", "1", false},
+		{"", "2", true}, // undone already
+		{"", "3", false},
+		{"GHES.example.com", "3", false},
+		{"other.example.com", "3", true},
+		{"", "4", true}, // kept, not muted
+		{"", "5", true},
+	}
+	for _, tt := range tests {
+		r, err := UndoCandidate(records, tt.host, tt.thread)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("UndoCandidate(%q, %q) error = %v, want error %v", tt.host, tt.thread, err, tt.wantErr)
+		}
+		if err == nil && r.ThreadID != tt.thread {
+			t.Errorf("UndoCandidate(%q, %q) = thread %s", tt.host, tt.thread, r.ThreadID)
+		}
+	}
+}
+
+func TestRecordMute(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	d := Decision{Notification: Notification{
+		ID:         "77",
+		Host:       "ghes.example.com",
+		Reason:     "review_requested",
+		Subject:    Subject{Title: "Bump deps", URL: "https://ghes.example.com/api/v3/repos/org/repo/pulls/9"},
+		Repository: Repository{FullName: "org/repo"},
+	}, Teams: []string{"backend"}}
+	want := MutationRecord{Time: now, ThreadID: "77", Host: "ghes.example.com", Label: "ghes.example.com/org/repo#9", Title: "Bump deps", URL: "https://ghes.example.com/api/v3/repos/org/repo/pulls/9", Reason: "review_requested", Teams: []string{"backend"}, Mode: ModeDone, Ignored: true}
+	if got := RecordMute(d, ModeDone, true, now); !reflect.DeepEqual(got, want) {
+		t.Errorf("RecordMute() = %+v, want %+v", got, want)
+	}
+
+	want = MutationRecord{Time: now, ThreadID: "77", Host: "ghes.example.com", Label: "ghes.example.com/org/repo#9", Title: "Bump deps", Reason: "review_requested", Teams: []string{"backend"}, Kept: true, Direct: true}
+	if got := RecordKeep(d, true, now); !reflect.DeepEqual(got, want) {
+		t.Errorf("RecordKeep() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPruneRecords(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	records := []MutationRecord{
+		{ThreadID: "old", Time: now.Add(-31 * 24 * time.Hour)},
+		{ThreadID: "new", Time: now.Add(-time.Hour)},
+	}
+	got := PruneRecords(records, now, JournalRetention)
+	if len(got) != 1 || got[0].ThreadID != "new" {
+		t.Errorf("PruneRecords() = %+v, want only the recent record", got)
+	}
+}
+
+func TestFormatUndoRow(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := MutationRecord{Label: "org/repo#9", Title: "Bump deps", Time: now.Add(-2 * time.Hour)}
+	if got, want := FormatUndoRow(r, now, nil), `UNDO   org/repo#9  "Bump deps"  (muted 2h ago)`; got != want {
+		t.Errorf("FormatUndoRow() = %q, want %q", got, want)
+	}
+	r.Time = now
+	if got, want := FormatUndoRow(r, now, nil), `UNDO   org/repo#9  "Bump deps"  (muted now)`; got != want {
+		t.Errorf("FormatUndoRow() = %q, want %q", got, want)
+	}
+	if got, want := FormatUndoRow(r, now, errors.New("status 500")), `ERROR  org/repo#9  "Bump deps"  status 500`; got != want {
+		t.Errorf("FormatUndoRow() = %q, want %q", got, want)
+	}
+}
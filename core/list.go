@@ -0,0 +1,112 @@
+package core
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ListFilter narrows the notifications mutemath ls shows, on top of the repo
+// filter in Config.
+type ListFilter struct {
+	Reasons []string // any of these reasons, e.g. "mention"; empty for all
+	Types   []string // any of these subject types, e.g. "Issue"; empty for all
+}
+
+// FilterForList returns the notifications passing the repo filter and f, in
+// their original order.
+func FilterForList(notifications []Notification, cfg Config, f ListFilter) []Notification {
+	var kept []Notification
+	for _, n := range notifications {
+		if !MatchesRepoFilter(n, cfg) {
+			continue
+		}
+		if len(f.Reasons) > 0 && !containsFold(f.Reasons, n.Reason) {
+			continue
+		}
+		if len(f.Types) > 0 && !containsFold(f.Types, n.Subject.Type) {
+			continue
+		}
+		kept = append(kept, n)
+	}
+	return kept
+}
+
+// ListSort is the order mutemath ls lists notifications in.
+type ListSort int
+
+const (
+	SortNewest ListSort = iota // most recently updated first
+	SortOldest                 // least recently updated first
+	SortRepo                   // by repository, newest first within each
+	SortReason                 // by reason, newest first within each
+)
+
+// ParseListSort parses the --sort flag.
+func ParseListSort(s string) (ListSort, error) {
+	switch strings.ToLower(s) {
+	case "", "newest":
+		return SortNewest, nil
+	case "oldest":
+		return SortOldest, nil
+	case "repo":
+		return SortRepo, nil
+	case "reason":
+		return SortReason, nil
+	default:
+		return 0, fmt.Errorf("invalid --sort %q (valid values: newest, oldest, repo, reason)", s)
+	}
+}
+
+// SortNotifications sorts notifications in place.
+func SortNotifications(notifications []Notification, by ListSort) {
+	newest := func(a, b Notification) int { return b.UpdatedAt.Compare(a.UpdatedAt) }
+	slices.SortStableFunc(notifications, func(a, b Notification) int {
+		switch by {
+		case SortOldest:
+			return -newest(a, b)
+		case SortRepo:
+			return cmp.Or(cmp.Compare(strings.ToLower(a.Host+"/"+a.Repository.FullName), strings.ToLower(b.Host+"/"+b.Repository.FullName)), newest(a, b))
+		case SortReason:
+			return cmp.Or(cmp.Compare(a.Reason, b.Reason), newest(a, b))
+		default:
+			return newest(a, b)
+		}
+	})
+}
+
+// FormatAge renders how long ago something happened, compactly: "now", "12m",
+// "5h", "3d".
+func FormatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+}
+
+// NotificationLabel renders a notification as "org/repo#42", or "org/repo"
+// when it isn't about a pull request.
+func NotificationLabel(n Notification) string {
+	return formatLabel(Decision{Notification: n})
+}
+
+// FormatListRow formats a notification as a line of mutemath ls output.
+func FormatListRow(n Notification, now time.Time) string {
+	return fmt.Sprintf("%-4s  %-40s  %-11s  %-16s  %s", FormatAge(now.Sub(n.UpdatedAt)), NotificationLabel(n), n.Subject.Type, n.Reason, n.Subject.Title)
+}
+
+// FormatListTSV formats a notification as a tab-separated line for scripts:
+// thread ID, updated time, label, type, reason, and title. Tabs and newlines
+// in the title become spaces.
+func FormatListTSV(n Notification) string {
+	title := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(n.Subject.Title)
+	return strings.Join([]string{n.ID, n.UpdatedAt.UTC().Format(time.RFC3339), NotificationLabel(n), n.Subject.Type, n.Reason, title}, "\t")
+}
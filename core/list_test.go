@@ -0,0 +1,106 @@
+package core
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func listFixture() []Notification {
+	at := func(h int) time.Time { return time.Date(2026, 3, 1, h, 0, 0, 0, time.UTC) }
+	return []Notification{
+		{ID: "1", Reason: "review_requested", Subject: Subject{Type: "PullRequest"}, Repository: Repository{FullName: "org/web", Owner: "org", Private: true}, UpdatedAt: at(9)},
+		{ID: "2", Reason: "mention", Subject: Subject{Type: "Issue"}, Repository: Repository{FullName: "org/api", Owner: "org", Private: true}, UpdatedAt: at(12)},
+		{ID: "3", Reason: "review_requested", Subject: Subject{Type: "PullRequest"}, Repository: Repository{FullName: "oss/lib", Owner: "oss"}, UpdatedAt: at(10)},
+		{ID: "4", Reason: "comment", Subject: Subject{Type: "PullRequest"}, Repository: Repository{FullName: "org/api", Owner: "org", Private: true}, UpdatedAt: at(8)},
+	}
+}
+
+func listIDs(ns []Notification) []string {
+	var out []string
+	for _, n := range ns {
+		out = append(out, n.ID)
+	}
+	return out
+}
+
+func TestFilterForList(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    Config
+		filter ListFilter
+		want   []string
+	}{
+		{"everything", Config{}, ListFilter{}, []string{"1", "2", "3", "4"}},
+		{"org", Config{IncludeOrg: "ORG"}, ListFilter{}, []string{"1", "2", "4"}},
+		{"public", Config{Visibility: "public"}, ListFilter{}, []string{"3"}},
+		{"reasons", Config{}, ListFilter{Reasons: []string{"mention", "comment"}}, []string{"2", "4"}},
+		{"types", Config{}, ListFilter{Types: []string{"pullrequest"}}, []string{"1", "3", "4"}},
+		{"combined", Config{ExcludeOrg: "oss"}, ListFilter{Reasons: []string{"review_requested"}}, []string{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := listIDs(FilterForList(listFixture(), tt.cfg, tt.filter)); !slices.Equal(got, tt.want) {
+				t.Errorf("FilterForList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortNotifications(t *testing.T) {
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{"", []string{"2", "3", "1", "4"}},
+		{"oldest", []string{"4", "1", "3", "2"}},
+		{"repo", []string{"2", "4", "1", "3"}},
+		{"reason", []string{"4", "2", "3", "1"}},
+	}
+	for _, tt := range tests {
+		by, err := ParseListSort(tt.sort)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ns := listFixture()
+		SortNotifications(ns, by)
+		if got := listIDs(ns); !slices.Equal(got, tt.want) {
+			t.Errorf("SortNotifications(%q) = %v, want %v", tt.sort, got, tt.want)
+		}
+	}
+	if _, err := ParseListSort("size"); err == nil {
+		t.Error("ParseListSort(size) succeeded, want an error")
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{30 * time.Second, "now"},
+		{12 * time.Minute, "12m"},
+		{5*time.Hour + 59*time.Minute, "5h"},
+		{47 * time.Hour, "47h"},
+		{72 * time.Hour, "3d"},
+	}
+	for _, tt := range tests {
+		if got := FormatAge(tt.d); got != tt.want {
+			t.Errorf("FormatAge(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestFormatListTSV(t *testing.T) {
+	n := Notification{
+		ID:         "42",
+		Reason:     "review_requested",
+		Subject:    Subject{Title: "Fix\tthe\nthing", URL: "https://api.github.com/repos/org/web/pulls/7", Type: "PullRequest"},
+		Repository: Repository{FullName: "org/web"},
+		UpdatedAt:  time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+	}
+	want := "42\t2026-03-01T09:00:00Z\torg/web#7\tPullRequest\treview_requested\tFix the thing"
+	if got := FormatListTSV(n); got != want {
+		t.Errorf("FormatListTSV() = %q, want %q", got, want)
+	}
+}
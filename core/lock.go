@@ -0,0 +1,57 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LockHolder describes the run holding the run lock, as recorded in the lock
+// file so a blocked run can say who it's waiting for.
+type LockHolder struct {
+	PID     int
+	Started time.Time
+	Daemon  bool
+}
+
+// FormatLockHolder renders a holder as the lock file's contents: PID, start
+// time, and "daemon" or "run".
+func FormatLockHolder(h LockHolder) string {
+	kind := "run"
+	if h.Daemon {
+		kind = "daemon"
+	}
+	return fmt.Sprintf("%d %s %s\n", h.PID, h.Started.UTC().Format(time.RFC3339), kind)
+}
+
+// ParseLockHolder parses a lock file's contents. Returns false if they're
+// missing or malformed, e.g. written by a crashed run.
+func ParseLockHolder(s string) (LockHolder, bool) {
+	fields := strings.Fields(s)
+	if len(fields) != 3 {
+		return LockHolder{}, false
+	}
+	pid, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return LockHolder{}, false
+	}
+	started, err := time.Parse(time.RFC3339, fields[1])
+	if err != nil {
+		return LockHolder{}, false
+	}
+	return LockHolder{PID: pid, Started: started, Daemon: fields[2] == "daemon"}, true
+}
+
+// DescribeLockHolder names the holder for a message, e.g. "a daemon (pid
+// 4242, started 3h ago)", or "another run" if it's unknown.
+func DescribeLockHolder(h LockHolder, known bool, now time.Time) string {
+	if !known {
+		return "another run"
+	}
+	kind := "another run"
+	if h.Daemon {
+		kind = "a daemon"
+	}
+	return fmt.Sprintf("%s (pid %d, started %s ago)", kind, h.PID, FormatAge(now.Sub(h.Started)))
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockHolderRoundTrip(t *testing.T) {
+	h := LockHolder{PID: 4242, Started: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), Daemon: true}
+	s := FormatLockHolder(h)
+	if s != "4242 2026-03-01T10:00:00Z daemon\n" {
+		t.Errorf("FormatLockHolder() = %q", s)
+	}
+	got, ok := ParseLockHolder(s)
+	if !ok || got != h {
+		t.Errorf("ParseLockHolder(%q) = %+v, %v; want %+v", s, got, ok, h)
+	}
+}
+
+func TestParseLockHolderMalformed(t *testing.T) {
+	for _, s := range []string{"", "4242", "x 2026-03-01T10:00:00Z run", "4242 yesterday run", "1 2 3 4"} {
+		if _, ok := ParseLockHolder(s); ok {
+			t.Errorf("ParseLockHolder(%q) ok, want malformed", s)
+		}
+	}
+}
+
+func TestDescribeLockHolder(t *testing.T) {
+	now := time.Date(2026, 3, 1, 13, 0, 0, 0, time.UTC)
+	started := now.Add(-3 * time.Hour)
+	tests := []struct {
+		h     LockHolder
+		known bool
+		want  string
+	}{
+		{h: LockHolder{PID: 4242, Started: started, Daemon: true}, known: true, want: "a daemon (pid 4242, started 3h ago)"},
+		{h: LockHolder{PID: 7, Started: now.Add(-5 * time.Minute)}, known: true, want: "another run (pid 7, started 5m ago)"},
+		{known: false, want: "another run"},
+	}
+	for _, tt := range tests {
+		if got := DescribeLockHolder(tt.h, tt.known, now); got != tt.want {
+			t.Errorf("DescribeLockHolder(%+v, %v) = %q, want %q", tt.h, tt.known, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Priority is a syslog severity, as used by syslog and journald.
+type Priority int
+
+const (
+	PriorityErr     Priority = 3
+	PriorityWarning Priority = 4
+	PriorityNotice  Priority = 5
+	PriorityInfo    Priority = 6
+)
+
+// LogBackends lists the valid --log values.
+var LogBackends = []string{"stderr", "syslog", "journald"}
+
+// ParseLogBackend validates a --log value. Empty means stderr.
+func ParseLogBackend(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return "stderr", nil
+	}
+	if !slices.Contains(LogBackends, s) {
+		return "", fmt.Errorf("invalid --log %q (valid values: %s)", s, strings.Join(LogBackends, ", "))
+	}
+	return s, nil
+}
+
+// LinePriority picks the priority for a line of log or daemon output, going by
+// the prefixes this program writes: errors ("cycle error: ...", "watchdog: ...",
+// mutation rows starting "ERROR"), warnings ("warning: ..."), and mutations ("READ"/"DONE"
+// rows), which are notices so mute activity stands out from routine info.
+func LinePriority(line string) Priority {
+	// Mutation rows start with a timestamp.
+	if ts, rest, ok := strings.Cut(line, "  "); ok {
+		if _, err := time.Parse(time.RFC3339, ts); err == nil {
+			line = rest
+		}
+	}
+	switch {
+	case strings.HasPrefix(line, "ERROR"), strings.HasPrefix(line, "Error:"), strings.HasPrefix(line, "cycle error:"), strings.HasPrefix(line, "watchdog:"):
+		return PriorityErr
+	case strings.HasPrefix(line, "warning:"):
+		return PriorityWarning
+	case strings.HasPrefix(line, ModeRead.ActionLabel()+" "), strings.HasPrefix(line, ModeDone.ActionLabel()+" "):
+		return PriorityNotice
+	default:
+		return PriorityInfo
+	}
+}
+
+// JournalEntry serializes a message in the journald native protocol
+// (https://systemd.io/JOURNAL_NATIVE_PROTOCOL/). Multi-line messages use the
+// length-prefixed form.
+func JournalEntry(identifier string, p Priority, msg string) []byte {
+	var b []byte
+	field := func(key, value string) {
+		if !strings.Contains(value, "\n") {
+			b = append(b, key+"="+value+"\n"...)
+			return
+		}
+		b = append(b, key+"\n"...)
+		b = binary.LittleEndian.AppendUint64(b, uint64(len(value)))
+		b = append(b, value+"\n"...)
+	}
+	field("MESSAGE", msg)
+	field("PRIORITY", strconv.Itoa(int(p)))
+	field("SYSLOG_IDENTIFIER", identifier)
+	return b
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseLogBackend(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "", want: "stderr"},
+		{in: "syslog", want: "syslog"},
+		{in: " Journald ", want: "journald"},
+		{in: "file", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLogBackend(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLogBackend(%q) = %q, %v; want %q, err %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestLinePriority(t *testing.T) {
+	d := Decision{Notification: Notification{
+		Subject:    Subject{Title: "Fix", URL: "https://api.github.com/repos/org/repo/pulls/1"},
+		Repository: Repository{FullName: "org/repo"},
+	}}
+	at := time.Date(2026, 2, 27, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		line string
+		want Priority
+	}{
+		{line: "cycle error: list notifications: EOF", want: PriorityErr},
+		{line: FormatMutationRow(d, ModeRead, errors.New("unexpected status 500"), at), want: PriorityErr},
+		{line: "watchdog: cycle is 6m0s overdue, over the 5m0s threshold", want: PriorityErr},
+		{line: "warning: get reviewers: unexpected status 404", want: PriorityWarning},
+		{line: FormatMutationRow(d, ModeRead, nil, at), want: PriorityNotice},
+		{line: FormatMutationRow(d, ModeDone, nil, at), want: PriorityNotice},
+		{line: "daemon started (poll interval: 1m0s)", want: PriorityInfo},
+		{line: "2026-02-27T10:00:00Z  cycle: 3 scanned, 1 read, 0 errors", want: PriorityInfo},
+		{line: "READY", want: PriorityInfo},
+	}
+	for _, tt := range tests {
+		if got := LinePriority(tt.line); got != tt.want {
+			t.Errorf("LinePriority(%q) = %d, want %d", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestJournalEntry(t *testing.T) {
+	got := JournalEntry("mutemath", PriorityWarning, "warning: slow")
+	want := "MESSAGE=warning: slow\nPRIORITY=4\nSYSLOG_IDENTIFIER=mutemath\n"
+	if string(got) != want {
+		t.Errorf("JournalEntry() = %q, want %q", got, want)
+	}
+
+	got = JournalEntry("mutemath", PriorityInfo, "a\nb")
+	want = "MESSAGE\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\nPRIORITY=6\nSYSLOG_IDENTIFIER=mutemath\n"
+	if string(got) != want {
+		t.Errorf("JournalEntry(multiline) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,17 @@
+package core
+
+// OctoboxIDs returns the Octobox notification IDs of the decisions with the
+// given action, so Octobox can mirror them. inbox maps GitHub thread IDs to
+// Octobox IDs; threads Octobox doesn't have are skipped.
+func OctoboxIDs(decisions []Decision, action Action, inbox map[string]int64) []int64 {
+	var ids []int64
+	for _, d := range decisions {
+		if d.Action != action {
+			continue
+		}
+		if id, ok := inbox[d.Notification.ID]; ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
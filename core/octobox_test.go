@@ -0,0 +1,36 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOctoboxIDs(t *testing.T) {
+	decisions := []Decision{
+		{Notification: Notification{ID: "1"}, Action: ActionMute},
+		{Notification: Notification{ID: "2"}, Action: ActionKeep},
+		{Notification: Notification{ID: "3"}, Action: ActionMute},
+		{Notification: Notification{ID: "4"}, Action: ActionMute}, // not in Octobox
+		{Notification: Notification{ID: "5"}, Action: ActionSkip},
+		{Notification: Notification{ID: "6"}, Action: ActionArchive},
+		{Notification: Notification{ID: "7"}, Action: ActionDim},
+	}
+	inbox := map[string]int64{"1": 101, "2": 102, "3": 103, "5": 105, "6": 106, "7": 107}
+
+	tests := []struct {
+		action Action
+		want   []int64
+	}{
+		{ActionMute, []int64{101, 103}},
+		{ActionArchive, []int64{106}},
+		{ActionDim, []int64{107}},
+	}
+	for _, tt := range tests {
+		if got := OctoboxIDs(decisions, tt.action, inbox); !slices.Equal(got, tt.want) {
+			t.Errorf("OctoboxIDs(%s) = %v, want %v", tt.action, got, tt.want)
+		}
+	}
+	if got := OctoboxIDs(decisions, ActionMute, nil); got != nil {
+		t.Errorf("OctoboxIDs(empty inbox) = %v, want nil", got)
+	}
+}
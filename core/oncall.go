@@ -0,0 +1,143 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OnCallSpec is where to find the user's reviewer-on-call rotation: an iCal
+// feed of their shifts, or a PagerDuty schedule and the email they're on it
+// as. While on call, team-only review requests through Teams (or any team,
+// when empty) are kept.
+type OnCallSpec struct {
+	ICalURL           string
+	PagerDutySchedule string
+	PagerDutyEmail    string
+	Teams             []string
+}
+
+// Validate checks that exactly one schedule source is configured.
+func (s OnCallSpec) Validate() error {
+	switch {
+	case s.ICalURL != "" && s.PagerDutySchedule != "":
+		return errors.New("oncall: set ical_url or pagerduty_schedule, not both")
+	case s.ICalURL != "":
+		u, err := url.Parse(s.ICalURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http" && u.Scheme != "webcal") || u.Host == "" {
+			return fmt.Errorf("oncall: ical_url %q must be an http(s) or webcal URL", s.ICalURL)
+		}
+	case s.PagerDutySchedule != "":
+		if s.PagerDutyEmail == "" {
+			return errors.New("oncall: pagerduty_schedule needs pagerduty_email, the address you're on the schedule as")
+		}
+	default:
+		return errors.New("oncall: set ical_url or pagerduty_schedule")
+	}
+	for _, t := range s.Teams {
+		if strings.TrimSpace(t) == "" {
+			return errors.New("oncall: teams: empty team slug")
+		}
+	}
+	return nil
+}
+
+// OnCallShift is one on-call shift, from Start up to End.
+type OnCallShift struct {
+	Start, End time.Time
+}
+
+// OnCallAt reports whether now falls in any of the shifts.
+func OnCallAt(shifts []OnCallShift, now time.Time) bool {
+	for _, s := range shifts {
+		if !now.Before(s.Start) && now.Before(s.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseICalShifts reads the events of an iCal feed as shifts. zone resolves
+// TZID parameters, e.g. time.LoadLocation; times without one are UTC or, for
+// all-day events, whole UTC days.
+func ParseICalShifts(data string, zone func(string) (*time.Location, error)) ([]OnCallShift, error) {
+	// Unfold continuation lines, which start with a space or tab.
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	data = strings.ReplaceAll(data, "\n ", "")
+	data = strings.ReplaceAll(data, "\n\t", "")
+
+	var shifts []OnCallShift
+	var cur *OnCallShift
+	for i, line := range strings.Split(data, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, params, _ := strings.Cut(name, ";")
+		switch strings.ToUpper(name) {
+		case "BEGIN":
+			if strings.EqualFold(value, "VEVENT") {
+				cur = &OnCallShift{}
+			}
+		case "END":
+			if strings.EqualFold(value, "VEVENT") && cur != nil {
+				if cur.Start.IsZero() {
+					return nil, fmt.Errorf("ical line %d: event has no DTSTART", i+1)
+				}
+				if cur.End.IsZero() {
+					cur.End = cur.Start.Add(24 * time.Hour)
+				}
+				shifts = append(shifts, *cur)
+				cur = nil
+			}
+		case "DTSTART", "DTEND":
+			if cur == nil {
+				continue
+			}
+			t, err := parseICalTime(value, params, zone)
+			if err != nil {
+				return nil, fmt.Errorf("ical line %d: %w", i+1, err)
+			}
+			if strings.EqualFold(name, "DTSTART") {
+				cur.Start = t
+			} else {
+				cur.End = t
+			}
+		}
+	}
+	return shifts, nil
+}
+
+func parseICalTime(value, params string, zone func(string) (*time.Location, error)) (time.Time, error) {
+	loc := time.UTC
+	for _, p := range strings.Split(params, ";") {
+		if k, v, ok := strings.Cut(p, "="); ok && strings.EqualFold(k, "TZID") {
+			l, err := zone(strings.Trim(v, `"`))
+			if err != nil {
+				return time.Time{}, fmt.Errorf("unknown TZID %q", v)
+			}
+			loc = l
+		}
+	}
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date-time %q", value)
+}
+
+// onCallTeam returns the requested team login is on call for, if any.
+func onCallTeam(reviewers *Reviewers, cfg Config) (string, bool) {
+	if !cfg.OnCall || reviewers == nil {
+		return "", false
+	}
+	for _, t := range reviewers.Teams {
+		if len(cfg.OnCallTeams) == 0 || containsFold(cfg.OnCallTeams, t) {
+			return t, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOnCallSpecValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    OnCallSpec
+		wantErr bool
+	}{
+		{"ical", OnCallSpec{ICalURL: "https://example.pagerduty.com/private/abc/feed"}, false},
+		{"webcal", OnCallSpec{ICalURL: "webcal://example.com/oncall.ics"}, false},
+		{"pagerduty", OnCallSpec{PagerDutySchedule: "P1ABCDE", PagerDutyEmail: "me@example.com", Teams: []string{"backend"}}, false},
+		{"neither", OnCallSpec{}, true},
+		{"both", OnCallSpec{ICalURL: "https://example.com/a.ics", PagerDutySchedule: "P1", PagerDutyEmail: "me@example.com"}, true},
+		{"bad url", OnCallSpec{ICalURL: "example.com/a.ics"}, true},
+		{"no email", OnCallSpec{PagerDutySchedule: "P1"}, true},
+		{"empty team", OnCallSpec{ICalURL: "https://example.com/a.ics", Teams: []string{" "}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.spec.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseICalShifts(t *testing.T) {
+	feed := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:On Call - Backend Reviewers\r\n" +
+		"DTSTART:20260302T090000Z\r\n" +
+		"DTEND:20260309T0900\r\n 00Z\r\n" + // folded
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;TZID=America/New_York:20260310T090000\r\n" +
+		"DTEND;TZID=America/New_York:20260310T170000\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20260320\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	ny := time.FixedZone("EST", -5*3600)
+	zone := func(string) (*time.Location, error) { return ny, nil }
+
+	shifts, err := ParseICalShifts(feed, zone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []OnCallShift{
+		{time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 3, 10, 9, 0, 0, 0, ny), time.Date(2026, 3, 10, 17, 0, 0, 0, ny)},
+		{time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 21, 0, 0, 0, 0, time.UTC)},
+	}
+	if len(shifts) != len(want) {
+		t.Fatalf("ParseICalShifts() = %v, want %v", shifts, want)
+	}
+	for i := range want {
+		if !shifts[i].Start.Equal(want[i].Start) || !shifts[i].End.Equal(want[i].End) {
+			t.Errorf("shift %d = %v, want %v", i, shifts[i], want[i])
+		}
+	}
+
+	for _, tt := range []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC), false}, // shifts end exclusively
+		{time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 3, 20, 23, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), false},
+	} {
+		if got := OnCallAt(shifts, tt.at); got != tt.want {
+			t.Errorf("OnCallAt(%v) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+
+	if _, err := ParseICalShifts("BEGIN:VEVENT\nDTSTART:tomorrow\nEND:VEVENT\n", zone); err == nil {
+		t.Error("ParseICalShifts() with a bad DTSTART succeeded, want an error")
+	}
+}
+
+func TestDecideOnCall(t *testing.T) {
+	n := Notification{
+		ID:         "1",
+		Reason:     "review_requested",
+		Subject:    Subject{Title: "Fix", URL: "https://api.github.com/repos/org/repo/pulls/1", Type: "PullRequest"},
+		Repository: Repository{FullName: "org/repo", Owner: "org"},
+	}
+	tests := []struct {
+		name       string
+		reviewers  *Reviewers
+		cfg        Config
+		wantAction Action
+		wantReason string
+	}{
+		{"off call", &Reviewers{Teams: []string{"backend"}}, Config{}, ActionMute, "team-only review request"},
+		{"on call, any team", &Reviewers{Teams: []string{"backend"}}, Config{OnCall: true}, ActionKeep, "on call for backend"},
+		{"on call for the team", &Reviewers{Teams: []string{"web", "Backend"}}, Config{OnCall: true, OnCallTeams: []string{"backend"}}, ActionKeep, "on call for Backend"},
+		{"on call for another team", &Reviewers{Teams: []string{"web"}}, Config{OnCall: true, OnCallTeams: []string{"backend"}}, ActionMute, "team-only review request"},
+		{"direct requests are kept anyway", &Reviewers{Users: []string{"me"}, Teams: []string{"backend"}}, Config{OnCall: true}, ActionKeep, "direct review request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Decide(n, Facts{Reviewers: tt.reviewers}, "me", tt.cfg)
+			if got.Action != tt.wantAction || got.Reason != tt.wantReason {
+				t.Errorf("Decide() = %s (%s), want %s (%s)", got.Action, got.Reason, tt.wantAction, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package core
+
+// NeedsParticipationLookup decides if a notification requires fetching the
+// PR's reviews and comments: for PRs passing the repo filter that aren't
+// pinned yet, when PinParticipated is set.
+func NeedsParticipationLookup(n Notification, cfg Config) bool {
+	return cfg.PinParticipated && n.Subject.Type == "PullRequest" && MatchesRepoFilter(n, cfg) &&
+		!cfg.Pinned[n.ID] && !IsPinned(n, cfg.Pins)
+}
+
+// Participated reports how login has taken part in the PR: "you reviewed" if
+// they've submitted a review, including review comments, or "you commented"
+// if they've commented on the conversation. ok is false if neither.
+func Participated(facts Facts, login string) (how string, ok bool) {
+	switch {
+	case containsFold(facts.ReviewAuthors, login):
+		return "you reviewed", true
+	case containsFold(facts.CommentAuthors, login):
+		return "you commented", true
+	default:
+		return "", false
+	}
+}
+
+// PinFor is the pin naming n's pull request, on n's host if it has one.
+func PinFor(n Notification) (ThreadTarget, error) {
+	ref, err := ParseSubjectURL(n.Subject.URL)
+	if err != nil {
+		return ThreadTarget{}, err
+	}
+	return ThreadTarget{PR: ref, Host: n.Host}, nil
+}
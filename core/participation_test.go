@@ -0,0 +1,67 @@
+package core
+
+import "testing"
+
+func TestDecidePinParticipated(t *testing.T) {
+	pr := Notification{ID: "9", Reason: "review_requested", Subject: Subject{Type: "PullRequest", URL: "https://api.github.com/repos/acme/api/pulls/42"}, Repository: Repository{Owner: "acme"}}
+	issue := Notification{ID: "10", Reason: "comment", Subject: Subject{Type: "Issue", URL: "https://api.github.com/repos/acme/api/issues/43"}, Repository: Repository{Owner: "acme"}}
+	muteAll := mustParseRules(t, RuleSpec{Name: "everything", When: "true", Action: "mute"})
+	on := Config{Rules: muteAll, PinParticipated: true}
+	tests := []struct {
+		name       string
+		n          Notification
+		facts      Facts
+		cfg        Config
+		wantAction Action
+		wantReason string
+		wantPin    bool
+	}{
+		{"reviewed", pr, Facts{ReviewAuthors: []string{"alice", "Me"}}, on, ActionKeep, "you reviewed", true},
+		{"commented", pr, Facts{CommentAuthors: []string{"me"}}, on, ActionKeep, "you commented", true},
+		{"neither", pr, Facts{ReviewAuthors: []string{"alice"}, CommentAuthors: []string{"bob"}}, on, ActionMute, "rule everything", false},
+		{"not a PR", issue, Facts{CommentAuthors: []string{"me"}}, on, ActionMute, "rule everything", false},
+		{"off", pr, Facts{ReviewAuthors: []string{"me"}}, Config{Rules: muteAll}, ActionMute, "rule everything", false},
+	}
+	for _, tt := range tests {
+		d := Decide(tt.n, tt.facts, "me", tt.cfg)
+		if d.Action != tt.wantAction || d.Reason != tt.wantReason || d.Pin != tt.wantPin {
+			t.Errorf("%s: Decide() = %s (%s) pin %v, want %s (%s) pin %v", tt.name, d.Action, d.Reason, d.Pin, tt.wantAction, tt.wantReason, tt.wantPin)
+		}
+	}
+}
+
+func TestNeedsParticipationLookup(t *testing.T) {
+	pr := Notification{ID: "9", Subject: Subject{Type: "PullRequest", URL: "https://api.github.com/repos/acme/api/pulls/42"}, Repository: Repository{Owner: "acme"}}
+	pinned := []ThreadTarget{{PR: PRRef{Owner: "acme", Repo: "api", Number: 42}}}
+	tests := []struct {
+		name string
+		n    Notification
+		cfg  Config
+		want bool
+	}{
+		{"on", pr, Config{PinParticipated: true}, true},
+		{"off", pr, Config{}, false},
+		{"already pinned", pr, Config{PinParticipated: true, Pins: pinned}, false},
+		{"filtered org", pr, Config{PinParticipated: true, ExcludeOrg: "acme"}, false},
+		{"issue", Notification{Subject: Subject{Type: "Issue"}}, Config{PinParticipated: true}, false},
+	}
+	for _, tt := range tests {
+		if got := NeedsParticipationLookup(tt.n, tt.cfg); got != tt.want {
+			t.Errorf("%s: NeedsParticipationLookup() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPinFor(t *testing.T) {
+	n := Notification{Host: "ghes.example.com", Subject: Subject{Type: "PullRequest", URL: "https://ghes.example.com/api/v3/repos/acme/api/pulls/42"}}
+	pin, err := PinFor(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pin.String(), "https://ghes.example.com/acme/api/pull/42"; got != want {
+		t.Errorf("PinFor().String() = %q, want %q", got, want)
+	}
+	if !pin.Matches(n) {
+		t.Error("PinFor() doesn't match its own notification")
+	}
+}
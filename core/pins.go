@@ -0,0 +1,73 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// String renders a target so ParseThreadTarget reads it back: the thread ID,
+// the PR URL when the host is known, or else org/repo#number.
+func (t ThreadTarget) String() string {
+	switch {
+	case t.ThreadID != "":
+		return t.ThreadID
+	case t.Host != "":
+		return fmt.Sprintf("https://%s/%s/%s/pull/%d", t.Host, t.PR.Owner, t.PR.Repo, t.PR.Number)
+	default:
+		return fmt.Sprintf("%s/%s#%d", t.PR.Owner, t.PR.Repo, t.PR.Number)
+	}
+}
+
+// Matches reports whether n is the target's thread: the same thread ID, or
+// a notification about the same pull request. A PR URL's host only has to
+// match notifications labeled with a host, from several hosts.
+func (t ThreadTarget) Matches(n Notification) bool {
+	if t.ThreadID != "" {
+		return t.ThreadID == n.ID
+	}
+	if t.Host != "" && n.Host != "" && !strings.EqualFold(t.Host, n.Host) {
+		return false
+	}
+	if n.Subject.Type != "PullRequest" {
+		return false
+	}
+	ref, err := ParseSubjectURL(n.Subject.URL)
+	return err == nil && ref.Number == t.PR.Number &&
+		strings.EqualFold(ref.Owner, t.PR.Owner) && strings.EqualFold(ref.Repo, t.PR.Repo)
+}
+
+// IsPinned reports whether any of pins is n's thread.
+func IsPinned(n Notification, pins []ThreadTarget) bool {
+	for _, p := range pins {
+		if p.Matches(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// samePin reports whether two pins name the same thread or PR.
+func samePin(a, b ThreadTarget) bool {
+	return a.ThreadID == b.ThreadID && strings.EqualFold(a.Host, b.Host) && a.PR.Number == b.PR.Number &&
+		strings.EqualFold(a.PR.Owner, b.PR.Owner) && strings.EqualFold(a.PR.Repo, b.PR.Repo)
+}
+
+// AddPin adds p to pins, reporting false if it was already there.
+func AddPin(pins []ThreadTarget, p ThreadTarget) ([]ThreadTarget, bool) {
+	for _, q := range pins {
+		if samePin(p, q) {
+			return pins, false
+		}
+	}
+	return append(pins, p), true
+}
+
+// RemovePin removes p from pins, reporting false if it wasn't there.
+func RemovePin(pins []ThreadTarget, p ThreadTarget) ([]ThreadTarget, bool) {
+	for i, q := range pins {
+		if samePin(p, q) {
+			return append(pins[:i:i], pins[i+1:]...), true
+		}
+	}
+	return pins, false
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestThreadTargetString(t *testing.T) {
+	for _, in := range []string{"1234567", "acme/api#42", "https://ghes.example.com/acme/api/pull/7"} {
+		target, err := ParseThreadTarget(in)
+		if err != nil {
+			t.Fatalf("ParseThreadTarget(%q) error = %v", in, err)
+		}
+		if got := target.String(); got != in {
+			t.Errorf("ParseThreadTarget(%q).String() = %q", in, got)
+		}
+	}
+}
+
+func TestIsPinned(t *testing.T) {
+	pr := func(id, host, url string) Notification {
+		return Notification{ID: id, Host: host, Subject: Subject{Type: "PullRequest", URL: url}}
+	}
+	pins := []ThreadTarget{
+		{ThreadID: "111"},
+		{PR: PRRef{Owner: "acme", Repo: "api", Number: 42}},
+		{PR: PRRef{Owner: "acme", Repo: "web", Number: 7}, Host: "ghes.example.com"},
+	}
+	tests := []struct {
+		name string
+		n    Notification
+		want bool
+	}{
+		{"thread ID", Notification{ID: "111"}, true},
+		{"PR", pr("2", "", "https://api.github.com/repos/Acme/API/pulls/42"), true},
+		{"other PR", pr("3", "", "https://api.github.com/repos/acme/api/pulls/43"), false},
+		{"issue with the PR's number", Notification{ID: "4", Subject: Subject{Type: "Issue", URL: "https://api.github.com/repos/acme/api/issues/42"}}, false},
+		{"PR URL pin, single host", pr("5", "", "https://ghes.example.com/api/v3/repos/acme/web/pulls/7"), true},
+		{"PR URL pin, its host", pr("6", "ghes.example.com", "https://ghes.example.com/api/v3/repos/acme/web/pulls/7"), true},
+		{"PR URL pin, another host", pr("7", "github.com", "https://api.github.com/repos/acme/web/pulls/7"), false},
+	}
+	for _, tt := range tests {
+		if got := IsPinned(tt.n, pins); got != tt.want {
+			t.Errorf("%s: IsPinned() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAddRemovePin(t *testing.T) {
+	a := ThreadTarget{PR: PRRef{Owner: "acme", Repo: "api", Number: 42}}
+	b := ThreadTarget{ThreadID: "111"}
+	pins, added := AddPin(nil, a)
+	if !added {
+		t.Fatal("AddPin() to an empty list = false")
+	}
+	pins, _ = AddPin(pins, b)
+	if _, added := AddPin(pins, ThreadTarget{PR: PRRef{Owner: "ACME", Repo: "api", Number: 42}}); added {
+		t.Error("AddPin() added the same PR again")
+	}
+	pins, removed := RemovePin(pins, a)
+	if !removed || !slices.Equal(pins, []ThreadTarget{b}) {
+		t.Errorf("RemovePin() = %v, %v, want [%v], true", pins, removed, b)
+	}
+	if _, removed := RemovePin(pins, a); removed {
+		t.Error("RemovePin() removed a pin that wasn't there")
+	}
+}
+
+func TestDecidePins(t *testing.T) {
+	n := Notification{ID: "9", Reason: "review_requested", Subject: Subject{Type: "PullRequest", URL: "https://api.github.com/repos/acme/api/pulls/42"}, Repository: Repository{Owner: "acme"}}
+	muteAll := mustParseRules(t, RuleSpec{Name: "everything", When: "true", Action: "mute"})
+	cfg := Config{Rules: muteAll, Pins: []ThreadTarget{{PR: PRRef{Owner: "acme", Repo: "api", Number: 42}}}}
+	if d := Decide(n, Facts{}, "me", cfg); d.Action != ActionKeep || d.Reason != "pinned" {
+		t.Errorf("Decide() = %s (%s), want KEEP (pinned)", d.Action, d.Reason)
+	}
+}
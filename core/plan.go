@@ -0,0 +1,199 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// planHeader explains the --edit plan file, like git rebase -i's todo list.
+const planHeader = `# mutemath plan: change the first word of a line to set that thread's action.
+#
+#   m, mute = mark read and ignore the thread
+#   k, keep = leave it unread
+#   s, skip = leave it unread (not a review request)
+#   c, claim = request your review personally, so it's yours, and leave it unread
+#   d, dim = mark read but stay subscribed
+#   a, archive = mark done but stay subscribed
+#   defer = leave it unread until business hours (only rules can defer)
+#
+# Deleting a line leaves that thread alone. Lines starting with # are ignored.
+# Save and quit to apply. If you remove everything, nothing is applied.
+`
+
+// ErrPlanEmpty means the edited plan has no entries, aborting the run.
+var ErrPlanEmpty = errors.New("plan is empty, nothing applied")
+
+// planWaveHeader follows planHeader when the plan has waves.
+const planWaveHeader = `#
+# A wave line stands for all of its PRs: its action applies to every one.
+`
+
+// FormatPlan renders decisions as an editable plan: one line per thread with
+// its action, thread ID, and a description after a #. Each wave gets one line,
+// with its ID, where its first PR would be.
+func FormatPlan(decisions []Decision, waves []Wave) string {
+	var sb strings.Builder
+	sb.WriteString(planHeader)
+	if len(waves) > 0 {
+		sb.WriteString(planWaveHeader)
+	}
+	sb.WriteString("\n")
+	index := WaveIndex(waves)
+	written := make(map[int]bool)
+	for _, d := range decisions {
+		if i, ok := index[d.Notification.ID]; ok {
+			if !written[i] {
+				written[i] = true
+				w := waves[i]
+				fmt.Fprintf(&sb, "%-4s %s  # %s by %s  %q  (%s)\n",
+					strings.ToLower(w.Action().String()), w.ID, w.Summary(), w.Author, w.Pattern, w.Reason())
+			}
+			continue
+		}
+		fmt.Fprintf(&sb, "%-4s %s  # %s  %q  (%s)\n",
+			strings.ToLower(d.Action.String()), d.Notification.ID, formatLabel(d), d.Notification.Subject.Title, d.Reason)
+	}
+	return sb.String()
+}
+
+// ParsePlan reads an edited plan back into decisions, in the plan's order,
+// with a wave's line standing for each of its PRs. Threads whose action
+// changed get the reason "edited plan"; threads whose lines were deleted are
+// dropped. Every problem is reported with its line.
+func ParsePlan(text string, decisions []Decision, waves []Wave) ([]Decision, error) {
+	byID := make(map[string]Decision, len(decisions))
+	for _, d := range decisions {
+		byID[d.Notification.ID] = d
+	}
+	wavesByID := make(map[string]Wave, len(waves))
+	for _, w := range waves {
+		wavesByID[w.ID] = w
+	}
+
+	var out []Decision
+	var errs []error
+	seen := make(map[string]int)
+	for i, line := range strings.Split(text, "\n") {
+		lineNo := i + 1
+		if before, _, ok := strings.Cut(line, "#"); ok {
+			line = before
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			errs = append(errs, fmt.Errorf("line %d: want \"<action> <thread id>\"", lineNo))
+			continue
+		}
+		action, claim, err := parsePlanAction(fields[0])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", lineNo, err))
+			continue
+		}
+		id := fields[1]
+		if w, ok := wavesByID[id]; ok {
+			switch {
+			case claim:
+				errs = append(errs, fmt.Errorf("line %d: %s can't be claimed; claim its PRs one at a time", lineNo, id))
+				continue
+			case action == ActionDefer && w.Action() != ActionDefer:
+				errs = append(errs, fmt.Errorf("line %d: only rules can defer a thread", lineNo))
+				continue
+			}
+			for _, d := range w.Decisions {
+				if prev, dup := seen[d.Notification.ID]; dup {
+					errs = append(errs, fmt.Errorf("line %d: thread %s in %s is already on line %d", lineNo, d.Notification.ID, id, prev))
+					continue
+				}
+				seen[d.Notification.ID] = lineNo
+				if action != d.Action {
+					d.Action = action
+					d.Reason, d.Rule = "edited plan", ""
+				}
+				out = append(out, d)
+			}
+			continue
+		}
+		d, ok := byID[id]
+		if !ok {
+			errs = append(errs, fmt.Errorf("line %d: unknown thread %s", lineNo, id))
+			continue
+		}
+		if prev, dup := seen[id]; dup {
+			errs = append(errs, fmt.Errorf("line %d: thread %s is already on line %d", lineNo, id, prev))
+			continue
+		}
+		seen[id] = lineNo
+		if claim && !CanClaim(d.Notification) {
+			errs = append(errs, fmt.Errorf("line %d: thread %s isn't a pull request review request, so it can't be claimed", lineNo, id))
+			continue
+		}
+		if action == ActionDefer && d.Action != ActionDefer {
+			errs = append(errs, fmt.Errorf("line %d: only rules can defer a thread", lineNo))
+			continue
+		}
+		switch {
+		case claim:
+			d.Action, d.Reason, d.Rule, d.Claim = ActionKeep, "claimed", "", true
+		case action != d.Action:
+			d.Action = action
+			d.Reason, d.Rule = "edited plan", ""
+		}
+		out = append(out, d)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(out) == 0 {
+		return nil, ErrPlanEmpty
+	}
+	return out, nil
+}
+
+// parsePlanAction accepts an action name or its first letter, or claim, a
+// keep that also requests login's review.
+func parsePlanAction(s string) (Action, bool, error) {
+	switch strings.ToLower(s) {
+	case "m":
+		return ActionMute, false, nil
+	case "k":
+		return ActionKeep, false, nil
+	case "s":
+		return ActionSkip, false, nil
+	case "d":
+		return ActionDim, false, nil
+	case "a":
+		return ActionArchive, false, nil
+	case "c", "claim":
+		return ActionKeep, true, nil
+	}
+	a, err := ParseAction(s)
+	return a, false, err
+}
+
+// CanClaim reports whether a thread can be claimed: it must be a review
+// request on a pull request.
+func CanClaim(n Notification) bool {
+	return n.Reason == "review_requested" && n.Subject.Type == "PullRequest"
+}
+
+// FormatClaimRow formats the outcome of claiming a review request.
+func FormatClaimRow(d Decision, err error) string {
+	if err != nil {
+		return fmt.Sprintf("ERROR  %s  %q  claim: %s", formatLabel(d), d.Notification.Subject.Title, err)
+	}
+	return fmt.Sprintf("CLAIM  %s  %q", formatLabel(d), d.Notification.Subject.Title)
+}
+
+// EditorCommand picks the plan editor the way git does: $VISUAL, then
+// $EDITOR, then vi. The value may carry arguments, e.g. "code --wait".
+func EditorCommand(visual, editor string) []string {
+	for _, v := range []string{visual, editor} {
+		if fields := strings.Fields(v); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"vi"}
+}
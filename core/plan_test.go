@@ -0,0 +1,183 @@
+package core
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func planDecisions() []Decision {
+	n := func(id, num string) Notification {
+		return Notification{
+			ID:         id,
+			Subject:    Subject{Title: "PR " + num, URL: "https://api.github.com/repos/org/repo/pulls/" + num},
+			Repository: Repository{FullName: "org/repo"},
+		}
+	}
+	return []Decision{
+		{Notification: n("101", "1"), Action: ActionMute, Reason: "team-only review request"},
+		{Notification: n("102", "2"), Action: ActionKeep, Reason: "direct review request"},
+		{Notification: n("103", "3"), Action: ActionSkip, Reason: "not a review-requested PR"},
+	}
+}
+
+func TestFormatPlan(t *testing.T) {
+	plan := FormatPlan(planDecisions(), nil)
+	for _, want := range []string{
+		"mute 101  # org/repo#1  \"PR 1\"  (team-only review request)\n",
+		"keep 102  # org/repo#2  \"PR 2\"  (direct review request)\n",
+		"skip 103  # org/repo#3",
+	} {
+		if !strings.Contains(plan, want) {
+			t.Errorf("FormatPlan() missing %q\nGot:\n%s", want, plan)
+		}
+	}
+
+	// An unedited plan round-trips.
+	got, err := ParsePlan(plan, planDecisions(), nil)
+	if err != nil {
+		t.Fatalf("ParsePlan(unedited) error = %v", err)
+	}
+	for i, d := range planDecisions() {
+		if got[i].Action != d.Action || got[i].Reason != d.Reason {
+			t.Errorf("ParsePlan(unedited)[%d] = %v (%s), want %v (%s)", i, got[i].Action, got[i].Reason, d.Action, d.Reason)
+		}
+	}
+}
+
+func TestParsePlan(t *testing.T) {
+	text := "# comment\n\nk 101 # was mute\nMUTE 103\n"
+	got, err := ParsePlan(text, planDecisions(), nil)
+	if err != nil {
+		t.Fatalf("ParsePlan() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("ParsePlan() = %d decisions, want 2 (deleted line dropped)", len(got))
+	}
+	if got[0].Notification.ID != "101" || got[0].Action != ActionKeep || got[0].Reason != "edited plan" {
+		t.Errorf("line 3 = %+v", got[0])
+	}
+	if got[1].Notification.ID != "103" || got[1].Action != ActionMute {
+		t.Errorf("line 4 = %+v", got[1])
+	}
+
+	got, err = ParsePlan("d 101\narchive 102\n", planDecisions(), nil)
+	if err != nil {
+		t.Fatalf("ParsePlan() error = %v", err)
+	}
+	if got[0].Action != ActionDim || got[1].Action != ActionArchive {
+		t.Errorf("ParsePlan(dim, archive) = %v, %v", got[0].Action, got[1].Action)
+	}
+}
+
+func TestParsePlanErrors(t *testing.T) {
+	text := "mutte 101\nmute 999\nkeep\nkeep 102\nmute 102\n"
+	_, err := ParsePlan(text, planDecisions(), nil)
+	if err == nil {
+		t.Fatal("ParsePlan() succeeded, want errors")
+	}
+	for _, want := range []string{
+		`line 1: invalid action "mutte"`,
+		"line 2: unknown thread 999",
+		"line 3: want",
+		"line 5: thread 102 is already on line 4",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("ParsePlan() error missing %q\nGot: %v", want, err)
+		}
+	}
+
+	if _, err := ParsePlan("# all gone\n", planDecisions(), nil); !errors.Is(err, ErrPlanEmpty) {
+		t.Errorf("ParsePlan(empty) error = %v, want ErrPlanEmpty", err)
+	}
+}
+
+func TestParsePlanClaim(t *testing.T) {
+	decisions := planDecisions()
+	for i := range decisions[:2] {
+		decisions[i].Notification.Reason = "review_requested"
+		decisions[i].Notification.Subject.Type = "PullRequest"
+	}
+	got, err := ParsePlan("claim 101\nc 102\nkeep 103\n", decisions, nil)
+	if err != nil {
+		t.Fatalf("ParsePlan() error = %v", err)
+	}
+	for i, want := range []bool{true, true, false} {
+		if got[i].Claim != want {
+			t.Errorf("ParsePlan()[%d].Claim = %v, want %v", i, got[i].Claim, want)
+		}
+	}
+	if got[0].Action != ActionKeep || got[0].Reason != "claimed" {
+		t.Errorf("claimed mute = %v (%s), want KEEP (claimed)", got[0].Action, got[0].Reason)
+	}
+
+	_, err = ParsePlan("claim 103\n", decisions, nil)
+	if err == nil || !strings.Contains(err.Error(), "line 1: thread 103 isn't a pull request review request") {
+		t.Errorf("ParsePlan(claim non-review) error = %v", err)
+	}
+}
+
+func TestFormatClaimRow(t *testing.T) {
+	d := planDecisions()[0]
+	if got, want := FormatClaimRow(d, nil), `CLAIM  org/repo#1  "PR 1"`; got != want {
+		t.Errorf("FormatClaimRow() = %q, want %q", got, want)
+	}
+	if got, want := FormatClaimRow(d, errors.New("unexpected status 422")), `ERROR  org/repo#1  "PR 1"  claim: unexpected status 422`; got != want {
+		t.Errorf("FormatClaimRow(err) = %q, want %q", got, want)
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	tests := []struct {
+		visual, editor string
+		want           string
+	}{
+		{"", "", "vi"},
+		{"", "nano", "nano"},
+		{"code --wait", "nano", "code|--wait"},
+		{"  ", "emacs -nw", "emacs|-nw"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(EditorCommand(tt.visual, tt.editor), "|"); got != tt.want {
+			t.Errorf("EditorCommand(%q, %q) = %q, want %q", tt.visual, tt.editor, got, tt.want)
+		}
+	}
+}
+
+func TestPlanWaves(t *testing.T) {
+	decisions := waveDecisions()
+	waves := FindWaves(decisions, 3)
+	plan := FormatPlan(decisions, waves)
+	if want := "mute wave1  # 3 PRs in 3 repos by dependabot[bot]  \"Bump golang.org/x/net from * to *\"  (various reasons)\n"; !strings.Contains(plan, want) {
+		t.Errorf("FormatPlan() missing %q\nGot:\n%s", want, plan)
+	}
+	if strings.Contains(plan, "mute 3 ") {
+		t.Errorf("FormatPlan() lists a wave's PR on its own:\n%s", plan)
+	}
+
+	got, err := ParsePlan("keep wave1\nmute 2\n", decisions, waves)
+	if err != nil {
+		t.Fatalf("ParsePlan() error = %v", err)
+	}
+	var ids []string
+	for _, d := range got {
+		ids = append(ids, d.Notification.ID)
+		if d.Notification.ID != "2" && (d.Action != ActionKeep || d.Reason != "edited plan") {
+			t.Errorf("ParsePlan() thread %s = %v (%s), want KEEP (edited plan)", d.Notification.ID, d.Action, d.Reason)
+		}
+	}
+	if !slices.Equal(ids, []string{"1", "3", "5", "2"}) {
+		t.Errorf("ParsePlan() threads = %v, want the wave's then 2", ids)
+	}
+
+	_, err = ParsePlan("claim wave1\nmute 3\nmute wave1\n", decisions, waves)
+	if err == nil {
+		t.Fatal("ParsePlan() succeeded, want errors")
+	}
+	for _, want := range []string{"line 1: wave1 can't be claimed", "line 3: thread 3 in wave1 is already on line 2"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("ParsePlan() error missing %q\nGot: %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// PolicySource says where an org's shared rules policy lives: either a plain
+// HTTPS URL, or a file in a GitHub repository fetched through the API (which
+// works for private repos).
+type PolicySource struct {
+	URL       string
+	Repo      string // "org/policies"
+	Path      string // "mutemath.json"
+	Ref       string // branch, tag, or commit; empty for the default branch
+	PublicKey string // base64 ed25519 key; if set, the policy must have a valid signature
+}
+
+func (p PolicySource) IsZero() bool {
+	return p.URL == "" && p.Repo == "" && p.Path == ""
+}
+
+// String identifies the source in messages and cache keys.
+func (p PolicySource) String() string {
+	if p.URL != "" {
+		return p.URL
+	}
+	s := p.Repo + "/" + p.Path
+	if p.Ref != "" {
+		s += "@" + p.Ref
+	}
+	return s
+}
+
+// Validate checks that exactly one kind of source is set and the key parses.
+func (p PolicySource) Validate() error {
+	switch {
+	case p.URL != "" && (p.Repo != "" || p.Path != ""):
+		return fmt.Errorf("set rules_url or rules_repo, not both")
+	case p.URL != "":
+		u, err := url.Parse(p.URL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("rules_url must be an https:// URL, got %q", p.URL)
+		}
+	case p.Repo != "" || p.Path != "":
+		owner, name, ok := strings.Cut(p.Repo, "/")
+		if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return fmt.Errorf("rules_repo.repo must be \"owner/repo\", got %q", p.Repo)
+		}
+		if p.Path == "" {
+			return fmt.Errorf("rules_repo.path is required")
+		}
+	}
+	if p.PublicKey != "" {
+		if _, err := parsePublicKey(p.PublicKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SignatureSource is where the detached signature for a policy lives: the
+// policy's URL or path with ".sig" appended.
+func (p PolicySource) SignatureSource() PolicySource {
+	sig := p
+	if p.URL != "" {
+		u, _ := url.Parse(p.URL)
+		u.Path += ".sig"
+		sig.URL = u.String()
+	} else {
+		sig.Path += ".sig"
+	}
+	sig.PublicKey = ""
+	return sig
+}
+
+func parsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("rules_public_key must be a base64 ed25519 public key")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// VerifyPolicy checks a policy's detached signature (base64 ed25519 over the
+// exact file bytes) against the source's public key. Sources without a key
+// aren't verified.
+func VerifyPolicy(p PolicySource, content []byte, signature string) error {
+	if p.PublicKey == "" {
+		return nil
+	}
+	key, err := parsePublicKey(p.PublicKey)
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("policy %s: malformed signature", p)
+	}
+	if !ed25519.Verify(key, content, sig) {
+		return fmt.Errorf("policy %s: signature does not match rules_public_key", p)
+	}
+	return nil
+}
+
+// CheckPolicy reports on fetching and parsing the shared policy, for doctor.
+func CheckPolicy(p PolicySource, rules int, err error) CheckResult {
+	if err != nil {
+		return CheckResult{Name: "Shared policy", Status: CheckFail, Detail: err.Error(), Fix: "check rules_url/rules_repo in the config file and that the policy is reachable"}
+	}
+	detail := fmt.Sprintf("%s (%d rules)", p, rules)
+	if p.PublicKey != "" {
+		detail += ", signature verified"
+	}
+	return CheckResult{Name: "Shared policy", Status: CheckOK, Detail: detail}
+}
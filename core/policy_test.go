@@ -0,0 +1,110 @@
+package core
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPolicySourceValidate(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(strings.NewReader(strings.Repeat("k", 64)))
+	key := base64.StdEncoding.EncodeToString(pub)
+
+	tests := []struct {
+		name    string
+		src     PolicySource
+		wantErr string
+	}{
+		{name: "url", src: PolicySource{URL: "https://raw.githubusercontent.com/org/policies/main/mutemath.json"}},
+		{name: "repo", src: PolicySource{Repo: "org/policies", Path: "mutemath.json", Ref: "main"}},
+		{name: "with key", src: PolicySource{URL: "https://example.com/p.json", PublicKey: key}},
+		{name: "both", src: PolicySource{URL: "https://example.com/p.json", Repo: "org/policies", Path: "p.json"}, wantErr: "not both"},
+		{name: "http url", src: PolicySource{URL: "http://example.com/p.json"}, wantErr: "https://"},
+		{name: "bad repo", src: PolicySource{Repo: "policies", Path: "p.json"}, wantErr: "owner/repo"},
+		{name: "missing path", src: PolicySource{Repo: "org/policies"}, wantErr: "path is required"},
+		{name: "bad key", src: PolicySource{URL: "https://example.com/p.json", PublicKey: "c2hvcnQ="}, wantErr: "ed25519"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.src.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicySourceString(t *testing.T) {
+	tests := []struct {
+		src  PolicySource
+		want string
+		sig  string
+	}{
+		{PolicySource{URL: "https://example.com/p.json?x=1"}, "https://example.com/p.json?x=1", "https://example.com/p.json.sig?x=1"},
+		{PolicySource{Repo: "org/policies", Path: "mutemath.json", Ref: "v2"}, "org/policies/mutemath.json@v2", "org/policies/mutemath.json.sig@v2"},
+		{PolicySource{Repo: "org/policies", Path: "mutemath.json"}, "org/policies/mutemath.json", "org/policies/mutemath.json.sig"},
+	}
+	for _, tt := range tests {
+		if got := tt.src.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+		if got := tt.src.SignatureSource().String(); got != tt.sig {
+			t.Errorf("SignatureSource() = %q, want %q", got, tt.sig)
+		}
+	}
+}
+
+func TestVerifyPolicy(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(strings.NewReader(strings.Repeat("k", 64)))
+	otherPub, _, _ := ed25519.GenerateKey(strings.NewReader(strings.Repeat("o", 64)))
+	content := []byte(`{"rules": []}`)
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, content))
+	src := PolicySource{URL: "https://example.com/p.json", PublicKey: base64.StdEncoding.EncodeToString(pub)}
+
+	tests := []struct {
+		name    string
+		src     PolicySource
+		content []byte
+		sig     string
+		wantErr string
+	}{
+		{name: "valid", src: src, content: content, sig: sig + "\n"},
+		{name: "no key skips verification", src: PolicySource{URL: src.URL}, content: content},
+		{name: "tampered", src: src, content: []byte(`{"rules": [{}]}`), sig: sig, wantErr: "does not match"},
+		{name: "wrong key", src: PolicySource{URL: src.URL, PublicKey: base64.StdEncoding.EncodeToString(otherPub)}, content: content, sig: sig, wantErr: "does not match"},
+		{name: "missing signature", src: src, content: content, wantErr: "malformed signature"},
+		{name: "garbage signature", src: src, content: content, sig: "!!", wantErr: "malformed signature"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyPolicy(tt.src, tt.content, tt.sig)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("VerifyPolicy() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("VerifyPolicy() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckPolicy(t *testing.T) {
+	src := PolicySource{Repo: "org/policies", Path: "p.json", PublicKey: "k"}
+	if r := CheckPolicy(src, 4, nil); r.Status != CheckOK || r.Detail != "org/policies/p.json (4 rules), signature verified" {
+		t.Errorf("CheckPolicy() = %v %q", r.Status, r.Detail)
+	}
+	if r := CheckPolicy(src, 0, errors.New("unexpected status 404")); r.Status != CheckFail {
+		t.Errorf("CheckPolicy(err) status = %v, want FAIL", r.Status)
+	}
+}
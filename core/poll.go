@@ -0,0 +1,103 @@
+package core
+
+import (
+	"cmp"
+	"fmt"
+	"time"
+)
+
+// IdleCyclesBeforeBackoff is how many not-modified cycles in a row the daemon
+// waits out at the server's poll interval before lengthening it.
+const IdleCyclesBeforeBackoff = 3
+
+// PollBackoff lengthens the daemon's poll interval while nothing changes: after
+// IdleCyclesBeforeBackoff not-modified cycles, each further idle cycle doubles
+// the interval, up to Max. Any change snaps back to the server's interval.
+type PollBackoff struct {
+	Max  time.Duration // zero disables backoff
+	idle int
+}
+
+// Next records whether a cycle saw changes and returns how long to wait before
+// the next one. base is the server's X-Poll-Interval, which is never undercut.
+func (b *PollBackoff) Next(base time.Duration, notModified bool) time.Duration {
+	if !notModified || b.Max <= base {
+		b.idle = 0
+		return base
+	}
+	b.idle++
+	doublings := b.idle - IdleCyclesBeforeBackoff + 1
+	if doublings <= 0 {
+		return base
+	}
+	d := base
+	for range doublings {
+		d *= 2
+		if d >= b.Max {
+			return b.Max
+		}
+	}
+	return d
+}
+
+// Jitter adds a random share of maxJitter to a poll wait, so daemons sharing a
+// server spread out rather than polling in lockstep. r is a random number in
+// [0, 1). Jitter only ever lengthens the wait, keeping X-Poll-Interval honored.
+func Jitter(d, maxJitter time.Duration, r float64) time.Duration {
+	if maxJitter <= 0 {
+		return d
+	}
+	return d + time.Duration(r*float64(maxJitter))
+}
+
+// LastRun is when a one-shot run last listed a host's notifications, and the
+// poll interval GitHub asked for in reply, kept for --min-interval-guard.
+type LastRun struct {
+	Host         string
+	At           time.Time
+	PollInterval time.Duration
+}
+
+// TooSoon reports whether a run at now would come sooner after l than its
+// poll interval allows, and if so how long until one may run.
+func (l LastRun) TooSoon(now time.Time) (wait time.Duration, soon bool) {
+	wait = l.At.Add(l.PollInterval).Sub(now)
+	return wait, wait > 0
+}
+
+// FormatTooSoon renders the notice a run stopped by --min-interval-guard
+// prints.
+func FormatTooSoon(l LastRun, now time.Time) string {
+	host := cmp.Or(l.Host, "github.com")
+	wait, _ := l.TooSoon(now)
+	return fmt.Sprintf("Skipped: the last run on %s was %s ago, sooner than its poll interval of %s; run again in %s.",
+		host, now.Sub(l.At).Round(time.Second), l.PollInterval, wait.Round(time.Second))
+}
+
+// FetchCursor is what the daemon carries between cycles so each listing only
+// downloads what changed. Servers that send Last-Modified get conditional
+// requests; for those that don't (some GHES versions), the listing falls back
+// to the since parameter.
+type FetchCursor struct {
+	LastModified string    // for If-Modified-Since
+	Since        time.Time // for since=, when there's no LastModified
+	All          bool      // list read threads too, for a --backfill --all first cycle
+}
+
+// Advance returns the cursor for the next cycle after a successful listing,
+// which only lists unread threads. lastModified is the response's Last-Modified header, if any. serverDate is
+// its Date header, used for since so the client's clock skew doesn't drop
+// updates; cycleStart stands in when the server sent no Date.
+func (c FetchCursor) Advance(lastModified string, serverDate, cycleStart time.Time) FetchCursor {
+	if lastModified != "" {
+		return FetchCursor{LastModified: lastModified}
+	}
+	if c.LastModified != "" {
+		// A 304 carries no Last-Modified; the previous one still holds.
+		return FetchCursor{LastModified: c.LastModified}
+	}
+	if !serverDate.IsZero() {
+		return FetchCursor{Since: serverDate}
+	}
+	return FetchCursor{Since: cycleStart}
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPollBackoff(t *testing.T) {
+	base := time.Minute
+	b := PollBackoff{Max: 10 * time.Minute}
+
+	// Three idle cycles at the base interval, then doubling up to the max.
+	want := []time.Duration{1, 1, 2, 4, 8, 10, 10}
+	for i, w := range want {
+		if got := b.Next(base, true); got != w*time.Minute {
+			t.Errorf("idle cycle %d: Next() = %s, want %s", i+1, got, w*time.Minute)
+		}
+	}
+
+	// A change snaps back, and backoff starts over.
+	if got := b.Next(base, false); got != base {
+		t.Errorf("after a change: Next() = %s, want %s", got, base)
+	}
+	if got := b.Next(base, true); got != base {
+		t.Errorf("first idle cycle after a change: Next() = %s, want %s", got, base)
+	}
+}
+
+func TestPollBackoffDisabled(t *testing.T) {
+	var b PollBackoff
+	for range 10 {
+		if got := b.Next(time.Minute, true); got != time.Minute {
+			t.Fatalf("Next() = %s with backoff disabled, want 1m", got)
+		}
+	}
+}
+
+func TestPollBackoffNeverUndercutsServer(t *testing.T) {
+	b := PollBackoff{Max: time.Minute}
+	for range 10 {
+		if got := b.Next(2*time.Minute, true); got != 2*time.Minute {
+			t.Fatalf("Next() = %s, want the server's 2m", got)
+		}
+	}
+}
+
+func TestJitter(t *testing.T) {
+	tests := []struct {
+		name      string
+		d         time.Duration
+		maxJitter time.Duration
+		r         float64
+		want      time.Duration
+	}{
+		{name: "disabled", d: time.Minute, maxJitter: 0, r: 0.5, want: time.Minute},
+		{name: "none drawn", d: time.Minute, maxJitter: 20 * time.Second, r: 0, want: time.Minute},
+		{name: "half", d: time.Minute, maxJitter: 20 * time.Second, r: 0.5, want: 70 * time.Second},
+		{name: "initial delay", d: 0, maxJitter: 20 * time.Second, r: 0.25, want: 5 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Jitter(tt.d, tt.maxJitter, tt.r); got != tt.want {
+				t.Errorf("Jitter() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLastRunTooSoon(t *testing.T) {
+	last := LastRun{At: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), PollInterval: time.Minute}
+	tests := []struct {
+		after    time.Duration
+		wantWait time.Duration
+		wantSoon bool
+	}{
+		{0, time.Minute, true},
+		{20 * time.Second, 40 * time.Second, true},
+		{time.Minute, 0, false},
+		{time.Hour, -59 * time.Minute, false},
+	}
+	for _, tt := range tests {
+		if wait, soon := last.TooSoon(last.At.Add(tt.after)); wait != tt.wantWait || soon != tt.wantSoon {
+			t.Errorf("TooSoon(+%s) = %s, %v, want %s, %v", tt.after, wait, soon, tt.wantWait, tt.wantSoon)
+		}
+	}
+
+	want := "Skipped: the last run on github.com was 20s ago, sooner than its poll interval of 1m0s; run again in 40s."
+	if got := FormatTooSoon(last, last.At.Add(20*time.Second)); got != want {
+		t.Errorf("FormatTooSoon() = %q, want %q", got, want)
+	}
+}
+
+func TestFetchCursorAdvance(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	serverDate := start.Add(-5 * time.Second)
+
+	tests := []struct {
+		name         string
+		cursor       FetchCursor
+		lastModified string
+		serverDate   time.Time
+		want         FetchCursor
+	}{
+		{name: "last-modified", cursor: FetchCursor{}, lastModified: "Mon, 02 Mar 2026 09:00:00 GMT", serverDate: serverDate, want: FetchCursor{LastModified: "Mon, 02 Mar 2026 09:00:00 GMT"}},
+		{name: "304 keeps last-modified", cursor: FetchCursor{LastModified: "x"}, serverDate: serverDate, want: FetchCursor{LastModified: "x"}},
+		{name: "no last-modified uses server date", cursor: FetchCursor{}, serverDate: serverDate, want: FetchCursor{Since: serverDate}},
+		{name: "since advances", cursor: FetchCursor{Since: start.Add(-time.Hour)}, serverDate: serverDate, want: FetchCursor{Since: serverDate}},
+		{name: "no date uses cycle start", cursor: FetchCursor{}, want: FetchCursor{Since: start}},
+		{name: "last-modified appears", cursor: FetchCursor{Since: start}, lastModified: "y", want: FetchCursor{LastModified: "y"}},
+		{name: "backfill of read threads ends", cursor: FetchCursor{All: true}, serverDate: serverDate, want: FetchCursor{Since: serverDate}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cursor.Advance(tt.lastModified, tt.serverDate, start); got != tt.want {
+				t.Errorf("Advance() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// PushJob is the Pushgateway job one-shot runs push their metrics under.
+const PushJob = "mutemath"
+
+// PushgatewayURL is where a run's metrics go on the Pushgateway at base:
+// the mutemath job's group, split by host when processing several.
+func PushgatewayURL(base, host string) string {
+	u := strings.TrimRight(base, "/") + "/metrics/job/" + PushJob
+	if host != "" {
+		u += "/host/" + url.PathEscape(host)
+	}
+	return u
+}
+
+// FormatPushMetrics renders a run's summary as Prometheus text exposition,
+// for a Pushgateway. Every metric is a gauge describing the last run, as
+// each push replaces the previous run's.
+func FormatPushMetrics(s RunSummary) string {
+	var b strings.Builder
+	gauge := func(name, help string, samples ...string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		for _, sample := range samples {
+			fmt.Fprintf(&b, "%s%s\n", name, sample)
+		}
+	}
+	value := func(v any) string { return fmt.Sprintf(" %v", v) }
+	flag := func(v bool) string {
+		if v {
+			return " 1"
+		}
+		return " 0"
+	}
+
+	gauge("mutemath_last_run_timestamp_seconds", "When the last run finished, as a Unix time.", value(s.Finished.Unix()))
+	gauge("mutemath_last_run_duration_seconds", "How long the last run took.", value(s.Duration.Seconds()))
+	gauge("mutemath_last_run_success", "Whether the last run listed notifications and made every mute without an error.", flag(s.Err == "" && s.Errors == 0))
+	gauge("mutemath_last_run_applied", "Whether the last run applied its mutes, rather than previewing them.", flag(s.Applied))
+	gauge("mutemath_last_run_notifications", "Notifications the last run decided, by action.",
+		`{action="muted"}`+value(s.Muted),
+		`{action="dimmed"}`+value(s.Dimmed),
+		`{action="archived"}`+value(s.Archived),
+		`{action="kept"}`+value(s.Kept),
+		`{action="skipped"}`+value(s.Skipped))
+	gauge("mutemath_last_run_mute_errors", "Mutes, dims, and archives that failed in the last run.", value(s.Errors))
+	if len(s.Rules) > 0 {
+		var samples []string
+		for _, h := range s.Rules {
+			samples = append(samples, fmt.Sprintf(`{rule="%s"}`, escapeLabel(h.Name))+value(h.Count))
+		}
+		gauge("mutemath_last_run_rule_matches", "Notifications each rule decided in the last run.", samples...)
+	}
+	if s.RateLimit.Limit > 0 {
+		gauge("mutemath_rate_limit_remaining", "API requests left in the rate-limit window after the last run.", value(s.RateLimit.Remaining))
+		gauge("mutemath_rate_limit_limit", "API requests allowed per rate-limit window.", value(s.RateLimit.Limit))
+	}
+	return b.String()
+}
+
+// escapeLabel escapes a Prometheus label value.
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
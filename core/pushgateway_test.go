@@ -0,0 +1,64 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPushgatewayURL(t *testing.T) {
+	tests := []struct {
+		base, host, want string
+	}{
+		{"http://pushgateway:9091", "", "http://pushgateway:9091/metrics/job/mutemath"},
+		{"http://pushgateway:9091/", "ghes.example.com", "http://pushgateway:9091/metrics/job/mutemath/host/ghes.example.com"},
+	}
+	for _, tt := range tests {
+		if got := PushgatewayURL(tt.base, tt.host); got != tt.want {
+			t.Errorf("PushgatewayURL(%q, %q) = %q, want %q", tt.base, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestFormatPushMetrics(t *testing.T) {
+	s := RunSummary{
+		Finished:  time.Unix(1760000000, 0),
+		Duration:  1500 * time.Millisecond,
+		Applied:   true,
+		Scanned:   6,
+		Muted:     3,
+		Archived:  1,
+		Kept:      1,
+		Skipped:   2,
+		Errors:    1,
+		RateLimit: RateLimit{Limit: 5000, Remaining: 4990},
+		Rules:     []RuleHit{{Name: `bots "all"`, Count: 2}},
+	}
+	got := FormatPushMetrics(s)
+	for _, want := range []string{
+		"# TYPE mutemath_last_run_timestamp_seconds gauge\nmutemath_last_run_timestamp_seconds 1760000000\n",
+		"mutemath_last_run_duration_seconds 1.5\n",
+		"mutemath_last_run_success 0\n",
+		"mutemath_last_run_applied 1\n",
+		`mutemath_last_run_notifications{action="muted"} 3` + "\n",
+		`mutemath_last_run_notifications{action="archived"} 1` + "\n",
+		`mutemath_last_run_notifications{action="skipped"} 2` + "\n",
+		"mutemath_last_run_mute_errors 1\n",
+		`mutemath_last_run_rule_matches{rule="bots \"all\""} 2` + "\n",
+		"mutemath_rate_limit_remaining 4990\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FormatPushMetrics() missing %q in:\n%s", want, got)
+		}
+	}
+
+	bare := FormatPushMetrics(RunSummary{Err: "list notifications: 502"})
+	if !strings.Contains(bare, "mutemath_last_run_success 0\n") {
+		t.Errorf("FormatPushMetrics() of a failed run:\n%s", bare)
+	}
+	for _, absent := range []string{"mutemath_rate_limit_remaining", "mutemath_last_run_rule_matches"} {
+		if strings.Contains(bare, absent) {
+			t.Errorf("FormatPushMetrics() without rate limit or rules has %s", absent)
+		}
+	}
+}
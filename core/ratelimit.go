@@ -0,0 +1,77 @@
+package core
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RateLimitResource is one bucket from GET /rate_limit, e.g. "core" (REST) or
+// "graphql".
+type RateLimitResource struct {
+	Name      string
+	Limit     int
+	Used      int
+	Remaining int
+	Reset     time.Time
+}
+
+// rateLimitLowFraction is the share of a limit below which its remaining
+// calls are flagged as low.
+const rateLimitLowFraction = 0.1
+
+// RateLimitResources orders resources for display: core and graphql, which
+// mutemath uses, first, then the rest by name. Without all, only those two
+// are kept.
+func RateLimitResources(resources []RateLimitResource, all bool) []RateLimitResource {
+	rank := func(name string) int {
+		switch name {
+		case "core":
+			return 0
+		case "graphql":
+			return 1
+		}
+		return 2
+	}
+	var out []RateLimitResource
+	for _, r := range resources {
+		if all || rank(r.Name) < 2 {
+			out = append(out, r)
+		}
+	}
+	slices.SortFunc(out, func(a, b RateLimitResource) int {
+		return cmp.Or(cmp.Compare(rank(a.Name), rank(b.Name)), cmp.Compare(a.Name, b.Name))
+	})
+	return out
+}
+
+// FormatRateLimits renders rate-limit resources as a table for mutemath
+// ratelimit, flagging exhausted and nearly exhausted ones. No resources means
+// the server has rate limiting disabled, as GitHub Enterprise Server can.
+func FormatRateLimits(resources []RateLimitResource, now time.Time) string {
+	if len(resources) == 0 {
+		return "Rate limiting is disabled on this server.\n"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-10s  %13s  %9s  %s\n", "RESOURCE", "USED/LIMIT", "REMAINING", "RESETS")
+	for _, r := range resources {
+		reset := "now"
+		switch d := r.Reset.Sub(now); {
+		case d >= time.Minute:
+			reset = fmt.Sprintf("in %s (%s)", FormatAge(d), r.Reset.UTC().Format("15:04 UTC"))
+		case d > 0:
+			reset = fmt.Sprintf("in under 1m (%s)", r.Reset.UTC().Format("15:04 UTC"))
+		}
+		fmt.Fprintf(&b, "%-10s  %13s  %9d  %s", r.Name, fmt.Sprintf("%d/%d", r.Used, r.Limit), r.Remaining, reset)
+		switch {
+		case r.Remaining == 0:
+			b.WriteString("  EXHAUSTED")
+		case float64(r.Remaining) < rateLimitLowFraction*float64(r.Limit):
+			b.WriteString("  LOW")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimitResources(t *testing.T) {
+	resources := []RateLimitResource{{Name: "search"}, {Name: "graphql"}, {Name: "code_search"}, {Name: "core"}}
+	tests := []struct {
+		all  bool
+		want []string
+	}{
+		{all: false, want: []string{"core", "graphql"}},
+		{all: true, want: []string{"core", "graphql", "code_search", "search"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, r := range RateLimitResources(resources, tt.all) {
+			got = append(got, r.Name)
+		}
+		if len(got) != len(tt.want) {
+			t.Fatalf("RateLimitResources(all=%v) = %v, want %v", tt.all, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("RateLimitResources(all=%v) = %v, want %v", tt.all, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestFormatRateLimits(t *testing.T) {
+	now := time.Date(2026, 3, 1, 16, 34, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		resources []RateLimitResource
+		want      string
+	}{
+		{
+			name: "disabled",
+			want: "Rate limiting is disabled on this server.\n",
+		},
+		{
+			name: "usage",
+			resources: []RateLimitResource{
+				{Name: "core", Limit: 5000, Used: 412, Remaining: 4588, Reset: now.Add(38 * time.Minute)},
+				{Name: "graphql", Limit: 5000, Used: 4700, Remaining: 300, Reset: now.Add(30 * time.Second)},
+				{Name: "search", Limit: 30, Used: 30, Remaining: 0, Reset: now.Add(-time.Second)},
+			},
+			want: "RESOURCE       USED/LIMIT  REMAINING  RESETS\n" +
+				"core             412/5000       4588  in 38m (17:12 UTC)\n" +
+				"graphql         4700/5000        300  in under 1m (16:34 UTC)  LOW\n" +
+				"search              30/30          0  now  EXHAUSTED\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatRateLimits(tt.resources, now); got != tt.want {
+				t.Errorf("FormatRateLimits() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
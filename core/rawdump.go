@@ -0,0 +1,100 @@
+package core
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// --dump-raw writes every API response of a run to a directory, for bug
+// reports; mutemath replay serves them back in place of GitHub.
+
+// Redacted replaces secrets in dumped responses.
+const Redacted = "[REDACTED]"
+
+// githubTokenPattern matches GitHub token formats: classic and OAuth tokens
+// (ghp_, gho_, ghu_, ghs_, ghr_) and fine-grained personal access tokens.
+var githubTokenPattern = regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,})\b`)
+
+// RedactSecrets replaces each of secrets, and anything shaped like a GitHub
+// token, with Redacted.
+func RedactSecrets(s string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, Redacted)
+		}
+	}
+	return githubTokenPattern.ReplaceAllString(s, Redacted)
+}
+
+// DumpedHeaders lists the response headers a dump keeps: those mutemath
+// reads. Others, such as cookies, are dropped.
+var DumpedHeaders = []string{
+	"Content-Type",
+	"Date",
+	"ETag",
+	"Last-Modified",
+	"Retry-After",
+	"X-Poll-Interval",
+	"X-OAuth-Scopes",
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+	"X-RateLimit-Resource",
+}
+
+// RelativeURL strips the API base URL from a request URL, so a dump replays
+// against any server: "https://api.github.com/notifications?page=1" becomes
+// "/notifications?page=1".
+func RelativeURL(u, baseURL string) string {
+	if rest, ok := strings.CutPrefix(u, strings.TrimSuffix(baseURL, "/")); ok {
+		return rest
+	}
+	if parsed, err := url.Parse(u); err == nil {
+		return parsed.RequestURI()
+	}
+	return u
+}
+
+// ReplayKey identifies a request for replay: its method, its relative URL
+// with the query sorted, and its body, so GraphQL queries about different
+// PRs get their own responses.
+func ReplayKey(method, relURL, body string) string {
+	path, query, _ := strings.Cut(relURL, "?")
+	if values, err := url.ParseQuery(query); err == nil && query != "" {
+		path += "?" + values.Encode()
+	}
+	key := strings.ToUpper(method) + " " + path
+	if body != "" {
+		key += " " + body
+	}
+	return key
+}
+
+// Replay serves dumped responses by request: each key's responses in the
+// order they were dumped, then its last one again.
+type Replay struct {
+	byKey  map[string][]int // indexes into the dump, in order
+	served map[string]int
+}
+
+// NewReplay indexes a dump's responses by their ReplayKey.
+func NewReplay(keys []string) *Replay {
+	r := &Replay{byKey: make(map[string][]int), served: make(map[string]int)}
+	for i, k := range keys {
+		r.byKey[k] = append(r.byKey[k], i)
+	}
+	return r
+}
+
+// Next returns the index of the response to serve for key, or false if the
+// dump has none.
+func (r *Replay) Next(key string) (int, bool) {
+	indexes := r.byKey[key]
+	if len(indexes) == 0 {
+		return 0, false
+	}
+	n := r.served[key]
+	r.served[key] = n + 1
+	return indexes[min(n, len(indexes)-1)], true
+}
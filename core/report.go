@@ -0,0 +1,118 @@
+package core
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// reportWeek is the length of a review-load report period.
+const reportWeek = 7 * 24 * time.Hour
+
+// CompletedReview is a review login submitted on a pull request.
+type CompletedReview struct {
+	PR        string // e.g. "org/repo#42"
+	Submitted time.Time
+}
+
+// ReviewLoadWeek is one week of a review-load report.
+type ReviewLoadWeek struct {
+	Start, End  time.Time
+	Direct      int // review requests kept that named login personally
+	TeamMuted   int // review requests muted
+	Reviews     int // reviews login submitted
+	ReviewedPRs int // pull requests those reviews were on
+}
+
+// TeamCount is how many muted review requests came through a team.
+type TeamCount struct {
+	Team  string
+	Count int
+}
+
+// ReviewLoad summarizes the journal records for host and login's completed
+// reviews into weeks of seven days ending at now, newest first. A thread
+// counts once per week however many times it was recorded.
+func ReviewLoad(records []MutationRecord, reviews []CompletedReview, host string, now time.Time, weeks int) []ReviewLoadWeek {
+	out := make([]ReviewLoadWeek, weeks)
+	for i := range out {
+		end := now.Add(-time.Duration(i) * reportWeek)
+		w := ReviewLoadWeek{Start: end.Add(-reportWeek), End: end}
+		direct, muted := make(map[string]bool), make(map[string]bool)
+		for _, r := range records {
+			if r.Host != host || r.Reason != "review_requested" || !inWeek(r.Time, w) {
+				continue
+			}
+			switch {
+			case r.Kept && r.Direct:
+				direct[r.ThreadID] = true
+			case !r.Kept && !r.Undo:
+				muted[r.ThreadID] = true
+			}
+		}
+		prs := make(map[string]bool)
+		for _, rv := range reviews {
+			if inWeek(rv.Submitted, w) {
+				w.Reviews++
+				prs[rv.PR] = true
+			}
+		}
+		w.Direct, w.TeamMuted, w.ReviewedPRs = len(direct), len(muted), len(prs)
+		out[i] = w
+	}
+	return out
+}
+
+// MutedByTeam counts the muted review requests for host between start and
+// end by requested team, most first. A request through several teams counts
+// for each; each thread counts once.
+func MutedByTeam(records []MutationRecord, host string, start, end time.Time) []TeamCount {
+	threads := make(map[string]map[string]bool) // by team
+	for _, r := range records {
+		if r.Host != host || r.Kept || r.Undo || r.Reason != "review_requested" || !inWeek(r.Time, ReviewLoadWeek{Start: start, End: end}) {
+			continue
+		}
+		for _, t := range r.Teams {
+			if threads[t] == nil {
+				threads[t] = make(map[string]bool)
+			}
+			threads[t][r.ThreadID] = true
+		}
+	}
+	var out []TeamCount
+	for t, ids := range threads {
+		out = append(out, TeamCount{Team: t, Count: len(ids)})
+	}
+	slices.SortFunc(out, func(a, b TeamCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Team, b.Team))
+	})
+	return out
+}
+
+func inWeek(t time.Time, w ReviewLoadWeek) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// FormatReviewLoad renders a review-load report for mutemath report.
+func FormatReviewLoad(login string, weeks []ReviewLoadWeek, teams []TeamCount) string {
+	var b strings.Builder
+	period := "last week"
+	if len(weeks) > 1 {
+		period = fmt.Sprintf("last %d weeks", len(weeks))
+	}
+	fmt.Fprintf(&b, "Review load for %s, %s\n\n", login, period)
+	fmt.Fprintf(&b, "%-10s  %6s  %10s  %7s  %3s\n", "WEEK OF", "DIRECT", "TEAM MUTED", "REVIEWS", "PRS")
+	for _, w := range weeks {
+		fmt.Fprintf(&b, "%-10s  %6d  %10d  %7d  %3d\n", w.Start.Format(time.DateOnly), w.Direct, w.TeamMuted, w.Reviews, w.ReviewedPRs)
+	}
+	if len(teams) > 0 {
+		parts := make([]string, len(teams))
+		for i, t := range teams {
+			parts[i] = fmt.Sprintf("%s %d", t.Team, t.Count)
+		}
+		fmt.Fprintf(&b, "\nTeam requests muted by team: %s\n", strings.Join(parts, ", "))
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReviewLoad(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	rec := func(id string, ago time.Duration, r MutationRecord) MutationRecord {
+		r.ThreadID, r.Time, r.Reason = id, now.Add(-ago), "review_requested"
+		return r
+	}
+	records := []MutationRecord{
+		rec("1", 1*day, MutationRecord{Kept: true, Direct: true}),
+		rec("1", 2*day, MutationRecord{Kept: true, Direct: true}), // same thread, counted once
+		rec("2", 3*day, MutationRecord{Kept: true}),               // kept through a team: not direct
+		rec("3", 1*day, MutationRecord{Teams: []string{"backend"}}),
+		rec("4", 2*day, MutationRecord{Teams: []string{"backend", "infra"}}),
+		rec("4", 2*day, MutationRecord{Undo: true}),
+		rec("5", 9*day, MutationRecord{Teams: []string{"infra"}}),
+		rec("6", 1*day, MutationRecord{Host: "ghes.example.com", Teams: []string{"backend"}}), // other host
+		{ThreadID: "7", Time: now.Add(-day)},                                                  // not a review request
+	}
+	reviews := []CompletedReview{
+		{PR: "org/a#1", Submitted: now.Add(-1 * day)},
+		{PR: "org/a#1", Submitted: now.Add(-2 * day)},
+		{PR: "org/b#2", Submitted: now.Add(-10 * day)},
+	}
+
+	got := ReviewLoad(records, reviews, "", now, 2)
+	want := []ReviewLoadWeek{
+		{Start: now.Add(-7 * day), End: now, Direct: 1, TeamMuted: 2, Reviews: 2, ReviewedPRs: 1},
+		{Start: now.Add(-14 * day), End: now.Add(-7 * day), TeamMuted: 1, Reviews: 1, ReviewedPRs: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReviewLoad() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	teams := MutedByTeam(records, "", now.Add(-14*day), now)
+	wantTeams := []TeamCount{{"backend", 2}, {"infra", 2}}
+	if !reflect.DeepEqual(teams, wantTeams) {
+		t.Errorf("MutedByTeam() = %+v, want %+v", teams, wantTeams)
+	}
+}
+
+func TestFormatReviewLoad(t *testing.T) {
+	start := time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC)
+	weeks := []ReviewLoadWeek{{Start: start, Direct: 7, TeamMuted: 42, Reviews: 5, ReviewedPRs: 4}}
+	got := FormatReviewLoad("octocat", weeks, []TeamCount{{"backend", 30}, {"frontend", 12}})
+	want := "Review load for octocat, last week\n\n" +
+		"WEEK OF     DIRECT  TEAM MUTED  REVIEWS  PRS\n" +
+		"2026-03-08       7          42        5    4\n" +
+		"\nTeam requests muted by team: backend 30, frontend 12\n"
+	if got != want {
+		t.Errorf("FormatReviewLoad() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,68 @@
+package core
+
+// MutationStep is a step in muting a thread.
+type MutationStep int
+
+const (
+	StepMark   MutationStep = iota // mark the thread read (or done)
+	StepIgnore                     // set ignored on the thread subscription
+)
+
+// MaxMutationAttempts is how many times a mute is tried before giving up.
+const MaxMutationAttempts = 5
+
+// PendingMutation is a mute that failed, to be retried from the step that failed.
+// A thread that was marked read but not ignored no longer shows up as unread,
+// so without a retry it would stay half-muted.
+type PendingMutation struct {
+	Decision Decision
+	Step     MutationStep
+	Attempts int
+}
+
+// RetryQueue holds failed mutes between attempts: until the end of a run, or
+// across daemon cycles.
+type RetryQueue struct {
+	items []PendingMutation
+}
+
+// Add records a failed attempt. It returns false, dropping the mutation, once
+// it has used up MaxMutationAttempts. A thread already queued is replaced, so
+// a fresh attempt from a later cycle supersedes the stale one.
+func (q *RetryQueue) Add(m PendingMutation) bool {
+	m.Attempts++
+	q.Remove(m.Decision.Notification.ID)
+	if m.Attempts >= MaxMutationAttempts {
+		return false
+	}
+	q.items = append(q.items, m)
+	return true
+}
+
+// Remove drops a thread from the queue, e.g. once it has been muted.
+func (q *RetryQueue) Remove(threadID string) {
+	kept := q.items[:0]
+	for _, m := range q.items {
+		if m.Decision.Notification.ID != threadID {
+			kept = append(kept, m)
+		}
+	}
+	q.items = kept
+}
+
+// Take empties the queue, returning its mutations in the order they failed.
+func (q *RetryQueue) Take() []PendingMutation {
+	items := q.items
+	q.items = nil
+	return items
+}
+
+// Len returns the number of queued mutations.
+func (q *RetryQueue) Len() int {
+	return len(q.items)
+}
+
+// Pending returns a copy of the queued mutations, in the order they failed.
+func (q *RetryQueue) Pending() []PendingMutation {
+	return append([]PendingMutation(nil), q.items...)
+}
@@ -0,0 +1,56 @@
+package core
+
+import "testing"
+
+func pending(id string, step MutationStep) PendingMutation {
+	return PendingMutation{Decision: Decision{Notification: Notification{ID: id}, Action: ActionMute}, Step: step}
+}
+
+func TestRetryQueue(t *testing.T) {
+	var q RetryQueue
+	if !q.Add(pending("1", StepMark)) || !q.Add(pending("2", StepIgnore)) {
+		t.Fatal("Add() = false for a first failure")
+	}
+	if q.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", q.Len())
+	}
+
+	items := q.Take()
+	if q.Len() != 0 || len(items) != 2 {
+		t.Fatalf("Take() = %d items, Len() after = %d", len(items), q.Len())
+	}
+	if items[0].Decision.Notification.ID != "1" || items[1].Step != StepIgnore || items[0].Attempts != 1 {
+		t.Errorf("Take() = %+v", items)
+	}
+}
+
+func TestRetryQueueGivesUp(t *testing.T) {
+	var q RetryQueue
+	m := pending("1", StepIgnore)
+	for i := 1; i < MaxMutationAttempts; i++ {
+		if !q.Add(m) {
+			t.Fatalf("Add() gave up after %d attempts, want %d", i, MaxMutationAttempts)
+		}
+		m = q.Take()[0]
+		if m.Attempts != i {
+			t.Fatalf("Attempts = %d, want %d", m.Attempts, i)
+		}
+	}
+	if q.Add(m) {
+		t.Errorf("Add() after %d attempts = true, want false", MaxMutationAttempts)
+	}
+	if q.Len() != 0 {
+		t.Errorf("Len() = %d after giving up, want 0", q.Len())
+	}
+}
+
+func TestRetryQueueReplacesThread(t *testing.T) {
+	var q RetryQueue
+	q.Add(pending("1", StepMark))
+	q.Add(pending("2", StepMark))
+	q.Add(pending("1", StepIgnore))
+	items := q.Take()
+	if len(items) != 2 || items[0].Decision.Notification.ID != "2" || items[1].Step != StepIgnore {
+		t.Errorf("Take() = %+v, want thread 2 then thread 1 at StepIgnore", items)
+	}
+}
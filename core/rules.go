@@ -0,0 +1,736 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PullRequest holds the PR details rules can match on. The shell only fetches
+// it when a rule references a pr.* field.
+type PullRequest struct {
+	Author    string
+	Draft     bool
+	State     string // "open" or "closed"
+	Labels    []string
+	Body      string
+	Assignees []string // logins
+	Head      string   // head branch, e.g. "renovate/react"
+	Base      string   // base branch, e.g. "main"
+}
+
+// Facts is the per-notification data the shell looked up for classification.
+// Any field may be nil when the lookup wasn't needed or failed.
+type Facts struct {
+	Reviewers *Reviewers
+	PR        *PullRequest
+	Files     []string // paths the PR changes
+	Topics    []string // repository topics; nil if not looked up
+	Language  string   // repository's primary language; empty if none or not looked up
+
+	// ReviewAuthors are the logins that have submitted a review on the PR, and
+	// TeamMembers the members of each requested team, by slug.
+	ReviewAuthors []string
+	TeamMembers   map[string][]string
+
+	// ReviewRequests are the PR's pending review requests, with whether each
+	// came from CODEOWNERS; nil if not looked up.
+	ReviewRequests []ReviewRequest
+
+	// CommentAuthors are the logins that have commented on the PR's
+	// conversation; nil if not looked up.
+	CommentAuthors []string
+
+	// ReviewDecision is the PR's GraphQL reviewDecision: "APPROVED",
+	// "CHANGES_REQUESTED", "REVIEW_REQUIRED", or empty when the base branch
+	// doesn't require reviews or it wasn't looked up.
+	ReviewDecision string
+
+	// Now is when the notification is decided, for time.* fields and defer.
+	Now time.Time
+}
+
+// RuleSpec is a rule as written in the config file, before parsing.
+type RuleSpec struct {
+	Name   string
+	When   string
+	Teams  []string
+	Action string
+}
+
+// Rule is a parsed rule: when all of its conditions match, Action is taken.
+type Rule struct {
+	Name   string
+	When   *Expr    // nil if the rule has no expression
+	Teams  []string // matches if any of these teams is a requested reviewer; empty means any
+	Action Action
+}
+
+// Matches reports whether every condition of the rule holds.
+func (r Rule) Matches(env ExprEnv) bool {
+	if len(r.Teams) > 0 && !anyTeamRequested(r.Teams, env.Facts.Reviewers) {
+		return false
+	}
+	return r.When == nil || r.When.Eval(env)
+}
+
+func anyTeamRequested(teams []string, reviewers *Reviewers) bool {
+	if reviewers == nil {
+		return false
+	}
+	for _, t := range reviewers.Teams {
+		if containsFold(teams, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	return slices.ContainsFunc(list, func(v string) bool { return strings.EqualFold(v, s) })
+}
+
+// usesField reports whether the rule reads a field or field prefix (see Expr.UsesField).
+func (r Rule) usesField(name string) bool {
+	if len(r.Teams) > 0 && (name == "reviewers." || name == "reviewers.teams") {
+		return true
+	}
+	return r.When != nil && r.When.UsesField(name)
+}
+
+// NeedsBusinessHours reports whether the rule defers or checks
+// time.business_hours, which do nothing without business hours configured.
+func (r Rule) NeedsBusinessHours() bool {
+	return r.Action == ActionDefer || r.usesField("time.business_hours")
+}
+
+// RuleError is a problem with one rule in the config. Field is the config key
+// at fault ("when", "teams", "action"), or empty for the rule as a whole.
+type RuleError struct {
+	Index int // 0-based position in the rules list
+	Name  string
+	Field string
+	Err   error
+}
+
+func (e *RuleError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("rule %s: %s", e.Name, e.Err)
+	}
+	return fmt.Sprintf("rule %s: %s: %s", e.Name, e.Field, e.Err)
+}
+
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+// ParseAction parses a rule action name.
+func ParseAction(s string) (Action, error) {
+	switch strings.ToLower(s) {
+	case "keep":
+		return ActionKeep, nil
+	case "mute":
+		return ActionMute, nil
+	case "skip":
+		return ActionSkip, nil
+	case "defer":
+		return ActionDefer, nil
+	case "dim", "read":
+		return ActionDim, nil
+	case "archive", "done":
+		return ActionArchive, nil
+	default:
+		return 0, fmt.Errorf("invalid action %q (valid values: keep, mute, skip, defer, dim or read, archive or done)", s)
+	}
+}
+
+// ActionSet is a set of actions, such as the rows --show prints.
+type ActionSet uint8
+
+// AllActions has every action.
+const AllActions ActionSet = 1<<ActionSkip | 1<<ActionKeep | 1<<ActionMute | 1<<ActionDefer | 1<<ActionDim | 1<<ActionArchive
+
+// Has reports whether a is in the set.
+func (s ActionSet) Has(a Action) bool {
+	return s&(1<<a) != 0
+}
+
+// ParseActionSet parses a comma-separated list of actions, like
+// "mute,keep". Empty or "all" is every action.
+func ParseActionSet(s string) (ActionSet, error) {
+	if strings.TrimSpace(s) == "" || strings.EqualFold(strings.TrimSpace(s), "all") {
+		return AllActions, nil
+	}
+	var set ActionSet
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		a, err := ParseAction(name)
+		if err != nil {
+			return 0, err
+		}
+		set |= 1 << a
+	}
+	if set == 0 {
+		return AllActions, nil
+	}
+	return set, nil
+}
+
+// CheckRules parses and type-checks rule specs, returning every problem found
+// rather than stopping at the first. Unnamed rules are named by their 1-based
+// position. The rules are only usable if no errors are returned.
+func CheckRules(specs []RuleSpec) ([]Rule, []*RuleError) {
+	var rules []Rule
+	var errs []*RuleError
+	for i, s := range specs {
+		name := s.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		fail := func(field string, err error) {
+			errs = append(errs, &RuleError{Index: i, Name: name, Field: field, Err: err})
+		}
+
+		r := Rule{Name: name, Teams: s.Teams}
+		if strings.TrimSpace(s.When) == "" && len(s.Teams) == 0 {
+			fail("", fmt.Errorf(`needs a "when" expression or a "teams" list`))
+		}
+		if strings.TrimSpace(s.When) != "" {
+			when, err := ParseExpr(s.When)
+			if err != nil {
+				fail("when", err)
+			}
+			r.When = when
+		}
+		for _, t := range s.Teams {
+			if strings.TrimSpace(t) == "" {
+				fail("teams", fmt.Errorf("empty team slug"))
+			}
+		}
+		action, err := ParseAction(s.Action)
+		if err != nil {
+			fail("action", err)
+		}
+		r.Action = action
+		rules = append(rules, r)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return rules, nil
+}
+
+// ParseRules is CheckRules for callers that only need the first problem.
+func ParseRules(specs []RuleSpec) ([]Rule, error) {
+	rules, errs := CheckRules(specs)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return rules, nil
+}
+
+// RuleConflicts finds rules that can never take effect as written: rules
+// shadowed by an earlier rule with the same expression that matches
+// everything they do, and teams listed in rules with the same expression but
+// different actions, where only the earlier rule will ever apply.
+func RuleConflicts(rules []Rule) []*RuleError {
+	var conflicts []*RuleError
+	for j, later := range rules {
+		for _, earlier := range rules[:j] {
+			if exprSource(earlier.When) != exprSource(later.When) {
+				continue
+			}
+			if len(earlier.Teams) == 0 || (len(later.Teams) > 0 && allFold(later.Teams, earlier.Teams)) {
+				conflicts = append(conflicts, &RuleError{Index: j, Name: later.Name, Err: fmt.Errorf("never matches: rule %s comes first and matches everything it does", earlier.Name)})
+				break
+			}
+			if earlier.Action == later.Action {
+				continue
+			}
+			for _, t := range later.Teams {
+				if containsFold(earlier.Teams, t) {
+					conflicts = append(conflicts, &RuleError{
+						Index: j,
+						Name:  later.Name,
+						Field: "teams",
+						Err:   fmt.Errorf("team %s is also in %s rule %s, which comes first and wins", t, strings.ToLower(earlier.Action.String()), earlier.Name),
+					})
+				}
+			}
+		}
+	}
+	return conflicts
+}
+
+func exprSource(e *Expr) string {
+	if e == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(e.String()), " ")
+}
+
+// allFold reports whether every element of sub is in set, ignoring case.
+func allFold(sub, set []string) bool {
+	for _, s := range sub {
+		if !containsFold(set, s) {
+			return false
+		}
+	}
+	return true
+}
+
+func rulesUseField(rules []Rule, name string) bool {
+	for _, r := range rules {
+		if r.usesField(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsPRLookup decides if a notification requires fetching PR details:
+// only for PRs passing the repo filter, and only if some rule reads pr.* fields
+// (other than pr.files, see NeedsFilesLookup), a keep_* setting needs them, or
+// waves are grouped.
+func NeedsPRLookup(n Notification, cfg Config) bool {
+	if n.Subject.Type != "PullRequest" || !MatchesRepoFilter(n, cfg) {
+		return false
+	}
+	if cfg.Waves || rulesUsePRDetails(cfg.Rules) {
+		return true
+	}
+	return n.Reason == "review_requested" && (len(cfg.KeepAuthors) > 0 || cfg.KeepMentions || cfg.KeepAssigned || cfg.DeadlineWithin > 0 || (cfg.Scoring != nil && cfg.Scoring.usesPR()))
+}
+
+// NeedsFilesLookup decides if a notification requires fetching the PR's
+// changed files: only for PRs passing the repo filter, and only if some rule
+// reads pr.files.
+func NeedsFilesLookup(n Notification, cfg Config) bool {
+	return n.Subject.Type == "PullRequest" && MatchesRepoFilter(n, cfg) && rulesUseField(cfg.Rules, "pr.files")
+}
+
+// NeedsReviewDecisionLookup decides if a notification requires fetching the
+// PR's review decision: for review requests when MuteApproved is set, and for
+// any PR if some rule reads pr.review_decision. Only PRs passing the repo filter.
+func NeedsReviewDecisionLookup(n Notification, cfg Config) bool {
+	if n.Subject.Type != "PullRequest" || !MatchesRepoFilter(n, cfg) {
+		return false
+	}
+	return rulesUseField(cfg.Rules, "pr.review_decision") || (cfg.MuteApproved && n.Reason == "review_requested")
+}
+
+// NeedsTeammateLookup decides if a notification requires fetching the PR's
+// reviews and the requested teams' members: for review requests passing the
+// org filter, when MuteTeammateReviewing is set.
+func NeedsTeammateLookup(n Notification, cfg Config) bool {
+	return cfg.MuteTeammateReviewing && n.Reason == "review_requested" && n.Subject.Type == "PullRequest" && MatchesRepoFilter(n, cfg)
+}
+
+// NeedsTeamMembersLookup decides if a notification requires fetching the
+// requested teams' members: as NeedsTeammateLookup, or for the same review
+// requests when TeamSizeThreshold is set.
+func NeedsTeamMembersLookup(n Notification, cfg Config) bool {
+	return NeedsTeammateLookup(n, cfg) ||
+		cfg.TeamSizeThreshold > 0 && n.Reason == "review_requested" && n.Subject.Type == "PullRequest" && MatchesRepoFilter(n, cfg)
+}
+
+// teamMembersKnown reports whether every requested team's members were
+// looked up.
+func teamMembersKnown(facts Facts) bool {
+	for _, t := range facts.Reviewers.Teams {
+		if facts.TeamMembers[t] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// SmallestTeam finds the smallest requested team whose members are known,
+// among those login belongs to; if login isn't in any (say, they're in a
+// child team), among all of them. ok is false if no member list is known.
+func SmallestTeam(facts Facts, login string) (team string, size int, ok bool) {
+	if facts.Reviewers == nil {
+		return "", 0, false
+	}
+	var mine, all []string
+	for _, t := range facts.Reviewers.Teams {
+		members, known := facts.TeamMembers[t]
+		if !known || members == nil {
+			continue
+		}
+		all = append(all, t)
+		if containsFold(members, login) {
+			mine = append(mine, t)
+		}
+	}
+	candidates := mine
+	if len(candidates) == 0 {
+		candidates = all
+	}
+	for _, t := range candidates {
+		if n := len(facts.TeamMembers[t]); !ok || n < size {
+			team, size, ok = t, n, true
+		}
+	}
+	return team, size, ok
+}
+
+// TeammateReviewing finds another member of a requested team who is already
+// on the PR: who has submitted a review, or who was requested individually.
+// It returns the teammate and the team they were found through.
+func TeammateReviewing(facts Facts, login string) (teammate, team string, ok bool) {
+	if facts.Reviewers == nil {
+		return "", "", false
+	}
+	for _, t := range facts.Reviewers.Teams {
+		for _, member := range facts.TeamMembers[t] {
+			if strings.EqualFold(member, login) {
+				continue
+			}
+			if containsFold(facts.ReviewAuthors, member) || containsFold(facts.Reviewers.Users, member) {
+				return member, t, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// rulesUsePRDetails reports whether any rule reads a field of the PR itself,
+// which pr.files and pr.review_decision, fetched separately, are not.
+func rulesUsePRDetails(rules []Rule) bool {
+	for _, r := range rules {
+		if r.usesPRDetails() {
+			return true
+		}
+	}
+	return false
+}
+
+// usesPRDetails reports whether the rule reads a field of the PR itself.
+func (r Rule) usesPRDetails() bool {
+	for _, name := range ExprFieldNames() {
+		if strings.HasPrefix(name, "pr.") && name != "pr.files" && name != "pr.review_decision" && r.usesField(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// missingFact returns why the rule can't be checked against facts, or "" if
+// it can: it reads something whose lookup failed. Evaluated anyway, it would
+// see no reviewers or a zero PR, and could mute a direct review request.
+// Reviewers, PR details, files, and review requests are only looked up for
+// pull requests.
+func (r Rule) missingFact(n Notification, facts Facts) string {
+	if r.usesField("repo.topics") && facts.Topics == nil {
+		return "no topic data"
+	}
+	if n.Subject.Type != "PullRequest" {
+		return ""
+	}
+	switch {
+	case r.usesField("reviewers.") && facts.Reviewers == nil:
+		return "no reviewer data"
+	case r.usesPRDetails() && facts.PR == nil:
+		return "no PR data"
+	case r.usesField("pr.files") && facts.Files == nil:
+		return "no file data"
+	case r.usesField("request.source") && facts.ReviewRequests == nil:
+		return "no review request data"
+	}
+	return ""
+}
+
+// MentionsLogin reports whether text @-mentions login, e.g. "cc @octocat".
+// The mention must stand alone: "@octocat-bot" and "me@octocat" don't count.
+func MentionsLogin(text, login string) bool {
+	if login == "" {
+		return false
+	}
+	lower, mention := strings.ToLower(text), "@"+strings.ToLower(login)
+	for i := 0; ; {
+		j := strings.Index(lower[i:], mention)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(mention)
+		if (start == 0 || !isLoginByte(lower[start-1])) && (end == len(lower) || !isLoginByte(lower[end])) {
+			return true
+		}
+		i = end
+	}
+}
+
+// isLoginByte reports whether c can appear in a GitHub login.
+func isLoginByte(c byte) bool {
+	return c == '-' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
+
+// Decide determines the action for a notification. Notifications from repos
+// excluded by the topic filter are skipped, as are all notifications when the
+// filter is set but topics couldn't be looked up. Then pinned threads, and review
+// requests from keep_authors or on PRs assigned to login, are kept; with
+// MuteApproved, team-only requests on approved PRs are muted, and with
+// MuteTeammateReviewing, those a teammate is already reviewing; then the first
+// matching rule wins, otherwise the built-in Classify logic applies. With
+// KeepMentions, a team-only request whose PR description @-mentions login is
+// kept instead of muted, as is one through a team of at most
+// TeamSizeThreshold members, or one due within DeadlineWithin. None of these
+// see notifications excluded by the org filter. A rule that reads facts whose
+// lookup failed skips the notification instead of matching.
+func Decide(n Notification, facts Facts, login string, cfg Config) Decision {
+	return withAuthor(decide(n, facts, login, cfg, func(string, string) {}), facts)
+}
+
+// ExplainStep is one check made in deciding a notification, and its outcome.
+type ExplainStep struct {
+	Check   string // e.g. "topic filter", "rule drafts"
+	Outcome string
+}
+
+// Explain decides like Decide, and also returns each check made along the
+// way, in order. The last step is the one that decided.
+func Explain(n Notification, facts Facts, login string, cfg Config) (Decision, []ExplainStep) {
+	var steps []ExplainStep
+	d := decide(n, facts, login, cfg, func(check, outcome string) {
+		steps = append(steps, ExplainStep{Check: check, Outcome: outcome})
+	})
+	return withAuthor(d, facts), steps
+}
+
+// withAuthor records the PR's author on d, when PR details were looked up.
+func withAuthor(d Decision, facts Facts) Decision {
+	if facts.PR != nil {
+		d.Author = facts.PR.Author
+	}
+	return d
+}
+
+// decide is Decide, reporting each check and its outcome to trace.
+func decide(n Notification, facts Facts, login string, cfg Config, trace func(check, outcome string)) Decision {
+	if !MatchesRepoFilter(n, cfg) {
+		trace("org and visibility filters", "filtered out")
+	} else {
+		trace("org and visibility filters", "pass")
+		if FiltersTopics(cfg) && facts.Topics == nil {
+			trace("topic filter", "no topic data")
+			return Decision{Notification: n, Action: ActionSkip, Reason: "no topic data"}
+		}
+		if !MatchesTopicFilter(facts.Topics, cfg) {
+			trace("topic filter", fmt.Sprintf("topics %s filtered out", formatList(facts.Topics)))
+			return Decision{Notification: n, Action: ActionSkip, Reason: "filtered by topic"}
+		}
+		if FiltersTopics(cfg) {
+			trace("topic filter", fmt.Sprintf("topics %s pass", formatList(facts.Topics)))
+		}
+		if len(cfg.Pinned) > 0 || len(cfg.Pins) > 0 {
+			if cfg.Pinned[n.ID] || IsPinned(n, cfg.Pins) {
+				trace("pinned", "yes")
+				return Decision{Notification: n, Action: ActionKeep, Reason: "pinned"}
+			}
+			trace("pinned", "no")
+		}
+		if cfg.PinParticipated && n.Subject.Type == "PullRequest" {
+			if how, ok := Participated(facts, login); ok {
+				trace("pin_participated", how)
+				return Decision{Notification: n, Action: ActionKeep, Reason: how, Pin: true}
+			}
+			trace("pin_participated", "you haven't reviewed or commented")
+		}
+		// Without the PR, the author or an assignment to login can't be ruled
+		// out: don't mute.
+		if n.Reason == "review_requested" && facts.PR == nil && (len(cfg.KeepAuthors) > 0 || cfg.KeepAssigned) {
+			trace("keep_authors and keep_assigned", "no PR data")
+			return Decision{Notification: n, Action: ActionSkip, Reason: "no PR data"}
+		}
+		if reason, ok := keepReason(n, facts.PR, login, cfg); ok {
+			trace("keep_authors and keep_assigned", reason)
+			d := Decision{Notification: n, Action: ActionKeep, Reason: reason}
+			if facts.Reviewers != nil {
+				d.Teams = facts.Reviewers.Teams
+			}
+			return d
+		} else if len(cfg.KeepAuthors) > 0 || cfg.KeepAssigned {
+			trace("keep_authors and keep_assigned", "no match")
+		}
+		teamOnly := isTeamOnlyRequest(n, facts.Reviewers, login)
+		if cfg.OnCall {
+			if team, ok := onCallTeam(facts.Reviewers, cfg); ok && teamOnly {
+				trace("oncall", "on call for "+team)
+				return Decision{Notification: n, Action: ActionKeep, Reason: "on call for " + team, Teams: facts.Reviewers.Teams}
+			}
+			trace("oncall", "no on-call team requested")
+		}
+		if cfg.MuteApproved {
+			switch {
+			case !teamOnly:
+				trace("mute_approved", "not a team-only review request")
+			case facts.ReviewDecision == "APPROVED":
+				trace("mute_approved", "PR is approved")
+				return Decision{Notification: n, Action: ActionMute, Reason: "already approved", Teams: facts.Reviewers.Teams}
+			case facts.ReviewDecision == "":
+				trace("mute_approved", "no review decision")
+			default:
+				trace("mute_approved", "review decision "+facts.ReviewDecision)
+			}
+		}
+		if cfg.MuteTeammateReviewing {
+			teammate, team, ok := TeammateReviewing(facts, login)
+			switch {
+			case !teamOnly:
+				trace("mute_teammate_reviewing", "not a team-only review request")
+			case ok:
+				reason := fmt.Sprintf("%s from %s is reviewing", teammate, team)
+				trace("mute_teammate_reviewing", reason)
+				return Decision{Notification: n, Action: ActionMute, Reason: reason, Teams: facts.Reviewers.Teams}
+			default:
+				trace("mute_teammate_reviewing", "no teammate is reviewing")
+			}
+		}
+		env := ExprEnv{Notification: n, Facts: facts, Login: login, Hours: cfg.BusinessHours}
+		for _, r := range cfg.Rules {
+			// Skip, as Classify does, rather than guess what the rule would say.
+			if missing := r.missingFact(n, facts); missing != "" {
+				trace("rule "+r.Name, missing)
+				return Decision{Notification: n, Action: ActionSkip, Reason: missing}
+			}
+			if r.Matches(env) {
+				var until time.Time
+				if r.Action == ActionDefer {
+					// Deferring only holds a notification outside business hours.
+					if until = deferUntil(facts.Now, cfg); until.IsZero() {
+						trace("rule "+r.Name, "matches: DEFER, but within business hours")
+						continue
+					}
+				}
+				trace("rule "+r.Name, "matches: "+r.Action.String())
+				d := Decision{Notification: n, Action: r.Action, Reason: fmt.Sprintf("rule %s", r.Name), Rule: r.Name, Until: until}
+				if facts.Reviewers != nil {
+					d.Teams = facts.Reviewers.Teams
+				}
+				return d
+			}
+			trace("rule "+r.Name, "no match")
+		}
+		if cfg.KeepFailedChecks && n.Subject.Type == "CheckSuite" {
+			if d, ok := checkSuiteDecision(n); ok {
+				trace("keep_failed_checks", d.Reason)
+				return d
+			}
+			trace("keep_failed_checks", "outcome not in title")
+		}
+	}
+	if cfg.Scoring != nil && n.Reason == "review_requested" && facts.Reviewers != nil && MatchesRepoFilter(n, cfg) {
+		d := cfg.Scoring.decide(n, facts, login)
+		trace("scoring", fmt.Sprintf("%s (%s)", d.Action, d.Reason))
+		return d
+	}
+	d := Classify(n, facts.Reviewers, login, cfg)
+	trace("built-in classification", fmt.Sprintf("%s (%s)", d.Action, d.Reason))
+	if d.Action == ActionMute && cfg.TeamSizeThreshold > 0 && isTeamOnlyRequest(n, facts.Reviewers, login) {
+		// With a team's members unknown, it might be small: don't mute.
+		team, size, ok := SmallestTeam(facts, login)
+		switch {
+		case !ok:
+			trace("team_size_threshold", "no team member data")
+			d.Action, d.Reason = ActionSkip, "no team member data"
+		case size <= cfg.TeamSizeThreshold:
+			trace("team_size_threshold", fmt.Sprintf("%s has size %d, at most %d", team, size, cfg.TeamSizeThreshold))
+			d.Action, d.Reason = ActionKeep, fmt.Sprintf("small team %s, size %d", team, size)
+		case !teamMembersKnown(facts):
+			trace("team_size_threshold", fmt.Sprintf("%s has size %d, over %d, but not every team's members are known", team, size, cfg.TeamSizeThreshold))
+			d.Action, d.Reason = ActionSkip, "no team member data"
+		default:
+			trace("team_size_threshold", fmt.Sprintf("%s has size %d, over %d", team, size, cfg.TeamSizeThreshold))
+		}
+	}
+	if d.Action == ActionMute && cfg.KeepMentions {
+		switch {
+		case facts.PR == nil:
+			// The description might mention login: don't mute.
+			trace("keep_mentions", "no PR data")
+			d.Action, d.Reason = ActionSkip, "no PR data"
+		case MentionsLogin(facts.PR.Body, login):
+			trace("keep_mentions", "PR description mentions @"+login)
+			d.Action, d.Reason = ActionKeep, "mentioned in PR description"
+		default:
+			trace("keep_mentions", "no mention")
+		}
+	}
+	if d.Action == ActionMute && cfg.DeadlineWithin > 0 && isTeamOnlyRequest(n, facts.Reviewers, login) {
+		loc := time.UTC
+		if cfg.BusinessHours != nil {
+			loc = cfg.BusinessHours.Location
+		}
+		if facts.PR == nil {
+			// The deadline might be close: don't mute.
+			trace("keep_deadline_days", "no PR data")
+			d.Action, d.Reason = ActionSkip, "no PR data"
+		} else if due, ok := ReviewDeadline(facts.PR, loc); !ok {
+			trace("keep_deadline_days", "no deadline")
+		} else if due.Sub(facts.Now) <= cfg.DeadlineWithin {
+			d.Action, d.Reason = ActionKeep, deadlineReason(due, facts.Now)
+			trace("keep_deadline_days", d.Reason)
+		} else {
+			trace("keep_deadline_days", deadlineReason(due, facts.Now)+", with slack")
+		}
+	}
+	return d
+}
+
+// deferUntil returns when a notification deferred at now should be decided
+// again, or the zero time if business hours are open (or not configured).
+func deferUntil(now time.Time, cfg Config) time.Time {
+	if cfg.BusinessHours == nil {
+		return time.Time{}
+	}
+	if open := cfg.BusinessHours.NextOpen(now); open.After(now) {
+		return open
+	}
+	return time.Time{}
+}
+
+// formatList renders a list for explanations, e.g. "[go, backend]".
+func formatList(list []string) string {
+	return "[" + strings.Join(list, ", ") + "]"
+}
+
+// FormatExplanation renders a decision from Explain: the notification, each
+// check made, one per line, and the decision.
+func FormatExplanation(d Decision, steps []ExplainStep) string {
+	width := 0
+	for _, s := range steps {
+		width = max(width, len(s.Check))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s  %q\n", formatLabel(d), d.Notification.Subject.Title)
+	for _, s := range steps {
+		fmt.Fprintf(&b, "  %-*s  %s\n", width, s.Check, s.Outcome)
+	}
+	fmt.Fprintf(&b, "=> %s (%s)\n", d.Action, d.Reason)
+	return b.String()
+}
+
+// isTeamOnlyRequest reports whether a notification is a review request in
+// which login was only requested through a team.
+func isTeamOnlyRequest(n Notification, reviewers *Reviewers, login string) bool {
+	return n.Reason == "review_requested" && reviewers != nil && !containsFold(reviewers.Users, login)
+}
+
+// keepReason reports whether a review request is kept before rules apply
+// because of who wrote or is assigned to the PR, and why.
+func keepReason(n Notification, pr *PullRequest, login string, cfg Config) (string, bool) {
+	if n.Reason != "review_requested" || pr == nil {
+		return "", false
+	}
+	if containsFold(cfg.KeepAuthors, pr.Author) {
+		return fmt.Sprintf("author %s is on keep_authors", pr.Author), true
+	}
+	if cfg.KeepAssigned && containsFold(pr.Assignees, login) {
+		return "assigned to you", true
+	}
+	return "", false
+}
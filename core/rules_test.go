@@ -0,0 +1,938 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func mustParseRules(t *testing.T, specs ...RuleSpec) []Rule {
+	t.Helper()
+	rules, err := ParseRules(specs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rules
+}
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []RuleSpec
+		wantErr string
+	}{
+		{name: "empty", specs: nil},
+		{name: "valid", specs: []RuleSpec{{Name: "drafts", When: "pr.draft", Action: "mute"}, {When: "true", Action: "KEEP"}}},
+		{name: "missing when", specs: []RuleSpec{{Name: "x", Action: "mute"}}, wantErr: `rule x: needs a "when" expression or a "teams" list`},
+		{name: "bad expr", specs: []RuleSpec{{Name: "x", When: "pr.nope", Action: "mute"}}, wantErr: "rule x: when: col 1: unknown field"},
+		{name: "bad action", specs: []RuleSpec{{When: "true", Action: "delete"}}, wantErr: `rule #1: action: invalid action "delete"`},
+		{name: "teams only", specs: []RuleSpec{{Teams: []string{"infra"}, Action: "mute"}}},
+		{name: "empty team", specs: []RuleSpec{{Teams: []string{" "}, Action: "mute"}}, wantErr: "rule #1: teams: empty team slug"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ParseRules(tt.specs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseRules() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRules() error = %v", err)
+			}
+			if len(rules) != len(tt.specs) {
+				t.Errorf("got %d rules, want %d", len(rules), len(tt.specs))
+			}
+		})
+	}
+}
+
+func TestParseRulesDefaultName(t *testing.T) {
+	rules := mustParseRules(t, RuleSpec{Name: "a", When: "true", Action: "keep"}, RuleSpec{When: "true", Action: "skip"})
+	if rules[1].Name != "#2" {
+		t.Errorf("Name = %q, want #2", rules[1].Name)
+	}
+	if rules[1].Action != ActionSkip {
+		t.Errorf("Action = %v, want SKIP", rules[1].Action)
+	}
+}
+
+func TestDecide(t *testing.T) {
+	n := Notification{
+		ID:         "1",
+		Reason:     "review_requested",
+		Subject:    Subject{Title: "Fix", URL: "https://api.github.com/repos/org/repo/pulls/1", Type: "PullRequest"},
+		Repository: Repository{FullName: "org/repo", Owner: "org"},
+	}
+	teamOnly := &Reviewers{Teams: []string{"platform-team"}}
+	direct := &Reviewers{Users: []string{"me"}, Teams: []string{"platform-team"}}
+	rules := mustParseRules(t,
+		RuleSpec{Name: "platform drafts", When: `"platform-team" in reviewers.teams && pr.draft`, Action: "keep"},
+		RuleSpec{Name: "bots", When: `pr.author.endsWith("[bot]")`, Action: "mute"},
+	)
+
+	tests := []struct {
+		name       string
+		n          Notification
+		facts      Facts
+		cfg        Config
+		wantAction Action
+		wantReason string
+	}{
+		{
+			name:       "first matching rule wins",
+			facts:      Facts{Reviewers: teamOnly, PR: &PullRequest{Draft: true, Author: "x[bot]"}},
+			cfg:        Config{Rules: rules},
+			wantAction: ActionKeep,
+			wantReason: "rule platform drafts",
+		},
+		{
+			name:       "second rule",
+			facts:      Facts{Reviewers: direct, PR: &PullRequest{Author: "x[bot]"}},
+			cfg:        Config{Rules: rules},
+			wantAction: ActionMute,
+			wantReason: "rule bots",
+		},
+		{
+			name:       "no rule matches falls back to classify",
+			facts:      Facts{Reviewers: direct, PR: &PullRequest{Author: "alice"}},
+			cfg:        Config{Rules: rules},
+			wantAction: ActionKeep,
+			wantReason: "direct review request",
+		},
+		{
+			name:       "no rules",
+			facts:      Facts{Reviewers: teamOnly},
+			wantAction: ActionMute,
+			wantReason: "team-only review request",
+		},
+		{
+			name:       "org filter beats rules",
+			facts:      Facts{Reviewers: teamOnly, PR: &PullRequest{Draft: true}},
+			cfg:        Config{ExcludeOrg: "org", Rules: rules},
+			wantAction: ActionSkip,
+			wantReason: "filtered by org",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Decide(n, tt.facts, "me", tt.cfg)
+			if got.Action != tt.wantAction || got.Reason != tt.wantReason {
+				t.Errorf("Decide() = %v (%s), want %v (%s)", got.Action, got.Reason, tt.wantAction, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestDecideRuleMissingFacts(t *testing.T) {
+	n := Notification{
+		ID:         "1",
+		Reason:     "review_requested",
+		Subject:    Subject{Title: "Fix", URL: "https://api.github.com/repos/org/repo/pulls/1", Type: "PullRequest"},
+		Repository: Repository{FullName: "org/repo", Owner: "org"},
+	}
+	direct := &Reviewers{Users: []string{"me"}}
+	tests := []struct {
+		name       string
+		spec       RuleSpec
+		n          Notification
+		facts      Facts
+		wantAction Action
+		wantReason string
+	}{
+		{
+			name:       "failed reviewer lookup",
+			spec:       RuleSpec{Name: "team only", When: `notification.reason == "review_requested" && !(login in reviewers.users)`, Action: "mute"},
+			facts:      Facts{},
+			wantAction: ActionSkip,
+			wantReason: "no reviewer data",
+		},
+		{
+			name:       "reviewers looked up",
+			spec:       RuleSpec{Name: "team only", When: `notification.reason == "review_requested" && !(login in reviewers.users)`, Action: "mute"},
+			facts:      Facts{Reviewers: direct},
+			wantAction: ActionKeep,
+			wantReason: "direct review request",
+		},
+		{
+			name:       "teams without reviewers",
+			spec:       RuleSpec{Name: "infra", Teams: []string{"infra"}, Action: "keep"},
+			facts:      Facts{},
+			wantAction: ActionSkip,
+			wantReason: "no reviewer data",
+		},
+		{
+			name:       "failed PR lookup",
+			spec:       RuleSpec{Name: "not mine", When: `pr.author != "me"`, Action: "mute"},
+			facts:      Facts{Reviewers: direct},
+			wantAction: ActionSkip,
+			wantReason: "no PR data",
+		},
+		{
+			name:       "failed files lookup",
+			spec:       RuleSpec{Name: "docs", When: `pr.files.allGlob("docs/**")`, Action: "dim"},
+			facts:      Facts{Reviewers: direct, PR: &PullRequest{}},
+			wantAction: ActionSkip,
+			wantReason: "no file data",
+		},
+		{
+			name:       "failed review requests lookup",
+			spec:       RuleSpec{Name: "owners", When: `request.source == "codeowners"`, Action: "mute"},
+			facts:      Facts{Reviewers: direct},
+			wantAction: ActionSkip,
+			wantReason: "no review request data",
+		},
+		{
+			name:       "failed topics lookup",
+			spec:       RuleSpec{Name: "legacy", When: `"legacy" in repo.topics`, Action: "mute"},
+			facts:      Facts{Reviewers: direct},
+			wantAction: ActionSkip,
+			wantReason: "no topic data",
+		},
+		{
+			name:       "no PR lookup for an issue",
+			spec:       RuleSpec{Name: "not mine", When: `pr.author != "me"`, Action: "mute"},
+			n:          Notification{Reason: "mention", Subject: Subject{Type: "Issue"}, Repository: Repository{FullName: "org/repo", Owner: "org"}},
+			facts:      Facts{},
+			wantAction: ActionMute,
+			wantReason: "rule not mine",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.n.Subject.Type == "" {
+				tt.n = n
+			}
+			got := Decide(tt.n, tt.facts, "me", Config{Rules: mustParseRules(t, tt.spec)})
+			if got.Action != tt.wantAction || got.Reason != tt.wantReason {
+				t.Errorf("Decide() = %v (%s), want %v (%s)", got.Action, got.Reason, tt.wantAction, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestDecideRuleRecordsTeams(t *testing.T) {
+	n := Notification{Reason: "mention", Subject: Subject{Type: "PullRequest"}, Repository: Repository{Owner: "org"}}
+	rules := mustParseRules(t, RuleSpec{Name: "all", When: "true", Action: "keep"})
+	got := Decide(n, Facts{Reviewers: &Reviewers{Teams: []string{"infra"}}}, "me", Config{Rules: rules})
+	if len(got.Teams) != 1 || got.Teams[0] != "infra" {
+		t.Errorf("Teams = %v, want [infra]", got.Teams)
+	}
+}
+
+func TestNeedsLookupsWithRules(t *testing.T) {
+	pr := Notification{Reason: "mention", Subject: Subject{Type: "PullRequest"}, Repository: Repository{Owner: "org"}}
+	issue := Notification{Reason: "mention", Subject: Subject{Type: "Issue"}, Repository: Repository{Owner: "org"}}
+	usesPR := Config{Rules: mustParseRules(t, RuleSpec{When: "pr.draft", Action: "mute"})}
+	usesReviewers := Config{Rules: mustParseRules(t, RuleSpec{When: `"x" in reviewers.teams`, Action: "mute"})}
+
+	tests := []struct {
+		name         string
+		n            Notification
+		cfg          Config
+		wantPR       bool
+		wantReviewer bool
+	}{
+		{name: "no rules", n: pr, cfg: Config{}},
+		{name: "pr rule", n: pr, cfg: usesPR, wantPR: true},
+		{name: "pr rule on issue", n: issue, cfg: usesPR},
+		{name: "reviewers rule", n: pr, cfg: usesReviewers, wantReviewer: true},
+		{name: "reviewers rule on issue", n: issue, cfg: usesReviewers},
+		{name: "filtered org", n: pr, cfg: Config{ExcludeOrg: "org", Rules: usesPR.Rules}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NeedsPRLookup(tt.n, tt.cfg); got != tt.wantPR {
+				t.Errorf("NeedsPRLookup() = %v, want %v", got, tt.wantPR)
+			}
+			if got := NeedsReviewerLookup(tt.n, tt.cfg); got != tt.wantReviewer {
+				t.Errorf("NeedsReviewerLookup() = %v, want %v", got, tt.wantReviewer)
+			}
+		})
+	}
+}
+
+func TestCheckRulesReportsEveryProblem(t *testing.T) {
+	_, errs := CheckRules([]RuleSpec{
+		{Name: "a", When: "pr.nope", Action: "zap"},
+		{Name: "b", When: "true", Action: "keep"},
+		{Name: "c", Action: "mute"},
+	})
+	var got []string
+	for _, e := range errs {
+		got = append(got, fmt.Sprintf("%d/%s", e.Index, e.Field))
+	}
+	want := []string{"0/when", "0/action", "2/"}
+	if !slices.Equal(got, want) {
+		t.Errorf("errors at %v, want %v", got, want)
+	}
+
+	var exprErr *ExprError
+	if !errors.As(errs[0], &exprErr) || exprErr.Col != 1 {
+		t.Errorf("errs[0] = %v, want an *ExprError at col 1", errs[0])
+	}
+}
+
+func TestRuleMatchesTeams(t *testing.T) {
+	rules := mustParseRules(t,
+		RuleSpec{Teams: []string{"Platform"}, Action: "mute"},
+		RuleSpec{Teams: []string{"infra"}, When: "pr.draft", Action: "mute"},
+	)
+	tests := []struct {
+		name  string
+		rule  Rule
+		facts Facts
+		want  bool
+	}{
+		{name: "team requested, case-insensitive", rule: rules[0], facts: Facts{Reviewers: &Reviewers{Teams: []string{"platform"}}}, want: true},
+		{name: "other team", rule: rules[0], facts: Facts{Reviewers: &Reviewers{Teams: []string{"web"}}}},
+		{name: "no reviewer data", rule: rules[0]},
+		{name: "team and expression", rule: rules[1], facts: Facts{Reviewers: &Reviewers{Teams: []string{"infra"}}, PR: &PullRequest{Draft: true}}, want: true},
+		{name: "team but not expression", rule: rules[1], facts: Facts{Reviewers: &Reviewers{Teams: []string{"infra"}}, PR: &PullRequest{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(ExprEnv{Facts: tt.facts}); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !NeedsReviewerLookup(Notification{Reason: "mention", Subject: Subject{Type: "PullRequest"}}, Config{Rules: rules[:1]}) {
+		t.Error("NeedsReviewerLookup() = false for a teams rule, want true")
+	}
+}
+
+func TestRuleConflicts(t *testing.T) {
+	tests := []struct {
+		name  string
+		specs []RuleSpec
+		want  []string
+	}{
+		{
+			name: "no conflicts",
+			specs: []RuleSpec{
+				{Name: "a", Teams: []string{"platform"}, Action: "keep"},
+				{Name: "b", Teams: []string{"web"}, Action: "mute"},
+				{Name: "c", When: "pr.draft", Action: "mute"},
+			},
+		},
+		{
+			name: "same team in keep and mute",
+			specs: []RuleSpec{
+				{Name: "a", Teams: []string{"platform", "infra"}, Action: "keep"},
+				{Name: "b", Teams: []string{"Platform", "web"}, Action: "mute"},
+			},
+			want: []string{"rule b: teams: team Platform is also in keep rule a, which comes first and wins"},
+		},
+		{
+			name: "same team, same action",
+			specs: []RuleSpec{
+				{Name: "a", Teams: []string{"platform", "infra"}, Action: "mute"},
+				{Name: "b", Teams: []string{"platform", "web"}, Action: "mute"},
+			},
+		},
+		{
+			name: "different expressions don't conflict",
+			specs: []RuleSpec{
+				{Name: "a", Teams: []string{"platform"}, When: "pr.draft", Action: "keep"},
+				{Name: "b", Teams: []string{"platform"}, Action: "mute"},
+			},
+		},
+		{
+			name: "shadowed by subset",
+			specs: []RuleSpec{
+				{Name: "a", Teams: []string{"platform", "infra"}, Action: "keep"},
+				{Name: "b", Teams: []string{"infra"}, Action: "mute"},
+			},
+			want: []string{"rule b: never matches: rule a comes first and matches everything it does"},
+		},
+		{
+			name: "shadowed by identical expression",
+			specs: []RuleSpec{
+				{Name: "a", When: `pr.draft  &&  pr.state == "open"`, Action: "keep"},
+				{Name: "b", When: `pr.draft && pr.state == "open"`, Action: "mute"},
+			},
+			want: []string{"rule b: never matches: rule a comes first and matches everything it does"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range RuleConflicts(mustParseRules(t, tt.specs...)) {
+				got = append(got, e.Error())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("RuleConflicts() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecidePinned(t *testing.T) {
+	n := Notification{
+		ID:         "7",
+		Reason:     "review_requested",
+		Subject:    Subject{Type: "PullRequest"},
+		Repository: Repository{Owner: "org"},
+	}
+	muteAll := mustParseRules(t, RuleSpec{Name: "all", When: "true", Action: "mute"})
+	pinned := map[string]bool{"7": true}
+
+	tests := []struct {
+		name       string
+		cfg        Config
+		wantAction Action
+		wantReason string
+	}{
+		{name: "pin beats rules", cfg: Config{Rules: muteAll, Pinned: pinned}, wantAction: ActionKeep, wantReason: "pinned"},
+		{name: "pin beats team-only", cfg: Config{Pinned: pinned}, wantAction: ActionKeep, wantReason: "pinned"},
+		{name: "org filter beats pin", cfg: Config{ExcludeOrg: "org", Pinned: pinned}, wantAction: ActionSkip, wantReason: "filtered by org"},
+		{name: "other thread pinned", cfg: Config{Rules: muteAll, Pinned: map[string]bool{"8": true}}, wantAction: ActionMute, wantReason: "rule all"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Decide(n, Facts{Reviewers: &Reviewers{Teams: []string{"x"}}}, "me", tt.cfg)
+			if got.Action != tt.wantAction || got.Reason != tt.wantReason {
+				t.Errorf("Decide() = %v (%s), want %v (%s)", got.Action, got.Reason, tt.wantAction, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestDecideKeepAuthors(t *testing.T) {
+	n := Notification{
+		ID:         "7",
+		Reason:     "review_requested",
+		Subject:    Subject{Type: "PullRequest"},
+		Repository: Repository{Owner: "org"},
+	}
+	mention := n
+	mention.Reason = "mention"
+	muteAll := mustParseRules(t, RuleSpec{Name: "all", When: "true", Action: "mute"})
+	keep := []string{"Boss"}
+
+	tests := []struct {
+		name       string
+		n          Notification
+		cfg        Config
+		author     string
+		wantAction Action
+		wantReason string
+	}{
+		{name: "author beats team-only", n: n, cfg: Config{KeepAuthors: keep}, author: "boss", wantAction: ActionKeep, wantReason: "author boss is on keep_authors"},
+		{name: "author beats rules", n: n, cfg: Config{Rules: muteAll, KeepAuthors: keep}, author: "boss", wantAction: ActionKeep, wantReason: "author boss is on keep_authors"},
+		{name: "other author", n: n, cfg: Config{KeepAuthors: keep}, author: "someone", wantAction: ActionMute, wantReason: "team-only review request"},
+		{name: "not a review request", n: mention, cfg: Config{Rules: muteAll, KeepAuthors: keep}, author: "boss", wantAction: ActionMute, wantReason: "rule all"},
+		{name: "org filter beats author", n: n, cfg: Config{ExcludeOrg: "org", KeepAuthors: keep}, author: "boss", wantAction: ActionSkip, wantReason: "filtered by org"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facts := Facts{Reviewers: &Reviewers{Teams: []string{"x"}}, PR: &PullRequest{Author: tt.author}}
+			got := Decide(tt.n, facts, "me", tt.cfg)
+			if got.Action != tt.wantAction || got.Reason != tt.wantReason {
+				t.Errorf("Decide() = %v (%s), want %v (%s)", got.Action, got.Reason, tt.wantAction, tt.wantReason)
+			}
+		})
+	}
+
+	// A failed PR lookup could hide an author on keep_authors.
+	noPR := Facts{Reviewers: &Reviewers{Teams: []string{"x"}}}
+	if got := Decide(n, noPR, "me", Config{Rules: muteAll, KeepAuthors: keep}); got.Action != ActionSkip || got.Reason != "no PR data" {
+		t.Errorf("Decide(no PR data) = %v (%s), want SKIP (no PR data)", got.Action, got.Reason)
+	}
+
+	cfg := Config{KeepAuthors: keep}
+	if !NeedsPRLookup(n, cfg) {
+		t.Error("NeedsPRLookup() = false for a review request with keep_authors, want true")
+	}
+	if NeedsPRLookup(mention, cfg) {
+		t.Error("NeedsPRLookup() = true for a mention with keep_authors, want false")
+	}
+}
+
+func TestMentionsLogin(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"@octocat please review", true},
+		{"cc @OctoCat.", true},
+		{"(@octocat)", true},
+		{"thanks\n@octocat", true},
+		{"@octocat-bot", false},
+		{"mail me@octocat.com", false},
+		{"octocat", false},
+		{"@octo", false},
+		{"@octocat2 and @octocat", true},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := MentionsLogin(tt.text, "octocat"); got != tt.want {
+			t.Errorf("MentionsLogin(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+	if MentionsLogin("@ hi", "") {
+		t.Error("MentionsLogin() = true for an empty login, want false")
+	}
+}
+
+func TestDecideKeepMentions(t *testing.T) {
+	n := Notification{
+		ID:         "7",
+		Reason:     "review_requested",
+		Subject:    Subject{Type: "PullRequest"},
+		Repository: Repository{Owner: "org"},
+	}
+	muteInfra := mustParseRules(t, RuleSpec{Name: "mute infra", Teams: []string{"infra"}, Action: "mute"})
+
+	tests := []struct {
+		name       string
+		cfg        Config
+		body       string
+		wantAction Action
+		wantReason string
+	}{
+		{name: "mentioned", cfg: Config{KeepMentions: true}, body: "@me can you look?", wantAction: ActionKeep, wantReason: "mentioned in PR description"},
+		{name: "not mentioned", cfg: Config{KeepMentions: true}, body: "@someone can you look?", wantAction: ActionMute, wantReason: "team-only review request"},
+		{name: "disabled", cfg: Config{}, body: "@me can you look?", wantAction: ActionMute, wantReason: "team-only review request"},
+		{name: "rule wins", cfg: Config{Rules: muteInfra, KeepMentions: true}, body: "@me can you look?", wantAction: ActionMute, wantReason: "rule mute infra"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facts := Facts{Reviewers: &Reviewers{Teams: []string{"infra"}}, PR: &PullRequest{Body: tt.body}}
+			got := Decide(n, facts, "me", tt.cfg)
+			if got.Action != tt.wantAction || got.Reason != tt.wantReason {
+				t.Errorf("Decide() = %v (%s), want %v (%s)", got.Action, got.Reason, tt.wantAction, tt.wantReason)
+			}
+		})
+	}
+
+	noPR := Facts{Reviewers: &Reviewers{Teams: []string{"infra"}}}
+	if got := Decide(n, noPR, "me", Config{KeepMentions: true}); got.Action != ActionSkip || got.Reason != "no PR data" {
+		t.Errorf("Decide(no PR data) = %v (%s), want SKIP (no PR data)", got.Action, got.Reason)
+	}
+
+	if !NeedsPRLookup(n, Config{KeepMentions: true}) {
+		t.Error("NeedsPRLookup() = false for a review request with KeepMentions, want true")
+	}
+}
+
+func TestDecideKeepAssigned(t *testing.T) {
+	n := Notification{
+		ID:         "7",
+		Reason:     "review_requested",
+		Subject:    Subject{Type: "PullRequest"},
+		Repository: Repository{Owner: "org"},
+	}
+	muteAll := mustParseRules(t, RuleSpec{Name: "all", When: "true", Action: "mute"})
+
+	tests := []struct {
+		name       string
+		cfg        Config
+		assignees  []string
+		wantAction Action
+		wantReason string
+	}{
+		{name: "assigned beats team-only", cfg: Config{KeepAssigned: true}, assignees: []string{"Me"}, wantAction: ActionKeep, wantReason: "assigned to you"},
+		{name: "assigned beats rules", cfg: Config{Rules: muteAll, KeepAssigned: true}, assignees: []string{"other", "me"}, wantAction: ActionKeep, wantReason: "assigned to you"},
+		{name: "assigned to someone else", cfg: Config{KeepAssigned: true}, assignees: []string{"other"}, wantAction: ActionMute, wantReason: "team-only review request"},
+		{name: "disabled", cfg: Config{}, assignees: []string{"me"}, wantAction: ActionMute, wantReason: "team-only review request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facts := Facts{Reviewers: &Reviewers{Teams: []string{"x"}}, PR: &PullRequest{Assignees: tt.assignees}}
+			got := Decide(n, facts, "me", tt.cfg)
+			if got.Action != tt.wantAction || got.Reason != tt.wantReason {
+				t.Errorf("Decide() = %v (%s), want %v (%s)", got.Action, got.Reason, tt.wantAction, tt.wantReason)
+			}
+		})
+	}
+
+	// A failed PR lookup could hide an assignment.
+	noPR := Facts{Reviewers: &Reviewers{Teams: []string{"x"}}}
+	if got := Decide(n, noPR, "me", Config{KeepAssigned: true}); got.Action != ActionSkip || got.Reason != "no PR data" {
+		t.Errorf("Decide(no PR data) = %v (%s), want SKIP (no PR data)", got.Action, got.Reason)
+	}
+
+	if !NeedsPRLookup(n, Config{KeepAssigned: true}) {
+		t.Error("NeedsPRLookup() = false for a review request with KeepAssigned, want true")
+	}
+}
+
+func TestNeedsFilesLookup(t *testing.T) {
+	pr := Notification{Reason: "review_requested", Subject: Subject{Type: "PullRequest"}, Repository: Repository{Owner: "org"}}
+	issue := Notification{Reason: "review_requested", Subject: Subject{Type: "Issue"}, Repository: Repository{Owner: "org"}}
+	usesFiles := Config{Rules: mustParseRules(t, RuleSpec{When: `pr.files.allGlob("docs/**")`, Action: "mute"})}
+
+	if !NeedsFilesLookup(pr, usesFiles) {
+		t.Error("NeedsFilesLookup() = false for a PR with a pr.files rule, want true")
+	}
+	if NeedsFilesLookup(issue, usesFiles) {
+		t.Error("NeedsFilesLookup() = true for an issue, want false")
+	}
+	if NeedsFilesLookup(pr, Config{}) {
+		t.Error("NeedsFilesLookup() = true with no rules, want false")
+	}
+	if NeedsPRLookup(pr, usesFiles) {
+		t.Error("NeedsPRLookup() = true for a rule that only reads pr.files, want false")
+	}
+}
+
+func TestDecideMuteApproved(t *testing.T) {
+	n := Notification{
+		ID:         "7",
+		Reason:     "review_requested",
+		Subject:    Subject{Type: "PullRequest"},
+		Repository: Repository{Owner: "org"},
+	}
+	keepTeam := mustParseRules(t, RuleSpec{Name: "my team", Teams: []string{"backend"}, Action: "keep"})
+
+	tests := []struct {
+		name       string
+		cfg        Config
+		decision   string
+		users      []string
+		wantAction Action
+		wantReason string
+	}{
+		{name: "approved beats keep rule", cfg: Config{Rules: keepTeam, MuteApproved: true}, decision: "APPROVED", wantAction: ActionMute, wantReason: "already approved"},
+		{name: "not approved", cfg: Config{Rules: keepTeam, MuteApproved: true}, decision: "REVIEW_REQUIRED", wantAction: ActionKeep, wantReason: "rule my team"},
+		{name: "direct request", cfg: Config{Rules: keepTeam, MuteApproved: true}, decision: "APPROVED", users: []string{"me"}, wantAction: ActionKeep, wantReason: "rule my team"},
+		{name: "disabled", cfg: Config{Rules: keepTeam}, decision: "APPROVED", wantAction: ActionKeep, wantReason: "rule my team"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facts := Facts{Reviewers: &Reviewers{Users: tt.users, Teams: []string{"backend"}}, ReviewDecision: tt.decision}
+			got := Decide(n, facts, "me", tt.cfg)
+			if got.Action != tt.wantAction || got.Reason != tt.wantReason {
+				t.Errorf("Decide() = %v (%s), want %v (%s)", got.Action, got.Reason, tt.wantAction, tt.wantReason)
+			}
+		})
+	}
+
+	if !NeedsReviewDecisionLookup(n, Config{MuteApproved: true}) {
+		t.Error("NeedsReviewDecisionLookup() = false for a review request with MuteApproved, want true")
+	}
+	usesDecision := Config{Rules: mustParseRules(t, RuleSpec{When: `pr.review_decision == "APPROVED"`, Action: "mute"})}
+	if !NeedsReviewDecisionLookup(n, usesDecision) {
+		t.Error("NeedsReviewDecisionLookup() = false for a pr.review_decision rule, want true")
+	}
+	if NeedsPRLookup(n, usesDecision) {
+		t.Error("NeedsPRLookup() = true for a rule that only reads pr.review_decision, want false")
+	}
+}
+
+func TestDecideMuteTeammateReviewing(t *testing.T) {
+	n := Notification{
+		ID:         "7",
+		Reason:     "review_requested",
+		Subject:    Subject{Type: "PullRequest"},
+		Repository: Repository{Owner: "org"},
+	}
+	keepTeam := mustParseRules(t, RuleSpec{Name: "my team", Teams: []string{"backend"}, Action: "keep"})
+	members := map[string][]string{"backend": {"me", "alice", "bob"}}
+
+	tests := []struct {
+		name       string
+		cfg        Config
+		users      []string
+		authors    []string
+		wantAction Action
+		wantReason string
+	}{
+		{name: "teammate reviewed", cfg: Config{Rules: keepTeam, MuteTeammateReviewing: true}, authors: []string{"carol", "Bob"}, wantAction: ActionMute, wantReason: "bob from backend is reviewing"},
+		{name: "teammate requested", cfg: Config{Rules: keepTeam, MuteTeammateReviewing: true}, users: []string{"alice"}, wantAction: ActionMute, wantReason: "alice from backend is reviewing"},
+		{name: "outsider reviewed", cfg: Config{Rules: keepTeam, MuteTeammateReviewing: true}, authors: []string{"carol"}, wantAction: ActionKeep, wantReason: "rule my team"},
+		{name: "only I reviewed", cfg: Config{Rules: keepTeam, MuteTeammateReviewing: true}, authors: []string{"me"}, wantAction: ActionKeep, wantReason: "rule my team"},
+		{name: "direct request", cfg: Config{Rules: keepTeam, MuteTeammateReviewing: true}, users: []string{"me", "alice"}, wantAction: ActionKeep, wantReason: "rule my team"},
+		{name: "disabled", cfg: Config{Rules: keepTeam}, authors: []string{"bob"}, wantAction: ActionKeep, wantReason: "rule my team"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facts := Facts{Reviewers: &Reviewers{Users: tt.users, Teams: []string{"backend"}}, ReviewAuthors: tt.authors, TeamMembers: members}
+			got := Decide(n, facts, "me", tt.cfg)
+			if got.Action != tt.wantAction || got.Reason != tt.wantReason {
+				t.Errorf("Decide() = %v (%s), want %v (%s)", got.Action, got.Reason, tt.wantAction, tt.wantReason)
+			}
+		})
+	}
+
+	if !NeedsTeammateLookup(n, Config{MuteTeammateReviewing: true}) {
+		t.Error("NeedsTeammateLookup() = false for a review request with MuteTeammateReviewing, want true")
+	}
+	if NeedsTeammateLookup(n, Config{}) {
+		t.Error("NeedsTeammateLookup() = true without MuteTeammateReviewing, want false")
+	}
+}
+
+func TestDecideTeamSizeThreshold(t *testing.T) {
+	n := Notification{
+		ID:         "7",
+		Reason:     "review_requested",
+		Subject:    Subject{Type: "PullRequest"},
+		Repository: Repository{Owner: "org"},
+	}
+	members := map[string][]string{
+		"backend":  {"me", "alice", "bob", "carol"},
+		"platform": {"me", "dave"},
+		"infra":    {"erin"},
+	}
+
+	tests := []struct {
+		name       string
+		threshold  int
+		teams      []string
+		members    map[string][]string
+		wantAction Action
+		wantReason string
+	}{
+		{name: "small team", threshold: 3, teams: []string{"platform"}, members: members, wantAction: ActionKeep, wantReason: "small team platform, size 2"},
+		{name: "at threshold", threshold: 4, teams: []string{"backend"}, members: members, wantAction: ActionKeep, wantReason: "small team backend, size 4"},
+		{name: "large team", threshold: 3, teams: []string{"backend"}, members: members, wantAction: ActionMute, wantReason: "team-only review request"},
+		{name: "smallest of my teams", threshold: 2, teams: []string{"backend", "platform", "infra"}, members: members, wantAction: ActionKeep, wantReason: "small team platform, size 2"},
+		{name: "not in a requested team", threshold: 1, teams: []string{"infra"}, members: members, wantAction: ActionKeep, wantReason: "small team infra, size 1"},
+		{name: "members unknown", threshold: 3, teams: []string{"platform"}, members: map[string][]string{"platform": nil}, wantAction: ActionSkip, wantReason: "no team member data"},
+		{name: "no member data", threshold: 3, teams: []string{"platform"}, wantAction: ActionSkip, wantReason: "no team member data"},
+		{name: "one team's members unknown", threshold: 3, teams: []string{"backend", "platform"}, members: map[string][]string{"backend": members["backend"]}, wantAction: ActionSkip, wantReason: "no team member data"},
+		{name: "disabled", teams: []string{"platform"}, members: members, wantAction: ActionMute, wantReason: "team-only review request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facts := Facts{Reviewers: &Reviewers{Teams: tt.teams}, TeamMembers: tt.members}
+			got := Decide(n, facts, "me", Config{TeamSizeThreshold: tt.threshold})
+			if got.Action != tt.wantAction || got.Reason != tt.wantReason {
+				t.Errorf("Decide() = %v (%s), want %v (%s)", got.Action, got.Reason, tt.wantAction, tt.wantReason)
+			}
+		})
+	}
+
+	if !NeedsTeamMembersLookup(n, Config{TeamSizeThreshold: 3}) {
+		t.Error("NeedsTeamMembersLookup() = false for a review request with TeamSizeThreshold, want true")
+	}
+	if NeedsTeamMembersLookup(n, Config{}) {
+		t.Error("NeedsTeamMembersLookup() = true without TeamSizeThreshold or MuteTeammateReviewing, want false")
+	}
+}
+
+func TestDecideTopicFilter(t *testing.T) {
+	n := Notification{
+		ID:         "7",
+		Reason:     "review_requested",
+		Subject:    Subject{Type: "PullRequest"},
+		Repository: Repository{Owner: "org"},
+	}
+	pinned := map[string]bool{"7": true}
+
+	tests := []struct {
+		name       string
+		cfg        Config
+		topics     []string
+		wantAction Action
+		wantReason string
+	}{
+		{name: "no filter", cfg: Config{}, wantAction: ActionMute, wantReason: "team-only review request"},
+		{name: "excluded", cfg: Config{ExcludeTopics: []string{"deprecated"}}, topics: []string{"go", "Deprecated"}, wantAction: ActionSkip, wantReason: "filtered by topic"},
+		{name: "not excluded", cfg: Config{ExcludeTopics: []string{"deprecated"}}, topics: []string{"go"}, wantAction: ActionMute, wantReason: "team-only review request"},
+		{name: "included", cfg: Config{IncludeTopics: []string{"team-platform", "team-infra"}}, topics: []string{"team-infra"}, wantAction: ActionMute, wantReason: "team-only review request"},
+		{name: "not included", cfg: Config{IncludeTopics: []string{"team-platform"}}, topics: []string{}, wantAction: ActionSkip, wantReason: "filtered by topic"},
+		{name: "exclude beats include", cfg: Config{IncludeTopics: []string{"team-platform"}, ExcludeTopics: []string{"deprecated"}}, topics: []string{"team-platform", "deprecated"}, wantAction: ActionSkip, wantReason: "filtered by topic"},
+		{name: "topic filter beats pin", cfg: Config{ExcludeTopics: []string{"deprecated"}, Pinned: pinned}, topics: []string{"deprecated"}, wantAction: ActionSkip, wantReason: "filtered by topic"},
+		{name: "lookup failed", cfg: Config{ExcludeTopics: []string{"deprecated"}}, topics: nil, wantAction: ActionSkip, wantReason: "no topic data"},
+		{name: "org filter first", cfg: Config{ExcludeOrg: "org", ExcludeTopics: []string{"deprecated"}}, topics: nil, wantAction: ActionSkip, wantReason: "filtered by org"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facts := Facts{Reviewers: &Reviewers{Teams: []string{"x"}}, Topics: tt.topics}
+			got := Decide(n, facts, "me", tt.cfg)
+			if got.Action != tt.wantAction || got.Reason != tt.wantReason {
+				t.Errorf("Decide() = %v (%s), want %v (%s)", got.Action, got.Reason, tt.wantAction, tt.wantReason)
+			}
+		})
+	}
+
+	if !NeedsTopicsLookup(n, Config{IncludeTopics: []string{"x"}}) {
+		t.Error("NeedsTopicsLookup() = false with a topic filter, want true")
+	}
+	usesTopics := Config{Rules: mustParseRules(t, RuleSpec{When: `"archived-soon" in repo.topics`, Action: "mute"})}
+	if !NeedsTopicsLookup(n, usesTopics) {
+		t.Error("NeedsTopicsLookup() = false for a repo.topics rule, want true")
+	}
+	if NeedsTopicsLookup(n, Config{}) {
+		t.Error("NeedsTopicsLookup() = true with no filter or rule, want false")
+	}
+}
+
+func TestDecideRepoLanguage(t *testing.T) {
+	n := Notification{ID: "1", Reason: "review_requested", Subject: Subject{Type: "PullRequest"}, Repository: Repository{Owner: "org"}}
+	cfg := Config{Rules: mustParseRules(t,
+		RuleSpec{Name: "my-area", When: `repo.language in ["Go", "HCL"]`, Action: "keep"},
+		RuleSpec{Name: "frontend", When: `repo.language == "TypeScript"`, Action: "mute"},
+	)}
+	team := &Reviewers{Teams: []string{"x"}}
+	tests := []struct {
+		language   string
+		wantAction Action
+		wantReason string
+	}{
+		{"Go", ActionKeep, "rule my-area"},
+		{"TypeScript", ActionMute, "rule frontend"},
+		{"", ActionMute, "team-only review request"},
+	}
+	for _, tt := range tests {
+		got := Decide(n, Facts{Reviewers: team, Language: tt.language}, "me", cfg)
+		if got.Action != tt.wantAction || got.Reason != tt.wantReason {
+			t.Errorf("language %q: Decide() = %v (%s), want %v (%s)", tt.language, got.Action, got.Reason, tt.wantAction, tt.wantReason)
+		}
+	}
+
+	if !NeedsLanguageLookup(n, cfg) {
+		t.Error("NeedsLanguageLookup() = false for a repo.language rule, want true")
+	}
+	if NeedsLanguageLookup(n, Config{}) {
+		t.Error("NeedsLanguageLookup() = true with no rule, want false")
+	}
+	if NeedsLanguageLookup(n, Config{Rules: cfg.Rules, ExcludeOrg: "org"}) {
+		t.Error("NeedsLanguageLookup() = true for a filtered org, want false")
+	}
+}
+
+func TestExplain(t *testing.T) {
+	n := Notification{
+		ID:         "7",
+		Reason:     "review_requested",
+		Subject:    Subject{Type: "PullRequest"},
+		Repository: Repository{Owner: "org"},
+	}
+	drafts := mustParseRules(t, RuleSpec{Name: "drafts", When: "pr.draft", Action: "mute"})
+	teamOnly := &Reviewers{Teams: []string{"backend"}}
+
+	tests := []struct {
+		name  string
+		facts Facts
+		cfg   Config
+		want  []string // "check: outcome"
+	}{
+		{
+			name:  "built-in classification",
+			facts: Facts{Reviewers: teamOnly},
+			want: []string{
+				"org and visibility filters: pass",
+				"built-in classification: MUTE (team-only review request)",
+			},
+		},
+		{
+			name:  "filtered by org",
+			facts: Facts{Reviewers: teamOnly},
+			cfg:   Config{ExcludeOrg: "org", Rules: drafts},
+			want: []string{
+				"org and visibility filters: filtered out",
+				"built-in classification: SKIP (filtered by org)",
+			},
+		},
+		{
+			name:  "rules in order, then keep_mentions",
+			facts: Facts{Reviewers: teamOnly, PR: &PullRequest{Body: "cc @me"}},
+			cfg:   Config{Rules: drafts, KeepMentions: true, KeepAuthors: []string{"bot"}},
+			want: []string{
+				"org and visibility filters: pass",
+				"keep_authors and keep_assigned: no match",
+				"rule drafts: no match",
+				"built-in classification: MUTE (team-only review request)",
+				"keep_mentions: PR description mentions @me",
+			},
+		},
+		{
+			name:  "topic filter and mute_approved",
+			facts: Facts{Reviewers: teamOnly, Topics: []string{"go"}, ReviewDecision: "APPROVED"},
+			cfg:   Config{IncludeTopics: []string{"go"}, MuteApproved: true, Rules: drafts},
+			want: []string{
+				"org and visibility filters: pass",
+				"topic filter: topics [go] pass",
+				"mute_approved: PR is approved",
+			},
+		},
+		{
+			name:  "rule decides",
+			facts: Facts{Reviewers: &Reviewers{Users: []string{"me"}}, PR: &PullRequest{Draft: true}},
+			cfg:   Config{Rules: drafts, MuteTeammateReviewing: true},
+			want: []string{
+				"org and visibility filters: pass",
+				"mute_teammate_reviewing: not a team-only review request",
+				"rule drafts: matches: MUTE",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, steps := Explain(n, tt.facts, "me", tt.cfg)
+			var got []string
+			for _, s := range steps {
+				got = append(got, s.Check+": "+s.Outcome)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Explain() steps:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if want := Decide(n, tt.facts, "me", tt.cfg); d.Action != want.Action || d.Reason != want.Reason {
+				t.Errorf("Explain() = %v (%s), Decide() = %v (%s)", d.Action, d.Reason, want.Action, want.Reason)
+			}
+		})
+	}
+}
+
+func TestFormatExplanation(t *testing.T) {
+	n := Notification{
+		Subject:    Subject{Title: "WIP: new login", URL: "https://api.github.com/repos/org/repo/pulls/42"},
+		Repository: Repository{FullName: "org/repo"},
+	}
+	d := Decision{Notification: n, Action: ActionMute, Reason: "rule drafts"}
+	steps := []ExplainStep{
+		{Check: "org and visibility filters", Outcome: "pass"},
+		{Check: "rule drafts", Outcome: "matches: MUTE"},
+	}
+	want := "org/repo#42  \"WIP: new login\"\n" +
+		"  org and visibility filters  pass\n" +
+		"  rule drafts                 matches: MUTE\n" +
+		"=> MUTE (rule drafts)\n"
+	if got := FormatExplanation(d, steps); got != want {
+		t.Errorf("FormatExplanation() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestParseActionSet(t *testing.T) {
+	tests := []struct {
+		in      string
+		has     []Action
+		hasNot  []Action
+		wantErr bool
+	}{
+		{in: "", has: []Action{ActionSkip, ActionKeep, ActionMute, ActionDefer, ActionDim, ActionArchive}},
+		{in: "all", has: []Action{ActionSkip, ActionKeep, ActionMute, ActionDefer, ActionDim, ActionArchive}},
+		{in: "dim,Archive", has: []Action{ActionDim, ActionArchive}, hasNot: []Action{ActionMute}},
+		{in: "read", has: []Action{ActionDim}, hasNot: []Action{ActionArchive}},
+		{in: "done", has: []Action{ActionArchive}, hasNot: []Action{ActionDim}},
+		{in: "mute,keep", has: []Action{ActionMute, ActionKeep}, hasNot: []Action{ActionSkip, ActionDefer}},
+		{in: " MUTE , ", has: []Action{ActionMute}, hasNot: []Action{ActionKeep}},
+		{in: "mute,ignore", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseActionSet(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseActionSet(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		for _, a := range tt.has {
+			if !got.Has(a) {
+				t.Errorf("ParseActionSet(%q) doesn't have %s", tt.in, a)
+			}
+		}
+		for _, a := range tt.hasNot {
+			if got.Has(a) {
+				t.Errorf("ParseActionSet(%q) has %s", tt.in, a)
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Sample describes a notification and the facts about it by hand, for config
+// validate's sample flags and rules test cases.
+type Sample struct {
+	Reason         string
+	Repo           string // owner/repo
+	Title          string
+	Type           string // subject type, e.g. "PullRequest"
+	Teams          []string
+	Users          []string
+	Author         string
+	Draft          bool
+	Labels         []string
+	Body           string
+	Assignees      []string
+	Head           string
+	Base           string
+	Files          []string
+	ReviewDecision string
+}
+
+// Notification builds the sample's notification, about PR #1 of its repo.
+func (s Sample) Notification() Notification {
+	owner, _, _ := strings.Cut(s.Repo, "/")
+	return Notification{
+		ID:         "sample",
+		Reason:     s.Reason,
+		Subject:    Subject{Title: s.Title, URL: fmt.Sprintf("https://api.github.com/repos/%s/pulls/1", s.Repo), Type: s.Type},
+		Repository: Repository{FullName: s.Repo, Owner: owner},
+	}
+}
+
+// Facts builds what the shell would have looked up about the sample, as of
+// now. Every lookup succeeds: what the sample leaves out, it has none of.
+func (s Sample) Facts(now time.Time) Facts {
+	return Facts{
+		Reviewers:      &Reviewers{Users: s.Users, Teams: s.Teams},
+		Files:          append([]string{}, s.Files...),
+		Topics:         []string{},
+		ReviewRequests: []ReviewRequest{},
+		ReviewDecision: s.ReviewDecision,
+		PR:             &PullRequest{Author: s.Author, Draft: s.Draft, State: "open", Labels: s.Labels, Body: s.Body, Assignees: s.Assignees, Head: s.Head, Base: s.Base},
+		Now:            now,
+	}
+}
+
+// RuleTest is a rules test case: a sample and the decision it should get.
+type RuleTest struct {
+	Name     string
+	Sample   Sample
+	Login    string
+	At       time.Time // when the sample arrives, for time.* fields and defer
+	Want     Action
+	WantRule string // the rule that should decide; empty to accept any decider
+}
+
+// RuleTestResult is a rules test case and the decision it got.
+type RuleTestResult struct {
+	Test RuleTest
+	Got  Decision
+}
+
+// Passed reports whether the case got the action, and rule if any, it wanted.
+func (r RuleTestResult) Passed() bool {
+	return r.Got.Action == r.Test.Want && (r.Test.WantRule == "" || r.Got.Rule == r.Test.WantRule)
+}
+
+// RunRuleTests decides each case's sample under cfg.
+func RunRuleTests(tests []RuleTest, cfg Config) []RuleTestResult {
+	results := make([]RuleTestResult, len(tests))
+	for i, t := range tests {
+		s := t.Sample
+		results[i] = RuleTestResult{Test: t, Got: Decide(s.Notification(), s.Facts(t.At), t.Login, cfg)}
+	}
+	return results
+}
+
+// FormatRuleTestResults renders a line per case, with what a failing one got
+// instead, and a final tally.
+func FormatRuleTestResults(results []RuleTestResult) string {
+	var b strings.Builder
+	failed := 0
+	for _, r := range results {
+		want := r.Test.Want.String()
+		if r.Test.WantRule != "" {
+			want += " by rule " + r.Test.WantRule
+		}
+		if r.Passed() {
+			fmt.Fprintf(&b, "PASS  %s: %s\n", r.Test.Name, want)
+			continue
+		}
+		failed++
+		fmt.Fprintf(&b, "FAIL  %s: want %s, got %s (%s)\n", r.Test.Name, want, r.Got.Action, r.Got.Reason)
+	}
+	if failed > 0 {
+		fmt.Fprintf(&b, "\n%d of %d cases failed\n", failed, len(results))
+	} else {
+		fmt.Fprintf(&b, "\nAll %d cases passed\n", len(results))
+	}
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSample(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	s := Sample{Reason: "review_requested", Repo: "acme/api", Title: "Bump deps", Type: "PullRequest", Teams: []string{"backend"}, Author: "dependabot[bot]", Base: "main"}
+	n := s.Notification()
+	if n.Repository.Owner != "acme" || n.Subject.URL != "https://api.github.com/repos/acme/api/pulls/1" || NotificationLabel(n) != "acme/api#1" {
+		t.Errorf("Notification() = %+v", n)
+	}
+	f := s.Facts(now)
+	if f.PR.Author != "dependabot[bot]" || f.PR.State != "open" || f.Reviewers.Teams[0] != "backend" || !f.Now.Equal(now) {
+		t.Errorf("Facts() = %+v", f)
+	}
+}
+
+func TestRunRuleTests(t *testing.T) {
+	rules := mustParseRules(t, RuleSpec{Name: "bots", When: `pr.author == "dependabot[bot]"`, Action: "mute"})
+	bump := Sample{Reason: "review_requested", Repo: "acme/api", Type: "PullRequest", Teams: []string{"backend"}, Author: "dependabot[bot]"}
+	direct := Sample{Reason: "review_requested", Repo: "acme/api", Type: "PullRequest", Users: []string{"me"}, Author: "alice"}
+	tests := []RuleTest{
+		{Name: "bot bumps", Sample: bump, Login: "me", Want: ActionMute, WantRule: "bots"},
+		{Name: "direct requests", Sample: direct, Login: "me", Want: ActionKeep},
+		{Name: "wrong rule", Sample: bump, Login: "me", Want: ActionMute, WantRule: "drafts"},
+		{Name: "wrong action", Sample: direct, Login: "me", Want: ActionMute},
+	}
+	results := RunRuleTests(tests, Config{Rules: rules})
+	for i, want := range []bool{true, true, false, false} {
+		if got := results[i].Passed(); got != want {
+			t.Errorf("%s: Passed() = %v, want %v (got %s, %s)", tests[i].Name, got, want, results[i].Got.Action, results[i].Got.Reason)
+		}
+	}
+
+	want := "PASS  bot bumps: MUTE by rule bots\n" +
+		"PASS  direct requests: KEEP\n" +
+		"FAIL  wrong rule: want MUTE by rule drafts, got MUTE (rule bots)\n" +
+		"FAIL  wrong action: want MUTE, got KEEP (direct review request)\n" +
+		"\n2 of 4 cases failed\n"
+	if got := FormatRuleTestResults(results); got != want {
+		t.Errorf("FormatRuleTestResults() =\n%s\nwant\n%s", got, want)
+	}
+	if got := FormatRuleTestResults(results[:2]); got != "PASS  bot bumps: MUTE by rule bots\nPASS  direct requests: KEEP\n\nAll 2 cases passed\n" {
+		t.Errorf("FormatRuleTestResults() of passes =\n%s", got)
+	}
+}
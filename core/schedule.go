@@ -0,0 +1,132 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RequestClass is what a GitHub API request is for. Each class has its own
+// timeout, and when the request bucket runs dry, waiting requests are sent in
+// class priority order, so slow lookups can't starve mutations.
+type RequestClass int
+
+const (
+	ClassList   RequestClass = iota // the notification listing
+	ClassLookup                     // reviewers, PRs, repos, teams, searches, and GraphQL queries
+	ClassMutate                     // marking, ignoring, and every other write
+	numClasses
+)
+
+func (c RequestClass) String() string {
+	switch c {
+	case ClassList:
+		return "list"
+	case ClassLookup:
+		return "lookup"
+	case ClassMutate:
+		return "mutate"
+	default:
+		return "unknown"
+	}
+}
+
+func parseRequestClass(s string) (RequestClass, error) {
+	for c := range numClasses {
+		if strings.EqualFold(strings.TrimSpace(s), c.String()) {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid request class %q (valid values: list, lookup, mutate)", s)
+}
+
+// ClassifyRequest finds the class of a request from its method and URL path.
+// GraphQL is only queried, so its POSTs are lookups.
+func ClassifyRequest(method, path string) RequestClass {
+	switch {
+	case method == "GET" && strings.HasSuffix(path, "/notifications"):
+		return ClassList
+	case method == "GET" || method == "HEAD" || strings.HasSuffix(path, "/graphql"):
+		return ClassLookup
+	}
+	return ClassMutate
+}
+
+// RequestTimeouts is how long a request of each class may take, response body
+// included.
+type RequestTimeouts [numClasses]time.Duration
+
+// DefaultRequestTimeouts gives listing pages, which can be large, the most
+// time, and lookups, which are many and small, the least.
+var DefaultRequestTimeouts = RequestTimeouts{
+	ClassList:   60 * time.Second,
+	ClassLookup: 20 * time.Second,
+	ClassMutate: 30 * time.Second,
+}
+
+// ParseRequestTimeouts parses --request-timeout: comma-separated class=duration
+// pairs, like "lookup=10s,list=2m". Classes left out keep their defaults.
+func ParseRequestTimeouts(s string) (RequestTimeouts, error) {
+	t := DefaultRequestTimeouts
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return t, fmt.Errorf("invalid request timeout %q (want class=duration, e.g. lookup=10s)", pair)
+		}
+		c, err := parseRequestClass(name)
+		if err != nil {
+			return t, err
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d <= 0 {
+			return t, fmt.Errorf("invalid %s timeout %q (want a positive duration, e.g. 10s)", c, value)
+		}
+		t[c] = d
+	}
+	return t, nil
+}
+
+// RequestPriority orders the classes, highest priority first.
+type RequestPriority []RequestClass
+
+// DefaultRequestPriority sends mutations first, so a run's mutes aren't held
+// up behind the next cycle's lookups, then listing pages, then lookups.
+var DefaultRequestPriority = RequestPriority{ClassMutate, ClassList, ClassLookup}
+
+// ParseRequestPriority parses --request-priority: every class once, highest
+// first, like "mutate,list,lookup".
+func ParseRequestPriority(s string) (RequestPriority, error) {
+	var p RequestPriority
+	for _, name := range strings.Split(s, ",") {
+		c, err := parseRequestClass(name)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(p, c) {
+			return nil, fmt.Errorf("request class %s is listed twice", c)
+		}
+		p = append(p, c)
+	}
+	if len(p) != int(numClasses) {
+		return nil, fmt.Errorf("request priority %q must list list, lookup, and mutate once each", s)
+	}
+	return p, nil
+}
+
+// RequestQueue counts the requests waiting for the request bucket, by class.
+type RequestQueue [numClasses]int
+
+// Next returns the highest-priority class with requests waiting, or false if
+// none are.
+func (p RequestPriority) Next(waiting RequestQueue) (RequestClass, bool) {
+	for _, c := range p {
+		if waiting[c] > 0 {
+			return c, true
+		}
+	}
+	return 0, false
+}
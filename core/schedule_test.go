@@ -0,0 +1,93 @@
+package core
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestClassifyRequest(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         RequestClass
+	}{
+		{"GET", "/notifications", ClassList},
+		{"GET", "/api/v3/repos/org/repo/notifications", ClassList},
+		{"GET", "/repos/org/repo/pulls/42/requested_reviewers", ClassLookup},
+		{"GET", "/notifications/threads/1/subscription", ClassLookup},
+		{"POST", "/graphql", ClassLookup},
+		{"POST", "/api/graphql", ClassLookup},
+		{"PATCH", "/notifications/threads/1", ClassMutate},
+		{"PUT", "/notifications/threads/1/subscription", ClassMutate},
+		{"DELETE", "/notifications/threads/1", ClassMutate},
+		{"POST", "/repos/org/repo/pulls/42/requested_reviewers", ClassMutate},
+	}
+	for _, tt := range tests {
+		if got := ClassifyRequest(tt.method, tt.path); got != tt.want {
+			t.Errorf("ClassifyRequest(%s, %s) = %s, want %s", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseRequestTimeouts(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    RequestTimeouts
+		wantErr bool
+	}{
+		{in: "", want: DefaultRequestTimeouts},
+		{in: "lookup=10s, LIST=2m", want: RequestTimeouts{ClassList: 2 * time.Minute, ClassLookup: 10 * time.Second, ClassMutate: 30 * time.Second}},
+		{in: "lookup", wantErr: true},
+		{in: "fetch=10s", wantErr: true},
+		{in: "mutate=0s", wantErr: true},
+		{in: "mutate=soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRequestTimeouts(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRequestTimeouts(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseRequestTimeouts(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseRequestPriority(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    RequestPriority
+		wantErr bool
+	}{
+		{in: "list, mutate,lookup", want: RequestPriority{ClassList, ClassMutate, ClassLookup}},
+		{in: "mutate,list", wantErr: true},
+		{in: "mutate,list,list", wantErr: true},
+		{in: "mutate,list,write", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRequestPriority(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRequestPriority(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ParseRequestPriority(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRequestPriorityNext(t *testing.T) {
+	var q RequestQueue
+	if _, ok := DefaultRequestPriority.Next(q); ok {
+		t.Error("Next() of an empty queue found a class")
+	}
+	q[ClassLookup], q[ClassList] = 5, 1
+	if got, _ := DefaultRequestPriority.Next(q); got != ClassList {
+		t.Errorf("Next() = %s, want list", got)
+	}
+	q[ClassMutate] = 1
+	if got, _ := DefaultRequestPriority.Next(q); got != ClassMutate {
+		t.Errorf("Next() = %s, want mutate", got)
+	}
+}
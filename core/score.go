@@ -0,0 +1,149 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultSnooze is how long a review request scoring between the mute and
+// keep thresholds is snoozed when scoring doesn't say.
+const DefaultSnooze = 4 * time.Hour
+
+// ScoringSpec is scoring as written in the config file.
+type ScoringSpec struct {
+	Direct, Team int            // points for a personal or team-only request
+	Authors      map[string]int // points by PR author login
+	Labels       map[string]int // points by PR label
+	Repos        map[string]int // points by "org/repo", or "org/*" for a whole org
+	PerDayOld    int            // points per whole day since the thread was updated
+	KeepAt       int            // keep at or above this score
+	MuteBelow    int            // mute below this score; snooze in between
+	SnoozeFor    string         // e.g. "4h"; empty for DefaultSnooze
+}
+
+// Scoring replaces the built-in keep-or-mute of review requests with a
+// priority score from weighted signals. Review requests scoring KeepAt or
+// more are kept, under MuteBelow muted, and in between snoozed: deferred for
+// SnoozeFor, then scored again. Names are lowercase.
+type Scoring struct {
+	Direct, Team int
+	Authors      map[string]int
+	Labels       map[string]int
+	Repos        map[string]int
+	PerDayOld    int
+	KeepAt       int
+	MuteBelow    int
+	SnoozeFor    time.Duration
+}
+
+// CheckScoring validates a spec.
+func CheckScoring(spec ScoringSpec) (*Scoring, error) {
+	if spec.MuteBelow > spec.KeepAt {
+		return nil, fmt.Errorf("scoring: mute_below (%d) must not be above keep_at (%d)", spec.MuteBelow, spec.KeepAt)
+	}
+	s := &Scoring{
+		Direct:    spec.Direct,
+		Team:      spec.Team,
+		Authors:   lowerKeys(spec.Authors),
+		Labels:    lowerKeys(spec.Labels),
+		Repos:     lowerKeys(spec.Repos),
+		PerDayOld: spec.PerDayOld,
+		KeepAt:    spec.KeepAt,
+		MuteBelow: spec.MuteBelow,
+		SnoozeFor: DefaultSnooze,
+	}
+	if spec.SnoozeFor != "" {
+		d, err := time.ParseDuration(spec.SnoozeFor)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("scoring: snooze_for: invalid duration %q", spec.SnoozeFor)
+		}
+		s.SnoozeFor = d
+	}
+	for repo := range s.Repos {
+		if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" {
+			return nil, fmt.Errorf("scoring: repos: %q should be org/repo or org/*", repo)
+		}
+	}
+	for _, m := range []map[string]int{s.Authors, s.Labels} {
+		if _, ok := m[""]; ok {
+			return nil, errors.New("scoring: empty author or label name")
+		}
+	}
+	return s, nil
+}
+
+func lowerKeys(m map[string]int) map[string]int {
+	out := make(map[string]int, len(m))
+	for k, v := range m {
+		out[strings.ToLower(strings.TrimSpace(k))] += v
+	}
+	return out
+}
+
+// usesPR reports whether scoring reads PR details, which cost a lookup.
+func (s *Scoring) usesPR() bool {
+	return len(s.Authors) > 0 || len(s.Labels) > 0
+}
+
+// Score adds up a review request's signals. It returns the score and each
+// signal that contributed, e.g. "label security +40", in a stable order.
+func (s *Scoring) Score(n Notification, facts Facts, login string) (int, []string) {
+	score := 0
+	var parts []string
+	add := func(points int, signal string) {
+		if points != 0 {
+			score += points
+			parts = append(parts, fmt.Sprintf("%s %+d", signal, points))
+		}
+	}
+	if facts.Reviewers != nil && containsFold(facts.Reviewers.Users, login) {
+		add(s.Direct, "direct")
+	} else {
+		add(s.Team, "team")
+	}
+	if facts.PR != nil {
+		add(s.Authors[strings.ToLower(facts.PR.Author)], "author "+facts.PR.Author)
+		labels := append([]string(nil), facts.PR.Labels...)
+		sort.Strings(labels)
+		for _, l := range labels {
+			add(s.Labels[strings.ToLower(l)], "label "+l)
+		}
+	}
+	repo := strings.ToLower(n.Repository.FullName)
+	if points, ok := s.Repos[repo]; ok {
+		add(points, "repo "+n.Repository.FullName)
+	} else if owner, _, ok := strings.Cut(repo, "/"); ok {
+		add(s.Repos[owner+"/*"], "org "+n.Repository.Owner)
+	}
+	if !facts.Now.IsZero() && !n.UpdatedAt.IsZero() {
+		if days := int(facts.Now.Sub(n.UpdatedAt) / (24 * time.Hour)); days > 0 {
+			add(days*s.PerDayOld, fmt.Sprintf("%dd old", days))
+		}
+	}
+	return score, parts
+}
+
+// decide scores a review request and maps the score to an action.
+func (s *Scoring) decide(n Notification, facts Facts, login string) Decision {
+	score, parts := s.Score(n, facts, login)
+	reason := fmt.Sprintf("score %d", score)
+	if len(parts) > 0 {
+		reason += ": " + strings.Join(parts, ", ")
+	}
+	d := Decision{Notification: n, Reason: reason}
+	if facts.Reviewers != nil {
+		d.Teams = facts.Reviewers.Teams
+	}
+	switch {
+	case score >= s.KeepAt:
+		d.Action = ActionKeep
+	case score < s.MuteBelow:
+		d.Action = ActionMute
+	default:
+		d.Action, d.Until = ActionDefer, facts.Now.Add(s.SnoozeFor)
+	}
+	return d
+}
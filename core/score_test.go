@@ -0,0 +1,92 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckScoring(t *testing.T) {
+	s, err := CheckScoring(ScoringSpec{Direct: 50, Labels: map[string]int{"Security": 40}, Repos: map[string]int{"acme/*": 5}, KeepAt: 50, MuteBelow: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.SnoozeFor != DefaultSnooze || s.Labels["security"] != 40 {
+		t.Errorf("CheckScoring() = %+v, want default snooze and lowercase labels", s)
+	}
+	for _, spec := range []ScoringSpec{
+		{KeepAt: 10, MuteBelow: 20},
+		{SnoozeFor: "soon"},
+		{SnoozeFor: "-1h"},
+		{Repos: map[string]int{"acme": 5}},
+		{Authors: map[string]int{" ": 5}},
+	} {
+		if _, err := CheckScoring(spec); err == nil {
+			t.Errorf("CheckScoring(%+v) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestDecideScoring(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	scoring, err := CheckScoring(ScoringSpec{
+		Direct: 50, Team: 10,
+		Authors:   map[string]int{"my-manager": 30},
+		Labels:    map[string]int{"security": 40, "dependencies": -20},
+		Repos:     map[string]int{"acme/api": 20, "acme/*": 5},
+		PerDayOld: -2,
+		KeepAt:    50, MuteBelow: 20,
+		SnoozeFor: "2h",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{Scoring: scoring}
+	notification := func(repo string, age time.Duration) Notification {
+		owner, _, _ := strings.Cut(repo, "/")
+		return Notification{ID: "1", Reason: "review_requested", UpdatedAt: now.Add(-age), Subject: Subject{Type: "PullRequest"}, Repository: Repository{FullName: repo, Owner: owner}}
+	}
+	team := &Reviewers{Teams: []string{"backend"}}
+
+	tests := []struct {
+		name       string
+		n          Notification
+		facts      Facts
+		wantAction Action
+		wantReason string
+	}{
+		{"direct", notification("acme/web", time.Hour), Facts{Reviewers: &Reviewers{Users: []string{"me"}}, Now: now},
+			ActionKeep, "score 55: direct +50, org acme +5"},
+		{"team security fix", notification("acme/api", 3*24*time.Hour), Facts{Reviewers: team, PR: &PullRequest{Author: "alice", Labels: []string{"security"}}, Now: now},
+			ActionKeep, "score 64: team +10, label security +40, repo acme/api +20, 3d old -6"},
+		{"team, manager's PR", notification("oss/lib", 0), Facts{Reviewers: team, PR: &PullRequest{Author: "My-Manager"}, Now: now},
+			ActionDefer, "score 40: team +10, author My-Manager +30"},
+		{"dependency bump", notification("acme/web", 0), Facts{Reviewers: team, PR: &PullRequest{Author: "dependabot[bot]", Labels: []string{"dependencies"}}, Now: now},
+			ActionMute, "score -5: team +10, label dependencies -20, org acme +5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Decide(tt.n, tt.facts, "me", cfg)
+			if d.Action != tt.wantAction || d.Reason != tt.wantReason {
+				t.Errorf("Decide() = %s (%s), want %s (%s)", d.Action, d.Reason, tt.wantAction, tt.wantReason)
+			}
+			if d.Action == ActionDefer && !d.Until.Equal(now.Add(2*time.Hour)) {
+				t.Errorf("Until = %v, want %v", d.Until, now.Add(2*time.Hour))
+			}
+		})
+	}
+
+	// Rules still come first, and other notifications aren't scored.
+	cfg.Rules = mustParseRules(t, RuleSpec{Name: "bots", When: `pr.author.endsWith("[bot]")`, Action: "keep"})
+	if d := Decide(tests[3].n, tests[3].facts, "me", cfg); d.Reason != "rule bots" {
+		t.Errorf("Decide() with a matching rule = %s (%s), want the rule", d.Action, d.Reason)
+	}
+	mention := notification("acme/api", 0)
+	mention.Reason = "mention"
+	if d := Decide(mention, Facts{Now: now}, "me", cfg); d.Action != ActionSkip {
+		t.Errorf("Decide() for a mention = %s (%s), want SKIP", d.Action, d.Reason)
+	}
+	if !NeedsPRLookup(notification("acme/api", 0), cfg) {
+		t.Error("NeedsPRLookup() = false, want true for scoring by author and label")
+	}
+}
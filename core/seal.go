@@ -0,0 +1,67 @@
+package core
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+)
+
+// Sealer encrypts state with AES-256-GCM, the name of the log or document
+// bound in as additional data so records can't be moved between them.
+// Sealed data is base64 of the nonce and ciphertext.
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+// NewSealer returns a Sealer for a state key.
+func NewSealer(key []byte) (*Sealer, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Sealer{aead: aead}, nil
+}
+
+// NonceSize is how many random bytes Seal needs for each record.
+func (s *Sealer) NonceSize() int {
+	return s.aead.NonceSize()
+}
+
+// Seal encrypts plain as name, with a fresh random nonce of NonceSize bytes.
+func (s *Sealer) Seal(name string, plain, nonce []byte) []byte {
+	sealed := s.aead.Seal(bytes.Clone(nonce), nonce, plain, []byte(name))
+	return []byte(base64.StdEncoding.EncodeToString(sealed))
+}
+
+// Open decrypts data sealed as name. It fails closed: plaintext, data sealed
+// with another key or as another name, and data tampered with are all
+// rejected.
+func (s *Sealer) Open(name string, data []byte) ([]byte, error) {
+	if IsPlaintext(data) {
+		return nil, fmt.Errorf("%s: plaintext in encrypted state", name)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return nil, fmt.Errorf("%s: not encrypted state", name)
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	plain, err := s.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("%s: can't decrypt state; was the state key changed, or the file tampered with?", name)
+	}
+	return plain, nil
+}
+
+// IsPlaintext reports whether stored state is plain JSON, as written before
+// encryption was turned on, rather than sealed. Base64 never starts with a
+// brace or bracket.
+func IsPlaintext(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
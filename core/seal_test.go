@@ -0,0 +1,58 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSealer(t *testing.T) {
+	s, err := NewSealer(bytes.Repeat([]byte{1}, StateKeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := bytes.Repeat([]byte{2}, s.NonceSize())
+	sealed := s.Seal("journal", []byte(`{"thread_id":"1"}`), nonce)
+	if got, err := s.Open("journal", sealed); err != nil || string(got) != `{"thread_id":"1"}` {
+		t.Fatalf("Open() = %q, %v, want the record", got, err)
+	}
+
+	tampered := bytes.Clone(sealed)
+	tampered[len(tampered)/2] ^= 'A' ^ 'B'
+	other, _ := NewSealer(bytes.Repeat([]byte{3}, StateKeySize))
+	tests := []struct {
+		name   string
+		sealer *Sealer
+		as     string
+		data   []byte
+	}{
+		{"plaintext object", s, "journal", []byte(`{"thread_id":"1","undo":true}`)},
+		{"plaintext array", s, "deferred", []byte(" [] ")},
+		{"tampered", s, "journal", tampered},
+		{"moved to another log", s, "deferred", sealed},
+		{"another key", other, "journal", sealed},
+		{"not base64", s, "journal", []byte("!!")},
+		{"too short", s, "journal", []byte("AAAA")},
+	}
+	for _, tt := range tests {
+		if got, err := tt.sealer.Open(tt.as, tt.data); err == nil {
+			t.Errorf("%s: Open() = %q, want an error", tt.name, got)
+		}
+	}
+}
+
+func TestIsPlaintext(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{`{"a":1}`, true},
+		{"\n [1]", true},
+		{"", false},
+		{"AAAA", false},
+	}
+	for _, tt := range tests {
+		if got := IsPlaintext([]byte(tt.in)); got != tt.want {
+			t.Errorf("IsPlaintext(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
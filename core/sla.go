@@ -0,0 +1,71 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A muted team review request that nobody reviews within the review SLA is
+// unmuted and alerted on again, so muting doesn't let a team's PRs rot.
+
+// EscalationCandidates returns the team review requests muted at least sla
+// before now that are still muted, oldest first: each thread's latest record
+// is a mute of a review request through teams, with the subject URL needed
+// to check for reviews. Records must be in the order they were written.
+func EscalationCandidates(records []MutationRecord, now time.Time, sla time.Duration) []MutationRecord {
+	latest := make(map[string]int) // by host and thread, index into records
+	for i, r := range records {
+		if !r.Kept {
+			latest[r.Host+"\x00"+r.ThreadID] = i
+		}
+	}
+	var out []MutationRecord
+	for i, r := range records {
+		if r.Kept || r.Undo || latest[r.Host+"\x00"+r.ThreadID] != i {
+			continue
+		}
+		if r.Reason != "review_requested" || len(r.Teams) == 0 || r.URL == "" || now.Sub(r.Time) < sla {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// RecordEscalation builds the journal record for unmuting a thread whose
+// review SLA passed. Like an undo, it ends the mute.
+func RecordEscalation(m MutationRecord, now time.Time) MutationRecord {
+	m = RecordUndo(m, now)
+	m.Escalate = true
+	return m
+}
+
+// EscalationUnignores reports whether escalating r unignores its thread:
+// only if the mute ignored it. A thread the user had ignored themselves
+// before the mute stays ignored, as with undo, though it's still alerted on
+// and the escalation journaled.
+func EscalationUnignores(r MutationRecord) bool {
+	return r.Ignored
+}
+
+// AlertForEscalation builds the alert for a team review request unmuted
+// because nobody reviewed it within sla.
+func AlertForEscalation(r MutationRecord, sla time.Duration) Alert {
+	return Alert{
+		Title: fmt.Sprintf("Unclaimed team review: %s", r.Label),
+		Body:  fmt.Sprintf("%s\nNo reviews %s after the request to %s was muted.", r.Title, FormatAge(sla), strings.Join(r.Teams, ", ")),
+		URL:   HTMLURL(r.URL),
+	}
+}
+
+// FormatEscalationRow formats an escalated thread as a line of daemon output.
+func FormatEscalationRow(r MutationRecord, now time.Time, err error) string {
+	if err != nil {
+		return fmt.Sprintf("ERROR  %s  %q  %s", r.Label, r.Title, err)
+	}
+	if !EscalationUnignores(r) {
+		return fmt.Sprintf("ALERT  %s  %q  (unclaimed team review, muted %s ago; left ignored, as you ignored it yourself)", r.Label, r.Title, FormatAge(now.Sub(r.Time)))
+	}
+	return fmt.Sprintf("UNMUTE %s  %q  (unclaimed team review, muted %s ago)", r.Label, r.Title, FormatAge(now.Sub(r.Time)))
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestEscalationCandidates(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	url := "https://api.github.com/repos/acme/api/pulls/"
+	mute := func(id string, ago time.Duration, teams ...string) MutationRecord {
+		return MutationRecord{Time: now.Add(-ago), ThreadID: id, Reason: "review_requested", Teams: teams, URL: url + id}
+	}
+	undone := mute("4", 40*time.Hour, "backend")
+	records := []MutationRecord{
+		mute("1", 30*time.Hour, "backend"),
+		mute("2", 2*time.Hour, "backend"),
+		mute("3", 30*time.Hour),
+		undone,
+		RecordUndo(undone, now.Add(-time.Hour)),
+		{Time: now.Add(-30 * time.Hour), ThreadID: "5", Reason: "review_requested", Teams: []string{"backend"}},
+		{Time: now.Add(-30 * time.Hour), ThreadID: "6", Reason: "subscribed", Teams: []string{"backend"}, URL: url + "6"},
+		mute("7", 25*time.Hour, "web", "design"),
+		{Time: now.Add(-20 * time.Hour), ThreadID: "7", Kept: true},
+	}
+
+	got := EscalationCandidates(records, now, 24*time.Hour)
+	var ids []string
+	for _, r := range got {
+		ids = append(ids, r.ThreadID)
+	}
+	if want := []string{"1", "7"}; !slices.Equal(ids, want) {
+		t.Errorf("EscalationCandidates() = %v, want %v", ids, want)
+	}
+
+	escalated := RecordEscalation(records[0], now)
+	if !escalated.Undo || !escalated.Escalate || !escalated.Time.Equal(now) {
+		t.Errorf("RecordEscalation() = %+v, want an escalating undo at now", escalated)
+	}
+	if got := EscalationCandidates(append(records, escalated), now, 24*time.Hour); len(got) != 1 || got[0].ThreadID != "7" {
+		t.Errorf("EscalationCandidates() after escalating thread 1 = %+v, want only thread 7", got)
+	}
+}
+
+func TestAlertForEscalation(t *testing.T) {
+	r := MutationRecord{
+		Label: "acme/api#42",
+		Title: "Bump deps",
+		URL:   "https://api.github.com/repos/acme/api/pulls/42",
+		Teams: []string{"backend", "platform"},
+	}
+	a := AlertForEscalation(r, 24*time.Hour)
+	want := Alert{
+		Title: "Unclaimed team review: acme/api#42",
+		Body:  "Bump deps\nNo reviews 24h after the request to backend, platform was muted.",
+		URL:   "https://github.com/acme/api/pull/42",
+	}
+	if a != want {
+		t.Errorf("AlertForEscalation() = %+v, want %+v", a, want)
+	}
+}
+
+func TestFormatEscalationRow(t *testing.T) {
+	now := time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC)
+	r := MutationRecord{Label: "acme/api#42", Title: "Bump deps", Time: now.Add(-26 * time.Hour), Ignored: true}
+	tests := []struct {
+		name      string
+		ignored   bool
+		err       error
+		want      string
+		unignores bool
+	}{
+		{"muted by mutemath", true, nil, `UNMUTE acme/api#42  "Bump deps"  (unclaimed team review, muted 26h ago)`, true},
+		{"ignored by the user", false, nil, `ALERT  acme/api#42  "Bump deps"  (unclaimed team review, muted 26h ago; left ignored, as you ignored it yourself)`, false},
+		{"failed", true, errors.New("unexpected status 502"), `ERROR  acme/api#42  "Bump deps"  unexpected status 502`, true},
+	}
+	for _, tt := range tests {
+		r.Ignored = tt.ignored
+		if got := EscalationUnignores(r); got != tt.unignores {
+			t.Errorf("%s: EscalationUnignores() = %v, want %v", tt.name, got, tt.unignores)
+		}
+		if got := FormatEscalationRow(r, now, tt.err); got != tt.want {
+			t.Errorf("%s: FormatEscalationRow() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
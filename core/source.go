@@ -0,0 +1,46 @@
+package core
+
+import "strings"
+
+// ReviewRequest is a pending review request on a PR: of a user or a team,
+// and whether GitHub made it because CODEOWNERS names them.
+type ReviewRequest struct {
+	Reviewer  string // login, or team slug
+	Team      bool
+	CodeOwner bool
+}
+
+// RequestSource reports where login's review request came from: "human" if
+// someone asked for it explicitly, "codeowners" if GitHub requested it from
+// CODEOWNERS, or "" if there's no request to judge. A personal request of
+// login decides; otherwise the team requests do, and any explicit one makes
+// it "human".
+func RequestSource(requests []ReviewRequest, login string) string {
+	source := ""
+	for _, r := range requests {
+		if !r.Team {
+			if strings.EqualFold(r.Reviewer, login) {
+				return sourceOf(r)
+			}
+			continue
+		}
+		if source != "human" {
+			source = sourceOf(r)
+		}
+	}
+	return source
+}
+
+func sourceOf(r ReviewRequest) string {
+	if r.CodeOwner {
+		return "codeowners"
+	}
+	return "human"
+}
+
+// NeedsRequestSourceLookup decides if a notification requires fetching the
+// PR's pending review requests: only for PRs passing the repo filter, and
+// only if some rule reads request.source.
+func NeedsRequestSourceLookup(n Notification, cfg Config) bool {
+	return n.Subject.Type == "PullRequest" && MatchesRepoFilter(n, cfg) && rulesUseField(cfg.Rules, "request.source")
+}
@@ -0,0 +1,47 @@
+package core
+
+import "testing"
+
+func TestRequestSource(t *testing.T) {
+	team := func(slug string, codeOwner bool) ReviewRequest {
+		return ReviewRequest{Reviewer: slug, Team: true, CodeOwner: codeOwner}
+	}
+	tests := []struct {
+		name     string
+		requests []ReviewRequest
+		want     string
+	}{
+		{"none", nil, ""},
+		{"codeowners team", []ReviewRequest{team("backend", true)}, "codeowners"},
+		{"team by hand", []ReviewRequest{team("backend", false)}, "human"},
+		{"any team by hand", []ReviewRequest{team("backend", true), team("platform", false), team("web", true)}, "human"},
+		{"personal request decides", []ReviewRequest{team("backend", false), {Reviewer: "Me", CodeOwner: true}}, "codeowners"},
+		{"someone else's personal request", []ReviewRequest{{Reviewer: "alice"}, team("backend", true)}, "codeowners"},
+	}
+	for _, tt := range tests {
+		if got := RequestSource(tt.requests, "me"); got != tt.want {
+			t.Errorf("%s: RequestSource() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDecideRequestSource(t *testing.T) {
+	n := Notification{ID: "1", Reason: "review_requested", Subject: Subject{Type: "PullRequest"}, Repository: Repository{Owner: "org"}}
+	cfg := Config{Rules: mustParseRules(t, RuleSpec{Name: "by-hand", When: `request.source == "human"`, Action: "keep"})}
+	reviewers := &Reviewers{Teams: []string{"backend"}}
+
+	byHand := Facts{Reviewers: reviewers, ReviewRequests: []ReviewRequest{{Reviewer: "backend", Team: true}}}
+	if d := Decide(n, byHand, "me", cfg); d.Action != ActionKeep || d.Reason != "rule by-hand" {
+		t.Errorf("Decide() by hand = %v (%s), want KEEP (rule by-hand)", d.Action, d.Reason)
+	}
+	auto := Facts{Reviewers: reviewers, ReviewRequests: []ReviewRequest{{Reviewer: "backend", Team: true, CodeOwner: true}}}
+	if d := Decide(n, auto, "me", cfg); d.Action != ActionMute {
+		t.Errorf("Decide() from CODEOWNERS = %v (%s), want MUTE", d.Action, d.Reason)
+	}
+	if !NeedsRequestSourceLookup(n, cfg) {
+		t.Error("NeedsRequestSourceLookup() = false for a request.source rule, want true")
+	}
+	if NeedsRequestSourceLookup(n, Config{}) {
+		t.Error("NeedsRequestSourceLookup() = true with no rule, want false")
+	}
+}
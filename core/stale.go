@@ -0,0 +1,16 @@
+package core
+
+import "time"
+
+// DefaultReviewerMaxAge is how old reviewer data may be when a mute is made
+// before it's fetched again. Mutes wait for the whole listing, which on a
+// big inbox or a throttled token can take a while.
+const DefaultReviewerMaxAge = 10 * time.Minute
+
+// ReviewerDataStale reports whether a mute rests on reviewer data fetched
+// more than maxAge before now, so it should be decided again with fresh data
+// before it's made. A zero maxAge, or a decision made without reviewer data,
+// is never stale.
+func ReviewerDataStale(d Decision, fetched, now time.Time, maxAge time.Duration) bool {
+	return maxAge > 0 && d.Action == ActionMute && !fetched.IsZero() && now.Sub(fetched) > maxAge
+}
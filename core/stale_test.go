@@ -0,0 +1,30 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReviewerDataStale(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	mute := Decision{Action: ActionMute}
+	tests := []struct {
+		name    string
+		d       Decision
+		fetched time.Time
+		maxAge  time.Duration
+		want    bool
+	}{
+		{"fresh", mute, now.Add(-time.Minute), 10 * time.Minute, false},
+		{"at the bound", mute, now.Add(-10 * time.Minute), 10 * time.Minute, false},
+		{"stale", mute, now.Add(-11 * time.Minute), 10 * time.Minute, true},
+		{"guard off", mute, now.Add(-time.Hour), 0, false},
+		{"no reviewer data", mute, time.Time{}, 10 * time.Minute, false},
+		{"not a mute", Decision{Action: ActionKeep}, now.Add(-time.Hour), 10 * time.Minute, false},
+	}
+	for _, tt := range tests {
+		if got := ReviewerDataStale(tt.d, tt.fetched, now, tt.maxAge); got != tt.want {
+			t.Errorf("%s: ReviewerDataStale() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
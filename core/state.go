@@ -0,0 +1,85 @@
+package core
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// StateBackends lists the state backends mutemath has. Others people ask
+// for, such as SQLite and bbolt, need third-party drivers, and mutemath
+// uses the standard library only.
+var StateBackends = []string{"file"}
+
+// StateSpec is where mutemath keeps its state: the journal, feedback, the
+// deferred queue, and the previous run's decisions.
+type StateSpec struct {
+	Backend string // one of StateBackends; empty for file
+	Dir     string // for the file backend; empty for the user cache dir
+	Encrypt bool   // encrypt records and documents with the state key
+}
+
+// CheckState validates a state spec, defaulting the backend to file.
+func CheckState(spec StateSpec) (StateSpec, error) {
+	spec.Backend = strings.ToLower(strings.TrimSpace(spec.Backend))
+	switch {
+	case spec.Backend == "":
+		spec.Backend = "file"
+	case spec.Backend == "sqlite" || spec.Backend == "bbolt":
+		return StateSpec{}, fmt.Errorf("state backend %q isn't available: it needs a third-party driver, and mutemath uses the standard library only (valid values: %s)", spec.Backend, strings.Join(StateBackends, ", "))
+	case !slices.Contains(StateBackends, spec.Backend):
+		return StateSpec{}, fmt.Errorf("invalid state backend %q (valid values: %s)", spec.Backend, strings.Join(StateBackends, ", "))
+	}
+	return spec, nil
+}
+
+// StateKeySize is the length of the key state is encrypted with, for
+// AES-256.
+const StateKeySize = 32
+
+// StateKeyEnvVar holds the base64 state key, overriding the OS keychain.
+const StateKeyEnvVar = "MUTEMATH_STATE_KEY"
+
+// ParseStateKey decodes a base64 state key, from StateKeyEnvVar or the
+// keychain, checking its length.
+func ParseStateKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, errors.New("state key isn't valid base64")
+	}
+	if len(key) != StateKeySize {
+		return nil, fmt.Errorf("state key is %d bytes, want %d", len(key), StateKeySize)
+	}
+	return key, nil
+}
+
+// Keychain commands find and store the state key in the OS keychain, under
+// service "mutemath" and account "state-key".
+type KeychainCommands struct {
+	Lookup []string // prints the base64 key
+	Store  []string // stores the base64 key appended as the last argument, or read from stdin if StoreStdin
+	// StoreStdin passes the key on stdin rather than as an argument.
+	StoreStdin bool
+}
+
+// KeychainFor returns the keychain commands on an OS, as runtime.GOOS names
+// it: macOS's security, or libsecret's secret-tool on Linux and the BSDs.
+func KeychainFor(goos string) (KeychainCommands, error) {
+	switch goos {
+	case "darwin":
+		return KeychainCommands{
+			Lookup: []string{"security", "find-generic-password", "-s", "mutemath", "-a", "state-key", "-w"},
+			Store:  []string{"security", "add-generic-password", "-U", "-s", "mutemath", "-a", "state-key", "-w"},
+		}, nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return KeychainCommands{
+			Lookup:     []string{"secret-tool", "lookup", "service", "mutemath", "account", "state-key"},
+			Store:      []string{"secret-tool", "store", "--label=mutemath state key", "service", "mutemath", "account", "state-key"},
+			StoreStdin: true,
+		}, nil
+	default:
+		return KeychainCommands{}, fmt.Errorf("no OS keychain support on %s; set %s instead", goos, StateKeyEnvVar)
+	}
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestCheckState(t *testing.T) {
+	tests := []struct {
+		spec    StateSpec
+		want    StateSpec
+		wantErr bool
+	}{
+		{spec: StateSpec{}, want: StateSpec{Backend: "file"}},
+		{spec: StateSpec{Backend: " File ", Dir: "/data"}, want: StateSpec{Backend: "file", Dir: "/data"}},
+		{spec: StateSpec{Backend: "sqlite"}, wantErr: true},
+		{spec: StateSpec{Backend: "bbolt"}, wantErr: true},
+		{spec: StateSpec{Backend: "redis"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := CheckState(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckState(%+v) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("CheckState(%+v) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseStateKey(t *testing.T) {
+	valid := base64.StdEncoding.EncodeToString(make([]byte, StateKeySize))
+	if key, err := ParseStateKey(valid + "\n"); err != nil || len(key) != StateKeySize {
+		t.Errorf("ParseStateKey(valid) = %v, %v", key, err)
+	}
+	for _, s := range []string{"", "not base64!", base64.StdEncoding.EncodeToString(make([]byte, 16))} {
+		if _, err := ParseStateKey(s); err == nil {
+			t.Errorf("ParseStateKey(%q) succeeded", s)
+		}
+	}
+}
+
+func TestKeychainFor(t *testing.T) {
+	mac, err := KeychainFor("darwin")
+	if err != nil || mac.Lookup[0] != "security" || mac.StoreStdin {
+		t.Errorf("KeychainFor(darwin) = %+v, %v", mac, err)
+	}
+	linux, err := KeychainFor("linux")
+	if err != nil || linux.Lookup[0] != "secret-tool" || !linux.StoreStdin {
+		t.Errorf("KeychainFor(linux) = %+v, %v", linux, err)
+	}
+	if _, err := KeychainFor("windows"); err == nil {
+		t.Error("KeychainFor(windows) succeeded")
+	}
+}
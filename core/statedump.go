@@ -0,0 +1,75 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DaemonSnapshot is a daemon's internal state, for the dump it logs on
+// SIGQUIT.
+type DaemonSnapshot struct {
+	Name         string // the daemon's host or user; empty for the only one
+	Started      time.Time
+	Cycles       int
+	CycleStarted time.Time    // when the running cycle started; zero between cycles
+	Last         *CycleRecord // the last finished cycle; nil before the first
+	NextPoll     time.Time    // when the next cycle starts; zero during a cycle
+	Paused       string       // why cycles are paused; empty if they aren't
+	Retries      []PendingMutation
+	Caches       map[string]int // entries in each cache, by name
+}
+
+// FormatStateDump renders daemon snapshots for the log.
+func FormatStateDump(snaps []DaemonSnapshot, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "state dump at %s\n", now.UTC().Format(time.RFC3339))
+	for _, s := range snaps {
+		name := "daemon"
+		if s.Name != "" {
+			name += " " + s.Name
+		}
+		fmt.Fprintf(&b, "%s: up %s, %d cycles\n", name, now.Sub(s.Started).Round(time.Second), s.Cycles)
+		switch {
+		case !s.CycleStarted.IsZero():
+			fmt.Fprintf(&b, "  cycle:    running for %s\n", now.Sub(s.CycleStarted).Round(time.Second))
+		case !s.NextPoll.IsZero():
+			fmt.Fprintf(&b, "  cycle:    idle, next in %s\n", max(s.NextPoll.Sub(now), 0).Round(time.Second))
+		}
+		if s.Paused != "" {
+			fmt.Fprintf(&b, "  paused:   %s\n", s.Paused)
+		}
+		if c := s.Last; c != nil {
+			fmt.Fprintf(&b, "  last:     %s, took %s: %d scanned, %d actioned, %d errors",
+				formatAgo(now.Sub(c.At)), c.Duration.Round(time.Millisecond), c.Scanned, c.Actioned, c.Errors)
+			if c.NotModified {
+				b.WriteString(", not modified")
+			}
+			if c.Err != "" {
+				fmt.Fprintf(&b, ", failed: %s", c.Err)
+			}
+			b.WriteString("\n")
+			if c.RateLimit.Limit > 0 {
+				fmt.Fprintf(&b, "  rate:     %d of %d left\n", c.RateLimit.Remaining, c.RateLimit.Limit)
+			}
+		}
+		fmt.Fprintf(&b, "  retries:  %d queued\n", len(s.Retries))
+		for _, m := range s.Retries {
+			step := "mark"
+			if m.Step == StepIgnore {
+				step = "ignore"
+			}
+			fmt.Fprintf(&b, "    %s  failed at %s, %d attempts\n", formatLabel(m.Decision), step, m.Attempts)
+		}
+		if len(s.Caches) > 0 {
+			var sizes []string
+			for _, name := range slices.Sorted(maps.Keys(s.Caches)) {
+				sizes = append(sizes, fmt.Sprintf("%s %d", name, s.Caches[name]))
+			}
+			fmt.Fprintf(&b, "  caches:   %s\n", strings.Join(sizes, ", "))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatStateDump(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	stuck := Decision{Notification: Notification{
+		Subject:    Subject{URL: "https://api.github.com/repos/acme/api/pulls/7"},
+		Repository: Repository{FullName: "acme/api"},
+	}}
+	snaps := []DaemonSnapshot{
+		{
+			Started:      now.Add(-3 * time.Hour),
+			Cycles:       42,
+			CycleStarted: now.Add(-12 * time.Minute),
+			Last: &CycleRecord{
+				At:        now.Add(-13 * time.Minute),
+				Duration:  1500 * time.Millisecond,
+				Scanned:   9,
+				Actioned:  5,
+				Errors:    1,
+				RateLimit: RateLimit{Limit: 5000, Remaining: 4990},
+			},
+			Retries: []PendingMutation{{Decision: stuck, Step: StepIgnore, Attempts: 2}},
+			Caches:  map[string]int{"topics": 12, "alerted": 4},
+		},
+		{
+			Name:     "alice",
+			Started:  now.Add(-time.Minute),
+			NextPoll: now.Add(45 * time.Second),
+			Paused:   "paused through the control API",
+		},
+	}
+	want := "state dump at 2026-03-02T12:00:00Z\n" +
+		"daemon: up 3h0m0s, 42 cycles\n" +
+		"  cycle:    running for 12m0s\n" +
+		"  last:     13m ago, took 1.5s: 9 scanned, 5 actioned, 1 errors\n" +
+		"  rate:     4990 of 5000 left\n" +
+		"  retries:  1 queued\n" +
+		"    acme/api#7  failed at ignore, 2 attempts\n" +
+		"  caches:   alerted 4, topics 12\n" +
+		"daemon alice: up 1m0s, 0 cycles\n" +
+		"  cycle:    idle, next in 45s\n" +
+		"  paused:   paused through the control API\n" +
+		"  retries:  0 queued\n"
+	if got := FormatStateDump(snaps, now); got != want {
+		t.Errorf("FormatStateDump() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GitHubStatus is the part of githubstatus.com's summary that decides whether
+// to pause: its unresolved incidents and the API component's state.
+type GitHubStatus struct {
+	Incidents  []StatusIncident
+	Components []StatusComponent
+}
+
+// StatusIncident is an unresolved incident on githubstatus.com.
+type StatusIncident struct {
+	Name   string
+	Impact string // "none", "minor", "major", or "critical"
+	URL    string
+}
+
+// StatusComponent is a githubstatus.com component, e.g. "API Requests".
+type StatusComponent struct {
+	Name   string
+	Status string // "operational", "degraded_performance", "partial_outage", or "major_outage"
+}
+
+// statusAPIComponent is the component every cycle depends on.
+const statusAPIComponent = "API Requests"
+
+// IncidentPause reports whether cycles should pause for a GitHub incident,
+// and why: the API is in an outage, or an incident has major or critical
+// impact. Degraded performance and minor incidents don't pause; retries
+// handle those.
+func IncidentPause(s GitHubStatus) (string, bool) {
+	for _, inc := range s.Incidents {
+		if inc.Impact == "major" || inc.Impact == "critical" {
+			reason := fmt.Sprintf("%s incident: %s", inc.Impact, inc.Name)
+			if inc.URL != "" {
+				reason += " (" + inc.URL + ")"
+			}
+			return reason, true
+		}
+	}
+	for _, c := range s.Components {
+		if strings.EqualFold(c.Name, statusAPIComponent) && (c.Status == "partial_outage" || c.Status == "major_outage") {
+			return fmt.Sprintf("%s: %s", c.Name, strings.ReplaceAll(c.Status, "_", " ")), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,34 @@
+package core
+
+import "testing"
+
+func TestIncidentPause(t *testing.T) {
+	api := func(status string) []StatusComponent {
+		return []StatusComponent{{Name: "Git Operations", Status: "major_outage"}, {Name: "API Requests", Status: status}}
+	}
+	tests := []struct {
+		name       string
+		status     GitHubStatus
+		wantReason string
+		wantPause  bool
+	}{
+		{"all clear", GitHubStatus{Components: api("operational")}, "", false},
+		{"degraded", GitHubStatus{Components: api("degraded_performance")}, "", false},
+		{"minor incident", GitHubStatus{Incidents: []StatusIncident{{Name: "Delayed webhooks", Impact: "minor"}}, Components: api("operational")}, "", false},
+		{"api outage", GitHubStatus{Components: api("partial_outage")}, "API Requests: partial outage", true},
+		{
+			"major incident",
+			GitHubStatus{Incidents: []StatusIncident{{Name: "Delayed webhooks", Impact: "minor"}, {Name: "Disruption with some GitHub services", Impact: "major", URL: "https://stspg.io/x"}}, Components: api("operational")},
+			"major incident: Disruption with some GitHub services (https://stspg.io/x)",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, pause := IncidentPause(tt.status)
+			if reason != tt.wantReason || pause != tt.wantPause {
+				t.Errorf("IncidentPause() = %q, %v, want %q, %v", reason, pause, tt.wantReason, tt.wantPause)
+			}
+		})
+	}
+}
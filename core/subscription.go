@@ -0,0 +1,94 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ThreadTarget is the thread a command was pointed at: a thread ID, or a
+// pull request whose thread has to be looked up.
+type ThreadTarget struct {
+	ThreadID string
+	PR       PRRef  // set when ThreadID is empty
+	Host     string // the PR URL's host; empty for a thread ID or org/repo#N
+}
+
+// ParseThreadTarget reads a thread ID ("123456"), a pull request URL
+// ("https://github.com/org/repo/pull/42"), or "org/repo#42".
+func ParseThreadTarget(s string) (ThreadTarget, error) {
+	s = strings.TrimSpace(s)
+	if _, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return ThreadTarget{ThreadID: s}, nil
+	}
+	if repo, num, ok := strings.Cut(s, "#"); ok && !strings.Contains(s, "://") {
+		owner, name, ok := strings.Cut(repo, "/")
+		n, err := strconv.Atoi(num)
+		if !ok || owner == "" || name == "" || strings.Contains(name, "/") || err != nil || n <= 0 {
+			return ThreadTarget{}, fmt.Errorf("%q isn't org/repo#number", s)
+		}
+		return ThreadTarget{PR: PRRef{Owner: owner, Repo: name, Number: n}}, nil
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return ThreadTarget{}, fmt.Errorf("%q isn't a thread ID, pull request URL, or org/repo#number", s)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 4 || parts[2] != "pull" {
+		return ThreadTarget{}, fmt.Errorf("%s isn't a pull request URL, like https://github.com/org/repo/pull/42", s)
+	}
+	n, err := strconv.Atoi(parts[3])
+	if err != nil || n <= 0 {
+		return ThreadTarget{}, fmt.Errorf("%s isn't a pull request URL, like https://github.com/org/repo/pull/42", s)
+	}
+	return ThreadTarget{PR: PRRef{Owner: parts[0], Repo: parts[1], Number: n}, Host: u.Host}, nil
+}
+
+// FindPRThread finds the notification thread about a pull request among a
+// repo's notifications.
+func FindPRThread(ns []Notification, pr PRRef) (Notification, bool) {
+	for _, n := range ns {
+		ref, err := ParseSubjectURL(n.Subject.URL)
+		if err == nil && n.Subject.Type == "PullRequest" && ref.Number == pr.Number &&
+			strings.EqualFold(ref.Owner, pr.Owner) && strings.EqualFold(ref.Repo, pr.Repo) {
+			return n, true
+		}
+	}
+	return Notification{}, false
+}
+
+// Subscription is a thread's subscription, as GitHub reports it.
+type Subscription struct {
+	Found      bool // false if the thread has no subscription of its own
+	Subscribed bool
+	Ignored    bool
+	Reason     string // why GitHub subscribed you, e.g. "review_requested"; often empty
+	CreatedAt  time.Time
+}
+
+// FormatSubscription describes a thread's subscription for mutemath
+// subscription.
+func FormatSubscription(n Notification, s Subscription, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Thread %s: %s  %q\n", n.ID, NotificationLabel(n), n.Subject.Title)
+	switch {
+	case !s.Found:
+		b.WriteString("  state:   not subscribed to the thread itself; the repo's watch settings apply\n")
+		return b.String()
+	case s.Ignored:
+		b.WriteString("  state:   ignored (muted: no notifications about it)\n")
+	case s.Subscribed:
+		b.WriteString("  state:   subscribed\n")
+	default:
+		b.WriteString("  state:   neither subscribed nor ignored; the repo's watch settings apply\n")
+	}
+	if s.Reason != "" {
+		fmt.Fprintf(&b, "  reason:  %s\n", s.Reason)
+	}
+	if !s.CreatedAt.IsZero() {
+		fmt.Fprintf(&b, "  created: %s (%s)\n", s.CreatedAt.UTC().Format(time.RFC3339), formatAgo(now.Sub(s.CreatedAt)))
+	}
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseThreadTarget(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ThreadTarget
+		wantErr bool
+	}{
+		{in: "1234567", want: ThreadTarget{ThreadID: "1234567"}},
+		{in: "https://github.com/acme/api/pull/42", want: ThreadTarget{PR: PRRef{Owner: "acme", Repo: "api", Number: 42}, Host: "github.com"}},
+		{in: "https://ghes.example.com/acme/api/pull/7/files", want: ThreadTarget{PR: PRRef{Owner: "acme", Repo: "api", Number: 7}, Host: "ghes.example.com"}},
+		{in: "acme/api#42", want: ThreadTarget{PR: PRRef{Owner: "acme", Repo: "api", Number: 42}}},
+		{in: "https://github.com/acme/api/issues/42", wantErr: true},
+		{in: "acme/api#x", wantErr: true},
+		{in: "acme#4", wantErr: true},
+		{in: "not a thread", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseThreadTarget(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseThreadTarget(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseThreadTarget(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFindPRThread(t *testing.T) {
+	ns := []Notification{
+		{ID: "1", Subject: Subject{Type: "Issue", URL: "https://api.github.com/repos/acme/api/issues/42"}},
+		{ID: "2", Subject: Subject{Type: "PullRequest", URL: "https://api.github.com/repos/acme/api/pulls/41"}},
+		{ID: "3", Subject: Subject{Type: "PullRequest", URL: "https://api.github.com/repos/Acme/API/pulls/42"}},
+	}
+	if n, ok := FindPRThread(ns, PRRef{Owner: "acme", Repo: "api", Number: 42}); !ok || n.ID != "3" {
+		t.Errorf("FindPRThread() = %q, %v, want thread 3", n.ID, ok)
+	}
+	if _, ok := FindPRThread(ns, PRRef{Owner: "acme", Repo: "api", Number: 43}); ok {
+		t.Error("FindPRThread() found a thread for a PR without one")
+	}
+}
+
+func TestFormatSubscription(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	n := Notification{
+		ID:         "9",
+		Subject:    Subject{Title: "Bump deps", URL: "https://api.github.com/repos/acme/api/pulls/42"},
+		Repository: Repository{FullName: "acme/api"},
+	}
+	got := FormatSubscription(n, Subscription{Found: true, Ignored: true, Reason: "review_requested", CreatedAt: now.Add(-3 * time.Hour)}, now)
+	want := "Thread 9: acme/api#42  \"Bump deps\"\n" +
+		"  state:   ignored (muted: no notifications about it)\n" +
+		"  reason:  review_requested\n" +
+		"  created: 2026-03-02T09:00:00Z (3h ago)\n"
+	if got != want {
+		t.Errorf("FormatSubscription() =\n%s\nwant\n%s", got, want)
+	}
+
+	got = FormatSubscription(n, Subscription{}, now)
+	want = "Thread 9: acme/api#42  \"Bump deps\"\n" +
+		"  state:   not subscribed to the thread itself; the repo's watch settings apply\n"
+	if got != want {
+		t.Errorf("FormatSubscription() without a subscription =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RunSummary is the outcome of a single run or daemon cycle, written by
+// --summary-file for wrapper scripts and monitoring.
+type RunSummary struct {
+	Host        string // empty unless processing several hosts
+	Finished    time.Time
+	Duration    time.Duration
+	Mode        Mode
+	Applied     bool
+	NotModified bool // the daemon's conditional listing found nothing new
+	Scanned     int
+	Muted       int // mutes that succeeded; with Applied false, mutes that would be made
+	Dimmed      int // likewise dims, marked read but left subscribed
+	Archived    int // likewise archives, marked done but left subscribed
+	Kept        int
+	Skipped     int
+	Errors      int         // mutes, dims, and archives that failed
+	RateLimit   RateLimit   // zero Limit if no rate-limit headers were seen
+	Err         string      // why the listing failed, empty on success
+	Rules       []RuleHit   // how many decisions each rule made, in rule order
+	Orgs        []OrgCounts // per-org counts, nil unless the run spanned several orgs
+}
+
+// SummarizeRun counts a run's decisions into a RunSummary, with a hit count
+// for each of rules. failed counts the marks that failed, and err is the
+// listing error, if any.
+func SummarizeRun(decisions []Decision, rules []Rule, failed MarkCounts, err error) RunSummary {
+	skip, keep, _ := CountByAction(decisions)
+	marked := CountMarks(decisions).Minus(failed)
+	s := RunSummary{
+		Scanned:  len(decisions),
+		Muted:    marked.Mute,
+		Dimmed:   marked.Dim,
+		Archived: marked.Archive,
+		Kept:     keep,
+		Skipped:  skip,
+		Errors:   failed.Total(),
+		Rules:    CountRuleHits(decisions, rules),
+		Orgs:     CountByOrg(decisions),
+	}
+	if err != nil {
+		s.Err = err.Error()
+	}
+	return s
+}
+
+// OrgCounts is how one org's decisions in a run came out, counted like
+// CountByAction and CountMarks. Mute, Dim, and Archive include marks that
+// failed, since errors aren't tracked per org.
+type OrgCounts struct {
+	Org                            string
+	Keep, Skip, Mute, Dim, Archive int
+}
+
+// CountByOrg counts decisions by action for each org, sorted by name, so a
+// run over several orgs shows which one the spam came from. Nil unless the
+// decisions span more than one org.
+func CountByOrg(decisions []Decision) []OrgCounts {
+	_, groups := CountDecisions(decisions, GroupOrg)
+	if len(groups) < 2 {
+		return nil
+	}
+	orgs := make([]OrgCounts, 0, len(groups))
+	for _, g := range groups {
+		orgs = append(orgs, OrgCounts{
+			Org:     g.Group,
+			Keep:    g.Keep + g.Defer,
+			Skip:    g.Skip,
+			Mute:    g.Mute,
+			Dim:     g.Dim,
+			Archive: g.Archive,
+		})
+	}
+	return orgs
+}
+
+// FormatOrgCounts renders the per-org counts as a table for the end of a
+// run, or "" without them.
+func FormatOrgCounts(orgs []OrgCounts) string {
+	if len(orgs) == 0 {
+		return ""
+	}
+	width := len("ORG")
+	for _, o := range orgs {
+		width = max(width, len(o.Org))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-*s  %4s  %4s  %4s  %4s  %7s\n", width, "ORG", "KEEP", "SKIP", "MUTE", "DIM", "ARCHIVE")
+	for _, o := range orgs {
+		fmt.Fprintf(&b, "%-*s  %4d  %4d  %4d  %4d  %7d\n", width, o.Org, o.Keep, o.Skip, o.Mute, o.Dim, o.Archive)
+	}
+	return b.String()
+}
+
+// RuleHit is how many notifications a rule decided in a run.
+type RuleHit struct {
+	Name  string
+	Count int
+}
+
+// CountRuleHits counts the decisions each rule made, listing every rule in
+// order so rules that matched nothing show up too. Nil without rules.
+func CountRuleHits(decisions []Decision, rules []Rule) []RuleHit {
+	if len(rules) == 0 {
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, d := range decisions {
+		if d.Rule != "" {
+			counts[d.Rule]++
+		}
+	}
+	hits := make([]RuleHit, 0, len(rules))
+	for _, r := range rules {
+		hits = append(hits, RuleHit{Name: r.Name, Count: counts[r.Name]})
+		delete(counts, r.Name) // a later rule of the same name never decides
+	}
+	return hits
+}
+
+// FormatRuleHits renders the rule hit counts as a line for the end of a
+// run, or "" without rules. Rules that matched nothing are marked unused.
+func FormatRuleHits(hits []RuleHit) string {
+	if len(hits) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(hits))
+	for _, h := range hits {
+		if h.Count == 0 {
+			parts = append(parts, h.Name+" 0 (unused)")
+		} else {
+			parts = append(parts, fmt.Sprintf("%s %d", h.Name, h.Count))
+		}
+	}
+	return "Rules matched: " + strings.Join(parts, ", ")
+}
+
+// SummaryPathForHost returns where a host's summary goes when processing
+// several hosts: the host name goes before the extension, so
+// "summary.json" becomes "summary.ghes.example.com.json". With an empty host
+// the path is returned as-is.
+func SummaryPathForHost(path, host string) string {
+	if host == "" {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + host + ext
+}
+
+// LedgerHeader is the header row of a --ledger CSV file.
+var LedgerHeader = []string{"timestamp", "inbox", "apply", "scanned", "muted", "kept", "skipped", "errors", "duration_seconds"}
+
+// LedgerRow is a run's row in a --ledger CSV file. inbox names the host or
+// user the run was for, empty with a single one.
+func LedgerRow(s RunSummary, inbox string) []string {
+	return []string{
+		s.Finished.UTC().Format(time.RFC3339),
+		inbox,
+		strconv.FormatBool(s.Applied),
+		strconv.Itoa(s.Scanned),
+		strconv.Itoa(s.Muted),
+		strconv.Itoa(s.Kept),
+		strconv.Itoa(s.Skipped),
+		strconv.Itoa(s.Errors),
+		strconv.FormatFloat(s.Duration.Seconds(), 'f', 3, 64),
+	}
+}
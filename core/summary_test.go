@@ -0,0 +1,119 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSummarizeRun(t *testing.T) {
+	decisions := []Decision{
+		{Action: ActionMute},
+		{Action: ActionMute},
+		{Action: ActionMute},
+		{Action: ActionKeep},
+		{Action: ActionSkip},
+		{Action: ActionSkip},
+		{Action: ActionDim},
+		{Action: ActionArchive},
+		{Action: ActionArchive},
+	}
+	got := SummarizeRun(decisions, nil, MarkCounts{Mute: 1, Archive: 1}, errors.New("list notifications page 2: EOF"))
+	want := RunSummary{Scanned: 9, Muted: 2, Dimmed: 1, Archived: 1, Kept: 1, Skipped: 2, Errors: 2, Err: "list notifications page 2: EOF"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SummarizeRun() = %+v, want %+v", got, want)
+	}
+
+	if got := SummarizeRun(nil, nil, MarkCounts{}, nil); !reflect.DeepEqual(got, RunSummary{}) {
+		t.Errorf("SummarizeRun(nil) = %+v, want zero", got)
+	}
+}
+
+func TestCountRuleHits(t *testing.T) {
+	rules := []Rule{{Name: "drafts"}, {Name: "renovate"}, {Name: "backend"}, {Name: "drafts"}}
+	decisions := []Decision{
+		{Action: ActionMute, Rule: "drafts"},
+		{Action: ActionMute, Rule: "drafts"},
+		{Action: ActionKeep, Rule: "backend"},
+		{Action: ActionMute},
+	}
+	got := CountRuleHits(decisions, rules)
+	want := []RuleHit{{"drafts", 2}, {"renovate", 0}, {"backend", 1}, {"drafts", 0}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CountRuleHits() = %+v, want %+v", got, want)
+	}
+	if got := CountRuleHits(decisions, nil); got != nil {
+		t.Errorf("CountRuleHits(no rules) = %+v, want nil", got)
+	}
+
+	if got, want := FormatRuleHits(want), "Rules matched: drafts 2, renovate 0 (unused), backend 1, drafts 0 (unused)"; got != want {
+		t.Errorf("FormatRuleHits() = %q, want %q", got, want)
+	}
+	if got := FormatRuleHits(nil); got != "" {
+		t.Errorf("FormatRuleHits(nil) = %q, want empty", got)
+	}
+}
+
+func TestCountByOrg(t *testing.T) {
+	in := func(owner string, a Action) Decision {
+		return Decision{Action: a, Notification: Notification{Repository: Repository{Owner: owner, FullName: owner + "/repo"}}}
+	}
+	decisions := []Decision{
+		in("zeta", ActionMute),
+		in("acme", ActionKeep),
+		in("acme", ActionDefer),
+		in("Acme", ActionDim),
+		in("zeta", ActionSkip),
+		in("zeta", ActionArchive),
+	}
+	want := []OrgCounts{{Org: "acme", Keep: 2, Dim: 1}, {Org: "zeta", Skip: 1, Mute: 1, Archive: 1}}
+	if got := CountByOrg(decisions); !reflect.DeepEqual(got, want) {
+		t.Errorf("CountByOrg() = %+v, want %+v", got, want)
+	}
+	if got := CountByOrg(decisions[1:3]); got != nil {
+		t.Errorf("CountByOrg() with one org = %+v, want nil", got)
+	}
+
+	wantTable := "ORG   KEEP  SKIP  MUTE   DIM  ARCHIVE\n" +
+		"acme     2     0     0     1        0\n" +
+		"zeta     0     1     1     0        1\n"
+	if got := FormatOrgCounts(want); got != wantTable {
+		t.Errorf("FormatOrgCounts() =\n%s\nwant:\n%s", got, wantTable)
+	}
+	if got := FormatOrgCounts(nil); got != "" {
+		t.Errorf("FormatOrgCounts(nil) = %q, want empty", got)
+	}
+}
+
+func TestSummaryPathForHost(t *testing.T) {
+	tests := []struct {
+		path, host, want string
+	}{
+		{"/var/lib/mutemath/summary.json", "", "/var/lib/mutemath/summary.json"},
+		{"/var/lib/mutemath/summary.json", "ghes.example.com", "/var/lib/mutemath/summary.ghes.example.com.json"},
+		{"summary", "github.com", "summary.github.com"},
+		{"out.d/summary.json", "github.com", "out.d/summary.github.com.json"},
+	}
+	for _, tt := range tests {
+		if got := SummaryPathForHost(tt.path, tt.host); got != tt.want {
+			t.Errorf("SummaryPathForHost(%q, %q) = %q, want %q", tt.path, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestLedgerRow(t *testing.T) {
+	s := RunSummary{
+		Finished: time.Date(2026, 3, 2, 9, 30, 0, 0, time.FixedZone("EST", -5*3600)),
+		Duration: 2345 * time.Millisecond,
+		Applied:  true,
+		Scanned:  9, Muted: 4, Kept: 2, Skipped: 2, Errors: 1,
+	}
+	want := []string{"2026-03-02T14:30:00Z", "ghes.example.com", "true", "9", "4", "2", "2", "1", "2.345"}
+	if got := LedgerRow(s, "ghes.example.com"); !reflect.DeepEqual(got, want) {
+		t.Errorf("LedgerRow() = %q, want %q", got, want)
+	}
+	if len(want) != len(LedgerHeader) {
+		t.Errorf("LedgerRow() has %d fields, LedgerHeader %d", len(want), len(LedgerHeader))
+	}
+}
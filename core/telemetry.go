@@ -0,0 +1,108 @@
+package core
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// OTLP/HTTP exporter settings, following the OpenTelemetry environment
+// variable spec (https://opentelemetry.io/docs/specs/otel/protocol/exporter/).
+
+// DefaultOTLPEndpoint is the OTLP/HTTP collector address when none is configured.
+const DefaultOTLPEndpoint = "http://localhost:4318"
+
+// OTLPSignalURL returns the export URL for a signal ("traces" or "metrics").
+// A signal-specific endpoint is used as-is; the base endpoint gets /v1/<signal>
+// appended.
+func OTLPSignalURL(base, specific, signal string) string {
+	if specific != "" {
+		return specific
+	}
+	if base == "" {
+		base = DefaultOTLPEndpoint
+	}
+	return strings.TrimSuffix(base, "/") + "/v1/" + signal
+}
+
+// CheckOTLPProtocol validates OTEL_EXPORTER_OTLP_PROTOCOL. Only http/json is
+// supported, since the protobuf encodings would need the OpenTelemetry SDK.
+func CheckOTLPProtocol(s string) error {
+	if s != "" && s != "http/json" {
+		return fmt.Errorf("unsupported OTEL_EXPORTER_OTLP_PROTOCOL %q (only http/json is supported)", s)
+	}
+	return nil
+}
+
+// ParseOTLPHeaders parses OTEL_EXPORTER_OTLP_HEADERS: comma-separated
+// key=value pairs with URL-encoded values, e.g. "api-key=abc,x-tenant=a%20b".
+func ParseOTLPHeaders(s string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_HEADERS entry %q (want key=value)", pair)
+		}
+		value, err := url.QueryUnescape(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_HEADERS value for %s: %w", k, err)
+		}
+		headers[k] = value
+	}
+	return headers, nil
+}
+
+// SpanNameForRequest names an API call span by its route, keeping span names
+// low-cardinality: "GET /repos/{owner}/{repo}/pulls/{number}".
+func SpanNameForRequest(method, rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return method
+	}
+	path := u.Path
+	// GHES serves the API under /api/v3.
+	path = strings.TrimPrefix(path, "/api/v3")
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 0; i < len(parts); i++ {
+		switch {
+		case parts[0] == "repos" && i == 1:
+			parts[i] = "{owner}"
+		case parts[0] == "repos" && i == 2:
+			parts[i] = "{repo}"
+		case parts[0] == "orgs" && i == 1:
+			parts[i] = "{org}"
+		case parts[0] == "orgs" && i == 3 && parts[2] == "teams":
+			parts[i] = "{team}"
+		case i > 0 && parts[i-1] == "contents":
+			// File paths have any number of segments.
+			parts = append(parts[:i], "{path}")
+		case i > 0 && parts[i-1] == "threads":
+			parts[i] = "{thread_id}"
+		case parts[i] != "" && strings.IndexFunc(parts[i], func(r rune) bool { return !unicode.IsDigit(r) }) < 0:
+			parts[i] = "{number}"
+		}
+	}
+	return method + " /" + strings.Join(parts, "/")
+}
+
+// IsLoopbackAddr reports whether a listen address like "127.0.0.1:8080" or
+// "localhost:8080" only accepts local connections. ":8080" listens on every
+// interface, so it isn't.
+func IsLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOTLPSignalURL(t *testing.T) {
+	tests := []struct {
+		base, specific, signal string
+		want                   string
+	}{
+		{"", "", "traces", "http://localhost:4318/v1/traces"},
+		{"https://otel.example.com/", "", "metrics", "https://otel.example.com/v1/metrics"},
+		{"https://otel.example.com", "https://traces.example.com/ingest", "traces", "https://traces.example.com/ingest"},
+	}
+	for _, tt := range tests {
+		if got := OTLPSignalURL(tt.base, tt.specific, tt.signal); got != tt.want {
+			t.Errorf("OTLPSignalURL(%q, %q, %q) = %q, want %q", tt.base, tt.specific, tt.signal, got, tt.want)
+		}
+	}
+}
+
+func TestCheckOTLPProtocol(t *testing.T) {
+	for _, s := range []string{"", "http/json"} {
+		if err := CheckOTLPProtocol(s); err != nil {
+			t.Errorf("CheckOTLPProtocol(%q) error = %v", s, err)
+		}
+	}
+	if err := CheckOTLPProtocol("grpc"); err == nil || !strings.Contains(err.Error(), "http/json") {
+		t.Errorf("CheckOTLPProtocol(grpc) error = %v", err)
+	}
+}
+
+func TestParseOTLPHeaders(t *testing.T) {
+	got, err := ParseOTLPHeaders("api-key=abc, x-tenant=a%20b,,")
+	if err != nil {
+		t.Fatalf("ParseOTLPHeaders() error = %v", err)
+	}
+	if len(got) != 2 || got["api-key"] != "abc" || got["x-tenant"] != "a b" {
+		t.Errorf("ParseOTLPHeaders() = %v", got)
+	}
+
+	for _, bad := range []string{"novalue", "=v", "k=%zz"} {
+		if _, err := ParseOTLPHeaders(bad); err == nil {
+			t.Errorf("ParseOTLPHeaders(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestSpanNameForRequest(t *testing.T) {
+	tests := []struct {
+		method, url string
+		want        string
+	}{
+		{"GET", "https://api.github.com/notifications?per_page=50&page=2", "GET /notifications"},
+		{"GET", "https://api.github.com/user", "GET /user"},
+		{"GET", "https://api.github.com/repos/org/repo/pulls/42/requested_reviewers", "GET /repos/{owner}/{repo}/pulls/{number}/requested_reviewers"},
+		{"PATCH", "https://api.github.com/notifications/threads/123", "PATCH /notifications/threads/{thread_id}"},
+		{"PUT", "https://api.github.com/notifications/threads/123/subscription", "PUT /notifications/threads/{thread_id}/subscription"},
+		{"GET", "https://ghe.example.com/api/v3/repos/org/policies/contents/dir/p.json?ref=main", "GET /repos/{owner}/{repo}/contents/{path}"},
+		{"GET", "https://api.github.com/repos/org/repo/releases/latest", "GET /repos/{owner}/{repo}/releases/latest"},
+		{"GET", "https://api.github.com/orgs/org/teams/backend/members?per_page=100&page=1", "GET /orgs/{org}/teams/{team}/members"},
+		{"POST", "https://api.github.com/graphql", "POST /graphql"},
+	}
+	for _, tt := range tests {
+		if got := SpanNameForRequest(tt.method, tt.url); got != tt.want {
+			t.Errorf("SpanNameForRequest(%q, %q) = %q, want %q", tt.method, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestIsLoopbackAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1:8080", true},
+		{"localhost:8080", true},
+		{"[::1]:8080", true},
+		{":8080", false},
+		{"0.0.0.0:8080", false},
+		{"192.168.1.5:8080", false},
+		{"8080", false},
+	}
+	for _, tt := range tests {
+		if got := IsLoopbackAddr(tt.addr); got != tt.want {
+			t.Errorf("IsLoopbackAddr(%q) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
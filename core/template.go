@@ -0,0 +1,153 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// AlertData is what a user alert template can reference.
+type AlertData struct {
+	Repo   string   // "org/repo"
+	Org    string   // "org"
+	Number int      // PR number, zero if the subject isn't a PR
+	Title  string   // subject title
+	URL    string   // browser URL
+	Teams  []string // requested team slugs
+	Reason string   // classification reason, e.g. "direct review request"
+	Action string   // "KEEP", "MUTE", ...
+}
+
+// AlertTemplate renders alerts from a user-supplied text/template. The first
+// line of output is the alert title and the rest is the body.
+type AlertTemplate struct {
+	tmpl *template.Template
+}
+
+var alertTemplateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// ParseAlertTemplate parses a template and checks its field names against
+// AlertData, so typos are reported at startup rather than on the first alert.
+// It doesn't execute the template: whether {{index .Teams 1}} works depends on
+// the alert, so errors like that are Render's. An empty string returns nil,
+// meaning the default alert format.
+func ParseAlertTemplate(s string) (*AlertTemplate, error) {
+	if s == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("alert").Funcs(alertTemplateFuncs).Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alert template: %w", err)
+	}
+	if err := checkAlertFields(tmpl.Tree.Root, true); err != nil {
+		return nil, fmt.Errorf("invalid alert template: %w", err)
+	}
+	return &AlertTemplate{tmpl: tmpl}, nil
+}
+
+// checkAlertFields reports the first field an AlertData doesn't have. Fields
+// of dot are only checked where dot is the AlertData: inside range and with,
+// it's something else. Fields of $ are checked everywhere.
+func checkAlertFields(node parse.Node, dotIsData bool) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Nodes {
+			if err := checkAlertFields(c, dotIsData); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkAlertFields(n.Pipe, dotIsData)
+	case *parse.TemplateNode:
+		return checkAlertFields(n.Pipe, dotIsData)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				if err := checkAlertFields(arg, dotIsData); err != nil {
+					return err
+				}
+			}
+		}
+	case *parse.ChainNode:
+		return checkAlertFields(n.Node, dotIsData)
+	case *parse.FieldNode:
+		if dotIsData {
+			return checkAlertField(n.Ident[0])
+		}
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" && len(n.Ident) > 1 {
+			return checkAlertField(n.Ident[1])
+		}
+	case *parse.IfNode:
+		return checkAlertBranch(n.BranchNode, dotIsData, dotIsData)
+	case *parse.RangeNode:
+		return checkAlertBranch(n.BranchNode, dotIsData, false)
+	case *parse.WithNode:
+		return checkAlertBranch(n.BranchNode, dotIsData, false)
+	}
+	return nil
+}
+
+// checkAlertBranch checks an if, range, or with: its else branch keeps the
+// outer dot, and its body has bodyDotIsData.
+func checkAlertBranch(n parse.BranchNode, dotIsData, bodyDotIsData bool) error {
+	if err := checkAlertFields(n.Pipe, dotIsData); err != nil {
+		return err
+	}
+	if err := checkAlertFields(n.List, bodyDotIsData); err != nil {
+		return err
+	}
+	return checkAlertFields(n.ElseList, dotIsData)
+}
+
+func checkAlertField(name string) error {
+	if _, ok := reflect.TypeFor[AlertData]().FieldByName(name); !ok {
+		return fmt.Errorf("no field .%s (fields are .Repo, .Org, .Number, .Title, .URL, .Teams, .Reason, and .Action)", name)
+	}
+	return nil
+}
+
+// Render builds an alert for a decision. A nil template uses AlertForDecision.
+func (t *AlertTemplate) Render(d Decision) (Alert, error) {
+	if t == nil {
+		return AlertForDecision(d), nil
+	}
+	var sb strings.Builder
+	if err := t.tmpl.Execute(&sb, alertData(d)); err != nil {
+		return Alert{}, fmt.Errorf("invalid alert template: %w", err)
+	}
+	title, body, _ := strings.Cut(strings.TrimSpace(sb.String()), "\n")
+	return Alert{
+		Title: strings.TrimSpace(title),
+		Body:  strings.TrimSpace(body),
+		URL:   HTMLURL(d.Notification.Subject.URL),
+	}, nil
+}
+
+func alertData(d Decision) AlertData {
+	data := AlertData{
+		Repo:   d.Notification.Repository.FullName,
+		Org:    d.Notification.Repository.Owner,
+		Title:  d.Notification.Subject.Title,
+		URL:    HTMLURL(d.Notification.Subject.URL),
+		Teams:  d.Teams,
+		Reason: d.Reason,
+		Action: d.Action.String(),
+	}
+	if ref, err := ParseSubjectURL(d.Notification.Subject.URL); err == nil {
+		data.Number = ref.Number
+	}
+	return data
+}
@@ -0,0 +1,108 @@
+package core
+
+import "testing"
+
+func TestParseAlertTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "empty means default", tmpl: "", wantNil: true},
+		{name: "valid", tmpl: "{{.Repo}}#{{.Number}}\n{{.Title}}"},
+		{name: "funcs", tmpl: "{{upper .Action}} {{join .Teams \", \"}}"},
+		{name: "syntax error", tmpl: "{{.Repo", wantErr: true},
+		{name: "unknown field", tmpl: "{{.Author}}", wantErr: true},
+		{name: "unknown func", tmpl: "{{shout .Repo}}", wantErr: true},
+		{name: "index past one team", tmpl: "{{index .Teams 1}}"},
+		{name: "dot inside range", tmpl: "{{range .Teams}}{{.}} {{$.Repo}}{{end}}"},
+		{name: "unknown field of $", tmpl: "{{range .Teams}}{{$.Author}}{{end}}", wantErr: true},
+		{name: "unknown field in if", tmpl: "{{if .Teams}}{{.Author}}{{end}}", wantErr: true},
+		{name: "unknown field in else", tmpl: "{{with .Teams}}{{.}}{{else}}{{.Author}}{{end}}", wantErr: true},
+		{name: "unknown field in parens", tmpl: "{{upper (.Author)}}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAlertTemplate(tt.tmpl)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("ParseAlertTemplate() want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAlertTemplate() error = %v", err)
+			}
+			if (got == nil) != tt.wantNil {
+				t.Errorf("ParseAlertTemplate() = %v, wantNil %v", got, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestAlertTemplateRender(t *testing.T) {
+	d := Decision{
+		Notification: Notification{
+			Subject:    Subject{Title: "Fix bug", URL: "https://api.github.com/repos/org/repo/pulls/42", Type: "PullRequest"},
+			Repository: Repository{FullName: "org/repo", Owner: "org"},
+		},
+		Action: ActionKeep,
+		Reason: "direct review request",
+		Teams:  []string{"backend", "infra"},
+	}
+
+	t.Run("title and body", func(t *testing.T) {
+		tmpl, err := ParseAlertTemplate("[{{.Org}}] {{.Repo}}#{{.Number}}\n{{.Title}}\nteams: {{join .Teams \", \"}} ({{.Reason}})\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := tmpl.Render(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := Alert{
+			Title: "[org] org/repo#42",
+			Body:  "Fix bug\nteams: backend, infra (direct review request)",
+			URL:   "https://github.com/org/repo/pull/42",
+		}
+		if got != want {
+			t.Errorf("Render() = %+v\nwant %+v", got, want)
+		}
+	})
+
+	t.Run("single line is title only", func(t *testing.T) {
+		tmpl, err := ParseAlertTemplate("{{.Action}}: {{.Title}}")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := tmpl.Render(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Title != "KEEP: Fix bug" || got.Body != "" {
+			t.Errorf("Render() = %+v", got)
+		}
+	})
+
+	t.Run("error depending on the alert", func(t *testing.T) {
+		tmpl, err := ParseAlertTemplate("{{index .Teams 2}}")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tmpl.Render(d); err == nil {
+			t.Error("Render() with two teams want error")
+		}
+	})
+
+	t.Run("nil template uses default", func(t *testing.T) {
+		var tmpl *AlertTemplate
+		got, err := tmpl.Render(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != AlertForDecision(d) {
+			t.Errorf("Render() = %+v, want %+v", got, AlertForDecision(d))
+		}
+	})
+}
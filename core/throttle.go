@@ -0,0 +1,47 @@
+package core
+
+import "time"
+
+// Client-side request pacing. GitHub's secondary rate limits penalize bursts
+// of requests even when the hourly budget has room, so every request a client
+// sends goes through one shared bucket, however many workers are running.
+const (
+	DefaultRequestRate  = 10 // requests per second, sustained
+	DefaultRequestBurst = 10 // requests sent back to back before pacing starts
+)
+
+// TokenBucket paces requests: up to Burst at once, refilling at Rate tokens
+// per second. Callers serialize access to it.
+type TokenBucket struct {
+	Rate  float64 // zero disables pacing
+	Burst int
+
+	tokens float64
+	last   time.Time
+}
+
+// TryTake spends a token at now if one is available. Otherwise it spends
+// nothing and returns how long until one is, so a scheduler can then give it
+// to whichever waiting request should go first.
+func (b *TokenBucket) TryTake(now time.Time) (ok bool, wait time.Duration) {
+	if b.Rate <= 0 {
+		return true, 0
+	}
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / b.Rate * float64(time.Second))
+}
+
+func (b *TokenBucket) refill(now time.Time) {
+	switch {
+	case b.last.IsZero():
+		b.tokens = float64(b.Burst)
+		b.last = now
+	case now.After(b.last):
+		b.tokens = min(float64(b.Burst), b.tokens+now.Sub(b.last).Seconds()*b.Rate)
+		b.last = now
+	}
+}
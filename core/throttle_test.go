@@ -0,0 +1,52 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		after    time.Duration // since start
+		wantOK   bool
+		wantWait time.Duration
+	}{
+		{"burst 1", 0, true, 0},
+		{"burst 2", 0, true, 0},
+		{"burst 3", 0, true, 0},
+		{"empty", 0, false, 500 * time.Millisecond},
+		{"still empty", 0, false, 500 * time.Millisecond},
+		{"refilled one", 500 * time.Millisecond, true, 0},
+		{"partly refilled", 750 * time.Millisecond, false, 250 * time.Millisecond},
+		{"refilled after a pause", 10 * time.Second, true, 0},
+	}
+	b := TokenBucket{Rate: 2, Burst: 3}
+	for _, tt := range tests {
+		if ok, wait := b.TryTake(start.Add(tt.after)); ok != tt.wantOK || wait != tt.wantWait {
+			t.Errorf("%s: TryTake() = %v, %s, want %v, %s", tt.name, ok, wait, tt.wantOK, tt.wantWait)
+		}
+	}
+}
+
+func TestTokenBucketRefillCapsAtBurst(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := TokenBucket{Rate: 1, Burst: 2}
+	b.TryTake(start)
+	now := start.Add(time.Hour)
+	for i, want := range []bool{true, true, false} {
+		if ok, _ := b.TryTake(now); ok != want {
+			t.Errorf("take %d after an hour: TryTake() = %v, want %v", i+1, ok, want)
+		}
+	}
+}
+
+func TestTokenBucketDisabled(t *testing.T) {
+	var b TokenBucket
+	for range 100 {
+		if ok, wait := b.TryTake(time.Time{}); !ok || wait != 0 {
+			t.Fatalf("TryTake() = %v, %s with pacing disabled, want true, 0", ok, wait)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Trial is a --trial period: runs apply their mutes until it ends, with a
+// daily digest of them and a rollback of everything muted since it started.
+type Trial struct {
+	Started    time.Time
+	Until      time.Time
+	LastDigest time.Time // zero before the first digest
+}
+
+// DigestInterval is how often a trial sends a digest of its mutes.
+const DigestInterval = 24 * time.Hour
+
+// digestListMax bounds the mutes a digest lists one by one.
+const digestListMax = 20
+
+// ParseDays reads a --trial length: a number of days like "7d", or a Go
+// duration like "36h". It must be positive.
+func ParseDays(s string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid length %q (want e.g. 7d or 36h)", s)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("invalid length %q (want e.g. 7d or 36h)", s)
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("length %q must be positive", s)
+	}
+	return d, nil
+}
+
+// NewTrial starts a trial of length at now.
+func NewTrial(now time.Time, length time.Duration) Trial {
+	return Trial{Started: now, Until: now.Add(length)}
+}
+
+// Active reports whether the trial's runs still apply their mutes.
+func (t Trial) Active(now time.Time) bool {
+	return now.Before(t.Until)
+}
+
+// DigestDue reports whether a digest is due: a day after the trial started
+// or the last digest, up to one final digest once the trial has ended.
+func (t Trial) DigestDue(now time.Time) bool {
+	from := t.Started
+	if !t.LastDigest.IsZero() {
+		from = t.LastDigest
+	}
+	return now.Sub(from) >= DigestInterval && t.LastDigest.Before(t.Until)
+}
+
+// TrialMutes returns the trial's mutes that a rollback would undo, newest
+// first: each thread's latest mute since the trial started, unless it was
+// undone since.
+func TrialMutes(records []MutationRecord, t Trial, now time.Time) []MutationRecord {
+	return UndoCandidates(records, now, now.Sub(t.Started), 0)
+}
+
+// TrialDigest builds the digest of what the trial muted since the last one,
+// or since it started.
+func TrialDigest(records []MutationRecord, t Trial, now time.Time) Alert {
+	from := t.Started
+	if !t.LastDigest.IsZero() {
+		from = t.LastDigest
+	}
+	var muted []MutationRecord
+	for _, r := range records {
+		if !r.Kept && !r.Undo && !r.Time.Before(from) && r.Time.Before(now) {
+			muted = append(muted, r)
+		}
+	}
+
+	var b strings.Builder
+	for i, r := range muted {
+		if i == digestListMax {
+			fmt.Fprintf(&b, "…and %d more\n", len(muted)-digestListMax)
+			break
+		}
+		fmt.Fprintf(&b, "%s  %q\n", r.Label, r.Title)
+	}
+	if len(muted) > 0 {
+		b.WriteString("\n")
+	}
+	if t.Active(now) {
+		fmt.Fprintf(&b, "The trial ends in %s. Undo a mute with mutemath undo, or everything with mutemath trial rollback --apply.", FormatAge(t.Until.Sub(now)))
+	} else {
+		b.WriteString("The trial has ended, so runs no longer mute anything: switch --trial to --apply to keep muting, or undo everything with mutemath trial rollback --apply.")
+	}
+	return Alert{
+		Title: fmt.Sprintf("mutemath trial: muted %d notifications since %s", len(muted), from.Format("Jan 2 15:04")),
+		Body:  b.String(),
+	}
+}
+
+// FormatTrialStatus describes a trial for mutemath trial.
+func FormatTrialStatus(t Trial, mutes int, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Trial started %s.\n", t.Started.Format(time.RFC3339))
+	if t.Active(now) {
+		fmt.Fprintf(&b, "It ends %s (in %s); until then, --trial runs mute.\n", t.Until.Format(time.RFC3339), FormatAge(t.Until.Sub(now)))
+	} else {
+		fmt.Fprintf(&b, "It ended %s; --trial runs no longer mute. Switch to --apply to keep muting.\n", t.Until.Format(time.RFC3339))
+	}
+	fmt.Fprintf(&b, "%d mutes to roll back; preview with mutemath trial rollback, undo with --apply.\n", mutes)
+	return b.String()
+}
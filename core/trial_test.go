@@ -0,0 +1,86 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDays(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "7d", want: 7 * 24 * time.Hour},
+		{in: "36h", want: 36 * time.Hour},
+		{in: "0d", wantErr: true},
+		{in: "-2h", wantErr: true},
+		{in: "week", wantErr: true},
+		{in: "1.5d", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDays(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseDays(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestTrialDigestDue(t *testing.T) {
+	start := time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)
+	trial := NewTrial(start, 3*24*time.Hour)
+	tests := []struct {
+		name       string
+		lastDigest time.Time
+		now        time.Time
+		want       bool
+	}{
+		{"first day", time.Time{}, start.Add(23 * time.Hour), false},
+		{"a day in", time.Time{}, start.Add(24 * time.Hour), true},
+		{"just sent", start.Add(24 * time.Hour), start.Add(30 * time.Hour), false},
+		{"final digest after the end", start.Add(72*time.Hour - time.Minute), start.Add(96 * time.Hour), true},
+		{"none after the final one", start.Add(96 * time.Hour), start.Add(200 * time.Hour), false},
+	}
+	for _, tt := range tests {
+		tr := trial
+		tr.LastDigest = tt.lastDigest
+		if got := tr.DigestDue(tt.now); got != tt.want {
+			t.Errorf("%s: DigestDue() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if !trial.Active(start.Add(71*time.Hour)) || trial.Active(start.Add(72*time.Hour)) {
+		t.Errorf("Active() should end at Until")
+	}
+}
+
+func TestTrialDigest(t *testing.T) {
+	start := time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)
+	trial := NewTrial(start, 7*24*time.Hour)
+	trial.LastDigest = start.Add(24 * time.Hour)
+	now := start.Add(48 * time.Hour)
+	records := []MutationRecord{
+		{Time: start.Add(time.Hour), ThreadID: "1", Label: "acme/api#1", Title: "In the last digest"},
+		{Time: start.Add(30 * time.Hour), ThreadID: "2", Label: "acme/api#2", Title: "Bump x"},
+		{Time: start.Add(31 * time.Hour), ThreadID: "3", Label: "acme/web#3", Title: "Kept", Kept: true},
+		{Time: start.Add(32 * time.Hour), ThreadID: "2", Label: "acme/api#2", Undo: true},
+		{Time: start.Add(33 * time.Hour), ThreadID: "4", Label: "oss/lib#4", Title: "Fix y"},
+	}
+	a := TrialDigest(records, trial, now)
+	if want := "mutemath trial: muted 2 notifications since Mar 10 09:00"; a.Title != want {
+		t.Errorf("Title = %q, want %q", a.Title, want)
+	}
+	wantBody := "acme/api#2  \"Bump x\"\noss/lib#4  \"Fix y\"\n\nThe trial ends in 5d."
+	if !strings.HasPrefix(a.Body, wantBody) {
+		t.Errorf("Body =\n%s\nwant prefix\n%s", a.Body, wantBody)
+	}
+
+	ended := TrialDigest(records, trial, start.Add(8*24*time.Hour))
+	if !strings.Contains(ended.Body, "The trial has ended") {
+		t.Errorf("after the end, Body = %q", ended.Body)
+	}
+
+	if got := TrialMutes(records, trial, now); len(got) != 2 || got[0].ThreadID != "4" || got[1].ThreadID != "1" {
+		t.Errorf("TrialMutes() = %+v, want threads 4 and 1", got)
+	}
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// UserSpec is one user whose inbox a multi-user daemon manages, from the
+// config file's users list. Like a host's, the token is read from the
+// environment variable TokenEnv.
+type UserSpec struct {
+	Name     string // names the user's state and log lines, e.g. "alice"
+	Host     string // empty for GH_HOST or github.com
+	TokenEnv string // e.g. "ALICE_GH_TOKEN"
+	Config   string // path to the user's own config file, for rules; empty for none
+}
+
+// UserError is a problem with one entry in the users list.
+type UserError struct {
+	Index int // 0-based position in the users list
+	Field string
+	Err   error
+}
+
+func (e *UserError) Error() string {
+	return fmt.Sprintf("users[%d]: %s: %s", e.Index, e.Field, e.Err)
+}
+
+// userNamePattern keeps user names usable in state names and log lines.
+var userNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// CheckUsers validates a users list, returning every problem found: each user
+// needs a unique name of lowercase letters, digits, '-', and '_', and a token
+// variable, and a host must be a bare hostname.
+func CheckUsers(users []UserSpec) []*UserError {
+	var errs []*UserError
+	seen := make(map[string]int)
+	for i, u := range users {
+		switch {
+		case u.Name == "":
+			errs = append(errs, &UserError{Index: i, Field: "name", Err: fmt.Errorf("empty name")})
+		case !userNamePattern.MatchString(u.Name):
+			errs = append(errs, &UserError{Index: i, Field: "name", Err: fmt.Errorf("%q should be lowercase letters, digits, '-', and '_'", u.Name)})
+		default:
+			if prev, dup := seen[u.Name]; dup {
+				errs = append(errs, &UserError{Index: i, Field: "name", Err: fmt.Errorf("%s is already listed at users[%d]", u.Name, prev)})
+			}
+			seen[u.Name] = i
+		}
+		if strings.Contains(u.Host, "/") {
+			errs = append(errs, &UserError{Index: i, Field: "host", Err: fmt.Errorf("%q should be a bare hostname, e.g. ghes.example.com", u.Host)})
+		}
+		if strings.TrimSpace(u.TokenEnv) == "" {
+			errs = append(errs, &UserError{Index: i, Field: "token_env", Err: fmt.Errorf("needs the name of the environment variable holding the user's token")})
+		}
+	}
+	return errs
+}
+
+// MergeUserConfig gives a user the shared config plus their own: their rules
+// are checked before the shared ones, their keep lists and switches add to
+// the shared ones, and their business hours, scoring, and deadline window
+// replace the shared ones when set.
+func MergeUserConfig(shared, user Config) Config {
+	cfg := shared
+	cfg.Rules = append(append([]Rule(nil), user.Rules...), shared.Rules...)
+	cfg.KeepAuthors = append(append([]string(nil), shared.KeepAuthors...), user.KeepAuthors...)
+	cfg.KeepMentions = shared.KeepMentions || user.KeepMentions
+	cfg.KeepAssigned = shared.KeepAssigned || user.KeepAssigned
+	cfg.MuteApproved = shared.MuteApproved || user.MuteApproved
+	cfg.MuteTeammateReviewing = shared.MuteTeammateReviewing || user.MuteTeammateReviewing
+	cfg.KeepFailedChecks = shared.KeepFailedChecks || user.KeepFailedChecks
+	cfg.PinParticipated = shared.PinParticipated || user.PinParticipated
+	cfg.Pins = append(append([]ThreadTarget(nil), shared.Pins...), user.Pins...)
+	if user.DeadlineWithin > 0 {
+		cfg.DeadlineWithin = user.DeadlineWithin
+	}
+	if user.BusinessHours != nil {
+		cfg.BusinessHours = user.BusinessHours
+	}
+	if user.Scoring != nil {
+		cfg.Scoring = user.Scoring
+	}
+	return cfg
+}
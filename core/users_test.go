@@ -0,0 +1,70 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCheckUsers(t *testing.T) {
+	tests := []struct {
+		name  string
+		users []UserSpec
+		want  []string
+	}{
+		{name: "valid", users: []UserSpec{{Name: "alice", TokenEnv: "ALICE_TOKEN"}, {Name: "bob_2", Host: "ghes.example.com", TokenEnv: "BOB_TOKEN", Config: "bob.json"}}},
+		{name: "empty name", users: []UserSpec{{TokenEnv: "T"}}, want: []string{"users[0]: name: empty name"}},
+		{name: "bad name", users: []UserSpec{{Name: "Alice Smith", TokenEnv: "T"}}, want: []string{`users[0]: name: "Alice Smith" should be lowercase letters, digits, '-', and '_'`}},
+		{name: "duplicate", users: []UserSpec{{Name: "alice", TokenEnv: "A"}, {Name: "alice", TokenEnv: "B"}}, want: []string{"users[1]: name: alice is already listed at users[0]"}},
+		{name: "url host", users: []UserSpec{{Name: "alice", Host: "https://ghes.example.com", TokenEnv: "T"}}, want: []string{`users[0]: host: "https://ghes.example.com" should be a bare hostname, e.g. ghes.example.com`}},
+		{name: "no token env", users: []UserSpec{{Name: "alice"}}, want: []string{"users[0]: token_env: needs the name of the environment variable holding the user's token"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range CheckUsers(tt.users) {
+				got = append(got, e.Error())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("CheckUsers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeUserConfig(t *testing.T) {
+	hours := &BusinessHours{}
+	shared := Config{
+		IncludeOrg:    "acme",
+		Rules:         []Rule{{Name: "shared"}},
+		KeepAuthors:   []string{"cto"},
+		MuteApproved:  true,
+		BusinessHours: hours,
+	}
+	user := Config{
+		Rules:        []Rule{{Name: "mine"}},
+		KeepAuthors:  []string{"buddy"},
+		KeepMentions: true,
+		Scoring:      &Scoring{KeepAt: 50},
+	}
+	got := MergeUserConfig(shared, user)
+
+	var rules []string
+	for _, r := range got.Rules {
+		rules = append(rules, r.Name)
+	}
+	if want := []string{"mine", "shared"}; !slices.Equal(rules, want) {
+		t.Errorf("Rules = %v, want %v", rules, want)
+	}
+	if want := []string{"cto", "buddy"}; !slices.Equal(got.KeepAuthors, want) {
+		t.Errorf("KeepAuthors = %v, want %v", got.KeepAuthors, want)
+	}
+	if got.IncludeOrg != "acme" || !got.MuteApproved || !got.KeepMentions {
+		t.Errorf("MergeUserConfig() = %+v, want the shared filters and both users' switches", got)
+	}
+	if got.BusinessHours != hours || got.Scoring == nil || got.Scoring.KeepAt != 50 {
+		t.Errorf("BusinessHours, Scoring = %v, %v, want the shared hours and the user's scoring", got.BusinessHours, got.Scoring)
+	}
+	if len(shared.Rules) != 1 || len(shared.KeepAuthors) != 1 {
+		t.Errorf("MergeUserConfig() changed the shared config: %+v", shared)
+	}
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MutationCheck is what --verify found when re-fetching a muted thread.
+type MutationCheck struct {
+	Decision Decision
+	Unread   bool
+	Ignored  bool
+	Err      error // the thread or its subscription couldn't be fetched
+}
+
+// Problem describes why the mute didn't stick, or returns "" if it did.
+func (c MutationCheck) Problem() string {
+	if c.Err != nil {
+		return c.Err.Error()
+	}
+	var problems []string
+	if c.Unread {
+		problems = append(problems, "still unread")
+	}
+	if !c.Ignored {
+		problems = append(problems, "not ignored")
+	}
+	return strings.Join(problems, ", ")
+}
+
+// FormatVerifyRow formats a thread whose mute didn't stick, in the style of an
+// apply error row.
+func FormatVerifyRow(c MutationCheck) string {
+	return fmt.Sprintf("ERROR  %s  %q  verify: %s", formatLabel(c.Decision), c.Decision.Notification.Subject.Title, c.Problem())
+}
+
+// VerifyFailures counts the checks whose mute didn't stick.
+func VerifyFailures(checks []MutationCheck) int {
+	n := 0
+	for _, c := range checks {
+		if c.Problem() != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// FormatVerifySummary renders the verification result line.
+func FormatVerifySummary(checks []MutationCheck) string {
+	if failed := VerifyFailures(checks); failed > 0 {
+		return fmt.Sprintf("Verified %d muted threads: %d didn't stick", len(checks), failed)
+	}
+	return fmt.Sprintf("Verified %d muted threads: all OK", len(checks))
+}
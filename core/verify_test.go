@@ -0,0 +1,48 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMutationCheckProblem(t *testing.T) {
+	tests := []struct {
+		check MutationCheck
+		want  string
+	}{
+		{MutationCheck{Ignored: true}, ""},
+		{MutationCheck{Unread: true, Ignored: true}, "still unread"},
+		{MutationCheck{}, "not ignored"},
+		{MutationCheck{Unread: true}, "still unread, not ignored"},
+		{MutationCheck{Err: errors.New("get thread 1: unexpected status 502")}, "get thread 1: unexpected status 502"},
+	}
+	for _, tt := range tests {
+		if got := tt.check.Problem(); got != tt.want {
+			t.Errorf("Problem(%+v) = %q, want %q", tt.check, got, tt.want)
+		}
+	}
+}
+
+func TestFormatVerify(t *testing.T) {
+	d := Decision{Notification: Notification{
+		Subject:    Subject{Title: "Fix", URL: "https://api.github.com/repos/org/repo/pulls/7"},
+		Repository: Repository{FullName: "org/repo"},
+	}}
+	checks := []MutationCheck{
+		{Decision: d, Ignored: true},
+		{Decision: d, Unread: true, Ignored: true},
+	}
+
+	if got, want := FormatVerifyRow(checks[1]), `ERROR  org/repo#7  "Fix"  verify: still unread`; got != want {
+		t.Errorf("FormatVerifyRow() = %q, want %q", got, want)
+	}
+	if got := VerifyFailures(checks); got != 1 {
+		t.Errorf("VerifyFailures() = %d, want 1", got)
+	}
+	if got, want := FormatVerifySummary(checks), "Verified 2 muted threads: 1 didn't stick"; got != want {
+		t.Errorf("FormatVerifySummary() = %q, want %q", got, want)
+	}
+	if got, want := FormatVerifySummary(checks[:1]), "Verified 1 muted threads: all OK"; got != want {
+		t.Errorf("FormatVerifySummary() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BuildInfo describes the running binary. The shell fills it from ldflags and
+// runtime/debug; the core only formats and compares.
+type BuildInfo struct {
+	Version   string // release tag like "v1.2.3", or "dev" for local builds
+	Commit    string
+	Date      string
+	Dirty     bool
+	GoVersion string
+	OS        string
+	Arch      string
+}
+
+// FormatVersion renders build info for `mutemath version`.
+func FormatVersion(b BuildInfo) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "mutemath %s\n", b.Version)
+	if b.Commit != "" {
+		commit := b.Commit
+		if b.Dirty {
+			commit += " (modified)"
+		}
+		fmt.Fprintf(&sb, "  commit: %s\n", commit)
+	}
+	if b.Date != "" {
+		fmt.Fprintf(&sb, "  built:  %s\n", b.Date)
+	}
+	fmt.Fprintf(&sb, "  go:     %s %s/%s\n", b.GoVersion, b.OS, b.Arch)
+	return sb.String()
+}
+
+// Release is a published GitHub release.
+type Release struct {
+	Tag    string
+	Assets []ReleaseAsset
+}
+
+type ReleaseAsset struct {
+	Name string
+	URL  string // browser download URL
+}
+
+// ChecksumsAssetName is the sha256sum-format file published with every release.
+const ChecksumsAssetName = "checksums.txt"
+
+// ReleaseAssetName is the binary asset name for a platform, matching the release workflow.
+func ReleaseAssetName(goos, goarch string) string {
+	name := fmt.Sprintf("mutemath_%s_%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// FindAsset returns the asset with the given name.
+func FindAsset(r Release, name string) (ReleaseAsset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return ReleaseAsset{}, false
+}
+
+// ParseChecksums parses sha256sum output ("<hex>  <name>" per line) into a name → hex map.
+func ParseChecksums(text string) map[string]string {
+	sums := make(map[string]string)
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		// sha256sum marks binary-mode entries with a leading '*'.
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return sums
+}
+
+// VerifyChecksum checks a downloaded asset's sha256 against the published checksums.
+func VerifyChecksum(sums map[string]string, name, got string) error {
+	want, ok := sums[name]
+	if !ok {
+		return fmt.Errorf("no published checksum for %s", name)
+	}
+	if !strings.EqualFold(want, got) {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", name, got, want)
+	}
+	return nil
+}
+
+// CompareVersions compares two "vMAJOR.MINOR.PATCH" versions, returning -1, 0, or 1.
+// Returns an error if either version is not in that form.
+func CompareVersions(a, b string) (int, error) {
+	pa, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	pb, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func parseVersion(v string) ([3]int, error) {
+	var parts [3]int
+	fields := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	if len(fields) != 3 {
+		return parts, fmt.Errorf("invalid version %q", v)
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return parts, fmt.Errorf("invalid version %q", v)
+		}
+		parts[i] = n
+	}
+	return parts, nil
+}
+
+// UpdateAvailable decides whether the latest release should replace the running
+// binary. Development builds have no comparable version, so they only update when forced.
+func UpdateAvailable(current, latest string, force bool) (bool, error) {
+	if force {
+		return true, nil
+	}
+	if current == "" || current == "dev" {
+		return false, fmt.Errorf("running a development build; use --force to install %s", latest)
+	}
+	cmp, err := CompareVersions(current, latest)
+	if err != nil {
+		return false, err
+	}
+	return cmp < 0, nil
+}
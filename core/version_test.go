@@ -0,0 +1,154 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatVersion(t *testing.T) {
+	output := FormatVersion(BuildInfo{
+		Version:   "v1.2.3",
+		Commit:    "abc123",
+		Date:      "2026-02-27T10:00:00Z",
+		Dirty:     true,
+		GoVersion: "go1.26.0",
+		OS:        "linux",
+		Arch:      "amd64",
+	})
+	for _, want := range []string{"mutemath v1.2.3", "abc123 (modified)", "2026-02-27T10:00:00Z", "go1.26.0 linux/amd64"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("FormatVersion missing %q\nGot: %s", want, output)
+		}
+	}
+
+	dev := FormatVersion(BuildInfo{Version: "dev", GoVersion: "go1.26.0", OS: "darwin", Arch: "arm64"})
+	if strings.Contains(dev, "commit") || strings.Contains(dev, "built") {
+		t.Errorf("dev build should omit missing fields\nGot: %s", dev)
+	}
+}
+
+func TestReleaseAssetName(t *testing.T) {
+	tests := []struct {
+		goos, goarch string
+		want         string
+	}{
+		{goos: "linux", goarch: "amd64", want: "mutemath_linux_amd64"},
+		{goos: "darwin", goarch: "arm64", want: "mutemath_darwin_arm64"},
+		{goos: "windows", goarch: "amd64", want: "mutemath_windows_amd64.exe"},
+	}
+	for _, tt := range tests {
+		if got := ReleaseAssetName(tt.goos, tt.goarch); got != tt.want {
+			t.Errorf("ReleaseAssetName(%q, %q) = %q, want %q", tt.goos, tt.goarch, got, tt.want)
+		}
+	}
+}
+
+func TestFindAsset(t *testing.T) {
+	r := Release{Tag: "v1.0.0", Assets: []ReleaseAsset{
+		{Name: "mutemath_linux_amd64", URL: "https://example.com/linux"},
+		{Name: "checksums.txt", URL: "https://example.com/sums"},
+	}}
+	a, ok := FindAsset(r, "checksums.txt")
+	if !ok || a.URL != "https://example.com/sums" {
+		t.Errorf("FindAsset(checksums.txt) = %+v, %v", a, ok)
+	}
+	if _, ok := FindAsset(r, "mutemath_darwin_arm64"); ok {
+		t.Error("FindAsset found an asset that isn't there")
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	text := "ABC123  mutemath_linux_amd64\n" +
+		"def456 *mutemath_darwin_arm64\n" +
+		"\n" +
+		"garbage line with too many fields\n"
+	sums := ParseChecksums(text)
+	if len(sums) != 2 {
+		t.Fatalf("got %d entries, want 2: %v", len(sums), sums)
+	}
+	if sums["mutemath_linux_amd64"] != "abc123" {
+		t.Errorf("linux sum = %q, want abc123", sums["mutemath_linux_amd64"])
+	}
+	if sums["mutemath_darwin_arm64"] != "def456" {
+		t.Errorf("darwin sum = %q, want def456", sums["mutemath_darwin_arm64"])
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	sums := map[string]string{"mutemath_linux_amd64": "abc123"}
+
+	if err := VerifyChecksum(sums, "mutemath_linux_amd64", "ABC123"); err != nil {
+		t.Errorf("matching checksum: %v", err)
+	}
+	if err := VerifyChecksum(sums, "mutemath_linux_amd64", "fff000"); err == nil {
+		t.Error("mismatched checksum: want error")
+	}
+	if err := VerifyChecksum(sums, "mutemath_darwin_arm64", "abc123"); err == nil {
+		t.Error("missing checksum: want error")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		want    int
+		wantErr bool
+	}{
+		{a: "v1.2.3", b: "v1.2.3", want: 0},
+		{a: "v1.2.3", b: "v1.2.4", want: -1},
+		{a: "v1.10.0", b: "v1.9.9", want: 1},
+		{a: "1.0.0", b: "v2.0.0", want: -1},
+		{a: "v1.2", b: "v1.2.0", wantErr: true},
+		{a: "v1.2.3", b: "latest", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := CompareVersions(tt.a, tt.b)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("CompareVersions(%q, %q) = %d, want error", tt.a, tt.b, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("CompareVersions(%q, %q) error = %v", tt.a, tt.b, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateAvailable(t *testing.T) {
+	tests := []struct {
+		name            string
+		current, latest string
+		force           bool
+		want            bool
+		wantErr         bool
+	}{
+		{name: "older", current: "v1.0.0", latest: "v1.1.0", want: true},
+		{name: "same", current: "v1.1.0", latest: "v1.1.0", want: false},
+		{name: "newer than latest", current: "v1.2.0", latest: "v1.1.0", want: false},
+		{name: "dev build", current: "dev", latest: "v1.1.0", wantErr: true},
+		{name: "dev build forced", current: "dev", latest: "v1.1.0", force: true, want: true},
+		{name: "same version forced", current: "v1.1.0", latest: "v1.1.0", force: true, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UpdateAvailable(tt.current, tt.latest, tt.force)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("UpdateAvailable() = %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateAvailable() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("UpdateAvailable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
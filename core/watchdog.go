@@ -0,0 +1,59 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// Watchdog tracks the daemon's cycle cadence, to catch a cycle that overruns
+// (a wedged HTTP call, a retry loop that keeps backing off) or one that
+// doesn't start when it should. The shell calls Start and Finish from the
+// daemon loop and Check on a ticker of its own, so a stuck loop is still caught.
+type Watchdog struct {
+	Threshold time.Duration // how far a cycle may overrun or start late; zero disables
+
+	running bool
+	started time.Time
+	due     time.Time // when the next cycle should start; zero before the first finishes
+	alerted bool      // an alert went out for the current cycle or wait
+}
+
+// Start notes a cycle starting and reports a late start beyond the threshold,
+// unless Check already did.
+func (w *Watchdog) Start(now time.Time) (string, bool) {
+	msg, late := w.Check(now)
+	w.running, w.started, w.alerted = true, now, false
+	return msg, late
+}
+
+// Finish notes a cycle finishing and the wait until the next.
+func (w *Watchdog) Finish(now time.Time, wait time.Duration) {
+	w.running, w.due, w.alerted = false, now.Add(wait), false
+}
+
+// Check reports a cycle running, or overdue to start, beyond the threshold.
+// It reports once per cycle or wait.
+func (w *Watchdog) Check(now time.Time) (string, bool) {
+	if w.Threshold <= 0 || w.alerted {
+		return "", false
+	}
+	switch {
+	case w.running && now.Sub(w.started) > w.Threshold:
+		w.alerted = true
+		return fmt.Sprintf("cycle has been running for %s, over the %s threshold", now.Sub(w.started).Round(time.Second), w.Threshold), true
+	case !w.running && !w.due.IsZero() && now.Sub(w.due) > w.Threshold:
+		w.alerted = true
+		return fmt.Sprintf("cycle is %s overdue, over the %s threshold", now.Sub(w.due).Round(time.Second), w.Threshold), true
+	}
+	return "", false
+}
+
+// WatchdogAlert is the alert sent to the notifier sinks for a watchdog
+// message. host is empty unless processing several hosts.
+func WatchdogAlert(host, msg string) Alert {
+	title := "mutemath daemon watchdog"
+	if host != "" {
+		title += " (" + host + ")"
+	}
+	return Alert{Title: title, Body: msg}
+}
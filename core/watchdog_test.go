@@ -0,0 +1,103 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchdog(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	type step struct {
+		op   string // "start", "finish", or "check"
+		at   time.Duration
+		wait time.Duration // for "finish"
+		want string
+	}
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		steps     []step
+	}{
+		{
+			name:      "on time",
+			threshold: 5 * time.Minute,
+			steps: []step{
+				{op: "start", at: 0},
+				{op: "check", at: 4 * time.Minute},
+				{op: "finish", at: 4 * time.Minute, wait: time.Minute},
+				{op: "check", at: 9 * time.Minute},
+				{op: "start", at: 10 * time.Minute},
+			},
+		},
+		{
+			name:      "overrun alerts once",
+			threshold: 5 * time.Minute,
+			steps: []step{
+				{op: "start", at: 0},
+				{op: "check", at: 6 * time.Minute, want: "cycle has been running for 6m0s, over the 5m0s threshold"},
+				{op: "check", at: 7 * time.Minute},
+				{op: "finish", at: 8 * time.Minute, wait: time.Minute},
+				{op: "start", at: 9 * time.Minute},
+				{op: "check", at: 15 * time.Minute, want: "cycle has been running for 6m0s, over the 5m0s threshold"},
+			},
+		},
+		{
+			name:      "overdue start caught by check",
+			threshold: 5 * time.Minute,
+			steps: []step{
+				{op: "start", at: 0},
+				{op: "finish", at: time.Minute, wait: time.Minute},
+				{op: "check", at: 8 * time.Minute, want: "cycle is 6m0s overdue, over the 5m0s threshold"},
+				{op: "start", at: 9 * time.Minute},
+			},
+		},
+		{
+			name:      "late start caught by start",
+			threshold: 5 * time.Minute,
+			steps: []step{
+				{op: "start", at: 0},
+				{op: "finish", at: time.Minute, wait: time.Minute},
+				{op: "start", at: 10 * time.Minute, want: "cycle is 8m0s overdue, over the 5m0s threshold"},
+			},
+		},
+		{
+			name: "disabled",
+			steps: []step{
+				{op: "start", at: 0},
+				{op: "check", at: time.Hour},
+				{op: "finish", at: time.Hour, wait: time.Minute},
+				{op: "start", at: 3 * time.Hour},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := Watchdog{Threshold: tt.threshold}
+			for i, s := range tt.steps {
+				now := t0.Add(s.at)
+				var msg string
+				var ok bool
+				switch s.op {
+				case "start":
+					msg, ok = w.Start(now)
+				case "finish":
+					w.Finish(now, s.wait)
+				case "check":
+					msg, ok = w.Check(now)
+				}
+				if msg != s.want || ok != (s.want != "") {
+					t.Errorf("step %d (%s at %s) = %q, %v; want %q", i, s.op, s.at, msg, ok, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchdogAlert(t *testing.T) {
+	if got := WatchdogAlert("", "stuck").Title; got != "mutemath daemon watchdog" {
+		t.Errorf("title = %q", got)
+	}
+	if got := WatchdogAlert("ghe.example.com", "stuck").Title; got != "mutemath daemon watchdog (ghe.example.com)" {
+		t.Errorf("title with host = %q", got)
+	}
+}
@@ -0,0 +1,207 @@
+package core
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RepoSubscription is a repository you watch, for mutemath subscriptions.
+type RepoSubscription struct {
+	Repo          string // "org/repo"
+	Subscribed    bool   // watching all activity
+	Ignored       bool
+	Notifications int // journal records about it over the audit's days
+}
+
+// Level names how you watch the repo: "watching" for all activity,
+// "ignoring", or "custom" for a watch on only some events, like releases.
+func (s RepoSubscription) Level() string {
+	switch {
+	case s.Ignored:
+		return "ignoring"
+	case s.Subscribed:
+		return "watching"
+	default:
+		return "custom"
+	}
+}
+
+// CountRepoNotifications fills in each repo's notifications from the
+// journal's mutes and kept review requests on host since since, and sorts the
+// noisiest first. Undos aren't counted.
+func CountRepoNotifications(subs []RepoSubscription, records []MutationRecord, host string, since time.Time) []RepoSubscription {
+	counts := make(map[string]int)
+	for _, r := range records {
+		if r.Undo || r.Time.Before(since) || !strings.EqualFold(r.Host, host) {
+			continue
+		}
+		counts[strings.ToLower(recordRepo(r))]++
+	}
+	out := make([]RepoSubscription, len(subs))
+	for i, s := range subs {
+		s.Notifications = counts[strings.ToLower(s.Repo)]
+		out[i] = s
+	}
+	slices.SortStableFunc(out, func(a, b RepoSubscription) int {
+		if c := cmp.Compare(b.Notifications, a.Notifications); c != 0 {
+			return c
+		}
+		return cmp.Compare(strings.ToLower(a.Repo), strings.ToLower(b.Repo))
+	})
+	return out
+}
+
+// FormatSubscriptionAudit renders the repos you watch as a table, with their
+// notifications over the last days.
+func FormatSubscriptionAudit(subs []RepoSubscription, days int) string {
+	if len(subs) == 0 {
+		return "You aren't watching any repositories.\n"
+	}
+	width := len("REPO")
+	for _, s := range subs {
+		width = max(width, len(s.Repo))
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-*s  %-8s  %s\n", width, "REPO", "WATCH", fmt.Sprintf("NOTIFICATIONS (%dd)", days))
+	for _, s := range subs {
+		fmt.Fprintf(&sb, "%-*s  %-8s  %d\n", width, s.Repo, s.Level(), s.Notifications)
+	}
+	return sb.String()
+}
+
+// subscriptionPlanHeader explains the mutemath subscriptions --edit file.
+const subscriptionPlanHeader = `# mutemath subscriptions: change the first word of a line to participating
+# (or p) to stop watching that repo, so you're only notified about threads you
+# participate in or are @mentioned in.
+#
+# Other lines are left as they are. Lines starting with # are ignored.
+# Save and quit to apply.
+`
+
+// FormatSubscriptionPlan renders the repos you watch as an editable plan: one
+// line per repo with its watch level, its name, and its notifications over
+// the last days after a #.
+func FormatSubscriptionPlan(subs []RepoSubscription, days int) string {
+	var sb strings.Builder
+	sb.WriteString(subscriptionPlanHeader)
+	sb.WriteString("\n")
+	for _, s := range subs {
+		fmt.Fprintf(&sb, "%-8s %s  # %d notifications in %d days\n", s.Level(), s.Repo, s.Notifications, days)
+	}
+	return sb.String()
+}
+
+// ParseSubscriptionPlan reads an edited subscriptions plan and returns the
+// repos switched to participating, in the plan's order. Every problem is
+// reported with its line.
+func ParseSubscriptionPlan(text string, subs []RepoSubscription) ([]string, error) {
+	byRepo := make(map[string]RepoSubscription, len(subs))
+	for _, s := range subs {
+		byRepo[strings.ToLower(s.Repo)] = s
+	}
+
+	var out []string
+	var errs []error
+	seen := make(map[string]int)
+	for i, line := range strings.Split(text, "\n") {
+		lineNo := i + 1
+		if before, _, ok := strings.Cut(line, "#"); ok {
+			line = before
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			errs = append(errs, fmt.Errorf("line %d: want \"<watch> <org/repo>\"", lineNo))
+			continue
+		}
+		s, ok := byRepo[strings.ToLower(fields[1])]
+		if !ok {
+			errs = append(errs, fmt.Errorf("line %d: unknown repo %s", lineNo, fields[1]))
+			continue
+		}
+		if prev, dup := seen[strings.ToLower(s.Repo)]; dup {
+			errs = append(errs, fmt.Errorf("line %d: %s is already on line %d", lineNo, s.Repo, prev))
+			continue
+		}
+		seen[strings.ToLower(s.Repo)] = lineNo
+		switch strings.ToLower(fields[0]) {
+		case "p", "participating":
+			out = append(out, s.Repo)
+		case s.Level():
+		default:
+			errs = append(errs, fmt.Errorf("line %d: %q can only be changed to participating", lineNo, fields[0]))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return out, nil
+}
+
+// MatchRepos returns the repos ("org/repo") matching any of the glob
+// patterns, ignoring case, in order.
+func MatchRepos(repos, patterns []string) []string {
+	var out []string
+	for _, r := range repos {
+		if slices.ContainsFunc(patterns, func(p string) bool { return MatchGlob(strings.ToLower(p), strings.ToLower(r)) }) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// UnwatchSkip says why mutemath unwatch leaves a repo as it is, or returns
+// "" to change it: to ignoring with ignore, else to participating only.
+// Unwatching an ignored repo would notify you more, not less.
+func UnwatchSkip(s RepoSubscription, ignore bool) string {
+	switch {
+	case s.Ignored && ignore:
+		return "already ignoring"
+	case s.Ignored:
+		return "ignoring, which is quieter than participating"
+	}
+	return ""
+}
+
+// FormatUnwatchRow formats the outcome of switching a repo to participating,
+// or with ignore to ignoring; skip says why it was left alone.
+func FormatUnwatchRow(repo string, ignore, apply bool, skip string, err error) string {
+	verb := "UNWATCH"
+	if ignore {
+		verb = "IGNORE"
+	}
+	switch {
+	case err != nil:
+		return fmt.Sprintf("ERROR  %s  %s", repo, err)
+	case skip != "":
+		return fmt.Sprintf("SKIP   %s  (%s)", repo, skip)
+	case !apply:
+		return fmt.Sprintf("WOULD %s  %s", verb, repo)
+	default:
+		return fmt.Sprintf("%s  %s", verb, repo)
+	}
+}
+
+// FormatUnwatchSummary renders the final line of unwatching output.
+func FormatUnwatchSummary(changed, errors int, ignore, apply bool) string {
+	verb := "Unwatched"
+	if ignore {
+		verb = "Ignored"
+	}
+	switch {
+	case !apply && ignore:
+		return fmt.Sprintf("\nWould ignore %d repos", changed)
+	case !apply:
+		return fmt.Sprintf("\nWould unwatch %d repos", changed)
+	case errors > 0:
+		return fmt.Sprintf("\n%s %d repos, %d errors", verb, changed, errors)
+	default:
+		return fmt.Sprintf("\n%s %d repos", verb, changed)
+	}
+}
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCountRepoNotifications(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	subs := []RepoSubscription{
+		{Repo: "acme/api", Subscribed: true},
+		{Repo: "acme/web", Subscribed: true},
+		{Repo: "Oss/lib", Ignored: true},
+		{Repo: "acme/archive"},
+	}
+	records := []MutationRecord{
+		{Time: now.Add(-time.Hour), Label: "acme/web#1"},
+		{Time: now.Add(-time.Hour), Label: "acme/web#2", Kept: true},
+		{Time: now.Add(-time.Hour), Label: "oss/lib#3"},
+		{Time: now.Add(-time.Hour), Label: "acme/web#1", Undo: true},                                // undos aren't counted
+		{Time: now.Add(-40 * 24 * time.Hour), Label: "acme/api#4"},                                  // too old
+		{Time: now.Add(-time.Hour), Label: "ghes.example.com/acme/api#5", Host: "ghes.example.com"}, // other host
+	}
+	got := CountRepoNotifications(subs, records, "", now.Add(-30*24*time.Hour))
+	want := []RepoSubscription{
+		{Repo: "acme/web", Subscribed: true, Notifications: 2},
+		{Repo: "Oss/lib", Ignored: true, Notifications: 1},
+		{Repo: "acme/api", Subscribed: true},
+		{Repo: "acme/archive"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CountRepoNotifications() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	got = CountRepoNotifications(subs, records, "ghes.example.com", now.Add(-30*24*time.Hour))
+	if got[0].Repo != "acme/api" || got[0].Notifications != 1 {
+		t.Errorf("on ghes.example.com, first = %+v, want acme/api with 1", got[0])
+	}
+}
+
+func TestFormatSubscriptionAudit(t *testing.T) {
+	subs := []RepoSubscription{
+		{Repo: "acme/web", Subscribed: true, Notifications: 42},
+		{Repo: "acme/archive", Notifications: 3},
+		{Repo: "oss/lib", Ignored: true},
+	}
+	got := FormatSubscriptionAudit(subs, 30)
+	want := "REPO          WATCH     NOTIFICATIONS (30d)\n" +
+		"acme/web      watching  42\n" +
+		"acme/archive  custom    3\n" +
+		"oss/lib       ignoring  0\n"
+	if got != want {
+		t.Errorf("FormatSubscriptionAudit() =\n%s\nwant\n%s", got, want)
+	}
+	if got := FormatSubscriptionAudit(nil, 30); got != "You aren't watching any repositories.\n" {
+		t.Errorf("FormatSubscriptionAudit(nil) = %q", got)
+	}
+}
+
+func TestParseSubscriptionPlan(t *testing.T) {
+	subs := []RepoSubscription{
+		{Repo: "acme/web", Subscribed: true, Notifications: 42},
+		{Repo: "acme/archive", Notifications: 3},
+		{Repo: "oss/lib", Ignored: true},
+	}
+	plan := FormatSubscriptionPlan(subs, 30)
+	if !strings.Contains(plan, "watching acme/web  # 42 notifications in 30 days\n") {
+		t.Fatalf("FormatSubscriptionPlan() =\n%s", plan)
+	}
+
+	tests := []struct {
+		name    string
+		text    string
+		want    []string
+		wantErr string
+	}{
+		{name: "unchanged", text: plan},
+		{name: "switched", text: strings.Replace(strings.Replace(plan, "watching acme/web", "participating acme/web", 1), "ignoring oss/lib", "p oss/lib", 1),
+			want: []string{"acme/web", "oss/lib"}},
+		{name: "lines deleted", text: "P Acme/Archive\n", want: []string{"acme/archive"}},
+		{name: "unknown repo", text: "p acme/nope\n", wantErr: "line 1: unknown repo acme/nope"},
+		{name: "duplicate", text: "p acme/web\nwatching acme/web\n", wantErr: "line 2: acme/web is already on line 1"},
+		{name: "other level", text: "ignoring acme/web\n", wantErr: `line 1: "ignoring" can only be changed to participating`},
+		{name: "malformed", text: "acme/web\n", wantErr: "line 1: want"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSubscriptionPlan(tt.text, subs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchRepos(t *testing.T) {
+	repos := []string{"acme/archive-2019", "Acme/Archive-2020", "acme/api", "oss/archive-old"}
+	got := MatchRepos(repos, []string{"acme/archive-*", "oss/*-old"})
+	want := []string{"acme/archive-2019", "Acme/Archive-2020", "oss/archive-old"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchRepos() = %v, want %v", got, want)
+	}
+}
+
+func TestUnwatchRows(t *testing.T) {
+	tests := []struct {
+		sub           RepoSubscription
+		ignore, apply bool
+		want          string
+	}{
+		{RepoSubscription{Repo: "acme/a", Subscribed: true}, false, false, "WOULD UNWATCH  acme/a"},
+		{RepoSubscription{Repo: "acme/a"}, false, true, "UNWATCH  acme/a"},
+		{RepoSubscription{Repo: "acme/a", Subscribed: true}, true, true, "IGNORE  acme/a"},
+		{RepoSubscription{Repo: "acme/a", Ignored: true}, false, true, "SKIP   acme/a  (ignoring, which is quieter than participating)"},
+		{RepoSubscription{Repo: "acme/a", Ignored: true}, true, false, "SKIP   acme/a  (already ignoring)"},
+	}
+	for _, tt := range tests {
+		if got := FormatUnwatchRow(tt.sub.Repo, tt.ignore, tt.apply, UnwatchSkip(tt.sub, tt.ignore), nil); got != tt.want {
+			t.Errorf("FormatUnwatchRow(%+v, ignore %v, apply %v) = %q, want %q", tt.sub, tt.ignore, tt.apply, got, tt.want)
+		}
+	}
+	if got := FormatUnwatchSummary(2, 1, true, true); got != "\nIgnored 2 repos, 1 errors" {
+		t.Errorf("FormatUnwatchSummary() = %q", got)
+	}
+	if got := FormatUnwatchSummary(3, 0, false, false); got != "\nWould unwatch 3 repos" {
+		t.Errorf("FormatUnwatchSummary() = %q", got)
+	}
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Wave is a group of near-identical PRs decided alike, like one dependency
+// bumped by a bot across many repos, to show and edit as one.
+type Wave struct {
+	ID        string // "wave1", "wave2", ... in the --edit plan
+	Author    string
+	Pattern   string // the shared title, see TitlePattern
+	Decisions []Decision
+}
+
+// TitlePattern is a title with every word containing a digit replaced by
+// "*", so "Bump golang.org/x/net from 0.20.0 to 0.23.0" and the same bump
+// from another version read alike.
+func TitlePattern(title string) string {
+	words := strings.Fields(title)
+	for i, w := range words {
+		if strings.ContainsAny(w, "0123456789") {
+			words[i] = "*"
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// FindWaves groups PR decisions with the same author, title pattern, and
+// action into waves, keeping groups of at least min. Decisions without a
+// known author aren't grouped. Waves are in the order of their first PR.
+func FindWaves(decisions []Decision, min int) []Wave {
+	type key struct {
+		author, pattern string
+		action          Action
+	}
+	var order []key
+	groups := make(map[key][]Decision)
+	for _, d := range decisions {
+		if d.Author == "" || d.Notification.Subject.Type != "PullRequest" {
+			continue
+		}
+		k := key{strings.ToLower(d.Author), TitlePattern(d.Notification.Subject.Title), d.Action}
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], d)
+	}
+	var waves []Wave
+	for _, k := range order {
+		if g := groups[k]; len(g) >= min {
+			waves = append(waves, Wave{ID: fmt.Sprintf("wave%d", len(waves)+1), Author: g[0].Author, Pattern: k.pattern, Decisions: g})
+		}
+	}
+	return waves
+}
+
+// WaveIndex maps each thread in waves to its wave's index.
+func WaveIndex(waves []Wave) map[string]int {
+	index := make(map[string]int)
+	for i, w := range waves {
+		for _, d := range w.Decisions {
+			index[d.Notification.ID] = i
+		}
+	}
+	return index
+}
+
+// Action is the action the wave's PRs were decided.
+func (w Wave) Action() Action {
+	return w.Decisions[0].Action
+}
+
+// Reason is the wave's PRs' shared reason, or "various reasons".
+func (w Wave) Reason() string {
+	reason := w.Decisions[0].Reason
+	for _, d := range w.Decisions[1:] {
+		if d.Reason != reason {
+			return "various reasons"
+		}
+	}
+	return reason
+}
+
+// Summary describes the wave's size, e.g. "37 PRs in 12 repos".
+func (w Wave) Summary() string {
+	repos := make(map[string]bool)
+	for _, d := range w.Decisions {
+		repos[strings.ToLower(d.Notification.Repository.FullName)] = true
+	}
+	if len(repos) == 1 {
+		return fmt.Sprintf("%d PRs in 1 repo", len(w.Decisions))
+	}
+	return fmt.Sprintf("%d PRs in %d repos", len(w.Decisions), len(repos))
+}
+
+// FormatWaveRow formats a wave as one row in place of its PRs' rows, laid
+// out like FormatDecisionRow.
+func FormatWaveRow(w Wave) string {
+	return fmt.Sprintf("%-40s  %-90s  %s (%s)", w.Summary(), w.Author+": "+w.Pattern, w.Action(), w.Reason())
+}
+
+// FormatWavePlain formats a wave for --plain output, like FormatDecisionPlain.
+func FormatWavePlain(w Wave) string {
+	return fmt.Sprintf("wave: %s\nauthor: %s\ntitle: %s\naction: %s\nreason: %s\n\n", w.Summary(), w.Author, w.Pattern, w.Action(), w.Reason())
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTitlePattern(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Bump golang.org/x/net from 0.20.0 to 0.23.0", "Bump golang.org/x/net from * to *"},
+		{"Update dependency react to v19.1.0", "Update dependency react to *"},
+		{"Fix login  redirect loop", "Fix login redirect loop"},
+	}
+	for _, tt := range tests {
+		if got := TitlePattern(tt.in); got != tt.want {
+			t.Errorf("TitlePattern(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func waveDecisions() []Decision {
+	pr := func(id, repo, title, author string, action Action, reason string) Decision {
+		return Decision{
+			Notification: Notification{ID: id, Subject: Subject{Type: "PullRequest", Title: title}, Repository: Repository{FullName: repo}},
+			Action:       action, Reason: reason, Author: author,
+		}
+	}
+	return []Decision{
+		pr("1", "org/api", "Bump golang.org/x/net from 0.20.0 to 0.23.0", "dependabot[bot]", ActionMute, "team-only review request"),
+		pr("2", "org/api", "Add rate limiting", "alice", ActionKeep, "direct review request"),
+		pr("3", "org/web", "Bump golang.org/x/net from 0.21.0 to 0.23.0", "Dependabot[bot]", ActionMute, "rule bots"),
+		pr("4", "org/cli", "Bump golang.org/x/net from 0.19.0 to 0.23.0", "dependabot[bot]", ActionKeep, "direct review request"),
+		pr("5", "org/infra", "Bump golang.org/x/net from 0.20.0 to 0.23.0", "dependabot[bot]", ActionMute, "team-only review request"),
+		pr("6", "org/docs", "Bump golang.org/x/net from 0.20.0 to 0.23.0", "", ActionMute, "team-only review request"),
+	}
+}
+
+func TestFindWaves(t *testing.T) {
+	waves := FindWaves(waveDecisions(), 3)
+	if len(waves) != 1 {
+		t.Fatalf("FindWaves() = %d waves, want 1", len(waves))
+	}
+	w := waves[0]
+	var ids []string
+	for _, d := range w.Decisions {
+		ids = append(ids, d.Notification.ID)
+	}
+	// The kept bump differs in action, and 6 has no known author.
+	if !slices.Equal(ids, []string{"1", "3", "5"}) || w.ID != "wave1" || w.Pattern != "Bump golang.org/x/net from * to *" {
+		t.Errorf("FindWaves() = %s %q %v, want wave1 with 1, 3, 5", w.ID, w.Pattern, ids)
+	}
+	if got := w.Summary(); got != "3 PRs in 3 repos" {
+		t.Errorf("Summary() = %q", got)
+	}
+	if got := w.Reason(); got != "various reasons" {
+		t.Errorf("Reason() = %q, want various reasons", got)
+	}
+	if got := FormatWaveRow(w); !strings.HasPrefix(got, "3 PRs in 3 repos  ") || !strings.HasSuffix(got, "  MUTE (various reasons)") {
+		t.Errorf("FormatWaveRow() = %q", got)
+	}
+	if got := FindWaves(waveDecisions(), 4); got != nil {
+		t.Errorf("FindWaves(min 4) = %v, want none", got)
+	}
+	if got := WaveIndex(waves); len(got) != 3 || got["3"] != 0 {
+		t.Errorf("WaveIndex() = %v", got)
+	}
+}
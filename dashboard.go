@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// dashboardState is what the daemon's dashboard shows, recorded after each
+// cycle. A nil *dashboardState records nothing.
+type dashboardState struct {
+	users []*GitHubClient // a multi-user daemon's, whose journals make up the mute history
+
+	mu sync.Mutex
+	d  core.Dashboard
+}
+
+// dashboard is set by --dashboard.
+var dashboard *dashboardState
+
+// record adds a finished cycle and its decisions.
+func (s *dashboardState) record(c core.CycleRecord, decisions []core.Decision, err error) {
+	if s == nil {
+		return
+	}
+	if err != nil {
+		c.Err = err.Error()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.d.Record(c, decisions)
+}
+
+var dashboardPage = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="60">
+<title>mutemath</title>
+<style>
+body { font: 14px system-ui, sans-serif; margin: 2em; color: #1f2328; }
+h1 { font-size: 1.4em; }
+h2 { font-size: 1.1em; margin-top: 2em; }
+table { border-collapse: collapse; }
+th, td { text-align: left; padding: 0.25em 1em 0.25em 0; border-bottom: 1px solid #d0d7de; vertical-align: top; }
+.empty, footer { color: #656d76; }
+</style>
+</head>
+<body>
+<h1>mutemath</h1>
+{{range .Tables}}
+<h2>{{.Title}}</h2>
+{{if .Rows}}<table>
+<tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>{{else}}<p class="empty">{{.Empty}}</p>{{end}}
+{{end}}
+<footer><p>{{.Version}} · updated {{.Now}} · refreshes every minute</p></footer>
+</body>
+</html>
+`))
+
+// journal reads the mutation journal for the mute history, or with several
+// users, each user's, their labels naming the user. The demo's mutes aren't
+// journaled, so it has none.
+func (s *dashboardState) journal() ([]core.MutationRecord, error) {
+	if journalOff {
+		return nil, nil
+	}
+	if len(s.users) == 0 {
+		return readJournal()
+	}
+	var all []core.MutationRecord
+	for _, c := range s.users {
+		st, err := c.store()
+		if err != nil {
+			return nil, err
+		}
+		records, err := readJournalFrom(st)
+		if err != nil {
+			return nil, fmt.Errorf("user %s: %w", c.user, err)
+		}
+		for _, r := range records {
+			r.Label = c.user + ": " + r.Label
+			all = append(all, r)
+		}
+	}
+	slices.SortStableFunc(all, func(a, b core.MutationRecord) int { return a.Time.Compare(b.Time) })
+	return all, nil
+}
+
+// serveDashboard renders the dashboard.
+func (s *dashboardState) serveDashboard(w http.ResponseWriter, r *http.Request) {
+	records, err := s.journal()
+	if err != nil {
+		log.Printf("warning: dashboard: %s", err)
+	}
+	now := time.Now()
+	s.mu.Lock()
+	tables := core.DashboardTables(s.d, records, now)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err = dashboardPage.Execute(w, struct {
+		Tables  []core.Table
+		Version string
+		Now     string
+	}{tables, progName + " " + version, now.Format("15:04:05 MST")})
+	if err != nil {
+		log.Printf("warning: dashboard: %s", err)
+	}
+}
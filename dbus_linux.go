@@ -0,0 +1,416 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A minimal session-bus D-Bus client: just enough of the wire protocol to call
+// methods with simple arguments and receive signals. See
+// https://dbus.freedesktop.org/doc/dbus-specification.html.
+
+const (
+	dbusMethodCall   = 1
+	dbusMethodReturn = 2
+	dbusError        = 3
+	dbusSignal       = 4
+
+	dbusCallTimeout = 10 * time.Second
+	dbusMaxMessage  = 1 << 27 // spec limit is 128 MiB
+)
+
+type dbusMessage struct {
+	typ         byte
+	serial      uint32
+	replySerial uint32
+	path        string
+	iface       string
+	member      string
+	errName     string
+	sender      string
+	signature   string
+	body        []byte
+	order       binary.ByteOrder
+}
+
+type dbusConn struct {
+	conn     net.Conn
+	r        *bufio.Reader
+	onSignal func(*dbusMessage)
+
+	mu      sync.Mutex // guards everything below, and writes to conn
+	serial  uint32
+	pending map[uint32]chan *dbusMessage
+	err     error // set once the read loop exits
+}
+
+// dialSessionBus connects and authenticates to the session bus. onSignal is
+// called from the read goroutine for every signal the bus routes to us.
+func dialSessionBus(onSignal func(*dbusMessage)) (*dbusConn, error) {
+	addr := os.Getenv("DBUS_SESSION_BUS_ADDRESS")
+	if addr == "" {
+		addr = fmt.Sprintf("unix:path=/run/user/%d/bus", os.Getuid())
+	}
+	socket, err := parseBusAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout("unix", socket, dbusCallTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("connect to session bus: %w", err)
+	}
+	c := &dbusConn{
+		conn:     conn,
+		r:        bufio.NewReader(conn),
+		onSignal: onSignal,
+		pending:  make(map[uint32]chan *dbusMessage),
+	}
+	if err := c.auth(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("session bus auth: %w", err)
+	}
+	go c.readLoop()
+
+	if _, err := c.call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "Hello", "", nil); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("session bus hello: %w", err)
+	}
+	return c, nil
+}
+
+// parseBusAddress returns a dialable socket path from the first unix: entry
+// of a D-Bus address list. Abstract sockets are prefixed with "@".
+func parseBusAddress(addr string) (string, error) {
+	for _, entry := range strings.Split(addr, ";") {
+		transport, params, ok := strings.Cut(entry, ":")
+		if !ok || transport != "unix" {
+			continue
+		}
+		for _, kv := range strings.Split(params, ",") {
+			k, v, _ := strings.Cut(kv, "=")
+			switch k {
+			case "path":
+				return v, nil
+			case "abstract":
+				return "@" + v, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no usable unix socket in bus address %q", addr)
+}
+
+// auth performs SASL EXTERNAL authentication with our uid.
+func (c *dbusConn) auth() error {
+	c.conn.SetDeadline(time.Now().Add(dbusCallTimeout))
+	defer c.conn.SetDeadline(time.Time{})
+
+	uid := hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+	if _, err := fmt.Fprintf(c.conn, "\x00AUTH EXTERNAL %s\r\n", uid); err != nil {
+		return err
+	}
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "OK ") {
+		return fmt.Errorf("rejected: %s", strings.TrimSpace(line))
+	}
+	_, err = io.WriteString(c.conn, "BEGIN\r\n")
+	return err
+}
+
+func (c *dbusConn) Close() error {
+	return c.conn.Close()
+}
+
+// closed returns the error that ended the connection, or nil if it's still up.
+func (c *dbusConn) closed() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// call sends a method call and waits for its reply. body must already be
+// marshaled according to sig.
+func (c *dbusConn) call(dest, path, iface, member, sig string, body []byte) (*dbusMessage, error) {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return nil, c.err
+	}
+	c.serial++
+	serial := c.serial
+	reply := make(chan *dbusMessage, 1)
+	c.pending[serial] = reply
+	_, err := c.conn.Write(marshalMethodCall(serial, dest, path, iface, member, sig, body))
+	c.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case m, ok := <-reply:
+		if !ok {
+			return nil, c.closed()
+		}
+		if m.typ == dbusError {
+			text := newDBusDecoder(m.body, m.order).string()
+			return nil, fmt.Errorf("%s.%s: %s: %s", iface, member, m.errName, text)
+		}
+		return m, nil
+	case <-time.After(dbusCallTimeout):
+		c.mu.Lock()
+		delete(c.pending, serial)
+		c.mu.Unlock()
+		return nil, fmt.Errorf("%s.%s: timed out", iface, member)
+	}
+}
+
+func (c *dbusConn) readLoop() {
+	for {
+		m, err := readDBusMessage(c.r)
+		if err != nil {
+			c.mu.Lock()
+			c.err = fmt.Errorf("session bus connection lost: %w", err)
+			for serial, ch := range c.pending {
+				close(ch)
+				delete(c.pending, serial)
+			}
+			c.mu.Unlock()
+			c.conn.Close()
+			return
+		}
+
+		switch m.typ {
+		case dbusMethodReturn, dbusError:
+			c.mu.Lock()
+			ch := c.pending[m.replySerial]
+			delete(c.pending, m.replySerial)
+			c.mu.Unlock()
+			if ch != nil {
+				ch <- m
+			}
+		case dbusSignal:
+			if c.onSignal != nil {
+				c.onSignal(m)
+			}
+		}
+	}
+}
+
+func marshalMethodCall(serial uint32, dest, path, iface, member, sig string, body []byte) []byte {
+	e := &dbusEncoder{}
+	e.byte('l')
+	e.byte(dbusMethodCall)
+	e.byte(0) // flags
+	e.byte(1) // protocol version
+	e.uint32(uint32(len(body)))
+	e.uint32(serial)
+	e.array(8, func() {
+		field := func(code byte, sig string, value func()) {
+			e.align(8)
+			e.byte(code)
+			e.signature(sig)
+			value()
+		}
+		field(1, "o", func() { e.string(path) })
+		field(2, "s", func() { e.string(iface) })
+		field(3, "s", func() { e.string(member) })
+		field(6, "s", func() { e.string(dest) })
+		if sig != "" {
+			field(8, "g", func() { e.signature(sig) })
+		}
+	})
+	e.align(8)
+	return append(e.buf, body...)
+}
+
+func readDBusMessage(r io.Reader) (*dbusMessage, error) {
+	fixed := make([]byte, 16)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return nil, err
+	}
+	var order binary.ByteOrder
+	switch fixed[0] {
+	case 'l':
+		order = binary.LittleEndian
+	case 'B':
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("bad endianness marker %q", fixed[0])
+	}
+	bodyLen := order.Uint32(fixed[4:])
+	fieldsLen := order.Uint32(fixed[12:])
+	if bodyLen > dbusMaxMessage || fieldsLen > dbusMaxMessage {
+		return nil, errors.New("message too large")
+	}
+	headerEnd := 16 + int(fieldsLen)
+	bodyStart := (headerEnd + 7) &^ 7
+
+	msg := make([]byte, bodyStart+int(bodyLen))
+	copy(msg, fixed)
+	if _, err := io.ReadFull(r, msg[16:]); err != nil {
+		return nil, err
+	}
+
+	m := &dbusMessage{typ: fixed[1], serial: order.Uint32(fixed[8:]), order: order}
+	d := newDBusDecoder(msg[:headerEnd], order)
+	d.pos = 16
+	for d.pos < headerEnd && d.err == nil {
+		d.align(8)
+		code := d.byte()
+		sig := d.signature()
+		switch sig {
+		case "s", "o":
+			v := d.string()
+			switch code {
+			case 1:
+				m.path = v
+			case 2:
+				m.iface = v
+			case 3:
+				m.member = v
+			case 4:
+				m.errName = v
+			case 7:
+				m.sender = v
+			}
+		case "u":
+			v := d.uint32()
+			if code == 5 {
+				m.replySerial = v
+			}
+		case "g":
+			v := d.signature()
+			if code == 8 {
+				m.signature = v
+			}
+		default:
+			return nil, fmt.Errorf("unsupported header field signature %q", sig)
+		}
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("malformed header: %w", d.err)
+	}
+	m.body = msg[bodyStart:]
+	return m, nil
+}
+
+// dbusEncoder marshals little-endian D-Bus values. Alignment is relative to the
+// start of buf, which is correct for both headers and bodies since bodies start
+// on an 8-byte boundary.
+type dbusEncoder struct {
+	buf []byte
+}
+
+func (e *dbusEncoder) align(n int) {
+	for len(e.buf)%n != 0 {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *dbusEncoder) byte(b byte) {
+	e.buf = append(e.buf, b)
+}
+
+func (e *dbusEncoder) uint32(v uint32) {
+	e.align(4)
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, v)
+}
+
+func (e *dbusEncoder) int32(v int32) {
+	e.uint32(uint32(v))
+}
+
+func (e *dbusEncoder) string(s string) {
+	e.uint32(uint32(len(s)))
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, 0)
+}
+
+func (e *dbusEncoder) signature(s string) {
+	e.buf = append(e.buf, byte(len(s)))
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, 0)
+}
+
+// array writes an array whose elements have the given alignment; elems writes them.
+func (e *dbusEncoder) array(elemAlign int, elems func()) {
+	e.align(4)
+	lenPos := len(e.buf)
+	e.buf = append(e.buf, 0, 0, 0, 0)
+	e.align(elemAlign)
+	start := len(e.buf)
+	elems()
+	binary.LittleEndian.PutUint32(e.buf[lenPos:], uint32(len(e.buf)-start))
+}
+
+type dbusDecoder struct {
+	buf   []byte
+	pos   int
+	order binary.ByteOrder
+	err   error
+}
+
+func newDBusDecoder(buf []byte, order binary.ByteOrder) *dbusDecoder {
+	return &dbusDecoder{buf: buf, order: order}
+}
+
+func (d *dbusDecoder) need(n int) bool {
+	if d.err == nil && d.pos+n > len(d.buf) {
+		d.err = io.ErrUnexpectedEOF
+	}
+	return d.err == nil
+}
+
+func (d *dbusDecoder) align(n int) {
+	d.pos = (d.pos + n - 1) / n * n
+}
+
+func (d *dbusDecoder) byte() byte {
+	if !d.need(1) {
+		return 0
+	}
+	d.pos++
+	return d.buf[d.pos-1]
+}
+
+func (d *dbusDecoder) uint32() uint32 {
+	d.align(4)
+	if !d.need(4) {
+		return 0
+	}
+	d.pos += 4
+	return d.order.Uint32(d.buf[d.pos-4:])
+}
+
+func (d *dbusDecoder) string() string {
+	n := int(d.uint32())
+	if !d.need(n + 1) {
+		return ""
+	}
+	s := string(d.buf[d.pos : d.pos+n])
+	d.pos += n + 1
+	return s
+}
+
+func (d *dbusDecoder) signature() string {
+	n := int(d.byte())
+	if !d.need(n + 1) {
+		return ""
+	}
+	s := string(d.buf[d.pos : d.pos+n])
+	d.pos += n + 1
+	return s
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// deferMu serializes access to the deferred file from concurrent daemons.
+var deferMu sync.Mutex
+
+// deferredLine is a deferred notification as stored.
+type deferredLine struct {
+	Host     string    `json:"host,omitempty"`
+	ThreadID string    `json:"thread_id"`
+	Until    time.Time `json:"until"`
+}
+
+// updateDeferred loads client's deferred queue, applies f, and saves it. The
+// queue is the "deferred" document in the state store, so it survives a
+// restart overnight.
+func updateDeferred(client *GitHubClient, f func(q *core.DeferQueue)) error {
+	st, err := client.store()
+	if err != nil {
+		return err
+	}
+	deferMu.Lock()
+	defer deferMu.Unlock()
+
+	var lines []deferredLine
+	data, err := st.Load("deferred")
+	if err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &lines); err != nil {
+			return err
+		}
+	}
+	var q core.DeferQueue
+	for _, l := range lines {
+		q.Add(core.DeferredThread{Host: l.Host, ThreadID: l.ThreadID, Until: l.Until})
+	}
+	f(&q)
+
+	lines = lines[:0]
+	for _, t := range q.Items {
+		lines = append(lines, deferredLine{Host: t.Host, ThreadID: t.ThreadID, Until: t.Until.UTC()})
+	}
+	if data, err = json.Marshal(lines); err != nil {
+		return err
+	}
+	return st.Save("deferred", data)
+}
+
+// storeDeferred saves the cycle's deferred notifications to be classified
+// again when business hours open. Failing to is only a warning: they stay
+// unread either way.
+func storeDeferred(client *GitHubClient, decisions []core.Decision) {
+	if journalOff {
+		return
+	}
+	var deferred []core.DeferredThread
+	for _, d := range decisions {
+		if d.Action == core.ActionDefer {
+			deferred = append(deferred, core.DeferredThread{Host: d.Notification.Host, ThreadID: d.Notification.ID, Until: d.Until})
+		}
+	}
+	if len(deferred) == 0 {
+		return
+	}
+	err := updateDeferred(client, func(q *core.DeferQueue) {
+		for _, t := range deferred {
+			q.Add(t)
+		}
+	})
+	if err != nil {
+		log.Printf("warning: deferred notifications: %s", err)
+	}
+}
+
+// processDeferred classifies again the notifications whose deferral has
+// run out, except those the listing already decided this cycle, and mutes
+// as processNotifications does.
+func processDeferred(client *GitHubClient, cfg core.Config, mode core.Mode, decided []core.Decision, retries *core.RetryQueue, apply, verbose bool, now time.Time) ([]core.Decision, core.MarkCounts) {
+	if journalOff {
+		return nil, core.MarkCounts{}
+	}
+	var due []string
+	err := updateDeferred(client, func(q *core.DeferQueue) {
+		due = q.TakeDue(client.host, now)
+	})
+	if err != nil {
+		log.Printf("warning: deferred notifications: %s", err)
+		return nil, core.MarkCounts{}
+	}
+	seen := make(map[string]bool, len(decided))
+	for _, d := range decided {
+		seen[d.Notification.ID] = true
+	}
+	var ns []core.Notification
+	for _, id := range due {
+		if seen[id] {
+			continue
+		}
+		n, err := client.GetThread(id)
+		if err != nil {
+			log.Printf("warning: deferred notification: %s", err)
+			continue
+		}
+		ns = append(ns, n)
+	}
+	if len(ns) == 0 {
+		return nil, core.MarkCounts{}
+	}
+	if verbose {
+		log.Printf("classifying %d deferred notifications again", len(ns))
+	}
+	return processNotifications(client, cfg, mode, startInput(ns), retries, !apply, apply, verbose)
+}
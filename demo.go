@@ -0,0 +1,454 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// runDemo runs mutemath against a fake GitHub seeded with sample
+// notifications, taking the same flags as a normal run. The fake starts afresh
+// each time; within a daemon, later cycles see earlier mutes.
+func runDemo(args []string) int {
+	demo := newDemoServer()
+	defer demo.Close()
+	journalOff = true
+	log.Printf("demo: fake GitHub at %s, signed in as %s; nothing here touches your account", demo.srv.URL, demoLogin)
+	return runMain(args, demo)
+}
+
+// demoLogin is the user the demo server authenticates every request as.
+const demoLogin = "demo-user"
+
+// demoThread is a sample notification and the pull request or issue behind it.
+type demoThread struct {
+	id      string
+	repo    string // "org/repo"
+	private bool
+	number  int
+	kind    string // "PullRequest" or "Issue"
+	reason  string
+	title   string
+	age     time.Duration // how long before the server started it was updated
+
+	// Pull request details.
+	author     string
+	users      []string // requested reviewers
+	teams      []string // requested team slugs
+	draft      bool
+	labels     []string
+	body       string
+	files      []string
+	reviews    []string // review authors
+	comments   []string // conversation comment authors
+	decision   string   // GraphQL reviewDecision
+	codeowners bool     // teams were requested by CODEOWNERS, not by hand
+}
+
+// demoThreads seeds the demo inbox with a mix of team-only requests, direct
+// requests, and notifications that aren't review requests at all.
+var demoThreads = []demoThread{
+	{id: "1001", repo: "acme/api", private: true, number: 101, kind: "PullRequest", reason: "review_requested", age: 5 * time.Minute,
+		title: "Bump golang.org/x/net from 0.20.0 to 0.23.0", author: "dependabot[bot]", teams: []string{"backend"},
+		labels: []string{"dependencies"}, files: []string{"go.mod", "go.sum"}, codeowners: true},
+	{id: "1002", repo: "acme/api", private: true, number: 102, kind: "PullRequest", reason: "review_requested", age: 20 * time.Minute,
+		title: "Add rate limiting to the public API", author: "alice", users: []string{demoLogin}, teams: []string{"backend"},
+		files: []string{"internal/ratelimit/bucket.go", "internal/ratelimit/bucket_test.go", "cmd/api/main.go"}},
+	{id: "1003", repo: "acme/api", private: true, number: 103, kind: "PullRequest", reason: "review_requested", age: 45 * time.Minute,
+		title: "Refactor billing service", author: "frank", teams: []string{"backend"},
+		files: []string{"internal/billing/service.go"}, reviews: []string{"dave"}, decision: "APPROVED"},
+	{id: "1004", repo: "acme/web", private: true, number: 57, kind: "PullRequest", reason: "review_requested", age: time.Hour,
+		title: "Migrate settings page to the new design system", author: "bob", teams: []string{"frontend", "design"},
+		files: []string{"src/pages/settings.tsx", "src/styles/tokens.css"}, comments: []string{"bob", demoLogin},
+		codeowners: true},
+	{id: "1005", repo: "acme/web", private: true, number: 58, kind: "PullRequest", reason: "review_requested", age: 2 * time.Hour,
+		title: "Fix login redirect loop", author: "carol", teams: []string{"frontend"},
+		body:  "The session cookie wasn't cleared on logout.\n\n@" + demoLogin + " could you look at the session handling?",
+		files: []string{"src/auth/session.ts"}},
+	{id: "1006", repo: "acme/infra", private: true, number: 12, kind: "PullRequest", reason: "review_requested", age: 3 * time.Hour,
+		title: "WIP: rotate staging database credentials", author: "erin", teams: []string{"platform"}, draft: true,
+		files: []string{"terraform/staging/db.tf"}},
+	{id: "1007", repo: "acme/api", private: true, number: 99, kind: "Issue", reason: "mention", age: 4 * time.Hour,
+		title: "Flaky test in payments suite"},
+	{id: "1008", repo: "oss/widgets", number: 480, kind: "PullRequest", reason: "review_requested", age: 5 * time.Hour,
+		title: "Support custom themes", author: "stranger", users: []string{demoLogin},
+		files: []string{"themes/custom.go", "README.md"}},
+	{id: "1009", repo: "acme/web", private: true, number: 60, kind: "PullRequest", reason: "comment", age: 6 * time.Hour,
+		title: "Upgrade to React 19", author: "bob"},
+	// The same bump as 1001 in other repos: a wave, for --waves.
+	{id: "1010", repo: "acme/web", private: true, number: 61, kind: "PullRequest", reason: "review_requested", age: 7 * time.Hour,
+		title: "Bump golang.org/x/net from 0.21.0 to 0.23.0", author: "dependabot[bot]", teams: []string{"frontend"},
+		labels: []string{"dependencies"}, files: []string{"tools/go.mod", "tools/go.sum"}, codeowners: true},
+	{id: "1011", repo: "acme/infra", private: true, number: 13, kind: "PullRequest", reason: "review_requested", age: 8 * time.Hour,
+		title: "Bump golang.org/x/net from 0.20.0 to 0.23.0", author: "dependabot[bot]", teams: []string{"platform"},
+		labels: []string{"dependencies"}, files: []string{"go.mod", "go.sum"}, codeowners: true},
+}
+
+var demoLanguages = map[string]string{
+	"acme/api":    "Go",
+	"acme/web":    "TypeScript",
+	"acme/infra":  "HCL",
+	"oss/widgets": "Go",
+}
+
+var demoTopics = map[string][]string{
+	"acme/api":    {"go", "backend"},
+	"acme/web":    {"typescript", "frontend"},
+	"acme/infra":  {"terraform"},
+	"oss/widgets": {"open-source"},
+}
+
+var demoTeamMembers = map[string][]string{
+	"backend":  {demoLogin, "dave", "frank"},
+	"frontend": {demoLogin, "bob", "carol"},
+	"design":   {"grace"},
+	"platform": {demoLogin, "erin"},
+}
+
+// demoState is a thread's inbox state, changed by mutations.
+type demoState struct {
+	unread  bool
+	ignored bool
+	done    bool
+	updated time.Time
+}
+
+// demoServer is a fake GitHub API seeded with demoThreads. It serves just
+// the endpoints mutemath calls, and mutations change its state, so a second
+// run sees the result of the first.
+type demoServer struct {
+	srv     *httptest.Server
+	started time.Time
+
+	mu       sync.Mutex
+	state    map[string]*demoState
+	modified time.Time // for Last-Modified; changes with every mutation
+	requests int       // for the rate-limit headers
+	points   int       // for GraphQL's rateLimit field
+}
+
+func newDemoServer() *demoServer {
+	d := &demoServer{started: time.Now().UTC().Truncate(time.Second), state: make(map[string]*demoState)}
+	d.modified = d.started
+	for _, t := range demoThreads {
+		d.state[t.id] = &demoState{unread: true, updated: d.started.Add(-t.age)}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		writeDemoJSON(w, map[string]string{"current_user_url": d.srv.URL + "/user"})
+	})
+	mux.HandleFunc("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-OAuth-Scopes", "notifications, repo")
+		writeDemoJSON(w, ghAuthenticatedUser{Login: demoLogin})
+	})
+	mux.HandleFunc("GET /notifications", d.listNotifications)
+	mux.HandleFunc("GET /notifications/threads/{id}", d.thread(func(w http.ResponseWriter, r *http.Request, s *demoState) {
+		t := demoThreads[slices.IndexFunc(demoThreads, func(t demoThread) bool { return t.id == r.PathValue("id") })]
+		writeDemoJSON(w, d.notification(t, s))
+	}))
+	mux.HandleFunc("PATCH /notifications/threads/{id}", d.thread(func(w http.ResponseWriter, r *http.Request, s *demoState) {
+		s.unread = false
+		w.WriteHeader(http.StatusResetContent)
+	}))
+	mux.HandleFunc("DELETE /notifications/threads/{id}", d.thread(func(w http.ResponseWriter, r *http.Request, s *demoState) {
+		s.unread, s.done = false, true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	mux.HandleFunc("GET /notifications/threads/{id}/subscription", d.thread(func(w http.ResponseWriter, r *http.Request, s *demoState) {
+		writeDemoJSON(w, ghThreadSubscription{Ignored: s.ignored})
+	}))
+	mux.HandleFunc("PUT /notifications/threads/{id}/subscription", d.thread(func(w http.ResponseWriter, r *http.Request, s *demoState) {
+		s.ignored = true
+		writeDemoJSON(w, ghThreadSubscription{Ignored: true})
+	}))
+	mux.HandleFunc("GET /repos/{owner}/{repo}/pulls/{number}", d.pull(func(w http.ResponseWriter, r *http.Request, t demoThread) {
+		pr := ghPullRequest{
+			User:  ghUser{Login: t.author},
+			Draft: t.draft,
+			State: "open",
+			Body:  t.body,
+			Head:  ghRef{Ref: fmt.Sprintf("%s/pr-%d", t.author, t.number)},
+			Base:  ghRef{Ref: "main"},
+		}
+		for _, l := range t.labels {
+			pr.Labels = append(pr.Labels, ghLabel{Name: l})
+		}
+		writeDemoJSON(w, pr)
+	}))
+	mux.HandleFunc("GET /repos/{owner}/{repo}/pulls/{number}/requested_reviewers", d.pull(func(w http.ResponseWriter, r *http.Request, t demoThread) {
+		resp := ghReviewersResponse{Users: []ghUser{}, Teams: []ghTeam{}}
+		for _, u := range t.users {
+			resp.Users = append(resp.Users, ghUser{Login: u})
+		}
+		for _, s := range t.teams {
+			resp.Teams = append(resp.Teams, ghTeam{Slug: s})
+		}
+		writeDemoJSON(w, resp)
+	}))
+	// The sample PRs don't change; answering review request changes like GitHub is enough.
+	mux.HandleFunc("POST /repos/{owner}/{repo}/pulls/{number}/requested_reviewers", d.pull(func(w http.ResponseWriter, r *http.Request, t demoThread) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(ghPullRequest{User: ghUser{Login: t.author}, State: "open"})
+	}))
+	mux.HandleFunc("DELETE /repos/{owner}/{repo}/pulls/{number}/requested_reviewers", d.pull(func(w http.ResponseWriter, r *http.Request, t demoThread) {
+		writeDemoJSON(w, ghPullRequest{User: ghUser{Login: t.author}, State: "open"})
+	}))
+	mux.HandleFunc("GET /repos/{owner}/{repo}/pulls/{number}/files", d.pull(func(w http.ResponseWriter, r *http.Request, t demoThread) {
+		files := []ghPullRequestFile{}
+		if start, end, ok := demoPage(r, len(t.files)); ok {
+			for _, f := range t.files[start:end] {
+				files = append(files, ghPullRequestFile{Filename: f})
+			}
+		}
+		writeDemoJSON(w, files)
+	}))
+	mux.HandleFunc("GET /repos/{owner}/{repo}/pulls/{number}/reviews", d.pull(func(w http.ResponseWriter, r *http.Request, t demoThread) {
+		reviews := []ghReview{}
+		for _, u := range t.reviews {
+			reviews = append(reviews, ghReview{User: ghUser{Login: u}})
+		}
+		writeDemoJSON(w, reviews)
+	}))
+	mux.HandleFunc("GET /repos/{owner}/{repo}/issues/{number}/comments", d.pull(func(w http.ResponseWriter, r *http.Request, t demoThread) {
+		comments := []ghComment{}
+		for _, u := range t.comments {
+			comments = append(comments, ghComment{User: ghUser{Login: u}})
+		}
+		writeDemoJSON(w, comments)
+	}))
+	mux.HandleFunc("GET /repos/{owner}/{repo}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("owner") + "/" + r.PathValue("repo")
+		writeDemoJSON(w, ghRepository{FullName: name, Owner: ghOwner{Login: r.PathValue("owner")}, Language: demoLanguages[name]})
+	})
+	mux.HandleFunc("GET /repos/{owner}/{repo}/topics", func(w http.ResponseWriter, r *http.Request) {
+		writeDemoJSON(w, ghTopics{Names: append([]string{}, demoTopics[r.PathValue("owner")+"/"+r.PathValue("repo")]...)})
+	})
+	mux.HandleFunc("GET /orgs/{org}/teams/{slug}/members", func(w http.ResponseWriter, r *http.Request) {
+		members := []ghUser{}
+		for _, m := range demoTeamMembers[r.PathValue("slug")] {
+			members = append(members, ghUser{Login: m})
+		}
+		writeDemoJSON(w, members)
+	})
+	// Only the search --cross-check runs has results; others find nothing.
+	mux.HandleFunc("GET /search/issues", func(w http.ResponseWriter, r *http.Request) {
+		var result ghIssueSearch
+		result.Items = []ghIssueSearchItem{}
+		if strings.Contains(r.URL.Query().Get("q"), "user-review-requested:@me") {
+			for _, t := range demoThreads {
+				if t.kind == "PullRequest" && slices.Contains(t.users, demoLogin) {
+					var item ghIssueSearchItem
+					item.PullRequest.URL = fmt.Sprintf("%s/repos/%s/pulls/%d", d.srv.URL, t.repo, t.number)
+					result.Items = append(result.Items, item)
+				}
+			}
+		}
+		writeDemoJSON(w, result)
+	})
+	mux.HandleFunc("POST /graphql", d.graphQL)
+
+	d.srv = httptest.NewTLSServer(d.countRequests(mux))
+	return d
+}
+
+// Close shuts the server down.
+func (d *demoServer) Close() {
+	d.srv.Close()
+}
+
+// Client returns a GitHub client for the demo server, trusting its
+// self-signed certificate.
+func (d *demoServer) Client() *GitHubClient {
+	c := NewGitHubClient("demo-token", d.srv.URL)
+	c.httpClient = d.srv.Client()
+	c.httpClient.Timeout = 30 * time.Second
+	return c
+}
+
+// countRequests adds rate-limit headers as GitHub does, counting down from
+// 5000 with each request.
+func (d *demoServer) countRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d.mu.Lock()
+		d.requests++
+		remaining := max(0, 5000-d.requests)
+		d.mu.Unlock()
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(d.started.Add(time.Hour).Unix(), 10))
+		w.Header().Set("X-RateLimit-Resource", "core")
+		if r.URL.Path == "/graphql" {
+			w.Header().Set("X-RateLimit-Resource", "graphql")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (d *demoServer) listNotifications(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	lastModified := d.modified.Format(http.TimeFormat)
+	w.Header().Set("X-Poll-Interval", "60")
+	w.Header().Set("Last-Modified", lastModified)
+	if r.Header.Get("If-Modified-Since") == lastModified {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	all := r.URL.Query().Get("all") == "true"
+	var since time.Time
+	if s := r.URL.Query().Get("since"); s != "" {
+		since, _ = time.Parse(time.RFC3339, s)
+	}
+
+	var listed []ghNotification
+	for _, t := range demoThreads {
+		s := d.state[t.id]
+		if (!s.unread && !all) || s.done || s.updated.Before(since) {
+			continue
+		}
+		listed = append(listed, d.notification(t, s))
+	}
+	page := []ghNotification{}
+	if start, end, ok := demoPage(r, len(listed)); ok {
+		page = listed[start:end]
+	}
+	writeDemoJSON(w, page)
+}
+
+// notification renders a sample thread as the API does.
+func (d *demoServer) notification(t demoThread, s *demoState) ghNotification {
+	owner, _, _ := strings.Cut(t.repo, "/")
+	unread := s.unread
+	kind := "pulls"
+	if t.kind == "Issue" {
+		kind = "issues"
+	}
+	return ghNotification{
+		ID:     t.id,
+		Reason: t.reason,
+		Subject: ghSubject{
+			Title: t.title,
+			URL:   fmt.Sprintf("%s/repos/%s/%s/%d", d.srv.URL, t.repo, kind, t.number),
+			Type:  t.kind,
+		},
+		Repository: ghRepository{FullName: t.repo, Owner: ghOwner{Login: owner}, Private: t.private},
+		UpdatedAt:  s.updated,
+		Unread:     &unread,
+	}
+}
+
+// thread serves a request for one notification thread, under the lock. A
+// mutation counts as a change for Last-Modified.
+func (d *demoServer) thread(serve func(http.ResponseWriter, *http.Request, *demoState)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		s, ok := d.state[r.PathValue("id")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != "GET" {
+			// Last-Modified has one-second resolution, so always move it on.
+			d.modified = d.modified.Add(time.Second)
+			if now := time.Now().UTC().Truncate(time.Second); now.After(d.modified) {
+				d.modified = now
+			}
+		}
+		serve(w, r, s)
+	}
+}
+
+// pull serves a request about one of the sample pull requests.
+func (d *demoServer) pull(serve func(http.ResponseWriter, *http.Request, demoThread)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t, ok := findDemoPull(r.PathValue("owner")+"/"+r.PathValue("repo"), r.PathValue("number"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		serve(w, r, t)
+	}
+}
+
+// graphQL answers the queries mutemath makes, for a PR's review decision and
+// pending review requests, with both.
+func (d *demoServer) graphQL(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Variables struct {
+			Owner  string `json:"owner"`
+			Repo   string `json:"repo"`
+			Number int    `json:"number"`
+		} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	t, ok := findDemoPull(req.Variables.Owner+"/"+req.Variables.Repo, strconv.Itoa(req.Variables.Number))
+	if !ok {
+		writeDemoJSON(w, map[string]any{"errors": []map[string]string{{"message": "Could not resolve to a PullRequest"}}})
+		return
+	}
+	d.mu.Lock()
+	d.points++
+	remaining := max(0, 5000-d.points)
+	d.mu.Unlock()
+	requests := []map[string]any{}
+	for _, u := range t.users {
+		requests = append(requests, map[string]any{"asCodeOwner": false, "requestedReviewer": map[string]any{"login": u}})
+	}
+	for _, team := range t.teams {
+		requests = append(requests, map[string]any{"asCodeOwner": t.codeowners, "requestedReviewer": map[string]any{"slug": team}})
+	}
+	writeDemoJSON(w, map[string]any{"data": map[string]any{
+		"repository": map[string]any{"pullRequest": map[string]any{
+			"reviewDecision": t.decision,
+			"reviewRequests": map[string]any{"nodes": requests},
+		}},
+		"rateLimit": map[string]any{"cost": 1, "limit": 5000, "remaining": remaining, "resetAt": d.started.Add(time.Hour)},
+	}})
+}
+
+func findDemoPull(repo, number string) (demoThread, bool) {
+	i := slices.IndexFunc(demoThreads, func(t demoThread) bool {
+		return t.kind == "PullRequest" && t.repo == repo && strconv.Itoa(t.number) == number
+	})
+	if i < 0 {
+		return demoThread{}, false
+	}
+	return demoThreads[i], true
+}
+
+// demoPage returns the [start, end) bounds of the page the request asks for
+// out of n items, or ok false past the last page.
+func demoPage(r *http.Request, n int) (start, end int, ok bool) {
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 30
+	}
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+	start = (page - 1) * perPage
+	if start >= n {
+		return 0, 0, false
+	}
+	return start, min(n, start+perPage), true
+}
+
+func writeDemoJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
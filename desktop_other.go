@@ -0,0 +1,9 @@
+//go:build !linux
+
+package main
+
+import "fmt"
+
+func newDesktopNotifier() (notifier, error) {
+	return nil, fmt.Errorf("desktop notifications are only supported on Linux")
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// runDoctor checks the local setup and prints a fix for anything that's wrong.
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to the JSON config file to check (default "+defaultConfigPath()+")")
+	fs.Parse(args)
+
+	host := os.Getenv("GH_HOST")
+	webBase := core.WebBaseURL(host)
+	token, tokenErr := resolveToken(host)
+
+	client := NewGitHubClient(token, core.APIBaseURL(host))
+	d := client.Diagnose()
+	now := time.Now()
+
+	results := []core.CheckResult{core.CheckReachability(client.baseURL, d.ReachErr)}
+	switch {
+	case tokenErr != nil:
+		results = append(results, core.CheckTokenMissing(webBase))
+	case d.ReachErr == nil:
+		results = append(results, core.CheckToken(d.UserStatus, d.Login, webBase))
+		if d.UserStatus == 200 {
+			results = append(results, core.CheckScopes(d.Scopes, webBase))
+			if d.Scopes != "" {
+				results = append(results, core.CheckOrgScope(d.Scopes, webBase))
+			}
+		}
+	}
+	if d.ReachErr == nil {
+		results = append(results, core.CheckClockSkew(d.ServerDate, now))
+	}
+
+	path, required := resolveConfigPath(*configPath)
+	local, found, err := loadConfig(path, required)
+	results = append(results, core.CheckConfig(path, found, len(local.rules), err))
+	if !local.policy.IsZero() {
+		policy, err := loadPolicy(client, local.policy)
+		results = append(results, core.CheckPolicy(local.policy, len(policy.rules), err))
+	}
+
+	results = append(results, checkStateDir(local.state))
+
+	for _, r := range results {
+		fmt.Println(core.FormatCheckResult(r))
+	}
+	if core.AnyFailed(results) {
+		return 1
+	}
+	return 0
+}
+
+// checkStateDir tries the state dir the config file asks for, or the
+// default, by creating it if need be and writing and removing a file.
+func checkStateDir(spec core.StateSpec) core.CheckResult {
+	dir := spec.Dir
+	if dir == "" {
+		var err error
+		if dir, err = stateDir(); err != nil {
+			return core.CheckStateDir("", err)
+		}
+	}
+	err := os.MkdirAll(dir, 0o755)
+	if err == nil {
+		var f *os.File
+		if f, err = os.CreateTemp(dir, ".mutemath-doctor-*"); err == nil {
+			f.Close()
+			err = os.Remove(f.Name())
+		}
+	}
+	return core.CheckStateDir(dir, err)
+}
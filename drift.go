@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// priorLine is a decision of the previous run as stored.
+type priorLine struct {
+	Time     time.Time `json:"time"`
+	Host     string    `json:"host,omitempty"`
+	ThreadID string    `json:"thread_id"`
+	Action   string    `json:"action"`
+	Reason   string    `json:"reason"`
+}
+
+// readPrior reads the previous run's decisions, on every host, from the
+// "decisions" document in the state store. Before the first run there are
+// none.
+func readPrior() ([]core.PriorDecision, error) {
+	st, err := currentState()
+	if err != nil {
+		return nil, err
+	}
+	data, err := st.Load("decisions")
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var lines []priorLine
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return nil, fmt.Errorf("decisions: %w", err)
+	}
+	prior := make([]core.PriorDecision, 0, len(lines))
+	for _, l := range lines {
+		action, err := core.ParseAction(l.Action)
+		if err != nil {
+			return nil, fmt.Errorf("decisions: %w", err)
+		}
+		prior = append(prior, core.PriorDecision{Time: l.Time, Host: l.Host, ThreadID: l.ThreadID, Action: action, Reason: l.Reason})
+	}
+	return prior, nil
+}
+
+// storePrior replaces the stored decisions for host with this run's, for
+// the next --diff. Failing to is only a warning.
+func storePrior(host string, decisions []core.Decision, now time.Time) {
+	if journalOff {
+		return
+	}
+	if err := writePrior(host, core.Prior(decisions, now)); err != nil {
+		log.Printf("warning: store decisions for --diff: %s", err)
+	}
+}
+
+func writePrior(host string, decisions []core.PriorDecision) error {
+	st, err := currentState()
+	if err != nil {
+		return err
+	}
+	prior, err := readPrior()
+	if err != nil {
+		return err
+	}
+	var lines []priorLine
+	for _, p := range prior {
+		if !strings.EqualFold(p.Host, host) {
+			lines = append(lines, toPriorLine(p))
+		}
+	}
+	for _, p := range decisions {
+		lines = append(lines, toPriorLine(p))
+	}
+	data, err := json.Marshal(lines)
+	if err != nil {
+		return err
+	}
+	return st.Save("decisions", data)
+}
+
+func toPriorLine(p core.PriorDecision) priorLine {
+	return priorLine{Time: p.Time.UTC(), Host: p.Host, ThreadID: p.ThreadID, Action: strings.ToLower(p.Action.String()), Reason: p.Reason}
+}
+
+// printDrift prints the decisions that changed since the previous run on
+// client's host, instead of every decision. The demo has no previous run.
+func printDrift(client *GitHubClient, decisions []core.Decision, now time.Time) {
+	var prior []core.PriorDecision
+	if !journalOff {
+		var err error
+		if prior, err = readPrior(); err != nil {
+			log.Printf("warning: read previous decisions for --diff: %s", err)
+		}
+	}
+	var previous time.Time
+	var onHost []core.PriorDecision
+	for _, p := range prior {
+		if strings.EqualFold(p.Host, client.host) {
+			onHost = append(onHost, p)
+			previous = p.Time
+		}
+	}
+	drifts := core.DiffDecisions(onHost, decisions)
+	for _, d := range drifts {
+		fmt.Fprintln(stdout, core.FormatDriftRow(d))
+	}
+	if len(drifts) > 0 {
+		fmt.Fprintln(stdout)
+	}
+	fmt.Fprintln(stdout, core.FormatDriftSummary(len(drifts), len(decisions), previous, now))
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// classifyAll decides every notification from the fetch stage without
+// printing or mutating anything.
+func classifyAll(client *GitHubClient, cfg core.Config, fetch *fetchStage, verbose bool) []core.Decision {
+	c := newClassifier(client, cfg, verbose)
+	var decisions []core.Decision
+	for page := fetch.Next(); page != nil; page = fetch.Next() {
+		for _, n := range page {
+			d := c.decide(n)
+			if client.expired() {
+				fetch.Drain()
+				return decisions
+			}
+			decisions = append(decisions, d)
+		}
+	}
+	return decisions
+}
+
+// editPlan writes decisions to a temp file as a plan, each wave as one line,
+// opens the user's editor on it, and returns the edited plan.
+func editPlan(decisions []core.Decision, waves []core.Wave) ([]core.Decision, error) {
+	edited, err := editText("mutemath-plan-*.txt", core.FormatPlan(decisions, waves))
+	if err != nil {
+		return nil, err
+	}
+	return core.ParsePlan(edited, decisions, waves)
+}
+
+// editText writes text to a temp file named after pattern, opens the user's
+// editor on it, and returns what they saved.
+func editText(pattern, text string) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("write plan: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return "", fmt.Errorf("write plan: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("write plan: %w", err)
+	}
+
+	editor := core.EditorCommand(os.Getenv("VISUAL"), os.Getenv("EDITOR"))
+	cmd := exec.Command(editor[0], append(editor[1:], f.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %s: %w", editor[0], err)
+	}
+
+	edited, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", fmt.Errorf("read plan: %w", err)
+	}
+	return string(edited), nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// eventLog appends to the --event-log file; nil when there's none.
+var eventLog *eventWriter
+
+// eventLine is an event as stored, one JSON object per line.
+type eventLine struct {
+	V        int       `json:"v"`
+	Time     time.Time `json:"time"`
+	Event    string    `json:"event"`
+	User     string    `json:"user,omitempty"`
+	Host     string    `json:"host,omitempty"`
+	ThreadID string    `json:"thread_id"`
+	Label    string    `json:"label"`
+	Title    string    `json:"title"`
+	URL      string    `json:"url,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+	State    string    `json:"state,omitempty"`
+	Action   string    `json:"action,omitempty"`
+	Why      string    `json:"why,omitempty"`
+	Rule     string    `json:"rule,omitempty"`
+	Teams    []string  `json:"teams,omitempty"`
+	Apply    bool      `json:"apply,omitempty"`
+	Mode     string    `json:"mode,omitempty"`
+	Step     string    `json:"step,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Escalate bool      `json:"escalate,omitempty"`
+}
+
+// eventWriter appends events to a file, whole lines at a time, from any
+// number of daemons.
+type eventWriter struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func openEventLog(path string) (*eventWriter, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &eventWriter{f: f}, nil
+}
+
+// write appends an event for client. Failing to is only a warning, like the
+// journal.
+func (w *eventWriter) write(client *GitHubClient, e core.Event) {
+	if w == nil {
+		return
+	}
+	e.User = client.user
+	data, err := json.Marshal(eventLine{
+		V:        core.EventSchemaVersion,
+		Time:     e.Time.UTC(),
+		Event:    e.Kind,
+		User:     e.User,
+		Host:     e.Host,
+		ThreadID: e.ThreadID,
+		Label:    e.Label,
+		Title:    e.Title,
+		URL:      e.URL,
+		Reason:   e.Reason,
+		State:    e.State,
+		Action:   e.Action,
+		Why:      e.Why,
+		Rule:     e.Rule,
+		Teams:    e.Teams,
+		Apply:    e.Apply,
+		Mode:     e.Mode,
+		Step:     e.Step,
+		Error:    e.Err,
+		Escalate: e.Escalate,
+	})
+	if err != nil {
+		log.Printf("warning: event log: %s", err)
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.f.Write(append(data, '\n')); err != nil {
+		log.Printf("warning: event log: %s", err)
+	}
+}
+
+// Close closes the file; a nil writer has none.
+func (w *eventWriter) Close() error {
+	if w == nil {
+		return nil
+	}
+	return w.f.Close()
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// feedbackLine is a correction as stored, one JSON object per line.
+type feedbackLine struct {
+	Time     time.Time `json:"time"`
+	Host     string    `json:"host,omitempty"`
+	ThreadID string    `json:"thread_id"`
+	Label    string    `json:"label"`
+	Title    string    `json:"title"`
+	Repo     string    `json:"repo"`
+	Teams    []string  `json:"teams,omitempty"`
+	Was      string    `json:"was"`
+	ShouldBe string    `json:"should_be"`
+}
+
+// runFeedback records that mutemath got a thread wrong, then suggests rules
+// from every correction so far. Without --thread, it only suggests.
+func runFeedback(args []string) int {
+	fs := flag.NewFlagSet("feedback", flag.ExitOnError)
+	thread := fs.String("thread", "", "the thread ID mutemath got wrong, from its notification's thread URL")
+	shouldBe := fs.String("should-have-been", "", "what it should have done with the thread: keep or mute")
+	host := fs.String("host", "", "with several hosts in the config file, the host the thread is on (default any)")
+	configPath := fs.String("config", "", "path to the JSON config file, for its state (default "+defaultConfigPath()+")")
+	fs.Parse(args)
+	if (*thread == "") != (*shouldBe == "") {
+		fmt.Fprintf(os.Stderr, "Error: --thread and --should-have-been go together\n")
+		return 1
+	}
+
+	path, required := resolveConfigPath(*configPath)
+	local, _, err := loadConfig(path, required)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if err := useState(local.state); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	corrections, err := readFeedback()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	if *thread != "" {
+		action, err := core.ParseAction(*shouldBe)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --should-have-been: %s\n", err)
+			return 1
+		}
+		records, err := readJournal()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		r, err := core.LatestRecord(records, *host, *thread)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		c, err := core.NewCorrection(r, action, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		if err := appendFeedback(c); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		corrections = append(corrections, c)
+		fmt.Printf("Recorded: %s %q should have been %s, not %s\n", c.Label, c.Title, strings.ToLower(c.ShouldBe.String()), strings.ToLower(c.Was.String()))
+		if c.Was == core.ActionMute {
+			fmt.Println("It's still muted: subscribe to it again on GitHub to get its notifications.")
+		}
+		fmt.Println()
+	}
+	fmt.Print(core.FormatSuggestions(core.SuggestRules(corrections), len(corrections)))
+	return 0
+}
+
+// appendFeedback adds a correction to the "feedback" log in the state store.
+func appendFeedback(c core.Correction) error {
+	st, err := currentState()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(feedbackLine{
+		Time:     c.Time.UTC(),
+		Host:     c.Host,
+		ThreadID: c.ThreadID,
+		Label:    c.Label,
+		Title:    c.Title,
+		Repo:     c.Repo,
+		Teams:    c.Teams,
+		Was:      strings.ToLower(c.Was.String()),
+		ShouldBe: strings.ToLower(c.ShouldBe.String()),
+	})
+	if err != nil {
+		return err
+	}
+	return st.Append("feedback", data)
+}
+
+// readFeedback reads every correction, in the order recorded.
+func readFeedback() ([]core.Correction, error) {
+	st, err := currentState()
+	if err != nil {
+		return nil, err
+	}
+	lines, err := st.Records("feedback")
+	if err != nil {
+		return nil, err
+	}
+	var corrections []core.Correction
+	for i, data := range lines {
+		var l feedbackLine
+		if err := json.Unmarshal(data, &l); err != nil {
+			return nil, fmt.Errorf("feedback record %d: %w", i+1, err)
+		}
+		was, err := core.ParseAction(l.Was)
+		if err != nil {
+			return nil, fmt.Errorf("feedback record %d: %w", i+1, err)
+		}
+		shouldBe, err := core.ParseAction(l.ShouldBe)
+		if err != nil {
+			return nil, fmt.Errorf("feedback record %d: %w", i+1, err)
+		}
+		corrections = append(corrections, core.Correction{
+			Time:     l.Time,
+			Host:     l.Host,
+			ThreadID: l.ThreadID,
+			Label:    l.Label,
+			Title:    l.Title,
+			Repo:     l.Repo,
+			Teams:    l.Teams,
+			Was:      was,
+			ShouldBe: shouldBe,
+		})
+	}
+	return corrections, nil
+}
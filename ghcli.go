@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// progName is how the user invokes mutemath, "gh mutemath" when it runs as a
+// gh extension.
+var progName = core.ProgramName(os.Args[0])
+
+// resolveToken finds the token for host (GH_HOST's value, empty for
+// github.com): from the environment, or else from the gh CLI's login, so a gh
+// user needs no token setup of their own.
+func resolveToken(host string) (string, error) {
+	vars := core.TokenEnvVars(host)
+	for _, name := range vars {
+		if token := os.Getenv(name); token != "" {
+			return token, nil
+		}
+	}
+	if token := ghAuthToken(core.GHHostname(host)); token != "" {
+		return token, nil
+	}
+	return "", fmt.Errorf("no GitHub token: %s not set, and gh is not logged in to %s\n\nSet a GitHub Classic PAT with 'notifications' scope:\n  export GH_TOKEN=ghp_...\n\nor log in with the GitHub CLI:\n  gh auth login --hostname %[2]s --scopes notifications,repo",
+		strings.Join(vars, ", "), core.GHHostname(host))
+}
+
+// ghWebLogin runs gh auth login --web, GitHub's device flow, for hostname and
+// returns gh's new token. gh's output goes to stderr, clear of a run's rows.
+func ghWebLogin(hostname string) (string, error) {
+	cmd := exec.Command("gh", "auth", "login", "--hostname", hostname, "--scopes", "notifications,repo,read:org", "--web")
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stderr, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("gh auth login: %w", err)
+	}
+	token := ghAuthToken(hostname)
+	if token == "" {
+		return "", fmt.Errorf("gh isn't logged in to %s", hostname)
+	}
+	return token, nil
+}
+
+// ghAuthToken asks the gh CLI for its token for hostname. It returns "" if gh
+// isn't installed or isn't logged in to that host.
+func ghAuthToken(hostname string) string {
+	out, err := exec.Command("gh", "auth", "token", "--hostname", hostname).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -1,12 +1,19 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/lmarburger/mutemath/core"
@@ -19,6 +26,8 @@ type ghNotification struct {
 	Reason     string       `json:"reason"`
 	Subject    ghSubject    `json:"subject"`
 	Repository ghRepository `json:"repository"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+	Unread     *bool        `json:"unread,omitempty"` // nil, as in old --input files, counts as unread
 }
 
 type ghSubject struct {
@@ -30,6 +39,8 @@ type ghSubject struct {
 type ghRepository struct {
 	FullName string  `json:"full_name"`
 	Owner    ghOwner `json:"owner"`
+	Private  bool    `json:"private"`
+	Language string  `json:"language"` // only in a full repository, not a notification's
 }
 
 type ghOwner struct {
@@ -49,58 +60,492 @@ type ghTeam struct {
 	Slug string `json:"slug"`
 }
 
+type ghUserTeam struct {
+	Slug         string  `json:"slug"`
+	MembersCount int     `json:"members_count"`
+	Organization ghOwner `json:"organization"`
+}
+
+type ghPullRequest struct {
+	User      ghUser    `json:"user"`
+	Draft     bool      `json:"draft"`
+	State     string    `json:"state"`
+	Labels    []ghLabel `json:"labels"`
+	Body      string    `json:"body"`
+	Assignees []ghUser  `json:"assignees"`
+	Head      ghRef     `json:"head"`
+	Base      ghRef     `json:"base"`
+}
+
+type ghTopics struct {
+	Names []string `json:"names"`
+}
+
+type ghReview struct {
+	User        ghUser    `json:"user"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+type ghComment struct {
+	User ghUser `json:"user"`
+}
+
+type ghIssueSearch struct {
+	Items []ghIssueSearchItem `json:"items"`
+}
+
+type ghIssueSearchItem struct {
+	PullRequest struct {
+		URL string `json:"url"`
+	} `json:"pull_request"`
+}
+
+type ghPullRequestFile struct {
+	Filename string `json:"filename"`
+}
+
+type ghGraphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// ghGraphQLRateLimit is the rateLimit field queries ask for alongside their
+// data.
+type ghGraphQLRateLimit struct {
+	RateLimit *struct {
+		Cost      int       `json:"cost"`
+		Limit     int       `json:"limit"`
+		Remaining int       `json:"remaining"`
+		ResetAt   time.Time `json:"resetAt"`
+	} `json:"rateLimit"`
+}
+
+type ghReviewDecisionData struct {
+	Repository struct {
+		PullRequest struct {
+			ReviewDecision string `json:"reviewDecision"`
+		} `json:"pullRequest"`
+	} `json:"repository"`
+}
+
+type ghReviewRequestsData struct {
+	Repository struct {
+		PullRequest struct {
+			ReviewRequests struct {
+				Nodes []struct {
+					AsCodeOwner       bool `json:"asCodeOwner"`
+					RequestedReviewer struct {
+						Login string `json:"login"`
+						Slug  string `json:"slug"`
+					} `json:"requestedReviewer"`
+				} `json:"nodes"`
+			} `json:"reviewRequests"`
+		} `json:"pullRequest"`
+	} `json:"repository"`
+}
+
+type ghRef struct {
+	Ref string `json:"ref"`
+}
+
+type ghLabel struct {
+	Name string `json:"name"`
+}
+
+type ghThread struct {
+	Unread bool `json:"unread"`
+}
+
+type ghThreadSubscription struct {
+	Subscribed bool      `json:"subscribed"`
+	Ignored    bool      `json:"ignored"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 type ghAuthenticatedUser struct {
 	Login string `json:"login"`
 }
 
+type ghRelease struct {
+	TagName string           `json:"tag_name"`
+	Assets  []ghReleaseAsset `json:"assets"`
+}
+
+type ghReleaseAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+type ghRateLimits struct {
+	Resources map[string]ghRateLimitResource `json:"resources"`
+}
+
+type ghRateLimitResource struct {
+	Limit     int   `json:"limit"`
+	Used      int   `json:"used"`
+	Remaining int   `json:"remaining"`
+	Reset     int64 `json:"reset"`
+}
+
 // GitHubClient handles all GitHub API I/O.
 type GitHubClient struct {
 	token      string
+	baseURL    string     // REST API root, e.g. https://api.github.com
+	host       string     // qualifies labels when processing several hosts; empty otherwise
+	user       string     // names the inbox in a multi-user daemon; empty otherwise
+	state      stateStore // the user's state in a multi-user daemon; nil for the configured store
 	httpClient *http.Client
 	login      string
+	tel        *telemetry // nil unless --otel
+
+	// checkSubscription makes mutes GET the thread subscription first and skip
+	// the ignore write for threads that are already ignored.
+	checkSubscription bool
+
+	// crossCheck makes classification search for PRs requesting login
+	// personally and refuse to mute review requests on them.
+	crossCheck bool
+
+	// decline makes mutes of review requests also remove login's personal
+	// request from the PR.
+	decline bool
+
+	// reviewerMaxAge is how old the reviewer data behind a mute may be before
+	// it's fetched again and the thread decided afresh; zero to never.
+	reviewerMaxAge time.Duration
+
+	// fetchAnyway makes a dry run re-check would-be mutes against
+	// reviewerMaxAge as apply does, so the two can't decide differently.
+	fetchAnyway bool
+
+	userAgent  string // User-Agent header
+	apiVersion string // X-GitHub-Api-Version header
+
+	mu          sync.Mutex       // guards token, rateLimit, and graphQLCost; the notification listing runs concurrently
+	rateLimit   core.RateLimit   // from the most recent response carrying REST rate-limit headers
+	graphQLCost core.GraphQLCost // since the last takeGraphQLCost
+	pacer       *requestPacer    // shared by every request the client sends, and a multi-user daemon's other clients
+
+	topics    map[string]cachedTopics   // by repo full name; only used by classification
+	languages map[string]cachedLanguage // by repo full name; only used by classification
+	teams     map[string]cachedTeam     // by "org/slug"; only used by classification
+
+	teamScopeWarning sync.Once // see warnTeamScope
+
+	marked []core.Decision // mutes and archives made since the last takeMarked, for --octobox
+
+	dump io.Writer  // if set, each response is copied here, for mutemath why
+	raw  *rawDumper // if set, each response is written to a file, for --dump-raw
+
+	timeouts core.RequestTimeouts // how long each class of request may take
+
+	reauth *reauthPrompt  // if set, a 401 asks for a new token and resends
+	cache  *responseCache // if set, GETs are made conditional on earlier responses
+
+	ctx context.Context // bounds every request, for --max-runtime; nil for no bound
+}
+
+// errMaxRuntime is the error of every request made, or cut off, once the
+// --max-runtime deadline has passed.
+var errMaxRuntime = errors.New("run exceeded --max-runtime")
+
+// expired reports whether the client's --max-runtime deadline has passed.
+func (c *GitHubClient) expired() bool {
+	return c.ctx != nil && c.ctx.Err() != nil
+}
+
+// topicsTTL is how long repository topics are cached. They rarely change, so
+// a daemon only looks each repo up about once an hour.
+const topicsTTL = time.Hour
+
+type cachedTopics struct {
+	names   []string
+	fetched time.Time
+}
+
+type cachedLanguage struct {
+	name    string
+	fetched time.Time
+}
+
+// teamsTTL is how long team members are cached. Like topics, they rarely
+// change.
+const teamsTTL = time.Hour
+
+type cachedTeam struct {
+	members []string
+	fetched time.Time
 }
 
-func NewGitHubClient(token string) *GitHubClient {
+func NewGitHubClient(token, baseURL string) *GitHubClient {
 	return &GitHubClient{
 		token:      token,
+		baseURL:    baseURL,
 		httpClient: &http.Client{Timeout: 30 * time.Second},
+		userAgent:  core.DefaultUserAgent(buildInfo().Version),
+		apiVersion: core.DefaultAPIVersion,
+		pacer:      newRequestPacer(),
+		cache:      newResponseCache(),
+		timeouts:   core.DefaultRequestTimeouts,
+	}
+}
+
+// requestPacer is a request bucket clients can share. It schedules the
+// requests waiting for it: while the bucket is dry, each token goes to the
+// highest-priority class with a request waiting.
+type requestPacer struct {
+	mu       sync.Mutex
+	ready    *sync.Cond // signaled when a token is given out, so waiters check whose turn it is
+	bucket   core.TokenBucket
+	priority core.RequestPriority
+	waiting  core.RequestQueue
+}
+
+func newRequestPacer() *requestPacer {
+	p := &requestPacer{
+		bucket:   core.TokenBucket{Rate: core.DefaultRequestRate, Burst: core.DefaultRequestBurst},
+		priority: core.DefaultRequestPriority,
+	}
+	p.ready = sync.NewCond(&p.mu)
+	return p
+}
+
+// wait blocks until a request of class may be sent.
+func (p *requestPacer) wait(class core.RequestClass) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.waiting[class]++
+	defer func() { p.waiting[class]-- }()
+	for {
+		if next, _ := p.priority.Next(p.waiting); next != class {
+			p.ready.Wait()
+			continue
+		}
+		ok, wait := p.bucket.TryTake(time.Now())
+		if ok {
+			p.ready.Broadcast()
+			return
+		}
+		p.mu.Unlock()
+		time.Sleep(wait)
+		p.mu.Lock()
+	}
+}
+
+// name names the client's inbox in log lines and per-host files: the user in
+// a multi-user daemon, or else the host when processing several.
+func (c *GitHubClient) name() string {
+	if c.user != "" {
+		return c.user
+	}
+	return c.host
+}
+
+// rowPrefix starts the client's output rows: the user in a multi-user
+// daemon. Several hosts need none, as their labels name the host.
+func (c *GitHubClient) rowPrefix() string {
+	if c.user != "" {
+		return c.user + "  "
+	}
+	return ""
+}
+
+// store returns the store for the client's state.
+func (c *GitHubClient) store() (stateStore, error) {
+	if c.state != nil {
+		return c.state, nil
 	}
+	return currentState()
 }
 
 func (c *GitHubClient) setStandardHeaders(req *http.Request) {
 	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("Authorization", "Bearer "+c.token)
-	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if token := c.currentToken(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("X-GitHub-Api-Version", c.apiVersion)
 }
 
 // do executes an HTTP request with standard GitHub headers.
 // Retries once on 429 or 403 with Retry-After.
-func (c *GitHubClient) do(method, url string, body io.Reader) (*http.Response, error) {
+func (c *GitHubClient) do(method, url string, body io.Reader) (resp *http.Response, err error) {
+	span := c.tel.StartClient(method, url)
+	defer func() {
+		spanErr := err
+		if resp != nil {
+			span.SetAttr(intAttr("http.response.status_code", resp.StatusCode))
+			c.tel.Add("mutemath.api.requests", "http.response.status_code", strconv.Itoa(resp.StatusCode), 1)
+			if resp.StatusCode >= 400 {
+				spanErr = statusError(resp)
+			}
+		}
+		span.End(spanErr)
+	}()
+
 	for attempt := range 2 {
-		req, err := http.NewRequest(method, url, body)
+		if seeker, ok := body.(io.Seeker); ok && attempt > 0 {
+			seeker.Seek(0, io.SeekStart) // resend the whole body on retry
+		}
+		req, err := http.NewRequestWithContext(c.context(), method, url, body)
 		if err != nil {
 			return nil, err
 		}
 		c.setStandardHeaders(req)
 
-		resp, err := c.httpClient.Do(req)
+		resp, err := c.send(req)
 		if err != nil {
 			return nil, err
 		}
+		c.recordRateLimit(resp)
 
 		if attempt == 0 && isRateLimited(resp) {
 			wait := parseRetryAfter(resp)
 			resp.Body.Close()
-			time.Sleep(wait)
+			c.sleep(wait)
 			continue
 		}
 
+		if c.dump != nil {
+			c.dumpResponse(method, url, resp)
+		}
 		return resp, nil
 	}
 	// Unreachable, but the compiler needs it.
 	return nil, fmt.Errorf("exhausted retries")
 }
 
+// dumpResponse copies a response's status and body to c.dump, leaving the
+// body to be read again. JSON bodies are indented.
+func (c *GitHubClient) dumpResponse(method, url string, resp *http.Response) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	fmt.Fprintf(c.dump, "%s %s -> %d\n", method, url, resp.StatusCode)
+	if err != nil {
+		fmt.Fprintf(c.dump, "(reading body: %s)\n\n", err)
+		return
+	}
+	var indented bytes.Buffer
+	if json.Indent(&indented, body, "", "  ") == nil {
+		body = indented.Bytes()
+	}
+	fmt.Fprintf(c.dump, "%s\n\n", bytes.TrimSpace(body))
+}
+
+// context returns the context bounding the client's requests.
+func (c *GitHubClient) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// currentToken returns the token requests are sent with.
+func (c *GitHubClient) currentToken() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// setToken replaces the token for the requests sent from now on.
+func (c *GitHubClient) setToken(token string) {
+	c.mu.Lock()
+	c.token = token
+	c.mu.Unlock()
+}
+
+// send sends a request through the client's response cache. With a reauth
+// prompt, a 401 is sent again with the new token the user provides.
+func (c *GitHubClient) send(req *http.Request) (*http.Response, error) {
+	req, key, cached := c.cache.prepare(req)
+	for {
+		resp, err := c.transmit(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusUnauthorized && c.reauth != nil {
+			retry, err := c.reauthorized(req)
+			if err != nil || retry != nil {
+				resp.Body.Close()
+			}
+			if err != nil {
+				return nil, err
+			}
+			if retry != nil {
+				req = retry
+				continue
+			}
+		}
+		return c.cache.update(key, cached, resp), nil
+	}
+}
+
+// transmit paces and sends a request, within its class's timeout. Once the
+// --max-runtime deadline has passed, it fails with errMaxRuntime without
+// sending, and a request it cuts off fails with it too.
+func (c *GitHubClient) transmit(req *http.Request) (*http.Response, error) {
+	class := core.ClassifyRequest(req.Method, req.URL.Path)
+	c.pace(class)
+	ctx := c.context()
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	hc := *c.httpClient
+	hc.Timeout = c.timeouts[class]
+	resp, err := hc.Do(req)
+	if err != nil && ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	if err == nil {
+		c.raw.record(req, resp)
+	}
+	return resp, err
+}
+
+// sleep waits for d, or until the --max-runtime deadline.
+func (c *GitHubClient) sleep(d time.Duration) {
+	select {
+	case <-c.context().Done():
+	case <-time.After(d):
+	}
+}
+
+// pace waits for the client's request bucket, so concurrent workers together
+// stay under GitHub's secondary rate limits.
+func (c *GitHubClient) pace(class core.RequestClass) {
+	c.pacer.wait(class)
+}
+
+// recordRateLimit captures the rate-limit headers from resp, if present.
+func (c *GitHubClient) recordRateLimit(resp *http.Response) {
+	// GraphQL and search have budgets of their own; the REST cost estimate
+	// is about core.
+	if res := resp.Header.Get("X-RateLimit-Resource"); res != "" && res != "core" {
+		return
+	}
+	rl, ok := core.ParseRateLimit(
+		resp.Header.Get("X-RateLimit-Limit"),
+		resp.Header.Get("X-RateLimit-Remaining"),
+		resp.Header.Get("X-RateLimit-Reset"),
+	)
+	if ok {
+		c.mu.Lock()
+		c.rateLimit = rl
+		c.mu.Unlock()
+	}
+}
+
+// RateLimit returns the rate limit reported by the most recent response.
+func (c *GitHubClient) RateLimit() core.RateLimit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rateLimit
+}
+
 func isRateLimited(resp *http.Response) bool {
 	if resp.StatusCode == http.StatusTooManyRequests {
 		return true
@@ -109,27 +554,19 @@ func isRateLimited(resp *http.Response) bool {
 }
 
 func parseRetryAfter(resp *http.Response) time.Duration {
-	s := resp.Header.Get("Retry-After")
-	if s == "" {
-		return 60 * time.Second
-	}
-	secs, err := strconv.Atoi(s)
-	if err != nil {
-		return 60 * time.Second
-	}
-	return time.Duration(secs) * time.Second
+	return core.RetryAfter(resp.Header.Get("Retry-After"))
 }
 
 // FetchLogin calls GET /user and stores the authenticated user's login.
 func (c *GitHubClient) FetchLogin() error {
-	resp, err := c.do("GET", "https://api.github.com/user", nil)
+	resp, err := c.do("GET", c.baseURL+"/user", nil)
 	if err != nil {
 		return fmt.Errorf("fetch login: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("fetch login: unexpected status %d", resp.StatusCode)
+		return fmt.Errorf("fetch login: %w", statusError(resp))
 	}
 
 	var user ghAuthenticatedUser
@@ -140,27 +577,107 @@ func (c *GitHubClient) FetchLogin() error {
 	return nil
 }
 
+// ListOrgs lists the logins of the orgs the user belongs to. Orgs where
+// their membership is private need the read:org scope.
+func (c *GitHubClient) ListOrgs() ([]string, error) {
+	orgs, err := getAllPages[ghUser](c, c.baseURL+"/user/orgs?")
+	if err != nil {
+		return nil, fmt.Errorf("list orgs: %w", err)
+	}
+	logins := make([]string, len(orgs))
+	for i, o := range orgs {
+		logins[i] = o.Login
+	}
+	return logins, nil
+}
+
+// ListTeams lists the teams the user is on, across orgs. It needs the
+// read:org scope.
+func (c *GitHubClient) ListTeams() ([]core.InitTeam, error) {
+	ghTeams, err := getAllPages[ghUserTeam](c, c.baseURL+"/user/teams?")
+	if err != nil {
+		return nil, fmt.Errorf("list teams: %w", err)
+	}
+	teams := make([]core.InitTeam, len(ghTeams))
+	for i, t := range ghTeams {
+		teams[i] = core.InitTeam{Org: t.Organization.Login, Slug: t.Slug, Members: t.MembersCount}
+	}
+	return teams, nil
+}
+
+// Diagnosis holds the raw facts gathered for the doctor command.
+type Diagnosis struct {
+	ReachErr   error     // error requesting the API root, if any
+	UserStatus int       // status of GET /user, zero if the API was unreachable
+	Login      string    // authenticated login when UserStatus is 200
+	Scopes     string    // X-OAuth-Scopes header from GET /user
+	ServerDate time.Time // Date header from GET /user
+}
+
+// Diagnose probes the API root and GET /user without failing fast, so every
+// problem can be reported at once.
+func (c *GitHubClient) Diagnose() Diagnosis {
+	var d Diagnosis
+
+	resp, err := c.httpClient.Get(c.baseURL + "/")
+	if err != nil {
+		d.ReachErr = err
+		return d
+	}
+	resp.Body.Close()
+
+	resp, err = c.do("GET", c.baseURL+"/user", nil)
+	if err != nil {
+		d.ReachErr = err
+		return d
+	}
+	defer resp.Body.Close()
+
+	d.UserStatus = resp.StatusCode
+	d.Scopes = resp.Header.Get("X-OAuth-Scopes")
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		d.ServerDate = date
+	}
+	if resp.StatusCode == http.StatusOK {
+		var user ghAuthenticatedUser
+		if err := json.NewDecoder(resp.Body).Decode(&user); err == nil {
+			d.Login = user.Login
+		}
+	}
+	return d
+}
+
 // NotificationsResult holds the result of a ListUnreadNotifications call.
 type NotificationsResult struct {
-	Notifications []core.Notification
-	NotModified   bool
-	LastModified  string        // for conditional requests on the next poll
-	PollInterval  time.Duration // server-recommended poll interval
+	Count        int // notifications delivered to onPage
+	NotModified  bool
+	LastModified string        // for conditional requests on the next poll
+	PollInterval time.Duration // server-recommended poll interval
+	Date         time.Time     // server time of the first page, for since on the next poll
 }
 
-// ListUnreadNotifications fetches all unread notifications, handling pagination.
-// Stops when a page returns an empty array. Captures Last-Modified and X-Poll-Interval
-// from response headers and returns them in the result.
-// If lastModified is non-empty, sends If-Modified-Since on the first page.
+// ListUnreadNotifications fetches all unread notifications, handling pagination,
+// and passes each page to onPage as it arrives. Stops when a page returns an
+// empty array. Captures Last-Modified and X-Poll-Interval from response headers
+// and returns them in the result.
+// If the cursor has LastModified, sends If-Modified-Since on the first page;
+// otherwise, if it has Since, only lists threads updated since then. With
+// All, read threads are listed too.
 // Returns NotModified=true on 304 responses.
-func (c *GitHubClient) ListUnreadNotifications(lastModified string) (*NotificationsResult, error) {
-	var all []core.Notification
+func (c *GitHubClient) ListUnreadNotifications(cursor core.FetchCursor, onPage func([]core.Notification)) (*NotificationsResult, error) {
 	result := &NotificationsResult{}
+	lastModified := cursor.LastModified
 
 	for page := 1; ; page++ {
-		url := fmt.Sprintf("https://api.github.com/notifications?per_page=50&page=%d", page)
+		url := fmt.Sprintf("%s/notifications?per_page=50&page=%d", c.baseURL, page)
+		if lastModified == "" && !cursor.Since.IsZero() {
+			url += "&since=" + cursor.Since.UTC().Format(time.RFC3339)
+		}
+		if cursor.All {
+			url += "&all=true"
+		}
 
-		req, err := http.NewRequest("GET", url, nil)
+		req, err := http.NewRequestWithContext(c.context(), "GET", url, nil)
 		if err != nil {
 			return nil, fmt.Errorf("list notifications: %w", err)
 		}
@@ -170,28 +687,38 @@ func (c *GitHubClient) ListUnreadNotifications(lastModified string) (*Notificati
 			req.Header.Set("If-Modified-Since", lastModified)
 		}
 
-		resp, err := c.httpClient.Do(req)
+		span := c.tel.StartClient("GET", url)
+		resp, err := c.send(req)
 		if err != nil {
+			span.End(err)
 			return nil, fmt.Errorf("list notifications page %d: %w", page, err)
 		}
+		c.recordRateLimit(resp)
 
 		// Handle rate limiting inline for this special case
 		if isRateLimited(resp) {
 			wait := parseRetryAfter(resp)
 			resp.Body.Close()
-			time.Sleep(wait)
+			c.sleep(wait)
 
-			resp, err = c.httpClient.Do(req)
+			resp, err = c.send(req)
 			if err != nil {
+				span.End(err)
 				return nil, fmt.Errorf("list notifications page %d (retry): %w", page, err)
 			}
+			c.recordRateLimit(resp)
 		}
+		span.SetAttr(intAttr("http.response.status_code", resp.StatusCode))
+		c.tel.Add("mutemath.api.requests", "http.response.status_code", strconv.Itoa(resp.StatusCode), 1)
 
 		// Capture polling metadata from first page
 		if page == 1 {
 			if lm := resp.Header.Get("Last-Modified"); lm != "" {
 				result.LastModified = lm
 			}
+			if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+				result.Date = date
+			}
 			if pi := resp.Header.Get("X-Poll-Interval"); pi != "" {
 				if secs, err := strconv.Atoi(pi); err == nil {
 					result.PollInterval = time.Duration(secs) * time.Second
@@ -201,32 +728,41 @@ func (c *GitHubClient) ListUnreadNotifications(lastModified string) (*Notificati
 
 		if resp.StatusCode == http.StatusNotModified {
 			resp.Body.Close()
+			span.End(nil)
 			result.NotModified = true
 			return result, nil
 		}
 
 		if resp.StatusCode != http.StatusOK {
 			resp.Body.Close()
-			return nil, fmt.Errorf("list notifications page %d: unexpected status %d", page, resp.StatusCode)
+			err := fmt.Errorf("list notifications page %d: %w", page, statusError(resp))
+			span.End(err)
+			return nil, err
 		}
 
 		var ghNotifs []ghNotification
 		if err := json.NewDecoder(resp.Body).Decode(&ghNotifs); err != nil {
 			resp.Body.Close()
+			span.End(err)
 			return nil, fmt.Errorf("list notifications page %d: %w", page, err)
 		}
 		resp.Body.Close()
+		span.End(nil)
 
 		if len(ghNotifs) == 0 {
 			break
 		}
 
+		notifications := make([]core.Notification, 0, len(ghNotifs))
 		for _, gn := range ghNotifs {
-			all = append(all, toNotification(gn))
+			n := toNotification(gn)
+			n.Host = c.host
+			notifications = append(notifications, n)
 		}
+		result.Count += len(notifications)
+		onPage(notifications)
 	}
 
-	result.Notifications = all
 	return result, nil
 }
 
@@ -237,7 +773,7 @@ func (c *GitHubClient) GetRequestedReviewers(subjectURL string) (*core.Reviewers
 		return nil, fmt.Errorf("get reviewers: %w", err)
 	}
 
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/pulls/%d/requested_reviewers", ref.Owner, ref.Repo, ref.Number)
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/requested_reviewers", c.baseURL, ref.Owner, ref.Repo, ref.Number)
 	resp, err := c.do("GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("get reviewers for %s/%s#%d: %w", ref.Owner, ref.Repo, ref.Number, err)
@@ -245,7 +781,7 @@ func (c *GitHubClient) GetRequestedReviewers(subjectURL string) (*core.Reviewers
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("get reviewers for %s/%s#%d: unexpected status %d", ref.Owner, ref.Repo, ref.Number, resp.StatusCode)
+		return nil, fmt.Errorf("get reviewers for %s/%s#%d: %w", ref.Owner, ref.Repo, ref.Number, statusError(resp))
 	}
 
 	var ghReviewers ghReviewersResponse
@@ -256,79 +792,928 @@ func (c *GitHubClient) GetRequestedReviewers(subjectURL string) (*core.Reviewers
 	return toReviewers(ghReviewers), nil
 }
 
-// MarkThreadRead marks a notification thread as read.
-func (c *GitHubClient) MarkThreadRead(threadID string) error {
-	url := fmt.Sprintf("https://api.github.com/notifications/threads/%s", threadID)
-	resp, err := c.do("PATCH", url, nil)
+// RequestReview requests a user's review on a PR given its API subject URL.
+func (c *GitHubClient) RequestReview(subjectURL, login string) error {
+	ref, err := core.ParseSubjectURL(subjectURL)
 	if err != nil {
-		return fmt.Errorf("mark thread %s read: %w", threadID, err)
+		return fmt.Errorf("request review: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/requested_reviewers", c.baseURL, ref.Owner, ref.Repo, ref.Number)
+	body, err := json.Marshal(map[string][]string{"reviewers": {login}})
+	if err != nil {
+		return fmt.Errorf("request review: %w", err)
+	}
+	resp, err := c.do("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("request review on %s/%s#%d: %w", ref.Owner, ref.Repo, ref.Number, err)
 	}
 	defer resp.Body.Close()
 
-	// 205 Reset Content is the expected success response.
-	if resp.StatusCode != http.StatusResetContent && resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("mark thread %s read: unexpected status %d", threadID, resp.StatusCode)
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request review on %s/%s#%d: %w", ref.Owner, ref.Repo, ref.Number, statusError(resp))
 	}
 	return nil
 }
 
-// MarkThreadDone marks a notification thread as done, removing it from the inbox.
-func (c *GitHubClient) MarkThreadDone(threadID string) error {
-	url := fmt.Sprintf("https://api.github.com/notifications/threads/%s", threadID)
-	resp, err := c.do("DELETE", url, nil)
+// RemoveReviewRequest removes a user's review request from a PR given its API
+// subject URL.
+func (c *GitHubClient) RemoveReviewRequest(subjectURL, login string) error {
+	ref, err := core.ParseSubjectURL(subjectURL)
 	if err != nil {
-		return fmt.Errorf("mark thread %s done: %w", threadID, err)
+		return fmt.Errorf("remove review request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/requested_reviewers", c.baseURL, ref.Owner, ref.Repo, ref.Number)
+	body, err := json.Marshal(map[string][]string{"reviewers": {login}})
+	if err != nil {
+		return fmt.Errorf("remove review request: %w", err)
+	}
+	resp, err := c.do("DELETE", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("remove review request from %s/%s#%d: %w", ref.Owner, ref.Repo, ref.Number, err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("mark thread %s done: unexpected status %d", threadID, resp.StatusCode)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("remove review request from %s/%s#%d: %w", ref.Owner, ref.Repo, ref.Number, statusError(resp))
 	}
 	return nil
 }
 
-// IgnoreThread mutes/ignores a notification thread.
-func (c *GitHubClient) IgnoreThread(threadID string) error {
-	url := fmt.Sprintf("https://api.github.com/notifications/threads/%s/subscription", threadID)
-	body := strings.NewReader(`{"ignored":true}`)
-	resp, err := c.do("PUT", url, body)
+// GetPullRequest fetches PR details given its API subject URL.
+func (c *GitHubClient) GetPullRequest(subjectURL string) (*core.PullRequest, error) {
+	ref, err := core.ParseSubjectURL(subjectURL)
 	if err != nil {
-		return fmt.Errorf("ignore thread %s: %w", threadID, err)
+		return nil, fmt.Errorf("get pull request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d", c.baseURL, ref.Owner, ref.Repo, ref.Number)
+	resp, err := c.do("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get pull request %s/%s#%d: %w", ref.Owner, ref.Repo, ref.Number, err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("ignore thread %s: unexpected status %d", threadID, resp.StatusCode)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get pull request %s/%s#%d: %w", ref.Owner, ref.Repo, ref.Number, statusError(resp))
 	}
-	return nil
+
+	var gp ghPullRequest
+	if err := json.NewDecoder(resp.Body).Decode(&gp); err != nil {
+		return nil, fmt.Errorf("get pull request %s/%s#%d: %w", ref.Owner, ref.Repo, ref.Number, err)
+	}
+	return toPullRequest(gp), nil
 }
 
-// Conversion functions: GitHub JSON types → core types.
+// listPerPage is the page size for paginated list endpoints.
+const listPerPage = 100
 
-func toNotification(gn ghNotification) core.Notification {
-	return core.Notification{
-		ID:     gn.ID,
-		Reason: gn.Reason,
-		Subject: core.Subject{
-			Title: gn.Subject.Title,
-			URL:   gn.Subject.URL,
-			Type:  gn.Subject.Type,
-		},
-		Repository: core.Repository{
-			FullName: gn.Repository.FullName,
-			Owner:    gn.Repository.Owner.Login,
-		},
+// getAllPages fetches every page of a REST list endpoint. url must already
+// have a query string; the page parameters are appended.
+func getAllPages[T any](c *GitHubClient, url string) ([]T, error) {
+	var all []T
+	for page := 1; ; page++ {
+		resp, err := c.do("GET", fmt.Sprintf("%s&per_page=%d&page=%d", url, listPerPage, page), nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, statusError(resp)
+		}
+		var items []T
+		err = json.NewDecoder(resp.Body).Decode(&items)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) < listPerPage {
+			return all, nil
+		}
 	}
 }
 
-func toReviewers(gr ghReviewersResponse) *core.Reviewers {
-	users := make([]string, len(gr.Users))
-	for i, u := range gr.Users {
-		users[i] = u.Login
+// GetPullRequestFiles fetches the paths a PR changes given its API subject
+// URL. Renamed files are listed under their new path. The API returns at most
+// 3000 files.
+func (c *GitHubClient) GetPullRequestFiles(subjectURL string) ([]string, error) {
+	ref, err := core.ParseSubjectURL(subjectURL)
+	if err != nil {
+		return nil, fmt.Errorf("get pull request files: %w", err)
 	}
-	teams := make([]string, len(gr.Teams))
-	for i, t := range gr.Teams {
-		teams[i] = t.Slug
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/files?", c.baseURL, ref.Owner, ref.Repo, ref.Number)
+	ghFiles, err := getAllPages[ghPullRequestFile](c, url)
+	if err != nil {
+		return nil, fmt.Errorf("get files for %s/%s#%d: %w", ref.Owner, ref.Repo, ref.Number, err)
 	}
-	return &core.Reviewers{Users: users, Teams: teams}
+	files := make([]string, len(ghFiles))
+	for i, f := range ghFiles {
+		files[i] = f.Filename
+	}
+	return files, nil
+}
+
+// GetRepoTopics fetches a repository's ("owner/repo") topics, caching them
+// for topicsTTL. A repo without topics yields an empty, non-nil list.
+func (c *GitHubClient) GetRepoTopics(repo string) ([]string, error) {
+	if cached, ok := c.topics[repo]; ok && time.Since(cached.fetched) < topicsTTL {
+		return cached.names, nil
+	}
+
+	resp, err := c.do("GET", fmt.Sprintf("%s/repos/%s/topics", c.baseURL, repo), nil)
+	if err != nil {
+		return nil, fmt.Errorf("get topics for %s: %w", repo, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get topics for %s: %w", repo, statusError(resp))
+	}
+	var gt ghTopics
+	if err := json.NewDecoder(resp.Body).Decode(&gt); err != nil {
+		return nil, fmt.Errorf("get topics for %s: %w", repo, err)
+	}
+	names := gt.Names
+	if names == nil {
+		names = []string{}
+	}
+	if c.topics == nil {
+		c.topics = make(map[string]cachedTopics)
+	}
+	c.topics[repo] = cachedTopics{names: names, fetched: time.Now()}
+	return names, nil
+}
+
+// GetRepoLanguage fetches a repository's ("owner/repo") primary language,
+// caching it for topicsTTL like topics. A repo GitHub detects no language in
+// yields "".
+func (c *GitHubClient) GetRepoLanguage(repo string) (string, error) {
+	if cached, ok := c.languages[repo]; ok && time.Since(cached.fetched) < topicsTTL {
+		return cached.name, nil
+	}
+
+	resp, err := c.do("GET", fmt.Sprintf("%s/repos/%s", c.baseURL, repo), nil)
+	if err != nil {
+		return "", fmt.Errorf("get language for %s: %w", repo, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("get language for %s: %w", repo, statusError(resp))
+	}
+	var gr ghRepository
+	if err := json.NewDecoder(resp.Body).Decode(&gr); err != nil {
+		return "", fmt.Errorf("get language for %s: %w", repo, err)
+	}
+	if c.languages == nil {
+		c.languages = make(map[string]cachedLanguage)
+	}
+	c.languages[repo] = cachedLanguage{name: gr.Language, fetched: time.Now()}
+	return gr.Language, nil
+}
+
+// GetReviewAuthors fetches the logins of everyone who has submitted a review
+// on a PR, including comment-only reviews, given its API subject URL.
+func (c *GitHubClient) GetReviewAuthors(subjectURL string) ([]string, error) {
+	ref, err := core.ParseSubjectURL(subjectURL)
+	if err != nil {
+		return nil, fmt.Errorf("get reviews: %w", err)
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/reviews?", c.baseURL, ref.Owner, ref.Repo, ref.Number)
+	reviews, err := getAllPages[ghReview](c, url)
+	if err != nil {
+		return nil, fmt.Errorf("get reviews for %s/%s#%d: %w", ref.Owner, ref.Repo, ref.Number, err)
+	}
+	var authors []string
+	for _, r := range reviews {
+		if !slices.Contains(authors, r.User.Login) {
+			authors = append(authors, r.User.Login)
+		}
+	}
+	return authors, nil
+}
+
+// GetCommentAuthors fetches the logins of everyone who has commented on a
+// PR's conversation, given its API subject URL. Review comments belong to
+// reviews, so GetReviewAuthors covers those.
+func (c *GitHubClient) GetCommentAuthors(subjectURL string) ([]string, error) {
+	ref, err := core.ParseSubjectURL(subjectURL)
+	if err != nil {
+		return nil, fmt.Errorf("get comments: %w", err)
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/comments?", c.baseURL, ref.Owner, ref.Repo, ref.Number)
+	comments, err := getAllPages[ghComment](c, url)
+	if err != nil {
+		return nil, fmt.Errorf("get comments for %s/%s#%d: %w", ref.Owner, ref.Repo, ref.Number, err)
+	}
+	authors := []string{}
+	for _, cm := range comments {
+		if !slices.Contains(authors, cm.User.Login) {
+			authors = append(authors, cm.User.Login)
+		}
+	}
+	return authors, nil
+}
+
+// searchPRs runs an issue search and returns the API URLs of the pull
+// requests found. The search API returns at most 1000 results.
+func (c *GitHubClient) searchPRs(q string) ([]string, error) {
+	var prs []string
+	for page := 1; ; page++ {
+		u := fmt.Sprintf("%s/search/issues?q=%s&per_page=%d&page=%d", c.baseURL, url.QueryEscape(q), listPerPage, page)
+		resp, err := c.do("GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, statusError(resp)
+		}
+		var result ghIssueSearch
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range result.Items {
+			if item.PullRequest.URL != "" {
+				prs = append(prs, item.PullRequest.URL)
+			}
+		}
+		if len(result.Items) < listPerPage || page*listPerPage >= 1000 {
+			return prs, nil
+		}
+	}
+}
+
+// SearchPersonalReviewRequests returns the open pull requests that request
+// login's review personally, not just through a team, as API URLs.
+func (c *GitHubClient) SearchPersonalReviewRequests() ([]string, error) {
+	prs, err := c.searchPRs("is:pr is:open user-review-requested:@me")
+	if err != nil {
+		return nil, fmt.Errorf("search review requests: %w", err)
+	}
+	return prs, nil
+}
+
+// GetCompletedReviews fetches the reviews login has submitted since the
+// given time, finding the PRs to look at with the search API, which returns
+// at most 1000 of them.
+func (c *GitHubClient) GetCompletedReviews(since time.Time) ([]core.CompletedReview, error) {
+	q := fmt.Sprintf("type:pr reviewed-by:%s updated:>=%s", c.login, since.UTC().Format(time.DateOnly))
+	prs, err := c.searchPRs(q)
+	if err != nil {
+		return nil, fmt.Errorf("search reviewed PRs: %w", err)
+	}
+
+	var reviews []core.CompletedReview
+	for _, subjectURL := range prs {
+		ref, err := core.ParseSubjectURL(subjectURL)
+		if err != nil {
+			return nil, fmt.Errorf("get reviews: %w", err)
+		}
+		url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/reviews?", c.baseURL, ref.Owner, ref.Repo, ref.Number)
+		rs, err := getAllPages[ghReview](c, url)
+		if err != nil {
+			return nil, fmt.Errorf("get reviews for %s/%s#%d: %w", ref.Owner, ref.Repo, ref.Number, err)
+		}
+		for _, r := range rs {
+			// Pending reviews have no submission time.
+			if strings.EqualFold(r.User.Login, c.login) && !r.SubmittedAt.IsZero() && !r.SubmittedAt.Before(since) {
+				reviews = append(reviews, core.CompletedReview{
+					PR:        fmt.Sprintf("%s/%s#%d", ref.Owner, ref.Repo, ref.Number),
+					Submitted: r.SubmittedAt,
+				})
+			}
+		}
+	}
+	return reviews, nil
+}
+
+// warnTeamScope logs, once per client, that team members can't be listed,
+// usually for want of the read:org scope. Requests that need them are left
+// unread, so without the warning --team-size-threshold would silently do
+// nothing.
+func (c *GitHubClient) warnTeamScope(err error) {
+	c.teamScopeWarning.Do(func() {
+		log.Printf("warning: %s; team members can't be listed (does the token have read:org?), so review requests that need them are skipped", err)
+	})
+}
+
+// GetTeamMembers fetches the logins of a team's members, caching them for
+// teamsTTL. Listing members of a team you don't belong to needs the read:org
+// scope.
+func (c *GitHubClient) GetTeamMembers(org, slug string) ([]string, error) {
+	key := org + "/" + slug
+	if cached, ok := c.teams[key]; ok && time.Since(cached.fetched) < teamsTTL {
+		return cached.members, nil
+	}
+
+	url := fmt.Sprintf("%s/orgs/%s/teams/%s/members?", c.baseURL, org, slug)
+	users, err := getAllPages[ghUser](c, url)
+	if err != nil {
+		return nil, fmt.Errorf("get members of %s/%s: %w", org, slug, err)
+	}
+	members := make([]string, len(users))
+	for i, u := range users {
+		members[i] = u.Login
+	}
+	if c.teams == nil {
+		c.teams = make(map[string]cachedTeam)
+	}
+	c.teams[key] = cachedTeam{members: members, fetched: time.Now()}
+	return members, nil
+}
+
+// graphQL runs a GraphQL query and decodes its data into out. A response
+// carrying errors fails with the first error's message.
+func (c *GitHubClient) graphQL(query string, variables map[string]any, out any) error {
+	payload, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	resp, err := c.do("POST", core.GraphQLURL(c.baseURL), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+	var gr ghGraphQLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&gr); err != nil {
+		return err
+	}
+	c.recordGraphQLCost(gr.Data)
+	if len(gr.Errors) > 0 {
+		return errors.New(gr.Errors[0].Message)
+	}
+	return json.Unmarshal(gr.Data, out)
+}
+
+// recordGraphQLCost adds a query's cost to the client's total. A query that
+// didn't ask for rateLimit counts with no cost.
+func (c *GitHubClient) recordGraphQLCost(data json.RawMessage) {
+	var rl ghGraphQLRateLimit
+	if len(data) > 0 {
+		json.Unmarshal(data, &rl)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if r := rl.RateLimit; r != nil {
+		c.graphQLCost.Add(r.Cost, r.Limit, r.Remaining, r.ResetAt)
+	} else {
+		c.graphQLCost.Add(0, 0, 0, time.Time{})
+	}
+}
+
+// takeGraphQLCost returns the GraphQL cost since it was last taken, for a
+// run's or cycle's output.
+func (c *GitHubClient) takeGraphQLCost() core.GraphQLCost {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	g := c.graphQLCost
+	c.graphQLCost = core.GraphQLCost{}
+	return g
+}
+
+// takeMarked returns the mutes and archives made since it was last taken, for
+// mirroring into Octobox.
+func (c *GitHubClient) takeMarked() []core.Decision {
+	m := c.marked
+	c.marked = nil
+	return m
+}
+
+const reviewDecisionQuery = `query($owner: String!, $repo: String!, $number: Int!) {
+  repository(owner: $owner, name: $repo) { pullRequest(number: $number) { reviewDecision } }
+  rateLimit { cost limit remaining resetAt }
+}`
+
+// GetReviewDecision fetches a PR's review decision given its API subject URL.
+// It's only available through GraphQL, and is empty when the base branch
+// doesn't require reviews.
+func (c *GitHubClient) GetReviewDecision(subjectURL string) (string, error) {
+	ref, err := core.ParseSubjectURL(subjectURL)
+	if err != nil {
+		return "", fmt.Errorf("get review decision: %w", err)
+	}
+	var data ghReviewDecisionData
+	vars := map[string]any{"owner": ref.Owner, "repo": ref.Repo, "number": ref.Number}
+	if err := c.graphQL(reviewDecisionQuery, vars, &data); err != nil {
+		return "", fmt.Errorf("get review decision for %s/%s#%d: %w", ref.Owner, ref.Repo, ref.Number, err)
+	}
+	return data.Repository.PullRequest.ReviewDecision, nil
+}
+
+const reviewRequestsQuery = `query($owner: String!, $repo: String!, $number: Int!) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      reviewRequests(first: 100) {
+        nodes { asCodeOwner requestedReviewer { ... on User { login } ... on Team { slug } } }
+      }
+    }
+  }
+  rateLimit { cost limit remaining resetAt }
+}`
+
+// GetReviewRequests fetches a PR's pending review requests, with whether
+// each came from CODEOWNERS, given its API subject URL. Only GraphQL has
+// that; the REST timeline attributes CODEOWNERS requests to the PR's author.
+func (c *GitHubClient) GetReviewRequests(subjectURL string) ([]core.ReviewRequest, error) {
+	ref, err := core.ParseSubjectURL(subjectURL)
+	if err != nil {
+		return nil, fmt.Errorf("get review requests: %w", err)
+	}
+	var data ghReviewRequestsData
+	vars := map[string]any{"owner": ref.Owner, "repo": ref.Repo, "number": ref.Number}
+	if err := c.graphQL(reviewRequestsQuery, vars, &data); err != nil {
+		return nil, fmt.Errorf("get review requests for %s/%s#%d: %w", ref.Owner, ref.Repo, ref.Number, err)
+	}
+	requests := []core.ReviewRequest{}
+	for _, n := range data.Repository.PullRequest.ReviewRequests.Nodes {
+		r := n.RequestedReviewer
+		switch {
+		case r.Slug != "":
+			requests = append(requests, core.ReviewRequest{Reviewer: r.Slug, Team: true, CodeOwner: n.AsCodeOwner})
+		case r.Login != "":
+			requests = append(requests, core.ReviewRequest{Reviewer: r.Login, CodeOwner: n.AsCodeOwner})
+		}
+	}
+	return requests, nil
+}
+
+// FetchRepoFile fetches the raw contents of a file in a repository ("owner/repo")
+// at ref (empty for the default branch). If etag matches, notModified is set
+// and body is nil.
+func (c *GitHubClient) FetchRepoFile(repo, path, ref, etag string) (body []byte, newETag string, notModified bool, err error) {
+	u := fmt.Sprintf("%s/repos/%s/contents/%s", c.baseURL, repo, strings.TrimPrefix(path, "/"))
+	if ref != "" {
+		u += "?ref=" + url.QueryEscape(ref)
+	}
+	req, err := http.NewRequestWithContext(c.context(), "GET", u, nil)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("fetch %s/%s: %w", repo, path, err)
+	}
+	c.setStandardHeaders(req)
+	req.Header.Set("Accept", "application/vnd.github.raw+json")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("fetch %s/%s: %w", repo, path, err)
+	}
+	defer resp.Body.Close()
+	c.recordRateLimit(resp)
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil, etag, true, nil
+	case http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, "", false, fmt.Errorf("fetch %s/%s: %w", repo, path, err)
+		}
+		return body, resp.Header.Get("ETag"), false, nil
+	default:
+		return nil, "", false, fmt.Errorf("fetch %s/%s: %w", repo, path, statusError(resp))
+	}
+}
+
+// MarkThreadRead marks a notification thread as read.
+func (c *GitHubClient) MarkThreadRead(threadID string) error {
+	url := fmt.Sprintf("%s/notifications/threads/%s", c.baseURL, threadID)
+	resp, err := c.do("PATCH", url, nil)
+	if err != nil {
+		return fmt.Errorf("mark thread %s read: %w", threadID, err)
+	}
+	defer resp.Body.Close()
+
+	// 205 Reset Content is the expected success response.
+	if resp.StatusCode != http.StatusResetContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("mark thread %s read: %w", threadID, statusError(resp))
+	}
+	return nil
+}
+
+// MarkThreadDone marks a notification thread as done, removing it from the inbox.
+func (c *GitHubClient) MarkThreadDone(threadID string) error {
+	url := fmt.Sprintf("%s/notifications/threads/%s", c.baseURL, threadID)
+	resp, err := c.do("DELETE", url, nil)
+	if err != nil {
+		return fmt.Errorf("mark thread %s done: %w", threadID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("mark thread %s done: %w", threadID, statusError(resp))
+	}
+	return nil
+}
+
+// IgnoreThread mutes/ignores a notification thread.
+// GetThread fetches a single notification thread by ID, read or not.
+func (c *GitHubClient) GetThread(threadID string) (core.Notification, error) {
+	url := fmt.Sprintf("%s/notifications/threads/%s", c.baseURL, threadID)
+	resp, err := c.do("GET", url, nil)
+	if err != nil {
+		return core.Notification{}, fmt.Errorf("get thread %s: %w", threadID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return core.Notification{}, fmt.Errorf("get thread %s: %w", threadID, statusError(resp))
+	}
+	var gn ghNotification
+	if err := json.NewDecoder(resp.Body).Decode(&gn); err != nil {
+		return core.Notification{}, fmt.Errorf("get thread %s: %w", threadID, err)
+	}
+	n := toNotification(gn)
+	n.Host = c.host
+	return n, nil
+}
+
+// ThreadUnread reports whether a notification thread is still unread.
+func (c *GitHubClient) ThreadUnread(threadID string) (bool, error) {
+	url := fmt.Sprintf("%s/notifications/threads/%s", c.baseURL, threadID)
+	resp, err := c.do("GET", url, nil)
+	if err != nil {
+		return false, fmt.Errorf("get thread %s: %w", threadID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("get thread %s: %w", threadID, statusError(resp))
+	}
+	var thread ghThread
+	if err := json.NewDecoder(resp.Body).Decode(&thread); err != nil {
+		return false, fmt.Errorf("get thread %s: %w", threadID, err)
+	}
+	return thread.Unread, nil
+}
+
+// ThreadIgnored reports whether the thread's subscription is already ignored.
+// A thread with no subscription (404) isn't.
+func (c *GitHubClient) ThreadIgnored(threadID string) (bool, error) {
+	url := fmt.Sprintf("%s/notifications/threads/%s/subscription", c.baseURL, threadID)
+	resp, err := c.do("GET", url, nil)
+	if err != nil {
+		return false, fmt.Errorf("get thread subscription %s: %w", threadID, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var sub ghThreadSubscription
+		if err := json.NewDecoder(resp.Body).Decode(&sub); err != nil {
+			return false, fmt.Errorf("get thread subscription %s: %w", threadID, err)
+		}
+		return sub.Ignored, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("get thread subscription %s: %w", threadID, statusError(resp))
+	}
+}
+
+// GetThreadSubscription fetches a thread's subscription. A thread with no
+// subscription of its own (404) returns one that isn't Found.
+func (c *GitHubClient) GetThreadSubscription(threadID string) (core.Subscription, error) {
+	url := fmt.Sprintf("%s/notifications/threads/%s/subscription", c.baseURL, threadID)
+	resp, err := c.do("GET", url, nil)
+	if err != nil {
+		return core.Subscription{}, fmt.Errorf("get thread subscription %s: %w", threadID, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var sub ghThreadSubscription
+		if err := json.NewDecoder(resp.Body).Decode(&sub); err != nil {
+			return core.Subscription{}, fmt.Errorf("get thread subscription %s: %w", threadID, err)
+		}
+		return core.Subscription{
+			Found:      true,
+			Subscribed: sub.Subscribed,
+			Ignored:    sub.Ignored,
+			Reason:     sub.Reason,
+			CreatedAt:  sub.CreatedAt,
+		}, nil
+	case http.StatusNotFound:
+		return core.Subscription{}, nil
+	default:
+		return core.Subscription{}, fmt.Errorf("get thread subscription %s: %w", threadID, statusError(resp))
+	}
+}
+
+// prThreadPages bounds how far FindPRThread pages through a repo's
+// notifications, read or not, before giving up.
+const prThreadPages = 10
+
+// FindPRThread finds the notification thread about a pull request, read or
+// not, among its repo's notifications.
+func (c *GitHubClient) FindPRThread(pr core.PRRef) (core.Notification, error) {
+	for page := 1; page <= prThreadPages; page++ {
+		url := fmt.Sprintf("%s/repos/%s/%s/notifications?all=true&per_page=50&page=%d", c.baseURL, pr.Owner, pr.Repo, page)
+		resp, err := c.do("GET", url, nil)
+		if err != nil {
+			return core.Notification{}, fmt.Errorf("list %s/%s notifications: %w", pr.Owner, pr.Repo, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return core.Notification{}, fmt.Errorf("list %s/%s notifications: %w", pr.Owner, pr.Repo, statusError(resp))
+		}
+		var ghNotifs []ghNotification
+		err = json.NewDecoder(resp.Body).Decode(&ghNotifs)
+		resp.Body.Close()
+		if err != nil {
+			return core.Notification{}, fmt.Errorf("list %s/%s notifications: %w", pr.Owner, pr.Repo, err)
+		}
+		if len(ghNotifs) == 0 {
+			break
+		}
+		ns := make([]core.Notification, len(ghNotifs))
+		for i, gn := range ghNotifs {
+			ns[i] = toNotification(gn)
+			ns[i].Host = c.host
+		}
+		if n, ok := core.FindPRThread(ns, pr); ok {
+			return n, nil
+		}
+	}
+	return core.Notification{}, fmt.Errorf("no notification thread for %s/%s#%d; you get a thread once GitHub notifies you about it", pr.Owner, pr.Repo, pr.Number)
+}
+
+func (c *GitHubClient) IgnoreThread(threadID string) error {
+	url := fmt.Sprintf("%s/notifications/threads/%s/subscription", c.baseURL, threadID)
+	body := strings.NewReader(`{"ignored":true}`)
+	resp, err := c.do("PUT", url, body)
+	if err != nil {
+		return fmt.Errorf("ignore thread %s: %w", threadID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("ignore thread %s: %w", threadID, statusError(resp))
+	}
+	return nil
+}
+
+// UnignoreThread undoes IgnoreThread, subscribing to the thread again.
+func (c *GitHubClient) UnignoreThread(threadID string) error {
+	url := fmt.Sprintf("%s/notifications/threads/%s/subscription", c.baseURL, threadID)
+	body := strings.NewReader(`{"ignored":false}`)
+	resp, err := c.do("PUT", url, body)
+	if err != nil {
+		return fmt.Errorf("unignore thread %s: %w", threadID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("unignore thread %s: %w", threadID, statusError(resp))
+	}
+	return nil
+}
+
+// ListWatchedRepos lists the repositories the user watches, with how: the
+// list doesn't say, so each repo's subscription is fetched too.
+func (c *GitHubClient) ListWatchedRepos() ([]core.RepoSubscription, error) {
+	repos, err := c.ListWatchedRepoNames()
+	if err != nil {
+		return nil, err
+	}
+	subs := make([]core.RepoSubscription, 0, len(repos))
+	for _, repo := range repos {
+		sub, found, err := c.GetRepoSubscription(repo)
+		if err != nil {
+			return nil, err
+		}
+		// Not found: unwatched since it was listed.
+		if found {
+			subs = append(subs, sub)
+		}
+	}
+	return subs, nil
+}
+
+// ListWatchedRepoNames lists the repositories the user watches, as
+// "org/repo".
+func (c *GitHubClient) ListWatchedRepoNames() ([]string, error) {
+	repos, err := getAllPages[ghRepository](c, c.baseURL+"/user/subscriptions?")
+	if err != nil {
+		return nil, fmt.Errorf("list watched repos: %w", err)
+	}
+	names := make([]string, len(repos))
+	for i, r := range repos {
+		names[i] = r.FullName
+	}
+	return names, nil
+}
+
+// GetRepoSubscription fetches the user's subscription to a repository
+// ("org/repo"). found is false if they don't watch it.
+func (c *GitHubClient) GetRepoSubscription(repo string) (sub core.RepoSubscription, found bool, err error) {
+	resp, err := c.do("GET", fmt.Sprintf("%s/repos/%s/subscription", c.baseURL, repo), nil)
+	if err != nil {
+		return core.RepoSubscription{}, false, fmt.Errorf("get %s subscription: %w", repo, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var gs ghThreadSubscription
+		if err := json.NewDecoder(resp.Body).Decode(&gs); err != nil {
+			return core.RepoSubscription{}, false, fmt.Errorf("get %s subscription: %w", repo, err)
+		}
+		return core.RepoSubscription{Repo: repo, Subscribed: gs.Subscribed, Ignored: gs.Ignored}, true, nil
+	case http.StatusNotFound:
+		return core.RepoSubscription{}, false, nil
+	default:
+		return core.RepoSubscription{}, false, fmt.Errorf("get %s subscription: %w", repo, statusError(resp))
+	}
+}
+
+// UnwatchRepo deletes the user's subscription to a repository ("org/repo"),
+// leaving notifications only for threads they participate in or are
+// @mentioned in.
+func (c *GitHubClient) UnwatchRepo(repo string) error {
+	resp, err := c.do("DELETE", fmt.Sprintf("%s/repos/%s/subscription", c.baseURL, repo), nil)
+	if err != nil {
+		return fmt.Errorf("unwatch %s: %w", repo, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("unwatch %s: %w", repo, statusError(resp))
+	}
+	return nil
+}
+
+// IgnoreRepo ignores a repository ("org/repo"): no notifications from it at
+// all, even for threads the user participates in.
+func (c *GitHubClient) IgnoreRepo(repo string) error {
+	body := strings.NewReader(`{"ignored":true}`)
+	resp, err := c.do("PUT", fmt.Sprintf("%s/repos/%s/subscription", c.baseURL, repo), body)
+	if err != nil {
+		return fmt.Errorf("ignore %s: %w", repo, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ignore %s: %w", repo, statusError(resp))
+	}
+	return nil
+}
+
+// GetRateLimits fetches the token's usage of every rate-limit resource. This
+// call doesn't count against the limits. A server with rate limiting disabled
+// answers 404, which returns no resources.
+func (c *GitHubClient) GetRateLimits() ([]core.RateLimitResource, error) {
+	resp, err := c.do("GET", c.baseURL+"/rate_limit", nil)
+	if err != nil {
+		return nil, fmt.Errorf("get rate limit: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get rate limit: %w", statusError(resp))
+	}
+	var limits ghRateLimits
+	if err := json.NewDecoder(resp.Body).Decode(&limits); err != nil {
+		return nil, fmt.Errorf("get rate limit: %w", err)
+	}
+	var resources []core.RateLimitResource
+	for name, r := range limits.Resources {
+		resources = append(resources, core.RateLimitResource{
+			Name:      name,
+			Limit:     r.Limit,
+			Used:      r.Used,
+			Remaining: r.Remaining,
+			Reset:     time.Unix(r.Reset, 0).UTC(),
+		})
+	}
+	return resources, nil
+}
+
+// LatestRelease fetches the latest published release of a repository ("owner/repo").
+func (c *GitHubClient) LatestRelease(repo string) (core.Release, error) {
+	resp, err := c.do("GET", fmt.Sprintf("%s/repos/%s/releases/latest", c.baseURL, repo), nil)
+	if err != nil {
+		return core.Release{}, fmt.Errorf("latest release of %s: %w", repo, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return core.Release{}, fmt.Errorf("latest release of %s: %w", repo, statusError(resp))
+	}
+
+	var gr ghRelease
+	if err := json.NewDecoder(resp.Body).Decode(&gr); err != nil {
+		return core.Release{}, fmt.Errorf("latest release of %s: %w", repo, err)
+	}
+	return toRelease(gr), nil
+}
+
+// DownloadAsset streams a release asset into w. Asset URLs redirect to a CDN,
+// so no GitHub headers are sent.
+func (c *GitHubClient) DownloadAsset(url string, w io.Writer) error {
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return fmt.Errorf("download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download %s: %w", url, statusError(resp))
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("download %s: %w", url, err)
+	}
+	return nil
+}
+
+// Conversion functions: GitHub JSON types → core types.
+
+// decodeNotifications reads notifications in the API's JSON format: an array,
+// or several in a row as gh api --paginate prints them.
+func decodeNotifications(r io.Reader) ([]core.Notification, error) {
+	dec := json.NewDecoder(r)
+	var notifications []core.Notification
+	for {
+		var page []ghNotification
+		err := dec.Decode(&page)
+		if err == io.EOF {
+			return notifications, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decode notifications: %w", err)
+		}
+		for _, gn := range page {
+			notifications = append(notifications, toNotification(gn))
+		}
+	}
+}
+
+func toNotification(gn ghNotification) core.Notification {
+	// unread is all the API says: a read thread may be done too.
+	state := core.StateUnread
+	if gn.Unread != nil && !*gn.Unread {
+		state = core.StateRead
+	}
+	return core.Notification{
+		ID:     gn.ID,
+		Reason: gn.Reason,
+		Subject: core.Subject{
+			Title: gn.Subject.Title,
+			URL:   gn.Subject.URL,
+			Type:  gn.Subject.Type,
+		},
+		Repository: core.Repository{
+			FullName: gn.Repository.FullName,
+			Owner:    gn.Repository.Owner.Login,
+			Private:  gn.Repository.Private,
+		},
+		UpdatedAt: gn.UpdatedAt,
+		State:     state,
+	}
+}
+
+func toReviewers(gr ghReviewersResponse) *core.Reviewers {
+	users := make([]string, len(gr.Users))
+	for i, u := range gr.Users {
+		users[i] = u.Login
+	}
+	teams := make([]string, len(gr.Teams))
+	for i, t := range gr.Teams {
+		teams[i] = t.Slug
+	}
+	return &core.Reviewers{Users: users, Teams: teams}
+}
+
+func toPullRequest(gp ghPullRequest) *core.PullRequest {
+	labels := make([]string, len(gp.Labels))
+	for i, l := range gp.Labels {
+		labels[i] = l.Name
+	}
+	assignees := make([]string, len(gp.Assignees))
+	for i, a := range gp.Assignees {
+		assignees[i] = a.Login
+	}
+	return &core.PullRequest{Author: gp.User.Login, Draft: gp.Draft, State: gp.State, Labels: labels, Body: gp.Body, Assignees: assignees, Head: gp.Head.Ref, Base: gp.Base.Ref}
+}
+
+func toRelease(gr ghRelease) core.Release {
+	assets := make([]core.ReleaseAsset, len(gr.Assets))
+	for i, a := range gr.Assets {
+		assets[i] = core.ReleaseAsset{Name: a.Name, URL: a.BrowserDownloadURL}
+	}
+	return core.Release{Tag: gr.TagName, Assets: assets}
 }
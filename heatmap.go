@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// heatmapJSON is mutemath heatmap's JSON output.
+type heatmapJSON struct {
+	Timezone string             `json:"timezone"`
+	Since    time.Time          `json:"since"`
+	Weekdays []string           `json:"weekdays"`
+	Groups   []heatmapGroupJSON `json:"groups"`
+}
+
+type heatmapGroupJSON struct {
+	Group  string     `json:"group"`
+	Total  int        `json:"total"`
+	Counts [7][24]int `json:"counts"` // by weekday, as in weekdays, then hour
+}
+
+// runHeatmap exports when notifications arrive, by weekday and hour per org
+// or repo, from the journal's mutes and kept review requests.
+func runHeatmap(args []string) int {
+	fs := flag.NewFlagSet("heatmap", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to the JSON config file, for its state and business hours timezone (default "+defaultConfigPath()+")")
+	groupBy := fs.String("group-by", "org", "a heatmap per org or repo; empty for one over everything")
+	format := fs.String("format", "csv", "csv (one row per group, weekday, and hour) or json")
+	days := fs.Int("days", int(core.JournalRetention.Hours()/24), "count the last this many days")
+	zone := fs.String("timezone", "", "IANA timezone for weekdays and hours (default business_hours' timezone, or the local one)")
+	fs.Parse(args)
+	if *format != "csv" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: invalid --format %q (valid values: csv, json)\n", *format)
+		return 1
+	}
+	if *days < 1 {
+		fmt.Fprintln(os.Stderr, "Error: --days must be at least 1")
+		return 1
+	}
+	by, err := core.ParseCountGroup(*groupBy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	path, required := resolveConfigPath(*configPath)
+	local, _, err := loadConfig(path, required)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if err := useState(local.state); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	loc := time.Local
+	switch {
+	case *zone != "":
+		if loc, err = time.LoadLocation(*zone); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --timezone: %s\n", err)
+			return 1
+		}
+	case local.businessHours != nil:
+		loc = local.businessHours.Location
+	}
+	records, err := readJournal()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if retention := int(core.JournalRetention.Hours() / 24); *days > retention {
+		fmt.Fprintf(os.Stderr, "note: the journal keeps about %d days of records, so earlier days count nothing\n", retention)
+	}
+
+	since := time.Now().AddDate(0, 0, -*days)
+	maps := core.BuildHeatmaps(records, by, loc, since)
+	if *format == "csv" {
+		w := csv.NewWriter(os.Stdout)
+		w.WriteAll(core.HeatmapCSV(maps))
+		if err := w.Error(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	out := heatmapJSON{Timezone: loc.String(), Since: since.UTC(), Weekdays: core.HeatmapWeekdays, Groups: []heatmapGroupJSON{}}
+	for _, m := range maps {
+		out.Groups = append(out.Groups, heatmapGroupJSON{Group: m.Group, Total: m.Total(), Counts: m.Counts})
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	return 0
+}
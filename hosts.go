@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// newClients builds a GitHub client for each host in the config file, each
+// with the token from its own environment variable, or from gh's login to the
+// host when the variable is unset. Without a hosts list, it returns the single
+// client for GH_HOST. With several hosts, each client qualifies its
+// notifications' labels with its host. Every client sends the config file's
+// User-Agent and API version, if it sets them.
+func newClients(local localConfig) ([]*GitHubClient, error) {
+	clients, err := newHostClients(local.hosts)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range clients {
+		if local.userAgent != "" {
+			c.userAgent = local.userAgent
+		}
+		if local.apiVersion != "" {
+			c.apiVersion = local.apiVersion
+		}
+	}
+	return clients, nil
+}
+
+func newHostClients(hosts []core.HostSpec) ([]*GitHubClient, error) {
+	if len(hosts) == 0 {
+		host := os.Getenv("GH_HOST")
+		token, err := resolveToken(host)
+		if err != nil {
+			return nil, err
+		}
+		return []*GitHubClient{NewGitHubClient(token, core.APIBaseURL(host))}, nil
+	}
+
+	clients := make([]*GitHubClient, len(hosts))
+	for i, h := range hosts {
+		token := os.Getenv(h.TokenEnv)
+		if token == "" {
+			token = ghAuthToken(h.Host)
+		}
+		if token == "" {
+			return nil, fmt.Errorf("%s environment variable is not set, and gh is not logged in to %s", h.TokenEnv, h.Host)
+		}
+		clients[i] = NewGitHubClient(token, core.APIBaseURL(h.Host))
+		if len(hosts) > 1 {
+			clients[i].host = h.Host
+		}
+	}
+	return clients, nil
+}
+
+// runOnceHosts runs once against each host in turn, each with its own section
+// of output and summary. The exit code is the worst of the runs.
+func runOnceHosts(clients []*GitHubClient, cfg core.Config, mode core.Mode, apply, verbose bool, ob *octoboxSync, opts onceOptions) int {
+	if len(clients) == 1 {
+		return runOnce(clients[0], cfg, mode, apply, verbose, ob, opts)
+	}
+	code := 0
+	for i, client := range clients {
+		if i > 0 {
+			fmt.Fprintln(stdout)
+		}
+		fmt.Fprintf(stdout, "== %s ==\n", client.host)
+		code = max(code, runOnce(client, cfg, mode, apply, verbose, ob, opts))
+	}
+	return code
+}
+
+// runDaemonHosts runs a daemon per host concurrently, each on its own poll
+// schedule, until a signal stops them all. The exit code is the worst of them.
+func runDaemonHosts(clients []*GitHubClient, cfg core.Config, mode core.Mode, apply, verbose bool, ob *octoboxSync, opts daemonOptions) int {
+	if len(clients) == 1 {
+		return runDaemon(clients[0], cfg, mode, apply, verbose, ob, opts)
+	}
+
+	// Each daemon writes whole lines; keep them from interleaving mid-line.
+	stdout = &syncWriter{w: stdout}
+
+	var wg sync.WaitGroup
+	codes := make([]int, len(clients))
+	for i, client := range clients {
+		wg.Go(func() {
+			codes[i] = runDaemon(client, cfg, mode, apply, verbose, ob, opts)
+		})
+	}
+	wg.Wait()
+	return slices.Max(codes)
+}
+
+// syncWriter serializes writes from concurrent daemons.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(b)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// responseCacheSize bounds the GET responses a client keeps.
+const responseCacheSize = 500
+
+// cachedResponse is a GET response kept for conditional requests.
+type cachedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// responseCache keeps a client's GET responses that carry an ETag or
+// Last-Modified, and makes the next GET of the same URL conditional on them:
+// GitHub answers 304 when nothing changed, which doesn't count against the
+// rate limit, and the cached response is served instead. Every page of the
+// notification listing benefits, not just the first.
+type responseCache struct {
+	mu    sync.Mutex
+	lru   core.LRU[string, cachedResponse]
+	stats core.CacheStats
+}
+
+func newResponseCache() *responseCache {
+	return &responseCache{lru: core.LRU[string, cachedResponse]{Size: responseCacheSize}}
+}
+
+// prepare returns req made conditional on its cached response, if there is
+// one, leaving req itself as it was so the caller can send it again. key is
+// empty for a request the cache leaves alone: anything but a GET, or one the
+// caller made conditional itself, like the daemon's first listing page.
+func (rc *responseCache) prepare(req *http.Request) (out *http.Request, key string, cached *cachedResponse) {
+	if rc == nil || req.Method != http.MethodGet || req.Header.Get("If-Modified-Since") != "" || req.Header.Get("If-None-Match") != "" {
+		return req, "", nil
+	}
+	key = req.Header.Get("Accept") + " " + req.URL.String()
+	rc.mu.Lock()
+	r, ok := rc.lru.Get(key)
+	rc.mu.Unlock()
+	if !ok {
+		return req, key, nil
+	}
+	out = req.Clone(req.Context())
+	if etag := r.header.Get("ETag"); etag != "" {
+		out.Header.Set("If-None-Match", etag)
+	}
+	if lm := r.header.Get("Last-Modified"); lm != "" {
+		out.Header.Set("If-Modified-Since", lm)
+	}
+	return out, key, &r
+}
+
+// update returns resp as the caller should see it. A 304 to a request made
+// conditional by prepare becomes the cached response, with the 304's fresh
+// headers, such as the rate limit's. A 200 with an ETag or Last-Modified is
+// cached.
+func (rc *responseCache) update(key string, cached *cachedResponse, resp *http.Response) *http.Response {
+	if key == "" {
+		return resp
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		rc.stats.Hits++
+		resp.Body.Close()
+		header := cached.header.Clone()
+		for name, values := range resp.Header {
+			header[name] = values
+		}
+		resp.StatusCode, resp.Status = cached.status, fmt.Sprintf("%d %s", cached.status, http.StatusText(cached.status))
+		resp.Header = header
+		resp.Body = io.NopCloser(bytes.NewReader(cached.body))
+		resp.ContentLength = int64(len(cached.body))
+		rc.lru.Put(key, cachedResponse{status: cached.status, header: header, body: cached.body})
+		return resp
+	}
+	rc.stats.Misses++
+	if resp.StatusCode != http.StatusOK || (resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "") {
+		return resp
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err == nil {
+		rc.lru.Put(key, cachedResponse{status: resp.StatusCode, header: resp.Header.Clone(), body: body})
+	}
+	return resp
+}
+
+// take returns the stats since the last take, and how many responses are
+// cached.
+func (rc *responseCache) take() (core.CacheStats, int) {
+	if rc == nil {
+		return core.CacheStats{}, 0
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	s := rc.stats
+	rc.stats = core.CacheStats{}
+	return s, rc.lru.Len()
+}
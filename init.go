@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// initConfig is the config file mutemath init writes: only what it asked
+// about, so the file stays short enough to read.
+type initConfig struct {
+	Rules        []fileRule `json:"rules,omitempty"`
+	KeepMentions bool       `json:"keep_mentions,omitempty"`
+	KeepAssigned bool       `json:"keep_assigned,omitempty"`
+}
+
+// runInit walks a new user through setup: it finds or logs in for a token,
+// looks up their orgs and teams, proposes which to handle and keep, and
+// writes a first config file.
+func runInit(args []string) int {
+	flags := flag.NewFlagSet("init", flag.ExitOnError)
+	configPath := flags.String("config", "", "where to write the config file (default "+defaultConfigPath()+")")
+	force := flags.Bool("force", false, "overwrite an existing config file")
+	yes := flags.Bool("yes", false, "accept every proposal without asking")
+	teamSize := flags.Int("team-size", core.DefaultInitTeamSize, "propose keeping requests through teams of at most this many members")
+	flags.Parse(args)
+
+	path := *configPath
+	if path == "" {
+		path = defaultConfigPath()
+	}
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "Error: no default config directory; pass --config")
+		return 1
+	}
+	if _, err := os.Stat(path); err == nil && !*force {
+		fmt.Fprintf(os.Stderr, "Error: %s already exists; pass --force to replace it\n", path)
+		return 1
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	p := prompter{in: bufio.NewScanner(os.Stdin), yes: *yes}
+
+	host := os.Getenv("GH_HOST")
+	token, err := resolveToken(host)
+	if err != nil {
+		token, err = ghLogin(p, host)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+	}
+	client := NewGitHubClient(token, core.APIBaseURL(host))
+	if err := client.FetchLogin(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	fmt.Printf("Signed in to %s as %s.\n\n", core.GHHostname(host), client.login)
+
+	var cfg initConfig
+	orgs, err := client.ListOrgs()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if len(orgs) > 0 {
+		fmt.Println("Your orgs:")
+		for i, o := range orgs {
+			fmt.Printf("  %d. %s\n", i+1, o)
+		}
+		picked, err := p.choose("Only handle notifications from which orgs? Others are left alone, like --include-org.", len(orgs), allIndexes(len(orgs)))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		orgs = pick(orgs, picked)
+		if len(orgs) > 0 {
+			cfg.Rules = append(cfg.Rules, fileRule{Name: "other orgs", When: core.OnlyOrgsWhen(orgs), Action: "skip"})
+		}
+	}
+
+	teams, err := client.ListTeams()
+	if err != nil {
+		log.Printf("warning: %s; the token needs the read:org scope to see your teams", err)
+	}
+	teams, proposed := core.ProposeKeepTeams(teams, orgs, *teamSize)
+	if len(teams) > 0 {
+		fmt.Println("\nYour teams:")
+		for i, t := range teams {
+			fmt.Printf("  %d. %s/%s (%d members)\n", i+1, t.Org, t.Slug, t.Members)
+		}
+		picked, err := p.choose("Keep review requests through which teams? The rest are muted.", len(teams), proposed)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		var slugs []string
+		for _, t := range pick(teams, picked) {
+			slugs = append(slugs, t.Slug)
+		}
+		if len(slugs) > 0 {
+			cfg.Rules = append(cfg.Rules, fileRule{Name: "my teams", Teams: slugs, Action: "keep"})
+		}
+	}
+
+	fmt.Println()
+	cfg.KeepMentions = p.confirm("Keep team requests on PRs that @mention you?", true)
+	cfg.KeepAssigned = p.confirm("Keep team requests on PRs assigned to you?", true)
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0o755)
+	}
+	if err == nil {
+		err = os.WriteFile(path, append(data, '\n'), 0o644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: write config: %s\n", err)
+		return 1
+	}
+	if _, _, err := loadConfig(path, true); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: the written config doesn't load: %s\n", err)
+		return 1
+	}
+	fmt.Printf("\nWrote %s. Next:\n\n  %s            # see what would be muted\n  %[2]s --apply    # mute it\n", path, progName)
+	return 0
+}
+
+// ghLogin logs in with the gh CLI, whose --web login is GitHub's device
+// flow, when no token was found, and returns gh's new token.
+func ghLogin(p prompter, host string) (string, error) {
+	hostname := core.GHHostname(host)
+	if _, err := exec.LookPath("gh"); err != nil {
+		return "", fmt.Errorf("no GitHub token: set GH_TOKEN to a classic PAT with the notifications, repo, and read:org scopes, or install the GitHub CLI (https://cli.github.com) and run %s init again", progName)
+	}
+	if !p.confirm("No GitHub token found. Log in with gh now, entering a one-time code in your browser?", true) {
+		return "", errors.New("no GitHub token: set GH_TOKEN, or log in with gh auth login")
+	}
+	return ghWebLogin(hostname)
+}
+
+// prompter asks mutemath init's questions on stdin. With yes, or at the end
+// of input, every question takes its default.
+type prompter struct {
+	in  *bufio.Scanner
+	yes bool
+}
+
+func (p prompter) ask(question, def string) string {
+	fmt.Printf("%s [%s] ", question, def)
+	if p.yes || !p.in.Scan() {
+		fmt.Println()
+		return ""
+	}
+	return strings.TrimSpace(p.in.Text())
+}
+
+func (p prompter) confirm(question string, def bool) bool {
+	hint := "Y/n"
+	if !def {
+		hint = "y/N"
+	}
+	for {
+		switch strings.ToLower(p.ask(question, hint)) {
+		case "":
+			return def
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+	}
+}
+
+// choose asks for some of n numbered choices until the answer parses.
+func (p prompter) choose(question string, n int, def []int) ([]int, error) {
+	hint := "none"
+	if len(def) == n {
+		hint = "all"
+	} else if len(def) > 0 {
+		nums := make([]string, len(def))
+		for i, d := range def {
+			nums[i] = fmt.Sprint(d + 1)
+		}
+		hint = strings.Join(nums, ",")
+	}
+	for {
+		picked, err := core.ParseSelection(p.ask(question+" Numbers, all, or none:", hint), n, def)
+		if err == nil {
+			return picked, nil
+		}
+		fmt.Printf("  %s\n", err)
+	}
+}
+
+func allIndexes(n int) []int {
+	all := make([]int, n)
+	for i := range all {
+		all[i] = i
+	}
+	return all
+}
+
+func pick[T any](items []T, indexes []int) []T {
+	out := make([]T, len(indexes))
+	for i, j := range indexes {
+		out[i] = items[j]
+	}
+	return out
+}
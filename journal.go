@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// journalMaxBytes is the size past which the journal is compacted, dropping
+// records older than core.JournalRetention.
+const journalMaxBytes = 1 << 20
+
+// journalMu serializes journal writes from concurrent daemons.
+var journalMu sync.Mutex
+
+// journalOff disables the journal, for the demo: its threads aren't real and
+// must never be undone against GitHub.
+var journalOff bool
+
+// journalLine is a mutation journal record as stored, one JSON object per line.
+type journalLine struct {
+	Time     time.Time `json:"time"`
+	ThreadID string    `json:"thread_id"`
+	Host     string    `json:"host,omitempty"`
+	Label    string    `json:"label"`
+	Title    string    `json:"title"`
+	URL      string    `json:"url,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+	Teams    []string  `json:"teams,omitempty"`
+	Mode     string    `json:"mode"`
+	Ignored  bool      `json:"ignored"`
+	Undo     bool      `json:"undo,omitempty"`
+	Escalate bool      `json:"escalate,omitempty"`
+	Kept     bool      `json:"kept,omitempty"`
+	Direct   bool      `json:"direct,omitempty"`
+}
+
+// stateDir is where mutemath keeps its state by default: the journal and
+// run lock.
+func stateDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mutemath"), nil
+}
+
+// recordMutation appends a record to client's journal. Failing to is only a
+// warning: the mute itself went through.
+func recordMutation(client *GitHubClient, r core.MutationRecord) {
+	if r.Undo {
+		eventLog.write(client, core.UndoEvent(r))
+	}
+	if journalOff {
+		return
+	}
+	if err := appendJournal(client, r); err != nil {
+		log.Printf("warning: mutation journal: %s", err)
+	}
+}
+
+// keptThreads maps each user, then host and thread, to the UpdatedAt of the
+// last review request recorded as kept, loaded from the user's journal on
+// first use, so a request the daemon sees every cycle is recorded once per
+// update.
+var keptThreads = make(map[string]map[string]time.Time)
+
+// recordKeep journals a kept review request for mutemath report, unless
+// this update of the thread was already recorded.
+func recordKeep(client *GitHubClient, d core.Decision, direct bool) {
+	if journalOff {
+		return
+	}
+	n := d.Notification
+	key := n.Host + "\x00" + n.ID
+	journalMu.Lock()
+	kept, ok := keptThreads[client.user]
+	if !ok {
+		kept = make(map[string]time.Time)
+		keptThreads[client.user] = kept
+		if st, err := client.store(); err == nil {
+			records, _ := readJournalFrom(st)
+			for _, r := range records {
+				if r.Kept {
+					kept[r.Host+"\x00"+r.ThreadID] = r.Time
+				}
+			}
+		}
+	}
+	seen, ok := kept[key]
+	if ok && !n.UpdatedAt.After(seen) {
+		journalMu.Unlock()
+		return
+	}
+	kept[key] = time.Now()
+	journalMu.Unlock()
+	recordMutation(client, core.RecordKeep(d, direct, time.Now()))
+}
+
+func appendJournal(client *GitHubClient, r core.MutationRecord) error {
+	st, err := client.store()
+	if err != nil {
+		return err
+	}
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	if size, err := st.LogSize("journal"); err == nil && size > journalMaxBytes {
+		if err := compactJournal(st); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(toJournalLine(r))
+	if err != nil {
+		return err
+	}
+	return st.Append("journal", data)
+}
+
+// compactJournal rewrites the journal without records past retention.
+func compactJournal(st stateStore) error {
+	records, err := readJournalFrom(st)
+	if err != nil {
+		return err
+	}
+	records = core.PruneRecords(records, time.Now(), core.JournalRetention)
+	var lines [][]byte
+	for _, r := range records {
+		data, err := json.Marshal(toJournalLine(r))
+		if err != nil {
+			return err
+		}
+		lines = append(lines, data)
+	}
+	return st.ReplaceRecords("journal", lines)
+}
+
+// readJournal reads every record in the journal, in the order written. A
+// missing journal has no records.
+func readJournal() ([]core.MutationRecord, error) {
+	st, err := currentState()
+	if err != nil {
+		return nil, err
+	}
+	return readJournalFrom(st)
+}
+
+func readJournalFrom(st stateStore) ([]core.MutationRecord, error) {
+	lines, err := st.Records("journal")
+	if err != nil {
+		return nil, err
+	}
+	var records []core.MutationRecord
+	for i, data := range lines {
+		var l journalLine
+		if err := json.Unmarshal(data, &l); err != nil {
+			return nil, fmt.Errorf("journal record %d: %w", i+1, err)
+		}
+		mode, err := core.ParseMode(l.Mode)
+		if err != nil {
+			return nil, fmt.Errorf("journal record %d: %w", i+1, err)
+		}
+		records = append(records, core.MutationRecord{
+			Time:     l.Time,
+			ThreadID: l.ThreadID,
+			Host:     l.Host,
+			Label:    l.Label,
+			Title:    l.Title,
+			URL:      l.URL,
+			Reason:   l.Reason,
+			Teams:    l.Teams,
+			Mode:     mode,
+			Ignored:  l.Ignored,
+			Undo:     l.Undo,
+			Escalate: l.Escalate,
+			Kept:     l.Kept,
+			Direct:   l.Direct,
+		})
+	}
+	return records, nil
+}
+
+func toJournalLine(r core.MutationRecord) journalLine {
+	return journalLine{
+		Time:     r.Time.UTC(),
+		ThreadID: r.ThreadID,
+		Host:     r.Host,
+		Label:    r.Label,
+		Title:    r.Title,
+		URL:      r.URL,
+		Reason:   r.Reason,
+		Teams:    r.Teams,
+		Mode:     r.Mode.ActionLabelLower(),
+		Ignored:  r.Ignored,
+		Undo:     r.Undo,
+		Escalate: r.Escalate,
+		Kept:     r.Kept,
+		Direct:   r.Direct,
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// lockRetryInterval is how often --wait-for-lock retries the run lock.
+const lockRetryInterval = time.Second
+
+// runLock is the lock file an apply run or daemon holds while it may mutate,
+// so two can't at once. The OS releases it if the process dies.
+type runLock struct {
+	f *os.File
+}
+
+// acquireRunLock takes the run lock in the state dir. If another run holds it,
+// it fails with a message naming the holder, or with wait, retries until it's
+// free or ctx is done.
+func acquireRunLock(ctx context.Context, daemon, wait bool) (*runLock, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return nil, fmt.Errorf("run lock: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("run lock: %w", err)
+	}
+	path := filepath.Join(dir, "run.lock")
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("run lock: %w", err)
+	}
+
+	waiting := false
+	for {
+		ok, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("run lock %s: %w", path, err)
+		}
+		if ok {
+			break
+		}
+		holder := describeHolder(f)
+		if !wait {
+			f.Close()
+			return nil, fmt.Errorf("%s is already muting (lock %s); use --wait-for-lock to wait for it", holder, path)
+		}
+		if !waiting {
+			log.Printf("waiting for %s to release the run lock", holder)
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, context.Cause(ctx)
+		case <-time.After(lockRetryInterval):
+		}
+	}
+
+	// Record the holder for runs that find the lock taken. It's only
+	// informational, so failing to is fine.
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt([]byte(core.FormatLockHolder(core.LockHolder{PID: os.Getpid(), Started: time.Now(), Daemon: daemon})), 0)
+	}
+	return &runLock{f: f}, nil
+}
+
+// describeHolder reads the lock file's holder for a message.
+func describeHolder(f *os.File) string {
+	b, _ := io.ReadAll(io.NewSectionReader(f, 0, 256))
+	h, ok := core.ParseLockHolder(string(b))
+	return core.DescribeLockHolder(h, ok, time.Now())
+}
+
+// Release releases the lock. The file stays, for the next run to lock.
+func (l *runLock) Release() {
+	if l == nil {
+		return
+	}
+	l.f.Close()
+}
@@ -0,0 +1,10 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package main
+
+import "os"
+
+// tryLock always succeeds: the run lock is only enforced where flock exists.
+func tryLock(f *os.File) (bool, error) {
+	return true, nil
+}
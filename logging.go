@@ -0,0 +1,52 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// stdout receives decision rows and daemon cycle summaries. With a --log
+// backend in daemon mode, it also copies them to the backend so mute activity
+// reaches the system log.
+var stdout io.Writer = os.Stdout
+
+// lineWriter sends each line written to it to a log backend, with the
+// priority core.LinePriority picks for it.
+type lineWriter struct {
+	send func(p core.Priority, line string) error
+}
+
+func (w lineWriter) Write(b []byte) (int, error) {
+	for _, line := range strings.Split(string(b), "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		if err := w.send(core.LinePriority(line), line); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// setupLogging routes log output to the backend and, in daemon mode, copies
+// stdout to it too. stderr needs no setup.
+func setupLogging(backend string, daemon bool) error {
+	if backend == "stderr" {
+		return nil
+	}
+	w, err := newLogBackend(backend)
+	if err != nil {
+		return err
+	}
+	// The backend timestamps entries itself.
+	log.SetFlags(0)
+	log.SetOutput(w)
+	if daemon {
+		stdout = io.MultiWriter(os.Stdout, w)
+	}
+	return nil
+}
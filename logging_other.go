@@ -0,0 +1,12 @@
+//go:build windows || plan9
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func newLogBackend(backend string) (lineWriter, error) {
+	return lineWriter{}, fmt.Errorf("--log %s is not supported on %s", backend, runtime.GOOS)
+}
@@ -0,0 +1,45 @@
+//go:build !windows && !plan9
+
+package main
+
+import (
+	"fmt"
+	"log/syslog"
+	"net"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// journalSocket is where journald accepts native-protocol datagrams.
+const journalSocket = "/run/systemd/journal/socket"
+
+func newLogBackend(backend string) (lineWriter, error) {
+	switch backend {
+	case "syslog":
+		w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_DAEMON, "mutemath")
+		if err != nil {
+			return lineWriter{}, fmt.Errorf("connect to syslog: %w", err)
+		}
+		return lineWriter{send: func(p core.Priority, line string) error {
+			switch p {
+			case core.PriorityErr:
+				return w.Err(line)
+			case core.PriorityWarning:
+				return w.Warning(line)
+			case core.PriorityNotice:
+				return w.Notice(line)
+			default:
+				return w.Info(line)
+			}
+		}}, nil
+	default: // journald
+		conn, err := net.Dial("unixgram", journalSocket)
+		if err != nil {
+			return lineWriter{}, fmt.Errorf("connect to journald: %w", err)
+		}
+		return lineWriter{send: func(p core.Priority, line string) error {
+			_, err := conn.Write(core.JournalEntry("mutemath", p, line))
+			return err
+		}}, nil
+	}
+}
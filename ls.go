@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// lsEntry is a notification in mutemath ls --format json.
+type lsEntry struct {
+	ID        string    `json:"id"`
+	Host      string    `json:"host,omitempty"`
+	Repo      string    `json:"repo"`
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Title     string    `json:"title"`
+	URL       string    `json:"url"`
+	HTMLURL   string    `json:"html_url,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Private   bool      `json:"private"`
+}
+
+// runLs lists unread notifications without classifying or changing them.
+func runLs(args []string) int {
+	fs := flag.NewFlagSet("ls", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to the JSON config file, for its hosts (default "+defaultConfigPath()+")")
+	includeOrg := fs.String("include-org", "", "only list notifications from this org")
+	excludeOrg := fs.String("exclude-org", "", "skip notifications from this org")
+	onlyPrivate := fs.Bool("only-private", false, "only list notifications from private repos")
+	onlyPublic := fs.Bool("only-public", false, "only list notifications from public repos")
+	reason := fs.String("reason", "", "only list notifications with any of these comma-separated reasons (e.g. review_requested,mention)")
+	subjectType := fs.String("type", "", "only list notifications about any of these comma-separated subject types (e.g. PullRequest,Issue)")
+	sortBy := fs.String("sort", "newest", "order: newest, oldest, repo, or reason")
+	limit := fs.Int("limit", 0, "list at most this many notifications (0 for all)")
+	format := fs.String("format", "table", "output format: table, tsv, or json")
+	fs.Parse(args)
+
+	by, err := core.ParseListSort(*sortBy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if *format != "table" && *format != "tsv" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: invalid --format %q (valid values: table, tsv, json)\n", *format)
+		return 1
+	}
+	cfg := core.Config{IncludeOrg: *includeOrg, ExcludeOrg: *excludeOrg}
+	switch {
+	case *onlyPrivate && *onlyPublic:
+		fmt.Fprintf(os.Stderr, "Error: --only-private and --only-public can't be used together\n")
+		return 1
+	case *onlyPrivate:
+		cfg.Visibility = "private"
+	case *onlyPublic:
+		cfg.Visibility = "public"
+	}
+
+	path, required := resolveConfigPath(*configPath)
+	local, _, err := loadConfig(path, required)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	clients, err := newClients(local)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	var all []core.Notification
+	for _, client := range clients {
+		_, err := client.ListUnreadNotifications(core.FetchCursor{}, func(page []core.Notification) {
+			all = append(all, page...)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+	}
+
+	notifications := core.FilterForList(all, cfg, core.ListFilter{Reasons: splitList(*reason), Types: splitList(*subjectType)})
+	core.SortNotifications(notifications, by)
+	if *limit > 0 && len(notifications) > *limit {
+		notifications = notifications[:*limit]
+	}
+
+	switch *format {
+	case "json":
+		entries := make([]lsEntry, 0, len(notifications))
+		for _, n := range notifications {
+			entries = append(entries, lsEntry{
+				ID:        n.ID,
+				Host:      n.Host,
+				Repo:      n.Repository.FullName,
+				Type:      n.Subject.Type,
+				Reason:    n.Reason,
+				Title:     n.Subject.Title,
+				URL:       n.Subject.URL,
+				HTMLURL:   core.HTMLURL(n.Subject.URL),
+				UpdatedAt: n.UpdatedAt,
+				Private:   n.Repository.Private,
+			})
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(entries); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+	case "tsv":
+		for _, n := range notifications {
+			fmt.Println(core.FormatListTSV(n))
+		}
+	default:
+		if len(notifications) == 0 {
+			fmt.Println("No unread notifications.")
+			return 0
+		}
+		now := time.Now()
+		for _, n := range notifications {
+			fmt.Println(core.FormatListRow(n, now))
+		}
+	}
+	return 0
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"math/rand/v2"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
@@ -17,186 +22,1633 @@ func main() {
 }
 
 func run() int {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "doctor":
+			return runDoctor(os.Args[2:])
+		case "init":
+			return runInit(os.Args[2:])
+		case "config":
+			return runConfig(os.Args[2:])
+		case "version":
+			return runVersion()
+		case "update":
+			return runUpdate(os.Args[2:])
+		case "demo":
+			return runDemo(os.Args[2:])
+		case "why":
+			return runWhy(os.Args[2:])
+		case "ls":
+			return runLs(os.Args[2:])
+		case "undo":
+			return runUndo(os.Args[2:])
+		case "ratelimit":
+			return runRateLimit(os.Args[2:])
+		case "report":
+			return runReport(os.Args[2:])
+		case "heatmap":
+			return runHeatmap(os.Args[2:])
+		case "feedback":
+			return runFeedback(os.Args[2:])
+		case "replay":
+			return runReplay(os.Args[2:])
+		case "subscription":
+			return runSubscription(os.Args[2:])
+		case "subscriptions":
+			return runSubscriptions(os.Args[2:])
+		case "unwatch":
+			return runUnwatch(os.Args[2:])
+		case "trial":
+			return runTrial(os.Args[2:])
+		case "pin":
+			return runPin(os.Args[2:])
+		case "rules":
+			return runRules(os.Args[2:])
+		}
+	}
+	return runMain(os.Args[1:], nil)
+}
+
+// runMain runs mutemath with the given flags: once, or as a daemon. With a
+// demo server, it runs against that instead of GitHub.
+func runMain(args []string, demo *demoServer) int {
+
 	apply := flag.Bool("apply", false, "perform mutations (default is dry-run)")
 	verbose := flag.Bool("verbose", false, "detailed output")
 	daemon := flag.Bool("daemon", false, "long-running mode, polls per X-Poll-Interval")
 	includeOrg := flag.String("include-org", "", "only process notifications from this org")
 	excludeOrg := flag.String("exclude-org", "", "skip notifications from this org")
-	flag.Parse()
+	includeTopic := flag.String("include-topic", "", "only process notifications from repos with any of these comma-separated topics")
+	excludeTopic := flag.String("exclude-topic", "", "skip notifications from repos with any of these comma-separated topics")
+	onlyPrivate := flag.Bool("only-private", false, "only process notifications from private repos")
+	onlyPublic := flag.Bool("only-public", false, "only process notifications from public repos")
+	notify := flag.String("notify", "", "comma-separated sinks to alert on kept notifications in daemon mode (desktop, ntfy, pushover, slack, discord, matrix)")
+	notifyTemplate := flag.String("notify-template", "", "Go template for alerts: first line is the title, the rest the body (@file to read from a file)")
+	octobox := flag.Bool("octobox", false, "mirror mutes into Octobox (with --apply; needs OCTOBOX_TOKEN)")
+	octoboxPins := flag.Bool("octobox-pins", false, "always keep threads starred in Octobox (needs OCTOBOX_TOKEN)")
+	logBackend := flag.String("log", "stderr", "where to send log output: stderr, syslog, or journald (daemon mode also copies cycle output)")
+	otel := flag.Bool("otel", false, "export traces and metrics over OTLP/HTTP (configured by the OTEL_EXPORTER_OTLP_* env vars)")
+	trial := flag.String("trial", "", "apply mutes for a trial period (e.g. 7d), with a daily digest of them and mutemath trial rollback to undo them all")
+	edit := flag.Bool("edit", false, "write the plan to a file, open $EDITOR to change actions per thread, then apply it")
+	verify := flag.Bool("verify", false, "after muting, re-fetch each muted thread and exit non-zero if any mute didn't stick (with --apply)")
+	crossCheck := flag.Bool("cross-check", false, "before muting a review request, confirm with a search that the PR doesn't request you personally")
+	checkSubscription := flag.Bool("check-subscription", false, "before ignoring a thread, check its subscription and skip threads already ignored")
+	maxPoll := flag.Duration("max-poll-interval", 0, "in daemon mode, lengthen the poll interval up to this while nothing changes (e.g. 15m)")
+	summaryFile := flag.String("summary-file", "", "write a JSON summary (counts, duration, rate limit, errors) to this file after each run or daemon cycle")
+	ledger := flag.String("ledger", "", "append a CSV row (time, scanned, muted, kept, skipped, errors, duration) to this file after each run or daemon cycle")
+	pushgateway := flag.String("pushgateway", "", "push run metrics (counts, duration, errors) to the Prometheus Pushgateway at this URL after a one-shot run")
+	pollJitter := flag.Duration("poll-jitter", 0, "in daemon mode, add a random delay of up to this to each poll, and before the first (e.g. 15s)")
+	pauseOnIncident := flag.Bool("pause-on-incident", false, "in daemon mode, skip cycles while githubstatus.com reports a major incident or API outage")
+	watchdog := flag.Duration("watchdog", 0, "in daemon mode, alert when a cycle runs or starts this much later than it should (e.g. 10m)")
+	listen := flag.String("listen", "", "in daemon mode, serve /healthz on this address (e.g. 127.0.0.1:8080)")
+	debugEndpoints := flag.Bool("debug-endpoints", false, "also serve /debug/pprof and /debug/vars on the --listen address")
+	dashboardFlag := flag.Bool("dashboard", false, "also serve a read-only HTML dashboard of recent cycles, the inbox, and mutes at / on the --listen address")
+	api := flag.Bool("api", false, "also serve a JSON control API under /api/ on the --listen address, authenticated with "+apiTokenEnvVar)
+	maxRuntime := flag.Duration("max-runtime", 0, "stop a one-shot run after this long, reporting what it did so far (e.g. 5m)")
+	requestTimeout := flag.String("request-timeout", "", "comma-separated per-class request timeouts, e.g. lookup=10s,list=2m (classes: list, lookup, mutate; default list=60s,lookup=20s,mutate=30s)")
+	requestPriority := flag.String("request-priority", "mutate,list,lookup", "the order request classes go in while requests are being paced, highest first")
+	reviewerMaxAge := flag.Duration("reviewer-max-age", core.DefaultReviewerMaxAge, "with --apply, fetch a PR's reviewers again before muting it if they were fetched longer ago than this (0 to never)")
+	minIntervalGuard := flag.Bool("min-interval-guard", false, "exit at once, successfully, if the last run was sooner ago than GitHub's poll interval, for over-scheduled cron jobs")
+	fetchAnyway := flag.Bool("fetch-anyway", false, "in a dry run, make the lookups apply would, fetching stale reviewers again before showing a would-be mute")
+	decline := flag.Bool("decline", false, "with --apply, also remove your personal review request from muted PRs; a dry run counts its calls in the estimate")
+	backfill := flag.Bool("backfill", false, "in daemon mode, list the whole backlog in the first cycle, then poll incrementally")
+	all := flag.Bool("all", false, "with --backfill, include read notifications in the first cycle")
+	reviewSLA := flag.Duration("review-sla", 0, "in daemon mode with --apply, unmute and alert on team review requests still unreviewed this long after their mute (e.g. 24h)")
+	teamSizeThreshold := flag.Int("team-size-threshold", 0, "keep team-only review requests through a team of at most this many members instead of muting them")
+	waitForLock := flag.Bool("wait-for-lock", false, "with --apply, wait for another apply run or daemon to finish instead of failing")
+	userAgent := flag.String("user-agent", "", "User-Agent header for GitHub API requests, e.g. to tag traffic for a proxy (default mutemath/<version>)")
+	apiVersion := flag.String("api-version", "", "X-GitHub-Api-Version header for GitHub API requests (default "+core.DefaultAPIVersion+")")
+	diff := flag.Bool("diff", false, "only show notifications whose decision changed since the previous run")
+	show := flag.String("show", "all", "comma-separated actions whose rows a dry run prints, e.g. mute,keep")
+	plain := flag.Bool("plain", false, "print each notification as labeled fields, one per line, without padding or truncation (for screen readers and grep)")
+	timestamps := flag.Bool("timestamps", false, "start dry-run rows with the time too, as apply rows always do")
+	count := flag.Bool("count", false, "print only how many notifications would get each action, without listing them")
+	groupBy := flag.String("group-by", "", "with --count, break the counts down by org or repo")
+	waves := flag.Int("waves", 0, "show N or more near-identical PRs, like a bot's bumps across repos, as one row, or one line of the --edit plan (0 to list each)")
+	eventLogPath := flag.String("event-log", "", "append every classification and mutation as JSON lines to this file")
+	dumpRaw := flag.String("dump-raw", "", "write every API response, secrets redacted, to files in this directory for bug reports (see mutemath replay)")
+	input := flag.String("input", "", "classify the JSON array of notifications in this file (- for stdin) instead of listing them")
+	configPath := flag.String("config", "", "path to the JSON config file with rules (default "+defaultConfigPath()+")")
+	flag.CommandLine.Parse(args)
+
+	backend, err := core.ParseLogBackend(*logBackend)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if err := setupLogging(backend, *daemon); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	if *edit {
+		if *daemon {
+			fmt.Fprintf(os.Stderr, "Error: --edit can't be used with --daemon\n")
+			return 1
+		}
+		*apply = true
+	}
+	var trialLength time.Duration
+	if *trial != "" {
+		if trialLength, err = core.ParseDays(*trial); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --trial: %s\n", err)
+			return 1
+		}
+		if retention := core.JournalRetention; trialLength > retention {
+			fmt.Fprintf(os.Stderr, "Error: --trial can be at most %dd, as long as the journal keeps mutes to roll back\n", int(retention.Hours()/24))
+			return 1
+		}
+		if journalOff {
+			fmt.Fprintf(os.Stderr, "Error: --trial can't be used here: rolling back needs the journal, which this run doesn't keep\n")
+			return 1
+		}
+		*apply = true
+	}
+	if *verify && (!*apply || *daemon) {
+		fmt.Fprintf(os.Stderr, "Error: --verify requires --apply and can't be used with --daemon\n")
+		return 1
+	}
+	if (*maxPoll != 0 || *pollJitter != 0 || *watchdog != 0 || *pauseOnIncident) && !*daemon {
+		fmt.Fprintf(os.Stderr, "Error: --max-poll-interval, --poll-jitter, --watchdog, and --pause-on-incident require --daemon\n")
+		return 1
+	}
+	if *backfill && !*daemon {
+		fmt.Fprintf(os.Stderr, "Error: --backfill requires --daemon\n")
+		return 1
+	}
+	if *all && !*backfill {
+		fmt.Fprintf(os.Stderr, "Error: --all requires --backfill\n")
+		return 1
+	}
+	if *reviewSLA != 0 && (!*daemon || !*apply) {
+		fmt.Fprintf(os.Stderr, "Error: --review-sla requires --daemon and --apply\n")
+		return 1
+	}
+	if *reviewerMaxAge < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --reviewer-max-age can't be negative\n")
+		return 1
+	}
+	if *minIntervalGuard && (*daemon || *input != "") {
+		fmt.Fprintf(os.Stderr, "Error: --min-interval-guard can't be used with --daemon or --input\n")
+		return 1
+	}
+	if *fetchAnyway && *apply {
+		fmt.Fprintf(os.Stderr, "Error: --fetch-anyway can't be used with --apply, which makes its lookups anyway\n")
+		return 1
+	}
+	timeouts, err := core.ParseRequestTimeouts(*requestTimeout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --request-timeout: %s\n", err)
+		return 1
+	}
+	priority, err := core.ParseRequestPriority(*requestPriority)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --request-priority: %s\n", err)
+		return 1
+	}
+	if *teamSizeThreshold < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --team-size-threshold can't be negative\n")
+		return 1
+	}
+	if *pushgateway != "" && *daemon {
+		fmt.Fprintf(os.Stderr, "Error: --pushgateway can't be used with --daemon\n")
+		return 1
+	}
+	if *listen != "" && !*daemon {
+		fmt.Fprintf(os.Stderr, "Error: --listen requires --daemon\n")
+		return 1
+	}
+	if (*debugEndpoints || *dashboardFlag || *api) && *listen == "" {
+		fmt.Fprintf(os.Stderr, "Error: --debug-endpoints, --dashboard, and --api require --listen\n")
+		return 1
+	}
+	apiToken := os.Getenv(apiTokenEnvVar)
+	if *api && apiToken == "" {
+		fmt.Fprintf(os.Stderr, "Error: --api requires a token in %s\n", apiTokenEnvVar)
+		return 1
+	}
+	if *maxRuntime != 0 && *daemon {
+		fmt.Fprintf(os.Stderr, "Error: --max-runtime can't be used with --daemon\n")
+		return 1
+	}
+	// The deadline counts from startup, so it bounds setup lookups too.
+	runCtx := context.Background()
+	if *maxRuntime > 0 {
+		ctx, cancel := context.WithTimeoutCause(runCtx, *maxRuntime, errMaxRuntime)
+		defer cancel()
+		runCtx = ctx
+	}
+	if *waitForLock && !*apply {
+		fmt.Fprintf(os.Stderr, "Error: --wait-for-lock requires --apply\n")
+		return 1
+	}
+	// Only runs that mutate take the lock; the demo touches nothing real.
+	if *apply && demo == nil {
+		lock, err := acquireRunLock(runCtx, *daemon, *waitForLock)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		defer lock.Release()
+	}
+	if *input != "" && *daemon {
+		fmt.Fprintf(os.Stderr, "Error: --input can't be used with --daemon\n")
+		return 1
+	}
+	if *diff && (*daemon || *edit) {
+		fmt.Fprintf(os.Stderr, "Error: --diff can't be used with --daemon or --edit\n")
+		return 1
+	}
+	if *count && (*apply || *daemon || *diff || *edit) {
+		fmt.Fprintf(os.Stderr, "Error: --count can't be used with --apply, --daemon, --diff, or --edit\n")
+		return 1
+	}
+	plainOutput, timestampRows = *plain, *timestamps
+	showRows, err = core.ParseActionSet(*show)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --show: %s\n", err)
+		return 1
+	}
+	if *waves < 0 || *waves == 1 {
+		fmt.Fprintf(os.Stderr, "Error: --waves must be 0 or at least 2\n")
+		return 1
+	}
+	if *waves > 0 && (*daemon || *diff || *count || (*apply && !*edit)) {
+		fmt.Fprintf(os.Stderr, "Error: --waves can't be used with --daemon, --diff, or --count, or with --apply unless --edit is too\n")
+		return 1
+	}
+	if *groupBy != "" && !*count {
+		fmt.Fprintf(os.Stderr, "Error: --group-by requires --count\n")
+		return 1
+	}
+	countGroup, err := core.ParseCountGroup(*groupBy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if *input == "-" && *edit {
+		fmt.Fprintf(os.Stderr, "Error: --input - can't be used with --edit, whose editor needs the terminal\n")
+		return 1
+	}
+
+	visibility := ""
+	switch {
+	case *onlyPrivate && *onlyPublic:
+		fmt.Fprintf(os.Stderr, "Error: --only-private and --only-public can't be used together\n")
+		return 1
+	case *onlyPrivate:
+		visibility = "private"
+	case *onlyPublic:
+		visibility = "public"
+	}
+
+	path, required := resolveConfigPath(*configPath)
+	if demo != nil && !required {
+		path = "" // the demo only uses a config file it's given
+	}
+	local, _, err := loadConfig(path, required)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if err := useState(local.state); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if *userAgent != "" {
+		if err := core.CheckUserAgent(*userAgent); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --user-agent: %s\n", err)
+			return 1
+		}
+		local.userAgent = *userAgent
+	}
+	if *apiVersion != "" {
+		if err := core.CheckAPIVersion(*apiVersion); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --api-version: %s\n", err)
+			return 1
+		}
+		local.apiVersion = *apiVersion
+	}
+	if demo != nil {
+		if len(local.hosts) > 0 || len(local.users) > 0 || !local.policy.IsZero() {
+			log.Printf("demo: ignoring the config file's hosts, users, and shared policy")
+		}
+		local.hosts, local.users, local.policy, local.onCall = nil, nil, core.PolicySource{}, nil
+	}
+	if len(local.users) > 0 {
+		if !*daemon {
+			fmt.Fprintf(os.Stderr, "Error: the config file's users are only served with --daemon\n")
+			return 1
+		}
+		if local.onCall != nil {
+			fmt.Fprintf(os.Stderr, "Error: oncall can't be used with users; it follows one person's rotation\n")
+			return 1
+		}
+	}
 
 	cfg := core.Config{
 		IncludeOrg: *includeOrg,
 		ExcludeOrg: *excludeOrg,
+
+		IncludeTopics: splitList(*includeTopic),
+		ExcludeTopics: splitList(*excludeTopic),
+		Visibility:    visibility,
+		Rules:         local.rules,
+		KeepAuthors:   local.keepAuthors,
+		KeepMentions:  local.keepMentions,
+		KeepAssigned:  local.keepAssigned,
+		MuteApproved:  local.muteApproved,
+
+		MuteTeammateReviewing: local.muteTeammateReviewing,
+		KeepFailedChecks:      local.keepFailedChecks,
+		PinParticipated:       local.pinParticipated,
+		DeadlineWithin:        local.deadlineWithin,
+		TeamSizeThreshold:     *teamSizeThreshold,
+
+		Pins:          local.pins,
+		Waves:         *waves > 0,
+		BusinessHours: local.businessHours,
+		Scoring:       local.scoring,
 	}
 
-	token, err := resolveToken()
+	mode, err := core.ParseMode(os.Getenv("MODE"))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		return 1
 	}
 
-	mode, err := core.ParseMode(os.Getenv("MODE"))
+	sinks, err := core.ParseNotifySinks(*notify)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	settings := notifierSettingsFromEnv()
+	if err := settings.Validate(sinks); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	notifiers, err := newNotifiers(sinks, settings)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	alertTmpl, err := loadAlertTemplate(*notifyTemplate)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		return 1
 	}
 
-	client := NewGitHubClient(token)
+	if demo != nil && (*octobox || *octoboxPins) {
+		fmt.Fprintf(os.Stderr, "Error: --octobox and --octobox-pins can't be used in the demo\n")
+		return 1
+	}
+	var ob *octoboxSync
+	if *octobox || *octoboxPins {
+		obClient, err := newOctoboxClientFromEnv()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		ob = &octoboxSync{client: obClient, mirror: *octobox, pins: *octoboxPins}
+	}
 
-	if err := client.FetchLogin(); err != nil {
+	onCall, err := newOnCallSync(local.onCall)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		return 1
 	}
 
+	reporter, err := newErrorReporterFromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	var clients []*GitHubClient
+	if demo != nil {
+		clients = []*GitHubClient{demo.Client()}
+	} else if len(local.users) > 0 {
+		clients, err = newUserClients(local)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+	} else {
+		clients, err = newClients(local)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+	}
+	if ob != nil && len(clients) > 1 {
+		fmt.Fprintf(os.Stderr, "Error: --octobox and --octobox-pins only work with a single host and user\n")
+		return 1
+	}
+	if *input != "" && len(clients) > 1 {
+		fmt.Fprintf(os.Stderr, "Error: --input only works with a single host\n")
+		return 1
+	}
+	if *dumpRaw != "" {
+		if len(clients) > 1 {
+			fmt.Fprintf(os.Stderr, "Error: --dump-raw only works with a single host\n")
+			return 1
+		}
+		clients[0].raw, err = newRawDumper(*dumpRaw, clients[0].baseURL, []string{clients[0].token})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --dump-raw: %s\n", err)
+			return 1
+		}
+		// A replay has no cache to serve a recorded 304 from.
+		clients[0].cache = nil
+	}
+	// Someone at a terminal can log in again when the token expires mid-run.
+	// --dump-raw only redacts the token it started with.
+	if !*daemon && demo == nil && len(local.users) == 0 && *input != "-" && *dumpRaw == "" {
+		if prompt := newReauthPrompt(); prompt != nil {
+			for _, c := range clients {
+				c.reauth = prompt
+			}
+		}
+	}
+	// An over-scheduled cron job stops before making any request.
+	if *minIntervalGuard {
+		now := time.Now()
+		for _, c := range clients {
+			if last, soon := tooSoon(c, now); soon {
+				fmt.Println(core.FormatTooSoon(last, now))
+				return 0
+			}
+		}
+	}
+	if *eventLogPath != "" {
+		eventLog, err = openEventLog(*eventLogPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --event-log: %s\n", err)
+			return 1
+		}
+		defer eventLog.Close()
+	}
+	var inputNotifications []core.Notification
+	if *input != "" {
+		inputNotifications, err = readInput(*input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		if len(inputNotifications) == 0 {
+			fmt.Println("No notifications in input.")
+			return 0
+		}
+	}
+	var tel *telemetry
+	if *otel {
+		tel, err = newTelemetryFromEnv()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+	}
+	for _, c := range clients {
+		c.checkSubscription = *checkSubscription
+		c.crossCheck = *crossCheck
+		c.decline = *decline
+		c.reviewerMaxAge = *reviewerMaxAge
+		c.fetchAnyway = *fetchAnyway
+		c.timeouts = timeouts
+		c.pacer.priority = priority
+		c.ctx = runCtx
+		c.tel = tel
+		if err := c.FetchLogin(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+	}
+	// The shared policy is fetched from the first host.
+	client := clients[0]
+
+	var policy localConfig
+	if !local.policy.IsZero() {
+		policy, err = loadPolicy(client, local.policy)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		applyPolicy(&cfg, policy)
+	}
+	// Each user of a multi-user daemon has their own pins, added below.
+	if !journalOff && len(local.users) == 0 {
+		st, err := currentState()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		addStoredPins(&cfg, st)
+	}
+	var trialState stateStore
+	if *trial != "" {
+		if len(local.users) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: --trial can't be used with users in the config file\n")
+			return 1
+		}
+		if trialState, err = currentState(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		t, err := beginTrial(trialState, trialLength, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		if !t.Active(time.Now()) {
+			logTrialEnded(t)
+			*apply = false
+		}
+	}
+
 	if *verbose {
-		log.Printf("authenticated as %s", client.login)
+		for _, c := range clients {
+			if c.user != "" {
+				log.Printf("%s  authenticated as %s", c.user, c.login)
+			} else if c.host != "" {
+				log.Printf("authenticated as %s on %s", c.login, c.host)
+			} else {
+				log.Printf("authenticated as %s", c.login)
+			}
+		}
+		if len(local.rules) > 0 {
+			log.Printf("loaded %d rules from %s", len(local.rules), path)
+		}
+		if len(policy.rules) > 0 {
+			log.Printf("loaded %d rules from shared policy %s", len(policy.rules), local.policy)
+		}
 	}
 
 	if *daemon {
-		return runDaemon(client, cfg, mode, *apply, *verbose)
+		var status *statusWatch
+		if *pauseOnIncident && demo == nil {
+			status = newStatusWatch()
+		}
+		var control *daemonControl
+		if *listen != "" {
+			// The control API lists decisions from the dashboard's.
+			if *dashboardFlag || *api {
+				dashboard = &dashboardState{}
+				if len(local.users) > 0 {
+					dashboard.users = clients
+				}
+			}
+			if *api {
+				control = newDaemonControl(apiToken, clients)
+			}
+			if err := startListener(*listen, *debugEndpoints, *dashboardFlag, control); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				return 1
+			}
+		}
+		dopts := daemonOptions{notifiers: notifiers, alertTmpl: alertTmpl, reporter: reporter, maxPoll: *maxPoll, jitter: *pollJitter, watchdog: *watchdog, reviewSLA: *reviewSLA, backfill: *backfill, backfillAll: *all, onCall: onCall, status: status, control: control, summaryFile: *summaryFile, ledger: *ledger, trial: trialState}
+		if len(local.users) > 0 {
+			cfgs, err := userConfigs(local.users, cfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				return 1
+			}
+			for i, c := range clients {
+				if st, err := c.store(); err == nil && !journalOff {
+					addStoredPins(&cfgs[i], st)
+				}
+			}
+			return runDaemonUsers(clients, cfgs, mode, *apply, *verbose, dopts)
+		}
+		return runDaemonHosts(clients, cfg, mode, *apply, *verbose, ob, dopts)
 	}
-	return runOnce(client, cfg, mode, *apply, *verbose)
+	code := runOnceHosts(clients, cfg, mode, *apply, *verbose, ob, onceOptions{verify: *verify, edit: *edit, diff: *diff, count: *count, groupBy: countGroup, waves: *waves, input: inputNotifications, onCall: onCall, summaryFile: *summaryFile, ledger: *ledger, pushgateway: *pushgateway, minIntervalGuard: *minIntervalGuard})
+	if trialState != nil {
+		sendTrialDigest(trialState, notifiers, time.Now())
+	}
+	return code
 }
 
-func resolveToken() (string, error) {
-	token := os.Getenv("GH_TOKEN")
-	if token == "" {
-		return "", fmt.Errorf("GH_TOKEN environment variable is not set\n\nSet a GitHub Classic PAT with 'notifications' scope:\n  export GH_TOKEN=ghp_...")
-	}
-	return token, nil
+// onceOptions holds the options only a single run uses.
+type onceOptions struct {
+	verify      bool            // re-check muted threads after applying
+	edit        bool            // edit the plan in $EDITOR before applying
+	diff        bool            // show only decisions that changed since the previous run
+	count       bool            // print only counts per action, not rows
+	groupBy     core.CountGroup // with count, per org or repo
+	waves       int             // group this many or more near-identical PRs; 0 for none
+	summaryFile string          // where to write the run's JSON summary; empty for none
+	ledger      string          // CSV file to append the run's row to; empty for none
+	pushgateway string          // Pushgateway to push the run's metrics to; empty for none
+	onCall      *onCallSync     // reviewer-on-call rotation; nil if none
+
+	minIntervalGuard bool // record the run for the next one's guard
+
+	input []core.Notification // read with --input, to classify instead of listing; nil to list
 }
 
-func runOnce(client *GitHubClient, cfg core.Config, mode core.Mode, apply, verbose bool) int {
-	result, err := client.ListUnreadNotifications("")
+func runOnce(client *GitHubClient, cfg core.Config, mode core.Mode, apply, verbose bool, ob *octoboxSync, opts onceOptions) int {
+	start := time.Now()
+	cycle := client.tel.Start("cycle")
+	var cycleErr error
+	var decisions []core.Decision
+	var failed core.MarkCounts // mutes, dims, and archives that failed
+	claimErrs := 0
+	var pollInterval time.Duration // from the listing, for --min-interval-guard
+	defer func() {
+		if opts.minIntervalGuard {
+			recordLastRun(client, start, pollInterval)
+		}
+		cycle.End(cycleErr)
+		client.tel.Flush()
+		summary := finishSummary(client, core.SummarizeRun(decisions, cfg.Rules, failed, cycleErr), mode, apply, start)
+		recordSummary(opts.summaryFile, client, summary)
+		recordLedger(opts.ledger, client, summary)
+		pushMetrics(opts.pushgateway, client, summary)
+	}()
+
+	var fetch *fetchStage
+	if opts.input != nil {
+		fetch = startInput(opts.input)
+	} else {
+		fetch = startFetch(client, core.FetchCursor{})
+	}
+	if !fetch.Wait() {
+		result, err := fetch.Finish()
+		if err != nil {
+			cycleErr = err
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		pollInterval = result.PollInterval
+		if opts.count {
+			fmt.Fprint(stdout, core.FormatCounts(core.ActionCounts{}, nil, opts.groupBy))
+			return 0
+		}
+		fmt.Println("No unread notifications.")
+		return 0
+	}
+
+	if !apply && !opts.count {
+		fmt.Println("DRY RUN — no changes will be made (use --apply to execute)")
+		fmt.Println()
+	}
+
+	pinned, err := ob.Pinned(verbose)
 	if err != nil {
+		fetch.Drain()
+		cycleErr = err
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		return 1
 	}
-	if result.NotModified || len(result.Notifications) == 0 {
-		fmt.Println("No unread notifications.")
-		return 0
+	cfg.Pinned = pinned
+	opts.onCall.Apply(&cfg, time.Now())
+	var retries core.RetryQueue
+	if opts.edit {
+		decisions = classifyAll(client, cfg, fetch, verbose)
+		// Don't offer a partial plan.
+		if _, err := fetch.Finish(); err != nil {
+			cycleErr = err
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		var waves []core.Wave
+		if opts.waves > 0 {
+			waves = core.FindWaves(decisions, opts.waves)
+		}
+		plan, err := editPlan(decisions, waves)
+		if errors.Is(err, core.ErrPlanEmpty) {
+			fmt.Println("Plan is empty, nothing applied.")
+			return 0
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		decisions = plan
+		for _, d := range decisions {
+			eventLog.write(client, core.DecisionEvent(d, true, time.Now()))
+		}
+		failed = muteAll(client, mode, decisions, &retries)
+		claimErrs = claimAll(client, decisions)
+	} else {
+		decisions, failed = processNotifications(client, cfg, mode, fetch, &retries, !apply && !opts.diff && !opts.count && opts.waves == 0, apply, verbose)
+	}
+	result, err := fetch.Finish()
+	if retries.Len() > 0 && !client.expired() {
+		client.sleep(mutationRetryDelay)
+		recovered, _ := retryMutations(client, mode, retries.Take(), nil, &retries, true)
+		failed = failed.Minus(recovered)
+	}
+	if err != nil {
+		// Pages fetched before the failure were still processed.
+		cycleErr = err
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+	} else if verbose {
+		log.Printf("fetched %d unread notifications", result.Count)
+	}
+	pollInterval = result.PollInterval
+	if apply {
+		ob.Mirror(client.takeMarked(), verbose)
 	}
+	client.tel.RecordCycle(decisions, failed.Total()+claimErrs, time.Since(start), client.RateLimit())
 
-	if verbose {
-		log.Printf("fetched %d unread notifications", len(result.Notifications))
+	if opts.diff {
+		printDrift(client, decisions, time.Now())
+	}
+	if opts.waves > 0 && !opts.edit {
+		printWaveRows(client, decisions, core.FindWaves(decisions, opts.waves))
+	}
+	// Only a whole listing is a baseline for the next --diff.
+	if err == nil && opts.input == nil && !client.expired() {
+		storePrior(client.host, decisions, time.Now())
 	}
 
-	if !apply {
-		fmt.Println("DRY RUN — no changes will be made (use --apply to execute)")
-		fmt.Println()
+	if opts.count {
+		total, groups := core.CountDecisions(decisions, opts.groupBy)
+		fmt.Fprint(stdout, core.FormatCounts(total, groups, opts.groupBy))
+		if err != nil || client.expired() {
+			return 1
+		}
+		return 0
 	}
 
-	decisions, errCount := processNotifications(client, cfg, mode, result.Notifications, apply, verbose)
+	skip, keep, _ := core.CountByAction(decisions)
+	fmt.Println(core.FormatSummary(len(decisions), core.CountMarks(decisions).Minus(failed), keep, skip, failed.Total()+claimErrs, mode))
+	if orgs := core.FormatOrgCounts(core.CountByOrg(decisions)); orgs != "" {
+		fmt.Print(orgs)
+	}
+	if hits := core.FormatRuleHits(core.CountRuleHits(decisions, cfg.Rules)); hits != "" {
+		fmt.Println(hits)
+	}
+	if line := core.FormatGraphQLCost(client.takeGraphQLCost()); line != "" {
+		fmt.Println(line)
+	}
+	if line := core.FormatCacheStats(client.cache.take()); line != "" && verbose {
+		log.Print(line)
+	}
+	if !apply {
+		perMute := core.CallsPerMute(client.checkSubscription)
+		fmt.Println(core.FormatCostEstimate(core.EstimateApplyCalls(decisions, mode, perMute, client.decline), perMute, client.RateLimit()))
+	}
 
-	skip, keep, mute := core.CountByAction(decisions)
-	fmt.Println(core.FormatSummary(len(decisions), mute-errCount, keep, skip, errCount, mode))
+	verifyFailed := 0
+	if opts.verify {
+		checks := verifyMutes(client, decisions)
+		fmt.Println()
+		for _, c := range checks {
+			if c.Problem() != "" {
+				fmt.Println(core.FormatVerifyRow(c))
+			}
+		}
+		fmt.Println(core.FormatVerifySummary(checks))
+		verifyFailed = core.VerifyFailures(checks)
+	}
 
-	if errCount > 0 {
+	if client.expired() {
+		fmt.Fprintf(os.Stderr, "Error: %s; the results above are partial\n", errMaxRuntime)
+		return 1
+	}
+	if failed.Total() > 0 || claimErrs > 0 || err != nil || verifyFailed > 0 {
 		return 1
 	}
 	return 0
 }
 
-func runDaemon(client *GitHubClient, cfg core.Config, mode core.Mode, apply, verbose bool) int {
+// readInput reads and checks --input notifications from a file, or stdin for "-".
+func readInput(path string) ([]core.Notification, error) {
+	r := os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("read input: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	notifications, err := decodeNotifications(r)
+	if err != nil {
+		return nil, fmt.Errorf("read input: %w", err)
+	}
+	return core.CheckInput(notifications)
+}
+
+// verifyMutes re-fetches each muted thread's read state and subscription.
+func verifyMutes(client *GitHubClient, decisions []core.Decision) []core.MutationCheck {
+	var checks []core.MutationCheck
+	for _, d := range decisions {
+		if d.Action != core.ActionMute {
+			continue
+		}
+		c := core.MutationCheck{Decision: d}
+		c.Unread, c.Err = client.ThreadUnread(d.Notification.ID)
+		if c.Err == nil {
+			c.Ignored, c.Err = client.ThreadIgnored(d.Notification.ID)
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+// mutationRetryDelay is how long a single run waits before retrying failed mutes.
+const mutationRetryDelay = 5 * time.Second
+
+// daemonOptions holds the integrations only the daemon uses.
+type daemonOptions struct {
+	notifiers []notifier
+	alertTmpl *core.AlertTemplate
+	reporter  *errorReporter
+	maxPoll   time.Duration  // adaptive poll ceiling; zero to always poll at X-Poll-Interval
+	jitter    time.Duration  // random extra delay added to each poll
+	watchdog  time.Duration  // how late a cycle may run or start before alerting; zero for no watchdog
+	reviewSLA time.Duration  // how long a muted team request may go unreviewed before it's unmuted; zero for never
+	onCall    *onCallSync    // reviewer-on-call rotation; nil if none
+	status    *statusWatch   // pauses cycles during GitHub incidents; nil to never pause
+	control   *daemonControl // triggers and pauses cycles from the control API; nil for none
+
+	summaryFile string     // where to write each cycle's JSON summary; empty for none
+	ledger      string     // CSV file to append each cycle's row to; empty for none
+	trial       stateStore // where a --trial run's trial is kept; nil without --trial
+
+	backfill    bool // log that the first cycle lists the whole backlog
+	backfillAll bool // with backfill, the first cycle lists read threads too
+}
+
+func runDaemon(client *GitHubClient, cfg core.Config, mode core.Mode, apply, verbose bool, ob *octoboxSync, opts daemonOptions) int {
+	defer opts.reporter.RecoverPanic()
+
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
 
 	pollInterval := 60 * time.Second
-	lastModified := ""
+	// The cursor isn't persisted, so the first cycle always lists the whole
+	// unread backlog; a backfill can widen it to read threads.
+	cursor := core.FetchCursor{All: opts.backfillAll}
+	alerted := make(map[string]bool)
+	var streak core.ErrorStreak
+	var retries core.RetryQueue // failed mutes, retried next cycle
+	backoff := core.PollBackoff{Max: opts.maxPoll}
+	settled := make(map[string]bool) // mutes the review SLA check found reviewed or closed
+
+	// With several hosts or users, each daemon's lines name its host or user.
+	prefix := ""
+	if client.name() != "" {
+		prefix = client.name() + "  "
+	}
+	log.Printf("%sdaemon started (poll interval: %s)", prefix, pollInterval)
+	if opts.backfill {
+		what := "unread"
+		if opts.backfillAll {
+			what = "read and unread"
+		}
+		log.Printf("%sbackfilling: the first cycle processes every %s notification", prefix, what)
+	}
 
-	log.Printf("daemon started (poll interval: %s)", pollInterval)
+	wd := startWatchdog(opts.watchdog, client.name(), opts.notifiers, opts.reporter)
+	defer wd.Stop()
+	dump := registerDump(client.name(), time.Now())
+
+	// Spread out daemons started together, e.g. a team's on one GHES appliance.
+	if delay := core.Jitter(0, opts.jitter, rand.Float64()); delay > 0 {
+		select {
+		case s := <-sig:
+			log.Printf("received %s, shutting down", s)
+			return 0
+		case <-time.After(delay):
+		}
+	}
 
+	paused, held := "", false
 	for {
-		result, err := client.ListUnreadNotifications(lastModified)
+		if opts.control.Paused() {
+			if !held {
+				log.Printf("%scycles paused through the control API", prefix)
+				held = true
+				dump.update(func(s *core.DaemonSnapshot) { s.Paused = "through the control API" })
+			}
+			wd.Finish(pollInterval)
+			select {
+			case s := <-sig:
+				log.Printf("received %s, shutting down", s)
+				return 0
+			case <-time.After(pollInterval):
+			case <-opts.control.Woken():
+			}
+			continue
+		} else if held {
+			log.Printf("%scycles resumed through the control API", prefix)
+			held = false
+			dump.update(func(s *core.DaemonSnapshot) { s.Paused = "" })
+		}
+
+		// Sit out major incidents rather than half-applying mutes and
+		// piling up errors; queued retries wait for the next cycle.
+		if reason, pause := opts.status.Pause(client); pause {
+			if reason != paused {
+				log.Printf("%spausing cycles during GitHub incident: %s", prefix, reason)
+				paused = reason
+				dump.update(func(s *core.DaemonSnapshot) { s.Paused = "GitHub incident: " + reason })
+			}
+			wd.Finish(pollInterval)
+			select {
+			case s := <-sig:
+				log.Printf("received %s, shutting down", s)
+				return 0
+			case <-time.After(pollInterval):
+				continue
+			}
+		} else if paused != "" {
+			log.Printf("%sGitHub incident over, resuming cycles", prefix)
+			paused = ""
+			dump.update(func(s *core.DaemonSnapshot) { s.Paused = "" })
+		}
+
+		start := time.Now()
+		if opts.trial != nil {
+			apply = trialApplies(opts.trial, start, apply)
+		}
+		wd.Start()
+		dump.update(func(s *core.DaemonSnapshot) { s.CycleStarted, s.NextPoll = start, time.Time{} })
+		cycle := client.tel.Start("cycle")
+		carried := retries.Take()
+		if opts.onCall.Apply(&cfg, start) {
+			// The requests kept during the rotation are still unread but
+			// unchanged; list everything so they're muted now.
+			cursor = core.FetchCursor{}
+		}
+		fetch := startFetch(client, cursor)
+		var decisions []core.Decision
+		var failed core.MarkCounts
+		var pinErr error
+		if fetch.Wait() {
+			var pinned map[string]bool
+			if pinned, pinErr = ob.Pinned(verbose); pinErr == nil {
+				cfg.Pinned = pinned
+				decisions, failed = processNotifications(client, cfg, mode, fetch, &retries, !apply, apply, verbose)
+			} else {
+				fetch.Drain()
+			}
+		}
+		result, err := fetch.Finish()
+		if err == nil && pinErr != nil {
+			// A cycle error, so the cursor stays put and the threads left
+			// alone are listed again next cycle.
+			err = pinErr
+		}
+		if cfg.BusinessHours != nil && pinErr == nil {
+			redecided, redecidedFailed := processDeferred(client, cfg, mode, decisions, &retries, apply, verbose, start)
+			decisions, failed = append(decisions, redecided...), failed.Plus(redecidedFailed)
+			storeDeferred(client, decisions)
+		}
+		// Retry after the listing, which mutations would disturb. Half-muted
+		// threads are no longer unread, so this runs even when nothing changed.
+		if len(carried) > 0 {
+			recovered, gaveUp := retryMutations(client, mode, carried, decisions, &retries, false)
+			if verbose {
+				log.Printf("retried %d mutes: %d succeeded, %d gave up, %d queued", len(carried), recovered.Total(), gaveUp.Total(), retries.Len())
+			}
+		}
+		if opts.reviewSLA > 0 {
+			escalateUnclaimed(client, opts.reviewSLA, opts.notifiers, settled, verbose)
+		}
+		if opts.trial != nil {
+			sendTrialDigest(opts.trial, opts.notifiers, time.Now())
+		}
 		now := time.Now()
 
+		if streak.Record(err) {
+			opts.reporter.CaptureError(fmt.Sprintf("%d consecutive cycle errors: %s", streak.Count, err))
+		}
 		if err != nil {
-			log.Printf("cycle error: %s", err)
+			log.Printf("%scycle error: %s", prefix, err)
 		} else {
-			if result.LastModified != "" {
-				lastModified = result.LastModified
+			next := cursor.Advance(result.LastModified, result.Date, start)
+			if next.LastModified == "" && cursor.Since.IsZero() && verbose {
+				log.Printf("server sent no Last-Modified; listing only threads updated since the previous cycle")
 			}
+			cursor = next
 			if result.PollInterval > 0 {
 				pollInterval = result.PollInterval
 			}
+			if len(decisions) == 0 && verbose {
+				fmt.Fprint(stdout, prefix+core.FormatDaemonCycleSummary(now, 0, core.MarkCounts{}, 0, result.NotModified, mode))
+			}
+		}
+		if apply {
+			ob.Mirror(client.takeMarked(), verbose)
+		}
+		// Pages fetched before a listing failure were still processed.
+		if len(decisions) > 0 {
+			fmt.Fprint(stdout, prefix+core.FormatDaemonCycleSummary(now, len(decisions), core.CountMarks(decisions).Minus(failed), failed.Total(), false, mode))
+			if line := core.FormatGraphQLCost(client.takeGraphQLCost()); line != "" {
+				fmt.Fprintln(stdout, prefix+line)
+			}
 
-			if result.NotModified {
-				if verbose {
-					fmt.Print(core.FormatDaemonCycleSummary(now, 0, 0, 0, true, mode))
-				}
-			} else if len(result.Notifications) == 0 {
-				if verbose {
-					fmt.Print(core.FormatDaemonCycleSummary(now, 0, 0, 0, false, mode))
-				}
-			} else {
-				decisions, errCount := processNotifications(client, cfg, mode, result.Notifications, apply, verbose)
-				_, _, muted := core.CountByAction(decisions)
-				fmt.Print(core.FormatDaemonCycleSummary(now, len(decisions), muted-errCount, errCount, false, mode))
+			var pending []core.Decision
+			pending, alerted = core.PendingAlerts(decisions, alerted)
+			sendAlerts(opts.notifiers, opts.alertTmpl, pending)
+		}
+		if line := core.FormatCacheStats(client.cache.take()); line != "" && verbose {
+			log.Printf("%s%s", prefix, line)
+		}
+		client.tel.RecordCycle(decisions, failed.Total(), time.Since(start), client.RateLimit())
+		marked := core.CountMarks(decisions).Minus(failed)
+		recordCycleVars(now, decisions, marked, err)
+		record := core.CycleRecord{
+			Host:        client.name(),
+			At:          now,
+			Duration:    time.Since(start),
+			Scanned:     len(decisions),
+			Actioned:    marked.Total(),
+			Errors:      failed.Total(),
+			NotModified: err == nil && result.NotModified,
+			RateLimit:   client.RateLimit(),
+		}
+		dashboard.record(record, decisions, err)
+		summary := core.SummarizeRun(decisions, cfg.Rules, failed, err)
+		summary.NotModified = err == nil && result.NotModified
+		summary = finishSummary(client, summary, mode, apply, start)
+		recordSummary(opts.summaryFile, client, summary)
+		recordLedger(opts.ledger, client, summary)
+		cycle.End(err)
+		client.tel.Flush()
+
+		wait := backoff.Next(pollInterval, err == nil && result.NotModified)
+		if wait != pollInterval && verbose {
+			log.Printf("no changes lately, next poll in %s", wait)
+		}
+		var rateLimited *core.RateLimitError
+		if errors.As(err, &rateLimited) {
+			if until := time.Until(rateLimited.Until(time.Now())); until > wait {
+				wait = until
+				log.Printf("%srate limited; next poll in %s, when the limit resets", prefix, wait.Round(time.Second))
 			}
 		}
+		wait = core.Jitter(wait, opts.jitter, rand.Float64())
+		wd.Finish(wait)
+		if err != nil {
+			record.Err = err.Error()
+		}
+		caches := map[string]int{"alerted": len(alerted), "topics": len(client.topics), "teams": len(client.teams)}
+		if opts.reviewSLA > 0 {
+			caches["review SLA"] = len(settled)
+		}
+		dump.update(func(s *core.DaemonSnapshot) {
+			s.Cycles++
+			s.CycleStarted, s.NextPoll = time.Time{}, time.Now().Add(wait)
+			s.Last = &record
+			s.Retries = retries.Pending()
+			s.Caches = caches
+		})
 
 		select {
 		case s := <-sig:
 			log.Printf("received %s, shutting down", s)
 			return 0
-		case <-time.After(pollInterval):
+		case <-time.After(wait):
 			// Next cycle.
+		case <-opts.control.Woken():
+			if verbose {
+				log.Printf("%scycle triggered through the control API", prefix)
+			}
 		}
 	}
 }
 
-// processNotifications classifies and optionally mutates notifications one at a time,
-// printing each result as it goes. Returns all decisions and the error count.
-func processNotifications(client *GitHubClient, cfg core.Config, mode core.Mode, notifications []core.Notification, apply, verbose bool) ([]core.Decision, int) {
-	reviewersByURL := make(map[string]*core.Reviewers)
-	decisions := make([]core.Decision, 0, len(notifications))
-	errCount := 0
+// showRows is the actions whose rows are printed, set by --show.
+var showRows = core.AllActions
 
-	for _, n := range notifications {
-		// Fetch reviewer data if needed (with dedup).
-		if core.NeedsReviewerLookup(n, cfg) {
-			if _, ok := reviewersByURL[n.Subject.URL]; !ok {
-				reviewers, err := client.GetRequestedReviewers(n.Subject.URL)
-				if err != nil {
-					if verbose {
-						log.Printf("warning: %s", err)
-					}
-				} else {
-					reviewersByURL[n.Subject.URL] = reviewers
+// plainOutput prints rows as one labeled field per line, set by --plain.
+var plainOutput bool
+
+// timestampRows starts decision rows with the time too, set by --timestamps.
+// Mutation rows always have it.
+var timestampRows bool
+
+func printDecisionRow(client *GitHubClient, d core.Decision) {
+	d = core.AnnotateDryRun(d)
+	printRow(client, core.FormatDecisionRow(d), core.FormatDecisionPlain(d))
+}
+
+// printWaveRows prints a dry run's rows after the listing, each wave's as
+// one row where its first PR's would be.
+func printWaveRows(client *GitHubClient, decisions []core.Decision, waves []core.Wave) {
+	index := core.WaveIndex(waves)
+	printed := make(map[int]bool)
+	for _, d := range decisions {
+		i, ok := index[d.Notification.ID]
+		switch {
+		case !showRows.Has(d.Action):
+		case !ok:
+			printDecisionRow(client, d)
+		case !printed[i]:
+			printed[i] = true
+			printRow(client, core.FormatWaveRow(waves[i]), core.FormatWavePlain(waves[i]))
+		}
+	}
+}
+
+// printRow prints a dry-run row, or its --plain form.
+func printRow(client *GitHubClient, row, plain string) {
+	ts := ""
+	if plainOutput {
+		if timestampRows {
+			ts = "time: " + time.Now().UTC().Format(time.RFC3339) + "\n"
+		}
+		fmt.Fprint(stdout, plainPrefix(client)+ts+plain)
+		return
+	}
+	if timestampRows {
+		ts = time.Now().UTC().Format(time.RFC3339) + "  "
+	}
+	fmt.Fprintln(stdout, client.rowPrefix()+ts+row)
+}
+
+func printMutationRow(client *GitHubClient, d core.Decision, mode core.Mode, err error) {
+	if plainOutput {
+		fmt.Fprint(stdout, plainPrefix(client)+core.FormatMutationPlain(d, mode, err, time.Now()))
+		return
+	}
+	fmt.Fprintln(stdout, client.rowPrefix()+core.FormatMutationRow(d, mode, err, time.Now()))
+}
+
+// plainPrefix is rowPrefix for --plain: a user field in a multi-user daemon.
+func plainPrefix(client *GitHubClient) string {
+	if client.user != "" {
+		return "user: " + client.user + "\n"
+	}
+	return ""
+}
+
+// processNotifications is the classification and mutation stages of a cycle.
+// Pages from the fetch stage are classified as they arrive, printing each
+// decision in showRows as it goes when rows is set. Mutes are queued until
+// the listing is complete: marking threads read while still paging would
+// shift later pages and skip threads. Failed mutes go on the retry queue.
+// Returns all decisions and the failed marks.
+func processNotifications(client *GitHubClient, cfg core.Config, mode core.Mode, fetch *fetchStage, retries *core.RetryQueue, rows, apply, verbose bool) ([]core.Decision, core.MarkCounts) {
+	c := newClassifier(client, cfg, verbose)
+	var decisions, queue []core.Decision
+	var failed core.MarkCounts
+
+	// flush re-checks the queued mutes and makes them, or with --fetch-anyway
+	// prints their rows as re-decided, where apply would mute them.
+	flush := func(queue []core.Decision) core.MarkCounts {
+		checked := c.recheckStale(queue, decisions, apply)
+		if apply {
+			return muteAll(client, mode, checked, retries)
+		}
+		for _, q := range queue {
+			i := slices.IndexFunc(decisions, func(d core.Decision) bool { return d.Notification.ID == q.Notification.ID })
+			if rows && i >= 0 && showRows.Has(decisions[i].Action) {
+				printDecisionRow(client, decisions[i])
+			}
+		}
+		return core.MarkCounts{}
+	}
+
+pages:
+	for page := fetch.Next(); page != nil; page = fetch.Next() {
+		for _, n := range page {
+			d := c.decide(n)
+			if client.expired() {
+				// Its lookups may have been cut off; drop it and the rest.
+				fetch.Drain()
+				break pages
+			}
+			decisions = append(decisions, d)
+			eventLog.write(client, core.DecisionEvent(d, apply, time.Now()))
+
+			// Print, or queue the mutation. Kept review requests are
+			// journaled for mutemath report.
+			if apply && d.Action == core.ActionKeep && n.Reason == "review_requested" {
+				recordKeep(client, d, c.requestedDirectly(n))
+			}
+			if apply && d.Pin {
+				storePin(client, d, verbose)
+			}
+			if (apply || client.fetchAnyway) && d.Action.Marks() {
+				queue = append(queue, d)
+			} else if rows && showRows.Has(d.Action) {
+				printDecisionRow(client, d)
+			}
+			if fetch.Listed() && len(queue) > 0 {
+				failed = failed.Plus(flush(queue))
+				queue = queue[:0]
+			}
+		}
+	}
+	failed = failed.Plus(flush(queue))
+
+	return decisions, failed
+}
+
+// classifier gathers the facts each decision needs and decides, caching API
+// lookups for the run.
+type classifier struct {
+	client         *GitHubClient
+	cfg            core.Config
+	verbose        bool
+	reviewersByURL map[string]*core.Reviewers
+	reviewersAt    map[string]time.Time // when each entry in reviewersByURL was fetched
+	prsByURL       map[string]*core.PullRequest
+	filesByURL     map[string][]string
+	decisionsByURL map[string]string
+	authorsByURL   map[string][]string
+	commentsByURL  map[string][]string
+	requestsByURL  map[string][]core.ReviewRequest
+	membersByTeam  map[string][]string // by "org/slug"
+
+	personal        core.PersonalRequests // from the --cross-check search; nil if it failed
+	personalFetched bool
+}
+
+func newClassifier(client *GitHubClient, cfg core.Config, verbose bool) *classifier {
+	return &classifier{
+		client:         client,
+		cfg:            cfg,
+		verbose:        verbose,
+		reviewersByURL: make(map[string]*core.Reviewers),
+		reviewersAt:    make(map[string]time.Time),
+		prsByURL:       make(map[string]*core.PullRequest),
+		filesByURL:     make(map[string][]string),
+		decisionsByURL: make(map[string]string),
+		authorsByURL:   make(map[string][]string),
+		commentsByURL:  make(map[string][]string),
+		requestsByURL:  make(map[string][]core.ReviewRequest),
+		membersByTeam:  make(map[string][]string),
+	}
+}
+
+func (c *classifier) decide(n core.Notification) core.Decision {
+	d := core.Decide(n, c.facts(n), c.client.login, c.cfg)
+	if c.client.crossCheck && d.Action == core.ActionMute {
+		d = core.GuardPersonalRequests(d, c.personalRequests())
+	}
+	return d
+}
+
+// recheckStale decides queued mutes again when their reviewer data is older
+// than --reviewer-max-age, fetching the reviewers afresh, so a long listing
+// doesn't mute on review requests that have since changed. Mutes that no
+// longer hold are replaced in decisions and dropped from the queue.
+func (c *classifier) recheckStale(queue, decisions []core.Decision, apply bool) []core.Decision {
+	var out []core.Decision
+	for _, d := range queue {
+		url := d.Notification.Subject.URL
+		fetched := c.reviewersAt[url]
+		if !core.ReviewerDataStale(d, fetched, time.Now(), c.client.reviewerMaxAge) {
+			out = append(out, d)
+			continue
+		}
+		delete(c.reviewersByURL, url)
+		delete(c.reviewersAt, url)
+		fresh := c.decide(d.Notification)
+		if c.verbose {
+			log.Printf("%sreviewer data for %s was %s old; decided again: %s (%s)", c.client.rowPrefix(), core.NotificationLabel(d.Notification), time.Since(fetched).Round(time.Second), fresh.Action, fresh.Reason)
+		}
+		eventLog.write(c.client, core.DecisionEvent(fresh, apply, time.Now()))
+		if i := slices.IndexFunc(decisions, func(x core.Decision) bool { return x.Notification.ID == d.Notification.ID }); i >= 0 {
+			decisions[i] = fresh
+		}
+		if fresh.Action.Marks() {
+			out = append(out, fresh)
+		}
+	}
+	return out
+}
+
+// personalRequests searches once per classifier, so once a cycle, for the
+// PRs requesting login personally.
+func (c *classifier) personalRequests() core.PersonalRequests {
+	if !c.personalFetched {
+		c.personalFetched = true
+		prs, err := c.client.SearchPersonalReviewRequests()
+		if err != nil {
+			log.Printf("warning: %s; keeping review requests this cycle", err)
+		} else {
+			c.personal = core.NewPersonalRequests(prs)
+		}
+	}
+	return c.personal
+}
+
+// requestedDirectly reports whether the reviewers looked up for n include
+// login personally.
+func (c *classifier) requestedDirectly(n core.Notification) bool {
+	if reviewers := c.reviewersByURL[n.Subject.URL]; reviewers != nil {
+		for _, user := range reviewers.Users {
+			if strings.EqualFold(user, c.client.login) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// facts looks up what deciding n needs, skipping lookups the config doesn't
+// call for.
+func (c *classifier) facts(n core.Notification) core.Facts {
+	// Fetch repo topics first: a repo the topic filter rules out needs no
+	// other lookups.
+	var topics []string
+	if core.NeedsTopicsLookup(n, c.cfg) {
+		var err error
+		topics, err = c.client.GetRepoTopics(n.Repository.FullName)
+		if err != nil && c.verbose {
+			log.Printf("warning: %s", err)
+		}
+		if core.FiltersTopics(c.cfg) && (topics == nil || !core.MatchesTopicFilter(topics, c.cfg)) {
+			return core.Facts{Topics: topics, Now: time.Now()}
+		}
+	}
+
+	// Fetch the repo's language if a rule needs it (cached by the client).
+	var language string
+	if core.NeedsLanguageLookup(n, c.cfg) {
+		var err error
+		language, err = c.client.GetRepoLanguage(n.Repository.FullName)
+		if err != nil && c.verbose {
+			log.Printf("warning: %s", err)
+		}
+	}
+
+	// Fetch reviewer data if needed (with dedup).
+	if core.NeedsReviewerLookup(n, c.cfg) {
+		if _, ok := c.reviewersByURL[n.Subject.URL]; !ok {
+			reviewers, err := c.client.GetRequestedReviewers(n.Subject.URL)
+			if err != nil {
+				if c.verbose {
+					log.Printf("warning: %s", err)
+				}
+			} else {
+				c.reviewersByURL[n.Subject.URL] = reviewers
+				c.reviewersAt[n.Subject.URL] = time.Now()
+			}
+		}
+	}
+
+	// Fetch PR details if a rule needs them (with dedup).
+	if core.NeedsPRLookup(n, c.cfg) {
+		if _, ok := c.prsByURL[n.Subject.URL]; !ok {
+			pr, err := c.client.GetPullRequest(n.Subject.URL)
+			if err != nil {
+				if c.verbose {
+					log.Printf("warning: %s", err)
 				}
+			} else {
+				c.prsByURL[n.Subject.URL] = pr
 			}
 		}
+	}
 
-		// Classify (pure).
-		d := core.Classify(n, reviewersByURL[n.Subject.URL], client.login, cfg)
-		decisions = append(decisions, d)
+	// Fetch changed files if a rule needs them (with dedup).
+	if core.NeedsFilesLookup(n, c.cfg) {
+		if _, ok := c.filesByURL[n.Subject.URL]; !ok {
+			files, err := c.client.GetPullRequestFiles(n.Subject.URL)
+			if err != nil {
+				if c.verbose {
+					log.Printf("warning: %s", err)
+				}
+			} else {
+				c.filesByURL[n.Subject.URL] = files
+			}
+		}
+	}
 
-		// Print and optionally mutate.
-		if apply && d.Action == core.ActionMute {
-			var mutErr error
-			switch mode {
-			case core.ModeDone:
-				if err := client.MarkThreadDone(d.Notification.ID); err != nil {
-					mutErr = err
+	// Fetch the review decision if needed (with dedup).
+	if core.NeedsReviewDecisionLookup(n, c.cfg) {
+		if _, ok := c.decisionsByURL[n.Subject.URL]; !ok {
+			decision, err := c.client.GetReviewDecision(n.Subject.URL)
+			if err != nil {
+				if c.verbose {
+					log.Printf("warning: %s", err)
 				}
-			default:
-				if err := client.MarkThreadRead(d.Notification.ID); err != nil {
-					mutErr = err
+			} else {
+				c.decisionsByURL[n.Subject.URL] = decision
+			}
+		}
+	}
+
+	// Fetch pending review requests if a rule needs them (with dedup).
+	if core.NeedsRequestSourceLookup(n, c.cfg) {
+		if _, ok := c.requestsByURL[n.Subject.URL]; !ok {
+			requests, err := c.client.GetReviewRequests(n.Subject.URL)
+			if err != nil {
+				if c.verbose {
+					log.Printf("warning: %s", err)
 				}
+			} else {
+				c.requestsByURL[n.Subject.URL] = requests
 			}
-			if mutErr == nil {
-				if err := client.IgnoreThread(d.Notification.ID); err != nil {
-					mutErr = err
+		}
+	}
+
+	// Fetch reviews if needed (with dedup).
+	participation := core.NeedsParticipationLookup(n, c.cfg)
+	if core.NeedsTeammateLookup(n, c.cfg) || participation {
+		if _, ok := c.authorsByURL[n.Subject.URL]; !ok {
+			authors, err := c.client.GetReviewAuthors(n.Subject.URL)
+			if err != nil {
+				if c.verbose {
+					log.Printf("warning: %s", err)
 				}
+			} else {
+				c.authorsByURL[n.Subject.URL] = authors
 			}
-			if mutErr != nil {
-				errCount++
+		}
+	}
+
+	// Fetch conversation comments if needed (with dedup).
+	if participation {
+		if _, ok := c.commentsByURL[n.Subject.URL]; !ok {
+			authors, err := c.client.GetCommentAuthors(n.Subject.URL)
+			if err != nil {
+				if c.verbose {
+					log.Printf("warning: %s", err)
+				}
+			} else {
+				c.commentsByURL[n.Subject.URL] = authors
 			}
-			fmt.Println(core.FormatMutationRow(d, mode, mutErr))
-		} else if !apply {
-			fmt.Println(core.FormatDecisionRow(d))
 		}
 	}
 
-	return decisions, errCount
+	// Fetch requested teams' members if needed (with dedup).
+	var members map[string][]string
+	if core.NeedsTeamMembersLookup(n, c.cfg) {
+		if reviewers := c.reviewersByURL[n.Subject.URL]; reviewers != nil {
+			members = make(map[string][]string, len(reviewers.Teams))
+			for _, team := range reviewers.Teams {
+				key := n.Repository.Owner + "/" + team
+				if _, ok := c.membersByTeam[key]; !ok {
+					list, err := c.client.GetTeamMembers(n.Repository.Owner, team)
+					var authErr *core.AuthError
+					switch {
+					case errors.As(err, &authErr):
+						// Without read:org, every lookup fails: say so once, and
+						// don't ask again this run.
+						c.client.warnTeamScope(err)
+						c.membersByTeam[key] = nil
+					case err != nil:
+						if c.verbose {
+							log.Printf("warning: %s", err)
+						}
+					default:
+						c.membersByTeam[key] = list
+					}
+				}
+				members[team] = c.membersByTeam[key] // nil if unknown
+			}
+		}
+	}
+
+	return core.Facts{
+		Reviewers:      c.reviewersByURL[n.Subject.URL],
+		PR:             c.prsByURL[n.Subject.URL],
+		Files:          c.filesByURL[n.Subject.URL],
+		ReviewDecision: c.decisionsByURL[n.Subject.URL],
+		ReviewAuthors:  c.authorsByURL[n.Subject.URL],
+		CommentAuthors: c.commentsByURL[n.Subject.URL],
+		ReviewRequests: c.requestsByURL[n.Subject.URL],
+		TeamMembers:    members,
+		Topics:         topics,
+		Language:       language,
+		Now:            time.Now(),
+	}
+}
+
+// muteAll mutes, dims, or archives each decision in order, printing a row for
+// each. Failures go on the retry queue, unless the token was refused or the
+// thread is gone. Returns the failures, by action.
+func muteAll(client *GitHubClient, mode core.Mode, decisions []core.Decision, retries *core.RetryQueue) core.MarkCounts {
+	var failed core.MarkCounts
+	for _, d := range decisions {
+		if !d.Action.Marks() {
+			continue
+		}
+		step, err := mutate(client, d, mode, core.StepMark)
+		if err != nil {
+			failed.Add(d.Action)
+			if !client.expired() && core.Retryable(err) && retries.Add(core.PendingMutation{Decision: d, Step: step}) {
+				err = fmt.Errorf("%w (will retry)", err)
+			}
+		}
+		printMutationRow(client, d, mode, err)
+		if err == nil {
+			declineReview(client, d)
+		}
+	}
+	return failed
+}
+
+// claimAll requests login's review on each thread claimed in an --edit plan,
+// printing a row for each. Returns the error count.
+func claimAll(client *GitHubClient, decisions []core.Decision) int {
+	errCount := 0
+	for _, d := range decisions {
+		if !d.Claim {
+			continue
+		}
+		err := client.RequestReview(d.Notification.Subject.URL, client.login)
+		if err != nil {
+			errCount++
+		}
+		fmt.Fprintln(stdout, core.FormatClaimRow(d, err))
+	}
+	return errCount
+}
+
+// declineReview removes login's review request from a muted PR, with
+// --decline, and prints the outcome. Failing to is not a mute error.
+func declineReview(client *GitHubClient, d core.Decision) {
+	if !client.decline || !core.NeedsDecline(d) {
+		return
+	}
+	reviewers, err := client.GetRequestedReviewers(d.Notification.Subject.URL)
+	note := ""
+	if err == nil {
+		var ok bool
+		if ok, note = core.DeclineRequest(reviewers, client.login); ok {
+			err = client.RemoveReviewRequest(d.Notification.Subject.URL, client.login)
+		}
+	}
+	fmt.Fprintln(stdout, core.FormatDeclineRow(d, note, err))
+}
+
+// mutate mutes one thread, starting from the given step: marks it read (or
+// done), then ignores it. A dim or archive only marks it, so it isn't
+// journaled: undo would have nothing to reverse. On failure it returns the
+// step that failed.
+func mutate(client *GitHubClient, d core.Decision, mode core.Mode, from core.MutationStep) (core.MutationStep, error) {
+	mark := d.Action.MarkMode(mode)
+	span := client.tel.Start("mutate", stringAttr("thread.id", d.Notification.ID), stringAttr("mutemath.mode", mark.ActionLabelLower()))
+	ignored := false // whether this mute ignored the subscription, for the journal
+	step, err := func() (core.MutationStep, error) {
+		// A read thread, listed with --all, needs no marking read.
+		if from <= core.StepMark && mark.Marks(d.Notification.State) {
+			var err error
+			switch mark {
+			case core.ModeDone:
+				err = client.MarkThreadDone(d.Notification.ID)
+			default:
+				err = client.MarkThreadRead(d.Notification.ID)
+			}
+			if err != nil {
+				return core.StepMark, err
+			}
+		}
+		if d.Action != core.ActionMute {
+			return core.StepMark, nil
+		}
+		if client.checkSubscription {
+			// On error, fall through to the write rather than fail the mute.
+			if already, err := client.ThreadIgnored(d.Notification.ID); err == nil && already {
+				return core.StepIgnore, nil
+			}
+		}
+		ignored = true
+		return core.StepIgnore, client.IgnoreThread(d.Notification.ID)
+	}()
+	span.End(err)
+	eventLog.write(client, core.MuteEvent(d, mode, step, err, time.Now()))
+	if err == nil && d.Action == core.ActionMute {
+		recordMutation(client, core.RecordMute(d, mode, ignored, time.Now()))
+	}
+	if err == nil && (d.Action == core.ActionMute || d.Action == core.ActionArchive) {
+		client.marked = append(client.marked, d)
+	}
+	return step, err
+}
+
+// retryMutations retries failed mutes from earlier in the run or earlier daemon
+// cycles, printing a row for each that succeeds or is given up on. Threads that
+// were decided again this cycle are skipped: that fresh attempt supersedes the
+// retry. Unless final, mutes that fail again go back on the queue, if a
+// retry may succeed. Returns the recovered and given-up mutations, by action.
+func retryMutations(client *GitHubClient, mode core.Mode, pending []core.PendingMutation, decided []core.Decision, retries *core.RetryQueue, final bool) (recovered, failed core.MarkCounts) {
+	fresh := make(map[string]bool, len(decided))
+	for _, d := range decided {
+		fresh[d.Notification.ID] = true
+	}
+	for _, m := range pending {
+		if fresh[m.Decision.Notification.ID] {
+			continue
+		}
+		step, err := mutate(client, m.Decision, mode, m.Step)
+		if err == nil {
+			recovered.Add(m.Decision.Action)
+			printMutationRow(client, m.Decision, mode, nil)
+			declineReview(client, m.Decision)
+			continue
+		}
+		m.Step = step
+		if !final && core.Retryable(err) && retries.Add(m) {
+			continue
+		}
+		failed.Add(m.Decision.Action)
+		if core.Retryable(err) {
+			err = fmt.Errorf("%w (gave up after %d attempts)", err, m.Attempts+1)
+		}
+		printMutationRow(client, m.Decision, mode, err)
+	}
+	return recovered, failed
 }
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// notifyHTTPClient is shared by the webhook-style sinks.
+var notifyHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// notifier delivers alerts about kept notifications somewhere the user will see them.
+type notifier interface {
+	Notify(a core.Alert) error
+}
+
+func notifierSettingsFromEnv() core.NotifierSettings {
+	return core.NotifierSettings{
+		NtfyTopic:     os.Getenv("NTFY_TOPIC"),
+		NtfyToken:     os.Getenv("NTFY_TOKEN"),
+		PushoverToken: os.Getenv("PUSHOVER_TOKEN"),
+		PushoverUser:  os.Getenv("PUSHOVER_USER"),
+
+		SlackWebhookURL:   os.Getenv("SLACK_WEBHOOK_URL"),
+		DiscordWebhookURL: os.Getenv("DISCORD_WEBHOOK_URL"),
+		MatrixHomeserver:  os.Getenv("MATRIX_HOMESERVER"),
+		MatrixRoomID:      os.Getenv("MATRIX_ROOM_ID"),
+		MatrixAccessToken: os.Getenv("MATRIX_ACCESS_TOKEN"),
+	}
+}
+
+// newNotifiers builds a notifier for each sink name. Names are validated by
+// core.ParseNotifySinks and settings by core.NotifierSettings.Validate.
+func newNotifiers(sinks []string, settings core.NotifierSettings) ([]notifier, error) {
+	var notifiers []notifier
+	for _, sink := range sinks {
+		var n notifier
+		var err error
+		switch sink {
+		case "desktop":
+			n, err = newDesktopNotifier()
+		case "ntfy":
+			n = &ntfyNotifier{url: core.NtfyURL(settings.NtfyTopic), token: settings.NtfyToken}
+		case "pushover":
+			n = &pushoverNotifier{token: settings.PushoverToken, user: settings.PushoverUser}
+		case "slack":
+			n = newSlackNotifier(settings.SlackWebhookURL)
+		case "discord":
+			n = newDiscordNotifier(settings.DiscordWebhookURL)
+		case "matrix":
+			n = newMatrixNotifier(settings.MatrixHomeserver, settings.MatrixRoomID, settings.MatrixAccessToken)
+		}
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+	return notifiers, nil
+}
+
+// loadAlertTemplate parses a --notify-template value; "@path" reads the template from a file.
+func loadAlertTemplate(value string) (*core.AlertTemplate, error) {
+	if path, ok := strings.CutPrefix(value, "@"); ok {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read alert template: %w", err)
+		}
+		value = string(b)
+	}
+	return core.ParseAlertTemplate(value)
+}
+
+// sendAlerts alerts every notifier about each decision. Failures are logged, not fatal.
+func sendAlerts(notifiers []notifier, tmpl *core.AlertTemplate, decisions []core.Decision) {
+	for _, d := range decisions {
+		a, err := tmpl.Render(d)
+		if err != nil {
+			log.Printf("warning: alert for %s: %s; using default alert format", core.NotificationLabel(d.Notification), err)
+			a = core.AlertForDecision(d)
+		}
+		for _, n := range notifiers {
+			if err := n.Notify(a); err != nil {
+				log.Printf("warning: %s", err)
+			}
+		}
+	}
+}
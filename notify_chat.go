@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// chatNotifier posts an alert rendered by core.RenderChat as a JSON payload.
+// Adding a chat sink is a matter of choosing a markup dialect, an endpoint,
+// and the payload shape.
+type chatNotifier struct {
+	name    string
+	method  string
+	url     func() string // called per alert, so endpoints can carry a transaction ID
+	token   string        // optional bearer token
+	payload func(a core.Alert) any
+}
+
+func (n *chatNotifier) Notify(a core.Alert) error {
+	body, err := json.Marshal(n.payload(a))
+	if err != nil {
+		return fmt.Errorf("%s: %w", n.name, err)
+	}
+	req, err := http.NewRequest(n.method, n.url(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s: %w", n.name, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+	return postAlert(n.name, req)
+}
+
+func staticURL(u string) func() string {
+	return func() string { return u }
+}
+
+// newSlackNotifier posts to a Slack incoming webhook.
+func newSlackNotifier(webhookURL string) notifier {
+	return &chatNotifier{
+		name:   "slack",
+		method: "POST",
+		url:    staticURL(webhookURL),
+		payload: func(a core.Alert) any {
+			return map[string]string{"text": core.RenderChat(a, core.MarkupSlack)}
+		},
+	}
+}
+
+// newDiscordNotifier posts to a Discord channel webhook.
+func newDiscordNotifier(webhookURL string) notifier {
+	return &chatNotifier{
+		name:   "discord",
+		method: "POST",
+		url:    staticURL(webhookURL),
+		payload: func(a core.Alert) any {
+			return map[string]string{"content": core.RenderChat(a, core.MarkupMarkdown)}
+		},
+	}
+}
+
+// newMatrixNotifier sends an m.room.message event via the client-server API.
+func newMatrixNotifier(homeserver, roomID, token string) notifier {
+	var txn atomic.Uint64
+	start := time.Now().UnixNano()
+	base := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/",
+		strings.TrimSuffix(homeserver, "/"), url.PathEscape(roomID))
+	return &chatNotifier{
+		name:   "matrix",
+		method: "PUT",
+		// Transaction IDs must be unique per access token, or the homeserver treats the send as a retry and drops it.
+		url:   func() string { return fmt.Sprintf("%smutemath-%d-%d", base, start, txn.Add(1)) },
+		token: token,
+		payload: func(a core.Alert) any {
+			return map[string]string{
+				"msgtype":        "m.text",
+				"body":           core.RenderChat(a, core.MarkupPlain),
+				"format":         "org.matrix.custom.html",
+				"formatted_body": core.RenderChat(a, core.MarkupHTML),
+			}
+		},
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// ntfyNotifier publishes alerts to an ntfy topic (https://docs.ntfy.sh/publish/).
+type ntfyNotifier struct {
+	url   string
+	token string
+}
+
+func (n *ntfyNotifier) Notify(a core.Alert) error {
+	req, err := http.NewRequest("POST", n.url, strings.NewReader(a.Body))
+	if err != nil {
+		return fmt.Errorf("ntfy: %w", err)
+	}
+	// Header values must be ASCII; ntfy decodes RFC 2047 encoded-words.
+	req.Header.Set("Title", mime.QEncoding.Encode("utf-8", a.Title))
+	if a.URL != "" {
+		req.Header.Set("Click", a.URL)
+		req.Header.Set("Actions", "view, Open PR, "+a.URL)
+	}
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+	return postAlert("ntfy", req)
+}
+
+// pushoverNotifier sends alerts through the Pushover API (https://pushover.net/api).
+type pushoverNotifier struct {
+	token string
+	user  string
+}
+
+func (n *pushoverNotifier) Notify(a core.Alert) error {
+	form := url.Values{
+		"token":   {n.token},
+		"user":    {n.user},
+		"title":   {a.Title},
+		"message": {a.Body},
+	}
+	if a.URL != "" {
+		form.Set("url", a.URL)
+		form.Set("url_title", "Open PR")
+	}
+	req, err := http.NewRequest("POST", "https://api.pushover.net/1/messages.json", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("pushover: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return postAlert("pushover", req)
+}
+
+// postAlert sends a sink request and treats any non-2xx status as a failure.
+func postAlert(sink string, req *http.Request) error {
+	resp, err := notifyHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", sink, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s: unexpected status %d", sink, resp.StatusCode)
+	}
+	return nil
+}
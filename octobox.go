@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// Octobox JSON types — these never leave this file.
+
+type obNotificationsResponse struct {
+	Pagination    obPagination     `json:"pagination"`
+	Notifications []obNotification `json:"notifications"`
+}
+
+type obPagination struct {
+	TotalPages int `json:"total_pages"`
+}
+
+type obNotification struct {
+	ID       int64 `json:"id"`
+	GitHubID int64 `json:"github_id"`
+}
+
+// octoboxClient talks to the Octobox API (https://octobox.io or self-hosted).
+type octoboxClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// newOctoboxClientFromEnv reads OCTOBOX_URL and OCTOBOX_TOKEN.
+func newOctoboxClientFromEnv() (*octoboxClient, error) {
+	token := os.Getenv("OCTOBOX_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("--octobox and --octobox-pins require OCTOBOX_TOKEN (your API token from Octobox settings)")
+	}
+	base := os.Getenv("OCTOBOX_URL")
+	if base == "" {
+		base = "https://octobox.io"
+	}
+	return &octoboxClient{
+		baseURL:    strings.TrimSuffix(base, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (c *octoboxClient) do(method, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("X-Octobox-API", "true")
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	return c.httpClient.Do(req)
+}
+
+// notifications lists every notification matching the query, keyed by GitHub
+// thread ID, following pagination.
+func (c *octoboxClient) notifications(query url.Values) (map[string]int64, error) {
+	byThread := make(map[string]int64)
+	query.Set("per_page", "100")
+	for page := 1; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		resp, err := c.do("GET", "/api/notifications.json?"+query.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("list octobox notifications: %w", err)
+		}
+		var body obNotificationsResponse
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("list octobox notifications: unexpected status %d", resp.StatusCode)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("list octobox notifications: %w", err)
+		}
+
+		for _, n := range body.Notifications {
+			byThread[strconv.FormatInt(n.GitHubID, 10)] = n.ID
+		}
+		if page >= body.Pagination.TotalPages || len(body.Notifications) == 0 {
+			return byThread, nil
+		}
+	}
+}
+
+// Inbox returns the unarchived notifications, keyed by GitHub thread ID.
+func (c *octoboxClient) Inbox() (map[string]int64, error) {
+	return c.notifications(url.Values{})
+}
+
+// Starred returns the GitHub thread IDs starred in Octobox.
+func (c *octoboxClient) Starred() (map[string]bool, error) {
+	byThread, err := c.notifications(url.Values{"starred": {"true"}})
+	if err != nil {
+		return nil, err
+	}
+	starred := make(map[string]bool, len(byThread))
+	for thread := range byThread {
+		starred[thread] = true
+	}
+	return starred, nil
+}
+
+// Mute mutes (and archives) Octobox notifications by Octobox ID.
+func (c *octoboxClient) Mute(ids []int64) error {
+	return c.selected("mute", "/api/notifications/mute_selected.json", ids, url.Values{})
+}
+
+// Archive archives Octobox notifications by Octobox ID.
+func (c *octoboxClient) Archive(ids []int64) error {
+	return c.selected("archive", "/api/notifications/archive_selected.json", ids, url.Values{"value": {"true"}})
+}
+
+// selected posts ids to one of Octobox's bulk endpoints.
+func (c *octoboxClient) selected(verb, path string, ids []int64, form url.Values) error {
+	for _, id := range ids {
+		form.Add("id[]", strconv.FormatInt(id, 10))
+	}
+	resp, err := c.do("POST", path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("%s in octobox: %w", verb, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s in octobox: unexpected status %d", verb, resp.StatusCode)
+	}
+	return nil
+}
+
+// octoboxSync is the Octobox integration selected by flags. A nil
+// *octoboxSync means it's disabled. Errors are logged, not fatal: Octobox is a
+// convenience view of the same GitHub threads.
+type octoboxSync struct {
+	client *octoboxClient
+	mirror bool // mute and archive in Octobox what mutemath does
+	pins   bool // always keep threads starred in Octobox
+}
+
+// Pinned fetches the starred threads to keep this cycle. If they can't be
+// fetched, any thread might be one, so callers make no marks this cycle.
+func (s *octoboxSync) Pinned(verbose bool) (map[string]bool, error) {
+	if s == nil || !s.pins {
+		return nil, nil
+	}
+	starred, err := s.client.Starred()
+	if err != nil {
+		return nil, fmt.Errorf("%w; making no changes, so starred threads aren't muted", err)
+	}
+	if verbose {
+		log.Printf("%d threads pinned in octobox", len(starred))
+	}
+	return starred, nil
+}
+
+// Mirror mutes and archives in Octobox the threads muted and archived on
+// GitHub, as taken from GitHubClient.takeMarked: failed or queued marks
+// aren't among them. Dims aren't mirrored: Octobox picks up the read state
+// on its next sync with GitHub.
+func (s *octoboxSync) Mirror(marked []core.Decision, verbose bool) {
+	if s == nil || !s.mirror || len(marked) == 0 {
+		return
+	}
+	inbox, err := s.client.Inbox()
+	if err != nil {
+		log.Printf("warning: %s", err)
+		return
+	}
+	for _, m := range []struct {
+		action core.Action
+		verb   string
+		send   func([]int64) error
+	}{
+		{core.ActionMute, "muted", s.client.Mute},
+		{core.ActionArchive, "archived", s.client.Archive},
+	} {
+		ids := core.OctoboxIDs(marked, m.action, inbox)
+		if len(ids) == 0 {
+			continue
+		}
+		if err := m.send(ids); err != nil {
+			log.Printf("warning: %s", err)
+			continue
+		}
+		if verbose {
+			log.Printf("%s %d threads in octobox", m.verb, len(ids))
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// onCallRefresh is how often the on-call schedule is fetched again. Shifts
+// are known ahead of time, so checking against a cached schedule each cycle
+// is enough to notice a rotation starting or ending.
+const onCallRefresh = 15 * time.Minute
+
+// onCallLookahead is how far ahead PagerDuty shifts are fetched.
+const onCallLookahead = 24 * time.Hour
+
+// PagerDuty JSON types — these never leave this file.
+
+type pdOnCallsResponse struct {
+	OnCalls []pdOnCall `json:"oncalls"`
+}
+
+type pdOnCall struct {
+	User struct {
+		Email string `json:"email"`
+	} `json:"user"`
+	Start *time.Time `json:"start"` // nil for a permanent on-call
+	End   *time.Time `json:"end"`
+}
+
+// onCallSync tracks the user's reviewer-on-call rotation from the config's
+// oncall schedule. A nil *onCallSync means there's none. Errors are logged,
+// not fatal: the last fetched schedule stays in use.
+type onCallSync struct {
+	spec       core.OnCallSpec
+	token      string // PAGERDUTY_TOKEN, for a PagerDuty schedule
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	shifts  []core.OnCallShift
+	fetched time.Time
+}
+
+// newOnCallSync reads PAGERDUTY_TOKEN when spec is a PagerDuty schedule.
+func newOnCallSync(spec *core.OnCallSpec) (*onCallSync, error) {
+	if spec == nil {
+		return nil, nil
+	}
+	s := &onCallSync{spec: *spec, httpClient: &http.Client{Timeout: 30 * time.Second}}
+	if spec.PagerDutySchedule != "" {
+		s.token = os.Getenv("PAGERDUTY_TOKEN")
+		if s.token == "" {
+			return nil, fmt.Errorf("oncall: pagerduty_schedule requires PAGERDUTY_TOKEN (a read-only PagerDuty API key)")
+		}
+	}
+	return s, nil
+}
+
+// Apply sets cfg's on-call fields for now, refreshing the schedule when it's
+// stale. ended reports that the rotation cfg was last set for has ended, so
+// the requests it kept should be classified again. Daemons for several hosts
+// share one onCallSync, each with its own cfg.
+func (s *onCallSync) Apply(cfg *core.Config, now time.Time) (ended bool) {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	if now.Sub(s.fetched) >= onCallRefresh {
+		shifts, err := s.fetch(now)
+		if err != nil {
+			log.Printf("warning: %s", err)
+		} else {
+			s.shifts = shifts
+		}
+		// Don't retry a failing source every cycle either.
+		s.fetched = now
+	}
+	onCall := core.OnCallAt(s.shifts, now)
+	s.mu.Unlock()
+
+	switch {
+	case onCall && !cfg.OnCall:
+		log.Printf("on call: keeping team review requests")
+	case !onCall && cfg.OnCall:
+		log.Printf("rotation ended: muting team review requests again")
+	}
+	ended = cfg.OnCall && !onCall
+	cfg.OnCall, cfg.OnCallTeams = onCall, s.spec.Teams
+	return ended
+}
+
+func (s *onCallSync) fetch(now time.Time) ([]core.OnCallShift, error) {
+	if s.spec.ICalURL != "" {
+		return s.fetchICal()
+	}
+	return s.fetchPagerDuty(now)
+}
+
+func (s *onCallSync) fetchICal() ([]core.OnCallShift, error) {
+	u := s.spec.ICalURL
+	if rest, ok := strings.CutPrefix(u, "webcal://"); ok {
+		u = "https://" + rest
+	}
+	resp, err := s.httpClient.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("fetch on-call calendar: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch on-call calendar: unexpected status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return nil, fmt.Errorf("fetch on-call calendar: %w", err)
+	}
+	shifts, err := core.ParseICalShifts(string(data), time.LoadLocation)
+	if err != nil {
+		return nil, fmt.Errorf("fetch on-call calendar: %w", err)
+	}
+	return shifts, nil
+}
+
+// fetchPagerDuty lists the schedule's on-call entries from now through the
+// lookahead, keeping the user's.
+func (s *onCallSync) fetchPagerDuty(now time.Time) ([]core.OnCallShift, error) {
+	query := url.Values{
+		"schedule_ids[]": {s.spec.PagerDutySchedule},
+		"include[]":      {"users"},
+		"since":          {now.UTC().Format(time.RFC3339)},
+		"until":          {now.Add(onCallLookahead).UTC().Format(time.RFC3339)},
+	}
+	req, err := http.NewRequest("GET", "https://api.pagerduty.com/oncalls?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Token token="+s.token)
+	req.Header.Set("Accept", "application/vnd.pagerduty+json;version=2")
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch pagerduty on-calls: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch pagerduty on-calls: unexpected status %d", resp.StatusCode)
+	}
+	var body pdOnCallsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("fetch pagerduty on-calls: %w", err)
+	}
+	var shifts []core.OnCallShift
+	for _, oc := range body.OnCalls {
+		if !strings.EqualFold(oc.User.Email, s.spec.PagerDutyEmail) {
+			continue
+		}
+		shift := core.OnCallShift{Start: now, End: now.Add(onCallLookahead)}
+		if oc.Start != nil {
+			shift.Start = *oc.Start
+		}
+		if oc.End != nil {
+			shift.End = *oc.End
+		}
+		shifts = append(shifts, shift)
+	}
+	return shifts, nil
+}
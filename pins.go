@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// pinsMu serializes access to the pins document.
+var pinsMu sync.Mutex
+
+// runPin lists, adds, or removes the threads mutemath must never touch. Pins
+// added here are kept in state, alongside the config file's pinned list.
+func runPin(args []string) int {
+	fs := flag.NewFlagSet("pin", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s pin [flags] [thread-id | PR URL | org/repo#number]\n\nWith no thread, lists the pinned threads.\n\n", progName)
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("config", "", "path to the JSON config file, for its pinned list and state (default "+defaultConfigPath()+")")
+	remove := fs.Bool("remove", false, "unpin the thread instead")
+	fs.Parse(args)
+	if fs.NArg() > 1 || (*remove && fs.NArg() == 0) {
+		fs.Usage()
+		return 2
+	}
+
+	path, required := resolveConfigPath(*configPath)
+	local, _, err := loadConfig(path, required)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if err := useState(local.state); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	st, err := currentState()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if fs.NArg() == 0 {
+		stored, err := loadPins(st)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		if len(local.pins) == 0 && len(stored) == 0 {
+			fmt.Println("Nothing pinned.")
+			return 0
+		}
+		for _, p := range local.pins {
+			fmt.Printf("%s  (config file)\n", p)
+		}
+		for _, p := range stored {
+			fmt.Println(p)
+		}
+		return 0
+	}
+
+	target, err := core.ParseThreadTarget(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	var changed bool
+	err = updatePins(st, func(pins []core.ThreadTarget) []core.ThreadTarget {
+		if *remove {
+			pins, changed = core.RemovePin(pins, target)
+		} else {
+			pins, changed = core.AddPin(pins, target)
+		}
+		return pins
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	switch {
+	case *remove && changed:
+		fmt.Printf("Unpinned %s.\n", target)
+	case *remove:
+		fmt.Printf("%s wasn't pinned.\n", target)
+	case changed:
+		fmt.Printf("Pinned %s: mutemath won't touch it.\n", target)
+	default:
+		fmt.Printf("%s is already pinned.\n", target)
+	}
+	return 0
+}
+
+// loadPins returns the threads pinned with mutemath pin or automatically,
+// from the "pins" document in st.
+func loadPins(st stateStore) ([]core.ThreadTarget, error) {
+	pinsMu.Lock()
+	defer pinsMu.Unlock()
+	return readPins(st)
+}
+
+// updatePins loads the pins stored in st, applies f, and saves them.
+func updatePins(st stateStore, f func([]core.ThreadTarget) []core.ThreadTarget) error {
+	pinsMu.Lock()
+	defer pinsMu.Unlock()
+	pins, err := readPins(st)
+	if err != nil {
+		return err
+	}
+	lines := []string{}
+	for _, p := range f(pins) {
+		lines = append(lines, p.String())
+	}
+	data, err := json.Marshal(lines)
+	if err != nil {
+		return err
+	}
+	return st.Save("pins", data)
+}
+
+func readPins(st stateStore) ([]core.ThreadTarget, error) {
+	data, err := st.Load("pins")
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return nil, fmt.Errorf("pins: %w", err)
+	}
+	pins := make([]core.ThreadTarget, 0, len(lines))
+	for _, l := range lines {
+		p, err := core.ParseThreadTarget(l)
+		if err != nil {
+			return nil, fmt.Errorf("pins: %w", err)
+		}
+		pins = append(pins, p)
+	}
+	return pins, nil
+}
+
+// addStoredPins adds the pins stored in st to cfg. Failing to read them is
+// only a warning, leaving the config file's pins.
+func addStoredPins(cfg *core.Config, st stateStore) {
+	pins, err := loadPins(st)
+	if err != nil {
+		log.Printf("warning: reading pinned threads: %s", err)
+		return
+	}
+	cfg.Pins = append(cfg.Pins, pins...)
+}
+
+// storePin pins the thread of a decision made under pin_participated, so it
+// stays kept across restarts without looking up its reviews and comments
+// again. Failing to is only a warning: the next lookup keeps it anyway.
+func storePin(client *GitHubClient, d core.Decision, verbose bool) {
+	if journalOff {
+		return
+	}
+	pin, err := core.PinFor(d.Notification)
+	if err != nil {
+		log.Printf("warning: pinning %s: %s", core.NotificationLabel(d.Notification), err)
+		return
+	}
+	st, err := client.store()
+	if err != nil {
+		log.Printf("warning: pinning %s: %s", pin, err)
+		return
+	}
+	var added bool
+	err = updatePins(st, func(pins []core.ThreadTarget) []core.ThreadTarget {
+		pins, added = core.AddPin(pins, pin)
+		return pins
+	})
+	if err != nil {
+		log.Printf("warning: pinning %s: %s", pin, err)
+	} else if added && verbose {
+		log.Printf("%spinned %s: %s", client.rowPrefix(), pin, d.Reason)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// fetchBuffer is how many pages the fetch stage may run ahead of classification.
+const fetchBuffer = 8
+
+// fetchStage lists unread notifications in the background, streaming pages to
+// the classification stage as they arrive.
+type fetchStage struct {
+	pages chan []core.Notification // closed when the listing ends
+	done  chan struct{}            // closed when the listing ends, before pages are drained
+	head  []core.Notification      // first page, read by Wait
+
+	// Set before done is closed.
+	result *NotificationsResult
+	err    error
+}
+
+func startFetch(client *GitHubClient, cursor core.FetchCursor) *fetchStage {
+	f := &fetchStage{
+		pages: make(chan []core.Notification, fetchBuffer),
+		done:  make(chan struct{}),
+	}
+	go func() {
+		defer close(f.pages)
+		f.result, f.err = client.ListUnreadNotifications(cursor, func(page []core.Notification) {
+			f.pages <- page
+		})
+		close(f.done)
+	}()
+	return f
+}
+
+// inputPageSize is the page size notifications read with --input are split
+// into, matching the listing's, so mutes are batched the same way.
+const inputPageSize = 50
+
+// startInput is startFetch for notifications read with --input: it streams
+// them as pages without calling the API.
+func startInput(notifications []core.Notification) *fetchStage {
+	f := &fetchStage{
+		pages:  make(chan []core.Notification, fetchBuffer),
+		done:   make(chan struct{}),
+		result: &NotificationsResult{Count: len(notifications)},
+	}
+	go func() {
+		defer close(f.pages)
+		close(f.done)
+		for page := range slices.Chunk(notifications, inputPageSize) {
+			f.pages <- page
+		}
+	}()
+	return f
+}
+
+// Wait blocks until the first page arrives or the listing ends, and reports
+// whether there's anything to process. When it returns false, result and err
+// are set.
+func (f *fetchStage) Wait() bool {
+	page, ok := <-f.pages
+	f.head = page
+	return ok
+}
+
+// Listed reports whether the listing has finished.
+func (f *fetchStage) Listed() bool {
+	select {
+	case <-f.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Next returns the next page, or nil once the listing has ended and every
+// page has been returned.
+func (f *fetchStage) Next() []core.Notification {
+	if page := f.head; page != nil {
+		f.head = nil
+		return page
+	}
+	return <-f.pages
+}
+
+// Drain discards the remaining pages, for a consumer that stops early, so
+// the listing isn't left blocked on a full buffer.
+func (f *fetchStage) Drain() {
+	for f.Next() != nil {
+	}
+}
+
+// Finish waits for the listing to end and returns its outcome.
+func (f *fetchStage) Finish() (*NotificationsResult, error) {
+	<-f.done
+	return f.result, f.err
+}
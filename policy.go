@@ -0,0 +1,196 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// policyHTTPClient fetches rules_url policies. It never sends credentials:
+// the URL can point anywhere.
+var policyHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// policyCache is the last verified copy of a shared policy, on disk.
+type policyCache struct {
+	ETag      string `json:"etag"`
+	Content   []byte `json:"content"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// policyCachePath returns the cache file for a policy source, under the user
+// cache dir. The public key is part of the name, so a copy cached before
+// rules_public_key was set, or under another key, is never revalidated as if
+// it had been verified.
+func policyCachePath(src core.PolicySource) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(src.String() + "\x00" + src.PublicKey))
+	return filepath.Join(dir, "mutemath", "policy-"+hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+func readPolicyCache(path string) (*policyCache, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c policyCache
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func writePolicyCache(path string, c *policyCache) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// fetchPolicyFile fetches one file of a policy source, conditionally on etag.
+func fetchPolicyFile(client *GitHubClient, src core.PolicySource, etag string) (body []byte, newETag string, notModified bool, err error) {
+	if src.URL == "" {
+		return client.FetchRepoFile(src.Repo, src.Path, src.Ref, etag)
+	}
+
+	req, err := http.NewRequest("GET", src.URL, nil)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("fetch %s: %w", src, err)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := policyHTTPClient.Do(req)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("fetch %s: %w", src, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil, etag, true, nil
+	case http.StatusOK:
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if err != nil {
+			return nil, "", false, fmt.Errorf("fetch %s: %w", src, err)
+		}
+		return body, resp.Header.Get("ETag"), false, nil
+	default:
+		return nil, "", false, fmt.Errorf("fetch %s: unexpected status %d", src, resp.StatusCode)
+	}
+}
+
+// fetchPolicy returns a verified copy of the shared policy. Unchanged policies
+// (by ETag) come from the cache, and so does the last good copy if the fetch
+// fails. A policy that fails signature verification is never used or cached,
+// and a cached copy that fails it is ignored, ETag and all.
+func fetchPolicy(client *GitHubClient, src core.PolicySource) ([]byte, error) {
+	cachePath, err := policyCachePath(src)
+	if err != nil {
+		return nil, err
+	}
+	cached, _ := readPolicyCache(cachePath)
+	if cached != nil && core.VerifyPolicy(src, cached.Content, cached.Signature) != nil {
+		// Its ETag would get a 304 for content that can't be used.
+		cached = nil
+	}
+	etag := ""
+	if cached != nil {
+		etag = cached.ETag
+	}
+
+	fresh, err := func() (*policyCache, error) {
+		body, newETag, notModified, err := fetchPolicyFile(client, src, etag)
+		if err != nil || notModified {
+			return nil, err
+		}
+		c := &policyCache{ETag: newETag, Content: body}
+		if src.PublicKey != "" {
+			sig, _, _, err := fetchPolicyFile(client, src.SignatureSource(), "")
+			if err != nil {
+				return nil, fmt.Errorf("signature: %w", err)
+			}
+			c.Signature = string(sig)
+		}
+		return c, nil
+	}()
+
+	switch {
+	case fresh != nil:
+		if err := core.VerifyPolicy(src, fresh.Content, fresh.Signature); err != nil {
+			return nil, err
+		}
+		if err := writePolicyCache(cachePath, fresh); err != nil {
+			log.Printf("warning: cache policy %s: %s", src, err)
+		}
+		return fresh.Content, nil
+	case cached == nil && err != nil:
+		return nil, err
+	case cached == nil:
+		return nil, errors.New("server reported the policy unchanged, but there's no cached copy")
+	default:
+		if err != nil {
+			log.Printf("warning: %s; using cached policy", err)
+		}
+		return cached.Content, nil
+	}
+}
+
+// loadPolicy fetches and parses the shared policy, to extend the local config
+// with applyPolicy.
+func loadPolicy(client *GitHubClient, src core.PolicySource) (localConfig, error) {
+	data, err := fetchPolicy(client, src)
+	if err != nil {
+		return localConfig{}, fmt.Errorf("shared policy: %w", err)
+	}
+	cfg, diags := checkConfig(data)
+	for _, d := range diags {
+		if !d.Warning {
+			return localConfig{}, fmt.Errorf("shared policy: %s", core.FormatConfigDiagnostic(src.String(), d))
+		}
+	}
+	if !cfg.policy.IsZero() {
+		return localConfig{}, fmt.Errorf("shared policy %s: a policy can't reference another policy", src)
+	}
+	if len(cfg.hosts) > 0 {
+		return localConfig{}, fmt.Errorf("shared policy %s: hosts and their tokens are personal; list them in your own config", src)
+	}
+	return cfg, nil
+}
+
+// applyPolicy extends cfg with a shared policy: its rules go after the local
+// ones, its keep_authors add to the local list, and its settings apply if set.
+func applyPolicy(cfg *core.Config, policy localConfig) {
+	cfg.Rules = append(cfg.Rules, policy.rules...)
+	cfg.KeepAuthors = append(cfg.KeepAuthors, policy.keepAuthors...)
+	cfg.KeepMentions = cfg.KeepMentions || policy.keepMentions
+	cfg.KeepAssigned = cfg.KeepAssigned || policy.keepAssigned
+	cfg.MuteApproved = cfg.MuteApproved || policy.muteApproved
+	cfg.MuteTeammateReviewing = cfg.MuteTeammateReviewing || policy.muteTeammateReviewing
+	cfg.KeepFailedChecks = cfg.KeepFailedChecks || policy.keepFailedChecks
+	cfg.PinParticipated = cfg.PinParticipated || policy.pinParticipated
+	if cfg.DeadlineWithin == 0 {
+		cfg.DeadlineWithin = policy.deadlineWithin
+	}
+	cfg.Pins = append(cfg.Pins, policy.pins...)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// lastRunLine is a host's last run as stored.
+type lastRunLine struct {
+	Host                string    `json:"host"`
+	Time                time.Time `json:"time"`
+	PollIntervalSeconds int       `json:"poll_interval_seconds"`
+}
+
+// readLastRuns reads each host's last guarded run from the "last-runs"
+// document in client's state store. Before the first there are none.
+func readLastRuns(client *GitHubClient) ([]core.LastRun, error) {
+	st, err := client.store()
+	if err != nil {
+		return nil, err
+	}
+	data, err := st.Load("last-runs")
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var lines []lastRunLine
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return nil, fmt.Errorf("last-runs: %w", err)
+	}
+	runs := make([]core.LastRun, 0, len(lines))
+	for _, l := range lines {
+		runs = append(runs, core.LastRun{Host: l.Host, At: l.Time, PollInterval: time.Duration(l.PollIntervalSeconds) * time.Second})
+	}
+	return runs, nil
+}
+
+// tooSoon returns client's host's last run if a run now would come sooner
+// after it than GitHub's poll interval, for --min-interval-guard. Without a
+// recorded run, or with no state to read, the run goes ahead.
+func tooSoon(client *GitHubClient, now time.Time) (core.LastRun, bool) {
+	if journalOff {
+		return core.LastRun{}, false
+	}
+	runs, err := readLastRuns(client)
+	if err != nil {
+		log.Printf("warning: read last run for --min-interval-guard: %s", err)
+		return core.LastRun{}, false
+	}
+	host := core.APIHost(client.baseURL)
+	for _, r := range runs {
+		if _, soon := r.TooSoon(now); soon && strings.EqualFold(r.Host, host) {
+			return r, true
+		}
+	}
+	return core.LastRun{}, false
+}
+
+// recordLastRun replaces client's host's last run with one at at, for the
+// next --min-interval-guard. Failing to is only a warning.
+func recordLastRun(client *GitHubClient, at time.Time, pollInterval time.Duration) {
+	if journalOff || pollInterval <= 0 {
+		return
+	}
+	if err := writeLastRun(client, core.LastRun{Host: core.APIHost(client.baseURL), At: at, PollInterval: pollInterval}); err != nil {
+		log.Printf("warning: record last run for --min-interval-guard: %s", err)
+	}
+}
+
+func writeLastRun(client *GitHubClient, run core.LastRun) error {
+	st, err := client.store()
+	if err != nil {
+		return err
+	}
+	runs, err := readLastRuns(client)
+	if err != nil {
+		return err
+	}
+	lines := []lastRunLine{{Host: run.Host, Time: run.At.UTC(), PollIntervalSeconds: int(run.PollInterval / time.Second)}}
+	for _, r := range runs {
+		if !strings.EqualFold(r.Host, run.Host) {
+			lines = append(lines, lastRunLine{Host: r.Host, Time: r.At.UTC(), PollIntervalSeconds: int(r.PollInterval / time.Second)})
+		}
+	}
+	data, err := json.Marshal(lines)
+	if err != nil {
+		return err
+	}
+	return st.Save("last-runs", data)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// pushMetrics pushes a one-shot run's metrics to a Prometheus Pushgateway
+// when --pushgateway is set, replacing the previous run's. With several
+// hosts, each host is its own group. Failing to is only a warning; it never
+// fails the run.
+func pushMetrics(gateway string, client *GitHubClient, s core.RunSummary) {
+	if gateway == "" {
+		return
+	}
+	if err := push(core.PushgatewayURL(gateway, client.host), core.FormatPushMetrics(s)); err != nil {
+		log.Printf("warning: push metrics: %s", err)
+	}
+}
+
+func push(url, metrics string) error {
+	req, err := http.NewRequest(http.MethodPut, url, strings.NewReader(metrics))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := (&http.Client{Timeout: 10 * time.Second}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: unexpected status %d", url, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// runRateLimit prints the token's rate-limit usage and reset times on each
+// configured host, to explain slow or failing cycles.
+func runRateLimit(args []string) int {
+	fs := flag.NewFlagSet("ratelimit", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to the JSON config file, for its hosts (default "+defaultConfigPath()+")")
+	all := fs.Bool("all", false, "show every rate-limit resource, not just core (REST) and graphql")
+	fs.Parse(args)
+
+	path, required := resolveConfigPath(*configPath)
+	local, _, err := loadConfig(path, required)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	clients, err := newClients(local)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	status := 0
+	for i, client := range clients {
+		if client.host != "" {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%s:\n", client.host)
+		}
+		resources, err := client.GetRateLimits()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			status = 1
+			continue
+		}
+		fmt.Print(core.FormatRateLimits(core.RateLimitResources(resources, *all), time.Now()))
+	}
+	return status
+}
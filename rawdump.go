@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// dumpedResponse is an API response as written by --dump-raw, one per file.
+type dumpedResponse struct {
+	Method      string              `json:"method"`
+	URL         string              `json:"url"` // relative to the API base URL
+	RequestBody string              `json:"request_body,omitempty"`
+	Status      int                 `json:"status"`
+	Header      map[string][]string `json:"header,omitempty"`
+	Body        json.RawMessage     `json:"body,omitempty"` // a JSON body as-is
+	Text        string              `json:"text,omitempty"` // any other body
+}
+
+// rawDumper writes each response a client receives to a numbered file in
+// dir, with secrets redacted. Requests run concurrently, so files are
+// numbered in the order responses arrive.
+type rawDumper struct {
+	dir     string
+	baseURL string
+	secrets []string
+
+	mu     sync.Mutex
+	n      int
+	warned bool
+}
+
+// newRawDumper creates dir if needed. It must be empty, so a dump never
+// mixes two runs.
+func newRawDumper(dir, baseURL string, secrets []string) (*rawDumper, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		return nil, fmt.Errorf("%s isn't empty", dir)
+	}
+	return &rawDumper{dir: dir, baseURL: baseURL, secrets: secrets}, nil
+}
+
+// record writes resp to the dump, leaving its body to be read again. Failing
+// to is a warning, once: the run goes on.
+func (d *rawDumper) record(req *http.Request, resp *http.Response) {
+	if d == nil {
+		return
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err == nil {
+		err = d.write(req, resp, body)
+	}
+	if err != nil {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if !d.warned {
+			d.warned = true
+			log.Printf("warning: --dump-raw: %s", err)
+		}
+	}
+}
+
+func (d *rawDumper) write(req *http.Request, resp *http.Response, body []byte) error {
+	out := dumpedResponse{
+		Method: req.Method,
+		URL:    core.RedactSecrets(core.RelativeURL(req.URL.String(), d.baseURL), d.secrets),
+		Status: resp.StatusCode,
+	}
+	if req.GetBody != nil {
+		if rc, err := req.GetBody(); err == nil {
+			reqBody, _ := io.ReadAll(rc)
+			rc.Close()
+			out.RequestBody = core.RedactSecrets(string(reqBody), d.secrets)
+		}
+	}
+	for _, name := range core.DumpedHeaders {
+		for _, v := range resp.Header.Values(name) {
+			if out.Header == nil {
+				out.Header = make(map[string][]string)
+			}
+			out.Header[name] = append(out.Header[name], core.RedactSecrets(v, d.secrets))
+		}
+	}
+	redacted := core.RedactSecrets(string(body), d.secrets)
+	if json.Valid([]byte(redacted)) {
+		out.Body = json.RawMessage(redacted)
+	} else {
+		out.Text = redacted
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.n++
+	name := filepath.Join(d.dir, fmt.Sprintf("%05d.json", d.n))
+	d.mu.Unlock()
+	return os.WriteFile(name, append(data, '\n'), 0o600)
+}
+
+// runReplay runs mutemath against the responses in a --dump-raw directory
+// instead of GitHub, taking the same flags as a normal run. Each request gets
+// the dumped responses to the same request in order, then the last again;
+// requests the dump has no response to get a 404.
+func runReplay(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprintf(os.Stderr, "usage: %s replay <dump-dir> [flags]\n\nRuns against the API responses written by --dump-raw; the flags are those of a normal run.\n", progName)
+		return 2
+	}
+	demo, err := newReplayServer(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	defer demo.Close()
+	journalOff = true
+	log.Printf("replay: serving %s at %s; nothing here touches your account", args[0], demo.srv.URL)
+	return runMain(args[1:], demo)
+}
+
+// newReplayServer serves a --dump-raw directory, in the demo server's place.
+func newReplayServer(dir string) (*demoServer, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no dumped responses in %s", dir)
+	}
+	sort.Strings(files)
+	var responses []dumpedResponse
+	var keys []string
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		var r dumpedResponse
+		if err := json.Unmarshal(data, &r); err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		responses = append(responses, r)
+		keys = append(keys, core.ReplayKey(r.Method, r.URL, r.RequestBody))
+	}
+
+	replay := core.NewReplay(keys)
+	var mu sync.Mutex
+	d := &demoServer{started: time.Now().UTC().Truncate(time.Second)}
+	d.srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		key := core.ReplayKey(r.Method, r.URL.RequestURI(), string(body))
+		mu.Lock()
+		i, ok := replay.Next(key)
+		mu.Unlock()
+		if !ok {
+			http.Error(w, `{"message":"Not Found in the dump"}`, http.StatusNotFound)
+			return
+		}
+		resp := responses[i]
+		for name, values := range resp.Header {
+			for _, v := range values {
+				w.Header().Add(name, v)
+			}
+		}
+		w.WriteHeader(resp.Status)
+		if resp.Body != nil {
+			w.Write(resp.Body)
+		} else {
+			io.WriteString(w, resp.Text)
+		}
+	}))
+	return d, nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// reauthPrompt asks, in an interactive one-shot run, for a new token when
+// GitHub refuses a client's token with 401, so the run resumes where it was
+// instead of failing halfway. A run's clients share one, so concurrent
+// requests that all hit the 401 ask once.
+type reauthPrompt struct {
+	mu       sync.Mutex
+	in       *bufio.Scanner
+	declined bool // the user quit; later 401s fail without asking
+}
+
+// newReauthPrompt returns a prompt reading stdin, or nil if stdin isn't a
+// terminal, so nobody is there to answer.
+func newReauthPrompt() *reauthPrompt {
+	fi, err := os.Stdin.Stat()
+	if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	return &reauthPrompt{in: bufio.NewScanner(os.Stdin)}
+}
+
+// renew returns a token for c to replace stale, the one GitHub refused:
+// the token another request already renewed it to, or else one the user
+// logs in for. ok is false if they quit.
+func (r *reauthPrompt) renew(c *GitHubClient, stale string) (token string, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if token := c.currentToken(); token != stale {
+		return token, true
+	}
+	if r.declined {
+		return "", false
+	}
+	hostname := core.APIHost(c.baseURL)
+	fmt.Fprintf(os.Stderr, "\nGitHub refused the token for %s (401: bad or expired token).\n", hostname)
+	for {
+		fmt.Fprintf(os.Stderr, "[l]og in with gh and resume, [r]e-read gh's token (after gh auth login elsewhere), or [q]uit? [l] ")
+		if !r.in.Scan() {
+			fmt.Fprintln(os.Stderr)
+			r.declined = true
+			return "", false
+		}
+		var err error
+		switch strings.ToLower(strings.TrimSpace(r.in.Text())) {
+		case "", "l":
+			token, err = ghWebLogin(hostname)
+		case "r":
+			if token = ghAuthToken(hostname); token == "" {
+				err = fmt.Errorf("gh isn't logged in to %s", hostname)
+			}
+		case "q":
+			r.declined = true
+			return "", false
+		default:
+			continue
+		}
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "  %s\n", err)
+		case token == stale:
+			fmt.Fprintf(os.Stderr, "  gh still has the refused token\n")
+		default:
+			c.setToken(token)
+			fmt.Fprintf(os.Stderr, "Resuming with the new token.\n\n")
+			return token, true
+		}
+	}
+}
+
+// reauthorized returns req with a renewed token, to send again after it got
+// a 401, once the user provides one. It returns nil, leaving the 401 to fail
+// the request, if they quit or req's body can't be sent again.
+func (c *GitHubClient) reauthorized(req *http.Request) (*http.Request, error) {
+	if req.Body != nil && req.GetBody == nil {
+		return nil, nil
+	}
+	stale := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	token, ok := c.reauth.renew(c, stale)
+	if !ok {
+		return nil, nil
+	}
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	retry.Header.Set("Authorization", "Bearer "+token)
+	return retry, nil
+}
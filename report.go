@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// runReport prints a weekly review-load report on each configured host:
+// review requests kept because they named you, team requests muted, from the
+// journal, and the reviews you submitted, from the API.
+func runReport(args []string) int {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to the JSON config file, for its hosts and state (default "+defaultConfigPath()+")")
+	weeks := fs.Int("weeks", 1, "number of weeks to report, newest first")
+	fs.Parse(args)
+	if *weeks < 1 {
+		fmt.Fprintln(os.Stderr, "Error: --weeks must be at least 1")
+		return 1
+	}
+
+	path, required := resolveConfigPath(*configPath)
+	local, _, err := loadConfig(path, required)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if err := useState(local.state); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	clients, err := newClients(local)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	records, err := readJournal()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	now := time.Now()
+	since := now.Add(-time.Duration(*weeks) * 7 * 24 * time.Hour)
+	status := 0
+	for i, client := range clients {
+		if client.host != "" {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%s:\n", client.host)
+		}
+		if err := client.FetchLogin(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			status = 1
+			continue
+		}
+		reviews, err := client.GetCompletedReviews(since)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			status = 1
+			continue
+		}
+		load := core.ReviewLoad(records, reviews, client.host, now, *weeks)
+		teams := core.MutedByTeam(records, client.host, since, now)
+		fmt.Print(core.FormatReviewLoad(client.login, load, teams))
+	}
+	if since.Before(now.Add(-core.JournalRetention)) {
+		fmt.Fprintf(os.Stderr, "note: the journal keeps about %d days of records, so earlier weeks may undercount requests\n", int(core.JournalRetention.Hours()/24))
+	}
+	return status
+}
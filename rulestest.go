@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// ruleTestFile is a rules test case as written, one per JSON file. The
+// sample fields default like config validate's sample flags.
+type ruleTestFile struct {
+	Name           string   `json:"name"`
+	Login          string   `json:"login"`
+	At             string   `json:"at"`
+	Reason         string   `json:"reason"`
+	Repo           string   `json:"repo"`
+	Title          string   `json:"title"`
+	Type           string   `json:"type"`
+	Teams          []string `json:"teams"`
+	Users          []string `json:"users"`
+	Author         string   `json:"author"`
+	Draft          bool     `json:"draft"`
+	Labels         []string `json:"labels"`
+	Body           string   `json:"body"`
+	Assignees      []string `json:"assignees"`
+	Head           string   `json:"head"`
+	Base           string   `json:"base"`
+	Files          []string `json:"files"`
+	ReviewDecision string   `json:"review_decision"`
+	Expect         struct {
+		Action string `json:"action"`
+		Rule   string `json:"rule"`
+	} `json:"expect"`
+}
+
+// runRules runs the rules subcommands; test is the only one.
+func runRules(args []string) int {
+	if len(args) == 0 || args[0] != "test" {
+		fmt.Fprintf(os.Stderr, "usage: %s rules test [--config path] <dir>\n", progName)
+		return 2
+	}
+	fs := flag.NewFlagSet("rules test", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s rules test [flags] <dir>\n\nEach .json file in the directory is a test case: a sample notification and the action it should get.\n\n", progName)
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("config", "", "path to the JSON config file with the rules to test (default "+defaultConfigPath()+")")
+	fs.Parse(args[1:])
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	path, _ := resolveConfigPath(*configPath)
+	local, _, err := loadConfig(path, true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	tests, err := readRuleTests(fs.Arg(0), time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if len(tests) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no .json test cases in %s\n", fs.Arg(0))
+		return 1
+	}
+
+	results := core.RunRuleTests(tests, sampleConfig(local))
+	fmt.Print(core.FormatRuleTestResults(results))
+	for _, r := range results {
+		if !r.Passed() {
+			return 1
+		}
+	}
+	return 0
+}
+
+// readRuleTests reads every .json test case in dir, in name order. Cases
+// without "at" arrive at now.
+func readRuleTests(dir string, now time.Time) ([]core.RuleTest, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var tests []core.RuleTest
+	for _, path := range paths {
+		t, err := readRuleTest(path, now)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		tests = append(tests, t)
+	}
+	return tests, nil
+}
+
+func readRuleTest(path string, now time.Time) (core.RuleTest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return core.RuleTest{}, err
+	}
+	f := ruleTestFile{Reason: "review_requested", Type: "PullRequest", Base: "main"}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return core.RuleTest{}, err
+	}
+	if f.Expect.Action == "" {
+		return core.RuleTest{}, fmt.Errorf("expect.action is required")
+	}
+	want, err := core.ParseAction(f.Expect.Action)
+	if err != nil {
+		return core.RuleTest{}, fmt.Errorf("expect.action: %w", err)
+	}
+	at := now
+	if f.At != "" {
+		if at, err = time.Parse(time.RFC3339, f.At); err != nil {
+			return core.RuleTest{}, fmt.Errorf("at: %w", err)
+		}
+	}
+	name := f.Name
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), ".json")
+	}
+	return core.RuleTest{
+		Name: name,
+		Sample: core.Sample{
+			Reason:         f.Reason,
+			Repo:           f.Repo,
+			Title:          f.Title,
+			Type:           f.Type,
+			Teams:          f.Teams,
+			Users:          f.Users,
+			Author:         f.Author,
+			Draft:          f.Draft,
+			Labels:         f.Labels,
+			Body:           f.Body,
+			Assignees:      f.Assignees,
+			Head:           f.Head,
+			Base:           f.Base,
+			Files:          f.Files,
+			ReviewDecision: f.ReviewDecision,
+		},
+		Login:    f.Login,
+		At:       at,
+		Want:     want,
+		WantRule: f.Expect.Rule,
+	}, nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// secretEnvVars are the settings scrubbed from error reports wherever they appear.
+var secretEnvVars = []string{
+	"GH_TOKEN", "GITHUB_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN",
+	"NTFY_TOKEN", "PUSHOVER_TOKEN", "PUSHOVER_USER",
+	"SLACK_WEBHOOK_URL", "DISCORD_WEBHOOK_URL", "MATRIX_ACCESS_TOKEN",
+	"OCTOBOX_TOKEN", "PAGERDUTY_TOKEN", "OTEL_EXPORTER_OTLP_HEADERS",
+	"MUTEMATH_STATE_KEY", "MUTEMATH_API_TOKEN",
+}
+
+// errorReporter sends panics and persistent cycle errors to a Sentry-compatible
+// service. A nil *errorReporter reports nothing.
+type errorReporter struct {
+	dsn         core.DSN
+	environment string
+	secrets     []string
+	httpClient  *http.Client
+}
+
+// newErrorReporterFromEnv returns a reporter for SENTRY_DSN, or nil if it's unset.
+func newErrorReporterFromEnv() (*errorReporter, error) {
+	raw := os.Getenv("SENTRY_DSN")
+	if raw == "" {
+		return nil, nil
+	}
+	dsn, err := core.ParseDSN(raw)
+	if err != nil {
+		return nil, err
+	}
+	r := &errorReporter{
+		dsn:         dsn,
+		environment: os.Getenv("SENTRY_ENVIRONMENT"),
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, name := range secretEnvVars {
+		if v := os.Getenv(name); v != "" {
+			r.secrets = append(r.secrets, v)
+		}
+	}
+	return r, nil
+}
+
+// Sentry event payload (https://develop.sentry.dev/sdk/data-model/event-payloads/).
+
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Logger      string            `json:"logger"`
+	Release     string            `json:"release"`
+	Environment string            `json:"environment,omitempty"`
+	Message     *sentryMessage    `json:"message,omitempty"`
+	Exception   *sentryExceptions `json:"exception,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+type sentryMessage struct {
+	Formatted string `json:"formatted"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type       string            `json:"type"`
+	Value      string            `json:"value"`
+	Stacktrace *sentryStacktrace `json:"stacktrace,omitempty"`
+}
+
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+type sentryFrame struct {
+	Function string `json:"function"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+}
+
+// CaptureError reports a daemon error, e.g. a streak of failed cycles.
+func (r *errorReporter) CaptureError(msg string) {
+	if r == nil {
+		return
+	}
+	r.send(r.event("error", func(e *sentryEvent) {
+		e.Message = &sentryMessage{Formatted: core.ScrubSecrets(msg, r.secrets)}
+	}))
+}
+
+// CapturePanic reports a panic with the stack it was recovered from.
+func (r *errorReporter) CapturePanic(v any, stack []byte) {
+	if r == nil {
+		return
+	}
+	frames := core.ParseGoStack(string(stack))
+	// Sentry wants the outermost frame first.
+	st := &sentryStacktrace{}
+	for i := len(frames) - 1; i >= 0; i-- {
+		f := frames[i]
+		st.Frames = append(st.Frames, sentryFrame{Function: f.Function, AbsPath: f.File, Lineno: f.Line})
+	}
+	r.send(r.event("fatal", func(e *sentryEvent) {
+		e.Exception = &sentryExceptions{Values: []sentryException{{
+			Type:       "panic",
+			Value:      core.ScrubSecrets(fmt.Sprint(v), r.secrets),
+			Stacktrace: st,
+		}}}
+	}))
+}
+
+// RecoverPanic is deferred at the top of the daemon: it reports a panic, then
+// lets it continue to crash the process.
+func (r *errorReporter) RecoverPanic() {
+	if r == nil {
+		return
+	}
+	if v := recover(); v != nil {
+		r.CapturePanic(v, debug.Stack())
+		panic(v)
+	}
+}
+
+func (r *errorReporter) event(level string, fill func(*sentryEvent)) sentryEvent {
+	e := sentryEvent{
+		EventID:     randomHex(16),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Platform:    "go",
+		Level:       level,
+		Logger:      "mutemath",
+		Release:     "mutemath@" + buildInfo().Version,
+		Environment: r.environment,
+		Tags:        map[string]string{"mode": "daemon"},
+	}
+	fill(&e)
+	return e
+}
+
+// send posts an event as a Sentry envelope. Failures are logged, not fatal.
+func (r *errorReporter) send(e sentryEvent) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("warning: error report: %s", err)
+		return
+	}
+	header, _ := json.Marshal(map[string]string{
+		"event_id": e.EventID,
+		"dsn":      r.dsn.String(),
+		"sent_at":  e.Timestamp,
+	})
+	itemHeader, _ := json.Marshal(map[string]any{"type": "event", "length": len(payload)})
+
+	var body bytes.Buffer
+	body.Write(header)
+	body.WriteByte('\n')
+	body.Write(itemHeader)
+	body.WriteByte('\n')
+	body.Write(payload)
+	body.WriteByte('\n')
+
+	req, err := http.NewRequest("POST", r.dsn.EnvelopeURL(), &body)
+	if err != nil {
+		log.Printf("warning: error report: %s", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", r.dsn.AuthHeader("mutemath/"+buildInfo().Version))
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		log.Printf("warning: error report: %s", err)
+		return
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		log.Printf("warning: error report: unexpected status %d", resp.StatusCode)
+	}
+}
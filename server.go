@@ -0,0 +1,84 @@
+package main
+
+import (
+	"expvar"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// Daemon counters, served at /debug/vars alongside the runtime's memstats.
+var (
+	varCycles        = expvar.NewInt("cycles")
+	varCycleErrors   = expvar.NewInt("cycle_errors")
+	varNotifications = expvar.NewInt("notifications")
+	varMuted         = expvar.NewInt("muted")
+	varDimmed        = expvar.NewInt("dimmed")
+	varArchived      = expvar.NewInt("archived")
+	varLastCycle     = expvar.NewString("last_cycle")
+)
+
+// recordCycleVars updates the /debug/vars counters after a daemon cycle.
+func recordCycleVars(now time.Time, decisions []core.Decision, marked core.MarkCounts, err error) {
+	varCycles.Add(1)
+	if err != nil {
+		varCycleErrors.Add(1)
+	}
+	varNotifications.Add(int64(len(decisions)))
+	varMuted.Add(int64(marked.Mute))
+	varDimmed.Add(int64(marked.Dim))
+	varArchived.Add(int64(marked.Archive))
+	varLastCycle.Set(now.UTC().Format(time.RFC3339))
+}
+
+// startListener serves the daemon's HTTP endpoints in the background: /healthz,
+// plus /debug/pprof and /debug/vars when debug is set, the dashboard at / when
+// dash is set, and the control API under /api/ when control isn't nil. The
+// address is bound before returning, so a port conflict fails startup.
+func startListener(addr string, debug, dash bool, control *daemonControl) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	if debug {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		mux.Handle("/debug/vars", expvar.Handler())
+		if !core.IsLoopbackAddr(addr) {
+			log.Printf("warning: --debug-endpoints on %s is reachable from other hosts; profiles expose process internals", addr)
+		}
+	}
+
+	if dash {
+		mux.HandleFunc("GET /{$}", dashboard.serveDashboard)
+		if !core.IsLoopbackAddr(addr) {
+			log.Printf("warning: --dashboard on %s is reachable from other hosts; it shows notification titles, private repos included", addr)
+		}
+	}
+
+	if control != nil {
+		control.handleAPI(mux)
+		if !core.IsLoopbackAddr(addr) {
+			log.Printf("warning: --api on %s is reachable from other hosts over plain HTTP, bearer token included", addr)
+		}
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	go func() {
+		if err := http.Serve(ln, mux); err != nil {
+			log.Printf("warning: listener: %s", err)
+		}
+	}()
+	return nil
+}
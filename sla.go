@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// escalateUnclaimed unmutes client's team review requests muted at least sla
+// ago whose PRs are still open with no submitted reviews, alerting on each.
+// Threads found reviewed or closed go in settled, keyed by mute, so the
+// daemon checks each mute once.
+func escalateUnclaimed(client *GitHubClient, sla time.Duration, notifiers []notifier, settled map[string]bool, verbose bool) {
+	if journalOff {
+		return
+	}
+	st, err := client.store()
+	if err != nil {
+		log.Printf("warning: review SLA: %s", err)
+		return
+	}
+	records, err := readJournalFrom(st)
+	if err != nil {
+		log.Printf("warning: review SLA: %s", err)
+		return
+	}
+	now := time.Now()
+	for _, r := range core.EscalationCandidates(records, now, sla) {
+		// Several hosts share one journal; each daemon handles its own.
+		key := fmt.Sprintf("%s\x00%s\x00%d", r.Host, r.ThreadID, r.Time.UnixNano())
+		if r.Host != client.host || settled[key] {
+			continue
+		}
+		pr, err := client.GetPullRequest(r.URL)
+		if err != nil {
+			if verbose {
+				log.Printf("warning: review SLA: %s", err)
+			}
+			continue
+		}
+		if pr.State != "open" {
+			settled[key] = true
+			continue
+		}
+		authors, err := client.GetReviewAuthors(r.URL)
+		if err != nil {
+			if verbose {
+				log.Printf("warning: review SLA: %s", err)
+			}
+			continue
+		}
+		if len(authors) > 0 {
+			settled[key] = true
+			continue
+		}
+
+		if core.EscalationUnignores(r) {
+			err = client.UnignoreThread(r.ThreadID)
+		}
+		fmt.Fprintln(stdout, client.rowPrefix()+core.FormatEscalationRow(r, now, err))
+		if err != nil {
+			continue
+		}
+		recordMutation(client, core.RecordEscalation(r, time.Now()))
+		a := core.AlertForEscalation(r, sla)
+		for _, n := range notifiers {
+			if err := n.Notify(a); err != nil {
+				log.Printf("warning: %s", err)
+			}
+		}
+	}
+}
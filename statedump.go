@@ -0,0 +1,75 @@
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// daemonDump is the state a daemon shares for the SIGQUIT dump, updated as
+// its cycles start and finish, so a stuck cycle can't hold the dump up.
+type daemonDump struct {
+	mu   sync.Mutex
+	snap core.DaemonSnapshot
+}
+
+var (
+	dumpsMu   sync.Mutex
+	dumps     []*daemonDump
+	dumpsOnce sync.Once
+)
+
+// registerDump adds a daemon to the SIGQUIT dump, and on first use replaces
+// Go's default SIGQUIT handling, which exits, with logging the dump.
+func registerDump(name string, started time.Time) *daemonDump {
+	d := &daemonDump{snap: core.DaemonSnapshot{Name: name, Started: started}}
+	dumpsMu.Lock()
+	dumps = append(dumps, d)
+	dumpsMu.Unlock()
+	dumpsOnce.Do(func() {
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGQUIT)
+		go func() {
+			for range quit {
+				log.Print(stateDump())
+			}
+		}()
+	})
+	return d
+}
+
+// update changes the daemon's snapshot under its lock.
+func (d *daemonDump) update(f func(s *core.DaemonSnapshot)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	f(&d.snap)
+}
+
+// stateDump renders every daemon's snapshot and every goroutine's stack.
+func stateDump() string {
+	dumpsMu.Lock()
+	snaps := make([]core.DaemonSnapshot, len(dumps))
+	for i, d := range dumps {
+		d.mu.Lock()
+		snaps[i] = d.snap
+		d.mu.Unlock()
+	}
+	dumpsMu.Unlock()
+
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	return core.FormatStateDump(snaps, time.Now()) + "goroutines:\n" + string(buf)
+}
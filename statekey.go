@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// sealedStore seals everything it stores with the state key (see
+// core.Sealer). Plaintext, from before encryption was turned on or planted
+// since, fails to open; sealPlaintext migrates the former once.
+type sealedStore struct {
+	inner  stateStore
+	sealer *core.Sealer
+}
+
+func newSealedStore(inner stateStore, key []byte) (*sealedStore, error) {
+	sealer, err := core.NewSealer(key)
+	if err != nil {
+		return nil, err
+	}
+	return &sealedStore{inner: inner, sealer: sealer}, nil
+}
+
+func (s *sealedStore) seal(name string, plain []byte) []byte {
+	nonce := make([]byte, s.sealer.NonceSize())
+	rand.Read(nonce)
+	return s.sealer.Seal(name, plain, nonce)
+}
+
+func (s *sealedStore) open(name string, data []byte) ([]byte, error) {
+	return s.sealer.Open(name, data)
+}
+
+func (s *sealedStore) Append(log string, record []byte) error {
+	return s.inner.Append(log, s.seal(log, record))
+}
+
+func (s *sealedStore) Records(log string) ([][]byte, error) {
+	sealed, err := s.inner.Records(log)
+	if err != nil {
+		return nil, err
+	}
+	records := make([][]byte, 0, len(sealed))
+	for _, r := range sealed {
+		plain, err := s.open(log, r)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, plain)
+	}
+	return records, nil
+}
+
+func (s *sealedStore) ReplaceRecords(log string, records [][]byte) error {
+	sealed := make([][]byte, 0, len(records))
+	for _, r := range records {
+		sealed = append(sealed, s.seal(log, r))
+	}
+	return s.inner.ReplaceRecords(log, sealed)
+}
+
+func (s *sealedStore) LogSize(log string) (int64, error) {
+	return s.inner.LogSize(log)
+}
+
+func (s *sealedStore) Load(doc string) ([]byte, error) {
+	data, err := s.inner.Load(doc)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return s.open(doc, data)
+}
+
+func (s *sealedStore) Save(doc string, data []byte) error {
+	return s.inner.Save(doc, s.seal(doc, data))
+}
+
+// loadStateKey reads the state key from MUTEMATH_STATE_KEY, or else the OS
+// keychain.
+func loadStateKey() ([]byte, error) {
+	if v := os.Getenv(core.StateKeyEnvVar); v != "" {
+		key, err := core.ParseStateKey(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", core.StateKeyEnvVar, err)
+		}
+		return key, nil
+	}
+	kc, err := core.KeychainFor(runtime.GOOS)
+	if err != nil {
+		return nil, err
+	}
+	out, err := exec.Command(kc.Lookup[0], kc.Lookup[1:]...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("no state key in the keychain (%s: %w); create one with %s config state-key, or set %s", kc.Lookup[0], err, progName, core.StateKeyEnvVar)
+	}
+	return core.ParseStateKey(string(out))
+}
+
+// runStateKey creates a state key and stores it in the OS keychain, or with
+// --print, prints it for MUTEMATH_STATE_KEY.
+func runStateKey(args []string) int {
+	fs := flag.NewFlagSet("config state-key", flag.ExitOnError)
+	printKey := fs.Bool("print", false, "print a new key to set as "+core.StateKeyEnvVar+" instead of storing it in the keychain")
+	fs.Parse(args)
+
+	raw := make([]byte, core.StateKeySize)
+	rand.Read(raw)
+	key := base64.StdEncoding.EncodeToString(raw)
+	if *printKey {
+		fmt.Println(key)
+		return 0
+	}
+
+	kc, err := core.KeychainFor(runtime.GOOS)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	// Replacing a key would make state encrypted with it unreadable.
+	if err := exec.Command(kc.Lookup[0], kc.Lookup[1:]...).Run(); err == nil {
+		fmt.Fprintf(os.Stderr, "Error: the keychain already has a state key; replacing it would make encrypted state unreadable\n")
+		return 1
+	}
+	var cmd *exec.Cmd
+	if kc.StoreStdin {
+		cmd = exec.Command(kc.Store[0], kc.Store[1:]...)
+		cmd.Stdin = strings.NewReader(key)
+	} else {
+		cmd = exec.Command(kc.Store[0], append(kc.Store[1:], key)...)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: store state key with %s: %s\n", kc.Store[0], commandError(strings.TrimSpace(stderr.String()), err))
+		return 1
+	}
+	fmt.Println("Stored a new state key in the keychain. Set \"encrypt\": true under \"state\" in the config file to use it.")
+	return 0
+}
+
+// commandError picks a command's stderr to explain its failure, or else err.
+func commandError(stderr string, err error) error {
+	if stderr != "" {
+		return errors.New(stderr)
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// githubStatusURL is githubstatus.com's Statuspage summary.
+const githubStatusURL = "https://www.githubstatus.com/api/v2/summary.json"
+
+// statusMaxAge is how long a fetched status is reused, so daemons for several
+// hosts, or fast polls, don't each fetch it.
+const statusMaxAge = 30 * time.Second
+
+// Statuspage JSON types — these never leave this file.
+
+type spSummary struct {
+	Incidents []struct {
+		Name      string `json:"name"`
+		Impact    string `json:"impact"`
+		Shortlink string `json:"shortlink"`
+	} `json:"incidents"`
+	Components []struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	} `json:"components"`
+}
+
+// statusWatch checks githubstatus.com before each daemon cycle against
+// github.com. A nil *statusWatch never pauses. Failing to fetch the status
+// doesn't pause either: the status page shouldn't be able to stop mutemath.
+type statusWatch struct {
+	url        string
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	fetched time.Time
+	reason  string
+	pause   bool
+}
+
+func newStatusWatch() *statusWatch {
+	return &statusWatch{url: githubStatusURL, httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Pause reports whether client's cycle should be skipped for a GitHub
+// incident, and why. GHES appliances aren't on githubstatus.com, so they're
+// never paused.
+func (w *statusWatch) Pause(client *GitHubClient) (string, bool) {
+	if w == nil || client.baseURL != core.APIBaseURL("") {
+		return "", false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if time.Since(w.fetched) < statusMaxAge {
+		return w.reason, w.pause
+	}
+	status, err := w.fetch()
+	w.fetched = time.Now()
+	if err != nil {
+		log.Printf("warning: %s", err)
+		w.reason, w.pause = "", false
+		return "", false
+	}
+	w.reason, w.pause = core.IncidentPause(status)
+	return w.reason, w.pause
+}
+
+func (w *statusWatch) fetch() (core.GitHubStatus, error) {
+	resp, err := w.httpClient.Get(w.url)
+	if err != nil {
+		return core.GitHubStatus{}, fmt.Errorf("check githubstatus.com: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return core.GitHubStatus{}, fmt.Errorf("check githubstatus.com: unexpected status %d", resp.StatusCode)
+	}
+	var sp spSummary
+	if err := json.NewDecoder(resp.Body).Decode(&sp); err != nil {
+		return core.GitHubStatus{}, fmt.Errorf("check githubstatus.com: %w", err)
+	}
+	var status core.GitHubStatus
+	for _, inc := range sp.Incidents {
+		status.Incidents = append(status.Incidents, core.StatusIncident{Name: inc.Name, Impact: inc.Impact, URL: inc.Shortlink})
+	}
+	for _, c := range sp.Components {
+		status.Components = append(status.Components, core.StatusComponent{Name: c.Name, Status: c.Status})
+	}
+	return status, nil
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// stateStore keeps mutemath's state between runs: logs of records appended
+// one at a time, such as the mutation journal, and documents replaced
+// whole, such as the deferred queue. Records and documents are JSON; names
+// are short words like "journal".
+type stateStore interface {
+	// Append adds a record to the end of a log.
+	Append(log string, record []byte) error
+	// Records reads a log's records in the order appended; none if the
+	// log doesn't exist.
+	Records(log string) ([][]byte, error)
+	// ReplaceRecords rewrites a log, for compaction.
+	ReplaceRecords(log string, records [][]byte) error
+	// LogSize reports about how many bytes a log takes, to decide when to
+	// compact it.
+	LogSize(log string) (int64, error)
+
+	// Load reads a document; nil if it doesn't exist.
+	Load(doc string) ([]byte, error)
+	// Save replaces a document, atomically.
+	Save(doc string, data []byte) error
+}
+
+// state is the store set from the config file by useState; nil for the
+// default, files in the state dir.
+var state stateStore
+
+// useState selects the store a checked config file's state asks for. Call
+// it once, before anything reads or writes state. Encrypted state needs the
+// state key.
+func useState(spec core.StateSpec) error {
+	// The file backend is the only one; an empty dir is the default.
+	if spec.Dir != "" {
+		state = fileStore{dir: spec.Dir}
+	}
+	if !spec.Encrypt {
+		return nil
+	}
+	inner, err := currentState()
+	if err != nil {
+		return err
+	}
+	key, err := loadStateKey()
+	if err != nil {
+		return fmt.Errorf("encrypted state: %w", err)
+	}
+	sealed, err := newSealedStore(inner, key)
+	if err != nil {
+		return fmt.Errorf("encrypted state: %w", err)
+	}
+	if files, ok := inner.(fileStore); ok {
+		if err := sealPlaintext(files, sealed); err != nil {
+			return fmt.Errorf("encrypted state: %w", err)
+		}
+	}
+	state = sealed
+	return nil
+}
+
+// sealedMarker is a sealed document written when encryption is turned on,
+// so the state dir always holds sealed state from then on.
+const sealedMarker = "sealed"
+
+// sealPlaintext seals the plaintext logs and documents in files when
+// encryption is first turned on: while files holds nothing sealed yet. Once
+// anything is sealed, plaintext is never sealed again, so plaintext planted
+// in the state dir later fails to open rather than being trusted.
+func sealPlaintext(files fileStore, sealed *sealedStore) error {
+	entries, err := os.ReadDir(files.dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	var logs, docs []string
+	for _, e := range entries {
+		name := e.Name()
+		switch {
+		case strings.HasSuffix(name, ".jsonl"):
+			name = strings.TrimSuffix(name, ".jsonl")
+			records, err := files.Records(name)
+			if err != nil {
+				return err
+			}
+			for _, r := range records {
+				if len(bytes.TrimSpace(r)) > 0 && !core.IsPlaintext(r) {
+					return nil // already turned on
+				}
+			}
+			logs = append(logs, name)
+		case strings.HasSuffix(name, ".json"):
+			name = strings.TrimSuffix(name, ".json")
+			data, err := files.Load(name)
+			if err != nil {
+				return err
+			}
+			if len(bytes.TrimSpace(data)) > 0 && !core.IsPlaintext(data) {
+				return nil
+			}
+			docs = append(docs, name)
+		}
+	}
+	for _, name := range logs {
+		records, err := files.Records(name)
+		if err != nil {
+			return err
+		}
+		if err := sealed.ReplaceRecords(name, records); err != nil {
+			return err
+		}
+	}
+	for _, name := range docs {
+		data, err := files.Load(name)
+		if err != nil {
+			return err
+		}
+		if err := sealed.Save(name, data); err != nil {
+			return err
+		}
+	}
+	return sealed.Save(sealedMarker, []byte("{}"))
+}
+
+// currentState returns the store in use.
+func currentState() (stateStore, error) {
+	if state != nil {
+		return state, nil
+	}
+	dir, err := stateDir()
+	if err != nil {
+		return nil, err
+	}
+	return fileStore{dir: dir}, nil
+}
+
+// fileStore keeps each log as a JSON Lines file, name.jsonl, and each
+// document as name.json, in dir.
+type fileStore struct {
+	dir string
+}
+
+func (s fileStore) Append(log string, record []byte) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(s.dir, log+".jsonl"), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(record, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (s fileStore) Records(log string) ([][]byte, error) {
+	f, err := os.Open(filepath.Join(s.dir, log+".jsonl"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records [][]byte
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		records = append(records, bytes.Clone(scanner.Bytes()))
+	}
+	return records, scanner.Err()
+}
+
+func (s fileStore) ReplaceRecords(log string, records [][]byte) error {
+	var buf []byte
+	for _, r := range records {
+		buf = append(append(buf, r...), '\n')
+	}
+	return s.replace(log+".jsonl", buf)
+}
+
+func (s fileStore) LogSize(log string) (int64, error) {
+	info, err := os.Stat(filepath.Join(s.dir, log+".jsonl"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func (s fileStore) Load(doc string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, doc+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (s fileStore) Save(doc string, data []byte) error {
+	return s.replace(doc+".json", data)
+}
+
+// replace writes a file through a temp file and a rename, so readers never
+// see a partial write.
+func (s fileStore) replace(name string, data []byte) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(s.dir, name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// namespacedStore keeps one user's state in a shared store, prefixing each
+// log and document name with the user's: "alice.journal".
+type namespacedStore struct {
+	inner stateStore
+	ns    string
+}
+
+func (s namespacedStore) Append(log string, record []byte) error {
+	return s.inner.Append(s.ns+"."+log, record)
+}
+
+func (s namespacedStore) Records(log string) ([][]byte, error) {
+	return s.inner.Records(s.ns + "." + log)
+}
+
+func (s namespacedStore) ReplaceRecords(log string, records [][]byte) error {
+	return s.inner.ReplaceRecords(s.ns+"."+log, records)
+}
+
+func (s namespacedStore) LogSize(log string) (int64, error) {
+	return s.inner.LogSize(s.ns + "." + log)
+}
+
+func (s namespacedStore) Load(doc string) ([]byte, error) {
+	return s.inner.Load(s.ns + "." + doc)
+}
+
+func (s namespacedStore) Save(doc string, data []byte) error {
+	return s.inner.Save(s.ns+"."+doc, data)
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// runSubscription shows a thread's subscription and, with --ignore or
+// --subscribe, changes it.
+func runSubscription(args []string) int {
+	fs := flag.NewFlagSet("subscription", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s subscription [flags] <thread-id | PR URL | org/repo#number>\n\n", progName)
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("config", "", "path to the JSON config file, for its hosts (default "+defaultConfigPath()+")")
+	host := fs.String("host", "", "with several hosts in the config file, the host the thread is on (default the PR URL's host, or the first)")
+	ignore := fs.Bool("ignore", false, "ignore the thread: no more notifications about it")
+	subscribe := fs.Bool("subscribe", false, "subscribe to the thread, undoing an ignore")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	if *ignore && *subscribe {
+		fmt.Fprintf(os.Stderr, "Error: --ignore and --subscribe can't be used together\n")
+		return 1
+	}
+	target, err := core.ParseThreadTarget(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	path, required := resolveConfigPath(*configPath)
+	local, _, err := loadConfig(path, required)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	clients, err := newClients(local)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	client := clients[0]
+	if h := cmp.Or(*host, target.Host); h != "" && len(local.hosts) > 0 {
+		i := slices.IndexFunc(local.hosts, func(s core.HostSpec) bool { return strings.EqualFold(s.Host, h) })
+		if i < 0 {
+			fmt.Fprintf(os.Stderr, "Error: host %s isn't in the config file's hosts\n", h)
+			return 1
+		}
+		client = clients[i]
+	}
+
+	var n core.Notification
+	if target.ThreadID != "" {
+		n, err = client.GetThread(target.ThreadID)
+	} else {
+		n, err = client.FindPRThread(target.PR)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	switch {
+	case *ignore:
+		err = client.IgnoreThread(n.ID)
+	case *subscribe:
+		err = client.UnignoreThread(n.ID)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	sub, err := client.GetThreadSubscription(n.ID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	fmt.Print(core.FormatSubscription(n, sub, time.Now()))
+	return 0
+}
+
+// runSubscriptions lists the repositories the user watches, noisiest first
+// by the journal's count of their notifications, and with --edit lets them
+// switch noisy ones to participating only.
+func runSubscriptions(args []string) int {
+	fs := flag.NewFlagSet("subscriptions", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to the JSON config file, for its hosts and state (default "+defaultConfigPath()+")")
+	host := fs.String("host", "", "with several hosts in the config file, the host to audit (default the first)")
+	days := fs.Int("days", int(core.JournalRetention.Hours()/24), "count notifications over the last this many days")
+	edit := fs.Bool("edit", false, "open $EDITOR on the list to switch repos to participating only, then apply it")
+	fs.Parse(args)
+	if *days < 1 {
+		fmt.Fprintln(os.Stderr, "Error: --days must be at least 1")
+		return 1
+	}
+
+	path, required := resolveConfigPath(*configPath)
+	local, _, err := loadConfig(path, required)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if err := useState(local.state); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	clients, err := newClients(local)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	client := clients[0]
+	if *host != "" {
+		i := slices.IndexFunc(local.hosts, func(h core.HostSpec) bool { return strings.EqualFold(h.Host, *host) })
+		if i < 0 {
+			fmt.Fprintf(os.Stderr, "Error: host %s isn't in the config file's hosts\n", *host)
+			return 1
+		}
+		client = clients[i]
+	}
+
+	subs, err := client.ListWatchedRepos()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	records, err := readJournal()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	subs = core.CountRepoNotifications(subs, records, client.host, time.Now().AddDate(0, 0, -*days))
+	if !*edit {
+		fmt.Print(core.FormatSubscriptionAudit(subs, *days))
+		return 0
+	}
+
+	edited, err := editText("mutemath-subscriptions-*.txt", core.FormatSubscriptionPlan(subs, *days))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	repos, err := core.ParseSubscriptionPlan(edited, subs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if len(repos) == 0 {
+		fmt.Println("No subscriptions changed.")
+		return 0
+	}
+	unwatched, errCount := 0, 0
+	for _, repo := range repos {
+		err := client.UnwatchRepo(repo)
+		fmt.Fprintln(stdout, core.FormatUnwatchRow(repo, false, true, "", err))
+		if err != nil {
+			errCount++
+		} else {
+			unwatched++
+		}
+	}
+	fmt.Fprintln(stdout, core.FormatUnwatchSummary(unwatched, errCount, false, true))
+	if errCount > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runUnwatch stops watching, or with --ignore ignores, every watched repo
+// matching --match. Without --apply it only prints what it would change.
+func runUnwatch(args []string) int {
+	fs := flag.NewFlagSet("unwatch", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s unwatch --match <org/repo globs> [flags]\n\n", progName)
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("config", "", "path to the JSON config file, for its hosts (default "+defaultConfigPath()+")")
+	host := fs.String("host", "", "with several hosts in the config file, the host whose repos to change (default the first)")
+	match := fs.String("match", "", "comma-separated org/repo globs of the watched repos to change, e.g. 'acme/archive-*'")
+	ignore := fs.Bool("ignore", false, "ignore the repos, instead of only getting notified when participating or @mentioned")
+	apply := fs.Bool("apply", false, "change the subscriptions (default dry run)")
+	fs.Parse(args)
+	patterns := splitList(*match)
+	if len(patterns) == 0 || fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+	for _, p := range patterns {
+		if err := core.CheckGlob(p); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --match %q: %s\n", p, err)
+			return 1
+		}
+	}
+
+	path, required := resolveConfigPath(*configPath)
+	local, _, err := loadConfig(path, required)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	clients, err := newClients(local)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	client := clients[0]
+	if *host != "" {
+		i := slices.IndexFunc(local.hosts, func(h core.HostSpec) bool { return strings.EqualFold(h.Host, *host) })
+		if i < 0 {
+			fmt.Fprintf(os.Stderr, "Error: host %s isn't in the config file's hosts\n", *host)
+			return 1
+		}
+		client = clients[i]
+	}
+
+	names, err := client.ListWatchedRepoNames()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	repos := core.MatchRepos(names, patterns)
+	if len(repos) == 0 {
+		fmt.Printf("No watched repos match %s.\n", *match)
+		return 0
+	}
+	changed, errCount := 0, 0
+	for _, repo := range repos {
+		sub, found, err := client.GetRepoSubscription(repo)
+		if err == nil && !found {
+			continue
+		}
+		skip := ""
+		if err == nil {
+			skip = core.UnwatchSkip(sub, *ignore)
+		}
+		if err == nil && skip == "" && *apply {
+			if *ignore {
+				err = client.IgnoreRepo(repo)
+			} else {
+				err = client.UnwatchRepo(repo)
+			}
+		}
+		fmt.Fprintln(stdout, core.FormatUnwatchRow(repo, *ignore, *apply, skip, err))
+		switch {
+		case err != nil:
+			errCount++
+		case skip == "":
+			changed++
+		}
+	}
+	fmt.Fprintln(stdout, core.FormatUnwatchSummary(changed, errCount, *ignore, *apply))
+	if errCount > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// summaryFile is the JSON written by --summary-file.
+type summaryFile struct {
+	Host            string            `json:"host,omitempty"`
+	FinishedAt      time.Time         `json:"finished_at"`
+	DurationSeconds float64           `json:"duration_seconds"`
+	Mode            string            `json:"mode"`
+	Apply           bool              `json:"apply"`
+	NotModified     bool              `json:"not_modified"`
+	Scanned         int               `json:"scanned"`
+	Muted           int               `json:"muted"`
+	Dimmed          int               `json:"dimmed"`
+	Archived        int               `json:"archived"`
+	Kept            int               `json:"kept"`
+	Skipped         int               `json:"skipped"`
+	Errors          int               `json:"errors"`
+	Error           string            `json:"error,omitempty"`
+	RateLimit       *summaryRateLimit `json:"rate_limit,omitempty"`
+	Rules           []summaryRuleHit  `json:"rules,omitempty"`
+	Orgs            []summaryOrg      `json:"orgs,omitempty"`
+}
+
+type summaryOrg struct {
+	Org      string `json:"org"`
+	Kept     int    `json:"kept"`
+	Skipped  int    `json:"skipped"`
+	Muted    int    `json:"muted"`
+	Dimmed   int    `json:"dimmed"`
+	Archived int    `json:"archived"`
+}
+
+type summaryRuleHit struct {
+	Name    string `json:"name"`
+	Matched int    `json:"matched"`
+}
+
+type summaryRateLimit struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// writeSummaryFile replaces the file at path with s, atomically, so a reader
+// never sees a partial summary.
+func writeSummaryFile(path string, s core.RunSummary) error {
+	out := summaryFile{
+		Host:            s.Host,
+		FinishedAt:      s.Finished.UTC(),
+		DurationSeconds: s.Duration.Seconds(),
+		Mode:            s.Mode.ActionLabelLower(),
+		Apply:           s.Applied,
+		NotModified:     s.NotModified,
+		Scanned:         s.Scanned,
+		Muted:           s.Muted,
+		Dimmed:          s.Dimmed,
+		Archived:        s.Archived,
+		Kept:            s.Kept,
+		Skipped:         s.Skipped,
+		Errors:          s.Errors,
+		Error:           s.Err,
+	}
+	if s.RateLimit.Limit > 0 {
+		out.RateLimit = &summaryRateLimit{Limit: s.RateLimit.Limit, Remaining: s.RateLimit.Remaining, Reset: s.RateLimit.Reset}
+	}
+	for _, h := range s.Rules {
+		out.Rules = append(out.Rules, summaryRuleHit{Name: h.Name, Matched: h.Count})
+	}
+	for _, o := range s.Orgs {
+		out.Orgs = append(out.Orgs, summaryOrg{Org: o.Org, Kept: o.Keep, Skipped: o.Skip, Muted: o.Mute, Dimmed: o.Dim, Archived: o.Archive})
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".mutemath-summary-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// finishSummary fills in the parts of a run's summary that come from the
+// client and the run as a whole.
+func finishSummary(client *GitHubClient, s core.RunSummary, mode core.Mode, apply bool, start time.Time) core.RunSummary {
+	s.Host = client.host
+	s.Finished = time.Now()
+	s.Duration = s.Finished.Sub(start)
+	s.Mode = mode
+	s.Applied = apply
+	s.RateLimit = client.RateLimit()
+	return s
+}
+
+// recordSummary writes a run's summary when --summary-file is set. With
+// several hosts or users, each gets its own file. A failed write is only a
+// warning; it never fails the run.
+func recordSummary(path string, client *GitHubClient, s core.RunSummary) {
+	if path == "" {
+		return
+	}
+	if err := writeSummaryFile(core.SummaryPathForHost(path, client.name()), s); err != nil {
+		log.Printf("warning: write summary file: %s", err)
+	}
+}
+
+// ledgerMu serializes ledger appends from concurrent daemons.
+var ledgerMu sync.Mutex
+
+// recordLedger appends a run's row to the --ledger CSV file, writing the
+// header first if the file is new or empty. All hosts and users share the
+// file, told apart by its inbox column. A failed write is only a warning.
+func recordLedger(path string, client *GitHubClient, s core.RunSummary) {
+	if path == "" {
+		return
+	}
+	if err := appendLedger(path, core.LedgerRow(s, client.name())); err != nil {
+		log.Printf("warning: ledger: %s", err)
+	}
+}
+
+func appendLedger(path string, row []string) error {
+	ledgerMu.Lock()
+	defer ledgerMu.Unlock()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	// Build the lines first so a row is written with a single append.
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if info.Size() == 0 {
+		w.Write(core.LedgerHeader)
+	}
+	w.Write(row)
+	w.Flush()
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,417 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// OTLP span kinds and status codes (opentelemetry-proto trace.proto).
+const (
+	spanKindInternal = 1
+	spanKindClient   = 3
+	statusCodeError  = 2
+)
+
+// telemetry records spans and metrics and exports them over OTLP/HTTP with the
+// JSON encoding. A nil *telemetry records nothing, so callers don't check
+// whether --otel is on.
+type telemetry struct {
+	tracesURL  string
+	metricsURL string
+	headers    map[string]string
+	resource   []otlpAttr
+	httpClient *http.Client
+	start      time.Time // start of the cumulative metrics
+
+	mu       sync.Mutex
+	current  *span // innermost open span
+	finished []otlpSpan
+	counters map[metricKey]int64
+	gauges   map[string]float64
+}
+
+// metricKey identifies a counter's time series: its name plus one optional attribute.
+type metricKey struct {
+	name, attr, value string
+}
+
+// span is an open span. End records it; a nil *span is a no-op.
+type span struct {
+	t       *telemetry
+	parent  *span
+	traceID string
+	spanID  string
+	name    string
+	kind    int
+	start   time.Time
+	attrs   []otlpAttr
+}
+
+// OTLP JSON encoding (https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding).
+// IDs are hex and 64-bit integers are strings.
+
+type otlpAttr struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttr `json:"attributes"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []otlpAttr `json:"attributes,omitempty"`
+	Status            otlpStatus `json:"status"`
+}
+
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpMetrics struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpMetric struct {
+	Name  string     `json:"name"`
+	Unit  string     `json:"unit,omitempty"`
+	Sum   *otlpSum   `json:"sum,omitempty"`
+	Gauge *otlpGauge `json:"gauge,omitempty"`
+}
+
+type otlpSum struct {
+	AggregationTemporality int             `json:"aggregationTemporality"` // 2 = cumulative
+	IsMonotonic            bool            `json:"isMonotonic"`
+	DataPoints             []otlpDataPoint `json:"dataPoints"`
+}
+
+type otlpGauge struct {
+	DataPoints []otlpDataPoint `json:"dataPoints"`
+}
+
+type otlpDataPoint struct {
+	Attributes        []otlpAttr `json:"attributes,omitempty"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	AsInt             *string    `json:"asInt,omitempty"`
+	AsDouble          *float64   `json:"asDouble,omitempty"`
+}
+
+// metricUnits gives each metric's UCUM unit.
+var metricUnits = map[string]string{
+	"mutemath.cycles":              "{cycle}",
+	"mutemath.notifications":       "{notification}",
+	"mutemath.mutation.errors":     "{error}",
+	"mutemath.api.requests":        "{request}",
+	"mutemath.cycle.duration":      "s",
+	"mutemath.ratelimit.remaining": "{request}",
+}
+
+// newTelemetryFromEnv configures the exporter from the standard OTEL_*
+// environment variables.
+func newTelemetryFromEnv() (*telemetry, error) {
+	if err := core.CheckOTLPProtocol(os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")); err != nil {
+		return nil, err
+	}
+	headers, err := core.ParseOTLPHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	if err != nil {
+		return nil, err
+	}
+	service := os.Getenv("OTEL_SERVICE_NAME")
+	if service == "" {
+		service = "mutemath"
+	}
+	base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	return &telemetry{
+		tracesURL:  core.OTLPSignalURL(base, os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"), "traces"),
+		metricsURL: core.OTLPSignalURL(base, os.Getenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT"), "metrics"),
+		headers:    headers,
+		resource:   []otlpAttr{stringAttr("service.name", service), stringAttr("service.version", buildInfo().Version)},
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		start:      time.Now(),
+		counters:   make(map[metricKey]int64),
+		gauges:     make(map[string]float64),
+	}, nil
+}
+
+func stringAttr(key, value string) otlpAttr {
+	return otlpAttr{Key: key, Value: otlpValue{StringValue: &value}}
+}
+
+func intAttr(key string, value int) otlpAttr {
+	s := strconv.Itoa(value)
+	return otlpAttr{Key: key, Value: otlpValue{IntValue: &s}}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// Start opens a span as a child of the innermost open span, or as the root
+// of a new trace.
+func (t *telemetry) Start(name string, attrs ...otlpAttr) *span {
+	return t.open(name, spanKindInternal, attrs)
+}
+
+// StartClient opens a span for an outgoing HTTP request.
+func (t *telemetry) StartClient(method, url string) *span {
+	return t.open(core.SpanNameForRequest(method, url), spanKindClient,
+		[]otlpAttr{stringAttr("http.request.method", method), stringAttr("url.full", url)})
+}
+
+func (t *telemetry) open(name string, kind int, attrs []otlpAttr) *span {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := &span{t: t, parent: t.current, spanID: randomHex(8), name: name, kind: kind, start: time.Now(), attrs: attrs}
+	if s.parent != nil {
+		s.traceID = s.parent.traceID
+	} else {
+		s.traceID = randomHex(16)
+	}
+	// Client spans are leaves, and may be opened from the fetch goroutine, so
+	// they never become the parent of later spans.
+	if kind != spanKindClient {
+		t.current = s
+	}
+	return s
+}
+
+// SetAttr adds an attribute to an open span.
+func (s *span) SetAttr(a otlpAttr) {
+	if s == nil {
+		return
+	}
+	s.attrs = append(s.attrs, a)
+}
+
+// End closes the span, marking it failed if err is non-nil.
+func (s *span) End(err error) {
+	if s == nil {
+		return
+	}
+	t := s.t
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := otlpSpan{
+		TraceID:           s.traceID,
+		SpanID:            s.spanID,
+		Name:              s.name,
+		Kind:              s.kind,
+		StartTimeUnixNano: unixNano(s.start),
+		EndTimeUnixNano:   unixNano(time.Now()),
+		Attributes:        s.attrs,
+	}
+	if s.parent != nil {
+		out.ParentSpanID = s.parent.spanID
+	}
+	if err != nil {
+		out.Status = otlpStatus{Code: statusCodeError, Message: err.Error()}
+	}
+	t.finished = append(t.finished, out)
+	if t.current == s {
+		t.current = s.parent
+	}
+}
+
+// Add increments a cumulative counter. attr and value may be empty.
+func (t *telemetry) Add(name, attr, value string, n int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.counters[metricKey{name, attr, value}] += n
+}
+
+// Set records a gauge's current value.
+func (t *telemetry) Set(name string, v float64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.gauges[name] = v
+}
+
+// RecordCycle records one poll cycle's metrics.
+func (t *telemetry) RecordCycle(decisions []core.Decision, errCount int, dur time.Duration, rl core.RateLimit) {
+	if t == nil {
+		return
+	}
+	t.Add("mutemath.cycles", "", "", 1)
+	for _, d := range decisions {
+		t.Add("mutemath.notifications", "action", strings.ToLower(d.Action.String()), 1)
+	}
+	t.Add("mutemath.mutation.errors", "", "", int64(errCount))
+	t.Set("mutemath.cycle.duration", dur.Seconds())
+	if rl.Limit > 0 {
+		t.Set("mutemath.ratelimit.remaining", float64(rl.Remaining))
+	}
+}
+
+// Flush exports the spans finished since the last flush and the current
+// metric values. Export failures are logged but never fail a cycle; spans
+// that couldn't be sent are dropped.
+func (t *telemetry) Flush() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	spans := t.finished
+	t.finished = nil
+	metrics := t.metricsLocked(time.Now())
+	t.mu.Unlock()
+
+	scope := otlpScope{Name: "github.com/lmarburger/mutemath", Version: buildInfo().Version}
+	resource := otlpResource{Attributes: t.resource}
+	var errs []error
+	if len(spans) > 0 {
+		err := t.post(t.tracesURL, otlpTraces{ResourceSpans: []otlpResourceSpans{{
+			Resource:   resource,
+			ScopeSpans: []otlpScopeSpans{{Scope: scope, Spans: spans}},
+		}}})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("export traces: %w", err))
+		}
+	}
+	if len(metrics) > 0 {
+		err := t.post(t.metricsURL, otlpMetrics{ResourceMetrics: []otlpResourceMetrics{{
+			Resource:     resource,
+			ScopeMetrics: []otlpScopeMetrics{{Scope: scope, Metrics: metrics}},
+		}}})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("export metrics: %w", err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		log.Printf("warning: otel: %s", err)
+	}
+}
+
+// metricsLocked snapshots counters and gauges as OTLP metrics, sorted by name.
+func (t *telemetry) metricsLocked(now time.Time) []otlpMetric {
+	keys := make([]metricKey, 0, len(t.counters))
+	for k := range t.counters {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		return a.value < b.value
+	})
+
+	var metrics []otlpMetric
+	for _, k := range keys {
+		if len(metrics) == 0 || metrics[len(metrics)-1].Name != k.name {
+			metrics = append(metrics, otlpMetric{Name: k.name, Unit: metricUnits[k.name],
+				Sum: &otlpSum{AggregationTemporality: 2, IsMonotonic: true}})
+		}
+		n := strconv.FormatInt(t.counters[k], 10)
+		dp := otlpDataPoint{StartTimeUnixNano: unixNano(t.start), TimeUnixNano: unixNano(now), AsInt: &n}
+		if k.attr != "" {
+			dp.Attributes = []otlpAttr{stringAttr(k.attr, k.value)}
+		}
+		sum := metrics[len(metrics)-1].Sum
+		sum.DataPoints = append(sum.DataPoints, dp)
+	}
+	for name, v := range t.gauges {
+		metrics = append(metrics, otlpMetric{Name: name, Unit: metricUnits[name], Gauge: &otlpGauge{
+			DataPoints: []otlpDataPoint{{TimeUnixNano: unixNano(now), AsDouble: &v}},
+		}})
+	}
+	sort.SliceStable(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+	return metrics
+}
+
+func (t *telemetry) post(url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: unexpected status %d", url, resp.StatusCode)
+	}
+	return nil
+}
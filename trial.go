@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// trialMu keeps the daemons of several hosts, which share the trial, from
+// each sending its digest.
+var trialMu sync.Mutex
+
+// trialDoc is a trial as stored in the "trial" state document.
+type trialDoc struct {
+	Started    time.Time `json:"started"`
+	Until      time.Time `json:"until"`
+	LastDigest time.Time `json:"last_digest,omitzero"`
+}
+
+// runTrial shows the --trial period's status, rolls back everything it
+// muted, or resets it so the next --trial run starts a new one.
+func runTrial(args []string) int {
+	fs := flag.NewFlagSet("trial", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s trial [rollback [--apply] | reset] [flags]\n\n", progName)
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("config", "", "path to the JSON config file, for its hosts and state (default "+defaultConfigPath()+")")
+	apply := fs.Bool("apply", false, "with rollback, undo the mutes (default is to preview them)")
+	eventLogPath := fs.String("event-log", "", "with rollback, append each undo as a JSON line to this file")
+	cmd := ""
+	if len(args) > 0 && (args[0] == "rollback" || args[0] == "reset") {
+		cmd, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if fs.NArg() > 0 || (*apply && cmd != "rollback") {
+		fs.Usage()
+		return 2
+	}
+
+	path, required := resolveConfigPath(*configPath)
+	local, _, err := loadConfig(path, required)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if err := useState(local.state); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	st, err := currentState()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	t, found, err := loadTrial(st)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if !found {
+		fmt.Printf("No trial. Start one with %s --trial 7d.\n", progName)
+		return 0
+	}
+	if cmd == "reset" {
+		if err := st.Save("trial", nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		fmt.Println("Trial reset: the next --trial run starts a new one. Its mutes stay in the journal for mutemath undo.")
+		return 0
+	}
+
+	records, err := readJournal()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	now := time.Now()
+	mutes := core.TrialMutes(records, t, now)
+	if cmd == "" {
+		fmt.Print(core.FormatTrialStatus(t, len(mutes), now))
+		return 0
+	}
+	if *apply && t.Active(now) {
+		// End the trial first, so no --trial run mutes again meanwhile.
+		t.Until = now
+		if err := saveTrial(st, t); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+	}
+	if len(mutes) == 0 {
+		fmt.Println("Nothing to roll back.")
+		return 0
+	}
+	return undoMutes(local, mutes, *apply, *eventLogPath, now)
+}
+
+// loadTrial reads the trial from st. found is false if none was started.
+func loadTrial(st stateStore) (t core.Trial, found bool, err error) {
+	data, err := st.Load("trial")
+	if err != nil || len(data) == 0 {
+		return core.Trial{}, false, err
+	}
+	var doc trialDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return core.Trial{}, false, fmt.Errorf("trial: %w", err)
+	}
+	return core.Trial{Started: doc.Started, Until: doc.Until, LastDigest: doc.LastDigest}, true, nil
+}
+
+func saveTrial(st stateStore, t core.Trial) error {
+	data, err := json.Marshal(trialDoc{Started: t.Started, Until: t.Until, LastDigest: t.LastDigest})
+	if err != nil {
+		return err
+	}
+	return st.Save("trial", data)
+}
+
+// beginTrial returns the trial in st, starting one of length at now if none
+// was started.
+func beginTrial(st stateStore, length time.Duration, now time.Time) (core.Trial, error) {
+	t, found, err := loadTrial(st)
+	if err != nil || found {
+		return t, err
+	}
+	t = core.NewTrial(now, length)
+	if err := saveTrial(st, t); err != nil {
+		return core.Trial{}, err
+	}
+	log.Printf("trial started: muting until %s, with a daily digest; undo it all with %s trial rollback --apply", t.Until.Format(time.RFC1123), progName)
+	return t, nil
+}
+
+// trialApplies reports whether a --trial daemon's cycle at now still
+// applies, given whether the last one did, logging when the trial has ended
+// or been rolled back. Failing to read the trial is only a warning.
+func trialApplies(st stateStore, now time.Time, apply bool) bool {
+	t, found, err := loadTrial(st)
+	if err != nil {
+		log.Printf("warning: %s", err)
+		return apply
+	}
+	switch {
+	case apply && !found:
+		log.Printf("trial reset: not muting until %s restarts", progName)
+		return false
+	case apply && !t.Active(now):
+		logTrialEnded(t)
+		return false
+	}
+	return apply
+}
+
+func logTrialEnded(t core.Trial) {
+	log.Printf("trial ended %s: not muting. Switch --trial to --apply to keep muting, or undo it all with %s trial rollback --apply", t.Until.Format(time.RFC1123), progName)
+}
+
+// sendTrialDigest sends the trial's daily digest of mutes when one is due,
+// through the --notify sinks, or printed without any. Failing to is only a
+// warning; the next run tries again.
+func sendTrialDigest(st stateStore, notifiers []notifier, now time.Time) {
+	trialMu.Lock()
+	defer trialMu.Unlock()
+	t, found, err := loadTrial(st)
+	if err != nil {
+		log.Printf("warning: trial digest: %s", err)
+		return
+	}
+	if !found || !t.DigestDue(now) {
+		return
+	}
+	records, err := readJournalFrom(st)
+	if err != nil {
+		log.Printf("warning: trial digest: %s", err)
+		return
+	}
+	a := core.TrialDigest(records, t, now)
+	if len(notifiers) == 0 {
+		fmt.Fprintf(stdout, "\n%s\n%s\n", a.Title, a.Body)
+	}
+	for _, n := range notifiers {
+		if err := n.Notify(a); err != nil {
+			log.Printf("warning: trial digest: %s", err)
+		}
+	}
+	t.LastDigest = now
+	if err := saveTrial(st, t); err != nil {
+		log.Printf("warning: trial digest: %s", err)
+	}
+}
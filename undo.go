@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// runUndo reverses recent mutes from the mutation journal: threads are
+// subscribed to again. Like a run, it only previews unless --apply is set.
+func runUndo(args []string) int {
+	fs := flag.NewFlagSet("undo", flag.ExitOnError)
+	since := fs.Duration("since", 0, "undo mutes made within this long (e.g. 2h)")
+	last := fs.Int("last", 0, "undo the last N mutes")
+	apply := fs.Bool("apply", false, "undo the mutes (default is to preview them)")
+	configPath := fs.String("config", "", "path to the JSON config file, for its hosts and state (default "+defaultConfigPath()+")")
+	eventLogPath := fs.String("event-log", "", "append each undo as a JSON line to this file")
+	fs.Parse(args)
+	if *since <= 0 && *last <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: undo needs --since or --last\n")
+		return 1
+	}
+
+	cfgPath, required := resolveConfigPath(*configPath)
+	local, _, err := loadConfig(cfgPath, required)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if err := useState(local.state); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	records, err := readJournal()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	now := time.Now()
+	candidates := core.UndoCandidates(records, now, *since, *last)
+	if len(candidates) == 0 {
+		fmt.Println("Nothing to undo.")
+		return 0
+	}
+
+	return undoMutes(local, candidates, *apply, *eventLogPath, now)
+}
+
+// undoMutes undoes the journal's mutes in candidates, or without apply
+// previews them.
+func undoMutes(local localConfig, candidates []core.MutationRecord, apply bool, eventLogPath string, now time.Time) int {
+	if !apply {
+		fmt.Println("DRY RUN — nothing will be undone (use --apply to execute)")
+		fmt.Println()
+		for _, r := range candidates {
+			fmt.Println(core.FormatUndoRow(r, now, nil))
+		}
+		fmt.Println(core.FormatUndoSummary(len(candidates), 0, false))
+		return 0
+	}
+
+	if eventLogPath != "" {
+		var err error
+		eventLog, err = openEventLog(eventLogPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --event-log: %s\n", err)
+			return 1
+		}
+		defer eventLog.Close()
+	}
+
+	lock, err := acquireRunLock(context.Background(), false, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	defer lock.Release()
+
+	clients, err := newClients(local)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	byHost := make(map[string]*GitHubClient, len(clients))
+	for _, c := range clients {
+		byHost[c.host] = c
+	}
+
+	undone, errCount := 0, 0
+	for _, r := range candidates {
+		client, ok := byHost[r.Host]
+		if !ok {
+			err = fmt.Errorf("host %s isn't in the config file's hosts", r.Host)
+		} else if r.Ignored {
+			// A thread that was already ignored before the mute stays ignored.
+			err = client.UnignoreThread(r.ThreadID)
+		}
+		if err == nil {
+			recordMutation(client, core.RecordUndo(r, time.Now()))
+			undone++
+		} else {
+			errCount++
+		}
+		fmt.Println(core.FormatUndoRow(r, now, err))
+		err = nil
+	}
+	fmt.Println(core.FormatUndoSummary(undone, errCount, true))
+	fmt.Println("GitHub's API can't mark threads unread again. Undone threads notify you on new activity; to find them now, filter your notifications by is:read (or is:done).")
+	if errCount > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// version is set at release build time with -ldflags "-X main.version=v1.2.3".
+var version = "dev"
+
+// releaseRepo is where release binaries are published.
+const releaseRepo = "lmarburger/mutemath"
+
+func buildInfo() core.BuildInfo {
+	b := core.BuildInfo{
+		Version:   version,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+	// `go install ...@vX.Y.Z` records the module version instead of ldflags.
+	if b.Version == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		b.Version = info.Main.Version
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			b.Commit = s.Value
+		case "vcs.time":
+			b.Date = s.Value
+		case "vcs.modified":
+			b.Dirty = s.Value == "true"
+		}
+	}
+	return b
+}
+
+func runVersion() int {
+	fmt.Print(core.FormatVersion(buildInfo()))
+	return 0
+}
+
+// runUpdate replaces the running binary with the latest release after
+// verifying its checksum.
+func runUpdate(args []string) int {
+	fs := flag.NewFlagSet("update", flag.ExitOnError)
+	check := fs.Bool("check", false, "only report whether an update is available")
+	force := fs.Bool("force", false, "install the latest release even if it isn't newer")
+	fs.Parse(args)
+
+	current := buildInfo().Version
+	// Releases live on github.com regardless of GH_HOST, and need no token.
+	client := NewGitHubClient("", core.APIBaseURL(""))
+
+	release, err := client.LatestRelease(releaseRepo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	available, err := core.UpdateAvailable(current, release.Tag, *force)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if !available {
+		fmt.Printf("mutemath %s is up to date\n", current)
+		return 0
+	}
+	if *check {
+		fmt.Printf("Update available: %s -> %s (run `%s update` to install)\n", current, release.Tag, progName)
+		return 0
+	}
+
+	if err := installRelease(client, release); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	fmt.Printf("Updated mutemath %s -> %s\n", current, release.Tag)
+	return 0
+}
+
+// installRelease downloads the platform binary next to the running executable,
+// verifies it against checksums.txt, and renames it into place.
+func installRelease(client *GitHubClient, release core.Release) error {
+	name := core.ReleaseAssetName(runtime.GOOS, runtime.GOARCH)
+	asset, ok := core.FindAsset(release, name)
+	if !ok {
+		return fmt.Errorf("release %s has no binary for %s/%s", release.Tag, runtime.GOOS, runtime.GOARCH)
+	}
+	sumsAsset, ok := core.FindAsset(release, core.ChecksumsAssetName)
+	if !ok {
+		return fmt.Errorf("release %s has no %s; refusing to install unverified binary", release.Tag, core.ChecksumsAssetName)
+	}
+
+	var sums bytes.Buffer
+	if err := client.DownloadAsset(sumsAsset.URL, &sums); err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate running binary: %w", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return fmt.Errorf("locate running binary: %w", err)
+	}
+
+	// Download into the same directory so the final rename is atomic.
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".mutemath-update-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	err = client.DownloadAsset(asset.URL, io.MultiWriter(tmp, h))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := core.VerifyChecksum(core.ParseChecksums(sums.String()), name, hex.EncodeToString(h.Sum(nil))); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return fmt.Errorf("chmod %s: %w", tmp.Name(), err)
+	}
+	return replaceExecutable(tmp.Name(), exe)
+}
+
+// replaceExecutable moves the new binary at path over the running one at
+// exe. Windows won't replace a running binary, but will rename it, so there
+// the old one is moved aside to exe.old first, and left for the next update
+// to remove.
+func replaceExecutable(path, exe string) error {
+	if runtime.GOOS != "windows" {
+		if err := os.Rename(path, exe); err != nil {
+			return fmt.Errorf("replace %s: %w", exe, err)
+		}
+		return nil
+	}
+	old := exe + ".old"
+	// Left by the previous update; no longer running.
+	if err := os.Remove(old); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove %s: %w", old, err)
+	}
+	if err := os.Rename(exe, old); err != nil {
+		return fmt.Errorf("move %s aside: %w", exe, err)
+	}
+	if err := os.Rename(path, exe); err != nil {
+		if restoreErr := os.Rename(old, exe); restoreErr != nil {
+			return fmt.Errorf("replace %s: %w (the old binary is at %s)", exe, err, old)
+		}
+		return fmt.Errorf("replace %s: %w", exe, err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// newUserClients makes a client for each user of a multi-user daemon. They
+// share one request pacer, so together they stay under the rate the daemon
+// would use for one inbox, and each keeps its state in its own namespace of
+// the configured store.
+func newUserClients(local localConfig) ([]*GitHubClient, error) {
+	st, err := currentState()
+	if err != nil {
+		return nil, err
+	}
+	pacer := newRequestPacer()
+	clients := make([]*GitHubClient, len(local.users))
+	for i, u := range local.users {
+		// No gh fallback: gh is logged in as one person, not each user.
+		token := os.Getenv(u.TokenEnv)
+		if token == "" {
+			return nil, fmt.Errorf("user %s: %s environment variable is not set", u.Name, u.TokenEnv)
+		}
+		c := NewGitHubClient(token, core.APIBaseURL(u.Host))
+		c.host = u.Host
+		c.user = u.Name
+		c.state = namespacedStore{inner: st, ns: u.Name}
+		c.pacer = pacer
+		if local.userAgent != "" {
+			c.userAgent = local.userAgent
+		}
+		if local.apiVersion != "" {
+			c.apiVersion = local.apiVersion
+		}
+		clients[i] = c
+	}
+	return clients, nil
+}
+
+// userConfigs gives each user the shared config merged with their own config
+// file's classification settings.
+func userConfigs(users []core.UserSpec, shared core.Config) ([]core.Config, error) {
+	cfgs := make([]core.Config, len(users))
+	for i, u := range users {
+		if u.Config == "" {
+			cfgs[i] = shared
+			continue
+		}
+		local, _, err := loadConfig(u.Config, true)
+		if err != nil {
+			return nil, fmt.Errorf("user %s: %w", u.Name, err)
+		}
+		if len(local.users) > 0 || len(local.hosts) > 0 {
+			return nil, fmt.Errorf("user %s: %s: users and hosts only go in the main config file", u.Name, u.Config)
+		}
+		cfgs[i] = core.MergeUserConfig(shared, core.Config{
+			Rules:        local.rules,
+			KeepAuthors:  local.keepAuthors,
+			KeepMentions: local.keepMentions,
+			KeepAssigned: local.keepAssigned,
+			MuteApproved: local.muteApproved,
+
+			MuteTeammateReviewing: local.muteTeammateReviewing,
+			KeepFailedChecks:      local.keepFailedChecks,
+			PinParticipated:       local.pinParticipated,
+			DeadlineWithin:        local.deadlineWithin,
+
+			Pins:          local.pins,
+			BusinessHours: local.businessHours,
+			Scoring:       local.scoring,
+		})
+	}
+	return cfgs, nil
+}
+
+// runDaemonUsers runs a daemon per user concurrently, each with its own
+// config, poll schedule, and state. The exit code is the worst of the daemons.
+func runDaemonUsers(clients []*GitHubClient, cfgs []core.Config, mode core.Mode, apply, verbose bool, opts daemonOptions) int {
+	// Each daemon writes whole lines; keep them from interleaving mid-line.
+	stdout = &syncWriter{w: stdout}
+
+	var wg sync.WaitGroup
+	codes := make([]int, len(clients))
+	for i, client := range clients {
+		wg.Go(func() {
+			codes[i] = runDaemon(client, cfgs[i], mode, apply, verbose, nil, opts)
+		})
+	}
+	wg.Wait()
+	return slices.Max(codes)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// cycleWatchdog alerts when a daemon cycle overruns or starts late. It checks
+// on its own ticker, so a cycle stuck in an HTTP call is still caught. A nil
+// *cycleWatchdog is disabled.
+type cycleWatchdog struct {
+	mu   sync.Mutex
+	w    core.Watchdog
+	host string
+
+	notifiers []notifier
+	reporter  *errorReporter
+	stop      chan struct{}
+}
+
+// startWatchdog starts a watchdog with the threshold, or returns nil if it's zero.
+func startWatchdog(threshold time.Duration, host string, notifiers []notifier, reporter *errorReporter) *cycleWatchdog {
+	if threshold <= 0 {
+		return nil
+	}
+	wd := &cycleWatchdog{w: core.Watchdog{Threshold: threshold}, host: host, notifiers: notifiers, reporter: reporter, stop: make(chan struct{})}
+	go func() {
+		ticker := time.NewTicker(max(threshold/4, time.Second))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-wd.stop:
+				return
+			case <-ticker.C:
+				wd.mu.Lock()
+				msg, late := wd.w.Check(time.Now())
+				wd.mu.Unlock()
+				if late {
+					wd.alert(msg)
+				}
+			}
+		}
+	}()
+	return wd
+}
+
+// Start notes a cycle starting.
+func (wd *cycleWatchdog) Start() {
+	if wd == nil {
+		return
+	}
+	wd.mu.Lock()
+	msg, late := wd.w.Start(time.Now())
+	wd.mu.Unlock()
+	if late {
+		wd.alert(msg)
+	}
+}
+
+// Finish notes a cycle finishing and the wait until the next.
+func (wd *cycleWatchdog) Finish(wait time.Duration) {
+	if wd == nil {
+		return
+	}
+	wd.mu.Lock()
+	wd.w.Finish(time.Now(), wait)
+	wd.mu.Unlock()
+}
+
+// Stop stops the ticker.
+func (wd *cycleWatchdog) Stop() {
+	if wd == nil {
+		return
+	}
+	close(wd.stop)
+}
+
+// alert logs the message as an error and sends it to the notifier sinks and
+// error reporter. Delivery failures are logged, not fatal.
+func (wd *cycleWatchdog) alert(msg string) {
+	prefix := ""
+	if wd.host != "" {
+		prefix = wd.host + "  "
+	}
+	log.Printf("watchdog: %s%s", prefix, msg)
+	a := core.WatchdogAlert(wd.host, msg)
+	for _, n := range wd.notifiers {
+		if err := n.Notify(a); err != nil {
+			log.Printf("warning: %s", err)
+		}
+	}
+	wd.reporter.CaptureError(a.Title + ": " + msg)
+}
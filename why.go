@@ -0,0 +1,133 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lmarburger/mutemath/core"
+)
+
+// runWhy explains the decision for one notification thread: it prints each
+// API response the decision needed, then every check made, in order, and its
+// outcome.
+func runWhy(args []string) int {
+	fs := flag.NewFlagSet("why", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s why [flags] <thread-id>\n\nThe thread ID is the number at the end of a notification's thread URL.\n\n", progName)
+		fs.PrintDefaults()
+	}
+	configPath := fs.String("config", "", "path to the JSON config file with rules (default "+defaultConfigPath()+")")
+	host := fs.String("host", "", "with several hosts in the config file, the host the thread is on (default the first)")
+	includeOrg := fs.String("include-org", "", "only process notifications from this org")
+	excludeOrg := fs.String("exclude-org", "", "skip notifications from this org")
+	includeTopic := fs.String("include-topic", "", "only process notifications from repos with any of these comma-separated topics")
+	excludeTopic := fs.String("exclude-topic", "", "skip notifications from repos with any of these comma-separated topics")
+	onlyPrivate := fs.Bool("only-private", false, "only process notifications from private repos")
+	onlyPublic := fs.Bool("only-public", false, "only process notifications from public repos")
+	teamSizeThreshold := fs.Int("team-size-threshold", 0, "keep team-only review requests through a team of at most this many members instead of muting them")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	threadID := fs.Arg(0)
+
+	visibility := ""
+	switch {
+	case *onlyPrivate && *onlyPublic:
+		fmt.Fprintf(os.Stderr, "Error: --only-private and --only-public can't be used together\n")
+		return 1
+	case *onlyPrivate:
+		visibility = "private"
+	case *onlyPublic:
+		visibility = "public"
+	}
+
+	path, required := resolveConfigPath(*configPath)
+	local, _, err := loadConfig(path, required)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if err := useState(local.state); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	cfg := core.Config{
+		IncludeOrg:    *includeOrg,
+		ExcludeOrg:    *excludeOrg,
+		IncludeTopics: splitList(*includeTopic),
+		ExcludeTopics: splitList(*excludeTopic),
+		Visibility:    visibility,
+		Rules:         local.rules,
+		KeepAuthors:   local.keepAuthors,
+		KeepMentions:  local.keepMentions,
+		KeepAssigned:  local.keepAssigned,
+		MuteApproved:  local.muteApproved,
+
+		MuteTeammateReviewing: local.muteTeammateReviewing,
+		KeepFailedChecks:      local.keepFailedChecks,
+		PinParticipated:       local.pinParticipated,
+		DeadlineWithin:        local.deadlineWithin,
+		TeamSizeThreshold:     *teamSizeThreshold,
+
+		Pins:          local.pins,
+		BusinessHours: local.businessHours,
+		Scoring:       local.scoring,
+	}
+	onCall, err := newOnCallSync(local.onCall)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	onCall.Apply(&cfg, time.Now())
+
+	clients, err := newClients(local)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	client := clients[0]
+	if *host != "" {
+		i := slices.IndexFunc(local.hosts, func(h core.HostSpec) bool { return strings.EqualFold(h.Host, *host) })
+		if i < 0 {
+			fmt.Fprintf(os.Stderr, "Error: host %s isn't in the config file's hosts\n", *host)
+			return 1
+		}
+		client = clients[i]
+	}
+	if err := client.FetchLogin(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if !local.policy.IsZero() {
+		policy, err := loadPolicy(clients[0], local.policy)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		applyPolicy(&cfg, policy)
+	}
+	if st, err := currentState(); err == nil {
+		addStoredPins(&cfg, st)
+	}
+
+	// Only the responses the decision needs are dumped, not the setup above.
+	client.dump = os.Stdout
+	n, err := client.GetThread(threadID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	facts := newClassifier(client, cfg, true).facts(n)
+	client.dump = nil
+
+	d, steps := core.Explain(n, facts, client.login, cfg)
+	fmt.Printf("Decision for %s:\n", client.login)
+	fmt.Print(core.FormatExplanation(d, steps))
+	return 0
+}